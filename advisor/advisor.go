@@ -0,0 +1,257 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package advisor proposes indexes for the queries of a workload, it backs
+// the INDEX ADVISE statement.
+//
+// The queries are read from the file of the statement by a Splitter. For every
+// query block, the columns of a table compared with constants or the columns
+// of the other tables by equality, by ranges, and the columns of ORDER BY or
+// GROUP BY make a candidate composite index: the equality columns first, then
+// a range column or the order columns. The candidates are chosen greedily by
+// the number of the query blocks they serve, an index serves the candidates
+// which are its prefixes, until the MAX_IDXNUM limits are reached.
+package advisor
+
+import (
+	"context"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/resolver"
+	"github.com/daiguadaidai/parser/types"
+	"github.com/pingcap/errors"
+)
+
+// The default limits of the number of the proposed indexes, used if MAX_IDXNUM
+// is not specified.
+const (
+	DefaultMaxIndexNumPerTable = 5
+	DefaultMaxIndexNumPerDB    = 20
+)
+
+// maxIndexNameLen is the maximum length of an index name in characters.
+const maxIndexNameLen = 64
+
+// Advisor proposes indexes.
+type Advisor struct {
+	// InfoSchema is the optional schema catalog. With a catalog, the unknown
+	// tables and columns are skipped, the unqualified columns of the joins are
+	// bound to their tables, and the existing indexes are not proposed again.
+	InfoSchema resolver.InfoSchema
+	// CurrentDB is the database of the unqualified table names, it is changed
+	// by the USE statements of the queries.
+	CurrentDB string
+	// Open opens the file of INDEX ADVISE, os.Open is used if it is nil. For
+	// INDEX ADVISE LOCAL, it should read the file from the client.
+	Open func(path string) (io.ReadCloser, error)
+}
+
+// Result is the result of INDEX ADVISE.
+type Result struct {
+	// Indexes are the proposed indexes, the most useful first.
+	Indexes []*ast.CreateIndexStmt
+	// Queries is the number of the analyzed queries.
+	Queries int
+	// Skipped is the number of the records which can not be parsed.
+	Skipped int
+	// TimedOut is whether the time budget of MAX_MINUTES is used up before
+	// all the queries are read, the indexes are proposed for the queries read.
+	TimedOut bool
+}
+
+// Advise reads the file of an INDEX ADVISE statement and proposes indexes.
+func (a *Advisor) Advise(ctx context.Context, stmt *ast.IndexAdviseStmt) (*Result, error) {
+	open := a.Open
+	if open == nil {
+		open = func(path string) (io.ReadCloser, error) {
+			return os.Open(path)
+		}
+	}
+	f, err := open(stmt.Path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+	return a.AdviseReader(ctx, f, stmt)
+}
+
+// AdviseReader proposes indexes for the queries read from r, which is the file
+// of an INDEX ADVISE statement. The time budget of MAX_MINUTES is checked
+// before every record is read.
+func (a *Advisor) AdviseReader(ctx context.Context, r io.Reader, stmt *ast.IndexAdviseStmt) (*Result, error) {
+	budget := ctx
+	// A budget too long for a time.Duration is no limit.
+	if stmt.MaxMinutes <= math.MaxInt64/uint64(time.Minute) {
+		var cancel context.CancelFunc
+		budget, cancel = context.WithTimeout(ctx, time.Duration(stmt.MaxMinutes)*time.Minute)
+		defer cancel()
+	}
+	res := &Result{}
+	c := newCollector(a)
+	p := parser.New()
+	s := NewSplitter(r, stmt.LinesInfo)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if budget.Err() != nil {
+			res.TimedOut = true
+			break
+		}
+		text, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		stmts, _, err := p.Parse(text, "", "")
+		if err != nil {
+			res.Skipped++
+			continue
+		}
+		for _, stmt := range stmts {
+			if c.stmt(stmt) {
+				res.Queries++
+			}
+		}
+	}
+	perTable, perDB := uint64(DefaultMaxIndexNumPerTable), uint64(DefaultMaxIndexNumPerDB)
+	if stmt.MaxIndexNum != nil {
+		if stmt.MaxIndexNum.PerTable != ast.UnspecifiedSize {
+			perTable = stmt.MaxIndexNum.PerTable
+		}
+		if stmt.MaxIndexNum.PerDB != ast.UnspecifiedSize {
+			perDB = stmt.MaxIndexNum.PerDB
+		}
+	}
+	res.Indexes = c.choose(perTable, perDB)
+	return res, nil
+}
+
+// choose chooses the indexes from the candidates.
+func (c *collector) choose(perTable, perDB uint64) []*ast.CreateIndexStmt {
+	var candidates []*candidate
+	served := make(map[*candidate]bool)
+	for _, t := range c.tableList {
+		existing := existingIndexes(t.info)
+		for _, cand := range t.candidates {
+			candidates = append(candidates, cand)
+			for _, cols := range existing {
+				if isPrefixOf(cand.columns, cols) {
+					served[cand] = true
+					break
+				}
+			}
+		}
+	}
+	tableIndexes := make(map[*table]uint64)
+	dbIndexes := make(map[string]uint64)
+	names := make(map[*table]map[string]bool)
+	var res []*ast.CreateIndexStmt
+	for {
+		var best *candidate
+		bestBenefit := 0
+		for _, cand := range candidates {
+			t := cand.table
+			if served[cand] || tableIndexes[t] >= perTable || dbIndexes[t.schema.L] >= perDB {
+				continue
+			}
+			benefit := 0
+			for _, other := range t.candidates {
+				if !served[other] && isPrefixOf(other.columns, cand.columns) {
+					benefit += other.queries
+				}
+			}
+			if benefit > bestBenefit {
+				best, bestBenefit = cand, benefit
+			}
+		}
+		if best == nil {
+			return res
+		}
+		t := best.table
+		for _, other := range t.candidates {
+			if isPrefixOf(other.columns, best.columns) {
+				served[other] = true
+			}
+		}
+		tableIndexes[t]++
+		dbIndexes[t.schema.L]++
+		if names[t] == nil {
+			names[t] = make(map[string]bool)
+		}
+		res = append(res, createIndex(best, names[t]))
+	}
+}
+
+// existingIndexes returns the columns of the existing indexes of a table.
+func existingIndexes(info *model.TableInfo) [][]model.CIStr {
+	if info == nil {
+		return nil
+	}
+	var res [][]model.CIStr
+	if info.PKIsHandle {
+		if pk := info.GetPkColInfo(); pk != nil {
+			res = append(res, []model.CIStr{pk.Name})
+		}
+	}
+	for _, idx := range info.Indices {
+		cols := make([]model.CIStr, 0, len(idx.Columns))
+		for _, col := range idx.Columns {
+			cols = append(cols, col.Name)
+		}
+		res = append(res, cols)
+	}
+	return res
+}
+
+// createIndex returns the CREATE INDEX statement of a candidate, the index is
+// named by its columns and a number if the name is used.
+func createIndex(cand *candidate, used map[string]bool) *ast.CreateIndexStmt {
+	t := cand.table
+	parts := make([]*ast.IndexPartSpecification, 0, len(cand.columns))
+	names := make([]string, 0, len(cand.columns))
+	for _, col := range cand.columns {
+		parts = append(parts, &ast.IndexPartSpecification{Column: &ast.ColumnName{Name: col}, Length: types.UnspecifiedLength})
+		names = append(names, col.O)
+	}
+	base := []rune("idx_" + strings.Join(names, "_"))
+	if len(base) > maxIndexNameLen {
+		base = base[:maxIndexNameLen]
+	}
+	name := string(base)
+	for i := 2; used[strings.ToLower(name)] || t.info != nil && t.info.FindIndexByName(strings.ToLower(name)) != nil; i++ {
+		suffix := "_" + strconv.Itoa(i)
+		if len(base)+len(suffix) > maxIndexNameLen {
+			name = string(base[:maxIndexNameLen-len(suffix)]) + suffix
+		} else {
+			name = string(base) + suffix
+		}
+	}
+	used[strings.ToLower(name)] = true
+	return &ast.CreateIndexStmt{
+		IndexName:               name,
+		Table:                   &ast.TableName{Schema: t.schema, Name: t.name},
+		IndexPartSpecifications: parts,
+		IndexOption:             &ast.IndexOption{},
+	}
+}
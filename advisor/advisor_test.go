@@ -0,0 +1,253 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package advisor_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	. "github.com/daiguadaidai/parser/advisor"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/format"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func parseAdvise(t *testing.T, sql string) *ast.IndexAdviseStmt {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err, sql)
+	return stmt.(*ast.IndexAdviseStmt)
+}
+
+func restoreIndexes(t *testing.T, res *Result) []string {
+	var indexes []string
+	for _, stmt := range res.Indexes {
+		var sb strings.Builder
+		require.NoError(t, stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)))
+		indexes = append(indexes, sb.String())
+	}
+	return indexes
+}
+
+func advise(t *testing.T, a *Advisor, queries, stmt string) *Result {
+	res, err := a.AdviseReader(context.Background(), strings.NewReader(queries), parseAdvise(t, stmt))
+	require.NoError(t, err)
+	return res
+}
+
+func TestAdvise(t *testing.T) {
+	cases := []struct {
+		queries string
+		expect  []string
+	}{
+		{"select * from t where a = 1 and b > 2 and c < 3 order by d", []string{"CREATE INDEX `idx_a_b` ON `test`.`t` (`a`, `b`)"}},
+		{"select * from t where a = 1 order by d, e", []string{"CREATE INDEX `idx_a_d_e` ON `test`.`t` (`a`, `d`, `e`)"}},
+		{"select a from t order by b, c limit 10", []string{"CREATE INDEX `idx_b_c` ON `test`.`t` (`b`, `c`)"}},
+		{"select a, count(*) from t group by a", []string{"CREATE INDEX `idx_a` ON `test`.`t` (`a`)"}},
+		{"select * from t where a in (1, 2) and b is null and c between 1 and 2", []string{"CREATE INDEX `idx_a_b_c` ON `test`.`t` (`a`, `b`, `c`)"}},
+		{"select * from t where a like 'x%' and b like '%x'", []string{"CREATE INDEX `idx_a` ON `test`.`t` (`a`)"}},
+		{"select * from t where 1 = a and (b = a + 1 or c = 2) and d <> 3 and e = rand() and f not in (1)", []string{"CREATE INDEX `idx_a` ON `test`.`t` (`a`)"}},
+		{"select * from t order by a, u.b", nil},
+		{
+			"select * from db.t1 x join t2 on x.id = t2.t1_id where x.k = 5 order by x.k",
+			[]string{
+				"CREATE INDEX `idx_id_k` ON `db`.`t1` (`id`, `k`)",
+				"CREATE INDEX `idx_t1_id` ON `test`.`t2` (`t1_id`)",
+			},
+		},
+		// The unqualified columns of a join are ambiguous without a catalog.
+		{"select * from t1, t2 where a = 1 and t1.b = 2", []string{"CREATE INDEX `idx_b` ON `test`.`t1` (`b`)"}},
+		{
+			"select * from t where exists (select 1 from u where u.tid = t.id and s = 'x') and a in (select b from v where c > 1)",
+			[]string{
+				"CREATE INDEX `idx_a` ON `test`.`t` (`a`)",
+				"CREATE INDEX `idx_tid_s` ON `test`.`u` (`tid`, `s`)",
+				"CREATE INDEX `idx_c` ON `test`.`v` (`c`)",
+			},
+		},
+		{
+			"with c as (select * from u where k = 1) select * from c join (select * from v where j = 2) d on c.x = d.y where z = 3",
+			[]string{
+				"CREATE INDEX `idx_k` ON `test`.`u` (`k`)",
+				"CREATE INDEX `idx_j` ON `test`.`v` (`j`)",
+			},
+		},
+		{
+			"select a from t where a = 1 union all select b from u where b = 2 order by 1",
+			[]string{
+				"CREATE INDEX `idx_a` ON `test`.`t` (`a`)",
+				"CREATE INDEX `idx_b` ON `test`.`u` (`b`)",
+			},
+		},
+		{
+			"update t set a = (select max(x) from u where y = 1) where b = 2; delete from v where c < 3 order by d; insert into w select * from x where e = 4",
+			[]string{
+				"CREATE INDEX `idx_b` ON `test`.`t` (`b`)",
+				"CREATE INDEX `idx_y` ON `test`.`u` (`y`)",
+				"CREATE INDEX `idx_c` ON `test`.`v` (`c`)",
+				"CREATE INDEX `idx_e` ON `test`.`x` (`e`)",
+			},
+		},
+		{"use db; select * from t where a = 1", []string{"CREATE INDEX `idx_a` ON `db`.`t` (`a`)"}},
+		{"create table t (a int); insert into t values (1); select 1", nil},
+		// The long names are truncated by characters.
+		{
+			"select * from t where " + strings.Repeat("列", 61) + "x = 1; select * from t where " + strings.Repeat("列", 61) + "y = 1",
+			[]string{
+				"CREATE INDEX `idx_" + strings.Repeat("列", 60) + "` ON `test`.`t` (`" + strings.Repeat("列", 61) + "x`)",
+				"CREATE INDEX `idx_" + strings.Repeat("列", 58) + "_2` ON `test`.`t` (`" + strings.Repeat("列", 61) + "y`)",
+			},
+		},
+	}
+	a := &Advisor{CurrentDB: "test"}
+	for _, c := range cases {
+		res := advise(t, a, c.queries, "index advise infile 'q.sql' lines terminated by ';'")
+		require.Equal(t, c.expect, restoreIndexes(t, res), c.queries)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	a := &Advisor{CurrentDB: "test"}
+	queries := strings.Join([]string{
+		"select * from t where a = 1",
+		"select * from t where a = ? and b = ?",
+		"select * from t where c = 1",
+		"select * from t where c = 2",
+		"select * from t where c = 3 and a = 1",
+		"select * from u where x = 1",
+		"select * from d2.t where a = 1",
+		"select * from t where",
+		"",
+	}, "\n")
+	res := advise(t, a, queries, "index advise infile 'q.sql'")
+	require.Equal(t, 7, res.Queries)
+	require.Equal(t, 1, res.Skipped)
+	require.False(t, res.TimedOut)
+	// (c, a) serves 3 queries with its prefix (c), and (a, b) serves 2.
+	require.Equal(t, []string{
+		"CREATE INDEX `idx_c_a` ON `test`.`t` (`c`, `a`)",
+		"CREATE INDEX `idx_a_b` ON `test`.`t` (`a`, `b`)",
+		"CREATE INDEX `idx_x` ON `test`.`u` (`x`)",
+		"CREATE INDEX `idx_a` ON `d2`.`t` (`a`)",
+	}, restoreIndexes(t, res))
+
+	res = advise(t, a, queries, "index advise infile 'q.sql' max_idxnum per_table 1")
+	require.Equal(t, []string{
+		"CREATE INDEX `idx_c_a` ON `test`.`t` (`c`, `a`)",
+		"CREATE INDEX `idx_x` ON `test`.`u` (`x`)",
+		"CREATE INDEX `idx_a` ON `d2`.`t` (`a`)",
+	}, restoreIndexes(t, res))
+
+	res = advise(t, a, queries, "index advise infile 'q.sql' max_idxnum per_table 3 per_db 2")
+	require.Equal(t, []string{
+		"CREATE INDEX `idx_c_a` ON `test`.`t` (`c`, `a`)",
+		"CREATE INDEX `idx_a_b` ON `test`.`t` (`a`, `b`)",
+		"CREATE INDEX `idx_a` ON `d2`.`t` (`a`)",
+	}, restoreIndexes(t, res))
+
+	res = advise(t, a, queries, "index advise infile 'q.sql' max_idxnum per_db 0")
+	require.Empty(t, res.Indexes)
+
+	res = advise(t, a, "-- select * from t where a = 1\n#q select * from t where b = 1", "index advise infile 'q.sql' lines starting by '#q'")
+	require.Equal(t, []string{"CREATE INDEX `idx_b` ON `test`.`t` (`b`)"}, restoreIndexes(t, res))
+}
+
+func TestAdviseWithCatalog(t *testing.T) {
+	catalog := ddl.NewCatalog()
+	stmts, _, err := parser.New().Parse(`
+		create database test; use test;
+		create table t (id int primary key, a int, b int, c text, d int, e int, key idx_d_b (d), key ab (a, b), unique (e, b));
+		create table u (uid bigint, tid int, primary key (uid, tid), key (tid));
+		create view v as select * from t`, "", "")
+	require.NoError(t, err)
+	for _, stmt := range stmts {
+		require.NoError(t, catalog.Apply(stmt))
+	}
+	a := &Advisor{InfoSchema: catalog, CurrentDB: "test"}
+	queries := strings.Join([]string{
+		// The existing indexes are not proposed again.
+		"select * from t where id = 1",
+		"select * from t where a = 1",
+		"select * from t where d = 1",
+		"select * from u where uid = 1",
+		"select * from u where tid = 1",
+		// The unknown columns and tables, the text columns and the views are
+		// skipped.
+		"select * from t where x = 1",
+		"select * from w where a = 1",
+		"select * from t where c = 'x'",
+		"select * from v where a = 1",
+		// The columns covering a unique key need no more index.
+		"select * from t where e = 1 and b = 2 and a = 3",
+		"select * from t join u on id = tid where d = 2",
+		// The unqualified columns of a join are bound by the catalog, the names
+		// of the indexes are not reused.
+		"select * from t join u on A = uid where D = 2",
+		"select * from t where d = 1 and b > 2",
+	}, ";")
+	res := advise(t, a, queries, "index advise infile 'q.sql' lines terminated by ';'")
+	require.Equal(t, []string{
+		"CREATE INDEX `idx_a_d` ON `test`.`t` (`a`, `d`)",
+		"CREATE INDEX `idx_d_b_2` ON `test`.`t` (`d`, `b`)",
+	}, restoreIndexes(t, res))
+}
+
+func TestAdviseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.sql")
+	require.NoError(t, os.WriteFile(path, []byte("select * from t where a = 1;\nselect * from t where b = 1;\n"), 0o600))
+	stmt := parseAdvise(t, "index advise infile '"+path+"' max_minutes 10 lines terminated by ';\n'")
+
+	a := &Advisor{}
+	res, err := a.Advise(context.Background(), stmt)
+	require.NoError(t, err)
+	require.Equal(t, 2, res.Queries)
+	require.Equal(t, []string{"CREATE INDEX `idx_a` ON `t` (`a`)", "CREATE INDEX `idx_b` ON `t` (`b`)"}, restoreIndexes(t, res))
+
+	var opened string
+	a.Open = func(path string) (io.ReadCloser, error) {
+		opened = path
+		return io.NopCloser(strings.NewReader("select * from t where c = 1")), nil
+	}
+	res, err = a.Advise(context.Background(), parseAdvise(t, "index advise local infile 'client.sql'"))
+	require.NoError(t, err)
+	require.Equal(t, "client.sql", opened)
+	require.Equal(t, []string{"CREATE INDEX `idx_c` ON `t` (`c`)"}, restoreIndexes(t, res))
+
+	_, err = (&Advisor{}).Advise(context.Background(), parseAdvise(t, "index advise infile '"+path+".none'"))
+	require.True(t, os.IsNotExist(errors.Cause(err)))
+
+	// The time budget is used up before the first query.
+	res, err = a.Advise(context.Background(), parseAdvise(t, "index advise infile 'q.sql' max_minutes 0"))
+	require.NoError(t, err)
+	require.True(t, res.TimedOut)
+	require.Zero(t, res.Queries)
+	require.Empty(t, res.Indexes)
+	// A budget overflowing a time.Duration is no limit.
+	res, err = a.Advise(context.Background(), parseAdvise(t, "index advise infile 'q.sql' max_minutes 200000000"))
+	require.NoError(t, err)
+	require.False(t, res.TimedOut)
+	require.Equal(t, 1, res.Queries)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = a.Advise(ctx, stmt)
+	require.Equal(t, context.Canceled, errors.Cause(err))
+}
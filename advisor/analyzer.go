@@ -0,0 +1,545 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package advisor
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/opcode"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// table is a base table referenced by the queries.
+type table struct {
+	schema, name model.CIStr
+	// info is nil if there is no schema catalog.
+	info *model.TableInfo
+	// candidates are the candidate indexes in the order they are found.
+	candidates []*candidate
+	byColumns  map[string]*candidate
+}
+
+// candidate is a candidate index of a table.
+type candidate struct {
+	table   *table
+	columns []model.CIStr
+	// queries is the number of the query blocks the index is built for.
+	queries int
+}
+
+func (c *candidate) key() string {
+	names := make([]string, 0, len(c.columns))
+	for _, col := range c.columns {
+		names = append(names, col.L)
+	}
+	return strings.Join(names, ",")
+}
+
+// coversUniqueKey returns whether the columns cover the primary key or a
+// unique key, then at most one row is read by the key and there is no need of
+// another index.
+func (t *table) coversUniqueKey(cols []model.CIStr) bool {
+	if t.info == nil {
+		return false
+	}
+	has := func(name model.CIStr) bool {
+		for _, col := range cols {
+			if col.L == name.L {
+				return true
+			}
+		}
+		return false
+	}
+	if t.info.PKIsHandle {
+		if pk := t.info.GetPkColInfo(); pk != nil && has(pk.Name) {
+			return true
+		}
+	}
+	for _, idx := range t.info.Indices {
+		if !idx.Unique && !idx.Primary {
+			continue
+		}
+		covered := true
+		for _, col := range idx.Columns {
+			covered = covered && has(col.Name)
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrefixOf returns whether the columns are a prefix of the columns of an
+// index, so the index can be used instead.
+func isPrefixOf(columns []model.CIStr, index []model.CIStr) bool {
+	if len(columns) > len(index) {
+		return false
+	}
+	for i, col := range columns {
+		if col.L != index[i].L {
+			return false
+		}
+	}
+	return true
+}
+
+// source is a table in a FROM clause, table is nil for a derived table or a
+// CTE.
+type source struct {
+	alias model.CIStr
+	table *table
+	usage usage
+}
+
+// usage is how a table is used by a query block.
+type usage struct {
+	// eq are the columns compared by equality with constants or the columns of
+	// the other tables.
+	eq []model.CIStr
+	// ranges are the columns compared by ranges.
+	ranges []model.CIStr
+	// order are the columns of ORDER BY, or GROUP BY without ORDER BY.
+	order []model.CIStr
+}
+
+func appendColumn(cols []model.CIStr, col model.CIStr) []model.CIStr {
+	for _, c := range cols {
+		if c.L == col.L {
+			return cols
+		}
+	}
+	return append(cols, col)
+}
+
+// columns returns the columns of the index of the usage: the equality
+// columns, then the first range column or the order columns.
+func (u *usage) columns() []model.CIStr {
+	var cols []model.CIStr
+	for _, col := range u.eq {
+		cols = appendColumn(cols, col)
+	}
+	rest := u.order
+	if len(u.ranges) > 0 {
+		rest = u.ranges[:1]
+	}
+	for _, col := range rest {
+		cols = appendColumn(cols, col)
+	}
+	return cols
+}
+
+// scope is the name scope of a query block.
+type scope struct {
+	parent  *scope
+	ctes    map[string]bool
+	sources []*source
+}
+
+func (s *scope) isCTE(name string) bool {
+	for ; s != nil; s = s.parent {
+		if s.ctes[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// collector collects the candidate indexes of the queries.
+type collector struct {
+	*Advisor
+	currentDB string
+	tables    map[[2]string]*table
+	// tableList is the tables in the order they are found.
+	tableList []*table
+}
+
+func newCollector(a *Advisor) *collector {
+	return &collector{Advisor: a, currentDB: a.CurrentDB, tables: make(map[[2]string]*table)}
+}
+
+// stmt collects the candidate indexes of a statement, it returns whether the
+// statement is a query.
+func (c *collector) stmt(stmt ast.StmtNode) bool {
+	switch x := stmt.(type) {
+	case *ast.UseStmt:
+		c.currentDB = x.DBName
+	case *ast.SelectStmt, *ast.SetOprStmt:
+		c.query(nil, x)
+		return true
+	case *ast.UpdateStmt:
+		s := c.with(nil, x.With)
+		c.block(s, x.TableRefs, x.Where, x.Order, nil)
+		for _, a := range x.List {
+			c.subqueries(s, a.Expr)
+		}
+		return true
+	case *ast.DeleteStmt:
+		c.block(c.with(nil, x.With), x.TableRefs, x.Where, x.Order, nil)
+		return true
+	case *ast.InsertStmt:
+		if x.Select != nil {
+			c.query(nil, x.Select)
+			return true
+		}
+	}
+	return false
+}
+
+// query collects a query, which is a SELECT, a set operation or a subquery.
+func (c *collector) query(parent *scope, node ast.Node) {
+	switch x := node.(type) {
+	case *ast.SubqueryExpr:
+		c.query(parent, x.Query)
+	case *ast.SelectStmt:
+		s := c.with(parent, x.With)
+		c.block(s, x.From, x.Where, x.OrderBy, x.GroupBy)
+		if x.Fields != nil {
+			for _, f := range x.Fields.Fields {
+				c.subqueries(s, f.Expr)
+			}
+		}
+		if x.Having != nil {
+			c.subqueries(s, x.Having.Expr)
+		}
+	case *ast.SetOprStmt:
+		c.query(c.with(parent, x.With), x.SelectList)
+	case *ast.SetOprSelectList:
+		s := c.with(parent, x.With)
+		for _, sel := range x.Selects {
+			c.query(s, sel)
+		}
+	}
+}
+
+// with returns the scope of the CTEs and collects their queries.
+func (c *collector) with(parent *scope, with *ast.WithClause) *scope {
+	s := &scope{parent: parent}
+	if with == nil {
+		return s
+	}
+	s.ctes = make(map[string]bool, len(with.CTEs))
+	for _, cte := range with.CTEs {
+		if with.IsRecursive {
+			s.ctes[cte.Name.L] = true
+		}
+		c.query(s, cte.Query)
+		s.ctes[cte.Name.L] = true
+	}
+	return s
+}
+
+// subqueries collects the subqueries of an expression.
+func (c *collector) subqueries(s *scope, expr ast.ExprNode) {
+	if expr == nil {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sub, ok := n.(*ast.SubqueryExpr); ok {
+			c.query(s, sub.Query)
+			return false
+		}
+		return true
+	})
+}
+
+// block collects a query block, and adds the candidate indexes of its tables.
+func (c *collector) block(s *scope, from *ast.TableRefsClause, where ast.ExprNode, order *ast.OrderByClause, group *ast.GroupByClause) {
+	var conds []ast.ExprNode
+	if from != nil {
+		conds = c.tableRefs(s, from.TableRefs, conds)
+	}
+	conds = splitAnd(where, conds)
+	for _, cond := range conds {
+		c.cond(s, cond)
+		c.subqueries(s, cond)
+	}
+	var items []*ast.ByItem
+	if order != nil {
+		items = order.Items
+	} else if group != nil {
+		items = group.Items
+	}
+	c.order(s, items)
+	for _, src := range s.sources {
+		if src.table == nil || src.table.coversUniqueKey(src.usage.eq) {
+			continue
+		}
+		if cols := src.usage.columns(); len(cols) > 0 {
+			c.addCandidate(src.table, cols)
+		}
+	}
+}
+
+// tableRefs adds the sources of a FROM clause to the scope, and returns the
+// conditions of the joins appended to conds.
+func (c *collector) tableRefs(s *scope, node ast.ResultSetNode, conds []ast.ExprNode) []ast.ExprNode {
+	switch x := node.(type) {
+	case *ast.Join:
+		conds = c.tableRefs(s, x.Left, conds)
+		if x.Right != nil {
+			conds = c.tableRefs(s, x.Right, conds)
+		}
+		if x.On != nil {
+			conds = splitAnd(x.On.Expr, conds)
+		}
+	case *ast.TableSource:
+		src := &source{alias: x.AsName}
+		switch y := x.Source.(type) {
+		case *ast.TableName:
+			if src.alias.L == "" {
+				src.alias = y.Name
+			}
+			if y.Schema.L != "" || !s.isCTE(y.Name.L) {
+				src.table = c.table(y)
+			}
+		default:
+			c.query(s, y)
+		}
+		s.sources = append(s.sources, src)
+	}
+	return conds
+}
+
+// table returns the table of a table name.
+func (c *collector) table(tn *ast.TableName) *table {
+	schema := tn.Schema
+	if schema.L == "" {
+		schema = model.NewCIStr(c.currentDB)
+	}
+	key := [2]string{schema.L, tn.Name.L}
+	if t, ok := c.tables[key]; ok {
+		return t
+	}
+	t := &table{schema: schema, name: tn.Name, byColumns: make(map[string]*candidate)}
+	if c.InfoSchema != nil {
+		t.info, _ = c.InfoSchema.TableByName(schema, tn.Name)
+	}
+	c.tables[key] = t
+	c.tableList = append(c.tableList, t)
+	return t
+}
+
+// addCandidate adds a candidate index of a table. The index is not added if
+// a column is unknown or can not be indexed without a prefix length, or the
+// table is unknown while there is a schema catalog.
+func (c *collector) addCandidate(t *table, cols []model.CIStr) {
+	if c.InfoSchema != nil {
+		if t.info == nil || t.info.IsView() || t.info.IsSequence() {
+			return
+		}
+		for i, col := range cols {
+			info := model.FindColumnInfo(t.info.Columns, col.L)
+			if info == nil {
+				return
+			}
+			if tp := info.GetType(); types.IsTypeBlob(tp) || tp == mysql.TypeJSON || tp == mysql.TypeGeometry {
+				return
+			}
+			cols[i] = info.Name
+		}
+	}
+	cand := &candidate{table: t, columns: cols}
+	if old, ok := t.byColumns[cand.key()]; ok {
+		old.queries++
+		return
+	}
+	cand.queries = 1
+	t.byColumns[cand.key()] = cand
+	t.candidates = append(t.candidates, cand)
+}
+
+// splitAnd appends the conjuncts of an expression to conds.
+func splitAnd(expr ast.ExprNode, conds []ast.ExprNode) []ast.ExprNode {
+	switch x := expr.(type) {
+	case nil:
+	case *ast.ParenthesesExpr:
+		conds = splitAnd(x.Expr, conds)
+	case *ast.BinaryOperationExpr:
+		if x.Op != opcode.LogicAnd {
+			return append(conds, expr)
+		}
+		conds = splitAnd(x.L, conds)
+		conds = splitAnd(x.R, conds)
+	default:
+		conds = append(conds, expr)
+	}
+	return conds
+}
+
+func unparen(expr ast.ExprNode) ast.ExprNode {
+	for {
+		p, ok := expr.(*ast.ParenthesesExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.Expr
+	}
+}
+
+// cond adds the columns of a condition to the usages of the sources.
+func (c *collector) cond(s *scope, cond ast.ExprNode) {
+	switch x := cond.(type) {
+	case *ast.BinaryOperationExpr:
+		switch x.Op {
+		case opcode.EQ, opcode.NullEQ:
+			c.compare(s, x.L, x.R, true)
+			c.compare(s, x.R, x.L, true)
+		case opcode.LT, opcode.LE, opcode.GT, opcode.GE:
+			c.compare(s, x.L, x.R, false)
+			c.compare(s, x.R, x.L, false)
+		}
+	case *ast.IsNullExpr:
+		if src, col := s.localColumn(x.Expr); src != nil && !x.Not {
+			src.usage.eq = append(src.usage.eq, col)
+		}
+	case *ast.PatternInExpr:
+		src, col := s.localColumn(x.Expr)
+		if src == nil || x.Not {
+			return
+		}
+		for _, item := range x.List {
+			if !s.independent(src, item) {
+				return
+			}
+		}
+		src.usage.eq = append(src.usage.eq, col)
+	case *ast.BetweenExpr:
+		src, col := s.localColumn(x.Expr)
+		if src != nil && !x.Not && s.independent(src, x.Left) && s.independent(src, x.Right) {
+			src.usage.ranges = append(src.usage.ranges, col)
+		}
+	case *ast.PatternLikeExpr:
+		src, col := s.localColumn(x.Expr)
+		if src == nil || x.Not {
+			return
+		}
+		// Only the patterns with a constant prefix are ranges.
+		if v, ok := unparen(x.Pattern).(ast.ValueExpr); ok {
+			if p, ok := v.GetValue().(string); ok && p != "" && p[0] != '%' && p[0] != '_' {
+				src.usage.ranges = append(src.usage.ranges, col)
+			}
+		}
+	}
+}
+
+// compare adds the column of a comparison if it is compared with a value
+// independent of its table.
+func (c *collector) compare(s *scope, l, r ast.ExprNode, eq bool) {
+	src, col := s.localColumn(l)
+	if src == nil || !s.independent(src, r) {
+		return
+	}
+	if eq {
+		src.usage.eq = append(src.usage.eq, col)
+	} else {
+		src.usage.ranges = append(src.usage.ranges, col)
+	}
+}
+
+// order adds the columns of ORDER BY or GROUP BY, if they are the columns of
+// one table.
+func (c *collector) order(s *scope, items []*ast.ByItem) {
+	var (
+		src  *source
+		cols []model.CIStr
+	)
+	for _, item := range items {
+		col, name := s.localColumn(item.Expr)
+		if col == nil || src != nil && col != src {
+			return
+		}
+		src = col
+		cols = append(cols, name)
+	}
+	if src != nil {
+		src.usage.order = cols
+	}
+}
+
+// localColumn returns the source and the name of a column expression, if it
+// is a column of a base table of the scope.
+func (s *scope) localColumn(expr ast.ExprNode) (*source, model.CIStr) {
+	cn, ok := unparen(expr).(*ast.ColumnNameExpr)
+	if !ok {
+		return nil, model.CIStr{}
+	}
+	src, local := s.resolve(cn.Name)
+	if !local || src == nil || src.table == nil {
+		return nil, model.CIStr{}
+	}
+	return src, cn.Name.Name
+}
+
+// independent returns whether the value of an expression is independent of
+// the rows of a source, which is the case if it has no columns of the source.
+// The columns which can not be resolved may be the columns of the source.
+func (s *scope) independent(src *source, expr ast.ExprNode) bool {
+	res := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.SubqueryExpr:
+			return false
+		case *ast.ColumnNameExpr:
+			if col, _ := s.resolve(x.Name); col == nil || col == src {
+				res = false
+			}
+		case *ast.DefaultExpr, *ast.VariableExpr:
+			res = false
+		case *ast.FuncCallExpr:
+			if x.FnName.L == ast.Rand || x.FnName.L == ast.UUID {
+				res = false
+			}
+		}
+		return res
+	})
+	return res
+}
+
+// resolve returns the source of a column, and whether it is a source of the
+// scope rather than the outer scopes. The source is nil if the column can not
+// be resolved: its table is ambiguous without a schema catalog.
+func (s *scope) resolve(name *ast.ColumnName) (*source, bool) {
+	for sc := s; sc != nil; sc = sc.parent {
+		if name.Table.L != "" {
+			for _, src := range sc.sources {
+				if src.alias.L == name.Table.L && (name.Schema.L == "" || src.table != nil && src.table.schema.L == name.Schema.L) {
+					return src, sc == s
+				}
+			}
+			continue
+		}
+		var found, unknown []*source
+		for _, src := range sc.sources {
+			switch {
+			case src.table == nil || src.table.info == nil:
+				unknown = append(unknown, src)
+			case model.FindColumnInfo(src.table.info.Columns, name.Name.L) != nil:
+				found = append(found, src)
+			}
+		}
+		switch {
+		case len(found) == 1 && len(unknown) == 0:
+			return found[0], sc == s
+		case len(found) == 0 && len(unknown) == 1:
+			return unknown[0], sc == s
+		case len(found)+len(unknown) > 0:
+			return nil, false
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package advisor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/pingcap/errors"
+)
+
+// MaxRecordSize is the maximum size of a record read by a Splitter.
+const MaxRecordSize = 16 << 20
+
+// Splitter reads the records of a query file one by one without reading the
+// whole file. The records are split like the lines of LOAD DATA: a record ends
+// with the TERMINATED BY string, and if there is a STARTING BY string, the
+// text of a record starts after it and the records without it are skipped.
+type Splitter struct {
+	scanner  *bufio.Scanner
+	starting string
+	record   int
+}
+
+// NewSplitter creates a Splitter reading r, the records are terminated by
+// "\n" if lines is nil.
+func NewSplitter(r io.Reader, lines *ast.LinesClause) *Splitter {
+	s := &Splitter{scanner: bufio.NewScanner(r)}
+	terminated := []byte("\n")
+	if lines != nil {
+		s.starting = lines.Starting
+		terminated = []byte(lines.Terminated)
+	}
+	s.scanner.Buffer(nil, MaxRecordSize)
+	s.scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(terminated) > 0 {
+			if i := bytes.Index(data, terminated); i >= 0 {
+				return i + len(terminated), data[:i], nil
+			}
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	return s
+}
+
+// Next returns the next record which is not blank, it returns io.EOF at the
+// end of the file.
+func (s *Splitter) Next() (string, error) {
+	for s.scanner.Scan() {
+		s.record++
+		text := s.scanner.Text()
+		if s.starting != "" {
+			i := strings.Index(text, s.starting)
+			if i < 0 {
+				continue
+			}
+			text = text[i+len(s.starting):]
+		}
+		if strings.TrimSpace(text) != "" {
+			return text, nil
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return "", errors.Annotatef(err, "record %d", s.record+1)
+	}
+	return "", io.EOF
+}
+
+// Record returns the 1-based number of the record returned by Next, the
+// skipped records are counted.
+func (s *Splitter) Record() int {
+	return s.record
+}
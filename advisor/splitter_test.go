@@ -0,0 +1,70 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package advisor_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/daiguadaidai/parser/advisor"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func split(t *testing.T, text string, lines *ast.LinesClause) ([]string, []int) {
+	s := NewSplitter(strings.NewReader(text), lines)
+	var records []string
+	var numbers []int
+	for {
+		record, err := s.Next()
+		if err == io.EOF {
+			return records, numbers
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+		numbers = append(numbers, s.Record())
+	}
+}
+
+func TestSplitter(t *testing.T) {
+	records, numbers := split(t, "select 1\n\n  \nselect 2\nselect 3", nil)
+	require.Equal(t, []string{"select 1", "select 2", "select 3"}, records)
+	require.Equal(t, []int{1, 4, 5}, numbers)
+
+	records, _ = split(t, "select\n  1;;select 2;\n", &ast.LinesClause{Terminated: ";"})
+	require.Equal(t, []string{"select\n  1", "select 2"}, records)
+
+	records, numbers = split(t, "-- q: select 1\nnoise\nq: select 2", &ast.LinesClause{Starting: "q: ", Terminated: "\n"})
+	require.Equal(t, []string{"select 1", "select 2"}, records)
+	require.Equal(t, []int{1, 3}, numbers)
+
+	records, _ = split(t, "select 1 /*;;*/ ;; select 2", &ast.LinesClause{Terminated: ";;"})
+	require.Equal(t, []string{"select 1 /*", "*/ ", " select 2"}, records)
+
+	records, _ = split(t, "select 1;\nselect 2", &ast.LinesClause{})
+	require.Equal(t, []string{"select 1;\nselect 2"}, records)
+
+	records, _ = split(t, "", nil)
+	require.Empty(t, records)
+
+	// A record longer than MaxRecordSize is an error.
+	s := NewSplitter(strings.NewReader("select 1\n"+strings.Repeat("x", MaxRecordSize+1)), nil)
+	record, err := s.Next()
+	require.NoError(t, err)
+	require.Equal(t, "select 1", record)
+	_, err = s.Next()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "record 2")
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+//go:generate go run ./internal/clonegen -o clone_generated.go
+
+// NodeCloner is implemented by the nodes defined out of this package, like the
+// ValueExpr and ParamMarkerExpr of the parser driver, so DeepCopy can copy them.
+type NodeCloner interface {
+	// CloneNode returns a deep copy of the node.
+	CloneNode() Node
+}
+
+// CopyTexprNode makes dst a deep copy of src, it's exported for parser driver
+// to implement NodeCloner.
+func CopyTexprNode(dst, src *TexprNode) {
+	src.copyTo(dst)
+}
+
+// deepCopyValue copies the nodes held by an interface{} field, other values
+// are shared.
+func deepCopyValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case Node:
+		return DeepCopy(x)
+	case []ExprNode:
+		if x == nil {
+			return x
+		}
+		res := make([]ExprNode, len(x))
+		for i, e := range x {
+			if e != nil {
+				res[i] = DeepCopy(e).(ExprNode)
+			}
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,4604 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by clonegen. DO NOT EDIT.
+
+package ast
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/daiguadaidai/parser/auth"
+	"github.com/daiguadaidai/parser/model"
+)
+
+// DeepCopy returns a deep copy of the node. The copy shares nothing with the
+// node but the bound schema objects like TableName.TableInfo. The nodes
+// defined out of this package must implement NodeCloner.
+func DeepCopy(n Node) Node {
+	if n == nil {
+		return nil
+	}
+	switch x := n.(type) {
+	case *AdminStmt:
+		return x.Clone()
+	case *AggregateFuncExpr:
+		return x.Clone()
+	case *AlterDatabaseStmt:
+		return x.Clone()
+	case *AlterImportStmt:
+		return x.Clone()
+	case *AlterInstanceStmt:
+		return x.Clone()
+	case *AlterPlacementPolicyStmt:
+		return x.Clone()
+	case *AlterSequenceStmt:
+		return x.Clone()
+	case *AlterTableSpec:
+		return x.Clone()
+	case *AlterTableStmt:
+		return x.Clone()
+	case *AlterUserStmt:
+		return x.Clone()
+	case *AnalyzeTableStmt:
+		return x.Clone()
+	case *AsOfClause:
+		return x.Clone()
+	case *Assignment:
+		return x.Clone()
+	case *AttributesSpec:
+		return x.Clone()
+	case *BRIEStmt:
+		return x.Clone()
+	case *BeginStmt:
+		return x.Clone()
+	case *BetweenExpr:
+		return x.Clone()
+	case *BinaryOperationExpr:
+		return x.Clone()
+	case *BinlogStmt:
+		return x.Clone()
+	case *ByItem:
+		return x.Clone()
+	case *CallStmt:
+		return x.Clone()
+	case *CaseExpr:
+		return x.Clone()
+	case *ChangeStmt:
+		return x.Clone()
+	case *CleanupTableLockStmt:
+		return x.Clone()
+	case *ColumnDef:
+		return x.Clone()
+	case *ColumnName:
+		return x.Clone()
+	case *ColumnNameExpr:
+		return x.Clone()
+	case *ColumnNameOrUserVar:
+		return x.Clone()
+	case *ColumnOption:
+		return x.Clone()
+	case *ColumnPosition:
+		return x.Clone()
+	case *CommitStmt:
+		return x.Clone()
+	case *CommonTableExpression:
+		return x.Clone()
+	case *CompactTableStmt:
+		return x.Clone()
+	case *CompareSubqueryExpr:
+		return x.Clone()
+	case *Constraint:
+		return x.Clone()
+	case *CreateBindingStmt:
+		return x.Clone()
+	case *CreateDatabaseStmt:
+		return x.Clone()
+	case *CreateImportStmt:
+		return x.Clone()
+	case *CreateIndexStmt:
+		return x.Clone()
+	case *CreatePlacementPolicyStmt:
+		return x.Clone()
+	case *CreateSequenceStmt:
+		return x.Clone()
+	case *CreateStatisticsStmt:
+		return x.Clone()
+	case *CreateTableStmt:
+		return x.Clone()
+	case *CreateUserStmt:
+		return x.Clone()
+	case *CreateViewStmt:
+		return x.Clone()
+	case *DeallocateStmt:
+		return x.Clone()
+	case *DefaultExpr:
+		return x.Clone()
+	case *DeleteStmt:
+		return x.Clone()
+	case *DeleteTableList:
+		return x.Clone()
+	case *DoStmt:
+		return x.Clone()
+	case *DropBindingStmt:
+		return x.Clone()
+	case *DropDatabaseStmt:
+		return x.Clone()
+	case *DropImportStmt:
+		return x.Clone()
+	case *DropIndexStmt:
+		return x.Clone()
+	case *DropPlacementPolicyStmt:
+		return x.Clone()
+	case *DropSequenceStmt:
+		return x.Clone()
+	case *DropStatisticsStmt:
+		return x.Clone()
+	case *DropStatsStmt:
+		return x.Clone()
+	case *DropTableStmt:
+		return x.Clone()
+	case *DropUserStmt:
+		return x.Clone()
+	case *ExecuteStmt:
+		return x.Clone()
+	case *ExistsSubqueryExpr:
+		return x.Clone()
+	case *ExplainForStmt:
+		return x.Clone()
+	case *ExplainStmt:
+		return x.Clone()
+	case *FieldList:
+		return x.Clone()
+	case *FlashBackTableStmt:
+		return x.Clone()
+	case *FlushStmt:
+		return x.Clone()
+	case *FrameBound:
+		return x.Clone()
+	case *FrameClause:
+		return x.Clone()
+	case *FuncCallExpr:
+		return x.Clone()
+	case *FuncCastExpr:
+		return x.Clone()
+	case *GetFormatSelectorExpr:
+		return x.Clone()
+	case *GrantProxyStmt:
+		return x.Clone()
+	case *GrantRoleStmt:
+		return x.Clone()
+	case *GrantStmt:
+		return x.Clone()
+	case *GroupByClause:
+		return x.Clone()
+	case *HavingClause:
+		return x.Clone()
+	case *HelpStmt:
+		return x.Clone()
+	case *IndexAdviseStmt:
+		return x.Clone()
+	case *IndexLockAndAlgorithm:
+		return x.Clone()
+	case *IndexOption:
+		return x.Clone()
+	case *IndexPartSpecification:
+		return x.Clone()
+	case *InsertStmt:
+		return x.Clone()
+	case *IsNullExpr:
+		return x.Clone()
+	case *IsTruthExpr:
+		return x.Clone()
+	case *Join:
+		return x.Clone()
+	case *KillStmt:
+		return x.Clone()
+	case *Limit:
+		return x.Clone()
+	case *LoadDataStmt:
+		return x.Clone()
+	case *LoadStatsStmt:
+		return x.Clone()
+	case *LockTablesStmt:
+		return x.Clone()
+	case *MatchAgainst:
+		return x.Clone()
+	case *MaxValueExpr:
+		return x.Clone()
+	case *NonTransactionalDeleteStmt:
+		return x.Clone()
+	case *OnCondition:
+		return x.Clone()
+	case *OnDeleteOpt:
+		return x.Clone()
+	case *OnUpdateOpt:
+		return x.Clone()
+	case *OrderByClause:
+		return x.Clone()
+	case *ParenthesesExpr:
+		return x.Clone()
+	case *PartitionByClause:
+		return x.Clone()
+	case *PartitionOptions:
+		return x.Clone()
+	case *PatternInExpr:
+		return x.Clone()
+	case *PatternLikeExpr:
+		return x.Clone()
+	case *PatternRegexpExpr:
+		return x.Clone()
+	case *PlanReplayerStmt:
+		return x.Clone()
+	case *PositionExpr:
+		return x.Clone()
+	case *PrepareStmt:
+		return x.Clone()
+	case *PrivElem:
+		return x.Clone()
+	case *PurgeImportStmt:
+		return x.Clone()
+	case *RecoverTableStmt:
+		return x.Clone()
+	case *ReferenceDef:
+		return x.Clone()
+	case *ReleaseSavepointStmt:
+		return x.Clone()
+	case *RenameTableStmt:
+		return x.Clone()
+	case *RenameUserStmt:
+		return x.Clone()
+	case *RepairTableStmt:
+		return x.Clone()
+	case *RestartStmt:
+		return x.Clone()
+	case *ResumeImportStmt:
+		return x.Clone()
+	case *RevokeRoleStmt:
+		return x.Clone()
+	case *RevokeStmt:
+		return x.Clone()
+	case *RollbackStmt:
+		return x.Clone()
+	case *RowExpr:
+		return x.Clone()
+	case *SavepointStmt:
+		return x.Clone()
+	case *SelectField:
+		return x.Clone()
+	case *SelectIntoOption:
+		return x.Clone()
+	case *SelectStmt:
+		return x.Clone()
+	case *SetBindingStmt:
+		return x.Clone()
+	case *SetCollationExpr:
+		return x.Clone()
+	case *SetConfigStmt:
+		return x.Clone()
+	case *SetDefaultRoleStmt:
+		return x.Clone()
+	case *SetOprSelectList:
+		return x.Clone()
+	case *SetOprStmt:
+		return x.Clone()
+	case *SetPwdStmt:
+		return x.Clone()
+	case *SetRoleStmt:
+		return x.Clone()
+	case *SetSessionStatesStmt:
+		return x.Clone()
+	case *SetStmt:
+		return x.Clone()
+	case *ShowImportStmt:
+		return x.Clone()
+	case *ShowStmt:
+		return x.Clone()
+	case *ShutdownStmt:
+		return x.Clone()
+	case *SplitRegionStmt:
+		return x.Clone()
+	case *StatsOptionsSpec:
+		return x.Clone()
+	case *StopImportStmt:
+		return x.Clone()
+	case *SubqueryExpr:
+		return x.Clone()
+	case *TableName:
+		return x.Clone()
+	case *TableNameExpr:
+		return x.Clone()
+	case *TableOptimizerHint:
+		return x.Clone()
+	case *TableRefsClause:
+		return x.Clone()
+	case *TableSample:
+		return x.Clone()
+	case *TableSource:
+		return x.Clone()
+	case *TableToTable:
+		return x.Clone()
+	case *TimeUnitExpr:
+		return x.Clone()
+	case *TraceStmt:
+		return x.Clone()
+	case *TrimDirectionExpr:
+		return x.Clone()
+	case *TruncateTableStmt:
+		return x.Clone()
+	case *UnaryOperationExpr:
+		return x.Clone()
+	case *UnlockTablesStmt:
+		return x.Clone()
+	case *UpdateStmt:
+		return x.Clone()
+	case *UseStmt:
+		return x.Clone()
+	case *UserToUser:
+		return x.Clone()
+	case *ValuesExpr:
+		return x.Clone()
+	case *VariableAssignment:
+		return x.Clone()
+	case *VariableExpr:
+		return x.Clone()
+	case *WhenClause:
+		return x.Clone()
+	case *WildCardField:
+		return x.Clone()
+	case *WindowFuncExpr:
+		return x.Clone()
+	case *WindowSpec:
+		return x.Clone()
+	case *WithClause:
+		return x.Clone()
+	case NodeCloner:
+		return x.CloneNode()
+	}
+	panic(fmt.Sprintf("ast: DeepCopy of unknown node type %T", n))
+}
+
+// Clone returns a deep copy of the node.
+func (n *AdminStmt) Clone() *AdminStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AdminStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AggregateFuncExpr) Clone() *AggregateFuncExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(AggregateFuncExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterDatabaseStmt) Clone() *AlterDatabaseStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterDatabaseStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterImportStmt) Clone() *AlterImportStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterImportStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterInstanceStmt) Clone() *AlterInstanceStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterInstanceStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterPlacementPolicyStmt) Clone() *AlterPlacementPolicyStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterPlacementPolicyStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterSequenceStmt) Clone() *AlterSequenceStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterSequenceStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterTableSpec) Clone() *AlterTableSpec {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterTableSpec)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterTableStmt) Clone() *AlterTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AlterUserStmt) Clone() *AlterUserStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AlterUserStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AnalyzeTableStmt) Clone() *AnalyzeTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(AnalyzeTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AsOfClause) Clone() *AsOfClause {
+	if n == nil {
+		return nil
+	}
+	c := new(AsOfClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *Assignment) Clone() *Assignment {
+	if n == nil {
+		return nil
+	}
+	c := new(Assignment)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *AttributesSpec) Clone() *AttributesSpec {
+	if n == nil {
+		return nil
+	}
+	c := new(AttributesSpec)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *BRIEStmt) Clone() *BRIEStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(BRIEStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *BeginStmt) Clone() *BeginStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(BeginStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *BetweenExpr) Clone() *BetweenExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(BetweenExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *BinaryOperationExpr) Clone() *BinaryOperationExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(BinaryOperationExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *BinlogStmt) Clone() *BinlogStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(BinlogStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ByItem) Clone() *ByItem {
+	if n == nil {
+		return nil
+	}
+	c := new(ByItem)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CallStmt) Clone() *CallStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CallStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CaseExpr) Clone() *CaseExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(CaseExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ChangeStmt) Clone() *ChangeStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ChangeStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CleanupTableLockStmt) Clone() *CleanupTableLockStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CleanupTableLockStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ColumnDef) Clone() *ColumnDef {
+	if n == nil {
+		return nil
+	}
+	c := new(ColumnDef)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ColumnName) Clone() *ColumnName {
+	if n == nil {
+		return nil
+	}
+	c := new(ColumnName)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ColumnNameExpr) Clone() *ColumnNameExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(ColumnNameExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ColumnNameOrUserVar) Clone() *ColumnNameOrUserVar {
+	if n == nil {
+		return nil
+	}
+	c := new(ColumnNameOrUserVar)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ColumnOption) Clone() *ColumnOption {
+	if n == nil {
+		return nil
+	}
+	c := new(ColumnOption)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ColumnPosition) Clone() *ColumnPosition {
+	if n == nil {
+		return nil
+	}
+	c := new(ColumnPosition)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CommitStmt) Clone() *CommitStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CommitStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CommonTableExpression) Clone() *CommonTableExpression {
+	if n == nil {
+		return nil
+	}
+	c := new(CommonTableExpression)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CompactTableStmt) Clone() *CompactTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CompactTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CompareSubqueryExpr) Clone() *CompareSubqueryExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(CompareSubqueryExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *Constraint) Clone() *Constraint {
+	if n == nil {
+		return nil
+	}
+	c := new(Constraint)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateBindingStmt) Clone() *CreateBindingStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateBindingStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateDatabaseStmt) Clone() *CreateDatabaseStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateDatabaseStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateImportStmt) Clone() *CreateImportStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateImportStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateIndexStmt) Clone() *CreateIndexStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateIndexStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreatePlacementPolicyStmt) Clone() *CreatePlacementPolicyStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreatePlacementPolicyStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateSequenceStmt) Clone() *CreateSequenceStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateSequenceStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateStatisticsStmt) Clone() *CreateStatisticsStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateStatisticsStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateTableStmt) Clone() *CreateTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateUserStmt) Clone() *CreateUserStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateUserStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *CreateViewStmt) Clone() *CreateViewStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(CreateViewStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DeallocateStmt) Clone() *DeallocateStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DeallocateStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DefaultExpr) Clone() *DefaultExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(DefaultExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DeleteStmt) Clone() *DeleteStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DeleteStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DeleteTableList) Clone() *DeleteTableList {
+	if n == nil {
+		return nil
+	}
+	c := new(DeleteTableList)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DoStmt) Clone() *DoStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DoStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropBindingStmt) Clone() *DropBindingStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropBindingStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropDatabaseStmt) Clone() *DropDatabaseStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropDatabaseStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropImportStmt) Clone() *DropImportStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropImportStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropIndexStmt) Clone() *DropIndexStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropIndexStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropPlacementPolicyStmt) Clone() *DropPlacementPolicyStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropPlacementPolicyStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropSequenceStmt) Clone() *DropSequenceStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropSequenceStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropStatisticsStmt) Clone() *DropStatisticsStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropStatisticsStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropStatsStmt) Clone() *DropStatsStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropStatsStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropTableStmt) Clone() *DropTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *DropUserStmt) Clone() *DropUserStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(DropUserStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ExecuteStmt) Clone() *ExecuteStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ExecuteStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ExistsSubqueryExpr) Clone() *ExistsSubqueryExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(ExistsSubqueryExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ExplainForStmt) Clone() *ExplainForStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ExplainForStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ExplainStmt) Clone() *ExplainStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ExplainStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *FieldList) Clone() *FieldList {
+	if n == nil {
+		return nil
+	}
+	c := new(FieldList)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *FlashBackTableStmt) Clone() *FlashBackTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(FlashBackTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *FlushStmt) Clone() *FlushStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(FlushStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *FrameBound) Clone() *FrameBound {
+	if n == nil {
+		return nil
+	}
+	c := new(FrameBound)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *FrameClause) Clone() *FrameClause {
+	if n == nil {
+		return nil
+	}
+	c := new(FrameClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *FuncCallExpr) Clone() *FuncCallExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(FuncCallExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *FuncCastExpr) Clone() *FuncCastExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(FuncCastExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *GetFormatSelectorExpr) Clone() *GetFormatSelectorExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(GetFormatSelectorExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *GrantProxyStmt) Clone() *GrantProxyStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(GrantProxyStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *GrantRoleStmt) Clone() *GrantRoleStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(GrantRoleStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *GrantStmt) Clone() *GrantStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(GrantStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *GroupByClause) Clone() *GroupByClause {
+	if n == nil {
+		return nil
+	}
+	c := new(GroupByClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *HavingClause) Clone() *HavingClause {
+	if n == nil {
+		return nil
+	}
+	c := new(HavingClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *HelpStmt) Clone() *HelpStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(HelpStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *IndexAdviseStmt) Clone() *IndexAdviseStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(IndexAdviseStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *IndexLockAndAlgorithm) Clone() *IndexLockAndAlgorithm {
+	if n == nil {
+		return nil
+	}
+	c := new(IndexLockAndAlgorithm)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *IndexOption) Clone() *IndexOption {
+	if n == nil {
+		return nil
+	}
+	c := new(IndexOption)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *IndexPartSpecification) Clone() *IndexPartSpecification {
+	if n == nil {
+		return nil
+	}
+	c := new(IndexPartSpecification)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *InsertStmt) Clone() *InsertStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(InsertStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *IsNullExpr) Clone() *IsNullExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(IsNullExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *IsTruthExpr) Clone() *IsTruthExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(IsTruthExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *Join) Clone() *Join {
+	if n == nil {
+		return nil
+	}
+	c := new(Join)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *KillStmt) Clone() *KillStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(KillStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *Limit) Clone() *Limit {
+	if n == nil {
+		return nil
+	}
+	c := new(Limit)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *LoadDataStmt) Clone() *LoadDataStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(LoadDataStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *LoadStatsStmt) Clone() *LoadStatsStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(LoadStatsStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *LockTablesStmt) Clone() *LockTablesStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(LockTablesStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *MatchAgainst) Clone() *MatchAgainst {
+	if n == nil {
+		return nil
+	}
+	c := new(MatchAgainst)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *MaxValueExpr) Clone() *MaxValueExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(MaxValueExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *NonTransactionalDeleteStmt) Clone() *NonTransactionalDeleteStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(NonTransactionalDeleteStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *OnCondition) Clone() *OnCondition {
+	if n == nil {
+		return nil
+	}
+	c := new(OnCondition)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *OnDeleteOpt) Clone() *OnDeleteOpt {
+	if n == nil {
+		return nil
+	}
+	c := new(OnDeleteOpt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *OnUpdateOpt) Clone() *OnUpdateOpt {
+	if n == nil {
+		return nil
+	}
+	c := new(OnUpdateOpt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *OrderByClause) Clone() *OrderByClause {
+	if n == nil {
+		return nil
+	}
+	c := new(OrderByClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ParenthesesExpr) Clone() *ParenthesesExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(ParenthesesExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PartitionByClause) Clone() *PartitionByClause {
+	if n == nil {
+		return nil
+	}
+	c := new(PartitionByClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PartitionOptions) Clone() *PartitionOptions {
+	if n == nil {
+		return nil
+	}
+	c := new(PartitionOptions)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PatternInExpr) Clone() *PatternInExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(PatternInExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PatternLikeExpr) Clone() *PatternLikeExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(PatternLikeExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PatternRegexpExpr) Clone() *PatternRegexpExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(PatternRegexpExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PlanReplayerStmt) Clone() *PlanReplayerStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(PlanReplayerStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PositionExpr) Clone() *PositionExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(PositionExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PrepareStmt) Clone() *PrepareStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(PrepareStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PrivElem) Clone() *PrivElem {
+	if n == nil {
+		return nil
+	}
+	c := new(PrivElem)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *PurgeImportStmt) Clone() *PurgeImportStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(PurgeImportStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RecoverTableStmt) Clone() *RecoverTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RecoverTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ReferenceDef) Clone() *ReferenceDef {
+	if n == nil {
+		return nil
+	}
+	c := new(ReferenceDef)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ReleaseSavepointStmt) Clone() *ReleaseSavepointStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ReleaseSavepointStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RenameTableStmt) Clone() *RenameTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RenameTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RenameUserStmt) Clone() *RenameUserStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RenameUserStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RepairTableStmt) Clone() *RepairTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RepairTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RestartStmt) Clone() *RestartStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RestartStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ResumeImportStmt) Clone() *ResumeImportStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ResumeImportStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RevokeRoleStmt) Clone() *RevokeRoleStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RevokeRoleStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RevokeStmt) Clone() *RevokeStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RevokeStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RollbackStmt) Clone() *RollbackStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(RollbackStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *RowExpr) Clone() *RowExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(RowExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SavepointStmt) Clone() *SavepointStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SavepointStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SelectField) Clone() *SelectField {
+	if n == nil {
+		return nil
+	}
+	c := new(SelectField)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SelectIntoOption) Clone() *SelectIntoOption {
+	if n == nil {
+		return nil
+	}
+	c := new(SelectIntoOption)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SelectStmt) Clone() *SelectStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SelectStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetBindingStmt) Clone() *SetBindingStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetBindingStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetCollationExpr) Clone() *SetCollationExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(SetCollationExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetConfigStmt) Clone() *SetConfigStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetConfigStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetDefaultRoleStmt) Clone() *SetDefaultRoleStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetDefaultRoleStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetOprSelectList) Clone() *SetOprSelectList {
+	if n == nil {
+		return nil
+	}
+	c := new(SetOprSelectList)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetOprStmt) Clone() *SetOprStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetOprStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetPwdStmt) Clone() *SetPwdStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetPwdStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetRoleStmt) Clone() *SetRoleStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetRoleStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetSessionStatesStmt) Clone() *SetSessionStatesStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetSessionStatesStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SetStmt) Clone() *SetStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SetStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ShowImportStmt) Clone() *ShowImportStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ShowImportStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ShowStmt) Clone() *ShowStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ShowStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ShutdownStmt) Clone() *ShutdownStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(ShutdownStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SplitRegionStmt) Clone() *SplitRegionStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(SplitRegionStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *StatsOptionsSpec) Clone() *StatsOptionsSpec {
+	if n == nil {
+		return nil
+	}
+	c := new(StatsOptionsSpec)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *StopImportStmt) Clone() *StopImportStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(StopImportStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *SubqueryExpr) Clone() *SubqueryExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(SubqueryExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TableName) Clone() *TableName {
+	if n == nil {
+		return nil
+	}
+	c := new(TableName)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TableNameExpr) Clone() *TableNameExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(TableNameExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TableOptimizerHint) Clone() *TableOptimizerHint {
+	if n == nil {
+		return nil
+	}
+	c := new(TableOptimizerHint)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TableRefsClause) Clone() *TableRefsClause {
+	if n == nil {
+		return nil
+	}
+	c := new(TableRefsClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TableSample) Clone() *TableSample {
+	if n == nil {
+		return nil
+	}
+	c := new(TableSample)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TableSource) Clone() *TableSource {
+	if n == nil {
+		return nil
+	}
+	c := new(TableSource)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TableToTable) Clone() *TableToTable {
+	if n == nil {
+		return nil
+	}
+	c := new(TableToTable)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TimeUnitExpr) Clone() *TimeUnitExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(TimeUnitExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TraceStmt) Clone() *TraceStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(TraceStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TrimDirectionExpr) Clone() *TrimDirectionExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(TrimDirectionExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *TruncateTableStmt) Clone() *TruncateTableStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(TruncateTableStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *UnaryOperationExpr) Clone() *UnaryOperationExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(UnaryOperationExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *UnlockTablesStmt) Clone() *UnlockTablesStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(UnlockTablesStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *UpdateStmt) Clone() *UpdateStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(UpdateStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *UseStmt) Clone() *UseStmt {
+	if n == nil {
+		return nil
+	}
+	c := new(UseStmt)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *UserToUser) Clone() *UserToUser {
+	if n == nil {
+		return nil
+	}
+	c := new(UserToUser)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *ValuesExpr) Clone() *ValuesExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(ValuesExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *VariableAssignment) Clone() *VariableAssignment {
+	if n == nil {
+		return nil
+	}
+	c := new(VariableAssignment)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *VariableExpr) Clone() *VariableExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(VariableExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *WhenClause) Clone() *WhenClause {
+	if n == nil {
+		return nil
+	}
+	c := new(WhenClause)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *WildCardField) Clone() *WildCardField {
+	if n == nil {
+		return nil
+	}
+	c := new(WildCardField)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *WindowFuncExpr) Clone() *WindowFuncExpr {
+	if n == nil {
+		return nil
+	}
+	c := new(WindowFuncExpr)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *WindowSpec) Clone() *WindowSpec {
+	if n == nil {
+		return nil
+	}
+	c := new(WindowSpec)
+	n.copyTo(c)
+	return c
+}
+
+// Clone returns a deep copy of the node.
+func (n *WithClause) Clone() *WithClause {
+	if n == nil {
+		return nil
+	}
+	c := new(WithClause)
+	n.copyTo(c)
+	return c
+}
+
+func (n *AdminStmt) copyTo(c *AdminStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Tables != nil {
+		c.Tables = make([]*TableName, len(n.Tables))
+		for i0 := range n.Tables {
+			c.Tables[i0] = n.Tables[i0].Clone()
+		}
+	}
+	if n.JobIDs != nil {
+		c.JobIDs = append([]int64(nil), n.JobIDs...)
+	}
+	if n.HandleRanges != nil {
+		c.HandleRanges = make([]HandleRange, len(n.HandleRanges))
+		for i0 := range n.HandleRanges {
+			n.HandleRanges[i0].copyTo(&c.HandleRanges[i0])
+		}
+	}
+	if n.ShowSlow != nil {
+		c.ShowSlow = new(ShowSlow)
+		n.ShowSlow.copyTo(c.ShowSlow)
+	}
+	if n.Plugins != nil {
+		c.Plugins = append([]string(nil), n.Plugins...)
+	}
+	if n.Where != nil {
+		c.Where = DeepCopy(n.Where).(ExprNode)
+	}
+	n.LimitSimple.copyTo(&c.LimitSimple)
+}
+
+func (n *AggregateFuncExpr) copyTo(c *AggregateFuncExpr) {
+	*c = *n
+	n.funcNode.copyTo(&c.funcNode)
+	if n.Args != nil {
+		c.Args = make([]ExprNode, len(n.Args))
+		for i0 := range n.Args {
+			if n.Args[i0] != nil {
+				c.Args[i0] = DeepCopy(n.Args[i0]).(ExprNode)
+			}
+		}
+	}
+	c.Order = n.Order.Clone()
+}
+
+func (n *AlterDatabaseStmt) copyTo(c *AlterDatabaseStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.Options != nil {
+		c.Options = make([]*DatabaseOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(DatabaseOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+}
+
+func (n *AlterImportStmt) copyTo(c *AlterImportStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Options != nil {
+		c.Options = make([]*BRIEOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(BRIEOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+	if n.Truncate != nil {
+		c.Truncate = new(ImportTruncate)
+		n.Truncate.copyTo(c.Truncate)
+	}
+}
+
+func (n *AlterInstanceStmt) copyTo(c *AlterInstanceStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *AlterPlacementPolicyStmt) copyTo(c *AlterPlacementPolicyStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.PlacementOptions != nil {
+		c.PlacementOptions = make([]*PlacementOption, len(n.PlacementOptions))
+		for i0 := range n.PlacementOptions {
+			if n.PlacementOptions[i0] != nil {
+				c.PlacementOptions[i0] = new(PlacementOption)
+				n.PlacementOptions[i0].copyTo(c.PlacementOptions[i0])
+			}
+		}
+	}
+}
+
+func (n *AlterSequenceStmt) copyTo(c *AlterSequenceStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Name = n.Name.Clone()
+	if n.SeqOptions != nil {
+		c.SeqOptions = make([]*SequenceOption, len(n.SeqOptions))
+		for i0 := range n.SeqOptions {
+			if n.SeqOptions[i0] != nil {
+				c.SeqOptions[i0] = new(SequenceOption)
+				n.SeqOptions[i0].copyTo(c.SeqOptions[i0])
+			}
+		}
+	}
+}
+
+func (n *AlterTableSpec) copyTo(c *AlterTableSpec) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Constraint = n.Constraint.Clone()
+	if n.Options != nil {
+		c.Options = make([]*TableOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(TableOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+	if n.OrderByList != nil {
+		c.OrderByList = make([]*AlterOrderItem, len(n.OrderByList))
+		for i0 := range n.OrderByList {
+			if n.OrderByList[i0] != nil {
+				c.OrderByList[i0] = new(AlterOrderItem)
+				n.OrderByList[i0].copyTo(c.OrderByList[i0])
+			}
+		}
+	}
+	c.NewTable = n.NewTable.Clone()
+	if n.NewColumns != nil {
+		c.NewColumns = make([]*ColumnDef, len(n.NewColumns))
+		for i0 := range n.NewColumns {
+			c.NewColumns[i0] = n.NewColumns[i0].Clone()
+		}
+	}
+	if n.NewConstraints != nil {
+		c.NewConstraints = make([]*Constraint, len(n.NewConstraints))
+		for i0 := range n.NewConstraints {
+			c.NewConstraints[i0] = n.NewConstraints[i0].Clone()
+		}
+	}
+	c.OldColumnName = n.OldColumnName.Clone()
+	c.NewColumnName = n.NewColumnName.Clone()
+	c.Position = n.Position.Clone()
+	c.Partition = n.Partition.Clone()
+	if n.PartitionNames != nil {
+		c.PartitionNames = append([]model.CIStr(nil), n.PartitionNames...)
+	}
+	if n.PartDefinitions != nil {
+		c.PartDefinitions = make([]*PartitionDefinition, len(n.PartDefinitions))
+		for i0 := range n.PartDefinitions {
+			if n.PartDefinitions[i0] != nil {
+				c.PartDefinitions[i0] = new(PartitionDefinition)
+				n.PartDefinitions[i0].copyTo(c.PartDefinitions[i0])
+			}
+		}
+	}
+	if n.TiFlashReplica != nil {
+		c.TiFlashReplica = new(TiFlashReplicaSpec)
+		n.TiFlashReplica.copyTo(c.TiFlashReplica)
+	}
+	if n.Statistics != nil {
+		c.Statistics = new(StatisticsSpec)
+		n.Statistics.copyTo(c.Statistics)
+	}
+	c.AttributesSpec = n.AttributesSpec.Clone()
+	c.StatsOptionsSpec = n.StatsOptionsSpec.Clone()
+}
+
+func (n *AlterTableStmt) copyTo(c *AlterTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+	if n.Specs != nil {
+		c.Specs = make([]*AlterTableSpec, len(n.Specs))
+		for i0 := range n.Specs {
+			c.Specs[i0] = n.Specs[i0].Clone()
+		}
+	}
+}
+
+func (n *AlterUserStmt) copyTo(c *AlterUserStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.CurrentAuth != nil {
+		c.CurrentAuth = new(AuthOption)
+		n.CurrentAuth.copyTo(c.CurrentAuth)
+	}
+	if n.Specs != nil {
+		c.Specs = make([]*UserSpec, len(n.Specs))
+		for i0 := range n.Specs {
+			if n.Specs[i0] != nil {
+				c.Specs[i0] = new(UserSpec)
+				n.Specs[i0].copyTo(c.Specs[i0])
+			}
+		}
+	}
+	if n.TLSOptions != nil {
+		c.TLSOptions = make([]*TLSOption, len(n.TLSOptions))
+		for i0 := range n.TLSOptions {
+			if n.TLSOptions[i0] != nil {
+				c.TLSOptions[i0] = new(TLSOption)
+				n.TLSOptions[i0].copyTo(c.TLSOptions[i0])
+			}
+		}
+	}
+	if n.ResourceOptions != nil {
+		c.ResourceOptions = make([]*ResourceOption, len(n.ResourceOptions))
+		for i0 := range n.ResourceOptions {
+			if n.ResourceOptions[i0] != nil {
+				c.ResourceOptions[i0] = new(ResourceOption)
+				n.ResourceOptions[i0].copyTo(c.ResourceOptions[i0])
+			}
+		}
+	}
+	if n.PasswordOrLockOptions != nil {
+		c.PasswordOrLockOptions = make([]*PasswordOrLockOption, len(n.PasswordOrLockOptions))
+		for i0 := range n.PasswordOrLockOptions {
+			if n.PasswordOrLockOptions[i0] != nil {
+				c.PasswordOrLockOptions[i0] = new(PasswordOrLockOption)
+				n.PasswordOrLockOptions[i0].copyTo(c.PasswordOrLockOptions[i0])
+			}
+		}
+	}
+}
+
+func (n *AnalyzeTableStmt) copyTo(c *AnalyzeTableStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.TableNames != nil {
+		c.TableNames = make([]*TableName, len(n.TableNames))
+		for i0 := range n.TableNames {
+			c.TableNames[i0] = n.TableNames[i0].Clone()
+		}
+	}
+	if n.PartitionNames != nil {
+		c.PartitionNames = append([]model.CIStr(nil), n.PartitionNames...)
+	}
+	if n.IndexNames != nil {
+		c.IndexNames = append([]model.CIStr(nil), n.IndexNames...)
+	}
+	if n.AnalyzeOpts != nil {
+		c.AnalyzeOpts = make([]AnalyzeOpt, len(n.AnalyzeOpts))
+		for i0 := range n.AnalyzeOpts {
+			n.AnalyzeOpts[i0].copyTo(&c.AnalyzeOpts[i0])
+		}
+	}
+	if n.ColumnNames != nil {
+		c.ColumnNames = append([]model.CIStr(nil), n.ColumnNames...)
+	}
+}
+
+func (n *AsOfClause) copyTo(c *AsOfClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.TsExpr != nil {
+		c.TsExpr = DeepCopy(n.TsExpr).(ExprNode)
+	}
+}
+
+func (n *Assignment) copyTo(c *Assignment) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Column = n.Column.Clone()
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *AttributesSpec) copyTo(c *AttributesSpec) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *BRIEStmt) copyTo(c *BRIEStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Schemas != nil {
+		c.Schemas = append([]string(nil), n.Schemas...)
+	}
+	if n.Tables != nil {
+		c.Tables = make([]*TableName, len(n.Tables))
+		for i0 := range n.Tables {
+			c.Tables[i0] = n.Tables[i0].Clone()
+		}
+	}
+	if n.Options != nil {
+		c.Options = make([]*BRIEOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(BRIEOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+}
+
+func (n *BeginStmt) copyTo(c *BeginStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	c.AsOf = n.AsOf.Clone()
+}
+
+func (n *BetweenExpr) copyTo(c *BetweenExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.Left != nil {
+		c.Left = DeepCopy(n.Left).(ExprNode)
+	}
+	if n.Right != nil {
+		c.Right = DeepCopy(n.Right).(ExprNode)
+	}
+}
+
+func (n *BinaryOperationExpr) copyTo(c *BinaryOperationExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.L != nil {
+		c.L = DeepCopy(n.L).(ExprNode)
+	}
+	if n.R != nil {
+		c.R = DeepCopy(n.R).(ExprNode)
+	}
+}
+
+func (n *BinlogStmt) copyTo(c *BinlogStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *ByItem) copyTo(c *ByItem) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *CallStmt) copyTo(c *CallStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.Procedure = n.Procedure.Clone()
+}
+
+func (n *CaseExpr) copyTo(c *CaseExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Value != nil {
+		c.Value = DeepCopy(n.Value).(ExprNode)
+	}
+	if n.WhenClauses != nil {
+		c.WhenClauses = make([]*WhenClause, len(n.WhenClauses))
+		for i0 := range n.WhenClauses {
+			c.WhenClauses[i0] = n.WhenClauses[i0].Clone()
+		}
+	}
+	if n.ElseClause != nil {
+		c.ElseClause = DeepCopy(n.ElseClause).(ExprNode)
+	}
+}
+
+func (n *ChangeStmt) copyTo(c *ChangeStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *CleanupTableLockStmt) copyTo(c *CleanupTableLockStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.Tables != nil {
+		c.Tables = make([]*TableName, len(n.Tables))
+		for i0 := range n.Tables {
+			c.Tables[i0] = n.Tables[i0].Clone()
+		}
+	}
+}
+
+func (n *ColumnDef) copyTo(c *ColumnDef) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Name = n.Name.Clone()
+	if n.Tp != nil {
+		c.Tp = n.Tp.Clone()
+	}
+	if n.Options != nil {
+		c.Options = make([]*ColumnOption, len(n.Options))
+		for i0 := range n.Options {
+			c.Options[i0] = n.Options[i0].Clone()
+		}
+	}
+}
+
+func (n *ColumnName) copyTo(c *ColumnName) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *ColumnNameExpr) copyTo(c *ColumnNameExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	c.Name = n.Name.Clone()
+	if n.Refer != nil {
+		c.Refer = new(ResultField)
+		n.Refer.copyTo(c.Refer)
+	}
+}
+
+func (n *ColumnNameOrUserVar) copyTo(c *ColumnNameOrUserVar) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.ColumnName = n.ColumnName.Clone()
+	c.UserVar = n.UserVar.Clone()
+}
+
+func (n *ColumnOption) copyTo(c *ColumnOption) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	c.Refer = n.Refer.Clone()
+}
+
+func (n *ColumnPosition) copyTo(c *ColumnPosition) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.RelativeColumn = n.RelativeColumn.Clone()
+}
+
+func (n *CommitStmt) copyTo(c *CommitStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *CommonTableExpression) copyTo(c *CommonTableExpression) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Query = n.Query.Clone()
+	if n.ColNameList != nil {
+		c.ColNameList = append([]model.CIStr(nil), n.ColNameList...)
+	}
+}
+
+func (n *CompactTableStmt) copyTo(c *CompactTableStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	c.Table = n.Table.Clone()
+}
+
+func (n *CompareSubqueryExpr) copyTo(c *CompareSubqueryExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.L != nil {
+		c.L = DeepCopy(n.L).(ExprNode)
+	}
+	if n.R != nil {
+		c.R = DeepCopy(n.R).(ExprNode)
+	}
+}
+
+func (n *Constraint) copyTo(c *Constraint) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Keys != nil {
+		c.Keys = make([]*IndexPartSpecification, len(n.Keys))
+		for i0 := range n.Keys {
+			c.Keys[i0] = n.Keys[i0].Clone()
+		}
+	}
+	c.Refer = n.Refer.Clone()
+	c.Option = n.Option.Clone()
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *CreateBindingStmt) copyTo(c *CreateBindingStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.OriginNode != nil {
+		c.OriginNode = DeepCopy(n.OriginNode).(StmtNode)
+	}
+	if n.HintedNode != nil {
+		c.HintedNode = DeepCopy(n.HintedNode).(StmtNode)
+	}
+}
+
+func (n *CreateDatabaseStmt) copyTo(c *CreateDatabaseStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.Options != nil {
+		c.Options = make([]*DatabaseOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(DatabaseOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+}
+
+func (n *CreateImportStmt) copyTo(c *CreateImportStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Options != nil {
+		c.Options = make([]*BRIEOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(BRIEOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+}
+
+func (n *CreateIndexStmt) copyTo(c *CreateIndexStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+	if n.IndexPartSpecifications != nil {
+		c.IndexPartSpecifications = make([]*IndexPartSpecification, len(n.IndexPartSpecifications))
+		for i0 := range n.IndexPartSpecifications {
+			c.IndexPartSpecifications[i0] = n.IndexPartSpecifications[i0].Clone()
+		}
+	}
+	c.IndexOption = n.IndexOption.Clone()
+	c.LockAlg = n.LockAlg.Clone()
+}
+
+func (n *CreatePlacementPolicyStmt) copyTo(c *CreatePlacementPolicyStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.PlacementOptions != nil {
+		c.PlacementOptions = make([]*PlacementOption, len(n.PlacementOptions))
+		for i0 := range n.PlacementOptions {
+			if n.PlacementOptions[i0] != nil {
+				c.PlacementOptions[i0] = new(PlacementOption)
+				n.PlacementOptions[i0].copyTo(c.PlacementOptions[i0])
+			}
+		}
+	}
+}
+
+func (n *CreateSequenceStmt) copyTo(c *CreateSequenceStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Name = n.Name.Clone()
+	if n.SeqOptions != nil {
+		c.SeqOptions = make([]*SequenceOption, len(n.SeqOptions))
+		for i0 := range n.SeqOptions {
+			if n.SeqOptions[i0] != nil {
+				c.SeqOptions[i0] = new(SequenceOption)
+				n.SeqOptions[i0].copyTo(c.SeqOptions[i0])
+			}
+		}
+	}
+	if n.TblOptions != nil {
+		c.TblOptions = make([]*TableOption, len(n.TblOptions))
+		for i0 := range n.TblOptions {
+			if n.TblOptions[i0] != nil {
+				c.TblOptions[i0] = new(TableOption)
+				n.TblOptions[i0].copyTo(c.TblOptions[i0])
+			}
+		}
+	}
+}
+
+func (n *CreateStatisticsStmt) copyTo(c *CreateStatisticsStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	c.Table = n.Table.Clone()
+	if n.Columns != nil {
+		c.Columns = make([]*ColumnName, len(n.Columns))
+		for i0 := range n.Columns {
+			c.Columns[i0] = n.Columns[i0].Clone()
+		}
+	}
+}
+
+func (n *CreateTableStmt) copyTo(c *CreateTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+	c.ReferTable = n.ReferTable.Clone()
+	if n.Cols != nil {
+		c.Cols = make([]*ColumnDef, len(n.Cols))
+		for i0 := range n.Cols {
+			c.Cols[i0] = n.Cols[i0].Clone()
+		}
+	}
+	if n.Constraints != nil {
+		c.Constraints = make([]*Constraint, len(n.Constraints))
+		for i0 := range n.Constraints {
+			c.Constraints[i0] = n.Constraints[i0].Clone()
+		}
+	}
+	if n.Options != nil {
+		c.Options = make([]*TableOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(TableOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+	c.Partition = n.Partition.Clone()
+	if n.Select != nil {
+		c.Select = DeepCopy(n.Select).(ResultSetNode)
+	}
+}
+
+func (n *CreateUserStmt) copyTo(c *CreateUserStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Specs != nil {
+		c.Specs = make([]*UserSpec, len(n.Specs))
+		for i0 := range n.Specs {
+			if n.Specs[i0] != nil {
+				c.Specs[i0] = new(UserSpec)
+				n.Specs[i0].copyTo(c.Specs[i0])
+			}
+		}
+	}
+	if n.TLSOptions != nil {
+		c.TLSOptions = make([]*TLSOption, len(n.TLSOptions))
+		for i0 := range n.TLSOptions {
+			if n.TLSOptions[i0] != nil {
+				c.TLSOptions[i0] = new(TLSOption)
+				n.TLSOptions[i0].copyTo(c.TLSOptions[i0])
+			}
+		}
+	}
+	if n.ResourceOptions != nil {
+		c.ResourceOptions = make([]*ResourceOption, len(n.ResourceOptions))
+		for i0 := range n.ResourceOptions {
+			if n.ResourceOptions[i0] != nil {
+				c.ResourceOptions[i0] = new(ResourceOption)
+				n.ResourceOptions[i0].copyTo(c.ResourceOptions[i0])
+			}
+		}
+	}
+	if n.PasswordOrLockOptions != nil {
+		c.PasswordOrLockOptions = make([]*PasswordOrLockOption, len(n.PasswordOrLockOptions))
+		for i0 := range n.PasswordOrLockOptions {
+			if n.PasswordOrLockOptions[i0] != nil {
+				c.PasswordOrLockOptions[i0] = new(PasswordOrLockOption)
+				n.PasswordOrLockOptions[i0].copyTo(c.PasswordOrLockOptions[i0])
+			}
+		}
+	}
+}
+
+func (n *CreateViewStmt) copyTo(c *CreateViewStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.ViewName = n.ViewName.Clone()
+	if n.Cols != nil {
+		c.Cols = append([]model.CIStr(nil), n.Cols...)
+	}
+	if n.Select != nil {
+		c.Select = DeepCopy(n.Select).(StmtNode)
+	}
+	if n.SchemaCols != nil {
+		c.SchemaCols = append([]model.CIStr(nil), n.SchemaCols...)
+	}
+	if n.Definer != nil {
+		v0 := *n.Definer
+		c.Definer = &v0
+	}
+}
+
+func (n *DeallocateStmt) copyTo(c *DeallocateStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *DefaultExpr) copyTo(c *DefaultExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	c.Name = n.Name.Clone()
+}
+
+func (n *DeleteStmt) copyTo(c *DeleteStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.TableRefs = n.TableRefs.Clone()
+	c.Tables = n.Tables.Clone()
+	if n.Where != nil {
+		c.Where = DeepCopy(n.Where).(ExprNode)
+	}
+	c.Order = n.Order.Clone()
+	c.Limit = n.Limit.Clone()
+	if n.TableHints != nil {
+		c.TableHints = make([]*TableOptimizerHint, len(n.TableHints))
+		for i0 := range n.TableHints {
+			c.TableHints[i0] = n.TableHints[i0].Clone()
+		}
+	}
+	c.With = n.With.Clone()
+}
+
+func (n *DeleteTableList) copyTo(c *DeleteTableList) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Tables != nil {
+		c.Tables = make([]*TableName, len(n.Tables))
+		for i0 := range n.Tables {
+			c.Tables[i0] = n.Tables[i0].Clone()
+		}
+	}
+}
+
+func (n *DoStmt) copyTo(c *DoStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Exprs != nil {
+		c.Exprs = make([]ExprNode, len(n.Exprs))
+		for i0 := range n.Exprs {
+			if n.Exprs[i0] != nil {
+				c.Exprs[i0] = DeepCopy(n.Exprs[i0]).(ExprNode)
+			}
+		}
+	}
+}
+
+func (n *DropBindingStmt) copyTo(c *DropBindingStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.OriginNode != nil {
+		c.OriginNode = DeepCopy(n.OriginNode).(StmtNode)
+	}
+	if n.HintedNode != nil {
+		c.HintedNode = DeepCopy(n.HintedNode).(StmtNode)
+	}
+}
+
+func (n *DropDatabaseStmt) copyTo(c *DropDatabaseStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+}
+
+func (n *DropImportStmt) copyTo(c *DropImportStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *DropIndexStmt) copyTo(c *DropIndexStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+	c.LockAlg = n.LockAlg.Clone()
+}
+
+func (n *DropPlacementPolicyStmt) copyTo(c *DropPlacementPolicyStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+}
+
+func (n *DropSequenceStmt) copyTo(c *DropSequenceStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.Sequences != nil {
+		c.Sequences = make([]*TableName, len(n.Sequences))
+		for i0 := range n.Sequences {
+			c.Sequences[i0] = n.Sequences[i0].Clone()
+		}
+	}
+}
+
+func (n *DropStatisticsStmt) copyTo(c *DropStatisticsStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *DropStatsStmt) copyTo(c *DropStatsStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	c.Table = n.Table.Clone()
+	if n.PartitionNames != nil {
+		c.PartitionNames = append([]model.CIStr(nil), n.PartitionNames...)
+	}
+}
+
+func (n *DropTableStmt) copyTo(c *DropTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.Tables != nil {
+		c.Tables = make([]*TableName, len(n.Tables))
+		for i0 := range n.Tables {
+			c.Tables[i0] = n.Tables[i0].Clone()
+		}
+	}
+}
+
+func (n *DropUserStmt) copyTo(c *DropUserStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.UserList != nil {
+		c.UserList = make([]*auth.UserIdentity, len(n.UserList))
+		for i0 := range n.UserList {
+			if n.UserList[i0] != nil {
+				v1 := *n.UserList[i0]
+				c.UserList[i0] = &v1
+			}
+		}
+	}
+}
+
+func (n *ExecuteStmt) copyTo(c *ExecuteStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.UsingVars != nil {
+		c.UsingVars = make([]ExprNode, len(n.UsingVars))
+		for i0 := range n.UsingVars {
+			if n.UsingVars[i0] != nil {
+				c.UsingVars[i0] = DeepCopy(n.UsingVars[i0]).(ExprNode)
+			}
+		}
+	}
+	c.BinaryArgs = deepCopyValue(n.BinaryArgs)
+}
+
+func (n *ExistsSubqueryExpr) copyTo(c *ExistsSubqueryExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Sel != nil {
+		c.Sel = DeepCopy(n.Sel).(ExprNode)
+	}
+}
+
+func (n *ExplainForStmt) copyTo(c *ExplainForStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *ExplainStmt) copyTo(c *ExplainStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Stmt != nil {
+		c.Stmt = DeepCopy(n.Stmt).(StmtNode)
+	}
+}
+
+func (n *FieldList) copyTo(c *FieldList) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Fields != nil {
+		c.Fields = make([]*SelectField, len(n.Fields))
+		for i0 := range n.Fields {
+			c.Fields[i0] = n.Fields[i0].Clone()
+		}
+	}
+}
+
+func (n *FlashBackTableStmt) copyTo(c *FlashBackTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+}
+
+func (n *FlushStmt) copyTo(c *FlushStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Tables != nil {
+		c.Tables = make([]*TableName, len(n.Tables))
+		for i0 := range n.Tables {
+			c.Tables[i0] = n.Tables[i0].Clone()
+		}
+	}
+	if n.Plugins != nil {
+		c.Plugins = append([]string(nil), n.Plugins...)
+	}
+}
+
+func (n *FrameBound) copyTo(c *FrameBound) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *FrameClause) copyTo(c *FrameClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	n.Extent.copyTo(&c.Extent)
+}
+
+func (n *FuncCallExpr) copyTo(c *FuncCallExpr) {
+	*c = *n
+	n.funcNode.copyTo(&c.funcNode)
+	if n.Args != nil {
+		c.Args = make([]ExprNode, len(n.Args))
+		for i0 := range n.Args {
+			if n.Args[i0] != nil {
+				c.Args[i0] = DeepCopy(n.Args[i0]).(ExprNode)
+			}
+		}
+	}
+}
+
+func (n *FuncCastExpr) copyTo(c *FuncCastExpr) {
+	*c = *n
+	n.funcNode.copyTo(&c.funcNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.Tp != nil {
+		c.Tp = n.Tp.Clone()
+	}
+}
+
+func (n *GetFormatSelectorExpr) copyTo(c *GetFormatSelectorExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+}
+
+func (n *GrantProxyStmt) copyTo(c *GrantProxyStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.LocalUser != nil {
+		v0 := *n.LocalUser
+		c.LocalUser = &v0
+	}
+	if n.ExternalUsers != nil {
+		c.ExternalUsers = make([]*auth.UserIdentity, len(n.ExternalUsers))
+		for i0 := range n.ExternalUsers {
+			if n.ExternalUsers[i0] != nil {
+				v1 := *n.ExternalUsers[i0]
+				c.ExternalUsers[i0] = &v1
+			}
+		}
+	}
+}
+
+func (n *GrantRoleStmt) copyTo(c *GrantRoleStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Roles != nil {
+		c.Roles = make([]*auth.RoleIdentity, len(n.Roles))
+		for i0 := range n.Roles {
+			if n.Roles[i0] != nil {
+				v1 := *n.Roles[i0]
+				c.Roles[i0] = &v1
+			}
+		}
+	}
+	if n.Users != nil {
+		c.Users = make([]*auth.UserIdentity, len(n.Users))
+		for i0 := range n.Users {
+			if n.Users[i0] != nil {
+				v1 := *n.Users[i0]
+				c.Users[i0] = &v1
+			}
+		}
+	}
+}
+
+func (n *GrantStmt) copyTo(c *GrantStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Privs != nil {
+		c.Privs = make([]*PrivElem, len(n.Privs))
+		for i0 := range n.Privs {
+			c.Privs[i0] = n.Privs[i0].Clone()
+		}
+	}
+	if n.Level != nil {
+		c.Level = new(GrantLevel)
+		n.Level.copyTo(c.Level)
+	}
+	if n.Users != nil {
+		c.Users = make([]*UserSpec, len(n.Users))
+		for i0 := range n.Users {
+			if n.Users[i0] != nil {
+				c.Users[i0] = new(UserSpec)
+				n.Users[i0].copyTo(c.Users[i0])
+			}
+		}
+	}
+	if n.TLSOptions != nil {
+		c.TLSOptions = make([]*TLSOption, len(n.TLSOptions))
+		for i0 := range n.TLSOptions {
+			if n.TLSOptions[i0] != nil {
+				c.TLSOptions[i0] = new(TLSOption)
+				n.TLSOptions[i0].copyTo(c.TLSOptions[i0])
+			}
+		}
+	}
+}
+
+func (n *GroupByClause) copyTo(c *GroupByClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Items != nil {
+		c.Items = make([]*ByItem, len(n.Items))
+		for i0 := range n.Items {
+			c.Items[i0] = n.Items[i0].Clone()
+		}
+	}
+}
+
+func (n *HavingClause) copyTo(c *HavingClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *HelpStmt) copyTo(c *HelpStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *IndexAdviseStmt) copyTo(c *IndexAdviseStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.MaxIndexNum != nil {
+		c.MaxIndexNum = new(MaxIndexNumClause)
+		n.MaxIndexNum.copyTo(c.MaxIndexNum)
+	}
+	if n.LinesInfo != nil {
+		c.LinesInfo = new(LinesClause)
+		n.LinesInfo.copyTo(c.LinesInfo)
+	}
+}
+
+func (n *IndexLockAndAlgorithm) copyTo(c *IndexLockAndAlgorithm) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *IndexOption) copyTo(c *IndexOption) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *IndexPartSpecification) copyTo(c *IndexPartSpecification) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Column = n.Column.Clone()
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *InsertStmt) copyTo(c *InsertStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.Table = n.Table.Clone()
+	if n.Columns != nil {
+		c.Columns = make([]*ColumnName, len(n.Columns))
+		for i0 := range n.Columns {
+			c.Columns[i0] = n.Columns[i0].Clone()
+		}
+	}
+	if n.Lists != nil {
+		c.Lists = make([][]ExprNode, len(n.Lists))
+		for i0 := range n.Lists {
+			if n.Lists[i0] != nil {
+				c.Lists[i0] = make([]ExprNode, len(n.Lists[i0]))
+				for i1 := range n.Lists[i0] {
+					if n.Lists[i0][i1] != nil {
+						c.Lists[i0][i1] = DeepCopy(n.Lists[i0][i1]).(ExprNode)
+					}
+				}
+			}
+		}
+	}
+	if n.Setlist != nil {
+		c.Setlist = make([]*Assignment, len(n.Setlist))
+		for i0 := range n.Setlist {
+			c.Setlist[i0] = n.Setlist[i0].Clone()
+		}
+	}
+	if n.OnDuplicate != nil {
+		c.OnDuplicate = make([]*Assignment, len(n.OnDuplicate))
+		for i0 := range n.OnDuplicate {
+			c.OnDuplicate[i0] = n.OnDuplicate[i0].Clone()
+		}
+	}
+	if n.Select != nil {
+		c.Select = DeepCopy(n.Select).(ResultSetNode)
+	}
+	if n.TableHints != nil {
+		c.TableHints = make([]*TableOptimizerHint, len(n.TableHints))
+		for i0 := range n.TableHints {
+			c.TableHints[i0] = n.TableHints[i0].Clone()
+		}
+	}
+	if n.PartitionNames != nil {
+		c.PartitionNames = append([]model.CIStr(nil), n.PartitionNames...)
+	}
+}
+
+func (n *IsNullExpr) copyTo(c *IsNullExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *IsTruthExpr) copyTo(c *IsTruthExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *Join) copyTo(c *Join) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Left != nil {
+		c.Left = DeepCopy(n.Left).(ResultSetNode)
+	}
+	if n.Right != nil {
+		c.Right = DeepCopy(n.Right).(ResultSetNode)
+	}
+	c.On = n.On.Clone()
+	if n.Using != nil {
+		c.Using = make([]*ColumnName, len(n.Using))
+		for i0 := range n.Using {
+			c.Using[i0] = n.Using[i0].Clone()
+		}
+	}
+}
+
+func (n *KillStmt) copyTo(c *KillStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *Limit) copyTo(c *Limit) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Count != nil {
+		c.Count = DeepCopy(n.Count).(ExprNode)
+	}
+	if n.Offset != nil {
+		c.Offset = DeepCopy(n.Offset).(ExprNode)
+	}
+}
+
+func (n *LoadDataStmt) copyTo(c *LoadDataStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.Table = n.Table.Clone()
+	if n.Columns != nil {
+		c.Columns = make([]*ColumnName, len(n.Columns))
+		for i0 := range n.Columns {
+			c.Columns[i0] = n.Columns[i0].Clone()
+		}
+	}
+	if n.FieldsInfo != nil {
+		c.FieldsInfo = new(FieldsClause)
+		n.FieldsInfo.copyTo(c.FieldsInfo)
+	}
+	if n.LinesInfo != nil {
+		c.LinesInfo = new(LinesClause)
+		n.LinesInfo.copyTo(c.LinesInfo)
+	}
+	if n.ColumnAssignments != nil {
+		c.ColumnAssignments = make([]*Assignment, len(n.ColumnAssignments))
+		for i0 := range n.ColumnAssignments {
+			c.ColumnAssignments[i0] = n.ColumnAssignments[i0].Clone()
+		}
+	}
+	if n.ColumnsAndUserVars != nil {
+		c.ColumnsAndUserVars = make([]*ColumnNameOrUserVar, len(n.ColumnsAndUserVars))
+		for i0 := range n.ColumnsAndUserVars {
+			c.ColumnsAndUserVars[i0] = n.ColumnsAndUserVars[i0].Clone()
+		}
+	}
+}
+
+func (n *LoadStatsStmt) copyTo(c *LoadStatsStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *LockTablesStmt) copyTo(c *LockTablesStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.TableLocks != nil {
+		c.TableLocks = make([]TableLock, len(n.TableLocks))
+		for i0 := range n.TableLocks {
+			n.TableLocks[i0].copyTo(&c.TableLocks[i0])
+		}
+	}
+}
+
+func (n *MatchAgainst) copyTo(c *MatchAgainst) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.ColumnNames != nil {
+		c.ColumnNames = make([]*ColumnName, len(n.ColumnNames))
+		for i0 := range n.ColumnNames {
+			c.ColumnNames[i0] = n.ColumnNames[i0].Clone()
+		}
+	}
+	if n.Against != nil {
+		c.Against = DeepCopy(n.Against).(ExprNode)
+	}
+}
+
+func (n *MaxValueExpr) copyTo(c *MaxValueExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+}
+
+func (n *NonTransactionalDeleteStmt) copyTo(c *NonTransactionalDeleteStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.ShardColumn = n.ShardColumn.Clone()
+	c.DeleteStmt = n.DeleteStmt.Clone()
+}
+
+func (n *OnCondition) copyTo(c *OnCondition) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *OnDeleteOpt) copyTo(c *OnDeleteOpt) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *OnUpdateOpt) copyTo(c *OnUpdateOpt) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *OrderByClause) copyTo(c *OrderByClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Items != nil {
+		c.Items = make([]*ByItem, len(n.Items))
+		for i0 := range n.Items {
+			c.Items[i0] = n.Items[i0].Clone()
+		}
+	}
+}
+
+func (n *ParenthesesExpr) copyTo(c *ParenthesesExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *PartitionByClause) copyTo(c *PartitionByClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Items != nil {
+		c.Items = make([]*ByItem, len(n.Items))
+		for i0 := range n.Items {
+			c.Items[i0] = n.Items[i0].Clone()
+		}
+	}
+}
+
+func (n *PartitionOptions) copyTo(c *PartitionOptions) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	n.PartitionMethod.copyTo(&c.PartitionMethod)
+	if n.Sub != nil {
+		c.Sub = new(PartitionMethod)
+		n.Sub.copyTo(c.Sub)
+	}
+	if n.Definitions != nil {
+		c.Definitions = make([]*PartitionDefinition, len(n.Definitions))
+		for i0 := range n.Definitions {
+			if n.Definitions[i0] != nil {
+				c.Definitions[i0] = new(PartitionDefinition)
+				n.Definitions[i0].copyTo(c.Definitions[i0])
+			}
+		}
+	}
+}
+
+func (n *PatternInExpr) copyTo(c *PatternInExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.List != nil {
+		c.List = make([]ExprNode, len(n.List))
+		for i0 := range n.List {
+			if n.List[i0] != nil {
+				c.List[i0] = DeepCopy(n.List[i0]).(ExprNode)
+			}
+		}
+	}
+	if n.Sel != nil {
+		c.Sel = DeepCopy(n.Sel).(ExprNode)
+	}
+}
+
+func (n *PatternLikeExpr) copyTo(c *PatternLikeExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.Pattern != nil {
+		c.Pattern = DeepCopy(n.Pattern).(ExprNode)
+	}
+	if n.PatChars != nil {
+		c.PatChars = append([]byte(nil), n.PatChars...)
+	}
+	if n.PatTypes != nil {
+		c.PatTypes = append([]byte(nil), n.PatTypes...)
+	}
+}
+
+func (n *PatternRegexpExpr) copyTo(c *PatternRegexpExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.Pattern != nil {
+		c.Pattern = DeepCopy(n.Pattern).(ExprNode)
+	}
+	if n.Sexpr != nil {
+		v0 := *n.Sexpr
+		c.Sexpr = &v0
+	}
+}
+
+func (n *PlanReplayerStmt) copyTo(c *PlanReplayerStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Stmt != nil {
+		c.Stmt = DeepCopy(n.Stmt).(StmtNode)
+	}
+	if n.Where != nil {
+		c.Where = DeepCopy(n.Where).(ExprNode)
+	}
+	c.OrderBy = n.OrderBy.Clone()
+	c.Limit = n.Limit.Clone()
+}
+
+func (n *PositionExpr) copyTo(c *PositionExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.P != nil {
+		c.P = DeepCopy(n.P).(ExprNode)
+	}
+	if n.Refer != nil {
+		c.Refer = new(ResultField)
+		n.Refer.copyTo(c.Refer)
+	}
+}
+
+func (n *PrepareStmt) copyTo(c *PrepareStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	c.SQLVar = n.SQLVar.Clone()
+}
+
+func (n *PrivElem) copyTo(c *PrivElem) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Cols != nil {
+		c.Cols = make([]*ColumnName, len(n.Cols))
+		for i0 := range n.Cols {
+			c.Cols[i0] = n.Cols[i0].Clone()
+		}
+	}
+}
+
+func (n *PurgeImportStmt) copyTo(c *PurgeImportStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *RecoverTableStmt) copyTo(c *RecoverTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+}
+
+func (n *ReferenceDef) copyTo(c *ReferenceDef) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Table = n.Table.Clone()
+	if n.IndexPartSpecifications != nil {
+		c.IndexPartSpecifications = make([]*IndexPartSpecification, len(n.IndexPartSpecifications))
+		for i0 := range n.IndexPartSpecifications {
+			c.IndexPartSpecifications[i0] = n.IndexPartSpecifications[i0].Clone()
+		}
+	}
+	c.OnDelete = n.OnDelete.Clone()
+	c.OnUpdate = n.OnUpdate.Clone()
+}
+
+func (n *ReleaseSavepointStmt) copyTo(c *ReleaseSavepointStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *RenameTableStmt) copyTo(c *RenameTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	if n.TableToTables != nil {
+		c.TableToTables = make([]*TableToTable, len(n.TableToTables))
+		for i0 := range n.TableToTables {
+			c.TableToTables[i0] = n.TableToTables[i0].Clone()
+		}
+	}
+}
+
+func (n *RenameUserStmt) copyTo(c *RenameUserStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.UserToUsers != nil {
+		c.UserToUsers = make([]*UserToUser, len(n.UserToUsers))
+		for i0 := range n.UserToUsers {
+			c.UserToUsers[i0] = n.UserToUsers[i0].Clone()
+		}
+	}
+}
+
+func (n *RepairTableStmt) copyTo(c *RepairTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+	c.CreateStmt = n.CreateStmt.Clone()
+}
+
+func (n *RestartStmt) copyTo(c *RestartStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *ResumeImportStmt) copyTo(c *ResumeImportStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *RevokeRoleStmt) copyTo(c *RevokeRoleStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Roles != nil {
+		c.Roles = make([]*auth.RoleIdentity, len(n.Roles))
+		for i0 := range n.Roles {
+			if n.Roles[i0] != nil {
+				v1 := *n.Roles[i0]
+				c.Roles[i0] = &v1
+			}
+		}
+	}
+	if n.Users != nil {
+		c.Users = make([]*auth.UserIdentity, len(n.Users))
+		for i0 := range n.Users {
+			if n.Users[i0] != nil {
+				v1 := *n.Users[i0]
+				c.Users[i0] = &v1
+			}
+		}
+	}
+}
+
+func (n *RevokeStmt) copyTo(c *RevokeStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Privs != nil {
+		c.Privs = make([]*PrivElem, len(n.Privs))
+		for i0 := range n.Privs {
+			c.Privs[i0] = n.Privs[i0].Clone()
+		}
+	}
+	if n.Level != nil {
+		c.Level = new(GrantLevel)
+		n.Level.copyTo(c.Level)
+	}
+	if n.Users != nil {
+		c.Users = make([]*UserSpec, len(n.Users))
+		for i0 := range n.Users {
+			if n.Users[i0] != nil {
+				c.Users[i0] = new(UserSpec)
+				n.Users[i0].copyTo(c.Users[i0])
+			}
+		}
+	}
+}
+
+func (n *RollbackStmt) copyTo(c *RollbackStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *RowExpr) copyTo(c *RowExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Values != nil {
+		c.Values = make([]ExprNode, len(n.Values))
+		for i0 := range n.Values {
+			if n.Values[i0] != nil {
+				c.Values[i0] = DeepCopy(n.Values[i0]).(ExprNode)
+			}
+		}
+	}
+}
+
+func (n *SavepointStmt) copyTo(c *SavepointStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *SelectField) copyTo(c *SelectField) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.WildCard = n.WildCard.Clone()
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *SelectIntoOption) copyTo(c *SelectIntoOption) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.FieldsInfo != nil {
+		c.FieldsInfo = new(FieldsClause)
+		n.FieldsInfo.copyTo(c.FieldsInfo)
+	}
+	if n.LinesInfo != nil {
+		c.LinesInfo = new(LinesClause)
+		n.LinesInfo.copyTo(c.LinesInfo)
+	}
+}
+
+func (n *SelectStmt) copyTo(c *SelectStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	if n.SelectStmtOpts != nil {
+		c.SelectStmtOpts = new(SelectStmtOpts)
+		n.SelectStmtOpts.copyTo(c.SelectStmtOpts)
+	}
+	c.From = n.From.Clone()
+	if n.Where != nil {
+		c.Where = DeepCopy(n.Where).(ExprNode)
+	}
+	c.Fields = n.Fields.Clone()
+	c.GroupBy = n.GroupBy.Clone()
+	c.Having = n.Having.Clone()
+	if n.WindowSpecs != nil {
+		c.WindowSpecs = make([]WindowSpec, len(n.WindowSpecs))
+		for i0 := range n.WindowSpecs {
+			n.WindowSpecs[i0].copyTo(&c.WindowSpecs[i0])
+		}
+	}
+	c.OrderBy = n.OrderBy.Clone()
+	c.Limit = n.Limit.Clone()
+	if n.LockInfo != nil {
+		c.LockInfo = new(SelectLockInfo)
+		n.LockInfo.copyTo(c.LockInfo)
+	}
+	if n.TableHints != nil {
+		c.TableHints = make([]*TableOptimizerHint, len(n.TableHints))
+		for i0 := range n.TableHints {
+			c.TableHints[i0] = n.TableHints[i0].Clone()
+		}
+	}
+	c.SelectIntoOpt = n.SelectIntoOpt.Clone()
+	if n.AfterSetOperator != nil {
+		v0 := *n.AfterSetOperator
+		c.AfterSetOperator = &v0
+	}
+	if n.Lists != nil {
+		c.Lists = make([]*RowExpr, len(n.Lists))
+		for i0 := range n.Lists {
+			c.Lists[i0] = n.Lists[i0].Clone()
+		}
+	}
+	c.With = n.With.Clone()
+}
+
+func (n *SetBindingStmt) copyTo(c *SetBindingStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.OriginNode != nil {
+		c.OriginNode = DeepCopy(n.OriginNode).(StmtNode)
+	}
+	if n.HintedNode != nil {
+		c.HintedNode = DeepCopy(n.HintedNode).(StmtNode)
+	}
+}
+
+func (n *SetCollationExpr) copyTo(c *SetCollationExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+}
+
+func (n *SetConfigStmt) copyTo(c *SetConfigStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Value != nil {
+		c.Value = DeepCopy(n.Value).(ExprNode)
+	}
+}
+
+func (n *SetDefaultRoleStmt) copyTo(c *SetDefaultRoleStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.RoleList != nil {
+		c.RoleList = make([]*auth.RoleIdentity, len(n.RoleList))
+		for i0 := range n.RoleList {
+			if n.RoleList[i0] != nil {
+				v1 := *n.RoleList[i0]
+				c.RoleList[i0] = &v1
+			}
+		}
+	}
+	if n.UserList != nil {
+		c.UserList = make([]*auth.UserIdentity, len(n.UserList))
+		for i0 := range n.UserList {
+			if n.UserList[i0] != nil {
+				v1 := *n.UserList[i0]
+				c.UserList[i0] = &v1
+			}
+		}
+	}
+}
+
+func (n *SetOprSelectList) copyTo(c *SetOprSelectList) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.With = n.With.Clone()
+	if n.AfterSetOperator != nil {
+		v0 := *n.AfterSetOperator
+		c.AfterSetOperator = &v0
+	}
+	if n.Selects != nil {
+		c.Selects = make([]Node, len(n.Selects))
+		for i0 := range n.Selects {
+			if n.Selects[i0] != nil {
+				c.Selects[i0] = DeepCopy(n.Selects[i0]).(Node)
+			}
+		}
+	}
+}
+
+func (n *SetOprStmt) copyTo(c *SetOprStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.SelectList = n.SelectList.Clone()
+	c.OrderBy = n.OrderBy.Clone()
+	c.Limit = n.Limit.Clone()
+	c.With = n.With.Clone()
+}
+
+func (n *SetPwdStmt) copyTo(c *SetPwdStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.User != nil {
+		v0 := *n.User
+		c.User = &v0
+	}
+}
+
+func (n *SetRoleStmt) copyTo(c *SetRoleStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.RoleList != nil {
+		c.RoleList = make([]*auth.RoleIdentity, len(n.RoleList))
+		for i0 := range n.RoleList {
+			if n.RoleList[i0] != nil {
+				v1 := *n.RoleList[i0]
+				c.RoleList[i0] = &v1
+			}
+		}
+	}
+}
+
+func (n *SetSessionStatesStmt) copyTo(c *SetSessionStatesStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *SetStmt) copyTo(c *SetStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Variables != nil {
+		c.Variables = make([]*VariableAssignment, len(n.Variables))
+		for i0 := range n.Variables {
+			c.Variables[i0] = n.Variables[i0].Clone()
+		}
+	}
+}
+
+func (n *ShowImportStmt) copyTo(c *ShowImportStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.TableNames != nil {
+		c.TableNames = make([]*TableName, len(n.TableNames))
+		for i0 := range n.TableNames {
+			c.TableNames[i0] = n.TableNames[i0].Clone()
+		}
+	}
+}
+
+func (n *ShowStmt) copyTo(c *ShowStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.Table = n.Table.Clone()
+	c.Column = n.Column.Clone()
+	if n.User != nil {
+		v0 := *n.User
+		c.User = &v0
+	}
+	if n.Roles != nil {
+		c.Roles = make([]*auth.RoleIdentity, len(n.Roles))
+		for i0 := range n.Roles {
+			if n.Roles[i0] != nil {
+				v1 := *n.Roles[i0]
+				c.Roles[i0] = &v1
+			}
+		}
+	}
+	c.Pattern = n.Pattern.Clone()
+	if n.Where != nil {
+		c.Where = DeepCopy(n.Where).(ExprNode)
+	}
+	if n.ShowProfileTypes != nil {
+		c.ShowProfileTypes = append([]int(nil), n.ShowProfileTypes...)
+	}
+	if n.ShowProfileArgs != nil {
+		v0 := *n.ShowProfileArgs
+		c.ShowProfileArgs = &v0
+	}
+	c.ShowProfileLimit = n.ShowProfileLimit.Clone()
+}
+
+func (n *ShutdownStmt) copyTo(c *ShutdownStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *SplitRegionStmt) copyTo(c *SplitRegionStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.Table = n.Table.Clone()
+	if n.PartitionNames != nil {
+		c.PartitionNames = append([]model.CIStr(nil), n.PartitionNames...)
+	}
+	if n.SplitSyntaxOpt != nil {
+		c.SplitSyntaxOpt = new(SplitSyntaxOption)
+		n.SplitSyntaxOpt.copyTo(c.SplitSyntaxOpt)
+	}
+	if n.SplitOpt != nil {
+		c.SplitOpt = new(SplitOption)
+		n.SplitOpt.copyTo(c.SplitOpt)
+	}
+}
+
+func (n *StatsOptionsSpec) copyTo(c *StatsOptionsSpec) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *StopImportStmt) copyTo(c *StopImportStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *SubqueryExpr) copyTo(c *SubqueryExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Query != nil {
+		c.Query = DeepCopy(n.Query).(ResultSetNode)
+	}
+}
+
+func (n *TableName) copyTo(c *TableName) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.IndexHints != nil {
+		c.IndexHints = make([]*IndexHint, len(n.IndexHints))
+		for i0 := range n.IndexHints {
+			if n.IndexHints[i0] != nil {
+				c.IndexHints[i0] = new(IndexHint)
+				n.IndexHints[i0].copyTo(c.IndexHints[i0])
+			}
+		}
+	}
+	if n.PartitionNames != nil {
+		c.PartitionNames = append([]model.CIStr(nil), n.PartitionNames...)
+	}
+	c.TableSample = n.TableSample.Clone()
+	c.AsOf = n.AsOf.Clone()
+}
+
+func (n *TableNameExpr) copyTo(c *TableNameExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	c.Name = n.Name.Clone()
+}
+
+func (n *TableOptimizerHint) copyTo(c *TableOptimizerHint) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.HintData = deepCopyValue(n.HintData)
+	if n.Tables != nil {
+		c.Tables = make([]HintTable, len(n.Tables))
+		for i0 := range n.Tables {
+			n.Tables[i0].copyTo(&c.Tables[i0])
+		}
+	}
+	if n.Indexes != nil {
+		c.Indexes = append([]model.CIStr(nil), n.Indexes...)
+	}
+}
+
+func (n *TableRefsClause) copyTo(c *TableRefsClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.TableRefs = n.TableRefs.Clone()
+}
+
+func (n *TableSample) copyTo(c *TableSample) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.RepeatableSeed != nil {
+		c.RepeatableSeed = DeepCopy(n.RepeatableSeed).(ExprNode)
+	}
+}
+
+func (n *TableSource) copyTo(c *TableSource) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Source != nil {
+		c.Source = DeepCopy(n.Source).(ResultSetNode)
+	}
+}
+
+func (n *TableToTable) copyTo(c *TableToTable) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.OldTable = n.OldTable.Clone()
+	c.NewTable = n.NewTable.Clone()
+}
+
+func (n *TimeUnitExpr) copyTo(c *TimeUnitExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+}
+
+func (n *TraceStmt) copyTo(c *TraceStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+	if n.Stmt != nil {
+		c.Stmt = DeepCopy(n.Stmt).(StmtNode)
+	}
+}
+
+func (n *TrimDirectionExpr) copyTo(c *TrimDirectionExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+}
+
+func (n *TruncateTableStmt) copyTo(c *TruncateTableStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+	c.Table = n.Table.Clone()
+}
+
+func (n *UnaryOperationExpr) copyTo(c *UnaryOperationExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.V != nil {
+		c.V = DeepCopy(n.V).(ExprNode)
+	}
+}
+
+func (n *UnlockTablesStmt) copyTo(c *UnlockTablesStmt) {
+	*c = *n
+	n.ddlNode.copyTo(&c.ddlNode)
+}
+
+func (n *UpdateStmt) copyTo(c *UpdateStmt) {
+	*c = *n
+	n.dmlNode.copyTo(&c.dmlNode)
+	c.TableRefs = n.TableRefs.Clone()
+	if n.List != nil {
+		c.List = make([]*Assignment, len(n.List))
+		for i0 := range n.List {
+			c.List[i0] = n.List[i0].Clone()
+		}
+	}
+	if n.Where != nil {
+		c.Where = DeepCopy(n.Where).(ExprNode)
+	}
+	c.Order = n.Order.Clone()
+	c.Limit = n.Limit.Clone()
+	if n.TableHints != nil {
+		c.TableHints = make([]*TableOptimizerHint, len(n.TableHints))
+		for i0 := range n.TableHints {
+			c.TableHints[i0] = n.TableHints[i0].Clone()
+		}
+	}
+	c.With = n.With.Clone()
+}
+
+func (n *UseStmt) copyTo(c *UseStmt) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *UserToUser) copyTo(c *UserToUser) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.OldUser != nil {
+		v0 := *n.OldUser
+		c.OldUser = &v0
+	}
+	if n.NewUser != nil {
+		v0 := *n.NewUser
+		c.NewUser = &v0
+	}
+}
+
+func (n *ValuesExpr) copyTo(c *ValuesExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	c.Column = n.Column.Clone()
+}
+
+func (n *VariableAssignment) copyTo(c *VariableAssignment) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Value != nil {
+		c.Value = DeepCopy(n.Value).(ExprNode)
+	}
+	if n.ExtendValue != nil {
+		c.ExtendValue = DeepCopy(n.ExtendValue).(ValueExpr)
+	}
+}
+
+func (n *VariableExpr) copyTo(c *VariableExpr) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+	if n.Value != nil {
+		c.Value = DeepCopy(n.Value).(ExprNode)
+	}
+}
+
+func (n *WhenClause) copyTo(c *WhenClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.Result != nil {
+		c.Result = DeepCopy(n.Result).(ExprNode)
+	}
+}
+
+func (n *WildCardField) copyTo(c *WildCardField) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *WindowFuncExpr) copyTo(c *WindowFuncExpr) {
+	*c = *n
+	n.funcNode.copyTo(&c.funcNode)
+	if n.Args != nil {
+		c.Args = make([]ExprNode, len(n.Args))
+		for i0 := range n.Args {
+			if n.Args[i0] != nil {
+				c.Args[i0] = DeepCopy(n.Args[i0]).(ExprNode)
+			}
+		}
+	}
+	n.Spec.copyTo(&c.Spec)
+}
+
+func (n *WindowSpec) copyTo(c *WindowSpec) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.PartitionBy = n.PartitionBy.Clone()
+	c.OrderBy = n.OrderBy.Clone()
+	c.Frame = n.Frame.Clone()
+}
+
+func (n *WithClause) copyTo(c *WithClause) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	if n.CTEs != nil {
+		c.CTEs = make([]*CommonTableExpression, len(n.CTEs))
+		for i0 := range n.CTEs {
+			c.CTEs[i0] = n.CTEs[i0].Clone()
+		}
+	}
+}
+
+func (n *stmtNode) copyTo(c *stmtNode) {
+	*c = *n
+	n.node.copyTo(&c.node)
+}
+
+func (n *HandleRange) copyTo(c *HandleRange) {
+	*c = *n
+}
+
+func (n *ShowSlow) copyTo(c *ShowSlow) {
+	*c = *n
+}
+
+func (n *LimitSimple) copyTo(c *LimitSimple) {
+	*c = *n
+}
+
+func (n *funcNode) copyTo(c *funcNode) {
+	*c = *n
+	n.exprNode.copyTo(&c.exprNode)
+}
+
+func (n *ddlNode) copyTo(c *ddlNode) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *DatabaseOption) copyTo(c *DatabaseOption) {
+	*c = *n
+	if n.TiFlashReplica != nil {
+		c.TiFlashReplica = new(TiFlashReplicaSpec)
+		n.TiFlashReplica.copyTo(c.TiFlashReplica)
+	}
+}
+
+func (n *BRIEOption) copyTo(c *BRIEOption) {
+	*c = *n
+}
+
+func (n *ImportTruncate) copyTo(c *ImportTruncate) {
+	*c = *n
+	if n.TableNames != nil {
+		c.TableNames = make([]*TableName, len(n.TableNames))
+		for i0 := range n.TableNames {
+			c.TableNames[i0] = n.TableNames[i0].Clone()
+		}
+	}
+}
+
+func (n *PlacementOption) copyTo(c *PlacementOption) {
+	*c = *n
+}
+
+func (n *SequenceOption) copyTo(c *SequenceOption) {
+	*c = *n
+}
+
+func (n *node) copyTo(c *node) {
+	*c = *n
+	if n.once != nil {
+		c.once = &sync.Once{}
+	}
+}
+
+func (n *TableOption) copyTo(c *TableOption) {
+	*c = *n
+	if n.Value != nil {
+		c.Value = DeepCopy(n.Value).(ValueExpr)
+	}
+	if n.TableNames != nil {
+		c.TableNames = make([]*TableName, len(n.TableNames))
+		for i0 := range n.TableNames {
+			c.TableNames[i0] = n.TableNames[i0].Clone()
+		}
+	}
+}
+
+func (n *AlterOrderItem) copyTo(c *AlterOrderItem) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Column = n.Column.Clone()
+}
+
+func (n *PartitionDefinition) copyTo(c *PartitionDefinition) {
+	*c = *n
+	c.Clause = copyPartitionDefinitionClause(n.Clause)
+	if n.Options != nil {
+		c.Options = make([]*TableOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(TableOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+	if n.Sub != nil {
+		c.Sub = make([]*SubPartitionDefinition, len(n.Sub))
+		for i0 := range n.Sub {
+			if n.Sub[i0] != nil {
+				c.Sub[i0] = new(SubPartitionDefinition)
+				n.Sub[i0].copyTo(c.Sub[i0])
+			}
+		}
+	}
+}
+
+func (n *TiFlashReplicaSpec) copyTo(c *TiFlashReplicaSpec) {
+	*c = *n
+	if n.Labels != nil {
+		c.Labels = append([]string(nil), n.Labels...)
+	}
+}
+
+func (n *StatisticsSpec) copyTo(c *StatisticsSpec) {
+	*c = *n
+	if n.Columns != nil {
+		c.Columns = make([]*ColumnName, len(n.Columns))
+		for i0 := range n.Columns {
+			c.Columns[i0] = n.Columns[i0].Clone()
+		}
+	}
+}
+
+func (n *AuthOption) copyTo(c *AuthOption) {
+	*c = *n
+}
+
+func (n *UserSpec) copyTo(c *UserSpec) {
+	*c = *n
+	if n.User != nil {
+		v0 := *n.User
+		c.User = &v0
+	}
+	if n.AuthOpt != nil {
+		c.AuthOpt = new(AuthOption)
+		n.AuthOpt.copyTo(c.AuthOpt)
+	}
+}
+
+func (n *TLSOption) copyTo(c *TLSOption) {
+	*c = *n
+}
+
+func (n *ResourceOption) copyTo(c *ResourceOption) {
+	*c = *n
+}
+
+func (n *PasswordOrLockOption) copyTo(c *PasswordOrLockOption) {
+	*c = *n
+}
+
+func (n *AnalyzeOpt) copyTo(c *AnalyzeOpt) {
+	*c = *n
+	if n.Value != nil {
+		c.Value = DeepCopy(n.Value).(ValueExpr)
+	}
+}
+
+func (n *exprNode) copyTo(c *exprNode) {
+	*c = *n
+	n.node.copyTo(&c.node)
+	c.Type = *n.Type.Clone()
+}
+
+func (n *dmlNode) copyTo(c *dmlNode) {
+	*c = *n
+	n.stmtNode.copyTo(&c.stmtNode)
+}
+
+func (n *ResultField) copyTo(c *ResultField) {
+	*c = *n
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	c.TableName = n.TableName.Clone()
+}
+
+func (n *FrameExtent) copyTo(c *FrameExtent) {
+	*c = *n
+	n.Start.copyTo(&c.Start)
+	n.End.copyTo(&c.End)
+}
+
+func (n *GrantLevel) copyTo(c *GrantLevel) {
+	*c = *n
+}
+
+func (n *MaxIndexNumClause) copyTo(c *MaxIndexNumClause) {
+	*c = *n
+}
+
+func (n *LinesClause) copyTo(c *LinesClause) {
+	*c = *n
+}
+
+func (n *FieldsClause) copyTo(c *FieldsClause) {
+	*c = *n
+}
+
+func (n *TableLock) copyTo(c *TableLock) {
+	*c = *n
+	c.Table = n.Table.Clone()
+}
+
+func (n *PartitionMethod) copyTo(c *PartitionMethod) {
+	*c = *n
+	if n.Expr != nil {
+		c.Expr = DeepCopy(n.Expr).(ExprNode)
+	}
+	if n.ColumnNames != nil {
+		c.ColumnNames = make([]*ColumnName, len(n.ColumnNames))
+		for i0 := range n.ColumnNames {
+			c.ColumnNames[i0] = n.ColumnNames[i0].Clone()
+		}
+	}
+	if n.KeyAlgorithm != nil {
+		c.KeyAlgorithm = new(PartitionKeyAlgorithm)
+		n.KeyAlgorithm.copyTo(c.KeyAlgorithm)
+	}
+}
+
+func (n *SelectStmtOpts) copyTo(c *SelectStmtOpts) {
+	*c = *n
+	if n.TableHints != nil {
+		c.TableHints = make([]*TableOptimizerHint, len(n.TableHints))
+		for i0 := range n.TableHints {
+			c.TableHints[i0] = n.TableHints[i0].Clone()
+		}
+	}
+}
+
+func (n *SelectLockInfo) copyTo(c *SelectLockInfo) {
+	*c = *n
+	if n.Tables != nil {
+		c.Tables = make([]*TableName, len(n.Tables))
+		for i0 := range n.Tables {
+			c.Tables[i0] = n.Tables[i0].Clone()
+		}
+	}
+}
+
+func (n *SplitSyntaxOption) copyTo(c *SplitSyntaxOption) {
+	*c = *n
+}
+
+func (n *SplitOption) copyTo(c *SplitOption) {
+	*c = *n
+	if n.Lower != nil {
+		c.Lower = make([]ExprNode, len(n.Lower))
+		for i0 := range n.Lower {
+			if n.Lower[i0] != nil {
+				c.Lower[i0] = DeepCopy(n.Lower[i0]).(ExprNode)
+			}
+		}
+	}
+	if n.Upper != nil {
+		c.Upper = make([]ExprNode, len(n.Upper))
+		for i0 := range n.Upper {
+			if n.Upper[i0] != nil {
+				c.Upper[i0] = DeepCopy(n.Upper[i0]).(ExprNode)
+			}
+		}
+	}
+	if n.ValueLists != nil {
+		c.ValueLists = make([][]ExprNode, len(n.ValueLists))
+		for i0 := range n.ValueLists {
+			if n.ValueLists[i0] != nil {
+				c.ValueLists[i0] = make([]ExprNode, len(n.ValueLists[i0]))
+				for i1 := range n.ValueLists[i0] {
+					if n.ValueLists[i0][i1] != nil {
+						c.ValueLists[i0][i1] = DeepCopy(n.ValueLists[i0][i1]).(ExprNode)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (n *IndexHint) copyTo(c *IndexHint) {
+	*c = *n
+	if n.IndexNames != nil {
+		c.IndexNames = append([]model.CIStr(nil), n.IndexNames...)
+	}
+}
+
+func (n *HintTable) copyTo(c *HintTable) {
+	*c = *n
+	if n.PartitionList != nil {
+		c.PartitionList = append([]model.CIStr(nil), n.PartitionList...)
+	}
+}
+
+func (n *SubPartitionDefinition) copyTo(c *SubPartitionDefinition) {
+	*c = *n
+	if n.Options != nil {
+		c.Options = make([]*TableOption, len(n.Options))
+		for i0 := range n.Options {
+			if n.Options[i0] != nil {
+				c.Options[i0] = new(TableOption)
+				n.Options[i0].copyTo(c.Options[i0])
+			}
+		}
+	}
+}
+
+func (n *PartitionKeyAlgorithm) copyTo(c *PartitionKeyAlgorithm) {
+	*c = *n
+}
+
+func copyPartitionDefinitionClause(v PartitionDefinitionClause) PartitionDefinitionClause {
+	switch x := v.(type) {
+	case *PartitionDefinitionClauseHistory:
+		if x == nil {
+			return x
+		}
+		c := new(PartitionDefinitionClauseHistory)
+		x.copyTo(c)
+		return c
+	case *PartitionDefinitionClauseIn:
+		if x == nil {
+			return x
+		}
+		c := new(PartitionDefinitionClauseIn)
+		x.copyTo(c)
+		return c
+	case *PartitionDefinitionClauseLessThan:
+		if x == nil {
+			return x
+		}
+		c := new(PartitionDefinitionClauseLessThan)
+		x.copyTo(c)
+		return c
+	case *PartitionDefinitionClauseNone:
+		if x == nil {
+			return x
+		}
+		c := new(PartitionDefinitionClauseNone)
+		x.copyTo(c)
+		return c
+	}
+	return v
+}
+
+func (n *PartitionDefinitionClauseHistory) copyTo(c *PartitionDefinitionClauseHistory) {
+	*c = *n
+}
+
+func (n *PartitionDefinitionClauseIn) copyTo(c *PartitionDefinitionClauseIn) {
+	*c = *n
+	if n.Values != nil {
+		c.Values = make([][]ExprNode, len(n.Values))
+		for i0 := range n.Values {
+			if n.Values[i0] != nil {
+				c.Values[i0] = make([]ExprNode, len(n.Values[i0]))
+				for i1 := range n.Values[i0] {
+					if n.Values[i0][i1] != nil {
+						c.Values[i0][i1] = DeepCopy(n.Values[i0][i1]).(ExprNode)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (n *PartitionDefinitionClauseLessThan) copyTo(c *PartitionDefinitionClauseLessThan) {
+	*c = *n
+	if n.Exprs != nil {
+		c.Exprs = make([]ExprNode, len(n.Exprs))
+		for i0 := range n.Exprs {
+			if n.Exprs[i0] != nil {
+				c.Exprs[i0] = DeepCopy(n.Exprs[i0]).(ExprNode)
+			}
+		}
+	}
+}
+
+func (n *PartitionDefinitionClauseNone) copyTo(c *PartitionDefinitionClauseNone) {
+	*c = *n
+}
+
+// astTypes are the node types and the implementations of the other interfaces
+// of this package.
+var astTypes = []interface{}{
+	(*AdminStmt)(nil),
+	(*AggregateFuncExpr)(nil),
+	(*AlterDatabaseStmt)(nil),
+	(*AlterImportStmt)(nil),
+	(*AlterInstanceStmt)(nil),
+	(*AlterPlacementPolicyStmt)(nil),
+	(*AlterSequenceStmt)(nil),
+	(*AlterTableSpec)(nil),
+	(*AlterTableStmt)(nil),
+	(*AlterUserStmt)(nil),
+	(*AnalyzeTableStmt)(nil),
+	(*AsOfClause)(nil),
+	(*Assignment)(nil),
+	(*AttributesSpec)(nil),
+	(*BRIEStmt)(nil),
+	(*BeginStmt)(nil),
+	(*BetweenExpr)(nil),
+	(*BinaryOperationExpr)(nil),
+	(*BinlogStmt)(nil),
+	(*ByItem)(nil),
+	(*CallStmt)(nil),
+	(*CaseExpr)(nil),
+	(*ChangeStmt)(nil),
+	(*CleanupTableLockStmt)(nil),
+	(*ColumnDef)(nil),
+	(*ColumnName)(nil),
+	(*ColumnNameExpr)(nil),
+	(*ColumnNameOrUserVar)(nil),
+	(*ColumnOption)(nil),
+	(*ColumnPosition)(nil),
+	(*CommitStmt)(nil),
+	(*CommonTableExpression)(nil),
+	(*CompactTableStmt)(nil),
+	(*CompareSubqueryExpr)(nil),
+	(*Constraint)(nil),
+	(*CreateBindingStmt)(nil),
+	(*CreateDatabaseStmt)(nil),
+	(*CreateImportStmt)(nil),
+	(*CreateIndexStmt)(nil),
+	(*CreatePlacementPolicyStmt)(nil),
+	(*CreateSequenceStmt)(nil),
+	(*CreateStatisticsStmt)(nil),
+	(*CreateTableStmt)(nil),
+	(*CreateUserStmt)(nil),
+	(*CreateViewStmt)(nil),
+	(*DeallocateStmt)(nil),
+	(*DefaultExpr)(nil),
+	(*DeleteStmt)(nil),
+	(*DeleteTableList)(nil),
+	(*DoStmt)(nil),
+	(*DropBindingStmt)(nil),
+	(*DropDatabaseStmt)(nil),
+	(*DropImportStmt)(nil),
+	(*DropIndexStmt)(nil),
+	(*DropPlacementPolicyStmt)(nil),
+	(*DropSequenceStmt)(nil),
+	(*DropStatisticsStmt)(nil),
+	(*DropStatsStmt)(nil),
+	(*DropTableStmt)(nil),
+	(*DropUserStmt)(nil),
+	(*ExecuteStmt)(nil),
+	(*ExistsSubqueryExpr)(nil),
+	(*ExplainForStmt)(nil),
+	(*ExplainStmt)(nil),
+	(*FieldList)(nil),
+	(*FlashBackTableStmt)(nil),
+	(*FlushStmt)(nil),
+	(*FrameBound)(nil),
+	(*FrameClause)(nil),
+	(*FuncCallExpr)(nil),
+	(*FuncCastExpr)(nil),
+	(*GetFormatSelectorExpr)(nil),
+	(*GrantProxyStmt)(nil),
+	(*GrantRoleStmt)(nil),
+	(*GrantStmt)(nil),
+	(*GroupByClause)(nil),
+	(*HavingClause)(nil),
+	(*HelpStmt)(nil),
+	(*IndexAdviseStmt)(nil),
+	(*IndexLockAndAlgorithm)(nil),
+	(*IndexOption)(nil),
+	(*IndexPartSpecification)(nil),
+	(*InsertStmt)(nil),
+	(*IsNullExpr)(nil),
+	(*IsTruthExpr)(nil),
+	(*Join)(nil),
+	(*KillStmt)(nil),
+	(*Limit)(nil),
+	(*LoadDataStmt)(nil),
+	(*LoadStatsStmt)(nil),
+	(*LockTablesStmt)(nil),
+	(*MatchAgainst)(nil),
+	(*MaxValueExpr)(nil),
+	(*NonTransactionalDeleteStmt)(nil),
+	(*OnCondition)(nil),
+	(*OnDeleteOpt)(nil),
+	(*OnUpdateOpt)(nil),
+	(*OrderByClause)(nil),
+	(*ParenthesesExpr)(nil),
+	(*PartitionByClause)(nil),
+	(*PartitionOptions)(nil),
+	(*PatternInExpr)(nil),
+	(*PatternLikeExpr)(nil),
+	(*PatternRegexpExpr)(nil),
+	(*PlanReplayerStmt)(nil),
+	(*PositionExpr)(nil),
+	(*PrepareStmt)(nil),
+	(*PrivElem)(nil),
+	(*PurgeImportStmt)(nil),
+	(*RecoverTableStmt)(nil),
+	(*ReferenceDef)(nil),
+	(*ReleaseSavepointStmt)(nil),
+	(*RenameTableStmt)(nil),
+	(*RenameUserStmt)(nil),
+	(*RepairTableStmt)(nil),
+	(*RestartStmt)(nil),
+	(*ResumeImportStmt)(nil),
+	(*RevokeRoleStmt)(nil),
+	(*RevokeStmt)(nil),
+	(*RollbackStmt)(nil),
+	(*RowExpr)(nil),
+	(*SavepointStmt)(nil),
+	(*SelectField)(nil),
+	(*SelectIntoOption)(nil),
+	(*SelectStmt)(nil),
+	(*SetBindingStmt)(nil),
+	(*SetCollationExpr)(nil),
+	(*SetConfigStmt)(nil),
+	(*SetDefaultRoleStmt)(nil),
+	(*SetOprSelectList)(nil),
+	(*SetOprStmt)(nil),
+	(*SetPwdStmt)(nil),
+	(*SetRoleStmt)(nil),
+	(*SetSessionStatesStmt)(nil),
+	(*SetStmt)(nil),
+	(*ShowImportStmt)(nil),
+	(*ShowStmt)(nil),
+	(*ShutdownStmt)(nil),
+	(*SplitRegionStmt)(nil),
+	(*StatsOptionsSpec)(nil),
+	(*StopImportStmt)(nil),
+	(*SubqueryExpr)(nil),
+	(*TableName)(nil),
+	(*TableNameExpr)(nil),
+	(*TableOptimizerHint)(nil),
+	(*TableRefsClause)(nil),
+	(*TableSample)(nil),
+	(*TableSource)(nil),
+	(*TableToTable)(nil),
+	(*TimeUnitExpr)(nil),
+	(*TraceStmt)(nil),
+	(*TrimDirectionExpr)(nil),
+	(*TruncateTableStmt)(nil),
+	(*UnaryOperationExpr)(nil),
+	(*UnlockTablesStmt)(nil),
+	(*UpdateStmt)(nil),
+	(*UseStmt)(nil),
+	(*UserToUser)(nil),
+	(*ValuesExpr)(nil),
+	(*VariableAssignment)(nil),
+	(*VariableExpr)(nil),
+	(*WhenClause)(nil),
+	(*WildCardField)(nil),
+	(*WindowFuncExpr)(nil),
+	(*WindowSpec)(nil),
+	(*WithClause)(nil),
+	(*PartitionDefinitionClauseHistory)(nil),
+	(*PartitionDefinitionClauseIn)(nil),
+	(*PartitionDefinitionClauseLessThan)(nil),
+	(*PartitionDefinitionClauseNone)(nil),
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast_test
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	. "github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+// acceptTypes returns the types which have an Accept method in the file.
+func acceptTypes(t *testing.T, fset *token.FileSet, file string) []string {
+	f, err := goparser.ParseFile(fset, file, nil, 0)
+	require.NoError(t, err)
+	var res []string
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*goast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != "Accept" {
+			continue
+		}
+		star, ok := fn.Recv.List[0].Type.(*goast.StarExpr)
+		require.True(t, ok)
+		res = append(res, star.X.(*goast.Ident).Name)
+	}
+	return res
+}
+
+func TestDeepCopyCoverage(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "clone_generated.go", nil, 0)
+	require.NoError(t, err)
+	covered := make(map[string]bool)
+	goast.Inspect(f, func(n goast.Node) bool {
+		if c, ok := n.(*goast.CaseClause); ok {
+			for _, e := range c.List {
+				if star, ok := e.(*goast.StarExpr); ok {
+					covered[star.X.(*goast.Ident).Name] = true
+				}
+			}
+		}
+		return true
+	})
+
+	for _, file := range []string{"ast.go", "ddl.go", "dml.go", "expressions.go", "functions.go", "misc.go", "stats.go", "advisor.go"} {
+		for _, tp := range acceptTypes(t, fset, file) {
+			require.Truef(t, covered[tp], "%s in %s is not covered by DeepCopy, run go generate", tp, file)
+		}
+	}
+	for _, tp := range acceptTypes(t, fset, "../test_driver/test_driver.go") {
+		require.True(t, covered[tp] || tp == "ValueExpr" || tp == "ParamMarkerExpr", tp)
+	}
+
+	// The driver nodes are copied by NodeCloner.
+	v := &test_driver.ValueExpr{}
+	v.SetBytes([]byte("abc"))
+	c := DeepCopy(v).(*test_driver.ValueExpr)
+	require.NotSame(t, v, c)
+	c.GetBytes()[0] = 'x'
+	require.Equal(t, "abc", string(v.GetBytes()))
+
+	p := &test_driver.ParamMarkerExpr{Offset: 3, Order: 1}
+	p.SetInt64(10)
+	cp := DeepCopy(p).(*test_driver.ParamMarkerExpr)
+	require.NotSame(t, p, cp)
+	require.Equal(t, 3, cp.Offset)
+	require.Equal(t, int64(10), cp.GetInt64())
+}
+
+// collectPointers collects the addresses of the AST objects reachable from v.
+func collectPointers(v reflect.Value, seen map[uintptr]string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		// The schema objects and the other opaque objects like the charset
+		// encodings are shared.
+		tp := v.Type().Elem()
+		switch tp.PkgPath() {
+		case "github.com/daiguadaidai/parser/ast", "github.com/daiguadaidai/parser/test_driver",
+			"github.com/daiguadaidai/parser/auth", "github.com/daiguadaidai/parser/types":
+		default:
+			return
+		}
+		if _, ok := seen[v.Pointer()]; ok {
+			return
+		}
+		seen[v.Pointer()] = tp.String()
+		collectPointers(v.Elem(), seen)
+	case reflect.Interface:
+		if !v.IsNil() {
+			collectPointers(v.Elem(), seen)
+		}
+	case reflect.Slice:
+		if v.Len() > 0 && v.Type().Elem().Kind() != reflect.Uint8 {
+			seen[v.Pointer()] = v.Type().String()
+		}
+		for i := 0; i < v.Len(); i++ {
+			collectPointers(v.Index(i), seen)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			collectPointers(v.Field(i), seen)
+		}
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	sqls := []string{
+		"select a, b+1 as c, count(distinct d) from t1 as x join t2 on x.id = t2.id where a in (1, 2, ?) and b like 'x%' group by a having count(*) > 1 order by b desc limit 10",
+		"select * from t where a regexp '^a' union all select * from (select 1) as s",
+		"with cte as (select 1 as n) select n, row_number() over (partition by n order by n) from cte",
+		"insert into t (a, b) values (1, 'a'), (2, 0x1f) on duplicate key update b = values(b)",
+		"update t1, t2 set t1.a = t2.a + 1.5 where t1.id = t2.id",
+		"delete from t where a is null order by b limit 1",
+		"create table t (id bigint primary key auto_increment, name varchar(64) not null default 'x' comment 'name', key idx_name (name(10))) engine = innodb partition by range (id) (partition p0 values less than (100), partition p1 values less than maxvalue)",
+		"alter table t add column c int after b, drop index idx_name, add index idx_c (c)",
+		"create user 'u'@'%' identified by 'p'",
+		"grant select on db.* to 'u'@'%'",
+		"set @a = 1, session sql_mode = 'ANSI'",
+		"explain format = 'brief' select case a when 1 then 'x' else 'y' end from t",
+		"create view v as select cast(a as char(10)) from t",
+		"prepare s from 'select ?'",
+	}
+	p := parser.New()
+	for _, sql := range sqls {
+		stmt, err := p.ParseOneStmt(sql, "", "")
+		require.NoError(t, err, sql)
+		c := DeepCopy(stmt).(StmtNode)
+		require.Equal(t, stmt.Text(), c.Text())
+		require.Equal(t, restore(t, stmt), restore(t, c), sql)
+
+		orig := make(map[uintptr]string)
+		collectPointers(reflect.ValueOf(stmt), orig)
+		copied := make(map[uintptr]string)
+		collectPointers(reflect.ValueOf(c), copied)
+		for ptr, tp := range copied {
+			_, shared := orig[ptr]
+			require.Falsef(t, shared, "%s is shared by the copy of %s", tp, sql)
+		}
+	}
+}
+
+func TestCloneIndependence(t *testing.T) {
+	p := parser.New()
+	stmt, err := p.ParseOneStmt("select a from t where b = 1", "", "")
+	require.NoError(t, err)
+	sel := stmt.(*SelectStmt)
+	c := sel.Clone()
+	c.Fields.Fields[0].Expr.(*ColumnNameExpr).Name.Name = model.NewCIStr("z")
+	c.Where.(*BinaryOperationExpr).R.(*test_driver.ValueExpr).SetInt64(2)
+	c.From.TableRefs.Left.(*TableSource).Source.(*TableName).Name = model.NewCIStr("t2")
+	require.Equal(t, "SELECT `a` FROM `t` WHERE `b`=1", restore(t, sel))
+	require.Equal(t, "SELECT `z` FROM `t2` WHERE `b`=2", restore(t, c))
+
+	var nilSel *SelectStmt
+	require.Nil(t, nilSel.Clone())
+	require.Nil(t, DeepCopy(nil))
+}
+
+func restore(t *testing.T, n Node) string {
+	var sb strings.Builder
+	require.NoError(t, n.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)))
+	return sb.String()
+}
@@ -14,6 +14,8 @@
 package ast
 
 import (
+	"strings"
+
 	"github.com/daiguadaidai/parser/auth"
 	"github.com/daiguadaidai/parser/format"
 	"github.com/daiguadaidai/parser/model"
@@ -940,6 +942,11 @@ func (n *ColumnDef) Restore(ctx *format.RestoreCtx) error {
 	if err := n.Name.Restore(ctx); err != nil {
 		return errors.Annotate(err, "An error occurred while splicing ColumnDef Name")
 	}
+	return n.restoreDefinition(ctx)
+}
+
+// restoreDefinition restores the type and options after the name.
+func (n *ColumnDef) restoreDefinition(ctx *format.RestoreCtx) error {
 	if n.Tp != nil {
 		ctx.WritePlain(" ")
 		if err := n.Tp.Restore(ctx); err != nil {
@@ -1050,23 +1057,44 @@ func (n *CreateTableStmt) Restore(ctx *format.RestoreCtx) error {
 	lenCols := len(n.Cols)
 	lenConstraints := len(n.Constraints)
 	if lenCols+lenConstraints > 0 {
-		ctx.WritePlain(" (")
-		for i, col := range n.Cols {
-			if i > 0 {
-				ctx.WritePlain(",")
-			}
-			if err := col.Restore(ctx); err != nil {
-				return errors.Annotatef(err, "An error occurred while splicing CreateTableStmt ColumnDef: [%v]", i)
+		// In pretty format every definition is written in a line, and the types
+		// of the columns are aligned.
+		var nameWidths []int
+		maxWidth := 0
+		if ctx.Flags.HasPrettyFormatFlag() {
+			nameWidths = make([]int, lenCols)
+			for i, col := range n.Cols {
+				var sb strings.Builder
+				if err := col.Name.Restore(format.NewRestoreCtx(ctx.Flags, &sb)); err != nil {
+					return errors.Annotatef(err, "An error occurred while splicing CreateTableStmt ColumnDef: [%v]", i)
+				}
+				nameWidths[i] = sb.Len()
+				if nameWidths[i] > maxWidth {
+					maxWidth = nameWidths[i]
+				}
 			}
 		}
-		for i, constraint := range n.Constraints {
-			if i > 0 || lenCols >= 1 {
-				ctx.WritePlain(",")
+		ctx.WritePlain(" (")
+		ctx.IncIndent()
+		err := ctx.WriteLines(",", lenCols+lenConstraints, func(ctx *format.RestoreCtx, i int) error {
+			if i >= lenCols {
+				return errors.Annotatef(n.Constraints[i-lenCols].Restore(ctx), "An error occurred while splicing CreateTableStmt Constraints: [%v]", i-lenCols)
 			}
-			if err := constraint.Restore(ctx); err != nil {
-				return errors.Annotatef(err, "An error occurred while splicing CreateTableStmt Constraints: [%v]", i)
+			col := n.Cols[i]
+			if nameWidths == nil {
+				return errors.Annotatef(col.Restore(ctx), "An error occurred while splicing CreateTableStmt ColumnDef: [%v]", i)
 			}
+			if err := col.Name.Restore(ctx); err != nil {
+				return errors.Annotatef(err, "An error occurred while splicing CreateTableStmt ColumnDef: [%v]", i)
+			}
+			ctx.WritePlain(strings.Repeat(" ", maxWidth-nameWidths[i]))
+			return errors.Annotatef(col.restoreDefinition(ctx), "An error occurred while splicing CreateTableStmt ColumnDef: [%v]", i)
+		})
+		ctx.DecIndent()
+		if err != nil {
+			return err
 		}
+		ctx.WriteBreak("")
 		ctx.WritePlain(")")
 	}
 
@@ -828,3 +828,15 @@ func TestRemovePlacementRestore(t *testing.T) {
 		runNodeRestoreTestWithFlagsStmtChange(t, testCases, "%s", extractNodeFunc, f)
 	}
 }
+
+func TestCreateTablePrettyRestore(t *testing.T) {
+	testCases := []NodeRestoreTestCase{
+		{"create table t (id int primary key, name varchar(10) default 'x', key idx(name)) engine=innodb",
+			"CREATE TABLE `t` (\n  `id`   INT PRIMARY KEY,\n  `name` VARCHAR(10) DEFAULT 'x',\n  INDEX `idx`(`name`)\n) ENGINE = innodb"},
+		{"create table t like t1", "CREATE TABLE `t` LIKE `t1`"},
+	}
+	extractNodeFunc := func(node Node) Node {
+		return node
+	}
+	runNodeRestoreTestWithFlags(t, testCases, "%s", extractNodeFunc, format.DefaultRestoreFlags|format.RestorePrettyFormat)
+}
@@ -173,22 +173,29 @@ func (n *Join) Restore(ctx *format.RestoreCtx) error {
 	if n.Right == nil {
 		return nil
 	}
-	if n.NaturalJoin {
-		ctx.WriteKeyWord(" NATURAL")
-	}
-	switch n.Tp {
-	case LeftJoin:
-		ctx.WriteKeyWord(" LEFT")
-	case RightJoin:
-		ctx.WriteKeyWord(" RIGHT")
-	}
-	if n.StraightJoin {
-		ctx.WriteKeyWord(" STRAIGHT_JOIN ")
+	// The joined table starts a new line with one more indent in pretty format.
+	ctx.IncIndent()
+	defer ctx.DecIndent()
+	if useCommaJoin && !n.NaturalJoin && n.Tp == CrossJoin && !n.StraightJoin {
+		ctx.WritePlain(",")
+		ctx.WriteBreak(" ")
 	} else {
-		if useCommaJoin {
+		ctx.WriteBreak(" ")
+		if n.NaturalJoin {
+			ctx.WriteKeyWord("NATURAL ")
+		}
+		switch n.Tp {
+		case LeftJoin:
+			ctx.WriteKeyWord("LEFT ")
+		case RightJoin:
+			ctx.WriteKeyWord("RIGHT ")
+		}
+		if n.StraightJoin {
+			ctx.WriteKeyWord("STRAIGHT_JOIN ")
+		} else if useCommaJoin {
 			ctx.WritePlain(", ")
 		} else {
-			ctx.WriteKeyWord(" JOIN ")
+			ctx.WriteKeyWord("JOIN ")
 		}
 	}
 	_, rightIsJoin := n.Right.(*Join)
@@ -559,12 +566,15 @@ func (n *TableSource) Restore(ctx *format.RestoreCtx) error {
 	} else {
 		if needParen {
 			ctx.WritePlain("(")
-		}
-		if err := n.Source.Restore(ctx); err != nil {
-			return errors.Annotate(err, "An error occurred while restore TableSource.Source")
-		}
-		if needParen {
+			err := ctx.WriteBlock(1, func(ctx *format.RestoreCtx) error {
+				return n.Source.Restore(ctx)
+			})
+			if err != nil {
+				return errors.Annotate(err, "An error occurred while restore TableSource.Source")
+			}
 			ctx.WritePlain(")")
+		} else if err := n.Source.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore TableSource.Source")
 		}
 		if asName := n.AsName.String(); asName != "" {
 			ctx.WriteKeyWord(" AS ")
@@ -834,16 +844,10 @@ type GroupByClause struct {
 
 // Restore implements Node interface.
 func (n *GroupByClause) Restore(ctx *format.RestoreCtx) error {
-	ctx.WriteKeyWord("GROUP BY ")
-	for i, v := range n.Items {
-		if i != 0 {
-			ctx.WritePlain(",")
-		}
-		if err := v.Restore(ctx); err != nil {
-			return errors.Annotatef(err, "An error occurred while restore GroupByClause.Items[%d]", i)
-		}
-	}
-	return nil
+	ctx.WriteKeyWord("GROUP BY")
+	return ctx.WriteList(" ", ",", len(n.Items), func(ctx *format.RestoreCtx, i int) error {
+		return errors.Annotatef(n.Items[i].Restore(ctx), "An error occurred while restore GroupByClause.Items[%d]", i)
+	})
 }
 
 // Accept implements Node Accept interface.
@@ -902,16 +906,10 @@ type OrderByClause struct {
 
 // Restore implements Node interface.
 func (n *OrderByClause) Restore(ctx *format.RestoreCtx) error {
-	ctx.WriteKeyWord("ORDER BY ")
-	for i, item := range n.Items {
-		if i != 0 {
-			ctx.WritePlain(",")
-		}
-		if err := item.Restore(ctx); err != nil {
-			return errors.Annotatef(err, "An error occurred while restore OrderByClause.Items[%d]", i)
-		}
-	}
-	return nil
+	ctx.WriteKeyWord("ORDER BY")
+	return ctx.WriteList(" ", ",", len(n.Items), func(ctx *format.RestoreCtx, i int) error {
+		return errors.Annotatef(n.Items[i].Restore(ctx), "An error occurred while restore OrderByClause.Items[%d]", i)
+	})
 }
 
 // Accept implements Node Accept interface.
@@ -1158,7 +1156,7 @@ func (n *WithClause) Restore(ctx *format.RestoreCtx) error {
 			return err
 		}
 	}
-	ctx.WritePlain(" ")
+	ctx.WriteBreak(" ")
 	return nil
 }
 
@@ -1199,37 +1197,38 @@ func (n *SelectStmt) Restore(ctx *format.RestoreCtx) error {
 		}
 	}
 
+	// The options are written with a leading space, so the fields can start a
+	// new line in pretty format without a trailing space.
 	ctx.WriteKeyWord(n.Kind.String())
-	ctx.WritePlain(" ")
 	switch n.Kind {
 	case SelectStmtKindSelect:
 		if n.SelectStmtOpts.Priority > 0 {
-			ctx.WriteKeyWord(mysql.Priority2Str[n.SelectStmtOpts.Priority])
 			ctx.WritePlain(" ")
+			ctx.WriteKeyWord(mysql.Priority2Str[n.SelectStmtOpts.Priority])
 		}
 
 		if n.SelectStmtOpts.SQLSmallResult {
-			ctx.WriteKeyWord("SQL_SMALL_RESULT ")
+			ctx.WriteKeyWord(" SQL_SMALL_RESULT")
 		}
 
 		if n.SelectStmtOpts.SQLBigResult {
-			ctx.WriteKeyWord("SQL_BIG_RESULT ")
+			ctx.WriteKeyWord(" SQL_BIG_RESULT")
 		}
 
 		if n.SelectStmtOpts.SQLBufferResult {
-			ctx.WriteKeyWord("SQL_BUFFER_RESULT ")
+			ctx.WriteKeyWord(" SQL_BUFFER_RESULT")
 		}
 
 		if !n.SelectStmtOpts.SQLCache {
-			ctx.WriteKeyWord("SQL_NO_CACHE ")
+			ctx.WriteKeyWord(" SQL_NO_CACHE")
 		}
 
 		if n.SelectStmtOpts.CalcFoundRows {
-			ctx.WriteKeyWord("SQL_CALC_FOUND_ROWS ")
+			ctx.WriteKeyWord(" SQL_CALC_FOUND_ROWS")
 		}
 
 		if n.TableHints != nil && len(n.TableHints) != 0 {
-			ctx.WritePlain("/*+ ")
+			ctx.WritePlain(" /*+ ")
 			for i, tableHint := range n.TableHints {
 				if i != 0 {
 					ctx.WritePlain(" ")
@@ -1238,76 +1237,80 @@ func (n *SelectStmt) Restore(ctx *format.RestoreCtx) error {
 					return errors.Annotatef(err, "An error occurred while restore SelectStmt.TableHints[%d]", i)
 				}
 			}
-			ctx.WritePlain("*/ ")
+			ctx.WritePlain("*/")
 		}
 
 		if n.Distinct {
-			ctx.WriteKeyWord("DISTINCT ")
+			ctx.WriteKeyWord(" DISTINCT")
 		} else if n.SelectStmtOpts.ExplicitAll {
-			ctx.WriteKeyWord("ALL ")
+			ctx.WriteKeyWord(" ALL")
 		}
 		if n.SelectStmtOpts.StraightJoin {
-			ctx.WriteKeyWord("STRAIGHT_JOIN ")
+			ctx.WriteKeyWord(" STRAIGHT_JOIN")
 		}
 		if n.Fields != nil {
-			for i, field := range n.Fields.Fields {
-				if i != 0 {
-					ctx.WritePlain(",")
-				}
-				if err := field.Restore(ctx); err != nil {
-					return errors.Annotatef(err, "An error occurred while restore SelectStmt.Fields[%d]", i)
-				}
+			err := ctx.WriteList(" ", ",", len(n.Fields.Fields), func(ctx *format.RestoreCtx, i int) error {
+				return errors.Annotatef(n.Fields.Fields[i].Restore(ctx), "An error occurred while restore SelectStmt.Fields[%d]", i)
+			})
+			if err != nil {
+				return err
 			}
+		} else {
+			ctx.WritePlain(" ")
 		}
 
 		if n.From != nil {
-			ctx.WriteKeyWord(" FROM ")
+			ctx.WriteBreak(" ")
+			ctx.WriteKeyWord("FROM ")
 			if err := n.From.Restore(ctx); err != nil {
 				return errors.Annotate(err, "An error occurred while restore SelectStmt.From")
 			}
 		}
 
 		if n.From == nil && n.Where != nil {
-			ctx.WriteKeyWord(" FROM DUAL")
+			ctx.WriteBreak(" ")
+			ctx.WriteKeyWord("FROM DUAL")
 		}
 
 		if n.Where != nil {
-			ctx.WriteKeyWord(" WHERE ")
+			ctx.WriteBreak(" ")
+			ctx.WriteKeyWord("WHERE ")
 			if err := n.Where.Restore(ctx); err != nil {
 				return errors.Annotate(err, "An error occurred while restore SelectStmt.Where")
 			}
 		}
 
 		if n.GroupBy != nil {
-			ctx.WritePlain(" ")
+			ctx.WriteBreak(" ")
 			if err := n.GroupBy.Restore(ctx); err != nil {
 				return errors.Annotate(err, "An error occurred while restore SelectStmt.GroupBy")
 			}
 		}
 
 		if n.Having != nil {
-			ctx.WritePlain(" ")
+			ctx.WriteBreak(" ")
 			if err := n.Having.Restore(ctx); err != nil {
 				return errors.Annotate(err, "An error occurred while restore SelectStmt.Having")
 			}
 		}
 
 		if n.WindowSpecs != nil {
-			ctx.WriteKeyWord(" WINDOW ")
-			for i, windowsSpec := range n.WindowSpecs {
-				if i != 0 {
-					ctx.WritePlain(",")
-				}
-				if err := windowsSpec.Restore(ctx); err != nil {
-					return errors.Annotatef(err, "An error occurred while restore SelectStmt.WindowSpec[%d]", i)
-				}
+			ctx.WriteBreak(" ")
+			ctx.WriteKeyWord("WINDOW")
+			err := ctx.WriteList(" ", ",", len(n.WindowSpecs), func(ctx *format.RestoreCtx, i int) error {
+				return errors.Annotatef(n.WindowSpecs[i].Restore(ctx), "An error occurred while restore SelectStmt.WindowSpec[%d]", i)
+			})
+			if err != nil {
+				return err
 			}
 		}
 	case SelectStmtKindTable:
+		ctx.WritePlain(" ")
 		if err := n.From.Restore(ctx); err != nil {
 			return errors.Annotate(err, "An error occurred while restore SelectStmt.From")
 		}
 	case SelectStmtKindValues:
+		ctx.WritePlain(" ")
 		for i, v := range n.Lists {
 			if err := v.Restore(ctx); err != nil {
 				return errors.Annotatef(err, "An error occurred while restore SelectStmt.Lists[%d]", i)
@@ -1319,21 +1322,21 @@ func (n *SelectStmt) Restore(ctx *format.RestoreCtx) error {
 	}
 
 	if n.OrderBy != nil {
-		ctx.WritePlain(" ")
+		ctx.WriteBreak(" ")
 		if err := n.OrderBy.Restore(ctx); err != nil {
 			return errors.Annotate(err, "An error occurred while restore SelectStmt.OrderBy")
 		}
 	}
 
 	if n.Limit != nil {
-		ctx.WritePlain(" ")
+		ctx.WriteBreak(" ")
 		if err := n.Limit.Restore(ctx); err != nil {
 			return errors.Annotate(err, "An error occurred while restore SelectStmt.Limit")
 		}
 	}
 
 	if n.LockInfo != nil {
-		ctx.WritePlain(" ")
+		ctx.WriteBreak(" ")
 		switch n.LockInfo.LockType {
 		case SelectLockNone:
 		case SelectLockForUpdateNoWait:
@@ -1537,14 +1540,18 @@ func (n *SetOprSelectList) Restore(ctx *format.RestoreCtx) error {
 		switch selectStmt := stmt.(type) {
 		case *SelectStmt:
 			if i != 0 {
-				ctx.WriteKeyWord(" " + selectStmt.AfterSetOperator.String() + " ")
+				ctx.WriteBreak(" ")
+				ctx.WriteKeyWord(selectStmt.AfterSetOperator.String())
+				ctx.WriteBreak(" ")
 			}
 			if err := selectStmt.Restore(ctx); err != nil {
 				return errors.Annotate(err, "An error occurred while restore SetOprSelectList.SelectStmt")
 			}
 		case *SetOprSelectList:
 			if i != 0 {
-				ctx.WriteKeyWord(" " + selectStmt.AfterSetOperator.String() + " ")
+				ctx.WriteBreak(" ")
+				ctx.WriteKeyWord(selectStmt.AfterSetOperator.String())
+				ctx.WriteBreak(" ")
 			}
 			ctx.WritePlain("(")
 			err := selectStmt.Restore(ctx)
@@ -1646,14 +1653,14 @@ func (n *SetOprStmt) Restore(ctx *format.RestoreCtx) error {
 	}
 
 	if n.OrderBy != nil {
-		ctx.WritePlain(" ")
+		ctx.WriteBreak(" ")
 		if err := n.OrderBy.Restore(ctx); err != nil {
 			return errors.Annotate(err, "An error occurred while restore SetOprStmt.OrderBy")
 		}
 	}
 
 	if n.Limit != nil {
-		ctx.WritePlain(" ")
+		ctx.WriteBreak(" ")
 		if err := n.Limit.Restore(ctx); err != nil {
 			return errors.Annotate(err, "An error occurred while restore SetOprStmt.Limit")
 		}
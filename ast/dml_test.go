@@ -415,3 +415,21 @@ func TestFulltextSearchModifier(t *testing.T) {
 	require.True(t, FulltextSearchModifier(FulltextSearchModifierNaturalLanguageMode).IsNaturalLanguageMode())
 	require.False(t, FulltextSearchModifier(FulltextSearchModifierNaturalLanguageMode).WithQueryExpansion())
 }
+
+func TestSelectPrettyRestore(t *testing.T) {
+	testCases := []NodeRestoreTestCase{
+		{"select a, b from t where c = 1 order by d", "SELECT `a`, `b`\nFROM `t`\nWHERE `c`=1\nORDER BY `d`"},
+		{"select a from t1 join t2 on t1.id = t2.id left join t3 using (id) group by a having count(*) > 1 limit 10",
+			"SELECT `a`\nFROM (`t1`\n  JOIN `t2` ON `t1`.`id`=`t2`.`id`)\n  LEFT JOIN `t3` USING (`id`)\nGROUP BY `a`\nHAVING COUNT(1)>1\nLIMIT 10"},
+		{"select distinct column_number_one, column_number_two, column_number_three, column_number_four from t",
+			"SELECT DISTINCT\n  `column_number_one`,\n  `column_number_two`,\n  `column_number_three`,\n  `column_number_four`\nFROM `t`"},
+		{"select a from t where b in (select column_number_one from table_number_one where column_number_two = 1)",
+			"SELECT `a`\nFROM `t`\nWHERE `b` IN (\n  SELECT `column_number_one`\n  FROM `table_number_one`\n  WHERE `column_number_two`=1\n)"},
+		{"select * from (select a from t) as x union all select 1", "SELECT *\nFROM (SELECT `a` FROM `t`) AS `x`\nUNION ALL\nSELECT 1"},
+		{"with cte as (select 1) select * from cte", "WITH `cte` AS (SELECT 1)\nSELECT *\nFROM `cte`"},
+	}
+	extractNodeFunc := func(node Node) Node {
+		return node
+	}
+	runNodeRestoreTestWithFlags(t, testCases, "%s", extractNodeFunc, format.DefaultRestoreFlags|format.RestorePrettyFormat)
+}
@@ -0,0 +1,413 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/opcode"
+)
+
+// EqualOption controls how Equal and Hash compare the nodes.
+type EqualOption uint8
+
+const (
+	// EqualIgnoreCase compares the identifiers case-insensitively.
+	EqualIgnoreCase EqualOption = 1 << iota
+	// EqualIgnoreText ignores the original text and the positions of the nodes.
+	EqualIgnoreText
+	// EqualCommutative treats the operands of the commutative operators as
+	// unordered, the operands of the chained associative operators like
+	// `a AND b AND c` are compared as a whole.
+	EqualCommutative
+)
+
+// Equal reports whether the two nodes are structurally equal.
+//
+// The bound schema objects like TableName.TableInfo are compared by identity,
+// and the derived fields like ColumnNameExpr.Refer and PatternRegexpExpr.Re are
+// ignored. The nodes of the parser driver are compared by their fields.
+func Equal(a, b Node, opts ...EqualOption) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	c := comparer{opts: mergeEqualOptions(opts)}
+	return c.equal(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// Hash returns the hash of the node, the nodes which are equal under the same
+// options have the same hash. The hash is stable across processes.
+func Hash(n Node, opts ...EqualOption) uint64 {
+	h := newHasher(mergeEqualOptions(opts))
+	if n != nil {
+		h.hash(reflect.ValueOf(n))
+	}
+	return h.h.Sum64()
+}
+
+func mergeEqualOptions(opts []EqualOption) EqualOption {
+	var res EqualOption
+	for _, opt := range opts {
+		res |= opt
+	}
+	return res
+}
+
+type fieldKind uint8
+
+const (
+	fieldCompare fieldKind = iota
+	// fieldText is the original text or position.
+	fieldText
+	// fieldSkip is the derived or cached information.
+	fieldSkip
+)
+
+var (
+	nodeType       = reflect.TypeOf(node{})
+	exprNodeType   = reflect.TypeOf(exprNode{})
+	ciStrType      = reflect.TypeOf(model.CIStr{})
+	binaryOpType   = reflect.TypeOf(&BinaryOperationExpr{})
+	paramMarkerTyp = reflect.TypeOf((*ParamMarkerExpr)(nil)).Elem()
+	modelPkgPath   = ciStrType.PkgPath()
+
+	// skippedFields are the fields of nodes which are derived from the others.
+	skippedFields = map[reflect.Type][]string{
+		// The original text and position of node are compared by the methods
+		// of Node.
+		nodeType:                            {"utf8Text", "enc", "once", "text", "offset"},
+		exprNodeType:                        {"flag"},
+		reflect.TypeOf(ColumnNameExpr{}):    {"Refer"},
+		reflect.TypeOf(PatternRegexpExpr{}): {"Re", "Sexpr"},
+	}
+
+	structFieldKinds sync.Map // map[reflect.Type][]fieldKind
+)
+
+// fieldKinds returns how the fields of the struct type are compared.
+func fieldKinds(tp reflect.Type) []fieldKind {
+	if kinds, ok := structFieldKinds.Load(tp); ok {
+		return kinds.([]fieldKind)
+	}
+	kinds := make([]fieldKind, tp.NumField())
+	for i := range kinds {
+		f := tp.Field(i)
+		if f.Name == "Offset" && f.Type.Kind() == reflect.Int &&
+			(tp == reflect.TypeOf(SelectField{}) || reflect.PtrTo(tp).Implements(paramMarkerTyp)) {
+			kinds[i] = fieldText
+		}
+		for _, name := range skippedFields[tp] {
+			if f.Name == name {
+				kinds[i] = fieldSkip
+			}
+		}
+	}
+	structFieldKinds.Store(tp, kinds)
+	return kinds
+}
+
+// isSchemaObject returns whether the pointer refers to a schema object, which
+// is compared by identity.
+func isSchemaObject(tp reflect.Type) bool {
+	return tp.Kind() == reflect.Ptr && tp.Elem().PkgPath() == modelPkgPath && tp.Elem() != ciStrType
+}
+
+// commutativeOps are the commutative operators, the associative ones are true.
+var commutativeOps = map[opcode.Op]bool{
+	opcode.LogicAnd: true,
+	opcode.LogicOr:  true,
+	opcode.LogicXor: true,
+	opcode.And:      true,
+	opcode.Or:       true,
+	opcode.Xor:      true,
+	opcode.Plus:     true,
+	opcode.Mul:      true,
+	opcode.EQ:       false,
+	opcode.NE:       false,
+	opcode.NullEQ:   false,
+}
+
+// commutativeOperands returns the operands of the binary operation if the
+// operator is commutative.
+func commutativeOperands(v reflect.Value) ([]ExprNode, bool) {
+	if v.Type() != binaryOpType || v.IsNil() || !v.CanInterface() {
+		return nil, false
+	}
+	e := v.Interface().(*BinaryOperationExpr)
+	assoc, ok := commutativeOps[e.Op]
+	if !ok {
+		return nil, false
+	}
+	if !assoc {
+		return []ExprNode{e.L, e.R}, true
+	}
+	return flattenOperands(e.Op, e, nil), true
+}
+
+func flattenOperands(op opcode.Op, e ExprNode, res []ExprNode) []ExprNode {
+	inner := e
+	if p, ok := inner.(*ParenthesesExpr); ok {
+		inner = p.Expr
+	}
+	if b, ok := inner.(*BinaryOperationExpr); ok && b.Op == op {
+		res = flattenOperands(op, b.L, res)
+		return flattenOperands(op, b.R, res)
+	}
+	return append(res, e)
+}
+
+type comparer struct {
+	opts EqualOption
+}
+
+func (c *comparer) equal(a, b reflect.Value) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && b.IsNil()
+		}
+		if a.Pointer() == b.Pointer() || isSchemaObject(a.Type()) {
+			return a.Pointer() == b.Pointer()
+		}
+		if c.opts&EqualIgnoreText == 0 && a.CanInterface() {
+			if x, ok := a.Interface().(Node); ok {
+				y := b.Interface().(Node)
+				if x.Text() != y.Text() || x.OriginTextPosition() != y.OriginTextPosition() {
+					return false
+				}
+			}
+		}
+		if c.opts&EqualCommutative != 0 {
+			if as, ok := commutativeOperands(a); ok {
+				bs, _ := commutativeOperands(b)
+				return a.Elem().FieldByName("Op").Int() == b.Elem().FieldByName("Op").Int() &&
+					c.equal(a.Elem().Field(0), b.Elem().Field(0)) && c.equalUnordered(as, bs)
+			}
+		}
+		return c.equal(a.Elem(), b.Elem())
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && b.IsNil()
+		}
+		return c.equal(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !c.equal(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			bv := b.MapIndex(iter.Key())
+			if !bv.IsValid() || !c.equal(iter.Value(), bv) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		if a.Type() == ciStrType {
+			if c.opts&EqualIgnoreCase != 0 {
+				return a.Field(1).String() == b.Field(1).String()
+			}
+			return a.Field(0).String() == b.Field(0).String()
+		}
+		for i, kind := range fieldKinds(a.Type()) {
+			if kind == fieldSkip || kind == fieldText && c.opts&EqualIgnoreText != 0 {
+				continue
+			}
+			if !c.equal(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	// Functions, channels and unsafe pointers are not the part of the tree.
+	return true
+}
+
+// equalUnordered reports whether the two lists are equal as multisets.
+func (c *comparer) equalUnordered(as, bs []ExprNode) bool {
+	if len(as) != len(bs) {
+		return false
+	}
+	used := make([]bool, len(bs))
+	for _, x := range as {
+		found := false
+		for j, y := range bs {
+			if !used[j] && c.equal(reflect.ValueOf(&x).Elem(), reflect.ValueOf(&y).Elem()) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type hasher struct {
+	opts EqualOption
+	h    hash.Hash64
+	buf  [8]byte
+}
+
+func newHasher(opts EqualOption) *hasher {
+	return &hasher{opts: opts, h: fnv.New64a()}
+}
+
+func (h *hasher) write(x uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], x)
+	_, _ = h.h.Write(h.buf[:])
+}
+
+func (h *hasher) writeString(s string) {
+	h.write(uint64(len(s)))
+	_, _ = io.WriteString(h.h, s)
+}
+
+// sub returns the hash of v computed by a new hasher.
+func (h *hasher) sub(v reflect.Value) uint64 {
+	s := newHasher(h.opts)
+	s.hash(v)
+	return s.h.Sum64()
+}
+
+func (h *hasher) hash(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.write(1)
+		} else {
+			h.write(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.write(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.write(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.writeFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		h.writeFloat(real(v.Complex()))
+		h.writeFloat(imag(v.Complex()))
+	case reflect.String:
+		h.writeString(v.String())
+	case reflect.Ptr:
+		if v.IsNil() {
+			h.write(0)
+			return
+		}
+		h.write(1)
+		if isSchemaObject(v.Type()) {
+			return
+		}
+		if h.opts&EqualIgnoreText == 0 && v.CanInterface() {
+			if n, ok := v.Interface().(Node); ok {
+				h.writeString(n.Text())
+				h.write(uint64(n.OriginTextPosition()))
+			}
+		}
+		if h.opts&EqualCommutative != 0 {
+			if operands, ok := commutativeOperands(v); ok {
+				h.hash(v.Elem().Field(0))
+				h.write(uint64(v.Elem().FieldByName("Op").Int()))
+				sums := make([]uint64, 0, len(operands))
+				for i := range operands {
+					sums = append(sums, h.sub(reflect.ValueOf(&operands[i]).Elem()))
+				}
+				sort.Slice(sums, func(i, j int) bool { return sums[i] < sums[j] })
+				for _, s := range sums {
+					h.write(s)
+				}
+				return
+			}
+		}
+		h.hash(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			h.write(0)
+			return
+		}
+		h.writeString(v.Elem().Type().String())
+		h.hash(v.Elem())
+	case reflect.Slice, reflect.Array:
+		h.write(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			h.hash(v.Index(i))
+		}
+	case reflect.Map:
+		// The entries are unordered.
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			sum ^= h.sub(iter.Key())*31 + h.sub(iter.Value())
+		}
+		h.write(uint64(v.Len()))
+		h.write(sum)
+	case reflect.Struct:
+		if v.Type() == ciStrType {
+			if h.opts&EqualIgnoreCase != 0 {
+				h.writeString(v.Field(1).String())
+			} else {
+				h.writeString(v.Field(0).String())
+			}
+			return
+		}
+		for i, kind := range fieldKinds(v.Type()) {
+			if kind == fieldSkip || kind == fieldText && h.opts&EqualIgnoreText != 0 {
+				continue
+			}
+			h.hash(v.Field(i))
+		}
+	}
+}
+
+func (h *hasher) writeFloat(f float64) {
+	if f == 0 {
+		// -0 equals to 0.
+		f = 0
+	}
+	h.write(math.Float64bits(f))
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	. "github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/stretchr/testify/require"
+)
+
+func parseExpr(t *testing.T, p *parser.Parser, expr string) ExprNode {
+	stmt, err := p.ParseOneStmt("select "+expr, "", "")
+	require.NoError(t, err)
+	return stmt.(*SelectStmt).Fields.Fields[0].Expr
+}
+
+func TestEqual(t *testing.T) {
+	p := parser.New()
+	cases := []struct {
+		a, b  string
+		opts  []EqualOption
+		equal bool
+	}{
+		{"a + 1", "a + 1", nil, true},
+		{"a + 1", "a + 2", nil, false},
+		{"a + 1", "a - 1", nil, false},
+		{"a + 1", "a  +  1", nil, false},
+		{"a + 1", "a  +  1", []EqualOption{EqualIgnoreText}, true},
+		{"t.A = 1", "T.a = 1", []EqualOption{EqualIgnoreText}, false},
+		{"t.A = 1", "T.a = 1", []EqualOption{EqualIgnoreText, EqualIgnoreCase}, true},
+		{"a = 1", "1 = a", []EqualOption{EqualIgnoreText}, false},
+		{"a = 1", "1 = a", []EqualOption{EqualIgnoreText, EqualCommutative}, true},
+		{"a < 1", "1 < a", []EqualOption{EqualIgnoreText, EqualCommutative}, false},
+		{"a and b and c", "c and (a and b)", []EqualOption{EqualIgnoreText, EqualCommutative}, true},
+		{"a and b and c", "c and a", []EqualOption{EqualIgnoreText, EqualCommutative}, false},
+		{"a and b and a", "a and b and b", []EqualOption{EqualIgnoreText, EqualCommutative}, false},
+		{"a or (b and c)", "(c and b) or a", []EqualOption{EqualIgnoreText, EqualCommutative}, true},
+		{"a - b", "b - a", []EqualOption{EqualIgnoreText, EqualCommutative}, false},
+		{"f(a, b)", "f(b, a)", []EqualOption{EqualIgnoreText, EqualCommutative}, false},
+		{"a in (select b from t where c = 1)", "a in (select b from t where 1 = c)", []EqualOption{EqualIgnoreText, EqualCommutative}, true},
+		{"'abc'", "'abc'", nil, true},
+		{"'abc'", "'ABC'", []EqualOption{EqualIgnoreText, EqualIgnoreCase}, false},
+		{"1.50", "1.5", []EqualOption{EqualIgnoreText}, false},
+		{"?", "?", []EqualOption{EqualIgnoreText}, true},
+	}
+	for _, c := range cases {
+		a, b := parseExpr(t, p, c.a), parseExpr(t, p, c.b)
+		require.Equal(t, c.equal, Equal(a, b, c.opts...), "%s vs %s", c.a, c.b)
+		require.Equal(t, c.equal, Equal(b, a, c.opts...), "%s vs %s", c.b, c.a)
+		if c.equal {
+			require.Equal(t, Hash(a, c.opts...), Hash(b, c.opts...), "%s vs %s", c.a, c.b)
+		} else {
+			require.NotEqual(t, Hash(a, c.opts...), Hash(b, c.opts...), "%s vs %s", c.a, c.b)
+		}
+	}
+	require.True(t, Equal(nil, nil))
+	require.False(t, Equal(nil, parseExpr(t, p, "1")))
+}
+
+func TestEqualStmt(t *testing.T) {
+	p := parser.New()
+	sqls := []string{
+		"select a, count(*) from t1 join t2 on t1.id = t2.id where a > 1 group by a order by 2 limit 10",
+		"insert into t values (1, 'a'), (2, 'b')",
+		"create table t (id int primary key, name varchar(10) default 'x') partition by hash(id) partitions 4",
+		"alter table t add index idx (a, b)",
+		"update t set a = a + 1 where b is not null",
+	}
+	for _, sql := range sqls {
+		a, err := p.ParseOneStmt(sql, "", "")
+		require.NoError(t, err)
+		b, err := p.ParseOneStmt(sql, "", "")
+		require.NoError(t, err)
+		require.True(t, Equal(a, b), sql)
+		require.Equal(t, Hash(a), Hash(b), sql)
+		c := DeepCopy(a)
+		require.True(t, Equal(a, c), sql)
+		require.Equal(t, Hash(a), Hash(c), sql)
+	}
+
+	a, err := p.ParseOneStmt("select a from t", "", "")
+	require.NoError(t, err)
+	b, err := p.ParseOneStmt("select a from t2", "", "")
+	require.NoError(t, err)
+	require.False(t, Equal(a, b))
+	require.NotEqual(t, Hash(a), Hash(b))
+
+	// The schema objects are compared by identity.
+	b = DeepCopy(a).(StmtNode)
+	tblA := a.(*SelectStmt).From.TableRefs.Left.(*TableSource).Source.(*TableName)
+	tblB := b.(*SelectStmt).From.TableRefs.Left.(*TableSource).Source.(*TableName)
+	tblA.TableInfo = &model.TableInfo{Name: model.NewCIStr("t")}
+	tblB.TableInfo = &model.TableInfo{Name: model.NewCIStr("t")}
+	require.False(t, Equal(a, b))
+	tblB.TableInfo = tblA.TableInfo
+	require.True(t, Equal(a, b))
+	require.Equal(t, Hash(a), Hash(b))
+}
+
+func TestEqualText(t *testing.T) {
+	p := parser.New()
+	a := parseExpr(t, p, "'中文'")
+	b := parseExpr(t, p, "'中文'")
+
+	// The texts are compared decoded, a node parsed from GBK is equal to the
+	// node parsed from UTF-8.
+	gbk, err := charset.EncodingGBKImpl.Transform(nil, []byte("'中文'"), charset.OpEncode)
+	require.NoError(t, err)
+	a.SetText(charset.EncodingGBKImpl, string(gbk))
+	b.SetText(nil, "'中文'")
+	require.True(t, Equal(a, b))
+	require.Equal(t, Hash(a), Hash(b))
+
+	b.SetText(nil, "'中文' ")
+	require.False(t, Equal(a, b))
+	require.NotEqual(t, Hash(a), Hash(b))
+	require.True(t, Equal(a, b, EqualIgnoreText))
+	require.Equal(t, Hash(a, EqualIgnoreText), Hash(b, EqualIgnoreText))
+
+	b.SetText(nil, "'中文'")
+	b.SetOriginTextPosition(a.OriginTextPosition() + 1)
+	require.False(t, Equal(a, b))
+	require.NotEqual(t, Hash(a), Hash(b))
+	require.True(t, Equal(a, b, EqualIgnoreText))
+}
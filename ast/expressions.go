@@ -374,7 +374,10 @@ func (*SubqueryExpr) resultSet() {}
 // Restore implements Node interface.
 func (n *SubqueryExpr) Restore(ctx *format.RestoreCtx) error {
 	ctx.WritePlain("(")
-	if err := n.Query.Restore(ctx); err != nil {
+	err := ctx.WriteBlock(1, func(ctx *format.RestoreCtx) error {
+		return n.Query.Restore(ctx)
+	})
+	if err != nil {
 		return errors.Annotate(err, "An error occurred while restore SubqueryExpr.Query")
 	}
 	ctx.WritePlain(")")
@@ -959,6 +962,14 @@ type ParamMarkerExpr interface {
 	SetOrder(int)
 }
 
+// ParamMarkerOffsetter is implemented by the ParamMarkerExpr which keeps the
+// offset of the marker in the text, the offset is moved when the statement is
+// reused after an edit of the text before it.
+type ParamMarkerOffsetter interface {
+	GetOffset() int
+	SetOffset(int)
+}
+
 // ParenthesesExpr is the parentheses expression.
 type ParenthesesExpr struct {
 	exprNode
@@ -0,0 +1,486 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Clonegen generates the deep copy functions of the AST nodes and the list of
+// the types referenced by the JSON encoding.
+//
+// Usage, in the ast directory:
+//
+//	go run ./internal/clonegen -o clone_generated.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const astPkgPath = "github.com/daiguadaidai/parser/ast"
+
+// sharedTypes are the external types which are referenced but not copied,
+// they are schema objects bound to the AST.
+var sharedTypes = map[string]bool{
+	"github.com/daiguadaidai/parser/model.TableInfo":     true,
+	"github.com/daiguadaidai/parser/model.DBInfo":        true,
+	"github.com/daiguadaidai/parser/model.ColumnInfo":    true,
+	"github.com/daiguadaidai/parser/model.IndexInfo":     true,
+	"github.com/daiguadaidai/parser/model.PartitionInfo": true,
+}
+
+type generator struct {
+	pkg      *types.Package
+	nodeType *types.Interface
+	buf      bytes.Buffer
+	// structs are the struct types of the ast package to generate copyTo.
+	structs map[string]*types.Named
+	queue   []*types.Named
+	// needsDeep caches whether a type holds references which must be copied.
+	needsDeep map[types.Type]bool
+	// ifaces are the interfaces of the ast package which are not Node to
+	// generate the copy functions.
+	ifaces     map[string]*types.Named
+	ifaceQueue []*types.Named
+	// imports are the packages referenced by the generated code.
+	imports map[string]bool
+}
+
+func main() {
+	out := flag.String("o", "clone_generated.go", "output file")
+	dir := flag.String("dir", ".", "directory of the ast package")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, *dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && !strings.HasSuffix(fi.Name(), "_generated.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var files []*ast.File
+	for _, f := range pkgs["ast"].Files {
+		files = append(files, f)
+	}
+	// The methods defined by the generated file are missing, ignore the errors
+	// of referencing them.
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(astPkgPath, fset, files, nil)
+
+	g := &generator{
+		pkg:       pkg,
+		nodeType:  pkg.Scope().Lookup("Node").Type().Underlying().(*types.Interface),
+		structs:   make(map[string]*types.Named),
+		needsDeep: make(map[types.Type]bool),
+		ifaces:    make(map[string]*types.Named),
+		imports:   map[string]bool{"fmt": true},
+	}
+	src, err := g.generate()
+	if err != nil {
+		os.WriteFile("/tmp/clone_raw.go", src, 0644)
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// nodeTypes returns the named struct types whose pointer implements Node.
+func (g *generator) nodeTypes() []*types.Named {
+	var res []*types.Named
+	scope := g.pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !tn.Exported() || tn.IsAlias() {
+			continue
+		}
+		named, ok := tn.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		if _, ok := named.Underlying().(*types.Struct); !ok {
+			continue
+		}
+		if types.Implements(types.NewPointer(named), g.nodeType) {
+			res = append(res, named)
+		}
+	}
+	return res
+}
+
+func (g *generator) isLocal(named *types.Named) bool {
+	return named.Obj().Pkg() == g.pkg
+}
+
+func (g *generator) isNodeInterface(t types.Type) bool {
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && !iface.Empty() && types.AssignableTo(t, g.pkg.Scope().Lookup("Node").Type())
+}
+
+func (g *generator) enqueue(named *types.Named) {
+	name := named.Obj().Name()
+	if _, ok := g.structs[name]; ok {
+		return
+	}
+	g.structs[name] = named
+	g.queue = append(g.queue, named)
+}
+
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+func allExported(t types.Type) bool {
+	st := t.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		if !st.Field(i).Exported() {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *generator) enqueueInterface(named *types.Named) {
+	name := named.Obj().Name()
+	if _, ok := g.ifaces[name]; ok {
+		return
+	}
+	g.ifaces[name] = named
+	g.ifaceQueue = append(g.ifaceQueue, named)
+}
+
+// implementations returns the struct types of the ast package whose pointer
+// implements the interface.
+func (g *generator) implementations(iface *types.Named) []*types.Named {
+	var res []*types.Named
+	it := iface.Underlying().(*types.Interface)
+	scope := g.pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() || !isStruct(tn.Type()) {
+			continue
+		}
+		named := tn.Type().(*types.Named)
+		if types.Implements(types.NewPointer(named), it) {
+			res = append(res, named)
+		}
+	}
+	return res
+}
+
+func qualifiedName(named *types.Named) string {
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
+
+// cloneMethod returns whether *named has a `Clone() *named` method.
+func cloneMethod(named *types.Named) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), "Clone")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	return types.Identical(sig.Results().At(0).Type(), types.NewPointer(named)) ||
+		types.Identical(sig.Results().At(0).Type(), named)
+}
+
+// deep returns whether a value of t holds references which must be copied.
+func (g *generator) deep(t types.Type) bool {
+	if v, ok := g.needsDeep[t]; ok {
+		return v
+	}
+	// Assume no deep copy for recursive types, they are resolved by the pointer.
+	g.needsDeep[t] = false
+	var res bool
+	switch tt := t.(type) {
+	case *types.Basic:
+		res = false
+	case *types.Named:
+		if qualifiedName(tt) == "sync.Once" {
+			res = false
+		} else if g.isLocal(tt) {
+			_, isIface := tt.Underlying().(*types.Interface)
+			res = !isIface || g.isNodeInterface(tt) || len(g.implementations(tt)) > 0
+		} else if isStruct(tt) {
+			res = cloneMethod(tt) || allExported(tt) && g.deep(tt.Underlying())
+		} else {
+			res = g.deep(tt.Underlying())
+		}
+	case *types.Pointer:
+		// The external structs with unexported fields are opaque, they are
+		// shared, e.g. *regexp.Regexp.
+		named, ok := tt.Elem().(*types.Named)
+		res = !ok || qualifiedName(named) == "sync.Once" || g.isLocal(named) || !isStruct(named) || cloneMethod(named) || allExported(named)
+	case *types.Slice, *types.Map:
+		res = true
+	case *types.Array:
+		res = g.deep(tt.Elem())
+	case *types.Interface:
+		res = !tt.Empty() && g.isNodeInterface(tt) || tt.Empty()
+	case *types.Struct:
+		for i := 0; i < tt.NumFields(); i++ {
+			if g.deep(tt.Field(i).Type()) {
+				res = true
+				break
+			}
+		}
+	default:
+		res = false
+	}
+	g.needsDeep[t] = res
+	return res
+}
+
+func (g *generator) use(p *types.Package) {
+	if p != g.pkg {
+		g.imports[p.Path()] = true
+	}
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.use(p)
+		return p.Name()
+	})
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// copyValue writes the statements which make dst a deep copy of src, dst
+// already holds a shallow copy of src.
+func (g *generator) copyValue(dst, src string, t types.Type, depth int) {
+	if !g.deep(t) {
+		return
+	}
+	switch tt := t.(type) {
+	case *types.Named:
+		name := qualifiedName(tt)
+		switch {
+		case g.isNodeInterface(tt):
+			g.printf("if %s != nil {\n%s = DeepCopy(%s).(%s)\n}\n", src, dst, src, g.typeString(tt))
+		case g.isLocal(tt) && types.IsInterface(tt):
+			g.enqueueInterface(tt)
+			g.printf("%s = copy%s(%s)\n", dst, tt.Obj().Name(), src)
+		case g.isLocal(tt):
+			if _, ok := tt.Underlying().(*types.Struct); ok {
+				g.enqueue(tt)
+				g.printf("%s.copyTo(&%s)\n", src, dst)
+			} else {
+				g.copyValue(dst, src, tt.Underlying(), depth)
+			}
+		case sharedTypes[name]:
+		case cloneMethod(tt):
+			if g.cloneReturnsValue(tt) {
+				g.printf("%s = %s.Clone()\n", dst, src)
+			} else {
+				g.printf("%s = *%s.Clone()\n", dst, src)
+			}
+		default:
+			g.copyValue(dst, src, tt.Underlying(), depth)
+		}
+	case *types.Pointer:
+		elem := tt.Elem()
+		named, isNamed := elem.(*types.Named)
+		switch {
+		case isNamed && qualifiedName(named) == "sync.Once":
+			g.use(named.Obj().Pkg())
+			g.printf("if %s != nil {\n%s = &sync.Once{}\n}\n", src, dst)
+		case isNamed && g.isLocal(named) && types.Implements(tt, g.nodeType):
+			g.printf("%s = %s.Clone()\n", dst, src)
+		case isNamed && g.isLocal(named) && isStruct(named):
+			g.enqueue(named)
+			g.printf("if %s != nil {\n%s = new(%s)\n%s.copyTo(%s)\n}\n", src, dst, g.typeString(named), src, dst)
+		case isNamed && sharedTypes[qualifiedName(named)]:
+		case isNamed && cloneMethod(named) && !g.cloneReturnsValue(named):
+			g.printf("if %s != nil {\n%s = %s.Clone()\n}\n", src, dst, src)
+		default:
+			v := fmt.Sprintf("v%d", depth)
+			g.printf("if %s != nil {\n%s := *%s\n", src, v, src)
+			g.copyValue(v, "(*"+src+")", elem, depth+1)
+			g.printf("%s = &%s\n}\n", dst, v)
+		}
+	case *types.Slice:
+		if !g.deep(tt.Elem()) {
+			g.printf("if %s != nil {\n%s = append(%s(nil), %s...)\n}\n", src, dst, g.typeString(tt), src)
+			return
+		}
+		i := fmt.Sprintf("i%d", depth)
+		g.printf("if %s != nil {\n%s = make(%s, len(%s))\n", src, dst, g.typeString(tt), src)
+		g.printf("for %s := range %s {\n", i, src)
+		if _, ok := tt.Elem().Underlying().(*types.Array); ok {
+			g.printf("%s[%s] = %s[%s]\n", dst, i, src, i)
+		}
+		g.copyValue(fmt.Sprintf("%s[%s]", dst, i), fmt.Sprintf("%s[%s]", src, i), tt.Elem(), depth+1)
+		g.printf("}\n}\n")
+	case *types.Map:
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		g.printf("if %s != nil {\n%s = make(%s, len(%s))\n", src, dst, g.typeString(tt), src)
+		g.printf("for %s, %s := range %s {\n", k, v, src)
+		g.copyValue(v, v, tt.Elem(), depth+1)
+		g.printf("%s[%s] = %s\n}\n}\n", dst, k, v)
+	case *types.Array:
+		i := fmt.Sprintf("i%d", depth)
+		g.printf("for %s := range %s {\n", i, src)
+		g.copyValue(fmt.Sprintf("%s[%s]", dst, i), fmt.Sprintf("%s[%s]", src, i), tt.Elem(), depth+1)
+		g.printf("}\n")
+	case *types.Interface:
+		if tt.Empty() {
+			g.printf("%s = deepCopyValue(%s)\n", dst, src)
+		} else {
+			g.printf("if %s != nil {\n%s = DeepCopy(%s).(%s)\n}\n", src, dst, src, g.typeString(tt))
+		}
+	case *types.Struct:
+		for i := 0; i < tt.NumFields(); i++ {
+			f := tt.Field(i)
+			g.copyValue(dst+"."+f.Name(), src+"."+f.Name(), f.Type(), depth)
+		}
+	}
+}
+
+// copyInterface writes the copy function of an interface which is not Node.
+func (g *generator) copyInterface(iface *types.Named) {
+	name := iface.Obj().Name()
+	g.printf("func copy%s(v %s) %s {\nswitch x := v.(type) {\n", name, name, name)
+	for _, impl := range g.implementations(iface) {
+		g.enqueue(impl)
+		g.printf("case *%s:\nif x == nil {\nreturn x\n}\nc := new(%s)\nx.copyTo(c)\nreturn c\n", impl.Obj().Name(), impl.Obj().Name())
+	}
+	g.printf("}\nreturn v\n}\n\n")
+}
+
+func (g *generator) cloneReturnsValue(named *types.Named) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), "Clone")
+	sig := obj.(*types.Func).Type().(*types.Signature)
+	return types.Identical(sig.Results().At(0).Type(), named)
+}
+
+const header = `// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by clonegen. DO NOT EDIT.
+
+package ast
+
+`
+
+func (g *generator) generate() ([]byte, error) {
+	nodes := g.nodeTypes()
+	g.printf("// DeepCopy returns a deep copy of the node. The copy shares nothing with the\n")
+	g.printf("// node but the bound schema objects like TableName.TableInfo. The nodes\n")
+	g.printf("// defined out of this package must implement NodeCloner.\n")
+	g.printf("func DeepCopy(n Node) Node {\nif n == nil {\nreturn nil\n}\nswitch x := n.(type) {\n")
+	for _, named := range nodes {
+		g.printf("case *%s:\nreturn x.Clone()\n", named.Obj().Name())
+	}
+	g.printf("case NodeCloner:\nreturn x.CloneNode()\n}\n")
+	g.printf("panic(fmt.Sprintf(\"ast: DeepCopy of unknown node type %%T\", n))\n}\n\n")
+
+	for _, named := range nodes {
+		name := named.Obj().Name()
+		g.enqueue(named)
+		g.printf("// Clone returns a deep copy of the node.\n")
+		g.printf("func (n *%s) Clone() *%s {\nif n == nil {\nreturn nil\n}\nc := new(%s)\nn.copyTo(c)\nreturn c\n}\n\n", name, name, name)
+	}
+
+	for len(g.queue) > 0 || len(g.ifaceQueue) > 0 {
+		if len(g.queue) == 0 {
+			g.copyInterface(g.ifaceQueue[0])
+			g.ifaceQueue = g.ifaceQueue[1:]
+			continue
+		}
+		named := g.queue[0]
+		g.queue = g.queue[1:]
+		name := named.Obj().Name()
+		st := named.Underlying().(*types.Struct)
+		g.printf("func (n *%s) copyTo(c *%s) {\n*c = *n\n", name, name)
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			g.copyValue("c."+f.Name(), "n."+f.Name(), f.Type(), 0)
+		}
+		g.printf("}\n\n")
+	}
+
+	// The implementations of the interfaces which are not Node are referenced
+	// by the type discriminator of the JSON encoding too.
+	listed := append([]*types.Named(nil), nodes...)
+	ifaceNames := make([]string, 0, len(g.ifaces))
+	for name := range g.ifaces {
+		ifaceNames = append(ifaceNames, name)
+	}
+	sort.Strings(ifaceNames)
+	for _, name := range ifaceNames {
+		listed = append(listed, g.implementations(g.ifaces[name])...)
+	}
+	g.printf("// astTypes are the node types and the implementations of the other interfaces\n")
+	g.printf("// of this package.\n")
+	g.printf("var astTypes = []interface{}{\n")
+	for _, named := range listed {
+		g.printf("(*%s)(nil),\n", named.Obj().Name())
+	}
+	g.printf("}\n")
+
+	var src bytes.Buffer
+	src.WriteString(header)
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	src.WriteString("import (\n")
+	for _, std := range []bool{true, false} {
+		for _, p := range paths {
+			if !strings.Contains(p, ".") == std {
+				fmt.Fprintf(&src, "%q\n", p)
+			}
+		}
+		src.WriteString("\n")
+	}
+	src.WriteString(")\n\n")
+	src.Write(g.buf.Bytes())
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return src.Bytes(), err
+	}
+	return formatted, nil
+}
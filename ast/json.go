@@ -0,0 +1,744 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/daiguadaidai/parser/model"
+	"github.com/pingcap/errors"
+)
+
+// JSONVersion is the version of the JSON encoding of the AST. DecodeJSON
+// rejects the documents of a newer version.
+const JSONVersion = 1
+
+// JSONOption controls the JSON encoding of the AST.
+type JSONOption uint8
+
+const (
+	// JSONWithPositions encodes the original text and the positions of the
+	// nodes as "text" and "offset".
+	JSONWithPositions JSONOption = 1 << iota
+)
+
+// The JSON encoding of the AST looks like:
+//
+//	{"version": 1, "node": {"type": "SelectStmt", "Fields": {"type": "FieldList", ...}, ...}}
+//
+// A node is an object with the "type" discriminator, which is the name of the
+// Go type, and its exported fields, the zero fields are omitted. The fields of
+// the embedded structs are inlined. The other values are encoded as:
+//
+//   - string: a JSON string, or {"base64": "..."} if it's not valid UTF-8.
+//   - model.CIStr: the original string.
+//   - []byte: a base64 string.
+//   - types.FieldType: the object of its MarshalJSON.
+//   - interface{}: {"type": "<Go type>", "value": <value>}, or the node object.
+//   - ValueExpr of the parser driver: {"type": "ValueExpr", "kind": "int64",
+//     "value": ..., "exprType": <types.FieldType>}, the kinds are null, int64,
+//     uint64, float32, float64, string, bytes, decimal and binary.
+//   - ParamMarkerExpr of the parser driver: {"type": "ParamMarkerExpr",
+//     "Order": ..., "InExecute": ...}.
+//
+// The bound schema objects like TableName.TableInfo and the derived fields
+// like ColumnNameExpr.Refer are not encoded.
+
+const (
+	jsonValueExpr       = "ValueExpr"
+	jsonParamMarkerExpr = "ParamMarkerExpr"
+)
+
+var (
+	// jsonTypes maps the type discriminators to the types.
+	jsonTypes = make(map[string]reflect.Type)
+	// jsonValueTypes are the types of values held by the interface{} fields.
+	jsonValueTypes = make(map[string]reflect.Type)
+)
+
+func init() {
+	for _, v := range astTypes {
+		tp := reflect.TypeOf(v)
+		jsonTypes[tp.Elem().Name()] = tp
+	}
+	for _, v := range []interface{}{false, int64(0), uint64(0), float64(0), "", []byte(nil), model.CIStr{}, HintSetVar{}, HintTimeRange{}} {
+		tp := reflect.TypeOf(v)
+		jsonValueTypes[tp.String()] = tp
+	}
+}
+
+type jsonDocument struct {
+	Version int             `json:"version"`
+	Node    json.RawMessage `json:"node"`
+}
+
+// EncodeJSON encodes the node to JSON.
+func EncodeJSON(n Node, opts ...JSONOption) ([]byte, error) {
+	var opt JSONOption
+	for _, o := range opts {
+		opt |= o
+	}
+	e := jsonEncoder{opts: opt}
+	fmt.Fprintf(&e.buf, `{"version":%d,"node":`, JSONVersion)
+	if err := e.writeNode(n); err != nil {
+		return nil, err
+	}
+	e.buf.WriteByte('}')
+	return e.buf.Bytes(), nil
+}
+
+// DecodeJSON decodes the node encoded by EncodeJSON.
+func DecodeJSON(data []byte) (Node, error) {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if doc.Version < 1 || doc.Version > JSONVersion {
+		return nil, errors.Errorf("unsupported AST JSON version %d", doc.Version)
+	}
+	n, err := decodeJSONNode(doc.Node)
+	if err != nil {
+		return nil, err
+	}
+	if n != nil {
+		SetFlag(n)
+	}
+	return n, nil
+}
+
+type jsonEncoder struct {
+	opts JSONOption
+	buf  bytes.Buffer
+}
+
+func (e *jsonEncoder) writeJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.buf.Write(data)
+	return nil
+}
+
+// writeString writes the string, the string which is not valid UTF-8 can't be
+// represented by a JSON string, it's written as {"base64": "..."}.
+func (e *jsonEncoder) writeString(s string) {
+	if utf8.ValidString(s) {
+		_ = e.writeJSON(s)
+		return
+	}
+	e.buf.WriteString(`{"base64":`)
+	_ = e.writeJSON([]byte(s))
+	e.buf.WriteByte('}')
+}
+
+func readJSONString(raw json.RawMessage, s *string) error {
+	if len(raw) > 0 && raw[0] == '{' {
+		var b struct {
+			Base64 []byte `json:"base64"`
+		}
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return errors.Trace(err)
+		}
+		*s = string(b.Base64)
+		return nil
+	}
+	return errors.Trace(json.Unmarshal(raw, s))
+}
+
+func (e *jsonEncoder) writeKey(first *bool, key string) {
+	if !*first {
+		e.buf.WriteByte(',')
+	}
+	*first = false
+	e.buf.WriteString(strconv.Quote(key))
+	e.buf.WriteByte(':')
+}
+
+func (e *jsonEncoder) writeNode(n Node) error {
+	if n == nil || reflect.ValueOf(n).IsNil() {
+		e.buf.WriteString("null")
+		return nil
+	}
+	v := reflect.ValueOf(n)
+	if _, ok := jsonTypes[v.Type().Elem().Name()]; ok && v.Type().Elem().PkgPath() == nodeType.PkgPath() {
+		return e.writeObject(v)
+	}
+	first := true
+	e.buf.WriteByte('{')
+	switch x := n.(type) {
+	case ParamMarkerExpr:
+		e.writeKey(&first, "type")
+		e.buf.WriteString(strconv.Quote(jsonParamMarkerExpr))
+		elem := v.Elem()
+		for _, name := range []string{"Offset", "Order", "InExecute"} {
+			f := elem.FieldByName(name)
+			if !f.IsValid() || f.IsZero() || name == "Offset" && e.opts&JSONWithPositions == 0 {
+				continue
+			}
+			e.writeKey(&first, name)
+			if err := e.writeValue(f); err != nil {
+				return err
+			}
+		}
+	case ValueExpr:
+		e.writeKey(&first, "type")
+		e.buf.WriteString(strconv.Quote(jsonValueExpr))
+		kind, value, err := jsonDatum(x.GetValue())
+		if err != nil {
+			return err
+		}
+		e.writeKey(&first, "kind")
+		e.buf.WriteString(strconv.Quote(kind))
+		if value != nil {
+			e.writeKey(&first, "value")
+			if str, ok := value.(string); ok {
+				e.writeString(str)
+			} else if err := e.writeJSON(value); err != nil {
+				return err
+			}
+		}
+		e.writeKey(&first, "exprType")
+		if err := e.writeJSON(x.GetType()); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("can't encode node %T to JSON", n)
+	}
+	e.writePositions(&first, n)
+	e.buf.WriteByte('}')
+	return nil
+}
+
+func (e *jsonEncoder) writePositions(first *bool, n Node) {
+	if e.opts&JSONWithPositions == 0 {
+		return
+	}
+	e.writeKey(first, "text")
+	e.writeString(n.Text())
+	e.writeKey(first, "offset")
+	e.buf.WriteString(strconv.Itoa(n.OriginTextPosition()))
+}
+
+// jsonDatum returns the kind and the JSON value of the value of a ValueExpr.
+func jsonDatum(v interface{}) (string, interface{}, error) {
+	switch x := v.(type) {
+	case nil:
+		return "null", nil, nil
+	case int64:
+		return "int64", x, nil
+	case uint64:
+		return "uint64", x, nil
+	case float32:
+		return "float32", x, nil
+	case float64:
+		return "float64", x, nil
+	case string:
+		return "string", x, nil
+	case []byte:
+		return "bytes", x, nil
+	case BinaryLiteral:
+		return "binary", hex.EncodeToString(reflect.ValueOf(x).Bytes()), nil
+	case fmt.Stringer:
+		// The decimal of the parser driver.
+		return "decimal", x.String(), nil
+	}
+	return "", nil, errors.Errorf("can't encode value %T to JSON", v)
+}
+
+// writeObject writes the node or the other struct pointed by v with the type
+// discriminator.
+func (e *jsonEncoder) writeObject(v reflect.Value) error {
+	first := true
+	e.buf.WriteByte('{')
+	e.writeKey(&first, "type")
+	e.buf.WriteString(strconv.Quote(v.Type().Elem().Name()))
+	if err := e.writeFields(&first, v.Elem()); err != nil {
+		return err
+	}
+	if n, ok := v.Interface().(Node); ok {
+		e.writePositions(&first, n)
+	}
+	e.buf.WriteByte('}')
+	return nil
+}
+
+func (e *jsonEncoder) writeFields(first *bool, v reflect.Value) error {
+	tp := v.Type()
+	kinds := fieldKinds(tp)
+	for i := 0; i < tp.NumField(); i++ {
+		f := tp.Field(i)
+		fv := v.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			// The original text of node is written by writePositions.
+			if f.Type == nodeType {
+				continue
+			}
+			if err := e.writeFields(first, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() || kinds[i] == fieldSkip || isSchemaObject(f.Type) || fv.IsZero() ||
+			kinds[i] == fieldText && e.opts&JSONWithPositions == 0 {
+			continue
+		}
+		e.writeKey(first, f.Name)
+		if err := e.writeValue(fv); err != nil {
+			return errors.Annotatef(err, "field %s.%s", tp.Name(), f.Name)
+		}
+	}
+	return nil
+}
+
+// hasJSONMethods returns whether the type is encoded by its own MarshalJSON
+// and UnmarshalJSON, like types.FieldType.
+func hasJSONMethods(tp reflect.Type) bool {
+	ptr := reflect.PtrTo(tp)
+	return ptr.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) &&
+		ptr.Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem())
+}
+
+func (e *jsonEncoder) writeValue(v reflect.Value) error {
+	tp := v.Type()
+	if tp == ciStrType {
+		e.writeString(v.Field(0).String())
+		return nil
+	}
+	if tp.Kind() != reflect.Ptr && tp.Kind() != reflect.Interface && hasJSONMethods(tp) {
+		ptr := reflect.New(tp)
+		ptr.Elem().Set(v)
+		return e.writeJSON(ptr.Interface())
+	}
+	switch tp.Kind() {
+	case reflect.Bool:
+		e.buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return e.writeJSON(v.Float())
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Ptr:
+		if v.IsNil() || isSchemaObject(tp) {
+			e.buf.WriteString("null")
+			return nil
+		}
+		if n, ok := v.Interface().(Node); ok {
+			return e.writeNode(n)
+		}
+		return e.writeValue(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		elem := v.Elem()
+		if n, ok := elem.Interface().(Node); ok {
+			return e.writeNode(n)
+		}
+		if tp.NumMethod() > 0 {
+			// The interfaces which are not Node, like PartitionDefinitionClause.
+			if _, ok := jsonTypes[elem.Type().Elem().Name()]; !ok || elem.Kind() != reflect.Ptr {
+				return errors.Errorf("can't encode %s to JSON", elem.Type())
+			}
+			return e.writeObject(elem)
+		}
+		if _, ok := jsonValueTypes[elem.Type().String()]; !ok {
+			return errors.Errorf("can't encode %s to JSON", elem.Type())
+		}
+		first := true
+		e.buf.WriteByte('{')
+		e.writeKey(&first, "type")
+		e.buf.WriteString(strconv.Quote(elem.Type().String()))
+		e.writeKey(&first, "value")
+		if err := e.writeValue(elem); err != nil {
+			return err
+		}
+		e.buf.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		if tp.Elem().Kind() == reflect.Uint8 && tp.Kind() == reflect.Slice {
+			return e.writeJSON(v.Bytes())
+		}
+		if tp.Kind() == reflect.Slice && v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		e.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.writeValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+	case reflect.Struct:
+		first := true
+		e.buf.WriteByte('{')
+		if err := e.writeFields(&first, v); err != nil {
+			return err
+		}
+		e.buf.WriteByte('}')
+	default:
+		return errors.Errorf("can't encode %s to JSON", tp)
+	}
+	return nil
+}
+
+type jsonObject map[string]json.RawMessage
+
+func isJSONNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+func (o jsonObject) typeName() (string, error) {
+	var name string
+	if err := json.Unmarshal(o["type"], &name); err != nil {
+		return "", errors.Annotate(err, "invalid type discriminator")
+	}
+	return name, nil
+}
+
+func decodeJSONNode(data json.RawMessage) (Node, error) {
+	if isJSONNull(data) {
+		return nil, nil
+	}
+	var obj jsonObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, errors.Trace(err)
+	}
+	name, err := obj.typeName()
+	if err != nil {
+		return nil, err
+	}
+	var n Node
+	switch name {
+	case jsonValueExpr:
+		n, err = decodeJSONValueExpr(obj)
+	case jsonParamMarkerExpr:
+		n, err = decodeJSONParamMarkerExpr(obj)
+	default:
+		tp, ok := jsonTypes[name]
+		if !ok {
+			return nil, errors.Errorf("unknown node type %q", name)
+		}
+		var v reflect.Value
+		if v, err = decodeJSONObject(tp, obj); err == nil {
+			var isNode bool
+			if n, isNode = v.Interface().(Node); !isNode {
+				return nil, errors.Errorf("%s is not a node", name)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := readJSONPositions(n, obj); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func readJSONPositions(n Node, obj jsonObject) error {
+	if raw, ok := obj["text"]; ok {
+		var text string
+		if err := readJSONString(raw, &text); err != nil {
+			return err
+		}
+		n.SetText(nil, text)
+	}
+	if raw, ok := obj["offset"]; ok {
+		var offset int
+		if err := json.Unmarshal(raw, &offset); err != nil {
+			return errors.Trace(err)
+		}
+		n.SetOriginTextPosition(offset)
+	}
+	return nil
+}
+
+func decodeJSONValueExpr(obj jsonObject) (Node, error) {
+	var kind string
+	if err := json.Unmarshal(obj["kind"], &kind); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var value interface{}
+	var err error
+	raw := obj["value"]
+	switch kind {
+	case "null":
+	case "int64":
+		var x int64
+		err = json.Unmarshal(raw, &x)
+		value = x
+	case "uint64":
+		var x uint64
+		err = json.Unmarshal(raw, &x)
+		value = x
+	case "float32":
+		var x float32
+		err = json.Unmarshal(raw, &x)
+		value = x
+	case "float64":
+		var x float64
+		err = json.Unmarshal(raw, &x)
+		value = x
+	case "string":
+		var x string
+		err = readJSONString(raw, &x)
+		value = x
+	case "bytes":
+		var x []byte
+		if err = json.Unmarshal(raw, &x); x == nil {
+			x = []byte{}
+		}
+		value = x
+	case "decimal", "binary":
+		var x string
+		if err = json.Unmarshal(raw, &x); err != nil {
+			break
+		}
+		if kind == "decimal" {
+			value, err = NewDecimal(x)
+		} else {
+			value, err = NewHexLiteral("x'" + x + "'")
+		}
+	default:
+		return nil, errors.Errorf("unknown value kind %q", kind)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	n := NewValueExpr(value, "", "")
+	if raw, ok := obj["exprType"]; ok {
+		if err := json.Unmarshal(raw, n.GetType()); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return n, nil
+}
+
+func decodeJSONParamMarkerExpr(obj jsonObject) (Node, error) {
+	var fields struct {
+		Offset    int
+		Order     int
+		InExecute bool
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Trace(err)
+	}
+	n := NewParamMarkerExpr(fields.Offset)
+	n.SetOrder(fields.Order)
+	if f := reflect.ValueOf(n).Elem().FieldByName("InExecute"); f.IsValid() && f.CanSet() {
+		f.SetBool(fields.InExecute)
+	}
+	return n, nil
+}
+
+// decodeJSONObject decodes the object of the pointer type tp.
+func decodeJSONObject(tp reflect.Type, obj jsonObject) (reflect.Value, error) {
+	v := reflect.New(tp.Elem())
+	if err := readJSONFields(v.Elem(), obj); err != nil {
+		return v, errors.Annotatef(err, "decode %s", tp.Elem().Name())
+	}
+	return v, nil
+}
+
+func readJSONFields(v reflect.Value, obj jsonObject) error {
+	tp := v.Type()
+	for i := 0; i < tp.NumField(); i++ {
+		f := tp.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if f.Type == nodeType {
+				continue
+			}
+			if err := readJSONFields(v.Field(i), obj); err != nil {
+				return err
+			}
+			continue
+		}
+		raw, ok := obj[f.Name]
+		if !ok || !f.IsExported() {
+			continue
+		}
+		if err := readJSONValue(v.Field(i), raw); err != nil {
+			return errors.Annotatef(err, "field %s", f.Name)
+		}
+	}
+	return nil
+}
+
+func readJSONValue(v reflect.Value, raw json.RawMessage) error {
+	if isJSONNull(raw) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	tp := v.Type()
+	if tp == ciStrType {
+		var s string
+		if err := readJSONString(raw, &s); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(model.NewCIStr(s)))
+		return nil
+	}
+	if tp.Kind() != reflect.Ptr && tp.Kind() != reflect.Interface && hasJSONMethods(tp) {
+		return errors.Trace(json.Unmarshal(raw, v.Addr().Interface()))
+	}
+	switch tp.Kind() {
+	case reflect.Bool:
+		var x bool
+		if err := json.Unmarshal(raw, &x); err != nil {
+			return errors.Trace(err)
+		}
+		v.SetBool(x)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var x int64
+		if err := json.Unmarshal(raw, &x); err != nil {
+			return errors.Trace(err)
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var x uint64
+		if err := json.Unmarshal(raw, &x); err != nil {
+			return errors.Trace(err)
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		var x float64
+		if err := json.Unmarshal(raw, &x); err != nil {
+			return errors.Trace(err)
+		}
+		v.SetFloat(x)
+	case reflect.String:
+		var x string
+		if err := readJSONString(raw, &x); err != nil {
+			return err
+		}
+		v.SetString(x)
+	case reflect.Ptr:
+		if isSchemaObject(tp) {
+			return nil
+		}
+		if tp.Implements(reflect.TypeOf((*Node)(nil)).Elem()) {
+			n, err := decodeJSONNode(raw)
+			if err != nil {
+				return err
+			}
+			return setJSONValue(v, reflect.ValueOf(n))
+		}
+		elem := reflect.New(tp.Elem())
+		if err := readJSONValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Interface:
+		return readJSONInterface(v, raw)
+	case reflect.Slice:
+		if tp.Elem().Kind() == reflect.Uint8 {
+			var x []byte
+			if err := json.Unmarshal(raw, &x); err != nil {
+				return errors.Trace(err)
+			}
+			v.SetBytes(x)
+			return nil
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return errors.Trace(err)
+		}
+		s := reflect.MakeSlice(tp, len(items), len(items))
+		for i, item := range items {
+			if err := readJSONValue(s.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return errors.Trace(err)
+		}
+		if len(items) != v.Len() {
+			return errors.Errorf("expect %d items for %s, but got %d", v.Len(), tp, len(items))
+		}
+		for i, item := range items {
+			if err := readJSONValue(v.Index(i), item); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		var obj jsonObject
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return errors.Trace(err)
+		}
+		return readJSONFields(v, obj)
+	default:
+		return errors.Errorf("can't decode %s from JSON", tp)
+	}
+	return nil
+}
+
+func readJSONInterface(v reflect.Value, raw json.RawMessage) error {
+	var obj jsonObject
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return errors.Trace(err)
+	}
+	name, err := obj.typeName()
+	if err != nil {
+		return err
+	}
+	if tp, ok := jsonValueTypes[name]; ok && v.Type().NumMethod() == 0 {
+		x := reflect.New(tp).Elem()
+		if err := readJSONValue(x, obj["value"]); err != nil {
+			return err
+		}
+		v.Set(x)
+		return nil
+	}
+	if tp, ok := jsonTypes[name]; ok && !tp.Implements(reflect.TypeOf((*Node)(nil)).Elem()) {
+		x, err := decodeJSONObject(tp, obj)
+		if err != nil {
+			return err
+		}
+		return setJSONValue(v, x)
+	}
+	n, err := decodeJSONNode(raw)
+	if err != nil {
+		return err
+	}
+	return setJSONValue(v, reflect.ValueOf(n))
+}
+
+func setJSONValue(dst, v reflect.Value) error {
+	if !v.Type().AssignableTo(dst.Type()) {
+		return errors.Errorf("can't assign %s to %s", v.Type(), dst.Type())
+	}
+	dst.Set(v)
+	return nil
+}
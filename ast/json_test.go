@@ -0,0 +1,94 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	. "github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	p := parser.New()
+	sqls := []string{
+		"select /*+ max_execution_time(1000), use_index(t idx), memory_quota(1 MB), set_var(sql_mode = 'ANSI') */ a, b from t where a in (1, 2.5, 0x0a, b'1', ?) and b = 'x\xffy'",
+		"select * from t1 natural join t2 where a > all (select b from t3) for update nowait",
+		"create table t (id int primary key, c varchar(10) character set utf8mb4 default 'x') partition by range (id) (partition p0 values less than (10), partition p1 values less than maxvalue)",
+		"create table t (id int) partition by list (id) (partition p0 values in (1, 2), partition p1 default)",
+		"alter table t add column c enum('a', 'b') not null, algorithm = instant",
+		"insert into t values (-1, 18446744073709551615, 1e3, null, true)",
+		"grant select on db.* to 'u'@'%' with grant option",
+	}
+	for _, sql := range sqls {
+		stmt, err := p.ParseOneStmt(sql, "", "")
+		require.NoError(t, err, sql)
+		for _, opts := range [][]JSONOption{nil, {JSONWithPositions}} {
+			data, err := EncodeJSON(stmt, opts...)
+			require.NoError(t, err, sql)
+			require.True(t, json.Valid(data), sql)
+			decoded, err := DecodeJSON(data)
+			require.NoError(t, err, sql)
+			require.Equal(t, restore(t, stmt), restore(t, decoded), sql)
+			require.True(t, Equal(stmt, decoded, EqualIgnoreText), sql)
+			if len(opts) > 0 {
+				require.Equal(t, stmt.Text(), decoded.Text())
+				require.True(t, Equal(stmt, decoded), sql)
+			} else {
+				require.Empty(t, decoded.Text())
+			}
+		}
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	p := parser.New()
+	stmt, err := p.ParseOneStmt("select a from t where b = ?", "", "")
+	require.NoError(t, err)
+	data, err := EncodeJSON(stmt)
+	require.NoError(t, err)
+
+	var doc struct {
+		Version int
+		Node    map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Equal(t, JSONVersion, doc.Version)
+	require.Equal(t, "SelectStmt", doc.Node["type"])
+	require.NotContains(t, doc.Node, "text")
+	where := doc.Node["Where"].(map[string]interface{})
+	require.Equal(t, "BinaryOperationExpr", where["type"])
+	require.Equal(t, "ParamMarkerExpr", where["R"].(map[string]interface{})["type"])
+	require.Equal(t, "b", where["L"].(map[string]interface{})["Name"].(map[string]interface{})["Name"])
+
+	decoded, err := DecodeJSON(data)
+	require.NoError(t, err)
+	require.IsType(t, &test_driver.ParamMarkerExpr{}, decoded.(*SelectStmt).Where.(*BinaryOperationExpr).R)
+
+	data, err = EncodeJSON(stmt, JSONWithPositions)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Equal(t, "select a from t where b = ?", doc.Node["text"])
+
+	_, err = DecodeJSON([]byte(`{"version":2,"node":null}`))
+	require.EqualError(t, err, "unsupported AST JSON version 2")
+	_, err = DecodeJSON([]byte(`{"version":1,"node":{"type":"NoSuchStmt"}}`))
+	require.EqualError(t, err, `unknown node type "NoSuchStmt"`)
+	n, err := DecodeJSON([]byte(`{"version":1,"node":null}`))
+	require.NoError(t, err)
+	require.Nil(t, n)
+}
@@ -0,0 +1,248 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Inspect traverses the node in depth-first order by Accept. f is called for
+// every node, the children of the node are skipped if f returns false.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil {
+		return
+	}
+	node.Accept(inspector(f))
+}
+
+type inspector func(Node) bool
+
+// Enter implements Visitor interface.
+func (f inspector) Enter(n Node) (Node, bool) {
+	return n, !f(n)
+}
+
+// Leave implements Visitor interface.
+func (f inspector) Leave(n Node) (Node, bool) {
+	return n, true
+}
+
+// FindAll returns the nodes of type T in the node in depth-first order, like
+// FindAll[*ColumnNameExpr](stmt).
+func FindAll[T Node](node Node) []T {
+	var res []T
+	Inspect(node, func(n Node) bool {
+		if x, ok := n.(T); ok {
+			res = append(res, x)
+		}
+		return true
+	})
+	return res
+}
+
+// FindColumnNames returns the column references in the node.
+func FindColumnNames(node Node) []*ColumnNameExpr {
+	return FindAll[*ColumnNameExpr](node)
+}
+
+// FindTableNames returns the table names in the node.
+func FindTableNames(node Node) []*TableName {
+	return FindAll[*TableName](node)
+}
+
+// FindFuncCalls returns the generic function calls in the node.
+func FindFuncCalls(node Node) []*FuncCallExpr {
+	return FindAll[*FuncCallExpr](node)
+}
+
+// ReplaceNodes replaces the nodes matched by match with the result of replace, and
+// returns the root which may be replaced too. The nodes are replaced after
+// their children, so the children of a replaced node are already replaced.
+// The rule of Visitor.Leave applies to the replacement: a non-expression node
+// can only be replaced by a node of the same type.
+func ReplaceNodes(root Node, match func(Node) bool, replace func(Node) Node) Node {
+	return Walk(root, nil, func(c *Cursor) bool {
+		if match(c.Node()) {
+			c.Replace(replace(c.Node()))
+		}
+		return true
+	})
+}
+
+// Cursor describes a node during Walk.
+type Cursor struct {
+	node    Node
+	parent  *Cursor
+	depth   int
+	name    string
+	index   int
+	skipped bool
+	// children maps the children to their fields, it's built when the first
+	// child is entered.
+	children map[Node]childField
+}
+
+type childField struct {
+	name  string
+	index int
+}
+
+// Node returns the current node.
+func (c *Cursor) Node() Node {
+	return c.node
+}
+
+// Parent returns the cursor of the parent node, it's nil for the root.
+func (c *Cursor) Parent() *Cursor {
+	return c.parent
+}
+
+// Depth returns the depth of the node, the depth of the root is 0.
+func (c *Cursor) Depth() int {
+	return c.depth
+}
+
+// Name returns the name of the field of the parent which holds the node, like
+// "Where" or "Fields". The fields of nested structs are joined by '.', like
+// "Frame.Extent.Start.Expr". It's empty for the root.
+func (c *Cursor) Name() string {
+	return c.name
+}
+
+// Index returns the index of the node in the slice Name refers to, or -1 if
+// the field is not a slice.
+func (c *Cursor) Index() int {
+	return c.index
+}
+
+// Replace replaces the current node. The rules of Visitor apply: the node
+// returned by Enter must be the same type, so a node replaced in pre must have
+// the same type, and the children of the replacement are visited.
+func (c *Cursor) Replace(n Node) {
+	c.node = n
+}
+
+// Walk traverses the node in depth-first order like Inspect, and calls pre
+// before and post after the children of a node are visited. The children and
+// post are skipped if pre returns false, the traversal stops if post returns
+// false. Either function may be nil. It returns the root, which may be
+// replaced by Cursor.Replace.
+func Walk(root Node, pre, post func(c *Cursor) bool) Node {
+	if root == nil {
+		return nil
+	}
+	w := &walker{pre: pre, post: post}
+	n, _ := root.Accept(w)
+	return n
+}
+
+type walker struct {
+	pre, post func(*Cursor) bool
+	stack     []*Cursor
+}
+
+// Enter implements Visitor interface.
+func (w *walker) Enter(n Node) (Node, bool) {
+	c := &Cursor{node: n, index: -1}
+	if len(w.stack) > 0 {
+		parent := w.stack[len(w.stack)-1]
+		c.parent = parent
+		c.depth = parent.depth + 1
+		f := parent.childField(n)
+		c.name, c.index = f.name, f.index
+	}
+	w.stack = append(w.stack, c)
+	if w.pre != nil && !w.pre(c) {
+		c.skipped = true
+		return c.node, true
+	}
+	return c.node, false
+}
+
+// Leave implements Visitor interface.
+func (w *walker) Leave(n Node) (Node, bool) {
+	c := w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+	c.node = n
+	if w.post != nil && !c.skipped && !w.post(c) {
+		return c.node, false
+	}
+	return c.node, true
+}
+
+func (c *Cursor) childField(n Node) childField {
+	if c.children == nil {
+		c.children = make(map[Node]childField)
+		v := reflect.ValueOf(c.node)
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			collectChildFields(v.Elem(), "", -1, c.children)
+		}
+	}
+	if f, ok := c.children[n]; ok {
+		return f
+	}
+	return childField{index: -1}
+}
+
+// collectChildFields collects the nodes held by v, a value owned by the parent.
+func collectChildFields(v reflect.Value, name string, index int, res map[Node]childField) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() || isSchemaObject(v.Type()) || !v.CanInterface() {
+			return
+		}
+		if n, ok := v.Interface().(Node); ok {
+			res[n] = childField{name: name, index: index}
+			return
+		}
+		collectChildFields(v.Elem(), name, index, res)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			elemName := name
+			if index >= 0 {
+				// The index of the outer slice is kept in the name.
+				elemName = name + "[" + strconv.Itoa(index) + "]"
+			}
+			collectChildFields(v.Index(i), elemName, i, res)
+		}
+	case reflect.Struct:
+		tp := v.Type()
+		if tp == ciStrType || tp == nodeType {
+			return
+		}
+		for i := 0; i < tp.NumField(); i++ {
+			f := tp.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			if f.Anonymous {
+				collectChildFields(v.Field(i), name, index, res)
+				continue
+			}
+			fieldName := f.Name
+			if name != "" {
+				if index >= 0 {
+					fieldName = name + "[" + strconv.Itoa(index) + "]." + f.Name
+				} else {
+					fieldName = name + "." + f.Name
+				}
+			}
+			collectChildFields(v.Field(i), fieldName, -1, res)
+		}
+	}
+}
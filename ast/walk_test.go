@@ -0,0 +1,151 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	. "github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectAndFind(t *testing.T) {
+	p := parser.New()
+	stmt, err := p.ParseOneStmt("select a, upper(t1.b) from t1 join db.t2 on t1.id = t2.id where c in (select d from t3) and concat(e, 'x') = 'y'", "", "")
+	require.NoError(t, err)
+
+	var cols []string
+	for _, c := range FindColumnNames(stmt) {
+		cols = append(cols, c.Name.String())
+	}
+	require.Equal(t, []string{"a", "t1.b", "t1.id", "t2.id", "c", "d", "e"}, cols)
+
+	var tables []string
+	for _, tn := range FindTableNames(stmt) {
+		tables = append(tables, tn.Schema.O+"."+tn.Name.O)
+	}
+	require.Equal(t, []string{".t1", "db.t2", ".t3"}, tables)
+
+	var funcs []string
+	for _, f := range FindFuncCalls(stmt) {
+		funcs = append(funcs, f.FnName.L)
+	}
+	require.Equal(t, []string{"upper", "concat"}, funcs)
+	require.Len(t, FindAll[*SubqueryExpr](stmt), 1)
+
+	// Skip the children of the subquery.
+	cnt := 0
+	Inspect(stmt, func(n Node) bool {
+		if _, ok := n.(*ColumnNameExpr); ok {
+			cnt++
+		}
+		_, ok := n.(*SubqueryExpr)
+		return !ok
+	})
+	require.Equal(t, 6, cnt)
+	Inspect(nil, func(Node) bool { panic("unreachable") })
+}
+
+func TestWalkCursor(t *testing.T) {
+	p := parser.New()
+	stmt, err := p.ParseOneStmt("select a, b from t where c = 1 order by d", "", "")
+	require.NoError(t, err)
+
+	var paths []string
+	Walk(stmt, func(c *Cursor) bool {
+		var chain []string
+		for x := c; x != nil; x = x.Parent() {
+			chain = append([]string{fmt.Sprintf("%T", x.Node())[5:]}, chain...)
+		}
+		if col, ok := c.Node().(*ColumnNameExpr); ok {
+			paths = append(paths, fmt.Sprintf("%s %s[%d] %d %s", col.Name.Name.O, c.Name(), c.Index(), c.Depth(), strings.Join(chain, "/")))
+		}
+		return true
+	}, nil)
+	require.Equal(t, []string{
+		"a Expr[-1] 3 SelectStmt/FieldList/SelectField/ColumnNameExpr",
+		"b Expr[-1] 3 SelectStmt/FieldList/SelectField/ColumnNameExpr",
+		"c L[-1] 2 SelectStmt/BinaryOperationExpr/ColumnNameExpr",
+		"d Expr[-1] 3 SelectStmt/OrderByClause/ByItem/ColumnNameExpr",
+	}, paths)
+
+	var fields []string
+	Walk(stmt, func(c *Cursor) bool {
+		if c.Parent() != nil && c.Parent().Parent() == nil {
+			fields = append(fields, c.Name())
+		}
+		if _, ok := c.Node().(*SelectField); ok {
+			fields = append(fields, fmt.Sprintf("%s[%d]", c.Name(), c.Index()))
+		}
+		return true
+	}, nil)
+	require.Equal(t, []string{"Fields", "Fields[0]", "Fields[1]", "From", "Where", "OrderBy"}, fields)
+
+	// Stop after the first column.
+	visited := 0
+	Walk(stmt, nil, func(c *Cursor) bool {
+		visited++
+		_, ok := c.Node().(*ColumnNameExpr)
+		return !ok
+	})
+	require.Less(t, visited, 6)
+}
+
+func TestWalkNestedFields(t *testing.T) {
+	p := parser.New()
+	stmt, err := p.ParseOneStmt("insert into t values (1, 2), (3, 4)", "", "")
+	require.NoError(t, err)
+	var fields []string
+	Walk(stmt, func(c *Cursor) bool {
+		if v, ok := c.Node().(*test_driver.ValueExpr); ok {
+			fields = append(fields, fmt.Sprintf("%d:%s[%d]", v.GetInt64(), c.Name(), c.Index()))
+		}
+		return true
+	}, nil)
+	require.Equal(t, []string{"1:Lists[0][0]", "2:Lists[0][1]", "3:Lists[1][0]", "4:Lists[1][1]"}, fields)
+}
+
+func TestReplaceNodes(t *testing.T) {
+	p := parser.New()
+	stmt, err := p.ParseOneStmt("select a + 1 from t where b = 2 and a > 3", "", "")
+	require.NoError(t, err)
+
+	// Rename the column a and wrap the constants in parentheses.
+	res := ReplaceNodes(stmt, func(n Node) bool {
+		switch x := n.(type) {
+		case *ColumnNameExpr:
+			return x.Name.Name.L == "a"
+		case ValueExpr:
+			return true
+		}
+		return false
+	}, func(n Node) Node {
+		if col, ok := n.(*ColumnNameExpr); ok {
+			c := col.Clone()
+			c.Name.Name.O, c.Name.Name.L = "x", "x"
+			return c
+		}
+		return &ParenthesesExpr{Expr: n.(ExprNode)}
+	})
+	require.Same(t, stmt, res)
+	require.Equal(t, "SELECT `x`+(1) FROM `t` WHERE `b`=(2) AND `x`>(3)", restore(t, res))
+
+	// The root can be replaced.
+	expr := &ColumnNameExpr{Name: &ColumnName{}}
+	require.Same(t, expr, ReplaceNodes(stmt.(*SelectStmt).Where, func(n Node) bool { return n == stmt.(*SelectStmt).Where }, func(Node) Node { return expr }))
+}
@@ -15,6 +15,8 @@ package parser
 
 import (
 	"testing"
+
+	"github.com/daiguadaidai/parser/mysql"
 )
 
 func BenchmarkSysbenchSelect(b *testing.B) {
@@ -64,3 +66,43 @@ func BenchmarkParseSimple(b *testing.B) {
 	}
 	b.ReportAllocs()
 }
+
+func BenchmarkParserPerQuery(b *testing.B) {
+	sql := "SELECT pad FROM sbtest1 WHERE id=1;"
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, _, err := New().ParseSQL(sql); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkParserPool(b *testing.B) {
+	sql := "SELECT pad FROM sbtest1 WHERE id=1;"
+	pool := NewPool(ParserConfig{EnableWindowFunction: true, EnableStrictDoubleTypeCheck: true}, mysql.ModeNone)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, _, err := pool.ParseSQL(sql); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkParserPoolGetPut(b *testing.B) {
+	sql := "SELECT pad FROM sbtest1 WHERE id=1;"
+	pool := NewPool(ParserConfig{EnableWindowFunction: true, EnableStrictDoubleTypeCheck: true}, mysql.ModeNone)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p := pool.Get()
+			if _, _, err := p.ParseSQL(sql); err != nil {
+				b.Fatal(err)
+			}
+			pool.Put(p)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Sqllsp is a Language Server Protocol server for SQL over the stdio.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/daiguadaidai/parser/lsp"
+	"github.com/daiguadaidai/parser/mysql"
+	_ "github.com/daiguadaidai/parser/test_driver"
+)
+
+func main() {
+	sqlMode := flag.String("sql-mode", "", "the SQL mode of the parser, like ANSI_QUOTES")
+	flag.Parse()
+	mode, err := mysql.GetSQLMode(*sqlMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sqllsp: %v\n", err)
+		os.Exit(2)
+	}
+	if err := lsp.NewServer(mode).Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "sqllsp: %v\n", err)
+		os.Exit(1)
+	}
+}
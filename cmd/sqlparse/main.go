@@ -0,0 +1,347 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Sqlparse is a command line tool built on the parser. It reads the SQL
+// statements from the files or the stdin if there is no file.
+//
+// Usage:
+//
+//	sqlparse fmt [flags] [files...]     restore the statements
+//	sqlparse check [flags] [files...]   check the syntax
+//	sqlparse digest [flags] [files...]  print the digest and the normalized statements
+//	sqlparse ast [flags] [files...]     dump the AST as JSON or an indented tree
+//
+// The syntax errors are reported as file:line:col and the exit code is 1.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/mysql"
+	_ "github.com/daiguadaidai/parser/test_driver"
+)
+
+const (
+	exitOK = iota
+	exitSyntaxError
+	exitUsage
+)
+
+const usage = `Usage: sqlparse <command> [flags] [files...]
+
+The statements are read from the files, or the stdin if there is no file.
+
+Commands:
+  fmt     restore the statements
+  check   check the syntax
+  digest  print the digest and the normalized statements
+  ast     dump the AST as JSON or an indented tree
+
+Run 'sqlparse <command> -h' for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// input is a file to parse.
+type input struct {
+	name string
+	sql  string
+}
+
+// command holds the flags shared by the commands.
+type command struct {
+	flags   *flag.FlagSet
+	sqlMode string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	name := args[0]
+	cmd := &command{
+		flags:  flag.NewFlagSet("sqlparse "+name, flag.ContinueOnError),
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	cmd.flags.SetOutput(stderr)
+	cmd.flags.StringVar(&cmd.sqlMode, "sql-mode", "", "the SQL mode of the parser, like ANSI_QUOTES")
+
+	var exec func(inputs []input) int
+	switch name {
+	case "fmt":
+		exec = cmd.fmtFlags()
+	case "check":
+		exec = cmd.check
+	case "digest":
+		exec = cmd.digest
+	case "ast":
+		exec = cmd.astFlags()
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "sqlparse: unknown command %q\n\n%s", name, usage)
+		return exitUsage
+	}
+	if err := cmd.flags.Parse(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	inputs, err := cmd.readInputs()
+	if err != nil {
+		fmt.Fprintf(stderr, "sqlparse: %v\n", err)
+		return exitUsage
+	}
+	return exec(inputs)
+}
+
+func (c *command) readInputs() ([]input, error) {
+	if c.flags.NArg() == 0 {
+		data, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return nil, err
+		}
+		return []input{{name: "<stdin>", sql: string(data)}}, nil
+	}
+	inputs := make([]input, 0, c.flags.NArg())
+	for _, name := range c.flags.Args() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input{name: name, sql: string(data)})
+	}
+	return inputs, nil
+}
+
+// parse parses the input and reports the errors to stderr.
+func (c *command) parse(in input) ([]ast.StmtNode, bool) {
+	p := parser.New()
+	p.EnableWindowFunc(true)
+	if c.sqlMode != "" {
+		mode, err := mysql.GetSQLMode(c.sqlMode)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "sqlparse: %v\n", err)
+			return nil, false
+		}
+		p.SetSQLMode(mode)
+	}
+	stmts, _, err := p.Parse(in.sql, "", "")
+	if err != nil {
+		fmt.Fprintln(c.stderr, errorPos(in.name, err))
+		return nil, false
+	}
+	return stmts, true
+}
+
+var errPosPattern = regexp.MustCompile(`line (\d+) column (\d+)`)
+
+// errorPos formats the error as file:line:col: message.
+func errorPos(name string, err error) string {
+	msg := err.Error()
+	if m := errPosPattern.FindStringSubmatch(msg); m != nil {
+		msg = strings.TrimSpace(strings.Replace(msg, m[0], "", 1))
+		return fmt.Sprintf("%s:%s:%s: %s", name, m[1], m[2], msg)
+	}
+	return fmt.Sprintf("%s: %s", name, strings.TrimSpace(msg))
+}
+
+// forEach calls f for the statements of every input, it returns exitSyntaxError
+// if any input fails to parse or f fails.
+func (c *command) forEach(inputs []input, f func(in input, stmt ast.StmtNode) error) int {
+	code := exitOK
+	for _, in := range inputs {
+		stmts, ok := c.parse(in)
+		if !ok {
+			code = exitSyntaxError
+			continue
+		}
+		for _, stmt := range stmts {
+			if err := f(in, stmt); err != nil {
+				fmt.Fprintf(c.stderr, "%s: %v\n", in.name, err)
+				code = exitSyntaxError
+			}
+		}
+	}
+	return code
+}
+
+func (c *command) fmtFlags() func(inputs []input) int {
+	var (
+		pretty         = c.flags.Bool("pretty", false, "write a clause per line")
+		indent         = c.flags.String("indent", "  ", "the indent of the pretty format")
+		width          = c.flags.Int("width", 80, "the max line width of the pretty format, negative for no limit")
+		leadingComma   = c.flags.Bool("leading-comma", false, "write the commas at the beginning of the lines in the pretty format")
+		keyword        = c.flags.String("keyword", "upper", "the case of the keywords: upper, lower or keep")
+		nameQuote      = c.flags.String("name-quote", "backquote", "the quotes of the names: backquote, double or none")
+		stringQuote    = c.flags.String("string-quote", "single", "the quotes of the strings: single or double")
+		specialComment = c.flags.Bool("special-comment", false, "wrap the TiDB specific syntax in the special comments")
+	)
+	return func(inputs []input) int {
+		var flags format.RestoreFlags
+		switch *keyword {
+		case "upper":
+			flags |= format.RestoreKeyWordUppercase
+		case "lower":
+			flags |= format.RestoreKeyWordLowercase
+		case "keep":
+		default:
+			fmt.Fprintf(c.stderr, "sqlparse: invalid -keyword %q\n", *keyword)
+			return exitUsage
+		}
+		switch *nameQuote {
+		case "backquote":
+			flags |= format.RestoreNameBackQuotes
+		case "double":
+			flags |= format.RestoreNameDoubleQuotes
+		case "none":
+		default:
+			fmt.Fprintf(c.stderr, "sqlparse: invalid -name-quote %q\n", *nameQuote)
+			return exitUsage
+		}
+		switch *stringQuote {
+		case "single":
+			flags |= format.RestoreStringSingleQuotes
+		case "double":
+			flags |= format.RestoreStringDoubleQuotes
+		default:
+			fmt.Fprintf(c.stderr, "sqlparse: invalid -string-quote %q\n", *stringQuote)
+			return exitUsage
+		}
+		if *pretty {
+			flags |= format.RestorePrettyFormat
+		}
+		if *specialComment {
+			flags |= format.RestoreTiDBSpecialComment
+		}
+		return c.forEach(inputs, func(_ input, stmt ast.StmtNode) error {
+			var sb strings.Builder
+			ctx := format.NewRestoreCtx(flags, &sb)
+			ctx.Pretty = format.PrettyConfig{Indent: *indent, MaxWidth: *width, LeadingComma: *leadingComma}
+			if err := stmt.Restore(ctx); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.stdout, "%s;\n", sb.String())
+			return nil
+		})
+	}
+}
+
+func (c *command) check(inputs []input) int {
+	return c.forEach(inputs, func(input, ast.StmtNode) error {
+		return nil
+	})
+}
+
+func (c *command) digest(inputs []input) int {
+	return c.forEach(inputs, func(_ input, stmt ast.StmtNode) error {
+		// The text of a statement may end with the semicolon.
+		text := strings.TrimSuffix(strings.TrimSpace(stmt.Text()), ";")
+		normalized, digest := parser.NormalizeDigest(text)
+		fmt.Fprintf(c.stdout, "%s\t%s\n", digest.String(), normalized)
+		return nil
+	})
+}
+
+func (c *command) astFlags() func(inputs []input) int {
+	var (
+		output    = c.flags.String("format", "tree", "the output format: tree or json")
+		positions = c.flags.Bool("positions", false, "write the text and the offsets of the nodes in JSON")
+	)
+	return func(inputs []input) int {
+		switch *output {
+		case "tree":
+			return c.forEach(inputs, func(_ input, stmt ast.StmtNode) error {
+				return writeTree(c.stdout, stmt)
+			})
+		case "json":
+			var opts []ast.JSONOption
+			if *positions {
+				opts = append(opts, ast.JSONWithPositions)
+			}
+			return c.forEach(inputs, func(_ input, stmt ast.StmtNode) error {
+				data, err := ast.EncodeJSON(stmt, opts...)
+				if err != nil {
+					return err
+				}
+				var buf bytes.Buffer
+				if err := json.Indent(&buf, data, "", "  "); err != nil {
+					return err
+				}
+				buf.WriteByte('\n')
+				_, err = buf.WriteTo(c.stdout)
+				return err
+			})
+		default:
+			fmt.Fprintf(c.stderr, "sqlparse: invalid -format %q\n", *output)
+			return exitUsage
+		}
+	}
+}
+
+// writeTree writes a node per line indented by its depth, like
+//
+//	SelectStmt
+//	  Fields: FieldList
+//	    Fields[0]: SelectField
+//	      Expr: ColumnNameExpr `a`
+func writeTree(w io.Writer, root ast.Node) error {
+	var err error
+	ast.Walk(root, func(c *ast.Cursor) bool {
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat("  ", c.Depth()))
+		if name := c.Name(); name != "" {
+			sb.WriteString(name)
+			if c.Index() >= 0 {
+				fmt.Fprintf(&sb, "[%d]", c.Index())
+			}
+			sb.WriteString(": ")
+		}
+		tp := fmt.Sprintf("%T", c.Node())
+		sb.WriteString(tp[strings.LastIndexByte(tp, '.')+1:])
+		switch c.Node().(type) {
+		case *ast.ColumnNameExpr, *ast.TableName, ast.ValueExpr, ast.ParamMarkerExpr:
+			sb.WriteByte(' ')
+			ctx := format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)
+			if err = c.Node().Restore(ctx); err != nil {
+				return false
+			}
+		}
+		sb.WriteByte('\n')
+		_, err = io.WriteString(w, sb.String())
+		return err == nil
+	}, func(*ast.Cursor) bool {
+		return err == nil
+	})
+	return err
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runCmd(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestFmt(t *testing.T) {
+	code, out, _ := runCmd("select a, b from t where c = 1; select 1", "fmt")
+	require.Equal(t, exitOK, code)
+	require.Equal(t, "SELECT `a`,`b` FROM `t` WHERE `c`=1;\nSELECT 1;\n", out)
+
+	code, out, _ = runCmd("select a from t where c = 'x'", "fmt", "-pretty", "-keyword", "lower", "-name-quote", "none", "-string-quote", "double")
+	require.Equal(t, exitOK, code)
+	require.Equal(t, "select a\nfrom t\nwhere c=\"x\";\n", out)
+
+	code, _, errOut := runCmd("select 1", "fmt", "-keyword", "title")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, errOut, `invalid -keyword "title"`)
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.sql")
+	bad := filepath.Join(dir, "bad.sql")
+	require.NoError(t, os.WriteFile(good, []byte("select 1;\nselect 2;\n"), 0o644))
+	require.NoError(t, os.WriteFile(bad, []byte("select 1;\nselect a\nfrom t wher c = 1;\n"), 0o644))
+
+	code, out, errOut := runCmd("", "check", good)
+	require.Equal(t, exitOK, code)
+	require.Empty(t, out)
+	require.Empty(t, errOut)
+
+	code, _, errOut = runCmd("", "check", good, bad)
+	require.Equal(t, exitSyntaxError, code)
+	require.Equal(t, bad+":3:14: near \"c = 1;\n\"\n", errOut)
+
+	code, _, errOut = runCmd("", "check", filepath.Join(dir, "missing.sql"))
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, errOut, "missing.sql")
+}
+
+func TestDigest(t *testing.T) {
+	code, out, _ := runCmd("select a from t where c = 1; select a from t where c = 2", "digest")
+	require.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, lines[0], lines[1])
+	require.True(t, strings.HasSuffix(lines[0], "\tselect `a` from `t` where `c` = ?"), lines[0])
+}
+
+func TestAST(t *testing.T) {
+	code, out, _ := runCmd("insert into t values (1, ?)", "ast")
+	require.Equal(t, exitOK, code)
+	require.Equal(t, `InsertStmt
+  Table: TableRefsClause
+    TableRefs: Join
+      Left: TableSource
+        Source: TableName `+"`t`"+`
+  Lists[0][0]: ValueExpr 1
+  Lists[0][1]: ParamMarkerExpr ?
+`, out)
+
+	code, out, _ = runCmd("select a", "ast", "-format", "json", "-positions")
+	require.Equal(t, exitOK, code)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &doc))
+	require.Equal(t, "SelectStmt", doc["node"].(map[string]interface{})["type"])
+	require.Equal(t, "select a", doc["node"].(map[string]interface{})["text"])
+}
+
+func TestUsage(t *testing.T) {
+	code, _, errOut := runCmd("", "")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, errOut, "unknown command")
+	code, _, errOut = runCmd("")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, errOut, "Usage: sqlparse")
+	code, out, _ := runCmd("", "help")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, "Commands:")
+}
@@ -0,0 +1,512 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/pingcap/errors"
+)
+
+// CompletionContextKind is the kind of object expected at the cursor.
+type CompletionContextKind int
+
+// CompletionContextKind values.
+const (
+	// CompleteTableName means a table name is expected, Qualifier is the schema if any.
+	CompleteTableName CompletionContextKind = iota + 1
+	// CompleteColumnName means a column name is expected, Qualifier is the table or alias if any.
+	CompleteColumnName
+	// CompleteFunctionName means a function name is expected.
+	CompleteFunctionName
+	// CompleteSchemaName means a database name is expected.
+	CompleteSchemaName
+	// CompleteCharset means a character set name is expected.
+	CompleteCharset
+	// CompleteCollation means a collation name is expected.
+	CompleteCollation
+)
+
+// CompletionTable is a table referenced by the statement around the cursor.
+type CompletionTable struct {
+	Schema string
+	Name   string
+	Alias  string
+}
+
+// CompletionContext describes an object expected at the cursor.
+type CompletionContext struct {
+	Kind CompletionContextKind
+	// Qualifier is the identifier written before the '.' preceding the cursor,
+	// e.g. "t" for "SELECT t.|".
+	Qualifier string
+	// Table is the referenced table which Qualifier names or aliases, it is
+	// only set for CompleteColumnName.
+	Table *CompletionTable
+}
+
+// Completion is the result of Complete.
+type Completion struct {
+	// Prefix is the partially typed word before the cursor, candidates are
+	// already filtered by it.
+	Prefix string
+	// Keywords are the keywords acceptable at the cursor, in upper case.
+	Keywords []string
+	// Functions are the builtin function keywords acceptable at the cursor.
+	Functions []string
+	// TokenKinds are the token classes acceptable at the cursor, like
+	// TokenIdentifier, TokenString or TokenNumber.
+	TokenKinds []TokenKind
+	// Contexts are the semantic contexts at the cursor.
+	Contexts []CompletionContext
+	// Tables are the tables referenced by the statement around the cursor.
+	Tables []CompletionTable
+	// Charsets and Collations are the supported names matching Prefix, filled
+	// when a character set or collation is expected.
+	Charsets   []string
+	Collations []string
+	// CanEnd is true if the statement may end at the cursor.
+	CanEnd bool
+}
+
+// HasContext returns whether kind is one of the contexts at the cursor.
+func (c *Completion) HasContext(kind CompletionContextKind) bool {
+	for _, ctx := range c.Contexts {
+		if ctx.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type completionTables struct {
+	once sync.Once
+	// terminals are the symbol indices of all terminal tokens.
+	terminals []int
+	// tokenIDs maps a symbol index to its token id.
+	tokenIDs map[int]int
+	// keywords maps a token id to its keyword names.
+	keywords map[int][]string
+	// functions maps a token id to the builtin function names.
+	functions map[int][]string
+	// identKeywords are the keywords which can be used as identifiers.
+	identKeywords map[int]struct{}
+	// statementKeywords are the keywords which can begin a statement.
+	statementKeywords map[int]struct{}
+	// contextSymbols maps the symbol indices of some nonterminals to a context.
+	contextSymbols map[int]CompletionContextKind
+}
+
+var compTables completionTables
+
+func (t *completionTables) init() {
+	t.once.Do(func() {
+		t.tokenIDs = make(map[int]int, len(yyXLAT))
+		for tok, x := range yyXLAT {
+			if tok == yyErrCode {
+				continue
+			}
+			t.tokenIDs[x] = tok
+			t.terminals = append(t.terminals, x)
+		}
+		sort.Ints(t.terminals)
+
+		t.keywords = make(map[int][]string)
+		for name, tok := range tokenMap {
+			t.keywords[tok] = append(t.keywords[tok], name)
+		}
+		for name, tok := range windowFuncTokenMap {
+			t.keywords[tok] = append(t.keywords[tok], name)
+		}
+		t.keywords[asof] = []string{"AS OF"}
+		t.functions = make(map[int][]string)
+		for name, tok := range btFuncTokenMap {
+			t.functions[tok] = append(t.functions[tok], name)
+		}
+
+		t.contextSymbols = make(map[int]CompletionContextKind)
+		for i, name := range yySymNames {
+			switch name {
+			case "TableName":
+				t.contextSymbols[i] = CompleteTableName
+			case "ColumnName", "SimpleIdent":
+				t.contextSymbols[i] = CompleteColumnName
+			case "FunctionCallGeneric":
+				t.contextSymbols[i] = CompleteFunctionName
+			case "DBName":
+				t.contextSymbols[i] = CompleteSchemaName
+			case "CharsetName":
+				t.contextSymbols[i] = CompleteCharset
+			case "CollationName":
+				t.contextSymbols[i] = CompleteCollation
+			}
+		}
+
+		// "USE" is only followed by a database name, so the keywords acceptable
+		// after it are exactly the keywords which can be used as identifiers.
+		t.identKeywords = make(map[int]struct{})
+		if stack, ok := lrFeed([]int{0}, yyXLAT[use]); ok {
+			for _, x := range t.acceptable(stack) {
+				t.identKeywords[t.tokenIDs[x]] = struct{}{}
+			}
+		}
+
+		t.statementKeywords = make(map[int]struct{})
+		for _, x := range t.acceptable([]int{0}) {
+			t.statementKeywords[t.tokenIDs[x]] = struct{}{}
+		}
+	})
+}
+
+// lrAction returns the action of state on symbol x: a positive value is the
+// state to shift to, a negative value is the rule to reduce by and 0 is an error.
+func lrAction(state, x int) int {
+	row := yyParseTab[state]
+	if x >= len(row) || row[x] == 0 {
+		return 0
+	}
+	return int(row[x]) + yyTabOfs
+}
+
+// lrFeed runs the LALR automaton on the terminal symbol x, it returns false if
+// x is not acceptable. The returned stack may share memory with stack.
+func lrFeed(stack []int, x int) ([]int, bool) {
+	for {
+		state := stack[len(stack)-1]
+		act := lrAction(state, x)
+		switch {
+		case act > 0:
+			return append(stack, act), true
+		case act < 0:
+			r := yyReductions[-act]
+			stack = stack[:len(stack)-r.components]
+			stack = append(stack, int(yyParseTab[stack[len(stack)-1]][r.xsym])+yyTabOfs)
+		default:
+			return stack, state == 1 && x == yyXLAT[yyEOFCode]
+		}
+	}
+}
+
+// acceptable returns the terminal symbols which can follow stack.
+func (t *completionTables) acceptable(stack []int) []int {
+	var (
+		res     []int
+		scratch []int
+	)
+	top := stack[len(stack)-1]
+	for _, x := range t.terminals {
+		if lrAction(top, x) == 0 {
+			continue
+		}
+		scratch = append(scratch[:0], stack...)
+		if _, ok := lrFeed(scratch, x); ok {
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+// contexts returns the nonterminals expected at the cursor, which are found
+// in the state shifting an identifier.
+func (t *completionTables) contexts(stack []int) []CompletionContextKind {
+	stack = append([]int(nil), stack...)
+	x := yyXLAT[identifier]
+	for {
+		state := stack[len(stack)-1]
+		act := lrAction(state, x)
+		if act >= 0 {
+			break
+		}
+		r := yyReductions[-act]
+		stack = stack[:len(stack)-r.components]
+		stack = append(stack, int(yyParseTab[stack[len(stack)-1]][r.xsym])+yyTabOfs)
+	}
+	row := yyParseTab[stack[len(stack)-1]]
+	var kinds []CompletionContextKind
+	for sym, kind := range t.contextSymbols {
+		if sym < len(row) && row[sym] != 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
+
+// Complete returns the completion candidates for the cursor at the byte offset
+// of sql. It runs the parser automaton on the tokens before the cursor, so the
+// SQL mode of the parser is honored. An error is returned if the text before
+// the cursor is not a valid SQL prefix.
+func (parser *Parser) Complete(sql string, offset int, params ...ParseParam) (*Completion, error) {
+	if offset < 0 || offset > len(sql) {
+		return nil, errors.Errorf("offset %d out of range [0, %d]", offset, len(sql))
+	}
+	compTables.init()
+
+	wordStart := offset
+	for wordStart > 0 && isIdentChar(sql[wordStart-1]) {
+		wordStart--
+	}
+	res := &Completion{Prefix: sql[wordStart:offset]}
+
+	resetParams(parser)
+	parser.lexer.reset(sql[:wordStart])
+	for _, p := range params {
+		if err := p.ApplyOn(parser); err != nil {
+			return nil, err
+		}
+	}
+	tokens, err := parser.completionTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	// A dot before the cursor means a qualified name, so the contexts are
+	// computed at the start of the name.
+	var qualifiers []string
+	nameStart := len(tokens)
+	for nameStart >= 2 && tokens[nameStart-1].ID == int('.') && isIdentToken(&tokens[nameStart-2]) {
+		qualifiers = append([]string{tokens[nameStart-2].Value.(string)}, qualifiers...)
+		nameStart -= 2
+	}
+
+	stack := []int{0}
+	for i, tok := range tokens {
+		if i == nameStart {
+			for _, kind := range compTables.contexts(stack) {
+				res.Contexts = append(res.Contexts, CompletionContext{Kind: kind})
+			}
+		}
+		x, ok := yyXLAT[tok.ID]
+		if !ok {
+			x = len(yySymNames)
+		}
+		if stack, ok = lrFeed(stack, x); !ok {
+			parser.lexer.r.updatePos(tok.Start)
+			parser.lexer.lastScanOffset = tok.Start.Offset
+			return nil, errors.Trace(parser.lexer.Errorf(""))
+		}
+	}
+	if nameStart == len(tokens) {
+		for _, kind := range compTables.contexts(stack) {
+			res.Contexts = append(res.Contexts, CompletionContext{Kind: kind})
+		}
+	}
+
+	res.Tables = parser.referencedTables(sql, wordStart)
+	res.Contexts = qualifyContexts(res.Contexts, qualifiers, res.Tables)
+
+	identAcceptable := false
+	kinds := make(map[TokenKind]struct{})
+	for _, x := range compTables.acceptable(stack) {
+		tok := compTables.tokenIDs[x]
+		if kind, ok := completionTokenKinds[tok]; ok {
+			kinds[kind] = struct{}{}
+			identAcceptable = identAcceptable || tok == identifier
+		}
+		if tok == yyEOFCode || tok == int(';') {
+			res.CanEnd = true
+		}
+	}
+	for _, x := range compTables.acceptable(stack) {
+		tok := compTables.tokenIDs[x]
+		if _, ok := compTables.identKeywords[tok]; ok && identAcceptable {
+			continue
+		}
+		for _, name := range compTables.keywords[tok] {
+			if hasPrefixFold(name, res.Prefix) {
+				res.Keywords = append(res.Keywords, name)
+			}
+		}
+		for _, name := range compTables.functions[tok] {
+			if hasPrefixFold(name, res.Prefix) {
+				res.Functions = append(res.Functions, name)
+			}
+		}
+	}
+	sort.Strings(res.Keywords)
+	sort.Strings(res.Functions)
+	for kind := range kinds {
+		res.TokenKinds = append(res.TokenKinds, kind)
+	}
+	sort.Slice(res.TokenKinds, func(i, j int) bool { return res.TokenKinds[i] < res.TokenKinds[j] })
+
+	if res.HasContext(CompleteCharset) {
+		for _, cs := range charset.GetSupportedCharsets() {
+			if hasPrefixFold(cs.Name, res.Prefix) {
+				res.Charsets = append(res.Charsets, cs.Name)
+			}
+		}
+		sort.Strings(res.Charsets)
+	}
+	if res.HasContext(CompleteCollation) {
+		for _, co := range charset.GetSupportedCollations() {
+			if hasPrefixFold(co.Name, res.Prefix) {
+				res.Collations = append(res.Collations, co.Name)
+			}
+		}
+		sort.Strings(res.Collations)
+	}
+	return res, nil
+}
+
+var completionTokenKinds = map[int]TokenKind{
+	identifier:         TokenIdentifier,
+	stringLit:          TokenString,
+	intLit:             TokenNumber,
+	decLit:             TokenNumber,
+	floatLit:           TokenNumber,
+	hexLit:             TokenHexLiteral,
+	bitLit:             TokenBitLiteral,
+	underscoreCS:       TokenCharsetIntroducer,
+	singleAtIdentifier: TokenVariable,
+	doubleAtIdentifier: TokenVariable,
+	paramMarker:        TokenParamMarker,
+}
+
+// completionTokens scans all the tokens with the lexer of the parser.
+func (parser *Parser) completionTokens() ([]Token, error) {
+	l := &parser.lexer
+	var tokens []Token
+	for {
+		tok := l.NextToken()
+		if tok.Kind == TokenEOF {
+			break
+		}
+		if tok.Kind == TokenInvalid {
+			l.lastScanOffset = tok.Start.Offset
+			return nil, errors.Trace(l.Errorf(""))
+		}
+		tokens = append(tokens, tok)
+	}
+	if _, errs := l.Errors(); len(errs) > 0 {
+		return nil, errors.Trace(errs[0])
+	}
+	return tokens, nil
+}
+
+// referencedTables collects the tables referenced by the statement around
+// offset. It only looks at the tokens, so it also works for incomplete SQL.
+func (parser *Parser) referencedTables(sql string, offset int) []CompletionTable {
+	l := NewScanner(sql)
+	l.SetSQLMode(parser.lexer.GetSQLMode())
+	l.EnableWindowFunc(parser.lexer.supportWindowFunc)
+	var stmt []Token
+	for {
+		tok := l.NextToken()
+		if tok.Kind == TokenEOF || tok.Kind == TokenInvalid {
+			break
+		}
+		if tok.ID == int(';') {
+			if tok.Start.Offset >= offset {
+				break
+			}
+			stmt = stmt[:0]
+			continue
+		}
+		stmt = append(stmt, tok)
+	}
+
+	var tables []CompletionTable
+	for i := 0; i < len(stmt); i++ {
+		switch stmt[i].ID {
+		case from, join, update, into, straightJoin:
+		default:
+			continue
+		}
+		for i+1 < len(stmt) && isIdentToken(&stmt[i+1]) {
+			i++
+			tbl := CompletionTable{Name: stmt[i].Value.(string)}
+			if i+2 < len(stmt) && stmt[i+1].ID == int('.') && isIdentToken(&stmt[i+2]) {
+				tbl.Schema, tbl.Name = tbl.Name, stmt[i+2].Value.(string)
+				i += 2
+			}
+			if i+2 < len(stmt) && stmt[i+1].ID == as && isIdentToken(&stmt[i+2]) {
+				tbl.Alias = stmt[i+2].Value.(string)
+				i += 2
+			} else if i+1 < len(stmt) && stmt[i+1].Kind != TokenKeyword && isIdentToken(&stmt[i+1]) {
+				tbl.Alias = stmt[i+1].Value.(string)
+				i++
+			}
+			tables = append(tables, tbl)
+			if i+1 >= len(stmt) || stmt[i+1].ID != int(',') {
+				break
+			}
+			i++
+		}
+	}
+	return tables
+}
+
+func qualifyContexts(contexts []CompletionContext, qualifiers []string, tables []CompletionTable) []CompletionContext {
+	if len(qualifiers) == 0 {
+		return contexts
+	}
+	var res []CompletionContext
+	for _, ctx := range contexts {
+		switch ctx.Kind {
+		case CompleteTableName:
+			if len(qualifiers) != 1 {
+				continue
+			}
+			ctx.Qualifier = qualifiers[0]
+		case CompleteColumnName:
+			if len(qualifiers) > 2 {
+				continue
+			}
+			ctx.Qualifier = qualifiers[len(qualifiers)-1]
+			for i := range tables {
+				tbl := &tables[i]
+				if len(qualifiers) == 2 && !strings.EqualFold(tbl.Schema, qualifiers[0]) {
+					continue
+				}
+				if strings.EqualFold(tbl.Alias, ctx.Qualifier) ||
+					tbl.Alias == "" && strings.EqualFold(tbl.Name, ctx.Qualifier) {
+					ctx.Table = tbl
+					break
+				}
+			}
+			if len(qualifiers) == 2 {
+				ctx.Qualifier = qualifiers[0] + "." + qualifiers[1]
+			}
+		case CompleteFunctionName:
+			if len(qualifiers) != 1 {
+				continue
+			}
+			ctx.Qualifier = qualifiers[0]
+		default:
+			continue
+		}
+		res = append(res, ctx)
+	}
+	return res
+}
+
+// isIdentToken returns whether the token can be used as an identifier.
+func isIdentToken(tok *Token) bool {
+	switch tok.Kind {
+	case TokenIdentifier, TokenQuotedIdentifier:
+		return true
+	case TokenKeyword:
+		_, ok := compTables.identKeywords[tok.ID]
+		return ok
+	}
+	return false
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
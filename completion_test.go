@@ -0,0 +1,111 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"testing"
+
+	requires "github.com/stretchr/testify/require"
+)
+
+func TestCompleteKeywords(t *testing.T) {
+	p := New()
+
+	c, err := p.Complete("SEL", 3)
+	requires.NoError(t, err)
+	requires.Equal(t, "SEL", c.Prefix)
+	requires.Equal(t, []string{"SELECT"}, c.Keywords)
+
+	c, err = p.Complete("SELECT * FROM t ", 16)
+	requires.NoError(t, err)
+	requires.Contains(t, c.Keywords, "WHERE")
+	requires.Contains(t, c.Keywords, "JOIN")
+	requires.Contains(t, c.Keywords, "ORDER")
+	requires.NotContains(t, c.Keywords, "SELECT")
+	requires.True(t, c.CanEnd)
+
+	// Unreserved keywords are not suggested where an identifier is expected.
+	c, err = p.Complete("USE ", 4)
+	requires.NoError(t, err)
+	requires.Len(t, c.Keywords, 0)
+	requires.Equal(t, []TokenKind{TokenIdentifier}, c.TokenKinds)
+	requires.True(t, c.HasContext(CompleteSchemaName))
+	requires.False(t, c.CanEnd)
+
+	// The cursor may be in the middle of the text.
+	c, err = p.Complete("SELECT * FR t", 11)
+	requires.NoError(t, err)
+	requires.Equal(t, "FR", c.Prefix)
+	requires.Equal(t, []string{"FROM"}, c.Keywords)
+
+	c, err = p.Complete("SELECT COU", 10)
+	requires.NoError(t, err)
+	requires.Contains(t, c.Functions, "COUNT")
+}
+
+func TestCompleteContexts(t *testing.T) {
+	p := New()
+
+	c, err := p.Complete("SELECT * FROM ", 14)
+	requires.NoError(t, err)
+	requires.True(t, c.HasContext(CompleteTableName))
+	requires.False(t, c.HasContext(CompleteColumnName))
+
+	c, err = p.Complete("SELECT * FROM db.", 17)
+	requires.NoError(t, err)
+	requires.Equal(t, []CompletionContext{{Kind: CompleteTableName, Qualifier: "db"}}, c.Contexts)
+
+	c, err = p.Complete("SELECT a FROM t WHERE ", 22)
+	requires.NoError(t, err)
+	requires.True(t, c.HasContext(CompleteColumnName))
+	requires.True(t, c.HasContext(CompleteFunctionName))
+	requires.Equal(t, []CompletionTable{{Name: "t"}}, c.Tables)
+	requires.Contains(t, c.TokenKinds, TokenNumber)
+	requires.Contains(t, c.TokenKinds, TokenString)
+
+	sql := "SELECT x. FROM db.t1 AS x JOIN t2 y ON x.id = y.id"
+	c, err = p.Complete(sql, 9)
+	requires.NoError(t, err)
+	requires.Equal(t, []CompletionTable{{Schema: "db", Name: "t1", Alias: "x"}, {Name: "t2", Alias: "y"}}, c.Tables)
+	requires.True(t, c.HasContext(CompleteColumnName))
+	for _, ctx := range c.Contexts {
+		if ctx.Kind == CompleteColumnName {
+			requires.Equal(t, "x", ctx.Qualifier)
+			requires.Equal(t, &CompletionTable{Schema: "db", Name: "t1", Alias: "x"}, ctx.Table)
+		}
+	}
+
+	c, err = p.Complete("CREATE TABLE t (a int) CHARSET utf", 34)
+	requires.NoError(t, err)
+	requires.True(t, c.HasContext(CompleteCharset))
+	requires.Equal(t, []string{"utf8", "utf8mb4"}, c.Charsets)
+
+	c, err = p.Complete("SELECT a COLLATE utf8mb4_b", 26)
+	requires.NoError(t, err)
+	requires.True(t, c.HasContext(CompleteCollation))
+	requires.Equal(t, []string{"utf8mb4_bin"}, c.Collations)
+
+	// Only the statement containing the cursor is considered.
+	c, err = p.Complete("SELECT * FROM t1; SELECT * FROM t2 WHERE ", 41)
+	requires.NoError(t, err)
+	requires.Equal(t, []CompletionTable{{Name: "t2"}}, c.Tables)
+}
+
+func TestCompleteError(t *testing.T) {
+	p := New()
+	_, err := p.Complete("SELECT FROM FROM ", 17)
+	requires.Error(t, err)
+	_, err = p.Complete("SELECT", 7)
+	requires.Error(t, err)
+}
@@ -57,6 +57,24 @@ func TestKeywordConsistent(t *testing.T) {
 
 	tidbKeywordsCollectionDef := extractKeywordsFromCollectionDef(content, "\nTiDBKeyword:")
 	requires.Equal(t, tidbKeywordsCollectionDef, tidbKeywords)
+
+	// reservedKeywords is generated by keywordgen, run go generate if it is
+	// out of date.
+	reservedTokens := make(map[int]struct{})
+	for _, kw := range reservedKeywords {
+		tok, ok := tokenMap[kw]
+		if !ok {
+			tok, ok = windowFuncTokenMap[kw]
+		}
+		requires.True(t, ok, kw)
+		reservedTokens[tok] = struct{}{}
+	}
+	for _, m := range []map[string]int{tokenMap, windowFuncTokenMap} {
+		for kw, tok := range m {
+			_, ok := reservedTokens[tok]
+			requires.Equal(t, ok, IsReservedKeyword(kw), kw)
+		}
+	}
 }
 
 func extractMiddle(str, startMarker, endMarker string) string {
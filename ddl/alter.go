@@ -0,0 +1,855 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+)
+
+// alter applies a spec of ALTER TABLE to the table. The specs which change no
+// schema, like LOCK and ALGORITHM, are ignored.
+func (tb *tableBuilder) alter(spec *ast.AlterTableSpec) error {
+	var err error
+	switch spec.Tp {
+	case ast.AlterTableOption:
+		err = tb.alterTableOptions(spec.Options)
+	case ast.AlterTableAddColumns:
+		err = tb.addColumns(spec)
+	case ast.AlterTableDropColumn:
+		err = tb.dropColumn(spec.OldColumnName.Name, spec.IfExists)
+	case ast.AlterTableModifyColumn, ast.AlterTableChangeColumn:
+		err = tb.changeColumn(spec)
+	case ast.AlterTableRenameColumn:
+		err = tb.renameColumn(spec.OldColumnName.Name, spec.NewColumnName.Name)
+	case ast.AlterTableAlterColumn:
+		err = tb.alterColumnDefault(spec.NewColumns[0])
+	case ast.AlterTableAddConstraint:
+		err = tb.addConstraint(spec.Constraint, spec.IfNotExists)
+	case ast.AlterTableDropPrimaryKey:
+		err = tb.dropPrimaryKey()
+	case ast.AlterTableDropIndex:
+		err = tb.dropIndex(spec.Name, spec.IfExists)
+	case ast.AlterTableDropForeignKey:
+		err = tb.dropForeignKey(spec.Name, spec.IfExists)
+	case ast.AlterTableRenameIndex:
+		err = tb.renameIndex(spec.FromKey, spec.ToKey)
+	case ast.AlterTableIndexInvisible:
+		err = tb.setIndexVisibility(spec.IndexName, spec.Visibility)
+	case ast.AlterTableAlterCheck:
+		err = tb.alterCheck(spec.Constraint.Name, spec.Constraint.Enforced)
+	case ast.AlterTableDropCheck:
+		err = tb.dropCheck(spec.Constraint.Name)
+	case ast.AlterTableAddPartitions:
+		err = tb.addPartitions(spec)
+	case ast.AlterTableDropPartition:
+		err = tb.dropPartitions(spec.PartitionNames, spec.IfExists)
+	case ast.AlterTableTruncatePartition:
+		err = tb.truncatePartitions(spec.PartitionNames, spec.OnAllPartitions)
+	case ast.AlterTableCoalescePartitions:
+		err = tb.coalescePartitions(spec.Num)
+	case ast.AlterTablePartition:
+		err = tb.buildPartitionInfo(spec.Partition)
+	case ast.AlterTableRemovePartitioning:
+		if tb.tbl.Partition == nil {
+			return ErrPartitionMgmtOnNonpartitioned.GenWithStackByArgs()
+		}
+		tb.tbl.Partition = nil
+	}
+	if err != nil {
+		return err
+	}
+	tb.resetKeyFlags()
+	return nil
+}
+
+// checkTable checks the table after all the specs of ALTER TABLE, which may
+// depend on each other like an auto increment column and its index.
+func (tb *tableBuilder) checkTable() error {
+	if len(tb.tbl.Columns) > maxColumnCount {
+		return ErrTooManyFields.GenWithStackByArgs()
+	}
+	if len(tb.tbl.Indices) > maxIndexCount {
+		return ErrTooManyKeys.GenWithStackByArgs(maxIndexCount)
+	}
+	if err := tb.checkGeneratedColumns(); err != nil {
+		return err
+	}
+	if err := tb.checkAutoIncrement(); err != nil {
+		return err
+	}
+	return tb.checkUniqueKeysInPartition(tb.partitionColumns())
+}
+
+// alterTableOptions sets the table options, the charset and the collation are
+// kept if the options have neither.
+func (tb *tableBuilder) alterTableOptions(options []*ast.TableOption) error {
+	cs, co := tb.tbl.Charset, tb.tbl.Collate
+	if err := tb.setTableOptions(options); err != nil {
+		return err
+	}
+	for _, op := range options {
+		if op.Tp == ast.TableOptionCharset || op.Tp == ast.TableOptionCollate {
+			return nil
+		}
+	}
+	tb.tbl.Charset, tb.tbl.Collate = cs, co
+	return nil
+}
+
+// addColumns adds the columns of ADD COLUMN.
+func (tb *tableBuilder) addColumns(spec *ast.AlterTableSpec) error {
+	var constraints []*ast.Constraint
+	for _, def := range spec.NewColumns {
+		if spec.IfNotExists && model.FindColumnInfo(tb.tbl.Columns, def.Name.Name.L) != nil {
+			continue
+		}
+		col, cs, err := tb.buildColumn(def)
+		if err != nil {
+			return err
+		}
+		if err := tb.addColumn(col); err != nil {
+			return err
+		}
+		if len(spec.NewColumns) == 1 {
+			if err := tb.positionColumn(col, spec.Position); err != nil {
+				return err
+			}
+		}
+		tb.setNoDefaultValueFlag(col)
+		constraints = append(constraints, cs...)
+	}
+	return tb.buildConstraints(append(constraints, spec.NewConstraints...))
+}
+
+// positionColumn moves a column to the position of FIRST or AFTER.
+func (tb *tableBuilder) positionColumn(col *model.ColumnInfo, pos *ast.ColumnPosition) error {
+	if pos == nil || pos.Tp == ast.ColumnPositionNone {
+		return nil
+	}
+	cols := make([]*model.ColumnInfo, 0, len(tb.tbl.Columns))
+	for _, c := range tb.tbl.Columns {
+		if c != col {
+			cols = append(cols, c)
+		}
+	}
+	at := 0
+	if pos.Tp == ast.ColumnPositionAfter {
+		at = -1
+		for i, c := range cols {
+			if c.Name.L == pos.RelativeColumn.Name.L {
+				at = i + 1
+				break
+			}
+		}
+		if at < 0 {
+			return ErrBadField.GenWithStackByArgs(pos.RelativeColumn.Name.O, tb.tbl.Name.O)
+		}
+	}
+	cols = append(cols[:at], append([]*model.ColumnInfo{col}, cols[at:]...)...)
+	tb.tbl.Columns = cols
+	tb.resetOffsets()
+	return nil
+}
+
+// resetOffsets sets the offsets of the columns and the index columns after
+// the columns are moved or dropped.
+func (tb *tableBuilder) resetOffsets() {
+	for i, col := range tb.tbl.Columns {
+		col.Offset = i
+	}
+	for _, idx := range tb.tbl.Indices {
+		for _, ic := range idx.Columns {
+			ic.Offset = model.FindColumnInfo(tb.tbl.Columns, ic.Name.L).Offset
+		}
+	}
+}
+
+// resetKeyFlags sets the key flags of the columns from the primary key and
+// the indexes.
+func (tb *tableBuilder) resetKeyFlags() {
+	var handle *model.ColumnInfo
+	if tb.tbl.PKIsHandle {
+		handle = tb.tbl.GetPkColInfo()
+	}
+	for _, col := range tb.tbl.Columns {
+		col.DelFlag(mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag)
+	}
+	if handle != nil {
+		handle.AddFlag(mysql.PriKeyFlag)
+	}
+	for _, idx := range tb.tbl.Indices {
+		if idx.Primary {
+			for _, ic := range idx.Columns {
+				tb.tbl.Columns[ic.Offset].AddFlag(mysql.PriKeyFlag)
+			}
+			continue
+		}
+		first := tb.tbl.Columns[idx.Columns[0].Offset]
+		if idx.Unique && len(idx.Columns) == 1 {
+			first.AddFlag(mysql.UniqueKeyFlag)
+		} else {
+			first.AddFlag(mysql.MultipleKeyFlag)
+		}
+	}
+}
+
+// checkColumnDependents checks that no generated column, functional index,
+// check constraint of several columns or partitioning refers to the column
+// to drop or rename.
+func (tb *tableBuilder) checkColumnDependents(col *model.ColumnInfo, rename bool) error {
+	for _, other := range tb.tbl.Columns {
+		if _, ok := other.Dependences[col.Name.L]; !ok || other == col {
+			continue
+		}
+		if other.Hidden {
+			return ErrDependentByFunctionalIndex.GenWithStackByArgs(col.Name.O)
+		}
+		return ErrDependentByGeneratedColumn.GenWithStackByArgs(col.Name.O)
+	}
+	for _, c := range tb.tbl.Constraints {
+		for _, name := range c.ConstraintCols {
+			if name.L == col.Name.L && (rename || len(c.ConstraintCols) > 1) {
+				return ErrDependentByCheckConstraint.GenWithStackByArgs(c.Name.O, col.Name.O)
+			}
+		}
+	}
+	for _, name := range tb.partitionColumns() {
+		if name == col.Name.L {
+			return ErrDependentByPartitionFunctional.GenWithStackByArgs(col.Name.O)
+		}
+	}
+	return nil
+}
+
+// dropColumn drops a column and removes it from the indexes, an index of no
+// column left is dropped. The check constraints of the column are dropped.
+func (tb *tableBuilder) dropColumn(name model.CIStr, ifExists bool) error {
+	col := model.FindColumnInfo(tb.tbl.Columns, name.L)
+	if col == nil || col.Hidden {
+		if ifExists {
+			return nil
+		}
+		return ErrCantDropFieldOrKey.GenWithStackByArgs(name.O)
+	}
+	visible := 0
+	for _, c := range tb.tbl.Columns {
+		if !c.Hidden {
+			visible++
+		}
+	}
+	if visible == 1 {
+		return ErrCantRemoveAllFields.GenWithStackByArgs()
+	}
+	if err := tb.checkColumnDependents(col, false); err != nil {
+		return err
+	}
+	for _, fk := range tb.tbl.ForeignKeys {
+		for _, c := range fk.Cols {
+			if c.L == col.Name.L {
+				return ErrFkColumnCannotDrop.GenWithStackByArgs(col.Name.O, fk.Name.O)
+			}
+		}
+	}
+
+	constraints := tb.tbl.Constraints[:0:0]
+	for _, c := range tb.tbl.Constraints {
+		if len(c.ConstraintCols) != 1 || c.ConstraintCols[0].L != col.Name.L {
+			constraints = append(constraints, c)
+		}
+	}
+	tb.tbl.Constraints = constraints
+	indices := tb.tbl.Indices[:0:0]
+	for _, idx := range tb.tbl.Indices {
+		columns := idx.Columns[:0:0]
+		for _, ic := range idx.Columns {
+			if ic.Name.L != col.Name.L {
+				columns = append(columns, ic)
+			}
+		}
+		idx.Columns = columns
+		if len(columns) > 0 {
+			indices = append(indices, idx)
+		}
+	}
+	tb.tbl.Indices = indices
+	if tb.tbl.PKIsHandle && mysql.HasPriKeyFlag(col.GetFlag()) {
+		tb.tbl.PKIsHandle = false
+	}
+	tb.removeColumns(col)
+	return nil
+}
+
+// removeColumns removes the columns from the table.
+func (tb *tableBuilder) removeColumns(cols ...*model.ColumnInfo) {
+	columns := tb.tbl.Columns[:0:0]
+	for _, c := range tb.tbl.Columns {
+		removed := false
+		for _, col := range cols {
+			removed = removed || c == col
+		}
+		if !removed {
+			columns = append(columns, c)
+		}
+	}
+	tb.tbl.Columns = columns
+	tb.resetOffsets()
+}
+
+// changeColumn replaces a column with the definition of MODIFY COLUMN or
+// CHANGE COLUMN, the column keeps its ID and its indexes.
+func (tb *tableBuilder) changeColumn(spec *ast.AlterTableSpec) error {
+	def := spec.NewColumns[0]
+	oldName := def.Name.Name
+	if spec.Tp == ast.AlterTableChangeColumn {
+		oldName = spec.OldColumnName.Name
+	}
+	col := model.FindColumnInfo(tb.tbl.Columns, oldName.L)
+	if col == nil || col.Hidden {
+		if spec.IfExists {
+			return nil
+		}
+		return ErrBadField.GenWithStackByArgs(oldName.O, tb.tbl.Name.O)
+	}
+	newName := def.Name.Name
+	if newName.L != col.Name.L && model.FindColumnInfo(tb.tbl.Columns, newName.L) != nil {
+		return ErrDupFieldName.GenWithStackByArgs(newName.O)
+	}
+	for _, fk := range tb.tbl.ForeignKeys {
+		for _, c := range fk.Cols {
+			if c.L == col.Name.L && !sameColumnType(col, def) {
+				return ErrFkColumnCannotChange.GenWithStackByArgs(col.Name.O, fk.Name.O)
+			}
+		}
+	}
+
+	newCol, constraints, err := tb.buildColumn(def)
+	if err != nil {
+		return err
+	}
+	if newName.L != col.Name.L {
+		if err := tb.renameColumn(col.Name, newName); err != nil {
+			return err
+		}
+	}
+	newCol.ID = col.ID
+	newCol.Offset = col.Offset
+	newCol.AddFlag(col.GetFlag() & (mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag))
+	if tb.isPrimaryKeyColumn(col) {
+		if tb.nullable[newName.L] {
+			return ErrPrimaryCantHaveNull.GenWithStackByArgs()
+		}
+		newCol.AddFlag(mysql.NotNullFlag)
+	}
+	tb.tbl.Columns[col.Offset] = newCol
+	if err := tb.positionColumn(newCol, spec.Position); err != nil {
+		return err
+	}
+	if err := tb.checkIndexLengths(newCol); err != nil {
+		return err
+	}
+	tb.setNoDefaultValueFlag(newCol)
+	return tb.buildConstraints(constraints)
+}
+
+// sameColumnType returns whether a column definition has the type of the
+// column, the type of a foreign key column cannot be changed.
+func sameColumnType(col *model.ColumnInfo, def *ast.ColumnDef) bool {
+	return col.GetType() == def.Tp.GetType() &&
+		mysql.HasUnsignedFlag(col.GetFlag()) == mysql.HasUnsignedFlag(def.Tp.GetFlag())
+}
+
+// isPrimaryKeyColumn returns whether a column is a column of the primary key.
+func (tb *tableBuilder) isPrimaryKeyColumn(col *model.ColumnInfo) bool {
+	if tb.tbl.PKIsHandle {
+		return mysql.HasPriKeyFlag(col.GetFlag())
+	}
+	for _, idx := range tb.tbl.Indices {
+		if !idx.Primary {
+			continue
+		}
+		for _, ic := range idx.Columns {
+			if ic.Name.L == col.Name.L {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkIndexLengths checks the indexes of a changed column.
+func (tb *tableBuilder) checkIndexLengths(col *model.ColumnInfo) error {
+	for _, idx := range tb.tbl.Indices {
+		var keyLength int
+		var found bool
+		for _, ic := range idx.Columns {
+			c := tb.tbl.Columns[ic.Offset]
+			found = found || c == col
+			length, err := indexColumnLength(c, ic.Length)
+			if err != nil {
+				return err
+			}
+			keyLength += length
+		}
+		if found && keyLength > maxKeyLength {
+			return ErrTooLongKey.GenWithStackByArgs(maxKeyLength)
+		}
+	}
+	return nil
+}
+
+// renameColumn renames a column and the references of the indexes, the
+// foreign keys and the partitioning.
+func (tb *tableBuilder) renameColumn(oldName, newName model.CIStr) error {
+	col := model.FindColumnInfo(tb.tbl.Columns, oldName.L)
+	if col == nil || col.Hidden {
+		return ErrBadField.GenWithStackByArgs(oldName.O, tb.tbl.Name.O)
+	}
+	if err := checkColumnName(newName.O); err != nil {
+		return err
+	}
+	if newName.L != col.Name.L && model.FindColumnInfo(tb.tbl.Columns, newName.L) != nil {
+		return ErrDupFieldName.GenWithStackByArgs(newName.O)
+	}
+	if err := tb.checkColumnDependents(col, true); err != nil {
+		return err
+	}
+	for _, idx := range tb.tbl.Indices {
+		for _, ic := range idx.Columns {
+			if ic.Name.L == col.Name.L {
+				ic.Name = newName
+			}
+		}
+	}
+	for _, fk := range tb.tbl.ForeignKeys {
+		for i, c := range fk.Cols {
+			if c.L == col.Name.L {
+				fk.Cols[i] = newName
+			}
+		}
+	}
+	col.Name = newName
+	return nil
+}
+
+// alterColumnDefault sets or drops the default value of ALTER COLUMN.
+func (tb *tableBuilder) alterColumnDefault(def *ast.ColumnDef) error {
+	col := model.FindColumnInfo(tb.tbl.Columns, def.Name.Name.L)
+	if col == nil || col.Hidden {
+		return ErrBadField.GenWithStackByArgs(def.Name.Name.O, tb.tbl.Name.O)
+	}
+	if col.IsGenerated() {
+		return ErrUnsupportedOnGeneratedColumn.GenWithStackByArgs("DEFAULT")
+	}
+	col.DefaultIsExpr = false
+	if len(def.Options) == 0 {
+		// DROP DEFAULT.
+		if err := col.SetDefaultValue(nil); err != nil {
+			return err
+		}
+		tb.setNoDefaultValueFlag(col)
+		return nil
+	}
+	if err := tb.setDefaultValue(col, def.Options[0].Expr); err != nil {
+		return err
+	}
+	if col.GetDefaultValue() == nil && !col.DefaultIsExpr && mysql.HasNotNullFlag(col.GetFlag()) {
+		return ErrInvalidDefault.GenWithStackByArgs(col.Name.O)
+	}
+	col.DelFlag(mysql.NoDefaultValueFlag)
+	return nil
+}
+
+// addConstraint adds an index, a foreign key or a check constraint.
+func (tb *tableBuilder) addConstraint(c *ast.Constraint, ifNotExists bool) error {
+	if ifNotExists && c.Name != "" {
+		switch c.Tp {
+		case ast.ConstraintCheck:
+			if tb.tbl.FindConstraintInfoByName(c.Name) != nil {
+				return nil
+			}
+		case ast.ConstraintForeignKey:
+			if tb.findForeignKey(c.Name) != nil {
+				return nil
+			}
+		default:
+			if tb.tbl.FindIndexByName(strings.ToLower(c.Name)) != nil {
+				return nil
+			}
+		}
+	}
+	return tb.buildConstraints([]*ast.Constraint{c})
+}
+
+// dropPrimaryKey drops the primary key.
+func (tb *tableBuilder) dropPrimaryKey() error {
+	if tb.tbl.PKIsHandle {
+		tb.tbl.PKIsHandle = false
+		return tb.checkForeignKeyIndexes(mysql.PrimaryKeyName)
+	}
+	for _, idx := range tb.tbl.Indices {
+		if idx.Primary {
+			tb.tbl.IsCommonHandle = false
+			tb.tbl.CommonHandleVersion = 0
+			return tb.removeIndex(idx)
+		}
+	}
+	return ErrCantDropFieldOrKey.GenWithStackByArgs(mysql.PrimaryKeyName)
+}
+
+// dropIndex drops an index, DROP INDEX `PRIMARY` drops the primary key.
+func (tb *tableBuilder) dropIndex(name string, ifExists bool) error {
+	if strings.EqualFold(name, mysql.PrimaryKeyName) {
+		return tb.dropPrimaryKey()
+	}
+	idx := tb.tbl.FindIndexByName(strings.ToLower(name))
+	if idx == nil {
+		if ifExists {
+			return nil
+		}
+		return ErrCantDropFieldOrKey.GenWithStackByArgs(name)
+	}
+	return tb.removeIndex(idx)
+}
+
+// removeIndex removes an index and the hidden columns of a functional index.
+func (tb *tableBuilder) removeIndex(idx *model.IndexInfo) error {
+	indices := tb.tbl.Indices[:0:0]
+	for _, i := range tb.tbl.Indices {
+		if i != idx {
+			indices = append(indices, i)
+		}
+	}
+	tb.tbl.Indices = indices
+	if err := tb.checkForeignKeyIndexes(idx.Name.O); err != nil {
+		return err
+	}
+	var hidden []*model.ColumnInfo
+	for _, ic := range idx.Columns {
+		if col := tb.tbl.Columns[ic.Offset]; col.Hidden {
+			hidden = append(hidden, col)
+		}
+	}
+	if len(hidden) > 0 {
+		tb.removeColumns(hidden...)
+	}
+	return nil
+}
+
+// checkForeignKeyIndexes checks that every foreign key still has an index
+// after the index is dropped.
+func (tb *tableBuilder) checkForeignKeyIndexes(name string) error {
+	for _, fk := range tb.tbl.ForeignKeys {
+		if !tb.hasIndexOn(fk.Cols) {
+			return ErrDropIndexFk.GenWithStackByArgs(name)
+		}
+	}
+	return nil
+}
+
+func (tb *tableBuilder) findForeignKey(name string) *model.FKInfo {
+	for _, fk := range tb.tbl.ForeignKeys {
+		if fk.Name.L == strings.ToLower(name) {
+			return fk
+		}
+	}
+	return nil
+}
+
+// dropForeignKey drops a foreign key, its index is kept.
+func (tb *tableBuilder) dropForeignKey(name string, ifExists bool) error {
+	fk := tb.findForeignKey(name)
+	if fk == nil {
+		if ifExists {
+			return nil
+		}
+		return ErrCantDropFieldOrKey.GenWithStackByArgs(name)
+	}
+	fks := tb.tbl.ForeignKeys[:0:0]
+	for _, f := range tb.tbl.ForeignKeys {
+		if f != fk {
+			fks = append(fks, f)
+		}
+	}
+	tb.tbl.ForeignKeys = fks
+	return nil
+}
+
+// renameIndex renames an index, the primary key cannot be renamed.
+func (tb *tableBuilder) renameIndex(from, to model.CIStr) error {
+	idx := tb.tbl.FindIndexByName(from.L)
+	if idx == nil {
+		return ErrKeyDoesNotExist.GenWithStackByArgs(from.O, tb.tbl.Name.O)
+	}
+	if idx.Primary || to.L == strings.ToLower(mysql.PrimaryKeyName) {
+		return ErrWrongNameForIndex.GenWithStackByArgs(to.O)
+	}
+	if from.L != to.L && tb.tbl.FindIndexByName(to.L) != nil {
+		return ErrDupKeyName.GenWithStackByArgs(to.O)
+	}
+	idx.Name = to
+	return nil
+}
+
+// setIndexVisibility sets ALTER INDEX ... VISIBLE or INVISIBLE.
+func (tb *tableBuilder) setIndexVisibility(name model.CIStr, visibility ast.IndexVisibility) error {
+	idx := tb.tbl.FindIndexByName(name.L)
+	if idx == nil {
+		return ErrKeyDoesNotExist.GenWithStackByArgs(name.O, tb.tbl.Name.O)
+	}
+	idx.Invisible = visibility == ast.IndexVisibilityInvisible
+	return nil
+}
+
+// alterCheck sets ALTER CHECK ... ENFORCED or NOT ENFORCED.
+func (tb *tableBuilder) alterCheck(name string, enforced bool) error {
+	c := tb.tbl.FindConstraintInfoByName(name)
+	if c == nil {
+		return ErrCheckConstraintNotFound.GenWithStackByArgs(name)
+	}
+	c.Enforced = enforced
+	return nil
+}
+
+// dropCheck drops a check constraint.
+func (tb *tableBuilder) dropCheck(name string) error {
+	c := tb.tbl.FindConstraintInfoByName(name)
+	if c == nil {
+		return ErrCheckConstraintNotFound.GenWithStackByArgs(name)
+	}
+	constraints := tb.tbl.Constraints[:0:0]
+	for _, other := range tb.tbl.Constraints {
+		if other != c {
+			constraints = append(constraints, other)
+		}
+	}
+	tb.tbl.Constraints = constraints
+	return nil
+}
+
+// partitionColumns returns the lower case names of the partitioning columns.
+func (tb *tableBuilder) partitionColumns() []string {
+	pi := tb.tbl.Partition
+	if pi == nil {
+		return nil
+	}
+	var cols []string
+	for _, c := range pi.Columns {
+		cols = append(cols, c.L)
+	}
+	expr := strings.ToLower(pi.Expr)
+	for _, col := range tb.tbl.Columns {
+		if strings.Contains(expr, "`"+col.Name.L+"`") {
+			cols = append(cols, col.Name.L)
+		}
+	}
+	return cols
+}
+
+// findPartition returns the index of a partition, or -1.
+func findPartition(pi *model.PartitionInfo, name string) int {
+	for i, def := range pi.Definitions {
+		if def.Name.L == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// addPartitions adds the partitions of ADD PARTITION.
+func (tb *tableBuilder) addPartitions(spec *ast.AlterTableSpec) error {
+	pi := tb.tbl.Partition
+	if pi == nil {
+		return ErrPartitionMgmtOnNonpartitioned.GenWithStackByArgs()
+	}
+	defs := spec.PartDefinitions
+	switch pi.Type {
+	case model.PartitionTypeRange, model.PartitionTypeList:
+		if len(defs) == 0 {
+			return ErrPartitionsMustBeDefined.GenWithStackByArgs(pi.Type.String())
+		}
+	default:
+		for i := len(defs); i < int(spec.Num); i++ {
+			name := fmt.Sprintf("p%d", len(pi.Definitions)+i)
+			defs = append(defs, &ast.PartitionDefinition{Name: model.NewCIStr(name)})
+		}
+	}
+
+	added := &model.PartitionInfo{}
+	opts := &ast.PartitionOptions{PartitionMethod: ast.PartitionMethod{Tp: pi.Type}, Definitions: defs}
+	if err := tb.buildPartitionDefinitions(added, opts); err != nil {
+		return err
+	}
+	for _, def := range added.Definitions {
+		if findPartition(pi, def.Name.L) >= 0 {
+			return ErrSameNamePartition.GenWithStackByArgs(def.Name.O)
+		}
+	}
+	if len(pi.Definitions)+len(added.Definitions) > maxPartitionCount {
+		return ErrTooManyPartitions.GenWithStackByArgs()
+	}
+	switch pi.Type {
+	case model.PartitionTypeRange:
+		if err := checkRangePartitions(defs, len(pi.Columns) > 0); err != nil {
+			return err
+		}
+		if err := checkAddedRangePartition(pi, defs[0]); err != nil {
+			return err
+		}
+	case model.PartitionTypeList:
+		if err := checkListPartitions(defs); err != nil {
+			return err
+		}
+		if err := checkAddedListPartitions(pi, defs); err != nil {
+			return err
+		}
+	}
+	pi.Definitions = append(pi.Definitions, added.Definitions...)
+	pi.Num = uint64(len(pi.Definitions))
+	return nil
+}
+
+// dropPartitions drops RANGE or LIST partitions.
+func (tb *tableBuilder) dropPartitions(names []model.CIStr, ifExists bool) error {
+	pi := tb.tbl.Partition
+	if pi == nil {
+		return ErrPartitionMgmtOnNonpartitioned.GenWithStackByArgs()
+	}
+	if pi.Type != model.PartitionTypeRange && pi.Type != model.PartitionTypeList {
+		return ErrOnlyOnRangeListPartition.GenWithStackByArgs("DROP")
+	}
+	drop := make(map[string]bool, len(names))
+	for _, name := range names {
+		if findPartition(pi, name.L) < 0 {
+			if ifExists {
+				continue
+			}
+			return ErrDropPartitionNonExistent.GenWithStackByArgs("DROP")
+		}
+		drop[name.L] = true
+	}
+	defs := pi.Definitions[:0:0]
+	for _, def := range pi.Definitions {
+		if !drop[def.Name.L] {
+			defs = append(defs, def)
+		}
+	}
+	if len(defs) == 0 {
+		return ErrDropLastPartition.GenWithStackByArgs()
+	}
+	pi.Definitions = defs
+	pi.Num = uint64(len(defs))
+	return nil
+}
+
+// truncatePartitions gives the partitions new IDs.
+func (tb *tableBuilder) truncatePartitions(names []model.CIStr, all bool) error {
+	pi := tb.tbl.Partition
+	if pi == nil {
+		return ErrPartitionMgmtOnNonpartitioned.GenWithStackByArgs()
+	}
+	if all {
+		for i := range pi.Definitions {
+			pi.Definitions[i].ID = tb.genID()
+		}
+		return nil
+	}
+	for _, name := range names {
+		if findPartition(pi, name.L) < 0 {
+			return ErrDropPartitionNonExistent.GenWithStackByArgs("TRUNCATE")
+		}
+	}
+	for _, name := range names {
+		pi.Definitions[findPartition(pi, name.L)].ID = tb.genID()
+	}
+	return nil
+}
+
+// coalescePartitions removes the last num HASH or KEY partitions.
+func (tb *tableBuilder) coalescePartitions(num uint64) error {
+	pi := tb.tbl.Partition
+	if pi == nil {
+		return ErrPartitionMgmtOnNonpartitioned.GenWithStackByArgs()
+	}
+	if pi.Type != model.PartitionTypeHash && pi.Type != model.PartitionTypeKey {
+		return ErrCoalesceOnlyOnHashPartition.GenWithStackByArgs()
+	}
+	if num >= uint64(len(pi.Definitions)) {
+		return ErrDropLastPartition.GenWithStackByArgs()
+	}
+	pi.Definitions = pi.Definitions[:len(pi.Definitions)-int(num)]
+	pi.Num = uint64(len(pi.Definitions))
+	return nil
+}
+
+// checkAddedRangePartition checks that the first added RANGE partition is
+// greater than the last partition.
+func checkAddedRangePartition(pi *model.PartitionInfo, def *ast.PartitionDefinition) error {
+	clause, ok := def.Clause.(*ast.PartitionDefinitionClauseLessThan)
+	if !ok || len(pi.Definitions) == 0 {
+		return nil
+	}
+	last := pi.Definitions[len(pi.Definitions)-1].LessThan
+	prev := make([]partitionValue, 0, len(last))
+	for _, s := range last {
+		prev = append(prev, storedPartitionValue(s))
+	}
+	values := make([]partitionValue, 0, len(clause.Exprs))
+	for _, expr := range clause.Exprs {
+		values = append(values, newPartitionValue(expr))
+	}
+	if !increasing(prev, values) {
+		return ErrRangeNotIncreasing.GenWithStackByArgs()
+	}
+	return nil
+}
+
+// checkAddedListPartitions checks that the values of the added LIST
+// partitions are in no partition.
+func checkAddedListPartitions(pi *model.PartitionInfo, defs []*ast.PartitionDefinition) error {
+	seen := make(map[string]bool)
+	for _, def := range pi.Definitions {
+		for _, row := range def.InValues {
+			keys := make([]string, 0, len(row))
+			for _, s := range row {
+				keys = append(keys, storedValueKey(s))
+			}
+			seen[strings.Join(keys, ",")] = true
+		}
+	}
+	for _, def := range defs {
+		clause, ok := def.Clause.(*ast.PartitionDefinitionClauseIn)
+		if !ok {
+			continue
+		}
+		for _, row := range clause.Values {
+			keys := make([]string, 0, len(row))
+			for _, expr := range row {
+				key, err := listValueKey(expr)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+			if seen[strings.Join(keys, ",")] {
+				return ErrMultipleDefConstInListPart.GenWithStackByArgs()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ddl builds the schema information of the DDL statements like the
+// ddl package of TiDB, without a TiDB server.
+//
+// A Builder converts a CreateTableStmt to a model.TableInfo: the column types
+// get their default lengths, charsets and collations, the constraints become
+// indexes, foreign keys and check constraints, and the partitioning becomes a
+// PartitionInfo. An invalid definition returns the error MySQL returns.
+package ddl
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/resolver"
+	"github.com/pingcap/errors"
+)
+
+// ClusteredIndexDefMode is the default of a primary key without CLUSTERED or
+// NONCLUSTERED, like the tidb_enable_clustered_index variable.
+type ClusteredIndexDefMode int
+
+// Clustered index modes.
+const (
+	// ClusteredIndexDefModeIntOnly clusters a primary key of a single integer
+	// column only.
+	ClusteredIndexDefModeIntOnly ClusteredIndexDefMode = iota
+	// ClusteredIndexDefModeOn clusters all the primary keys.
+	ClusteredIndexDefModeOn
+	// ClusteredIndexDefModeOff clusters no primary key.
+	ClusteredIndexDefModeOff
+)
+
+const (
+	// maxColumnCount is the maximum number of columns of a table.
+	maxColumnCount = 1017
+	// maxIndexCount is the maximum number of indexes of a table.
+	maxIndexCount = 64
+	// maxKeyLength is the maximum length of an index in bytes.
+	maxKeyLength = 3072
+	// maxColumnCommentLength is the maximum length of a column comment.
+	maxColumnCommentLength = 1024
+	// maxIndexCommentLength is the maximum length of an index comment.
+	maxIndexCommentLength = 1024
+	// maxTableCommentLength is the maximum length of a table comment.
+	maxTableCommentLength = 2048
+	// maxPartitionCount is the maximum number of partitions of a table.
+	maxPartitionCount = 8192
+	// maxFsp is the maximum fractional seconds precision.
+	maxFsp = 6
+)
+
+// exprRestoreFlags are the flags to restore the generated columns and the
+// check constraints, like TiDB.
+const exprRestoreFlags = format.RestoreStringSingleQuotes | format.RestoreKeyWordLowercase |
+	format.RestoreNameBackQuotes | format.RestoreSpacesAroundBinaryOperation
+
+// Builder builds the TableInfo of CREATE TABLE statements.
+type Builder struct {
+	// Charset and Collation are the defaults of the tables, which are the
+	// charset and the collation of the database.
+	Charset   string
+	Collation string
+	// ClusteredIndex is the default of the primary keys.
+	ClusteredIndex ClusteredIndexDefMode
+	// InfoSchema is the optional catalog of CREATE TABLE ... LIKE and
+	// CREATE TABLE ... SELECT.
+	InfoSchema resolver.InfoSchema
+	// CurrentDB is the database of the unqualified table names.
+	CurrentDB string
+	// GenID generates the global IDs of the tables and the partitions, the
+	// IDs increase from 1 if it is nil.
+	GenID func() int64
+
+	lastID int64
+}
+
+// NewBuilder creates a Builder with the default charset and collation.
+func NewBuilder() *Builder {
+	return &Builder{Charset: mysql.DefaultCharset, Collation: mysql.DefaultCollationName}
+}
+
+// BuildTableInfo builds the TableInfo of a CREATE TABLE statement with a new
+// Builder.
+func BuildTableInfo(stmt *ast.CreateTableStmt) (*model.TableInfo, error) {
+	return NewBuilder().Build(stmt)
+}
+
+func (b *Builder) genID() int64 {
+	if b.GenID != nil {
+		return b.GenID()
+	}
+	b.lastID++
+	return b.lastID
+}
+
+// tableBuilder builds a TableInfo.
+type tableBuilder struct {
+	*Builder
+	tbl *model.TableInfo
+	// hasDefault are the columns with a DEFAULT clause.
+	hasDefault map[string]bool
+	// nullable are the columns with a NULL clause.
+	nullable map[string]bool
+}
+
+// Build builds the TableInfo of a CREATE TABLE statement.
+func (b *Builder) Build(stmt *ast.CreateTableStmt) (*model.TableInfo, error) {
+	if err := checkTableName(stmt.Table.Name.O); err != nil {
+		return nil, err
+	}
+	if stmt.ReferTable != nil {
+		return b.buildLike(stmt)
+	}
+	tbl := &model.TableInfo{
+		ID:      b.genID(),
+		Name:    stmt.Table.Name,
+		State:   model.StatePublic,
+		Version: model.CurrLatestTableInfoVersion,
+	}
+	switch stmt.TemporaryKeyword {
+	case ast.TemporaryGlobal:
+		tbl.TempTableType = model.TempTableGlobal
+	case ast.TemporaryLocal:
+		tbl.TempTableType = model.TempTableLocal
+	}
+	tb := &tableBuilder{Builder: b, tbl: tbl, hasDefault: make(map[string]bool), nullable: make(map[string]bool)}
+	if err := tb.setTableOptions(stmt.Options); err != nil {
+		return nil, err
+	}
+
+	var constraints []*ast.Constraint
+	for _, def := range stmt.Cols {
+		col, cs, err := tb.buildColumn(def)
+		if err != nil {
+			return nil, err
+		}
+		if err := tb.addColumn(col); err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, cs...)
+	}
+	if stmt.Select != nil {
+		cols, err := tb.selectColumns(stmt.Select)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range cols {
+			if model.FindColumnInfo(tbl.Columns, col.Name.L) != nil {
+				// The definition of CREATE TABLE wins.
+				continue
+			}
+			if err := tb.addColumn(col); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(tbl.Columns) == 0 {
+		return nil, ErrTableMustHaveColumns.GenWithStackByArgs()
+	}
+	if len(tbl.Columns) > maxColumnCount {
+		return nil, ErrTooManyFields.GenWithStackByArgs()
+	}
+	if err := tb.checkGeneratedColumns(); err != nil {
+		return nil, err
+	}
+
+	constraints = append(constraints, stmt.Constraints...)
+	if err := tb.buildConstraints(constraints); err != nil {
+		return nil, err
+	}
+	if err := tb.checkAutoIncrement(); err != nil {
+		return nil, err
+	}
+	for _, col := range tbl.Columns {
+		tb.setNoDefaultValueFlag(col)
+	}
+	if stmt.Partition != nil {
+		if err := tb.buildPartitionInfo(stmt.Partition); err != nil {
+			return nil, err
+		}
+	}
+	return tbl, nil
+}
+
+// buildLike builds the TableInfo of CREATE TABLE ... LIKE, which copies the
+// columns, the indexes and the partitioning of the referred table.
+func (b *Builder) buildLike(stmt *ast.CreateTableStmt) (*model.TableInfo, error) {
+	schema := stmt.ReferTable.Schema
+	if schema.L == "" {
+		if b.CurrentDB == "" {
+			return nil, ErrNoDB.GenWithStackByArgs()
+		}
+		schema = model.NewCIStr(b.CurrentDB)
+	}
+	var refer *model.TableInfo
+	if b.InfoSchema != nil {
+		refer, _ = b.InfoSchema.TableByName(schema, stmt.ReferTable.Name)
+	}
+	if refer == nil {
+		return nil, ErrTableNotExists.GenWithStackByArgs(schema.O, stmt.ReferTable.Name.O)
+	}
+	tbl := cloneTable(refer)
+	tbl.ID = b.genID()
+	setTableName(tbl, stmt.Table.Name)
+	tbl.AutoIncID = 0
+	tbl.ForeignKeys = nil
+	tbl.View = nil
+	tbl.TiFlashReplica = nil
+	tbl.Lock = nil
+	if tbl.Partition != nil {
+		for i := range tbl.Partition.Definitions {
+			tbl.Partition.Definitions[i].ID = b.genID()
+		}
+	}
+	return tbl, nil
+}
+
+// cloneTable clones a TableInfo with its check constraints and partitioning,
+// which TableInfo.Clone shares.
+func cloneTable(tbl *model.TableInfo) *model.TableInfo {
+	nt := tbl.Clone()
+	nt.Constraints = make([]*model.ConstraintInfo, len(tbl.Constraints))
+	for i, c := range tbl.Constraints {
+		nt.Constraints[i] = c.Clone()
+	}
+	if tbl.Partition != nil {
+		nt.Partition = tbl.Partition.Clone()
+	}
+	return nt
+}
+
+// setTableName sets the name of a table and its indexes and constraints.
+func setTableName(tbl *model.TableInfo, name model.CIStr) {
+	tbl.Name = name
+	for _, idx := range tbl.Indices {
+		idx.Table = name
+	}
+	for _, c := range tbl.Constraints {
+		c.Table = name
+	}
+}
+
+// setTableOptions sets the charset, the collation and the other options of
+// the table.
+func (tb *tableBuilder) setTableOptions(options []*ast.TableOption) error {
+	var cs, co string
+	for _, op := range options {
+		switch op.Tp {
+		case ast.TableOptionCharset:
+			if op.Default {
+				cs = tb.Charset
+				continue
+			}
+			cs = strings.ToLower(op.StrValue)
+		case ast.TableOptionCollate:
+			co = strings.ToLower(op.StrValue)
+		case ast.TableOptionComment:
+			if utf8.RuneCountInString(op.StrValue) > maxTableCommentLength {
+				return ErrTooLongTableComment.GenWithStackByArgs(tb.tbl.Name.O, maxTableCommentLength)
+			}
+			tb.tbl.Comment = op.StrValue
+		case ast.TableOptionAutoIncrement:
+			tb.tbl.AutoIncID = int64(op.UintValue)
+		case ast.TableOptionAutoIdCache:
+			tb.tbl.AutoIdCache = int64(op.UintValue)
+		case ast.TableOptionAutoRandomBase:
+			tb.tbl.AutoRandID = int64(op.UintValue)
+		case ast.TableOptionShardRowID:
+			tb.tbl.ShardRowIDBits = op.UintValue
+			tb.tbl.MaxShardRowIDBits = op.UintValue
+		case ast.TableOptionPreSplitRegion:
+			tb.tbl.PreSplitRegions = op.UintValue
+		case ast.TableOptionCompression:
+			tb.tbl.Compression = op.StrValue
+		case ast.TableOptionPlacementPolicy:
+			tb.tbl.PlacementPolicyRef = &model.PolicyRefInfo{Name: model.NewCIStr(op.StrValue)}
+		}
+	}
+	if cs == "" && co == "" {
+		cs, co = tb.Charset, tb.Collation
+	}
+	cs, co, err := resolveCharsetCollation(cs, co)
+	if err != nil {
+		return err
+	}
+	tb.tbl.Charset, tb.tbl.Collate = cs, co
+	return nil
+}
+
+// resolveCharsetCollation validates a charset and a collation, the missing
+// one is derived from the other.
+func resolveCharsetCollation(cs, co string) (string, string, error) {
+	if cs != "" {
+		info, err := charset.GetCharsetInfo(cs)
+		if err != nil {
+			return "", "", ErrUnknownCharacterSet.GenWithStackByArgs(cs)
+		}
+		cs = info.Name
+	}
+	if co == "" {
+		if cs == "" {
+			return "", "", nil
+		}
+		co, err := charset.GetDefaultCollation(cs)
+		return cs, co, errors.Trace(err)
+	}
+	coll, err := charset.GetCollationByName(co)
+	if err != nil {
+		return "", "", ErrUnknownCollation.GenWithStackByArgs(co)
+	}
+	if cs == "" {
+		return coll.CharsetName, coll.Name, nil
+	}
+	if coll.CharsetName != cs {
+		return "", "", ErrCollationCharsetMismatch.GenWithStackByArgs(coll.Name, cs)
+	}
+	return cs, coll.Name, nil
+}
+
+// addColumn appends a column to the table.
+func (tb *tableBuilder) addColumn(col *model.ColumnInfo) error {
+	if model.FindColumnInfo(tb.tbl.Columns, col.Name.L) != nil {
+		return ErrDupFieldName.GenWithStackByArgs(col.Name.O)
+	}
+	tb.tbl.MaxColumnID++
+	col.ID = tb.tbl.MaxColumnID
+	col.Offset = len(tb.tbl.Columns)
+	tb.tbl.Columns = append(tb.tbl.Columns, col)
+	return nil
+}
+
+// checkTableName checks a table name like MySQL.
+func checkTableName(name string) error {
+	if name == "" || strings.HasSuffix(name, " ") {
+		return ErrWrongTableName.GenWithStackByArgs(name)
+	}
+	if utf8.RuneCountInString(name) > mysql.MaxTableNameLength {
+		return ErrTooLongIdent.GenWithStackByArgs(name)
+	}
+	return nil
+}
+
+// checkColumnName checks a column name like MySQL.
+func checkColumnName(name string) error {
+	if name == "" || strings.HasSuffix(name, " ") {
+		return ErrWrongColumnName.GenWithStackByArgs(name)
+	}
+	if utf8.RuneCountInString(name) > mysql.MaxColumnNameLength {
+		return ErrTooLongIdent.GenWithStackByArgs(name)
+	}
+	return nil
+}
+
+// restoreExpr restores an expression with the flags, without the outermost
+// parentheses.
+func restoreExpr(expr ast.ExprNode, flags format.RestoreFlags) (string, error) {
+	for {
+		p, ok := expr.(*ast.ParenthesesExpr)
+		if !ok {
+			break
+		}
+		expr = p.Expr
+	}
+	var sb strings.Builder
+	if err := expr.Restore(format.NewRestoreCtx(flags, &sb)); err != nil {
+		return "", errors.Trace(err)
+	}
+	return sb.String(), nil
+}
+
+// columnNames returns the lower case names of the columns an expression
+// refers to, in the order of their first occurrences.
+func columnNames(expr ast.ExprNode) []string {
+	v := &columnNameCollector{seen: make(map[string]bool)}
+	expr.Accept(v)
+	return v.names
+}
+
+type columnNameCollector struct {
+	names []string
+	seen  map[string]bool
+}
+
+// Enter implements ast.Visitor interface.
+func (v *columnNameCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if c, ok := n.(*ast.ColumnNameExpr); ok {
+		if !v.seen[c.Name.Name.L] {
+			v.seen[c.Name.Name.L] = true
+			v.names = append(v.names, c.Name.Name.L)
+		}
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (v *columnNameCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
@@ -0,0 +1,308 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	. "github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/resolver"
+	"github.com/daiguadaidai/parser/terror"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+func build(t *testing.T, b *Builder, sql string) (*model.TableInfo, error) {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err, sql)
+	return b.Build(stmt.(*ast.CreateTableStmt))
+}
+
+func mustBuild(t *testing.T, b *Builder, sql string) *model.TableInfo {
+	tbl, err := build(t, b, sql)
+	require.NoError(t, err, sql)
+	return tbl
+}
+
+func indexStrings(tbl *model.TableInfo) string {
+	res := make([]string, 0, len(tbl.Indices))
+	for _, idx := range tbl.Indices {
+		cols := make([]string, 0, len(idx.Columns))
+		for _, c := range idx.Columns {
+			cols = append(cols, c.Name.O)
+		}
+		res = append(res, fmt.Sprintf("%s(%s)", idx.Name.O, strings.Join(cols, ",")))
+	}
+	return strings.Join(res, " ")
+}
+
+func TestColumns(t *testing.T) {
+	cases := []struct {
+		def     string
+		tp      string
+		dflt    interface{}
+		charset string
+		collate string
+	}{
+		{"int", "int(11)", nil, "binary", "binary"},
+		{"int unsigned", "int(10) unsigned", nil, "binary", "binary"},
+		{"tinyint(1) default '1'", "tinyint(1)", "1", "binary", "binary"},
+		{"bigint zerofill", "bigint(20) unsigned zerofill", nil, "binary", "binary"},
+		{"decimal", "decimal(10,0)", nil, "binary", "binary"},
+		{"decimal(5,2) default 1.5", "decimal(5,2)", "1.50", "binary", "binary"},
+		{"double default -1", "double", "-1", "binary", "binary"},
+		{"varchar(10) default 'x'", "varchar(10)", "x", "utf8mb4", "utf8mb4_bin"},
+		{"char(3) charset latin1", "char(3)", nil, "latin1", "latin1_bin"},
+		{"varchar(10) collate utf8mb4_general_ci", "varchar(10)", nil, "utf8mb4", "utf8mb4_general_ci"},
+		{"varchar(10) binary", "varchar(10)", nil, "utf8mb4", "utf8mb4_bin"},
+		{"text(50)", "tinytext", nil, "utf8mb4", "utf8mb4_bin"},
+		{"varbinary(10)", "varbinary(10)", nil, "binary", "binary"},
+		{"enum('a','b') default 'b'", "enum('a','b')", "b", "utf8mb4", "utf8mb4_bin"},
+		{"set('a','b') default 'b,a,b'", "set('a','b')", "a,b", "utf8mb4", "utf8mb4_bin"},
+		{"bit(8) default b'101'", "bit(8)", "\x05", "binary", "binary"},
+		{"datetime(3) default current_timestamp(3)", "datetime(3)", "CURRENT_TIMESTAMP", "binary", "binary"},
+		{"timestamp default '2020-01-01 00:00:00'", "timestamp", "2020-01-01 00:00:00", "binary", "binary"},
+		{"tinyint unsigned default 255", "tinyint(3) unsigned", "255", "binary", "binary"},
+		{"smallint default '-32768'", "smallint(6)", "-32768", "binary", "binary"},
+		{"int default 1.5", "int(11)", "2", "binary", "binary"},
+		{"date default 20200101", "date", "2020-01-01", "binary", "binary"},
+		{"date default '2020/1/2 10:00:00'", "date", "2020-01-02", "binary", "binary"},
+		{"datetime default '2020-01-01'", "datetime", "2020-01-01 00:00:00", "binary", "binary"},
+		{"datetime(3) default '20-1-2 3:4:5.6789'", "datetime(3)", "2020-01-02 03:04:05.679", "binary", "binary"},
+		{"datetime default 0", "datetime", "0000-00-00 00:00:00", "binary", "binary"},
+		{"timestamp(6) default '1999-12-31 23:59:59.9999999'", "timestamp(6)", "2000-01-01 00:00:00.000000", "binary", "binary"},
+		{"time(2) default '10:00:00.5'", "time(2)", "10:00:00.50", "binary", "binary"},
+		{"time default '-1 2:3:4'", "time", "-26:03:04", "binary", "binary"},
+		{"time default 1234", "time", "00:12:34", "binary", "binary"},
+		{"year default 2020", "year(4)", "2020", "binary", "binary"},
+		{"year default '69'", "year(4)", "2069", "binary", "binary"},
+	}
+	for _, c := range cases {
+		tbl := mustBuild(t, NewBuilder(), "create table t (a "+c.def+")")
+		col := tbl.Columns[0]
+		require.Equal(t, c.tp, col.GetTypeDesc(), c.def)
+		require.Equal(t, c.dflt, col.GetDefaultValue(), c.def)
+		require.Equal(t, c.charset, col.GetCharset(), c.def)
+		require.Equal(t, c.collate, col.GetCollate(), c.def)
+	}
+
+	tbl := mustBuild(t, NewBuilder(), "create table t (a int not null, b int, c int not null default 1, d varchar(10) comment 'x', e int as (a + b), f double default (rand()))")
+	require.Equal(t, int64(1), tbl.ID)
+	require.Equal(t, int64(6), tbl.MaxColumnID)
+	require.True(t, mysql.HasNoDefaultValueFlag(tbl.Columns[0].GetFlag()))
+	require.False(t, mysql.HasNoDefaultValueFlag(tbl.Columns[1].GetFlag()))
+	require.False(t, mysql.HasNoDefaultValueFlag(tbl.Columns[2].GetFlag()))
+	require.Equal(t, "x", tbl.Columns[3].Comment)
+	require.Equal(t, "`a` + `b`", tbl.Columns[4].GeneratedExprString)
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, tbl.Columns[4].Dependences)
+	require.True(t, tbl.Columns[5].DefaultIsExpr)
+}
+
+func TestTableOptions(t *testing.T) {
+	tbl := mustBuild(t, NewBuilder(), "create table t (a varchar(10), b varchar(10) charset utf8) charset latin1 comment 'c' auto_increment 10 shard_row_id_bits 4")
+	require.Equal(t, "latin1", tbl.Charset)
+	require.Equal(t, "latin1_bin", tbl.Collate)
+	require.Equal(t, "latin1", tbl.Columns[0].GetCharset())
+	require.Equal(t, "utf8", tbl.Columns[1].GetCharset())
+	require.Equal(t, "c", tbl.Comment)
+	require.Equal(t, int64(10), tbl.AutoIncID)
+	require.Equal(t, uint64(4), tbl.ShardRowIDBits)
+
+	b := &Builder{Charset: "utf8", Collation: "utf8_general_ci"}
+	tbl = mustBuild(t, b, "create table t (a text) collate utf8mb4_unicode_ci")
+	require.Equal(t, "utf8mb4", tbl.Charset)
+	require.Equal(t, "utf8mb4_unicode_ci", tbl.Columns[0].GetCollate())
+	tbl = mustBuild(t, b, "create global temporary table t2 (a text) on commit delete rows")
+	require.Equal(t, "utf8_general_ci", tbl.Collate)
+	require.Equal(t, model.TempTableGlobal, tbl.TempTableType)
+	require.Equal(t, int64(2), tbl.ID)
+}
+
+func TestIndexes(t *testing.T) {
+	tbl := mustBuild(t, NewBuilder(), "create table t (id int primary key, a int, b varchar(10), c text, unique (a), key (a, b), key idx (c(10)) comment 'x' invisible)")
+	require.True(t, tbl.PKIsHandle)
+	require.Equal(t, "a(a) a_2(a,b) idx(c)", indexStrings(tbl))
+	require.True(t, mysql.HasPriKeyFlag(tbl.Columns[0].GetFlag()))
+	require.True(t, mysql.HasUniKeyFlag(tbl.Columns[1].GetFlag()))
+	require.Equal(t, 10, tbl.Indices[2].Columns[0].Length)
+	require.True(t, tbl.Indices[2].Invisible)
+	require.Equal(t, "x", tbl.Indices[2].Comment)
+	require.Equal(t, int64(3), tbl.MaxIndexID)
+
+	tbl = mustBuild(t, NewBuilder(), "create table t (a varchar(10), b int, primary key (a, b))")
+	require.False(t, tbl.PKIsHandle)
+	require.False(t, tbl.IsCommonHandle)
+	require.Equal(t, "PRIMARY(a,b)", indexStrings(tbl))
+	require.True(t, tbl.Indices[0].Primary)
+	require.True(t, mysql.HasNotNullFlag(tbl.Columns[0].GetFlag()))
+
+	tbl = mustBuild(t, NewBuilder(), "create table t (a varchar(10) primary key clustered)")
+	require.True(t, tbl.IsCommonHandle)
+	require.Equal(t, uint16(1), tbl.CommonHandleVersion)
+
+	tbl = mustBuild(t, &Builder{ClusteredIndex: ClusteredIndexDefModeOff}, "create table t (a int primary key)")
+	require.False(t, tbl.PKIsHandle)
+	require.Equal(t, "PRIMARY(a)", indexStrings(tbl))
+
+	tbl = mustBuild(t, NewBuilder(), "create table t (a int, b int, index ((a + b)), index ((a * 2), b))")
+	require.Equal(t, "functional_index(_V$_functional_index_0) functional_index_2(_V$_functional_index_2_0,b)", indexStrings(tbl))
+	require.True(t, tbl.Columns[2].Hidden)
+	require.Equal(t, "`a` + `b`", tbl.Columns[2].GeneratedExprString)
+	require.Equal(t, "bigint(20)", tbl.Columns[2].GetTypeDesc())
+}
+
+func TestConstraints(t *testing.T) {
+	tbl := mustBuild(t, NewBuilder(), "create table t (a int check (a > 0), b int references p (id), c int, constraint ck check (b < c) not enforced, foreign key fk (c) references db.p (id) on delete cascade, index (b))")
+	require.Len(t, tbl.Constraints, 2)
+	require.Equal(t, "t_chk_1", tbl.Constraints[0].Name.O)
+	require.Equal(t, "`a` > 0", tbl.Constraints[0].ExprString)
+	require.True(t, tbl.Constraints[0].InColumn)
+	require.True(t, tbl.Constraints[0].Enforced)
+	require.Equal(t, "ck", tbl.Constraints[1].Name.O)
+	require.Equal(t, []model.CIStr{model.NewCIStr("b"), model.NewCIStr("c")}, tbl.Constraints[1].ConstraintCols)
+	require.False(t, tbl.Constraints[1].Enforced)
+
+	require.Len(t, tbl.ForeignKeys, 2)
+	require.Equal(t, "t_ibfk_1", tbl.ForeignKeys[0].Name.O)
+	require.Equal(t, "p", tbl.ForeignKeys[0].RefTable.O)
+	require.Equal(t, "fk", tbl.ForeignKeys[1].Name.O)
+	require.Equal(t, int(ast.ReferOptionCascade), tbl.ForeignKeys[1].OnDelete)
+	// The index of b is reused, the index of c is added.
+	require.Equal(t, "b(b) fk(c)", indexStrings(tbl))
+}
+
+func TestPartitions(t *testing.T) {
+	b := NewBuilder()
+	tbl := mustBuild(t, b, "create table t (a int, b date) partition by range (a) (partition p0 values less than (10) comment 'x', partition p1 values less than (maxvalue))")
+	pi := tbl.Partition
+	require.Equal(t, model.PartitionTypeRange, pi.Type)
+	require.Equal(t, "`a`", pi.Expr)
+	require.Equal(t, uint64(2), pi.Num)
+	require.Equal(t, []string{"10"}, pi.Definitions[0].LessThan)
+	require.Equal(t, []string{"MAXVALUE"}, pi.Definitions[1].LessThan)
+	require.Equal(t, "x", pi.Definitions[0].Comment)
+	require.Equal(t, []int64{2, 3}, []int64{pi.Definitions[0].ID, pi.Definitions[1].ID})
+
+	tbl = mustBuild(t, b, "create table t (a int primary key) partition by hash (a) partitions 3")
+	require.Len(t, tbl.Partition.Definitions, 3)
+	require.Equal(t, "p2", tbl.Partition.Definitions[2].Name.O)
+
+	tbl = mustBuild(t, b, "create table t (a int, b varchar(10)) partition by list columns (a, b) (partition p0 values in ((1, 'x'), (2, 'y')))")
+	require.Equal(t, []model.CIStr{model.NewCIStr("a"), model.NewCIStr("b")}, tbl.Partition.Columns)
+	require.Equal(t, [][]string{{"1", "'x'"}, {"2", "'y'"}}, tbl.Partition.Definitions[0].InValues)
+
+	mustBuild(t, b, "create table t (a int, b date) partition by range columns (b) (partition p0 values less than ('2020-01-01'), partition p1 values less than ('2021-01-01'))")
+	mustBuild(t, b, "create table t (a int, b date) partition by range (to_days(b)) (partition p0 values less than (to_days('2020-01-01')), partition p1 values less than (maxvalue))")
+}
+
+func TestCreateTableSelect(t *testing.T) {
+	is := resolver.NewInfoSchema(&model.DBInfo{Name: model.NewCIStr("test"), Tables: []*model.TableInfo{
+		mustBuild(t, NewBuilder(), "create table s (a int not null, b varchar(10), c decimal(5,2))"),
+	}})
+	b := &Builder{Charset: mysql.DefaultCharset, Collation: mysql.DefaultCollationName, InfoSchema: is, CurrentDB: "test"}
+
+	tbl := mustBuild(t, b, "create table t (id int primary key) select a, b, c * 2 as d, concat(b, 'x') e, null f from s")
+	require.Len(t, tbl.Columns, 6)
+	descs := make([]string, 0, len(tbl.Columns))
+	for _, col := range tbl.Columns {
+		descs = append(descs, col.Name.O+" "+col.GetTypeDesc())
+	}
+	require.Equal(t, []string{"id int(11)", "a int(11)", "b varchar(10)", "d decimal(6,2)", "e varchar(11)", "f binary(0)"}, descs)
+	require.True(t, mysql.HasNotNullFlag(tbl.Columns[1].GetFlag()))
+
+	tbl = mustBuild(t, b, "create table t select a from s union select 1.5")
+	require.Equal(t, "decimal(12,1)", tbl.Columns[0].GetTypeDesc())
+
+	tbl = mustBuild(t, b, "create table t like s")
+	require.Equal(t, "t", tbl.Name.O)
+	require.Len(t, tbl.Columns, 3)
+	_, err := build(t, b, "create table t like u")
+	require.True(t, terror.ErrorEqual(ErrTableNotExists, err))
+	b.CurrentDB = ""
+	_, err = build(t, b, "create table t like s")
+	require.True(t, terror.ErrorEqual(ErrNoDB, err), "%v", err)
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		sql string
+		err *terror.Error
+	}{
+		{"create table t (a int, a int)", ErrDupFieldName},
+		{"create table t (a varchar(70000))", ErrTooBigFieldlength},
+		{"create table t (a int(256))", ErrTooBigDisplaywidth},
+		{"create table t (a decimal(66))", ErrTooBigPrecision},
+		{"create table t (a decimal(5,6))", ErrMBiggerThanD},
+		{"create table t (a datetime(7))", ErrTooBigPrecision},
+		{"create table t (a enum('x','X') collate utf8mb4_general_ci)", ErrDuplicatedValueInType},
+		{"create table t (a varchar(10) charset latin1 collate utf8mb4_bin)", ErrCollationCharsetMismatch},
+		{"create table t (a int default 'x')", ErrInvalidDefault},
+		{"create table t (a int not null default null)", ErrInvalidDefault},
+		{"create table t (a tinyint default 1000)", ErrInvalidDefault},
+		{"create table t (a tinyint default -129)", ErrInvalidDefault},
+		{"create table t (a int unsigned default -1)", ErrInvalidDefault},
+		{"create table t (a bigint unsigned default 18446744073709551616)", ErrInvalidDefault},
+		{"create table t (a datetime default 'abc')", ErrInvalidDefault},
+		{"create table t (a date default '2020-02-30')", ErrInvalidDefault},
+		{"create table t (a date default '2020-00-01')", ErrInvalidDefault},
+		{"create table t (a datetime default '2020-01-01 24:00:00')", ErrInvalidDefault},
+		{"create table t (a timestamp default '1960-01-01 00:00:00')", ErrInvalidDefault},
+		{"create table t (a time default '839:00:00')", ErrInvalidDefault},
+		{"create table t (a time default '10:60:00')", ErrInvalidDefault},
+		{"create table t (a year default 1900)", ErrInvalidDefault},
+		{"create table t (a text default 'x')", ErrBlobCantHaveDefault},
+		{"create table t (a int on update current_timestamp)", ErrInvalidOnUpdate},
+		{"create table t (a int auto_increment, b int auto_increment, key (a), key (b))", ErrWrongAutoKey},
+		{"create table t (a int auto_increment)", ErrWrongAutoKey},
+		{"create table t (a int as (b), b int as (1))", ErrGeneratedColumnNonPrior},
+		{"create table t (a int as (x))", ErrBadField},
+		{"create table t (a int auto_increment key, b int as (a))", ErrGeneratedColumnRefAutoInc},
+		{"create table t (a int primary key, b int primary key)", ErrMultiplePriKey},
+		{"create table t (a int null, primary key (a))", ErrPrimaryCantHaveNull},
+		{"create table t (a int, key k (a), key k (a))", ErrDupKeyName},
+		{"create table t (a int, key `primary` (a))", ErrWrongNameForIndex},
+		{"create table t (a int, key (b))", ErrKeyColumnDoesNotExits},
+		{"create table t (a int, key (a, a))", ErrDupFieldNameInIndex},
+		{"create table t (a text, key (a))", ErrBlobKeyWithoutLength},
+		{"create table t (a json, key (a))", ErrJSONUsedAsKey},
+		{"create table t (a int, key (a(10)))", ErrWrongSubKey},
+		{"create table t (a varchar(1000), b varchar(1000), key (a, b))", ErrTooLongKey},
+		{"create table t (a int, key ((a)))", ErrFunctionalIndexOnField},
+		{"create table t (a int, primary key ((a + 1)))", ErrFunctionalIndexPrimaryKey},
+		{"create table t (a text, key ((concat(a, 'x'))))", ErrFunctionalIndexOnLob},
+		{"create table t (a int, constraint c check (a > 0), constraint c check (a < 10))", ErrCheckConstraintDupName},
+		{"create table t (a int, check (b > 0))", ErrBadField},
+		{"create table t (a int, foreign key f (a) references p (x), foreign key f (a) references p (x))", ErrFkDupName},
+		{"create table t (a int, foreign key (a) references p (x, y))", ErrWrongFkDef},
+		{"create table t (a int) partition by range (a) (partition p values less than (1), partition p values less than (2))", ErrSameNamePartition},
+		{"create table t (a int) partition by range (a) (partition p0 values less than (2), partition p1 values less than (1))", ErrRangeNotIncreasing},
+		{"create table t (a int) partition by range (a) (partition p0 values less than maxvalue, partition p1 values less than (1))", ErrPartitionMaxvalue},
+		{"create table t (a int) partition by list (a) (partition p0 values in (1, 2), partition p1 values in (2))", ErrMultipleDefConstInListPart},
+		{"create table t (a int) partition by hash (b)", ErrFieldNotFoundPart},
+		{"create table t (a varchar(10)) partition by hash (a)", ErrFieldTypeNotAllowedAsPartitionField},
+		{"create table t (a int, b int primary key) partition by hash (a)", ErrUniqueKeyNeedAllFieldsInPf},
+		{"create table t (a int, b int, unique (b)) partition by hash (a)", ErrUniqueKeyNeedAllFieldsInPf},
+	}
+	for _, c := range cases {
+		_, err := build(t, NewBuilder(), c.sql)
+		require.Error(t, err, c.sql)
+		require.True(t, terror.ErrorEqual(c.err, err), "%s: %v", c.sql, err)
+	}
+}
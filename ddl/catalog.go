@@ -0,0 +1,610 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+)
+
+// viewRestoreFlags are the flags to restore the query of a view, like TiDB.
+const viewRestoreFlags = format.RestoreStringSingleQuotes | format.RestoreKeyWordUppercase |
+	format.RestoreNameBackQuotes | format.RestoreSpacesAroundBinaryOperation | format.RestoreStringWithoutCharset
+
+// Catalog is an in-memory schema which applies DDL statements like a MySQL
+// server. A statement which fails returns the error MySQL returns and leaves
+// the catalog unchanged.
+//
+// The TableInfo of the catalog are never modified in place, a statement
+// replaces the tables it changes, so the TableInfo returned before stay
+// valid.
+type Catalog struct {
+	// Charset and Collation are the defaults of the databases.
+	Charset   string
+	Collation string
+	// ClusteredIndex is the default of the primary keys.
+	ClusteredIndex ClusteredIndexDefMode
+
+	dbs       []*model.DBInfo
+	currentDB string
+	lastID    int64
+}
+
+// NewCatalog creates a Catalog holding the databases, the tables of a
+// database are the DBInfo.Tables.
+func NewCatalog(dbs ...*model.DBInfo) *Catalog {
+	c := &Catalog{Charset: mysql.DefaultCharset, Collation: mysql.DefaultCollationName}
+	for _, db := range dbs {
+		c.dbs = append(c.dbs, db.Copy())
+		c.lastID = maxInt64(c.lastID, db.ID)
+		for _, tbl := range db.Tables {
+			c.lastID = maxInt64(c.lastID, tbl.ID)
+			if tbl.Partition != nil {
+				for _, def := range tbl.Partition.Definitions {
+					c.lastID = maxInt64(c.lastID, def.ID)
+				}
+			}
+		}
+	}
+	return c
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// CurrentDB returns the database selected by USE.
+func (c *Catalog) CurrentDB() string {
+	return c.currentDB
+}
+
+// Schemas returns the databases in the order of creation.
+func (c *Catalog) Schemas() []*model.DBInfo {
+	return c.dbs
+}
+
+// SchemaByName implements resolver.InfoSchema interface.
+func (c *Catalog) SchemaByName(schema model.CIStr) (*model.DBInfo, bool) {
+	for _, db := range c.dbs {
+		if db.Name.L == schema.L {
+			return db, true
+		}
+	}
+	return nil, false
+}
+
+// TableByName implements resolver.InfoSchema interface.
+func (c *Catalog) TableByName(schema, table model.CIStr) (*model.TableInfo, bool) {
+	db, ok := c.SchemaByName(schema)
+	if !ok {
+		return nil, false
+	}
+	_, tbl := findTable(db, table)
+	return tbl, tbl != nil
+}
+
+func (c *Catalog) genID() int64 {
+	c.lastID++
+	return c.lastID
+}
+
+// Apply applies a statement to the catalog. The statements which change no
+// schema are ignored.
+func (c *Catalog) Apply(stmt ast.StmtNode) error {
+	switch x := stmt.(type) {
+	case *ast.UseStmt:
+		return c.use(x)
+	case *ast.CreateDatabaseStmt:
+		return c.createDatabase(x)
+	case *ast.AlterDatabaseStmt:
+		return c.alterDatabase(x)
+	case *ast.DropDatabaseStmt:
+		return c.dropDatabase(x)
+	case *ast.CreateTableStmt:
+		return c.createTable(x)
+	case *ast.CreateViewStmt:
+		return c.createView(x)
+	case *ast.DropTableStmt:
+		return c.dropTable(x)
+	case *ast.TruncateTableStmt:
+		return c.truncateTable(x)
+	case *ast.RenameTableStmt:
+		return c.renameTables(x)
+	case *ast.CreateIndexStmt:
+		return c.createIndex(x)
+	case *ast.DropIndexStmt:
+		return c.dropIndex(x)
+	case *ast.AlterTableStmt:
+		db, tbl, err := c.baseTable(x.Table)
+		if err != nil {
+			return err
+		}
+		return c.alterTable(db, tbl, x.Specs)
+	}
+	return nil
+}
+
+// builder returns a Builder of the tables of the database.
+func (c *Catalog) builder(db *model.DBInfo) *Builder {
+	return &Builder{
+		Charset:        db.Charset,
+		Collation:      db.Collate,
+		ClusteredIndex: c.ClusteredIndex,
+		InfoSchema:     c,
+		CurrentDB:      c.currentDB,
+		GenID:          c.genID,
+	}
+}
+
+// schemaName returns the database of a table name, which is the current
+// database if the name is not qualified.
+func (c *Catalog) schemaName(tn *ast.TableName) (model.CIStr, error) {
+	if tn.Schema.L != "" {
+		return tn.Schema, nil
+	}
+	if c.currentDB == "" {
+		return model.CIStr{}, ErrNoDB.GenWithStackByArgs()
+	}
+	return model.NewCIStr(c.currentDB), nil
+}
+
+// schemaOf returns the database of a table name.
+func (c *Catalog) schemaOf(tn *ast.TableName) (*model.DBInfo, error) {
+	schema, err := c.schemaName(tn)
+	if err != nil {
+		return nil, err
+	}
+	db, ok := c.SchemaByName(schema)
+	if !ok {
+		return nil, ErrDatabaseNotExists.GenWithStackByArgs(schema.O)
+	}
+	return db, nil
+}
+
+// table returns a table or a view.
+func (c *Catalog) table(tn *ast.TableName) (*model.DBInfo, *model.TableInfo, error) {
+	schema, err := c.schemaName(tn)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, ok := c.SchemaByName(schema)
+	if ok {
+		if _, tbl := findTable(db, tn.Name); tbl != nil {
+			return db, tbl, nil
+		}
+	}
+	return nil, nil, ErrTableNotExists.GenWithStackByArgs(schema.O, tn.Name.O)
+}
+
+// baseTable returns a table which is not a view.
+func (c *Catalog) baseTable(tn *ast.TableName) (*model.DBInfo, *model.TableInfo, error) {
+	db, tbl, err := c.table(tn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tbl.IsView() {
+		return nil, nil, ErrWrongObject.GenWithStackByArgs(db.Name.O, tbl.Name.O, "BASE TABLE")
+	}
+	return db, tbl, nil
+}
+
+// findTable returns a table of the database and its index in DBInfo.Tables.
+func findTable(db *model.DBInfo, name model.CIStr) (int, *model.TableInfo) {
+	for i, tbl := range db.Tables {
+		if tbl.Name.L == name.L {
+			return i, tbl
+		}
+	}
+	return -1, nil
+}
+
+// removeTable returns the tables without tbl in a new slice.
+func removeTable(tables []*model.TableInfo, tbl *model.TableInfo) []*model.TableInfo {
+	res := make([]*model.TableInfo, 0, len(tables))
+	for _, t := range tables {
+		if t != tbl {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func (c *Catalog) use(stmt *ast.UseStmt) error {
+	if _, ok := c.SchemaByName(model.NewCIStr(stmt.DBName)); !ok {
+		return ErrDatabaseNotExists.GenWithStackByArgs(stmt.DBName)
+	}
+	c.currentDB = stmt.DBName
+	return nil
+}
+
+// databaseCharset returns the charset and the collation of the options of
+// CREATE DATABASE or ALTER DATABASE, cs and co are the defaults.
+func databaseCharset(options []*ast.DatabaseOption, cs, co string) (string, string, error) {
+	var optCs, optCo string
+	for _, op := range options {
+		switch op.Tp {
+		case ast.DatabaseOptionCharset:
+			optCs = strings.ToLower(op.Value)
+		case ast.DatabaseOptionCollate:
+			optCo = strings.ToLower(op.Value)
+		}
+	}
+	if optCs == "" && optCo == "" {
+		return cs, co, nil
+	}
+	return resolveCharsetCollation(optCs, optCo)
+}
+
+func (c *Catalog) createDatabase(stmt *ast.CreateDatabaseStmt) error {
+	if _, ok := c.SchemaByName(stmt.Name); ok {
+		if stmt.IfNotExists {
+			return nil
+		}
+		return ErrDatabaseExists.GenWithStackByArgs(stmt.Name.O)
+	}
+	cs, co, err := databaseCharset(stmt.Options, c.Charset, c.Collation)
+	if err != nil {
+		return err
+	}
+	c.dbs = append(c.dbs, &model.DBInfo{
+		ID:      c.genID(),
+		Name:    stmt.Name,
+		Charset: cs,
+		Collate: co,
+		State:   model.StatePublic,
+	})
+	return nil
+}
+
+func (c *Catalog) alterDatabase(stmt *ast.AlterDatabaseStmt) error {
+	name := stmt.Name
+	if stmt.AlterDefaultDatabase {
+		if c.currentDB == "" {
+			return ErrNoDB.GenWithStackByArgs()
+		}
+		name = model.NewCIStr(c.currentDB)
+	}
+	db, ok := c.SchemaByName(name)
+	if !ok {
+		return ErrDatabaseNotExists.GenWithStackByArgs(name.O)
+	}
+	cs, co, err := databaseCharset(stmt.Options, db.Charset, db.Collate)
+	if err != nil {
+		return err
+	}
+	db.Charset, db.Collate = cs, co
+	return nil
+}
+
+func (c *Catalog) dropDatabase(stmt *ast.DropDatabaseStmt) error {
+	for i, db := range c.dbs {
+		if db.Name.L != stmt.Name.L {
+			continue
+		}
+		c.dbs = append(c.dbs[:i:i], c.dbs[i+1:]...)
+		if strings.EqualFold(c.currentDB, stmt.Name.O) {
+			c.currentDB = ""
+		}
+		return nil
+	}
+	if stmt.IfExists {
+		return nil
+	}
+	return ErrDatabaseDropExists.GenWithStackByArgs(stmt.Name.O)
+}
+
+func (c *Catalog) createTable(stmt *ast.CreateTableStmt) error {
+	db, err := c.schemaOf(stmt.Table)
+	if err != nil {
+		return err
+	}
+	if _, tbl := findTable(db, stmt.Table.Name); tbl != nil {
+		if stmt.IfNotExists {
+			return nil
+		}
+		return ErrTableExists.GenWithStackByArgs(stmt.Table.Name.O)
+	}
+	if stmt.ReferTable != nil {
+		if _, _, err := c.baseTable(stmt.ReferTable); err != nil {
+			return err
+		}
+	}
+	tbl, err := c.builder(db).Build(stmt)
+	if err != nil {
+		return err
+	}
+	db.Tables = append(db.Tables, tbl)
+	return nil
+}
+
+func (c *Catalog) createView(stmt *ast.CreateViewStmt) error {
+	db, err := c.schemaOf(stmt.ViewName)
+	if err != nil {
+		return err
+	}
+	if err := checkTableName(stmt.ViewName.Name.O); err != nil {
+		return err
+	}
+	i, old := findTable(db, stmt.ViewName.Name)
+	if old != nil {
+		if !old.IsView() {
+			return ErrWrongObject.GenWithStackByArgs(db.Name.O, old.Name.O, "VIEW")
+		}
+		if !stmt.OrReplace {
+			return ErrTableExists.GenWithStackByArgs(stmt.ViewName.Name.O)
+		}
+	}
+	sel, ok := stmt.Select.(ast.ResultSetNode)
+	if !ok {
+		return nil
+	}
+
+	tbl := &model.TableInfo{
+		Name:    stmt.ViewName.Name,
+		Charset: db.Charset,
+		Collate: db.Collate,
+		State:   model.StatePublic,
+		Version: model.CurrLatestTableInfoVersion,
+	}
+	tb := &tableBuilder{Builder: c.builder(db), tbl: tbl, hasDefault: make(map[string]bool), nullable: make(map[string]bool)}
+	cols, err := tb.selectColumns(sel)
+	if err != nil {
+		return err
+	}
+	if len(stmt.Cols) > 0 {
+		if len(stmt.Cols) != len(cols) {
+			return ErrViewWrongList.GenWithStackByArgs()
+		}
+		for i, name := range stmt.Cols {
+			if err := checkColumnName(name.O); err != nil {
+				return err
+			}
+			cols[i].Name = name
+		}
+	}
+	for _, col := range cols {
+		if err := tb.addColumn(col); err != nil {
+			return err
+		}
+	}
+	var sb strings.Builder
+	if err := stmt.Select.Restore(format.NewRestoreCtx(viewRestoreFlags, &sb)); err != nil {
+		return err
+	}
+	tbl.View = &model.ViewInfo{
+		Algorithm:   stmt.Algorithm,
+		Definer:     stmt.Definer,
+		Security:    stmt.Security,
+		SelectStmt:  sb.String(),
+		CheckOption: stmt.CheckOption,
+		Cols:        stmt.Cols,
+	}
+
+	if old != nil {
+		tbl.ID = old.ID
+		db.Tables[i] = tbl
+		return nil
+	}
+	tbl.ID = c.genID()
+	db.Tables = append(db.Tables, tbl)
+	return nil
+}
+
+// dropTable drops the tables or the views, nothing is dropped if one of them
+// does not exist.
+func (c *Catalog) dropTable(stmt *ast.DropTableStmt) error {
+	type dropped struct {
+		db  *model.DBInfo
+		tbl *model.TableInfo
+	}
+	var drops []dropped
+	var missing []string
+	for _, tn := range stmt.Tables {
+		schema, err := c.schemaName(tn)
+		if err != nil {
+			return err
+		}
+		var tbl *model.TableInfo
+		db, ok := c.SchemaByName(schema)
+		if ok {
+			_, tbl = findTable(db, tn.Name)
+		}
+		if tbl != nil && stmt.IsView && !tbl.IsView() {
+			return ErrWrongObject.GenWithStackByArgs(schema.O, tn.Name.O, "VIEW")
+		}
+		if tbl == nil || !stmt.IsView && tbl.IsView() {
+			missing = append(missing, schema.O+"."+tn.Name.O)
+			continue
+		}
+		drops = append(drops, dropped{db, tbl})
+	}
+	if len(missing) > 0 && !stmt.IfExists {
+		return ErrBadTable.GenWithStackByArgs(strings.Join(missing, ","))
+	}
+	for _, d := range drops {
+		d.db.Tables = removeTable(d.db.Tables, d.tbl)
+	}
+	return nil
+}
+
+// truncateTable replaces the table with an empty table of a new ID.
+func (c *Catalog) truncateTable(stmt *ast.TruncateTableStmt) error {
+	db, tbl, err := c.baseTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	nt := cloneTable(tbl)
+	nt.ID = c.genID()
+	nt.AutoIncID = 0
+	if nt.Partition != nil {
+		for i := range nt.Partition.Definitions {
+			nt.Partition.Definitions[i].ID = c.genID()
+		}
+	}
+	i, _ := findTable(db, tbl.Name)
+	db.Tables[i] = nt
+	return nil
+}
+
+// renameTables renames the tables one by one, nothing is renamed if one of
+// them fails.
+func (c *Catalog) renameTables(stmt *ast.RenameTableStmt) error {
+	saved := make([][]*model.TableInfo, len(c.dbs))
+	for i, db := range c.dbs {
+		saved[i] = db.Tables
+	}
+	for _, t := range stmt.TableToTables {
+		if err := c.renameTable(t.OldTable, t.NewTable); err != nil {
+			for i, db := range c.dbs {
+				db.Tables = saved[i]
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) renameTable(oldName, newName *ast.TableName) error {
+	oldDB, tbl, err := c.table(oldName)
+	if err != nil {
+		return err
+	}
+	newDB, err := c.schemaOf(newName)
+	if err != nil {
+		return err
+	}
+	if err := checkTableName(newName.Name.O); err != nil {
+		return err
+	}
+	if _, t := findTable(newDB, newName.Name); t != nil {
+		return ErrTableExists.GenWithStackByArgs(newName.Name.O)
+	}
+	nt := cloneTable(tbl)
+	setTableName(nt, newName.Name)
+	if newDB != oldDB {
+		keepRefSchemas(nt, oldDB.Name)
+	}
+	oldDB.Tables = removeTable(oldDB.Tables, tbl)
+	newDB.Tables = append(newDB.Tables, nt)
+	return nil
+}
+
+func (c *Catalog) createIndex(stmt *ast.CreateIndexStmt) error {
+	db, tbl, err := c.baseTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	if stmt.IfNotExists && tbl.FindIndexByName(strings.ToLower(stmt.IndexName)) != nil {
+		return nil
+	}
+	return c.alterTable(db, tbl, []*ast.AlterTableSpec{createIndexSpec(stmt)})
+}
+
+// createIndexSpec returns the ALTER TABLE spec of CREATE INDEX.
+func createIndexSpec(stmt *ast.CreateIndexStmt) *ast.AlterTableSpec {
+	tp := ast.ConstraintIndex
+	switch stmt.KeyType {
+	case ast.IndexKeyTypeUnique:
+		tp = ast.ConstraintUniq
+	case ast.IndexKeyTypeFullText:
+		tp = ast.ConstraintFulltext
+	}
+	return &ast.AlterTableSpec{
+		Tp: ast.AlterTableAddConstraint,
+		Constraint: &ast.Constraint{
+			Tp:     tp,
+			Name:   stmt.IndexName,
+			Keys:   stmt.IndexPartSpecifications,
+			Option: stmt.IndexOption,
+		},
+	}
+}
+
+func (c *Catalog) dropIndex(stmt *ast.DropIndexStmt) error {
+	db, tbl, err := c.baseTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	return c.alterTable(db, tbl, []*ast.AlterTableSpec{dropIndexSpec(stmt)})
+}
+
+// dropIndexSpec returns the ALTER TABLE spec of DROP INDEX.
+func dropIndexSpec(stmt *ast.DropIndexStmt) *ast.AlterTableSpec {
+	return &ast.AlterTableSpec{
+		Tp:       ast.AlterTableDropIndex,
+		Name:     stmt.IndexName,
+		IfExists: stmt.IfExists,
+	}
+}
+
+// alterTable applies the specs to a copy of the table, which replaces the
+// table if all the specs succeed.
+func (c *Catalog) alterTable(db *model.DBInfo, tbl *model.TableInfo, specs []*ast.AlterTableSpec) error {
+	nt := cloneTable(tbl)
+	tb := &tableBuilder{Builder: c.builder(db), tbl: nt, hasDefault: make(map[string]bool), nullable: make(map[string]bool)}
+	var newName *ast.TableName
+	for _, spec := range specs {
+		if spec.Tp == ast.AlterTableRenameTable {
+			newName = spec.NewTable
+			continue
+		}
+		if err := tb.alter(spec); err != nil {
+			return err
+		}
+	}
+	if err := tb.checkTable(); err != nil {
+		return err
+	}
+
+	newDB := db
+	if newName != nil {
+		var err error
+		if newDB, err = c.schemaOf(newName); err != nil {
+			return err
+		}
+		if err := checkTableName(newName.Name.O); err != nil {
+			return err
+		}
+		if _, t := findTable(newDB, newName.Name); t != nil && t != tbl {
+			return ErrTableExists.GenWithStackByArgs(newName.Name.O)
+		}
+		setTableName(nt, newName.Name)
+	}
+	if newDB == db {
+		i, _ := findTable(db, tbl.Name)
+		db.Tables[i] = nt
+		return nil
+	}
+	keepRefSchemas(nt, db.Name)
+	db.Tables = removeTable(db.Tables, tbl)
+	newDB.Tables = append(newDB.Tables, nt)
+	return nil
+}
+
+// keepRefSchemas sets the database of the tables referenced by the foreign
+// keys in the database of a table, which is moved out of the database.
+func keepRefSchemas(tbl *model.TableInfo, db model.CIStr) {
+	for _, fk := range tbl.ForeignKeys {
+		if fk.RefSchema.L == "" {
+			fk.RefSchema = db
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	. "github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/terror"
+	"github.com/stretchr/testify/require"
+)
+
+func apply(t *testing.T, c *Catalog, sql string) error {
+	stmts, _, err := parser.New().Parse(sql, "", "")
+	require.NoError(t, err, sql)
+	for _, stmt := range stmts {
+		if err := c.Apply(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mustApply(t *testing.T, c *Catalog, sql string) {
+	require.NoError(t, apply(t, c, sql), sql)
+}
+
+func mustTable(t *testing.T, c *Catalog, schema, table string) *model.TableInfo {
+	tbl, ok := c.TableByName(model.NewCIStr(schema), model.NewCIStr(table))
+	require.True(t, ok, "%s.%s", schema, table)
+	return tbl
+}
+
+func tableNames(c *Catalog, schema string) []string {
+	db, _ := c.SchemaByName(model.NewCIStr(schema))
+	names := make([]string, 0, len(db.Tables))
+	for _, tbl := range db.Tables {
+		names = append(names, tbl.Name.O)
+	}
+	return names
+}
+
+func TestCatalogStatements(t *testing.T) {
+	c := NewCatalog()
+	mustApply(t, c, "create database d1 charset latin1; create database d2; use d1")
+	require.Equal(t, "d1", c.CurrentDB())
+	require.Len(t, c.Schemas(), 2)
+
+	mustApply(t, c, "create table t (id int primary key, a varchar(10), key ka (a), constraint ck check (id > 0))")
+	tbl := mustTable(t, c, "d1", "t")
+	require.Equal(t, "latin1", tbl.Charset)
+
+	mustApply(t, c, "create table t2 like t; create table d2.s as select id, a, 1 as n from t")
+	like := mustTable(t, c, "d1", "t2")
+	require.NotEqual(t, tbl.ID, like.ID)
+	require.Equal(t, "t2", like.Constraints[0].Table.O)
+	require.Equal(t, "t", tbl.Constraints[0].Table.O)
+	sel := mustTable(t, c, "d2", "s")
+	require.Equal(t, "utf8mb4", sel.Charset)
+	require.Len(t, sel.Columns, 3)
+
+	mustApply(t, c, "create view v (x, y) as select id, a from t where id > 1")
+	v := mustTable(t, c, "d1", "v")
+	require.True(t, v.IsView())
+	require.Equal(t, "x", v.Columns[0].Name.O)
+	require.Equal(t, "SELECT `id`,`a` FROM `t` WHERE `id` > 1", v.View.SelectStmt)
+	mustApply(t, c, "create or replace view v as select a from t")
+	require.Equal(t, v.ID, mustTable(t, c, "d1", "v").ID)
+	require.Len(t, mustTable(t, c, "d1", "v").Columns, 1)
+
+	mustApply(t, c, "truncate table t")
+	require.NotEqual(t, tbl.ID, mustTable(t, c, "d1", "t").ID)
+
+	mustApply(t, c, "rename table t to d2.t, t2 to t3")
+	require.Equal(t, []string{"v", "t3"}, tableNames(c, "d1"))
+	require.Equal(t, []string{"s", "t"}, tableNames(c, "d2"))
+	require.Equal(t, "t", mustTable(t, c, "d2", "t").Indices[0].Table.O)
+
+	mustApply(t, c, "create index kb on d2.t (id, a); drop index ka on d2.t; create index if not exists kb on d2.t (a)")
+	require.Equal(t, "kb(id,a)", indexStrings(mustTable(t, c, "d2", "t")))
+
+	err := apply(t, c, "rename table t3 to t4, missing to t5")
+	require.True(t, terror.ErrorEqual(ErrTableNotExists, err), "%v", err)
+	require.Equal(t, []string{"v", "t3"}, tableNames(c, "d1"))
+
+	err = apply(t, c, "drop table t3, x, y")
+	require.True(t, terror.ErrorEqual(ErrBadTable, err), "%v", err)
+	require.EqualError(t, err, "[schema:1051]Unknown table 'd1.x,d1.y'")
+	require.Equal(t, []string{"v", "t3"}, tableNames(c, "d1"))
+	mustApply(t, c, "drop table if exists t3, x; drop view v")
+	require.Empty(t, tableNames(c, "d1"))
+
+	mustApply(t, c, "alter database d2 collate utf8mb4_general_ci; drop database d1")
+	require.Empty(t, c.CurrentDB())
+	d2, _ := c.SchemaByName(model.NewCIStr("d2"))
+	require.Equal(t, "utf8mb4_general_ci", d2.Collate)
+}
+
+func TestCatalogAlterTable(t *testing.T) {
+	c := NewCatalog()
+	mustApply(t, c, "create database d; use d; create table p (id int primary key)")
+	mustApply(t, c, "create table t (id int primary key, a int, b varchar(10), key ka (a, b))")
+	old := mustTable(t, c, "d", "t")
+
+	mustApply(t, c, "alter table t add column c int not null after id, add column d int first, add unique key uc (c)")
+	mustApply(t, c, "alter table t drop column b, modify a bigint default 1, change c c2 int, rename column d to d2")
+	mustApply(t, c, "alter table t add constraint fk foreign key (c2) references p (id), add constraint ck check (a > 0)")
+	mustApply(t, c, "alter table t alter column d2 set default 5, rename index ka to ka2, alter index uc invisible, comment 'x'")
+	mustApply(t, c, "alter table t alter check ck not enforced, drop primary key, add primary key (id, c2)")
+
+	tbl := mustTable(t, c, "d", "t")
+	show, err := ShowCreateTable(tbl, 0)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `t` (\n"+
+		"  `d2` int(11) DEFAULT '5',\n"+
+		"  `id` int(11) NOT NULL,\n"+
+		"  `c2` int(11) NOT NULL,\n"+
+		"  `a` bigint(20) DEFAULT '1',\n"+
+		"  PRIMARY KEY (`id`,`c2`) NONCLUSTERED,\n"+
+		"  KEY `ka2` (`a`),\n"+
+		"  UNIQUE KEY `uc` (`c2`) /*!80000 INVISIBLE */,\n"+
+		"  CONSTRAINT `fk` FOREIGN KEY (`c2`) REFERENCES `p` (`id`),\n"+
+		"  CONSTRAINT `ck` CHECK ((`a` > 0)) /*!80016 NOT ENFORCED */\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='x'", show)
+	for i, col := range tbl.Columns {
+		require.Equal(t, i, col.Offset)
+	}
+
+	// The table of the previous version is not changed.
+	require.Len(t, old.Columns, 3)
+	require.Equal(t, "ka(a,b)", indexStrings(old))
+
+	mustApply(t, c, "alter table t rename to d.t2")
+	require.Equal(t, []string{"p", "t2"}, tableNames(c, "d"))
+
+	mustApply(t, c, "create table r (a int, b int) partition by range (a) (partition p0 values less than (10))")
+	mustApply(t, c, "alter table r add partition (partition p1 values less than (20), partition p2 values less than maxvalue)")
+	mustApply(t, c, "alter table r drop partition p1; alter table r truncate partition p0")
+	pi := mustTable(t, c, "d", "r").Partition
+	require.Len(t, pi.Definitions, 2)
+	require.Equal(t, "p2", pi.Definitions[1].Name.O)
+	mustApply(t, c, "alter table r remove partitioning; alter table r partition by hash (b) partitions 4; alter table r coalesce partition 2")
+	pi = mustTable(t, c, "d", "r").Partition
+	require.Equal(t, model.PartitionTypeHash, pi.Type)
+	require.Len(t, pi.Definitions, 2)
+	show, err = ShowCreateTable(mustTable(t, c, "d", "r"), format.RestoreTiDBSpecialComment)
+	require.NoError(t, err)
+	require.Contains(t, show, "PARTITION BY HASH (`b`) PARTITIONS 2")
+}
+
+func TestCatalogForeignKeySchema(t *testing.T) {
+	c := NewCatalog()
+	mustApply(t, c, "create database d1; create database d2; use d1; create table p (id int primary key)")
+	mustApply(t, c, "create table d2.c (a int, b int, foreign key fa (a) references d1.p (id), foreign key fb (b) references d2.c (a))")
+	mustApply(t, c, "create table r (a int, foreign key fr (a) references p (id)); rename table r to d2.r")
+
+	show, err := ShowCreateTable(mustTable(t, c, "d2", "c"), 0)
+	require.NoError(t, err)
+	require.Contains(t, show, "CONSTRAINT `fa` FOREIGN KEY (`a`) REFERENCES `d1`.`p` (`id`)")
+	require.Contains(t, show, "CONSTRAINT `fb` FOREIGN KEY (`b`) REFERENCES `d2`.`c` (`a`)")
+	// The referenced table is kept when the table is moved to another database.
+	show, err = ShowCreateTable(mustTable(t, c, "d2", "r"), 0)
+	require.NoError(t, err)
+	require.Contains(t, show, "CONSTRAINT `fr` FOREIGN KEY (`a`) REFERENCES `d1`.`p` (`id`)")
+}
+
+func TestCatalogErrors(t *testing.T) {
+	setup := "create database d; use d; create table p (id int primary key);" +
+		"create table t (id int primary key, a int, b int, c int as (b + 1), d int, e int, f varchar(10)," +
+		" key ka (a), key kf (f), key ((e + 1)), constraint fk foreign key (d) references p (id), constraint ck check (a < f));" +
+		"create table r (a int, b int) partition by range (a) (partition p0 values less than (10));" +
+		"create table l (a int) partition by list (a) (partition p0 values in (1, 2));" +
+		"create view v as select id from t"
+	cases := []struct {
+		sql string
+		err *terror.Error
+	}{
+		{"create database d", ErrDatabaseExists},
+		{"drop database x", ErrDatabaseDropExists},
+		{"use x", ErrDatabaseNotExists},
+		{"create table x.t (a int)", ErrDatabaseNotExists},
+		{"create table t (a int)", ErrTableExists},
+		{"create table x like missing", ErrTableNotExists},
+		{"create table x like v", ErrWrongObject},
+		{"create view t as select 1", ErrWrongObject},
+		{"create view v as select 1", ErrTableExists},
+		{"create view w (a, b) as select 1", ErrViewWrongList},
+		{"create view w as select id, id from t", ErrDupFieldName},
+		{"drop view t", ErrWrongObject},
+		{"drop table v", ErrBadTable},
+		{"truncate table missing", ErrTableNotExists},
+		{"alter table v add column x int", ErrWrongObject},
+		{"rename table t to p", ErrTableExists},
+		{"alter table t rename to p", ErrTableExists},
+		{"alter table t add column a int", ErrDupFieldName},
+		{"alter table t add column x int after missing", ErrBadField},
+		{"alter table t add column x int auto_increment", ErrWrongAutoKey},
+		{"alter table t add primary key (a)", ErrMultiplePriKey},
+		{"alter table t add index ka (b)", ErrDupKeyName},
+		{"alter table t add index (missing)", ErrKeyColumnDoesNotExits},
+		{"alter table t drop column missing", ErrCantDropFieldOrKey},
+		{"alter table t drop column b", ErrDependentByGeneratedColumn},
+		{"alter table t drop column e", ErrDependentByFunctionalIndex},
+		{"alter table t drop column d", ErrFkColumnCannotDrop},
+		{"alter table t drop column f", ErrDependentByCheckConstraint},
+		{"alter table t rename column a to x", ErrDependentByCheckConstraint},
+		{"alter table r drop column a", ErrDependentByPartitionFunctional},
+		{"create table one (a int); alter table one drop column a", ErrCantRemoveAllFields},
+		{"alter table t modify missing int", ErrBadField},
+		{"alter table t change a b int", ErrDupFieldName},
+		{"alter table t modify d bigint", ErrFkColumnCannotChange},
+		{"alter table t modify id int null", ErrPrimaryCantHaveNull},
+		{"alter table t modify f text", ErrBlobKeyWithoutLength},
+		{"alter table t alter column missing set default 1", ErrBadField},
+		{"alter table t alter column c set default 1", ErrUnsupportedOnGeneratedColumn},
+		{"alter table t drop index missing", ErrCantDropFieldOrKey},
+		{"alter table t drop index fk", ErrDropIndexFk},
+		{"alter table t drop foreign key missing", ErrCantDropFieldOrKey},
+		{"alter table t drop check missing", ErrCheckConstraintNotFound},
+		{"alter table t alter check missing enforced", ErrCheckConstraintNotFound},
+		{"alter table t rename index missing to x", ErrKeyDoesNotExist},
+		{"alter table t rename index ka to fk", ErrDupKeyName},
+		{"alter table t alter index missing invisible", ErrKeyDoesNotExist},
+		{"drop index missing on t", ErrCantDropFieldOrKey},
+		{"create index ka on t (b)", ErrDupKeyName},
+		{"alter table t add partition (partition p1 values less than (1))", ErrPartitionMgmtOnNonpartitioned},
+		{"alter table r add partition (partition p0 values less than (20))", ErrSameNamePartition},
+		{"alter table r add partition (partition p1 values less than (5))", ErrRangeNotIncreasing},
+		{"alter table l add partition (partition p1 values in (2, 3))", ErrMultipleDefConstInListPart},
+		{"alter table r drop partition p9", ErrDropPartitionNonExistent},
+		{"alter table r drop partition p0", ErrDropLastPartition},
+		{"alter table r coalesce partition 1", ErrCoalesceOnlyOnHashPartition},
+		{"alter table r truncate partition p9", ErrDropPartitionNonExistent},
+		{"alter table r add unique key (a), add unique key (b)", ErrUniqueKeyNeedAllFieldsInPf},
+	}
+	for _, cs := range cases {
+		c := NewCatalog()
+		mustApply(t, c, setup)
+		err := apply(t, c, cs.sql)
+		require.Error(t, err, cs.sql)
+		require.True(t, terror.ErrorEqual(cs.err, err), "%s: %v", cs.sql, err)
+	}
+
+	c := NewCatalog()
+	err := apply(t, c, "create table t (a int)")
+	require.True(t, terror.ErrorEqual(ErrNoDB, err), "%v", err)
+}
@@ -0,0 +1,952 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/opcode"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// isStringType returns whether the type has a charset and a collation.
+func isStringType(tp byte) bool {
+	switch tp {
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString,
+		mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob,
+		mysql.TypeEnum, mysql.TypeSet:
+		return true
+	}
+	return false
+}
+
+// maxLenOf returns the maximum bytes of a character of the charset.
+func maxLenOf(cs string) int {
+	if info, err := charset.GetCharsetInfo(cs); err == nil {
+		return info.Maxlen
+	}
+	return 1
+}
+
+// buildColumn converts a column definition to a ColumnInfo, the PRIMARY KEY,
+// UNIQUE, REFERENCES and CHECK of the column are returned as the constraints
+// of the table.
+func (tb *tableBuilder) buildColumn(def *ast.ColumnDef) (*model.ColumnInfo, []*ast.Constraint, error) {
+	name := def.Name.Name
+	if err := checkColumnName(name.O); err != nil {
+		return nil, nil, err
+	}
+	col := &model.ColumnInfo{
+		Name:      name,
+		FieldType: *def.Tp.Clone(),
+		State:     model.StatePublic,
+		Version:   model.CurrLatestColumnInfoVersion,
+	}
+	var collates []string
+	for _, opt := range def.Options {
+		if opt.Tp == ast.ColumnOptionCollate {
+			collates = append(collates, strings.ToLower(opt.StrValue))
+		}
+	}
+	if err := tb.setFieldType(col, collates); err != nil {
+		return nil, nil, err
+	}
+
+	var constraints []*ast.Constraint
+	key := []*ast.IndexPartSpecification{{Column: def.Name, Length: types.UnspecifiedLength}}
+	for _, opt := range def.Options {
+		switch opt.Tp {
+		case ast.ColumnOptionNotNull:
+			col.AddFlag(mysql.NotNullFlag)
+		case ast.ColumnOptionNull:
+			col.DelFlag(mysql.NotNullFlag)
+			tb.nullable[name.L] = true
+		case ast.ColumnOptionAutoIncrement:
+			col.AddFlag(mysql.AutoIncrementFlag | mysql.NotNullFlag)
+		case ast.ColumnOptionPrimaryKey:
+			col.AddFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+			constraints = append(constraints, &ast.Constraint{
+				Tp:     ast.ConstraintPrimaryKey,
+				Keys:   key,
+				Option: &ast.IndexOption{PrimaryKeyTp: opt.PrimaryKeyTp},
+			})
+		case ast.ColumnOptionUniqKey:
+			constraints = append(constraints, &ast.Constraint{Tp: ast.ConstraintUniqKey, Keys: key})
+		case ast.ColumnOptionDefaultValue:
+			if err := tb.setDefaultValue(col, opt.Expr); err != nil {
+				return nil, nil, err
+			}
+			tb.hasDefault[name.L] = true
+		case ast.ColumnOptionOnUpdate:
+			if !isCurrentTimestamp(opt.Expr, col) {
+				return nil, nil, ErrInvalidOnUpdate.GenWithStackByArgs(name.O)
+			}
+			col.AddFlag(mysql.OnUpdateNowFlag)
+		case ast.ColumnOptionComment:
+			comment := ""
+			if v, ok := opt.Expr.(ast.ValueExpr); ok {
+				comment = v.GetString()
+			}
+			if utf8.RuneCountInString(comment) > maxColumnCommentLength {
+				return nil, nil, ErrTooLongFieldComment.GenWithStackByArgs(name.O, maxColumnCommentLength)
+			}
+			col.Comment = comment
+		case ast.ColumnOptionGenerated:
+			expr, err := restoreExpr(opt.Expr, exprRestoreFlags)
+			if err != nil {
+				return nil, nil, err
+			}
+			col.GeneratedExprString = expr
+			col.GeneratedStored = opt.Stored
+			col.Dependences = make(map[string]struct{})
+			for _, dep := range columnNames(opt.Expr) {
+				col.Dependences[dep] = struct{}{}
+			}
+		case ast.ColumnOptionReference:
+			constraints = append(constraints, &ast.Constraint{Tp: ast.ConstraintForeignKey, Keys: key, Refer: opt.Refer})
+		case ast.ColumnOptionCheck:
+			constraints = append(constraints, &ast.Constraint{
+				Tp:       ast.ConstraintCheck,
+				Name:     opt.ConstraintName,
+				Expr:     opt.Expr,
+				Enforced: opt.Enforced,
+				InColumn: true,
+			})
+		case ast.ColumnOptionAutoRandom:
+			bits := opt.AutoRandomBitLength
+			if bits == types.UnspecifiedLength || bits == 0 {
+				bits = 5
+			}
+			tb.tbl.AutoRandomBits = uint64(bits)
+		}
+	}
+	if mysql.HasPriKeyFlag(col.GetFlag()) && tb.nullable[name.L] {
+		return nil, nil, ErrPrimaryCantHaveNull.GenWithStackByArgs()
+	}
+	if col.IsGenerated() {
+		for _, opt := range def.Options {
+			switch opt.Tp {
+			case ast.ColumnOptionDefaultValue:
+				return nil, nil, ErrUnsupportedOnGeneratedColumn.GenWithStackByArgs("DEFAULT")
+			case ast.ColumnOptionAutoIncrement:
+				return nil, nil, ErrUnsupportedOnGeneratedColumn.GenWithStackByArgs("AUTO_INCREMENT")
+			case ast.ColumnOptionOnUpdate:
+				return nil, nil, ErrUnsupportedOnGeneratedColumn.GenWithStackByArgs("ON UPDATE")
+			}
+		}
+	}
+	if mysql.HasAutoIncrementFlag(col.GetFlag()) && tb.hasDefault[name.L] {
+		return nil, nil, ErrInvalidDefault.GenWithStackByArgs(name.O)
+	}
+	if tb.hasDefault[name.L] && col.GetDefaultValue() == nil && !col.DefaultIsExpr && mysql.HasNotNullFlag(col.GetFlag()) {
+		return nil, nil, ErrInvalidDefault.GenWithStackByArgs(name.O)
+	}
+	return col, constraints, nil
+}
+
+// setFieldType completes the type of a column: the charset and the collation
+// are derived from the table, and the unspecified length and decimal get
+// their defaults.
+func (tb *tableBuilder) setFieldType(col *model.ColumnInfo, collates []string) error {
+	ft := &col.FieldType
+	name := col.Name.O
+	if !isStringType(ft.GetType()) {
+		ft.SetCharset(charset.CharsetBin)
+		ft.SetCollate(charset.CollationBin)
+		if ft.GetType() != mysql.TypeBit {
+			ft.AddFlag(mysql.BinaryFlag)
+		}
+	} else if err := tb.setCharsetCollation(ft, collates); err != nil {
+		return err
+	}
+
+	if mysql.HasZerofillFlag(ft.GetFlag()) {
+		ft.AddFlag(mysql.UnsignedFlag)
+	}
+	tp := ft.GetType()
+	if tp == mysql.TypeBlob && ft.GetFlen() != types.UnspecifiedLength {
+		// TEXT(n) and BLOB(n) are the smallest type for n characters.
+		ft.SetType(blobTypeOf(ft.GetFlen() * maxLenOf(ft.GetCharset())))
+		ft.SetFlen(types.UnspecifiedLength)
+		tp = ft.GetType()
+	}
+	defaultFlen, defaultDecimal := mysql.GetDefaultFieldLengthAndDecimal(tp)
+	if ft.GetFlen() == types.UnspecifiedLength {
+		ft.SetFlen(defaultFlen)
+		if mysql.HasUnsignedFlag(ft.GetFlag()) && tp != mysql.TypeLonglong && mysql.IsIntegerType(tp) {
+			ft.SetFlen(defaultFlen - 1)
+		}
+	}
+	if ft.GetDecimal() == types.UnspecifiedLength {
+		ft.SetDecimal(defaultDecimal)
+	}
+	return checkFieldType(name, ft)
+}
+
+// setCharsetCollation sets the charset and the collation of a string type.
+func (tb *tableBuilder) setCharsetCollation(ft *types.FieldType, collates []string) error {
+	cs, co := ft.GetCharset(), ft.GetCollate()
+	for _, c := range collates {
+		coll, err := charset.GetCollationByName(c)
+		if err != nil {
+			return ErrUnknownCollation.GenWithStackByArgs(c)
+		}
+		if cs == "" {
+			cs = coll.CharsetName
+		} else if coll.CharsetName != cs {
+			return ErrCollationCharsetMismatch.GenWithStackByArgs(coll.Name, cs)
+		}
+		co = coll.Name
+	}
+	switch {
+	case cs == "":
+		cs, co = tb.tbl.Charset, tb.tbl.Collate
+	case co == "":
+		var err error
+		if cs, co, err = resolveCharsetCollation(cs, ""); err != nil {
+			return err
+		}
+	}
+	if cs == charset.CharsetBin {
+		ft.AddFlag(mysql.BinaryFlag)
+		co = charset.CollationBin
+	} else if mysql.HasBinaryFlag(ft.GetFlag()) {
+		// The BINARY attribute is the binary collation of the charset.
+		if _, err := charset.GetCollationByName(cs + "_bin"); err == nil {
+			co = cs + "_bin"
+		}
+		ft.DelFlag(mysql.BinaryFlag)
+	}
+	ft.SetCharset(cs)
+	ft.SetCollate(co)
+	return nil
+}
+
+// blobTypeOf returns the smallest BLOB type of n bytes.
+func blobTypeOf(n int) byte {
+	switch {
+	case n < 1<<8:
+		return mysql.TypeTinyBlob
+	case n < 1<<16:
+		return mysql.TypeBlob
+	case n < 1<<24:
+		return mysql.TypeMediumBlob
+	}
+	return mysql.TypeLongBlob
+}
+
+// checkFieldType checks the lengths of a type like MySQL.
+func checkFieldType(name string, ft *types.FieldType) error {
+	flen, decimal := ft.GetFlen(), ft.GetDecimal()
+	switch tp := ft.GetType(); tp {
+	case mysql.TypeString:
+		if flen > mysql.MaxFieldCharLength {
+			return ErrTooBigFieldlength.GenWithStackByArgs(name, mysql.MaxFieldCharLength)
+		}
+	case mysql.TypeVarchar:
+		maxLen := maxLenOf(ft.GetCharset())
+		if flen*maxLen > mysql.MaxFieldVarCharLength {
+			return ErrTooBigFieldlength.GenWithStackByArgs(name, mysql.MaxFieldVarCharLength/maxLen)
+		}
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		if flen > mysql.MaxFloatingTypeWidth {
+			return ErrTooBigDisplaywidth.GenWithStackByArgs(name, mysql.MaxFloatingTypeWidth)
+		}
+	case mysql.TypeBit:
+		if flen > mysql.MaxBitDisplayWidth {
+			return ErrTooBigDisplaywidth.GenWithStackByArgs(name, mysql.MaxBitDisplayWidth)
+		}
+	case mysql.TypeNewDecimal:
+		if flen > mysql.MaxDecimalWidth {
+			return ErrTooBigPrecision.GenWithStackByArgs(flen, name, mysql.MaxDecimalWidth)
+		}
+		if decimal > mysql.MaxDecimalScale {
+			return ErrTooBigScale.GenWithStackByArgs(decimal, name, mysql.MaxDecimalScale)
+		}
+		if decimal > flen {
+			return ErrMBiggerThanD.GenWithStackByArgs(name)
+		}
+	case mysql.TypeFloat, mysql.TypeDouble:
+		if decimal == types.UnspecifiedLength {
+			if tp == mysql.TypeFloat && flen > mysql.MaxDoublePrecisionLength {
+				return ErrWrongFieldSpec.GenWithStackByArgs(name)
+			}
+			break
+		}
+		if decimal > mysql.MaxFloatingTypeScale {
+			return ErrTooBigScale.GenWithStackByArgs(decimal, name, mysql.MaxFloatingTypeScale)
+		}
+		if flen > mysql.MaxFloatingTypeWidth {
+			return ErrTooBigDisplaywidth.GenWithStackByArgs(name, mysql.MaxFloatingTypeWidth)
+		}
+		if decimal > flen {
+			return ErrMBiggerThanD.GenWithStackByArgs(name)
+		}
+	case mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDuration:
+		if decimal > maxFsp {
+			return ErrTooBigPrecision.GenWithStackByArgs(decimal, name, maxFsp)
+		}
+	case mysql.TypeEnum, mysql.TypeSet:
+		if tp == mysql.TypeSet && len(ft.GetElems()) > mysql.MaxTypeSetMembers {
+			return ErrTooBigSet.GenWithStackByArgs(name)
+		}
+		kind := "ENUM"
+		if tp == mysql.TypeSet {
+			kind = "SET"
+		}
+		seen := make(map[string]bool, len(ft.GetElems()))
+		for _, e := range ft.GetElems() {
+			key := e
+			if ft.GetCollate() != charset.CollationBin && !strings.HasSuffix(ft.GetCollate(), "_bin") {
+				key = strings.ToLower(e)
+			}
+			if seen[key] {
+				return ErrDuplicatedValueInType.GenWithStackByArgs(name, e, kind)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// isCurrentTimestamp returns whether an expression is CURRENT_TIMESTAMP with
+// the fractional seconds precision of a TIMESTAMP or DATETIME column.
+func isCurrentTimestamp(expr ast.ExprNode, col *model.ColumnInfo) bool {
+	fn, ok := expr.(*ast.FuncCallExpr)
+	if !ok || !isCurrentTimestampFunc(fn.FnName.L) {
+		return false
+	}
+	if tp := col.GetType(); tp != mysql.TypeTimestamp && tp != mysql.TypeDatetime {
+		return false
+	}
+	fsp := int64(0)
+	if len(fn.Args) > 0 {
+		v, ok := fn.Args[0].(ast.ValueExpr)
+		if !ok {
+			return false
+		}
+		switch x := v.GetValue().(type) {
+		case int64:
+			fsp = x
+		case uint64:
+			fsp = int64(x)
+		}
+	}
+	return fsp == int64(col.GetDecimal())
+}
+
+func isCurrentTimestampFunc(name string) bool {
+	switch name {
+	case ast.CurrentTimestamp, ast.Now, ast.LocalTime, ast.LocalTimestamp:
+		return true
+	}
+	return false
+}
+
+// setDefaultValue sets the default value of a column. The value is stored as
+// a string in the format of the column type like TiDB, nil is NULL.
+func (tb *tableBuilder) setDefaultValue(col *model.ColumnInfo, expr ast.ExprNode) error {
+	name := col.Name.O
+	if fn, ok := expr.(*ast.FuncCallExpr); ok {
+		if isCurrentTimestampFunc(fn.FnName.L) {
+			if !isCurrentTimestamp(fn, col) {
+				return ErrInvalidDefault.GenWithStackByArgs(name)
+			}
+			return col.SetDefaultValue(strings.ToUpper(ast.CurrentTimestamp))
+		}
+		// The other functions are expression defaults.
+		s, err := restoreExpr(fn, exprRestoreFlags)
+		if err != nil {
+			return err
+		}
+		col.DefaultIsExpr = true
+		return col.SetDefaultValue(s)
+	}
+	value, ok := literalValue(expr)
+	if !ok {
+		return ErrInvalidDefault.GenWithStackByArgs(name)
+	}
+	if value == nil {
+		return col.SetDefaultValue(nil)
+	}
+	switch tp := col.GetType(); {
+	case types.IsTypeBlob(tp), tp == mysql.TypeJSON, tp == mysql.TypeGeometry:
+		return ErrBlobCantHaveDefault.GenWithStackByArgs(name)
+	case tp == mysql.TypeBit:
+		return col.SetDefaultValue(bitValue(value))
+	}
+	s, ok := convertDefault(col, value)
+	if !ok {
+		return ErrInvalidDefault.GenWithStackByArgs(name)
+	}
+	return col.SetDefaultValue(s)
+}
+
+// binaryLiteral is a hexadecimal or bit literal of the parser driver.
+type binaryLiteral interface {
+	ToString() string
+}
+
+// literalValue returns the value of a literal, which is nil, a string, a
+// *big.Rat or a binaryLiteral.
+func literalValue(expr ast.ExprNode) (interface{}, bool) {
+	switch x := expr.(type) {
+	case *ast.UnaryOperationExpr:
+		v, ok := literalValue(x.V)
+		if !ok {
+			return nil, false
+		}
+		r, ok := v.(*big.Rat)
+		switch {
+		case !ok:
+			return nil, false
+		case x.Op == opcode.Minus:
+			return r.Neg(r), true
+		case x.Op == opcode.Plus:
+			return r, true
+		}
+		return nil, false
+	case ast.ValueExpr:
+		switch v := x.GetValue().(type) {
+		case nil:
+			return nil, true
+		case int64:
+			return new(big.Rat).SetInt64(v), true
+		case uint64:
+			return new(big.Rat).SetUint64(v), true
+		case float32:
+			return ratOf(strconv.FormatFloat(float64(v), 'g', -1, 32))
+		case float64:
+			return ratOf(strconv.FormatFloat(v, 'g', -1, 64))
+		case string:
+			return v, true
+		case []byte:
+			return string(v), true
+		case binaryLiteral:
+			return v, true
+		case fmt.Stringer:
+			return ratOf(v.String())
+		}
+	}
+	return nil, false
+}
+
+func ratOf(s string) (*big.Rat, bool) {
+	return new(big.Rat).SetString(s)
+}
+
+// bitValue returns the bytes of the default value of a BIT column.
+func bitValue(value interface{}) string {
+	switch v := value.(type) {
+	case binaryLiteral:
+		return v.ToString()
+	case *big.Rat:
+		if v.IsInt() {
+			b := v.Num().Bytes()
+			if len(b) == 0 {
+				b = []byte{0}
+			}
+			return string(b)
+		}
+	case string:
+		return v
+	}
+	return ""
+}
+
+// convertDefault converts a literal to the default value of a column type.
+func convertDefault(col *model.ColumnInfo, value interface{}) (string, bool) {
+	var s string
+	var num *big.Rat
+	switch v := value.(type) {
+	case *big.Rat:
+		num = v
+	case string:
+		s = v
+	case binaryLiteral:
+		s = v.ToString()
+	}
+	tp := col.GetType()
+	switch {
+	case isTemporalType(tp):
+		return temporalDefault(col, num, s)
+	case mysql.IsIntegerType(tp) && tp != mysql.TypeBit, tp == mysql.TypeNewDecimal,
+		tp == mysql.TypeFloat, tp == mysql.TypeDouble:
+		if num == nil {
+			var ok bool
+			if num, ok = ratOf(strings.TrimSpace(s)); !ok {
+				return "", false
+			}
+		}
+		if mysql.HasUnsignedFlag(col.GetFlag()) && num.Sign() < 0 {
+			return "", false
+		}
+		switch {
+		case tp == mysql.TypeNewDecimal:
+			return num.FloatString(col.GetDecimal()), true
+		case tp == mysql.TypeFloat || tp == mysql.TypeDouble:
+			if col.GetDecimal() != types.UnspecifiedLength {
+				return num.FloatString(col.GetDecimal()), true
+			}
+			f, _ := num.Float64()
+			return strconv.FormatFloat(f, 'f', -1, 64), true
+		}
+		v := num.FloatString(0)
+		if !inIntegerRange(col, v) {
+			return "", false
+		}
+		return v, true
+	case tp == mysql.TypeEnum:
+		return enumValue(col, num, s)
+	case tp == mysql.TypeSet:
+		return setValue(col, num, s)
+	}
+	if num != nil {
+		if num.IsInt() {
+			s = num.FloatString(0)
+		} else {
+			f, _ := num.Float64()
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	if types.IsTypeChar(tp) && col.GetFlen() != types.UnspecifiedLength && utf8.RuneCountInString(s) > col.GetFlen() {
+		return "", false
+	}
+	return s, true
+}
+
+// integerBits are the numbers of the bits of the integer types.
+var integerBits = map[byte]uint{
+	mysql.TypeTiny:     8,
+	mysql.TypeShort:    16,
+	mysql.TypeInt24:    24,
+	mysql.TypeLong:     32,
+	mysql.TypeLonglong: 64,
+}
+
+// inIntegerRange returns whether an integer is in the range of the integer
+// type of a column.
+func inIntegerRange(col *model.ColumnInfo, s string) bool {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return false
+	}
+	bits := integerBits[col.GetType()]
+	var low, high big.Int
+	if mysql.HasUnsignedFlag(col.GetFlag()) {
+		high.Lsh(big.NewInt(1), bits)
+	} else {
+		low.Neg(high.Lsh(big.NewInt(1), bits-1))
+	}
+	high.Sub(&high, big.NewInt(1))
+	return v.Cmp(&low) >= 0 && v.Cmp(&high) <= 0
+}
+
+// isTemporalType returns whether the type is a date, a time or a year.
+func isTemporalType(tp byte) bool {
+	switch tp {
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDuration, mysql.TypeYear:
+		return true
+	}
+	return false
+}
+
+// temporalDefault normalizes a default value of a DATE, DATETIME, TIMESTAMP,
+// TIME or YEAR column like SHOW CREATE TABLE of MySQL, the fractional seconds
+// are rounded to the fsp of the column. ok is false if the value is invalid.
+// The zero date is valid.
+func temporalDefault(col *model.ColumnInfo, num *big.Rat, s string) (string, bool) {
+	if num != nil {
+		if num.Sign() < 0 && col.GetType() != mysql.TypeDuration {
+			return "", false
+		}
+		s = num.FloatString(maxFsp)
+		if num.IsInt() {
+			s = num.FloatString(0)
+		}
+	}
+	s = strings.TrimSpace(s)
+	fsp := col.GetDecimal()
+	if fsp < 0 {
+		fsp = 0
+	}
+	switch col.GetType() {
+	case mysql.TypeYear:
+		return yearDefault(s, num != nil)
+	case mysql.TypeDuration:
+		return timeDefault(s, fsp)
+	}
+	t, zero, ok := parseDatetime(s)
+	if !ok {
+		return "", false
+	}
+	switch col.GetType() {
+	case mysql.TypeDate:
+		if zero {
+			return "0000-00-00", true
+		}
+		return t.Format("2006-01-02"), true
+	case mysql.TypeTimestamp:
+		if !zero {
+			t = t.Round(fspUnit(fsp))
+			if t.Before(minTimestamp) || t.After(maxTimestamp) {
+				return "", false
+			}
+		}
+	}
+	frac := ""
+	if fsp > 0 {
+		frac = "." + strings.Repeat("0", fsp)
+	}
+	if zero {
+		return "0000-00-00 00:00:00" + frac, true
+	}
+	return t.Round(fspUnit(fsp)).Format("2006-01-02 15:04:05" + frac), true
+}
+
+// The range of TIMESTAMP in UTC.
+var (
+	minTimestamp = time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC)
+	maxTimestamp = time.Date(2038, 1, 19, 3, 14, 7, 999999000, time.UTC)
+)
+
+// fspUnit returns the duration of the last fractional digit of a fsp.
+func fspUnit(fsp int) time.Duration {
+	d := time.Second
+	for i := 0; i < fsp; i++ {
+		d /= 10
+	}
+	return d
+}
+
+// parseDatetime parses a date or a datetime like '2020-01-02 03:04:05.6',
+// '2020/1/2', '20200102030405' or '200102'. The years of two digits are in
+// 1970 to 2069. zero is true for the zero date.
+func parseDatetime(s string) (t time.Time, zero bool, ok bool) {
+	var parts []int
+	var frac string
+	if isDigits(strings.SplitN(s, ".", 2)[0]) {
+		digits := s
+		if i := strings.IndexByte(s, '.'); i >= 0 {
+			digits, frac = s[:i], s[i+1:]
+		}
+		if digits == "0" {
+			return time.Time{}, true, frac == "" || strings.Trim(frac, "0") == ""
+		}
+		widths := map[int][]int{
+			6:  {2, 2, 2},
+			8:  {4, 2, 2},
+			12: {2, 2, 2, 2, 2, 2},
+			14: {4, 2, 2, 2, 2, 2},
+		}[len(digits)]
+		if widths == nil {
+			return time.Time{}, false, false
+		}
+		for _, w := range widths {
+			n, _ := strconv.Atoi(digits[:w])
+			parts = append(parts, n)
+			digits = digits[w:]
+		}
+		if widths[0] == 2 {
+			parts[0] = twoDigitYear(parts[0])
+		}
+	} else {
+		date, clock := s, ""
+		if i := strings.IndexAny(s, " T"); i >= 0 {
+			date, clock = s[:i], strings.TrimSpace(s[i+1:])
+		}
+		if i := strings.IndexByte(clock, '.'); i >= 0 {
+			clock, frac = clock[:i], clock[i+1:]
+		}
+		fields := strings.FieldsFunc(date, func(r rune) bool { return r < '0' || r > '9' })
+		if len(fields) != 3 || len(fields[0]) > 4 || !isDigits(date[:1]) {
+			return time.Time{}, false, false
+		}
+		if clock != "" {
+			clockFields := strings.Split(clock, ":")
+			if len(clockFields) > 3 {
+				return time.Time{}, false, false
+			}
+			fields = append(fields, clockFields...)
+		}
+		for _, f := range fields {
+			n, err := strconv.Atoi(f)
+			if err != nil || n < 0 {
+				return time.Time{}, false, false
+			}
+			parts = append(parts, n)
+		}
+		if len(fields[0]) <= 2 {
+			parts[0] = twoDigitYear(parts[0])
+		}
+	}
+	if frac != "" && !isDigits(frac) {
+		return time.Time{}, false, false
+	}
+	for len(parts) < 6 {
+		parts = append(parts, 0)
+	}
+	if parts[0] == 0 && parts[1] == 0 && parts[2] == 0 {
+		zero = parts[3] == 0 && parts[4] == 0 && parts[5] == 0 && strings.Trim(frac, "0") == ""
+		return time.Time{}, zero, zero
+	}
+	if parts[1] < 1 || parts[1] > 12 || parts[2] < 1 || parts[3] > 23 || parts[4] > 59 || parts[5] > 59 {
+		return time.Time{}, false, false
+	}
+	t = time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], fracNanos(frac), time.UTC)
+	if t.Day() != parts[2] {
+		// The day is not in the month.
+		return time.Time{}, false, false
+	}
+	return t, false, true
+}
+
+// timeDefault normalizes a TIME like '-1 02:03:04.5', '02:03', '020304' or
+// '4'.
+func timeDefault(s string, fsp int) (string, bool) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	var frac string
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+		if !isDigits(frac) {
+			return "", false
+		}
+	}
+	var days, h, m, sec int
+	var err error
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		if days, err = strconv.Atoi(s[:i]); err != nil {
+			return "", false
+		}
+		s = strings.TrimSpace(s[i+1:])
+	}
+	if strings.Contains(s, ":") {
+		fields := strings.Split(s, ":")
+		if len(fields) > 3 {
+			return "", false
+		}
+		values := make([]int, 3)
+		for i, f := range fields {
+			if !isDigits(f) {
+				return "", false
+			}
+			values[i], _ = strconv.Atoi(f)
+		}
+		h, m, sec = values[0], values[1], values[2]
+	} else {
+		if !isDigits(s) || days != 0 {
+			return "", false
+		}
+		n, _ := strconv.Atoi(s)
+		h, m, sec = n/10000, n/100%100, n%100
+	}
+	if m > 59 || sec > 59 {
+		return "", false
+	}
+	d := time.Duration(days*24+h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second +
+		time.Duration(fracNanos(frac))
+	d = d.Round(fspUnit(fsp))
+	if d > 838*time.Hour+59*time.Minute+59*time.Second {
+		return "", false
+	}
+	res := fmt.Sprintf("%02d:%02d:%02d", int(d/time.Hour), int(d/time.Minute%60), int(d/time.Second%60))
+	if fsp > 0 {
+		res += fmt.Sprintf(".%09d", int(d%time.Second))[:fsp+1]
+	}
+	if neg && d != 0 {
+		res = "-" + res
+	}
+	return res, true
+}
+
+// yearDefault normalizes a YEAR, the years of one or two digits are in 1970
+// to 2069, except the number 0 and '0000' which are the zero year.
+func yearDefault(s string, isNum bool) (string, bool) {
+	if !isDigits(s) {
+		return "", false
+	}
+	n, err := strconv.Atoi(s)
+	switch {
+	case err != nil:
+		return "", false
+	case n == 0 && (isNum || len(s) == 4):
+		return "0000", true
+	case len(s) <= 2:
+		n = twoDigitYear(n)
+	case n < 1901 || n > 2155:
+		return "", false
+	}
+	return strconv.Itoa(n), true
+}
+
+// twoDigitYear returns the year of two digits, 70 to 99 are in the 1900s.
+func twoDigitYear(n int) int {
+	if n >= 70 {
+		return 1900 + n
+	}
+	return 2000 + n
+}
+
+// fracNanos returns the nanoseconds of the digits of a fraction.
+func fracNanos(frac string) int {
+	if len(frac) > 9 {
+		frac = frac[:9]
+	}
+	n, _ := strconv.Atoi(frac + strings.Repeat("0", 9-len(frac)))
+	return n
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// enumValue returns the member of an ENUM by value or by index.
+func enumValue(col *model.ColumnInfo, num *big.Rat, s string) (string, bool) {
+	elems := col.GetElems()
+	if num != nil {
+		if !num.IsInt() || !num.Num().IsInt64() {
+			return "", false
+		}
+		i := num.Num().Int64()
+		if i < 1 || i > int64(len(elems)) {
+			return "", false
+		}
+		return elems[i-1], true
+	}
+	for _, e := range elems {
+		if strings.EqualFold(e, strings.TrimRight(s, " ")) {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// setValue returns the members of a SET by values or by bits.
+func setValue(col *model.ColumnInfo, num *big.Rat, s string) (string, bool) {
+	elems := col.GetElems()
+	var res []string
+	if num != nil {
+		if !num.IsInt() || num.Sign() < 0 || num.Num().BitLen() > len(elems) {
+			return "", false
+		}
+		for i, e := range elems {
+			if num.Num().Bit(i) == 1 {
+				res = append(res, e)
+			}
+		}
+		return strings.Join(res, ","), true
+	}
+	if s == "" {
+		return "", true
+	}
+	// The members are deduplicated and sorted in the order of the definition.
+	chosen := make([]bool, len(elems))
+	for _, v := range strings.Split(s, ",") {
+		found := false
+		for i, e := range elems {
+			if strings.EqualFold(e, v) {
+				chosen[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	for i, e := range elems {
+		if chosen[i] {
+			res = append(res, e)
+		}
+	}
+	return strings.Join(res, ","), true
+}
+
+// setNoDefaultValueFlag sets the NoDefaultValueFlag of a NOT NULL column
+// without DEFAULT like TiDB.
+func (tb *tableBuilder) setNoDefaultValueFlag(col *model.ColumnInfo) {
+	if tb.hasDefault[col.Name.L] || col.IsGenerated() || !mysql.HasNotNullFlag(col.GetFlag()) {
+		return
+	}
+	if !mysql.HasAutoIncrementFlag(col.GetFlag()) && col.GetType() != mysql.TypeTimestamp {
+		col.AddFlag(mysql.NoDefaultValueFlag)
+	}
+}
+
+// checkGeneratedColumns checks the columns the generated columns refer to.
+func (tb *tableBuilder) checkGeneratedColumns() error {
+	for _, col := range tb.tbl.Columns {
+		if !col.IsGenerated() {
+			continue
+		}
+		for dep := range col.Dependences {
+			ref := model.FindColumnInfo(tb.tbl.Columns, dep)
+			if ref == nil {
+				return ErrBadField.GenWithStackByArgs(dep, "generated column function")
+			}
+			if ref.IsGenerated() && ref.Offset >= col.Offset {
+				return ErrGeneratedColumnNonPrior.GenWithStackByArgs()
+			}
+			if mysql.HasAutoIncrementFlag(ref.GetFlag()) {
+				return ErrGeneratedColumnRefAutoInc.GenWithStackByArgs(col.Name.O)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAutoIncrement checks that there is at most one auto increment column,
+// which is the first column of an index.
+func (tb *tableBuilder) checkAutoIncrement() error {
+	var autoCol *model.ColumnInfo
+	for _, col := range tb.tbl.Columns {
+		if !mysql.HasAutoIncrementFlag(col.GetFlag()) {
+			continue
+		}
+		if autoCol != nil {
+			return ErrWrongAutoKey.GenWithStackByArgs()
+		}
+		autoCol = col
+	}
+	if autoCol == nil {
+		return nil
+	}
+	if tb.tbl.PKIsHandle && mysql.HasPriKeyFlag(autoCol.GetFlag()) {
+		return nil
+	}
+	for _, idx := range tb.tbl.Indices {
+		if idx.Columns[0].Name.L == autoCol.Name.L {
+			return nil
+		}
+	}
+	return ErrWrongAutoKey.GenWithStackByArgs()
+}
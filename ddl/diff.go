@@ -0,0 +1,699 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/pingcap/errors"
+)
+
+// DiffCreateTable returns the ALTER TABLE statement which changes the table of
+// the CREATE TABLE statement from into the table of to, or nil if the tables
+// are the same. See Diff.
+func DiffCreateTable(from, to *ast.CreateTableStmt) (*ast.AlterTableStmt, error) {
+	fromTbl, err := NewBuilder().Build(from)
+	if err != nil {
+		return nil, err
+	}
+	toTbl, err := NewBuilder().Build(to)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(fromTbl, toTbl)
+}
+
+// Diff returns the ALTER TABLE statement which changes the table from into the
+// table to, or nil if the tables are the same.
+//
+// The specs are ordered so that they can be applied one by one: the foreign
+// keys, the checks and the indexes are dropped first, then the columns are
+// renamed, dropped, added and modified in the column order of to, then the
+// indexes, the checks and the foreign keys are added, the table is renamed and
+// the partitioning is changed at last. If all the columns are replaced, the
+// last one is dropped after the columns are added, since a table can not have
+// no column. A column or an index is regarded as renamed if it is the only one
+// which has the same definition as a missing one. The changes of the range and
+// list partitions are ADD PARTITION or DROP PARTITION if possible, otherwise
+// the table is partitioned again.
+//
+// The statement is parsed from its text, so a driver of value expressions like
+// github.com/daiguadaidai/parser/test_driver must be imported.
+func Diff(from, to *model.TableInfo) (*ast.AlterTableStmt, error) {
+	d := &differ{from: from, to: to, renamed: make(map[string]string)}
+	if err := d.diff(); err != nil {
+		return nil, err
+	}
+	if len(d.specs) == 0 && d.partition == "" {
+		return nil, nil
+	}
+	sql := "ALTER TABLE " + d.name(from.Name.O) + " " + strings.Join(d.specs, ", ")
+	if d.partition != "" {
+		sql += " " + d.partition
+	}
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return stmt.(*ast.AlterTableStmt), nil
+}
+
+type differ struct {
+	from, to *model.TableInfo
+	// renamed maps the lower case names of the renamed columns of from to
+	// their names in to.
+	renamed   map[string]string
+	specs     []string
+	partition string
+}
+
+// write returns the text written by fn with a showWriter of the table.
+func (d *differ) write(tbl *model.TableInfo, fn func(w *showWriter) error) (string, error) {
+	var sb strings.Builder
+	ctx := format.NewRestoreCtx(showCreateFlags|format.RestoreTiDBSpecialComment, &sb)
+	if err := fn(&showWriter{ctx: ctx, tbl: tbl}); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (d *differ) name(name string) string {
+	s, _ := d.write(nil, func(w *showWriter) error {
+		w.ctx.WriteName(name)
+		return nil
+	})
+	return s
+}
+
+func (d *differ) add(spec string, args ...interface{}) {
+	d.specs = append(d.specs, fmt.Sprintf(spec, args...))
+}
+
+func (d *differ) diff() error {
+	if err := d.diffTableOptions(); err != nil {
+		return err
+	}
+	if err := d.diffColumnNames(); err != nil {
+		return err
+	}
+	fks, err := d.diffForeignKeys()
+	if err != nil {
+		return err
+	}
+	checks, err := d.diffChecks()
+	if err != nil {
+		return err
+	}
+	indexes, err := d.diffIndexes()
+	if err != nil {
+		return err
+	}
+	if err := d.diffColumns(); err != nil {
+		return err
+	}
+	d.specs = append(d.specs, indexes...)
+	d.specs = append(d.specs, checks...)
+	d.specs = append(d.specs, fks...)
+	if d.from.Name.O != d.to.Name.O {
+		d.add("RENAME TO %s", d.name(d.to.Name.O))
+	}
+	return d.diffPartition()
+}
+
+func (d *differ) diffTableOptions() error {
+	from, to := d.from, d.to
+	opts := &model.TableInfo{}
+	var zeros []string
+	if from.Charset != to.Charset || from.Collate != to.Collate {
+		opts.Charset, opts.Collate = to.Charset, to.Collate
+	}
+	if from.AutoIncID != to.AutoIncID && to.AutoIncID > 1 {
+		opts.AutoIncID = to.AutoIncID
+	}
+	if from.AutoIdCache != to.AutoIdCache {
+		opts.AutoIdCache = to.AutoIdCache
+	}
+	if from.AutoRandID != to.AutoRandID {
+		opts.AutoRandID = to.AutoRandID
+	}
+	if from.ShardRowIDBits != to.ShardRowIDBits || from.PreSplitRegions != to.PreSplitRegions {
+		if to.ShardRowIDBits == 0 {
+			zeros = append(zeros, "/*T! SHARD_ROW_ID_BITS=0 */")
+		}
+		opts.ShardRowIDBits, opts.PreSplitRegions = to.ShardRowIDBits, to.PreSplitRegions
+	}
+	if from.Compression != to.Compression {
+		if to.Compression == "" {
+			zeros = append(zeros, "COMPRESSION=''")
+		}
+		opts.Compression = to.Compression
+	}
+	if from.Comment != to.Comment {
+		if to.Comment == "" {
+			zeros = append(zeros, "COMMENT=''")
+		}
+		opts.Comment = to.Comment
+	}
+	if ref := to.PlacementPolicyRef; ref != nil && (from.PlacementPolicyRef == nil || from.PlacementPolicyRef.Name.L != ref.Name.L) {
+		opts.PlacementPolicyRef = ref
+	}
+	s, err := d.write(opts, func(w *showWriter) error { return w.writeOptions() })
+	if err != nil {
+		return err
+	}
+	if s = strings.TrimSpace(strings.Join(append([]string{s}, zeros...), " ")); s != "" {
+		d.specs = append(d.specs, s)
+	}
+	return nil
+}
+
+// absolute returns a copy of the table whose columns are written with their
+// charsets and collations, so that the columns of the tables of different
+// charsets can be compared.
+func absolute(tbl *model.TableInfo) *model.TableInfo {
+	abs := *tbl
+	abs.Charset, abs.Collate = "", ""
+	return &abs
+}
+
+// columnText returns the definition of a column but its name.
+func (d *differ) columnText(tbl *model.TableInfo, col *model.ColumnInfo) (string, error) {
+	col = col.Clone()
+	col.Name = model.CIStr{}
+	return d.write(absolute(tbl), func(w *showWriter) error { return w.writeColumn(col) })
+}
+
+func visibleColumns(tbl *model.TableInfo) []*model.ColumnInfo {
+	cols := make([]*model.ColumnInfo, 0, len(tbl.Columns))
+	for _, col := range tbl.Columns {
+		if !col.Hidden {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// diffColumnNames finds the renamed columns.
+func (d *differ) diffColumnNames() error {
+	var dropped, added []*model.ColumnInfo
+	for _, col := range visibleColumns(d.from) {
+		if model.FindColumnInfo(d.to.Columns, col.Name.L) == nil {
+			dropped = append(dropped, col)
+		}
+	}
+	for _, col := range visibleColumns(d.to) {
+		if model.FindColumnInfo(d.from.Columns, col.Name.L) == nil {
+			added = append(added, col)
+		}
+	}
+	pairs, err := matchUnique(len(dropped), len(added),
+		func(i int) (string, error) { return d.columnText(d.from, dropped[i]) },
+		func(j int) (string, error) { return d.columnText(d.to, added[j]) })
+	if err != nil {
+		return err
+	}
+	for i, j := range pairs {
+		d.renamed[dropped[i].Name.L] = added[j].Name.O
+	}
+	return nil
+}
+
+// matchUnique returns the pairs of the items of two lists whose texts are the
+// same and unique in both lists, keyed by the indexes of the first list.
+func matchUnique(m, n int, left, right func(int) (string, error)) (map[int]int, error) {
+	leftTexts := make(map[string][]int)
+	for i := 0; i < m; i++ {
+		s, err := left(i)
+		if err != nil {
+			return nil, err
+		}
+		leftTexts[s] = append(leftTexts[s], i)
+	}
+	rightTexts := make(map[string][]int)
+	for j := 0; j < n; j++ {
+		s, err := right(j)
+		if err != nil {
+			return nil, err
+		}
+		rightTexts[s] = append(rightTexts[s], j)
+	}
+	pairs := make(map[int]int)
+	for s, is := range leftTexts {
+		if js := rightTexts[s]; len(is) == 1 && len(js) == 1 {
+			pairs[is[0]] = js[0]
+		}
+	}
+	return pairs, nil
+}
+
+// newName returns the name in to of a column of from.
+func (d *differ) newName(name model.CIStr) model.CIStr {
+	if s, ok := d.renamed[name.L]; ok {
+		return model.NewCIStr(s)
+	}
+	return name
+}
+
+// diffColumns drops, renames, adds and modifies the columns, the positions of
+// the columns are tracked so that a column is moved only if it is out of the
+// order of to.
+func (d *differ) diffColumns() error {
+	var order []string
+	fromCols := make(map[string]*model.ColumnInfo)
+	var dropped []*model.ColumnInfo
+	for _, col := range visibleColumns(d.from) {
+		name := d.newName(col.Name)
+		if model.FindColumnInfo(d.to.Columns, name.L) == nil {
+			dropped = append(dropped, col)
+			continue
+		}
+		order = append(order, name.L)
+		fromCols[name.L] = col
+	}
+	// A table can not have no column, if all the columns are dropped, the last
+	// one is dropped after the columns of to are added.
+	var last *model.ColumnInfo
+	if len(order) == 0 && len(dropped) > 0 {
+		last = dropped[len(dropped)-1]
+		for _, col := range dropped {
+			if !col.IsGenerated() {
+				last = col
+			}
+		}
+		order = append(order, last.Name.L)
+	}
+	// The generated columns are dropped first, they may depend on the others.
+	for _, generated := range []bool{true, false} {
+		for _, col := range dropped {
+			if col.IsGenerated() == generated && col != last {
+				d.add("DROP COLUMN %s", d.name(col.Name.O))
+			}
+		}
+	}
+
+	for i, col := range visibleColumns(d.to) {
+		text, err := d.write(d.to, func(w *showWriter) error { return w.writeColumn(col) })
+		if err != nil {
+			return err
+		}
+		pos := ""
+		if i == 0 {
+			pos = " FIRST"
+		} else {
+			pos = " AFTER " + d.name(visibleColumns(d.to)[i-1].Name.O)
+		}
+		old, ok := fromCols[col.Name.L]
+		if !ok {
+			if i == len(order) {
+				pos = ""
+			}
+			d.add("ADD COLUMN %s%s", text, pos)
+			order = append(order[:i], append([]string{col.Name.L}, order[i:]...)...)
+			continue
+		}
+		oldText, err := d.columnText(d.from, old)
+		if err != nil {
+			return err
+		}
+		newText, err := d.columnText(d.to, col)
+		if err != nil {
+			return err
+		}
+		j := indexOf(order, col.Name.L)
+		moved, changed, renamed := j != i, oldText != newText, old.Name.L != col.Name.L
+		if moved {
+			order = append(order[:j], order[j+1:]...)
+			order = append(order[:i], append([]string{col.Name.L}, order[i:]...)...)
+		} else {
+			pos = ""
+		}
+		switch {
+		case renamed && !moved && !changed:
+			d.add("RENAME COLUMN %s TO %s", d.name(old.Name.O), d.name(col.Name.O))
+		case renamed:
+			d.add("CHANGE COLUMN %s %s%s", d.name(old.Name.O), text, pos)
+		case moved || changed:
+			d.add("MODIFY COLUMN %s%s", text, pos)
+		}
+	}
+	if last != nil {
+		d.add("DROP COLUMN %s", d.name(last.Name.O))
+	}
+	return nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, s := range names {
+		if s == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// key is an index or a primary key of a table.
+type key struct {
+	name   string
+	idx    *model.IndexInfo
+	handle *model.ColumnInfo
+}
+
+// keys returns the keys of the table, the columns of from are renamed to
+// their names in to.
+func (d *differ) keys(tbl *model.TableInfo) []*key {
+	var keys []*key
+	if tbl.PKIsHandle {
+		if pk := tbl.GetPkColInfo(); pk != nil {
+			if tbl == d.from {
+				pk = pk.Clone()
+				pk.Name = d.newName(pk.Name)
+			}
+			keys = append(keys, &key{name: "primary", handle: pk})
+		}
+	}
+	for _, idx := range tbl.Indices {
+		if tbl == d.from {
+			idx = idx.Clone()
+			for _, ic := range idx.Columns {
+				ic.Name = d.newName(ic.Name)
+			}
+		}
+		keys = append(keys, &key{name: idx.Name.L, idx: idx})
+	}
+	return keys
+}
+
+func findKey(keys []*key, name string) *key {
+	for _, k := range keys {
+		if k.name == name {
+			return k
+		}
+	}
+	return nil
+}
+
+func (d *differ) keyText(tbl *model.TableInfo, k *key) (string, error) {
+	return d.write(tbl, func(w *showWriter) error {
+		if k.handle != nil {
+			return w.writePKHandle(k.handle)
+		}
+		return w.writeIndex(k.idx)
+	})
+}
+
+// keyDefinition returns the text of a key but its name and visibility.
+func (d *differ) keyDefinition(tbl *model.TableInfo, k *key) (string, error) {
+	if k.idx != nil {
+		idx := k.idx.Clone()
+		if !idx.Primary {
+			idx.Name = model.CIStr{}
+		}
+		idx.Invisible = false
+		k = &key{name: k.name, idx: idx}
+	}
+	return d.keyText(tbl, k)
+}
+
+// diffIndexes returns the specs which add the keys, the specs which drop,
+// rename or change the visibility of the keys are added to d.
+func (d *differ) diffIndexes() ([]string, error) {
+	fromKeys, toKeys := d.keys(d.from), d.keys(d.to)
+	var dropped, added []*key
+	for _, k := range fromKeys {
+		other := findKey(toKeys, k.name)
+		if other == nil {
+			dropped = append(dropped, k)
+			continue
+		}
+		s1, err := d.keyText(d.from, k)
+		if err != nil {
+			return nil, err
+		}
+		s2, err := d.keyText(d.to, other)
+		if err != nil {
+			return nil, err
+		}
+		if s1 == s2 {
+			continue
+		}
+		def1, err := d.keyDefinition(d.from, k)
+		if err != nil {
+			return nil, err
+		}
+		def2, err := d.keyDefinition(d.to, other)
+		if err != nil {
+			return nil, err
+		}
+		if def1 == def2 && k.idx != nil && other.idx != nil && k.idx.Name.O == other.idx.Name.O {
+			d.add("ALTER INDEX %s %s", d.name(other.idx.Name.O), visibility(other.idx))
+			continue
+		}
+		dropped = append(dropped, k)
+		added = append(added, other)
+	}
+	var newKeys []*key
+	for _, k := range toKeys {
+		if findKey(fromKeys, k.name) == nil {
+			newKeys = append(newKeys, k)
+		}
+	}
+
+	// The renamed keys are neither dropped nor added.
+	var oldKeys []*key
+	for _, k := range dropped {
+		if k.name != "primary" && findKey(toKeys, k.name) == nil {
+			oldKeys = append(oldKeys, k)
+		}
+	}
+	var newIndexes []*key
+	for _, k := range newKeys {
+		if k.name != "primary" {
+			newIndexes = append(newIndexes, k)
+		}
+	}
+	newKeys = newIndexes
+	pairs, err := matchUnique(len(oldKeys), len(newKeys),
+		func(i int) (string, error) { return d.keyDefinition(d.from, oldKeys[i]) },
+		func(j int) (string, error) { return d.keyDefinition(d.to, newKeys[j]) })
+	if err != nil {
+		return nil, err
+	}
+	renamedKeys := make(map[*key]bool)
+	for i, j := range pairs {
+		from, to := oldKeys[i].idx, newKeys[j].idx
+		d.add("RENAME INDEX %s TO %s", d.name(from.Name.O), d.name(to.Name.O))
+		if from.Invisible != to.Invisible {
+			d.add("ALTER INDEX %s %s", d.name(to.Name.O), visibility(to))
+		}
+		renamedKeys[oldKeys[i]], renamedKeys[newKeys[j]] = true, true
+	}
+
+	for _, k := range dropped {
+		switch {
+		case renamedKeys[k]:
+		case k.name == "primary":
+			d.add("DROP PRIMARY KEY")
+		default:
+			d.add("DROP INDEX %s", d.name(k.idx.Name.O))
+		}
+	}
+	var specs []string
+	for _, k := range toKeys {
+		if renamedKeys[k] || (findKey(fromKeys, k.name) != nil && !containsKey(added, k)) {
+			continue
+		}
+		s, err := d.keyText(d.to, k)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, "ADD "+s)
+	}
+	return specs, nil
+}
+
+func containsKey(keys []*key, k *key) bool {
+	for _, other := range keys {
+		if other == k {
+			return true
+		}
+	}
+	return false
+}
+
+func visibility(idx *model.IndexInfo) string {
+	if idx.Invisible {
+		return "INVISIBLE"
+	}
+	return "VISIBLE"
+}
+
+// diffForeignKeys returns the specs which add the foreign keys, the specs
+// which drop the foreign keys are added to d.
+func (d *differ) diffForeignKeys() ([]string, error) {
+	texts := make(map[string]string)
+	for _, fk := range d.from.ForeignKeys {
+		fk = fk.Clone()
+		for i, col := range fk.Cols {
+			fk.Cols[i] = d.newName(col)
+		}
+		s, err := d.write(d.from, func(w *showWriter) error { w.writeForeignKey(fk); return nil })
+		if err != nil {
+			return nil, err
+		}
+		texts[fk.Name.L] = s
+	}
+	var specs []string
+	kept := make(map[string]bool)
+	for _, fk := range d.to.ForeignKeys {
+		s, err := d.write(d.to, func(w *showWriter) error { w.writeForeignKey(fk); return nil })
+		if err != nil {
+			return nil, err
+		}
+		if texts[fk.Name.L] == s {
+			kept[fk.Name.L] = true
+			continue
+		}
+		specs = append(specs, "ADD "+s)
+	}
+	for _, fk := range d.from.ForeignKeys {
+		if !kept[fk.Name.L] {
+			d.add("DROP FOREIGN KEY %s", d.name(fk.Name.O))
+		}
+	}
+	return specs, nil
+}
+
+// diffChecks returns the specs which add the check constraints, the specs
+// which drop or alter the check constraints are added to d.
+func (d *differ) diffChecks() ([]string, error) {
+	var specs []string
+	kept := make(map[string]bool)
+	for _, c := range d.to.Constraints {
+		old := d.from.FindConstraintInfoByName(c.Name.L)
+		if old != nil && old.ExprString == c.ExprString {
+			kept[c.Name.L] = true
+			if old.Enforced != c.Enforced {
+				if c.Enforced {
+					d.add("ALTER CHECK %s ENFORCED", d.name(c.Name.O))
+				} else {
+					d.add("ALTER CHECK %s NOT ENFORCED", d.name(c.Name.O))
+				}
+			}
+			continue
+		}
+		s, err := d.write(d.to, func(w *showWriter) error { w.writeCheck(c); return nil })
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, "ADD "+s)
+	}
+	for _, c := range d.from.Constraints {
+		if !kept[c.Name.L] {
+			d.add("DROP CHECK %s", d.name(c.Name.O))
+		}
+	}
+	return specs, nil
+}
+
+// diffPartition changes the partitions, the definitions of the partitions are
+// compared by their texts.
+func (d *differ) diffPartition() error {
+	from, to := d.from.Partition, d.to.Partition
+	switch {
+	case from == nil && to == nil:
+		return nil
+	case to == nil:
+		d.partition = "REMOVE PARTITIONING"
+		return nil
+	case from == nil || from.Type != to.Type || from.Expr != to.Expr || !sameNames(from.Columns, to.Columns):
+		return d.repartition()
+	}
+
+	n := len(to.Definitions) - len(from.Definitions)
+	if to.Type == model.PartitionTypeHash || to.Type == model.PartitionTypeKey {
+		switch {
+		case n > 0:
+			d.add("ADD PARTITION PARTITIONS %d", n)
+		case n < 0:
+			d.add("COALESCE PARTITION %d", -n)
+		}
+		return nil
+	}
+
+	fromDefs := make(map[string]string)
+	for _, def := range from.Definitions {
+		s, err := d.partitionDefinition(d.from, def)
+		if err != nil {
+			return err
+		}
+		fromDefs[def.Name.L] = s
+	}
+	var dropped, added []string
+	for _, def := range to.Definitions {
+		s, err := d.partitionDefinition(d.to, def)
+		if err != nil {
+			return err
+		}
+		old, ok := fromDefs[def.Name.L]
+		switch {
+		case ok && old == s && len(added) == 0:
+			delete(fromDefs, def.Name.L)
+		case !ok:
+			added = append(added, s)
+		default:
+			return d.repartition()
+		}
+	}
+	for _, def := range from.Definitions {
+		if _, ok := fromDefs[def.Name.L]; ok {
+			dropped = append(dropped, d.name(def.Name.O))
+		}
+	}
+	switch {
+	case len(dropped) > 0 && len(added) > 0:
+		return d.repartition()
+	case len(dropped) > 0:
+		d.add("DROP PARTITION %s", strings.Join(dropped, ", "))
+	case len(added) > 0:
+		d.add("ADD PARTITION (%s)", strings.Join(added, ", "))
+	}
+	return nil
+}
+
+func (d *differ) partitionDefinition(tbl *model.TableInfo, def model.PartitionDefinition) (string, error) {
+	return d.write(tbl, func(w *showWriter) error { return w.writePartitionDefinition(def) })
+}
+
+func (d *differ) repartition() error {
+	s, err := d.write(d.to, func(w *showWriter) error { return w.writePartition() })
+	d.partition = strings.TrimSpace(s)
+	return err
+}
+
+func sameNames(a, b []model.CIStr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].L != b[i].L {
+			return false
+		}
+	}
+	return true
+}
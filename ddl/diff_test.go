@@ -0,0 +1,170 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	. "github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/stretchr/testify/require"
+)
+
+func restore(t *testing.T, node ast.Node) string {
+	var sb strings.Builder
+	require.NoError(t, node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags|format.RestoreTiDBSpecialComment, &sb)))
+	return sb.String()
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		from, to string
+		alter    string
+	}{
+		{
+			"create table t (a int, b int)",
+			"create table t (a int, b int)",
+			"",
+		},
+		{
+			"create table t (a int, b int)",
+			"create table t (z int, a int, b bigint not null, c varchar(10) default 'x' comment 'c')",
+			"ALTER TABLE `t` ADD COLUMN `z` INT(11) DEFAULT NULL FIRST, MODIFY COLUMN `b` BIGINT(20) NOT NULL, ADD COLUMN `c` VARCHAR(10) DEFAULT 'x' COMMENT 'c'",
+		},
+		{
+			"create table t (a int, b int, c int, d int)",
+			"create table t (d int, b int, a int, c int)",
+			"ALTER TABLE `t` MODIFY COLUMN `d` INT(11) DEFAULT NULL FIRST, MODIFY COLUMN `b` INT(11) DEFAULT NULL AFTER `d`",
+		},
+		{
+			"create table t (a int, b varchar(10), c int)",
+			"create table t (a int, x varchar(10), c int)",
+			"ALTER TABLE `t` RENAME COLUMN `b` TO `x`",
+		},
+		{
+			"create table t (a int, b varchar(10), c int as (a + 1))",
+			"create table t (a int)",
+			"ALTER TABLE `t` DROP COLUMN `c`, DROP COLUMN `b`",
+		},
+		{
+			"create table t (a int, b int, c int)",
+			"create table t (x int, y int, z int)",
+			"ALTER TABLE `t` DROP COLUMN `a`, DROP COLUMN `b`, ADD COLUMN `x` INT(11) DEFAULT NULL FIRST, ADD COLUMN `y` INT(11) DEFAULT NULL AFTER `x`, ADD COLUMN `z` INT(11) DEFAULT NULL AFTER `y`, DROP COLUMN `c`",
+		},
+		{
+			"create table t (a int, b int as (a + 1))",
+			"create table t (x varchar(10))",
+			"ALTER TABLE `t` DROP COLUMN `b`, ADD COLUMN `x` VARCHAR(10) DEFAULT NULL FIRST, DROP COLUMN `a`",
+		},
+		{
+			"create table t (a int primary key, b int, c int, key kb (b), unique key kc (c), key kbc (b, c))",
+			"create table t (a int, b int, c int, primary key (a, b), key kb (b) invisible, key kx (c), index kbc (c, b))",
+			"ALTER TABLE `t` ALTER INDEX `kb` INVISIBLE, DROP PRIMARY KEY, DROP INDEX `kc`, DROP INDEX `kbc`, MODIFY COLUMN `b` INT(11) NOT NULL, ADD PRIMARY KEY(`a`, `b`) /*T![clustered_index] NONCLUSTERED */, ADD INDEX `kx`(`c`), ADD INDEX `kbc`(`c`, `b`)",
+		},
+		{
+			"create table t (a int, b int, key kb (b))",
+			"create table t (a int, x int, key kx (x))",
+			"ALTER TABLE `t` RENAME INDEX `kb` TO `kx`, RENAME COLUMN `b` TO `x`",
+		},
+		{
+			"create table t (a int, constraint c1 check (a > 0), constraint c2 check (a < 10), constraint c3 check (a <> 5))",
+			"create table t (a int, constraint c1 check (a > 0) not enforced, constraint c2 check (a < 100), constraint c4 check (a <> 6))",
+			"ALTER TABLE `t` ALTER CHECK `c1` NOT ENFORCED, DROP CHECK `c2`, DROP CHECK `c3`, ADD CONSTRAINT `c2` CHECK((`a`<100)) ENFORCED, ADD CONSTRAINT `c4` CHECK((`a`!=6)) ENFORCED",
+		},
+		{
+			"create table t (a int, b int, key kb (b), constraint fk1 foreign key (a) references p (id), constraint fk2 foreign key (b) references p (id))",
+			"create table t (a int, b int, key kb (b), constraint fk1 foreign key (a) references p (id) on delete cascade, constraint fk3 foreign key (b) references q (id))",
+			"ALTER TABLE `t` DROP FOREIGN KEY `fk1`, DROP FOREIGN KEY `fk2`, ADD CONSTRAINT `fk1` FOREIGN KEY (`a`) REFERENCES `p`(`id`) ON DELETE CASCADE, ADD CONSTRAINT `fk3` FOREIGN KEY (`b`) REFERENCES `q`(`id`)",
+		},
+		{
+			"create table t (a int) charset latin1 comment 'x'",
+			"create table t2 (a int, b varchar(10)) charset utf8mb4 /*T! shard_row_id_bits = 4 */",
+			"ALTER TABLE `t` DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_BIN /*T! SHARD_ROW_ID_BITS = 4 */ COMMENT = '', ADD COLUMN `b` VARCHAR(10) DEFAULT NULL, RENAME AS `t2`",
+		},
+		{
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20))",
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20), partition p2 values less than (maxvalue))",
+			"ALTER TABLE `t` ADD PARTITION (PARTITION `p2` VALUES LESS THAN (MAXVALUE))",
+		},
+		{
+			"create table t (a int) partition by list (a) (partition p0 values in (1, 2), partition p1 values in (3), partition p2 values in (4))",
+			"create table t (a int) partition by list (a) (partition p0 values in (1, 2), partition p2 values in (4))",
+			"ALTER TABLE `t` DROP PARTITION `p1`",
+		},
+		{
+			"create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20))",
+			"create table t (a int) partition by range (a) (partition p1 values less than (20), partition p2 values less than (30))",
+			"ALTER TABLE `t` PARTITION BY RANGE (`a`) (PARTITION `p1` VALUES LESS THAN (20),PARTITION `p2` VALUES LESS THAN (30))",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 4",
+			"create table t (a int, b int) partition by hash (a) partitions 2",
+			"ALTER TABLE `t` ADD COLUMN `b` INT(11) DEFAULT NULL, COALESCE PARTITION 2",
+		},
+		{
+			"create table t (a int, b varchar(10), c int)",
+			"create table t (x varchar(10), a int, c int) partition by hash (a) partitions 2",
+			"ALTER TABLE `t` CHANGE COLUMN `b` `x` VARCHAR(10) DEFAULT NULL FIRST PARTITION BY HASH (`a`) PARTITIONS 2",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 2",
+			"create table t (a int) partition by hash (a) partitions 4",
+			"ALTER TABLE `t` ADD PARTITION PARTITIONS 2",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 4",
+			"create table t (a int) partition by key (a) partitions 4",
+			"ALTER TABLE `t` PARTITION BY KEY (`a`) PARTITIONS 4",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 4",
+			"create table t (a int)",
+			"ALTER TABLE `t` REMOVE PARTITIONING",
+		},
+	}
+	for _, c := range cases {
+		p := parser.New()
+		from, err := p.ParseOneStmt(c.from, "", "")
+		require.NoError(t, err)
+		to, err := p.ParseOneStmt(c.to, "", "")
+		require.NoError(t, err)
+		stmt, err := DiffCreateTable(from.(*ast.CreateTableStmt), to.(*ast.CreateTableStmt))
+		require.NoError(t, err, c.to)
+		if stmt == nil {
+			require.Equal(t, "", c.alter, c.to)
+			continue
+		}
+		sql := restore(t, stmt)
+		require.Equal(t, c.alter, sql, c.to)
+
+		// The text round-trips and changes the table into to.
+		again, err := p.ParseOneStmt(sql, "", "")
+		require.NoError(t, err, sql)
+		require.Equal(t, sql, restore(t, again))
+		cat := NewCatalog()
+		mustApply(t, cat, "create database d; use d; create table p (id int primary key); create table q (id int primary key)")
+		mustApply(t, cat, c.from)
+		require.NoError(t, cat.Apply(again), sql)
+		toTbl, err := BuildTableInfo(to.(*ast.CreateTableStmt))
+		require.NoError(t, err)
+		expected, err := ShowCreateTable(toTbl, 0)
+		require.NoError(t, err)
+		actual, err := ShowCreateTable(mustTable(t, cat, "d", toTbl.Name.O), 0)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, sql)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/terror"
+)
+
+var (
+	// ErrTableNotExists returns for an unknown table.
+	ErrTableNotExists = terror.ClassSchema.NewStd(mysql.ErrNoSuchTable)
+	// ErrDatabaseNotExists returns for an unknown database.
+	ErrDatabaseNotExists = terror.ClassSchema.NewStd(mysql.ErrBadDB)
+	// ErrNoDB returns for an unqualified table name without a current
+	// database.
+	ErrNoDB = terror.ClassSchema.NewStd(mysql.ErrNoDB)
+	// ErrDatabaseExists returns for creating a database which exists.
+	ErrDatabaseExists = terror.ClassSchema.NewStd(mysql.ErrDBCreateExists)
+	// ErrDatabaseDropExists returns for dropping an unknown database.
+	ErrDatabaseDropExists = terror.ClassSchema.NewStd(mysql.ErrDBDropExists)
+	// ErrTableExists returns for creating a table which exists.
+	ErrTableExists = terror.ClassSchema.NewStd(mysql.ErrTableExists)
+	// ErrBadTable returns for dropping unknown tables.
+	ErrBadTable = terror.ClassSchema.NewStd(mysql.ErrBadTable)
+	// ErrWrongObject returns for a view used as a table, or a table used as a
+	// view.
+	ErrWrongObject = terror.ClassSchema.NewStd(mysql.ErrWrongObject)
+	// ErrViewWrongList returns for a view whose column list does not match
+	// its query.
+	ErrViewWrongList = terror.ClassDDL.NewStd(mysql.ErrViewWrongList)
+	// ErrWrongTableName returns for an invalid table name.
+	ErrWrongTableName = terror.ClassDDL.NewStd(mysql.ErrWrongTableName)
+	// ErrWrongColumnName returns for an invalid column name.
+	ErrWrongColumnName = terror.ClassDDL.NewStd(mysql.ErrWrongColumnName)
+	// ErrWrongNameForIndex returns for an invalid index name.
+	ErrWrongNameForIndex = terror.ClassDDL.NewStd(mysql.ErrWrongNameForIndex)
+	// ErrTooLongIdent returns for an identifier longer than 64 characters.
+	ErrTooLongIdent = terror.ClassDDL.NewStd(mysql.ErrTooLongIdent)
+	// ErrTableMustHaveColumns returns for a table without columns.
+	ErrTableMustHaveColumns = terror.ClassDDL.NewStd(mysql.ErrTableMustHaveColumns)
+	// ErrTooManyFields returns for a table with too many columns.
+	ErrTooManyFields = terror.ClassDDL.NewStd(mysql.ErrTooManyFields)
+	// ErrDupFieldName returns for a duplicated column name.
+	ErrDupFieldName = terror.ClassDDL.NewStd(mysql.ErrDupFieldName)
+	// ErrWrongFieldSpec returns for a column attribute not allowed for the type.
+	ErrWrongFieldSpec = terror.ClassDDL.NewStd(mysql.ErrWrongFieldSpec)
+	// ErrTooBigFieldlength returns for a string column longer than the maximum.
+	ErrTooBigFieldlength = terror.ClassDDL.NewStd(mysql.ErrTooBigFieldlength)
+	// ErrTooBigDisplaywidth returns for a display width out of range.
+	ErrTooBigDisplaywidth = terror.ClassDDL.NewStd(mysql.ErrTooBigDisplaywidth)
+	// ErrTooBigPrecision returns for a precision out of range.
+	ErrTooBigPrecision = terror.ClassDDL.NewStd(mysql.ErrTooBigPrecision)
+	// ErrTooBigScale returns for a scale out of range.
+	ErrTooBigScale = terror.ClassDDL.NewStd(mysql.ErrTooBigScale)
+	// ErrMBiggerThanD returns for a scale larger than the precision.
+	ErrMBiggerThanD = terror.ClassDDL.NewStd(mysql.ErrMBiggerThanD)
+	// ErrTooBigSet returns for a SET column with more than 64 members.
+	ErrTooBigSet = terror.ClassDDL.NewStd(mysql.ErrTooBigSet)
+	// ErrDuplicatedValueInType returns for a duplicated member of ENUM or SET.
+	ErrDuplicatedValueInType = terror.ClassDDL.NewStd(mysql.ErrDuplicatedValueInType)
+	// ErrUnknownCharacterSet returns for an unknown charset.
+	ErrUnknownCharacterSet = terror.ClassDDL.NewStd(mysql.ErrUnknownCharacterSet)
+	// ErrUnknownCollation returns for an unknown collation.
+	ErrUnknownCollation = terror.ClassDDL.NewStd(mysql.ErrUnknownCollation)
+	// ErrCollationCharsetMismatch returns for a collation of another charset.
+	ErrCollationCharsetMismatch = terror.ClassDDL.NewStd(mysql.ErrCollationCharsetMismatch)
+	// ErrInvalidDefault returns for a default value not allowed for the column.
+	ErrInvalidDefault = terror.ClassDDL.NewStd(mysql.ErrInvalidDefault)
+	// ErrBlobCantHaveDefault returns for a default value of BLOB, TEXT or JSON.
+	ErrBlobCantHaveDefault = terror.ClassDDL.NewStd(mysql.ErrBlobCantHaveDefault)
+	// ErrInvalidOnUpdate returns for ON UPDATE of a column which is not
+	// TIMESTAMP or DATETIME.
+	ErrInvalidOnUpdate = terror.ClassDDL.NewStd(mysql.ErrInvalidOnUpdate)
+	// ErrTooLongFieldComment returns for a column comment longer than 1024.
+	ErrTooLongFieldComment = terror.ClassDDL.NewStd(mysql.ErrTooLongFieldComment)
+	// ErrTooLongTableComment returns for a table comment longer than 2048.
+	ErrTooLongTableComment = terror.ClassDDL.NewStd(mysql.ErrTooLongTableComment)
+	// ErrTooLongIndexComment returns for an index comment longer than 1024.
+	ErrTooLongIndexComment = terror.ClassDDL.NewStd(mysql.ErrTooLongIndexComment)
+	// ErrWrongAutoKey returns for several auto increment columns, or an auto
+	// increment column which is not a key.
+	ErrWrongAutoKey = terror.ClassDDL.NewStd(mysql.ErrWrongAutoKey)
+	// ErrBadGeneratedColumn returns for a generated column with a default value.
+	ErrBadGeneratedColumn = terror.ClassDDL.NewStd(mysql.ErrBadGeneratedColumn)
+	// ErrGeneratedColumnNonPrior returns for a generated column referring to a
+	// generated column defined after it.
+	ErrGeneratedColumnNonPrior = terror.ClassDDL.NewStd(mysql.ErrGeneratedColumnNonPrior)
+	// ErrGeneratedColumnRefAutoInc returns for a generated column referring to
+	// an auto increment column.
+	ErrGeneratedColumnRefAutoInc = terror.ClassDDL.NewStd(mysql.ErrGeneratedColumnRefAutoInc)
+	// ErrUnsupportedOnGeneratedColumn returns for an attribute not allowed for
+	// a generated column.
+	ErrUnsupportedOnGeneratedColumn = terror.ClassDDL.NewStd(mysql.ErrUnsupportedOnGeneratedColumn)
+	// ErrBadField returns for an unknown column in an expression.
+	ErrBadField = terror.ClassDDL.NewStd(mysql.ErrBadField)
+	// ErrMultiplePriKey returns for several primary keys.
+	ErrMultiplePriKey = terror.ClassDDL.NewStd(mysql.ErrMultiplePriKey)
+	// ErrPrimaryCantHaveNull returns for a NULL column of the primary key.
+	ErrPrimaryCantHaveNull = terror.ClassDDL.NewStd(mysql.ErrPrimaryCantHaveNull)
+	// ErrDupKeyName returns for a duplicated index name.
+	ErrDupKeyName = terror.ClassDDL.NewStd(mysql.ErrDupKeyName)
+	// ErrKeyColumnDoesNotExits returns for an unknown column of an index.
+	ErrKeyColumnDoesNotExits = terror.ClassDDL.NewStd(mysql.ErrKeyColumnDoesNotExits)
+	// ErrDupFieldNameInIndex returns for a column used twice in an index.
+	ErrDupFieldNameInIndex = terror.ClassDDL.NewStd(mysql.ErrDupFieldName)
+	// ErrTooManyKeyParts returns for an index with more than 16 columns.
+	ErrTooManyKeyParts = terror.ClassDDL.NewStd(mysql.ErrTooManyKeyParts)
+	// ErrTooManyKeys returns for a table with more than 64 indexes.
+	ErrTooManyKeys = terror.ClassDDL.NewStd(mysql.ErrTooManyKeys)
+	// ErrTooLongKey returns for an index longer than the maximum key length.
+	ErrTooLongKey = terror.ClassDDL.NewStd(mysql.ErrTooLongKey)
+	// ErrBlobKeyWithoutLength returns for a BLOB or TEXT index column without
+	// prefix length.
+	ErrBlobKeyWithoutLength = terror.ClassDDL.NewStd(mysql.ErrBlobKeyWithoutLength)
+	// ErrWrongSubKey returns for an invalid prefix length.
+	ErrWrongSubKey = terror.ClassDDL.NewStd(mysql.ErrWrongSubKey)
+	// ErrJSONUsedAsKey returns for a JSON index column.
+	ErrJSONUsedAsKey = terror.ClassDDL.NewStd(mysql.ErrJSONUsedAsKey)
+	// ErrFunctionalIndexPrimaryKey returns for an expression in the primary key.
+	ErrFunctionalIndexPrimaryKey = terror.ClassDDL.NewStd(mysql.ErrFunctionalIndexPrimaryKey)
+	// ErrFunctionalIndexOnField returns for an index expression which is a
+	// column.
+	ErrFunctionalIndexOnField = terror.ClassDDL.NewStd(mysql.ErrFunctionalIndexOnField)
+	// ErrFunctionalIndexOnLob returns for an index expression of BLOB or TEXT.
+	ErrFunctionalIndexOnLob = terror.ClassDDL.NewStd(mysql.ErrFunctionalIndexOnLob)
+	// ErrFunctionalIndexOnJSONOrGeometryFunction returns for an index
+	// expression of JSON or GEOMETRY.
+	ErrFunctionalIndexOnJSONOrGeometryFunction = terror.ClassDDL.NewStd(mysql.ErrFunctionalIndexOnJsonOrGeometryFunction)
+	// ErrFunctionalIndexRefAutoIncrement returns for an index expression
+	// referring to an auto increment column.
+	ErrFunctionalIndexRefAutoIncrement = terror.ClassDDL.NewStd(mysql.ErrFunctionalIndexRefAutoIncrement)
+	// ErrFkDupName returns for a duplicated foreign key name.
+	ErrFkDupName = terror.ClassDDL.NewStd(mysql.ErrFkDupName)
+	// ErrWrongFkDef returns for a foreign key whose columns do not match the
+	// referenced columns.
+	ErrWrongFkDef = terror.ClassDDL.NewStd(mysql.ErrWrongFkDef)
+	// ErrCheckConstraintDupName returns for a duplicated check constraint name.
+	ErrCheckConstraintDupName = terror.ClassDDL.NewStd(mysql.ErrCheckConstraintDupName)
+	// ErrCantDropFieldOrKey returns for dropping an unknown column or index.
+	ErrCantDropFieldOrKey = terror.ClassDDL.NewStd(mysql.ErrCantDropFieldOrKey)
+	// ErrCantRemoveAllFields returns for dropping the last column.
+	ErrCantRemoveAllFields = terror.ClassDDL.NewStd(mysql.ErrCantRemoveAllFields)
+	// ErrDependentByGeneratedColumn returns for dropping a column a generated
+	// column refers to.
+	ErrDependentByGeneratedColumn = terror.ClassDDL.NewStd(mysql.ErrDependentByGeneratedColumn)
+	// ErrDependentByFunctionalIndex returns for dropping or renaming a column
+	// a functional index refers to.
+	ErrDependentByFunctionalIndex = terror.ClassDDL.NewStd(mysql.ErrDependentByFunctionalIndex)
+	// ErrDependentByCheckConstraint returns for dropping or renaming a column
+	// a check constraint of several columns refers to.
+	ErrDependentByCheckConstraint = terror.ClassDDL.NewStd(mysql.ErrDependentByCheckConstraint)
+	// ErrDependentByPartitionFunctional returns for dropping or renaming a
+	// partitioning column.
+	ErrDependentByPartitionFunctional = terror.ClassDDL.NewStd(mysql.ErrDependentByPartitionFunctional)
+	// ErrKeyDoesNotExist returns for an unknown index.
+	ErrKeyDoesNotExist = terror.ClassDDL.NewStd(mysql.ErrKeyDoesNotExist)
+	// ErrCheckConstraintNotFound returns for an unknown check constraint.
+	ErrCheckConstraintNotFound = terror.ClassDDL.NewStd(mysql.ErrCheckConstraintNotFound)
+	// ErrDropIndexFk returns for dropping an index a foreign key needs.
+	ErrDropIndexFk = terror.ClassDDL.NewStd(mysql.ErrDropIndexFk)
+	// ErrFkColumnCannotDrop returns for dropping a column of a foreign key.
+	ErrFkColumnCannotDrop = terror.ClassDDL.NewStd(mysql.ErrFkColumnCannotDrop)
+	// ErrFkColumnCannotChange returns for changing the type of a column of a
+	// foreign key.
+	ErrFkColumnCannotChange = terror.ClassDDL.NewStd(mysql.ErrFkColumnCannotChange)
+	// ErrSameNamePartition returns for a duplicated partition name.
+	ErrSameNamePartition = terror.ClassDDL.NewStd(mysql.ErrSameNamePartition)
+	// ErrTooManyPartitions returns for more than 8192 partitions.
+	ErrTooManyPartitions = terror.ClassDDL.NewStd(mysql.ErrTooManyPartitions)
+	// ErrRangeNotIncreasing returns for RANGE partitions whose values are not
+	// strictly increasing.
+	ErrRangeNotIncreasing = terror.ClassDDL.NewStd(mysql.ErrRangeNotIncreasing)
+	// ErrPartitionMaxvalue returns for MAXVALUE which is not in the last
+	// RANGE partition.
+	ErrPartitionMaxvalue = terror.ClassDDL.NewStd(mysql.ErrPartitionMaxvalue)
+	// ErrMultipleDefConstInListPart returns for a value in several LIST
+	// partitions.
+	ErrMultipleDefConstInListPart = terror.ClassDDL.NewStd(mysql.ErrMultipleDefConstInListPart)
+	// ErrFieldNotFoundPart returns for an unknown partitioning column.
+	ErrFieldNotFoundPart = terror.ClassDDL.NewStd(mysql.ErrFieldNotFoundPart)
+	// ErrFieldTypeNotAllowedAsPartitionField returns for a partitioning column
+	// of a type not allowed.
+	ErrFieldTypeNotAllowedAsPartitionField = terror.ClassDDL.NewStd(mysql.ErrFieldTypeNotAllowedAsPartitionField)
+	// ErrPartitionMgmtOnNonpartitioned returns for managing the partitions of
+	// a table which is not partitioned.
+	ErrPartitionMgmtOnNonpartitioned = terror.ClassDDL.NewStd(mysql.ErrPartitionMgmtOnNonpartitioned)
+	// ErrDropPartitionNonExistent returns for an unknown partition.
+	ErrDropPartitionNonExistent = terror.ClassDDL.NewStd(mysql.ErrDropPartitionNonExistent)
+	// ErrDropLastPartition returns for dropping all the partitions.
+	ErrDropLastPartition = terror.ClassDDL.NewStd(mysql.ErrDropLastPartition)
+	// ErrOnlyOnRangeListPartition returns for ADD PARTITION or DROP PARTITION
+	// with definitions on HASH or KEY partitioning.
+	ErrOnlyOnRangeListPartition = terror.ClassDDL.NewStd(mysql.ErrOnlyOnRangeListPartition)
+	// ErrCoalesceOnlyOnHashPartition returns for COALESCE PARTITION on RANGE
+	// or LIST partitioning.
+	ErrCoalesceOnlyOnHashPartition = terror.ClassDDL.NewStd(mysql.ErrCoalesceOnlyOnHashPartition)
+	// ErrPartitionsMustBeDefined returns for RANGE or LIST partitions without
+	// definitions.
+	ErrPartitionsMustBeDefined = terror.ClassDDL.NewStd(mysql.ErrPartitionsMustBeDefined)
+	// ErrUniqueKeyNeedAllFieldsInPf returns for a unique key which does not
+	// include all the partitioning columns.
+	ErrUniqueKeyNeedAllFieldsInPf = terror.ClassDDL.NewStd(mysql.ErrUniqueKeyNeedAllFieldsInPf)
+	// ErrAlterOperationNotSupported returns for an ALGORITHM or a LOCK clause
+	// not supported by ALTER TABLE.
+	ErrAlterOperationNotSupported = terror.ClassDDL.NewStd(mysql.ErrAlterOperationNotSupported)
+	// ErrAlterOperationNotSupportedReason returns for an ALGORITHM or a LOCK
+	// clause not supported by an operation of ALTER TABLE.
+	ErrAlterOperationNotSupportedReason = terror.ClassDDL.NewStd(mysql.ErrAlterOperationNotSupportedReason)
+)
@@ -0,0 +1,478 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/typeinfer"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// buildConstraints builds the primary key, the indexes, the check constraints
+// and the foreign keys of the table. The primary key is built first, the
+// foreign keys are built last to reuse the indexes of their columns.
+func (tb *tableBuilder) buildConstraints(constraints []*ast.Constraint) error {
+	var pk *ast.Constraint
+	for _, c := range constraints {
+		if c.Tp != ast.ConstraintPrimaryKey {
+			continue
+		}
+		if pk != nil {
+			return ErrMultiplePriKey.GenWithStackByArgs()
+		}
+		pk = c
+	}
+	if pk != nil {
+		if err := tb.buildPrimaryKey(pk); err != nil {
+			return err
+		}
+	}
+	for _, c := range constraints {
+		var err error
+		switch c.Tp {
+		case ast.ConstraintKey, ast.ConstraintIndex:
+			_, err = tb.buildIndex(c, false, false)
+		case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+			_, err = tb.buildIndex(c, true, false)
+		case ast.ConstraintCheck:
+			err = tb.buildCheck(c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range constraints {
+		if c.Tp == ast.ConstraintForeignKey {
+			if err := tb.buildForeignKey(c); err != nil {
+				return err
+			}
+		}
+	}
+	if len(tb.tbl.Indices) > maxIndexCount {
+		return ErrTooManyKeys.GenWithStackByArgs(maxIndexCount)
+	}
+	return nil
+}
+
+// buildPrimaryKey builds the primary key, a clustered primary key of a single
+// integer column is the handle of the table and has no index.
+func (tb *tableBuilder) buildPrimaryKey(c *ast.Constraint) error {
+	if tb.hasPrimaryKey() {
+		return ErrMultiplePriKey.GenWithStackByArgs()
+	}
+	for _, key := range c.Keys {
+		if key.Expr != nil {
+			return ErrFunctionalIndexPrimaryKey.GenWithStackByArgs()
+		}
+		col := model.FindColumnInfo(tb.tbl.Columns, key.Column.Name.L)
+		if col == nil {
+			return ErrKeyColumnDoesNotExits.GenWithStackByArgs(key.Column.Name.O)
+		}
+		if tb.nullable[col.Name.L] {
+			return ErrPrimaryCantHaveNull.GenWithStackByArgs()
+		}
+	}
+	var intHandle bool
+	if len(c.Keys) == 1 && c.Keys[0].Length == types.UnspecifiedLength {
+		col := model.FindColumnInfo(tb.tbl.Columns, c.Keys[0].Column.Name.L)
+		intHandle = mysql.IsIntegerType(col.GetType())
+	}
+	clustered := tb.isClustered(c, intHandle)
+	if clustered && intHandle {
+		col := model.FindColumnInfo(tb.tbl.Columns, c.Keys[0].Column.Name.L)
+		col.AddFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+		tb.tbl.PKIsHandle = true
+		return nil
+	}
+	idx, err := tb.buildIndex(c, true, true)
+	if err != nil {
+		return err
+	}
+	for _, ic := range idx.Columns {
+		tb.tbl.Columns[ic.Offset].AddFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+	}
+	if clustered {
+		tb.tbl.IsCommonHandle = true
+		tb.tbl.CommonHandleVersion = 1
+	}
+	return nil
+}
+
+// hasPrimaryKey returns whether the table has an explicit primary key.
+func (tb *tableBuilder) hasPrimaryKey() bool {
+	if tb.tbl.PKIsHandle {
+		return true
+	}
+	for _, idx := range tb.tbl.Indices {
+		if idx.Primary {
+			return true
+		}
+	}
+	return false
+}
+
+// isClustered returns whether the primary key is clustered, intHandle reports
+// whether it is a single integer column.
+func (tb *tableBuilder) isClustered(c *ast.Constraint, intHandle bool) bool {
+	if c.Option != nil {
+		switch c.Option.PrimaryKeyTp {
+		case model.PrimaryKeyTypeClustered:
+			return true
+		case model.PrimaryKeyTypeNonClustered:
+			return false
+		}
+	}
+	switch tb.ClusteredIndex {
+	case ClusteredIndexDefModeOn:
+		return true
+	case ClusteredIndexDefModeOff:
+		return false
+	}
+	return intHandle
+}
+
+// buildIndex builds an index of the table, the expressions of a functional
+// index become hidden virtual columns.
+func (tb *tableBuilder) buildIndex(c *ast.Constraint, unique, primary bool) (*model.IndexInfo, error) {
+	name := c.Name
+	switch {
+	case primary:
+		name = mysql.PrimaryKeyName
+	case name == "":
+		name = tb.indexName(c.Keys)
+	case strings.EqualFold(name, mysql.PrimaryKeyName):
+		return nil, ErrWrongNameForIndex.GenWithStackByArgs(name)
+	}
+	if utf8.RuneCountInString(name) > mysql.MaxIndexIdentifierLen {
+		return nil, ErrTooLongIdent.GenWithStackByArgs(name)
+	}
+	if tb.tbl.FindIndexByName(strings.ToLower(name)) != nil {
+		return nil, ErrDupKeyName.GenWithStackByArgs(name)
+	}
+	if len(c.Keys) > mysql.MaxKeyParts {
+		return nil, ErrTooManyKeyParts.GenWithStackByArgs(mysql.MaxKeyParts)
+	}
+
+	idx := &model.IndexInfo{
+		Name:    model.NewCIStr(name),
+		Table:   tb.tbl.Name,
+		State:   model.StatePublic,
+		Tp:      model.IndexTypeBtree,
+		Unique:  unique,
+		Primary: primary,
+	}
+	if opt := c.Option; opt != nil {
+		if opt.Tp != model.IndexTypeInvalid {
+			idx.Tp = opt.Tp
+		}
+		if utf8.RuneCountInString(opt.Comment) > maxIndexCommentLength {
+			return nil, ErrTooLongIndexComment.GenWithStackByArgs(name, maxIndexCommentLength)
+		}
+		idx.Comment = opt.Comment
+		idx.Invisible = opt.Visibility == ast.IndexVisibilityInvisible
+	}
+
+	var keyLength int
+	for i, key := range c.Keys {
+		var col *model.ColumnInfo
+		prefix := key.Length
+		if key.Expr != nil {
+			var err error
+			if col, err = tb.buildHiddenColumn(name, i, key.Expr); err != nil {
+				return nil, err
+			}
+			prefix = types.UnspecifiedLength
+		} else if col = model.FindColumnInfo(tb.tbl.Columns, key.Column.Name.L); col == nil {
+			return nil, ErrKeyColumnDoesNotExits.GenWithStackByArgs(key.Column.Name.O)
+		}
+		for _, ic := range idx.Columns {
+			if ic.Name.L == col.Name.L {
+				return nil, ErrDupFieldNameInIndex.GenWithStackByArgs(col.Name.O)
+			}
+		}
+		length, err := indexColumnLength(col, prefix)
+		if err != nil {
+			return nil, err
+		}
+		keyLength += length
+		idx.Columns = append(idx.Columns, &model.IndexColumn{Name: col.Name, Offset: col.Offset, Length: prefix})
+	}
+	if keyLength > maxKeyLength {
+		return nil, ErrTooLongKey.GenWithStackByArgs(maxKeyLength)
+	}
+
+	tb.tbl.MaxIndexID++
+	idx.ID = tb.tbl.MaxIndexID
+	if primary {
+		// The primary key is the first index like MySQL.
+		tb.tbl.Indices = append([]*model.IndexInfo{idx}, tb.tbl.Indices...)
+	} else {
+		tb.tbl.Indices = append(tb.tbl.Indices, idx)
+	}
+	if !primary {
+		first := tb.tbl.Columns[idx.Columns[0].Offset]
+		if unique && len(idx.Columns) == 1 {
+			first.AddFlag(mysql.UniqueKeyFlag)
+		} else {
+			first.AddFlag(mysql.MultipleKeyFlag)
+		}
+	}
+	return idx, nil
+}
+
+// indexName generates the name of an index without a name, which is the name
+// of its first column, or functional_index, with a suffix to be unique.
+func (tb *tableBuilder) indexName(keys []*ast.IndexPartSpecification) string {
+	prefix := "functional_index"
+	if keys[0].Expr == nil {
+		prefix = keys[0].Column.Name.O
+	}
+	name := prefix
+	for i := 2; tb.tbl.FindIndexByName(strings.ToLower(name)) != nil ||
+		strings.EqualFold(name, mysql.PrimaryKeyName); i++ {
+		name = fmt.Sprintf("%s_%d", prefix, i)
+	}
+	return name
+}
+
+// indexColumnLength checks the column of an index and returns its length in
+// bytes, length is the prefix length or UnspecifiedLength.
+func indexColumnLength(col *model.ColumnInfo, length int) (int, error) {
+	tp := col.GetType()
+	switch {
+	case tp == mysql.TypeJSON:
+		return 0, ErrJSONUsedAsKey.GenWithStackByArgs(col.Name.O)
+	case types.IsTypeBlob(tp) && length == types.UnspecifiedLength:
+		return 0, ErrBlobKeyWithoutLength.GenWithStackByArgs(col.Name.O)
+	}
+	if length != types.UnspecifiedLength {
+		if !types.IsTypeChar(tp) && !types.IsTypeBlob(tp) || length == 0 ||
+			(col.GetFlen() != types.UnspecifiedLength && length > col.GetFlen()) {
+			return 0, ErrWrongSubKey.GenWithStackByArgs()
+		}
+	}
+	switch {
+	case types.IsTypeChar(tp) || types.IsTypeBlob(tp):
+		if length == types.UnspecifiedLength {
+			length = col.GetFlen()
+		}
+		return length * maxLenOf(col.GetCharset()), nil
+	case tp == mysql.TypeNewDecimal:
+		return decimalLength(col.GetFlen(), col.GetDecimal()), nil
+	case tp == mysql.TypeBit:
+		return (col.GetFlen() + 7) / 8, nil
+	case tp == mysql.TypeDatetime || tp == mysql.TypeTimestamp || tp == mysql.TypeDuration:
+		return mysql.DefaultLengthOfMysqlTypes[tp] + mysql.DefaultLengthOfTimeFraction[col.GetDecimal()], nil
+	}
+	return mysql.DefaultLengthOfMysqlTypes[tp], nil
+}
+
+// decimalLength returns the storage length of a DECIMAL(precision, frac),
+// which stores nine digits in four bytes.
+func decimalLength(precision, frac int) int {
+	digitBytes := [...]int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+	intg := precision - frac
+	return intg/9*4 + digitBytes[intg%9] + frac/9*4 + digitBytes[frac%9]
+}
+
+// buildHiddenColumn adds the hidden virtual column of the i-th expression of
+// a functional index.
+func (tb *tableBuilder) buildHiddenColumn(idxName string, i int, expr ast.ExprNode) (*model.ColumnInfo, error) {
+	if _, ok := expr.(*ast.ColumnNameExpr); ok {
+		return nil, ErrFunctionalIndexOnField.GenWithStackByArgs()
+	}
+	deps := make(map[string]struct{})
+	for _, name := range columnNames(expr) {
+		col := model.FindColumnInfo(tb.tbl.Columns, name)
+		if col == nil {
+			return nil, ErrBadField.GenWithStackByArgs(name, "functional index")
+		}
+		if mysql.HasAutoIncrementFlag(col.GetFlag()) {
+			return nil, ErrFunctionalIndexRefAutoIncrement.GenWithStackByArgs(idxName)
+		}
+		deps[name] = struct{}{}
+	}
+	expr.Accept(&columnBinder{tbl: tb.tbl})
+	inferrer := typeinfer.NewInferrer()
+	inferrer.Charset, inferrer.Collation = tb.tbl.Charset, tb.tbl.Collate
+	if err := inferrer.Infer(expr); err != nil {
+		return nil, err
+	}
+	ft := expr.GetType().Clone()
+	switch {
+	case types.IsTypeBlob(ft.GetType()):
+		return nil, ErrFunctionalIndexOnLob.GenWithStackByArgs()
+	case ft.GetType() == mysql.TypeJSON || ft.GetType() == mysql.TypeGeometry:
+		return nil, ErrFunctionalIndexOnJSONOrGeometryFunction.GenWithStackByArgs()
+	}
+	exprString, err := restoreExpr(expr, exprRestoreFlags)
+	if err != nil {
+		return nil, err
+	}
+	col := &model.ColumnInfo{
+		Name:                model.NewCIStr(fmt.Sprintf("_V$_%s_%d", idxName, i)),
+		FieldType:           *ft,
+		State:               model.StatePublic,
+		Version:             model.CurrLatestColumnInfoVersion,
+		GeneratedExprString: exprString,
+		Dependences:         deps,
+		Hidden:              true,
+	}
+	if err := tb.addColumn(col); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// columnBinder binds the columns of an expression to the columns of a table
+// for the type inference.
+type columnBinder struct {
+	tbl *model.TableInfo
+}
+
+// Enter implements ast.Visitor interface.
+func (v *columnBinder) Enter(n ast.Node) (ast.Node, bool) {
+	if c, ok := n.(*ast.ColumnNameExpr); ok {
+		if col := model.FindColumnInfo(v.tbl.Columns, c.Name.Name.L); col != nil {
+			c.Refer = &ast.ResultField{Column: col, ColumnAsName: col.Name, Table: v.tbl, TableAsName: v.tbl.Name}
+		}
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (v *columnBinder) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// buildCheck builds a check constraint, a constraint without a name is named
+// like <table>_chk_<n>.
+func (tb *tableBuilder) buildCheck(c *ast.Constraint) error {
+	name := c.Name
+	if name == "" {
+		for n := len(tb.tbl.Constraints) + 1; name == "" || tb.tbl.FindConstraintInfoByName(name) != nil; n++ {
+			name = fmt.Sprintf("%s_chk_%d", tb.tbl.Name.O, n)
+		}
+	} else if tb.tbl.FindConstraintInfoByName(name) != nil {
+		return ErrCheckConstraintDupName.GenWithStackByArgs(name)
+	}
+	var cols []model.CIStr
+	for _, dep := range columnNames(c.Expr) {
+		col := model.FindColumnInfo(tb.tbl.Columns, dep)
+		if col == nil {
+			return ErrBadField.GenWithStackByArgs(dep, fmt.Sprintf("check constraint %s expression", name))
+		}
+		cols = append(cols, col.Name)
+	}
+	expr, err := restoreExpr(c.Expr, exprRestoreFlags)
+	if err != nil {
+		return err
+	}
+	tb.tbl.MaxConstraintID++
+	tb.tbl.Constraints = append(tb.tbl.Constraints, &model.ConstraintInfo{
+		ID:             tb.tbl.MaxConstraintID,
+		Name:           model.NewCIStr(name),
+		Table:          tb.tbl.Name,
+		ConstraintCols: cols,
+		Enforced:       c.Enforced,
+		InColumn:       c.InColumn,
+		ExprString:     expr,
+		State:          model.StatePublic,
+	})
+	return nil
+}
+
+// buildForeignKey builds a foreign key, a foreign key without a name is named
+// like <table>_ibfk_<n>. An index of the columns is added if no index starts
+// with them.
+func (tb *tableBuilder) buildForeignKey(c *ast.Constraint) error {
+	name := c.Name
+	if name == "" {
+		name = fmt.Sprintf("%s_ibfk_%d", tb.tbl.Name.O, len(tb.tbl.ForeignKeys)+1)
+	}
+	for _, fk := range tb.tbl.ForeignKeys {
+		if fk.Name.L == strings.ToLower(name) {
+			return ErrFkDupName.GenWithStackByArgs(name)
+		}
+	}
+	if len(c.Keys) != len(c.Refer.IndexPartSpecifications) {
+		return ErrWrongFkDef.GenWithStackByArgs(name, "Key reference and table reference don't match")
+	}
+	fk := &model.FKInfo{
+		ID:        int64(len(tb.tbl.ForeignKeys) + 1),
+		Name:      model.NewCIStr(name),
+		RefSchema: c.Refer.Table.Schema,
+		RefTable:  c.Refer.Table.Name,
+		State:     model.StatePublic,
+	}
+	for _, key := range c.Keys {
+		col := model.FindColumnInfo(tb.tbl.Columns, key.Column.Name.L)
+		if col == nil {
+			return ErrKeyColumnDoesNotExits.GenWithStackByArgs(key.Column.Name.O)
+		}
+		fk.Cols = append(fk.Cols, col.Name)
+	}
+	for _, key := range c.Refer.IndexPartSpecifications {
+		fk.RefCols = append(fk.RefCols, key.Column.Name)
+	}
+	if c.Refer.OnDelete != nil {
+		fk.OnDelete = int(c.Refer.OnDelete.ReferOpt)
+	}
+	if c.Refer.OnUpdate != nil {
+		fk.OnUpdate = int(c.Refer.OnUpdate.ReferOpt)
+	}
+	tb.tbl.ForeignKeys = append(tb.tbl.ForeignKeys, fk)
+
+	if tb.hasIndexOn(fk.Cols) {
+		return nil
+	}
+	keys := make([]*ast.IndexPartSpecification, 0, len(c.Keys))
+	for _, key := range c.Keys {
+		keys = append(keys, &ast.IndexPartSpecification{Column: key.Column, Length: types.UnspecifiedLength})
+	}
+	_, err := tb.buildIndex(&ast.Constraint{Tp: ast.ConstraintIndex, Name: c.Name, Keys: keys}, false, false)
+	return err
+}
+
+// hasIndexOn returns whether an index starts with the columns.
+func (tb *tableBuilder) hasIndexOn(cols []model.CIStr) bool {
+	if tb.tbl.PKIsHandle && len(cols) == 1 {
+		if pk := tb.tbl.GetPkColInfo(); pk != nil && pk.Name.L == cols[0].L {
+			return true
+		}
+	}
+	for _, idx := range tb.tbl.Indices {
+		if len(idx.Columns) < len(cols) {
+			continue
+		}
+		match := true
+		for i, col := range cols {
+			if idx.Columns[i].Name.L != col.L || idx.Columns[i].Length != types.UnspecifiedLength {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,515 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/pingcap/errors"
+)
+
+// objectKey identifies a database, whose table is empty, or a table.
+type objectKey struct {
+	schema, table string
+}
+
+// Step is a DDL statement of a JobPlan.
+type Step struct {
+	// Index is the index of the statement in the planned statements.
+	Index int
+	Stmt  ast.StmtNode
+	// CurrentDB is the current database of the statement, set by the USE
+	// statements before it. A runner should use it before running the
+	// statement.
+	CurrentDB string
+	// Jobs are the DDL jobs of the statement like TiDB creates, the job IDs
+	// are the sequence numbers of the jobs. A statement changing nothing, like
+	// CREATE TABLE IF NOT EXISTS of an existing table, has no jobs.
+	Jobs []*model.Job
+	// DependsOn are the indexes of the statements which must be done before
+	// the statement.
+	DependsOn []int
+
+	// writes are the objects created, changed or dropped by the statement,
+	// reads are the objects it depends on, like the referenced tables of the
+	// foreign keys. A barrier is a statement the catalog does not know, it
+	// depends on all the statements before it and the other way round.
+	writes, reads map[objectKey]bool
+	barrier       bool
+}
+
+// JobPlan is the plan of running DDL statements in parallel.
+type JobPlan struct {
+	Steps []*Step
+	// Batches are the statements which can run in parallel, a batch runs
+	// after all the batches before it are done.
+	Batches [][]*Step
+}
+
+// PlanJobs applies DDL statements to the catalog, and plans to run them in
+// parallel. Two statements depend on each other if one of their jobs depends
+// on the other by model.Job.IsDependentOn, or if they change the same table
+// or database, or one changes a table the other reads: the referenced table
+// of a foreign key, the table of CREATE TABLE LIKE or a table of a view. The
+// tables are compared by names too, so a table renamed or truncated, which
+// changes its ID, is still known.
+//
+// The USE statements are not planned, they set the CurrentDB of the steps.
+// It returns an error if a statement is not a DDL statement, or it fails on
+// the catalog, the catalog is changed by the statements before it.
+func (c *Catalog) PlanJobs(stmts []ast.StmtNode) (*JobPlan, error) {
+	p := &jobPlanner{Catalog: c}
+	plan := &JobPlan{}
+	for i, stmt := range stmts {
+		if use, ok := stmt.(*ast.UseStmt); ok {
+			if err := c.Apply(use); err != nil {
+				return nil, errors.Annotatef(err, "statement %d", i)
+			}
+			continue
+		}
+		if _, ok := stmt.(ast.DDLNode); !ok {
+			return nil, errors.Errorf("statement %d is not a DDL statement", i)
+		}
+		step := &Step{
+			Index:     i,
+			Stmt:      stmt,
+			CurrentDB: c.currentDB,
+			writes:    make(map[objectKey]bool),
+			reads:     make(map[objectKey]bool),
+		}
+		if err := p.plan(step); err != nil {
+			return nil, errors.Annotatef(err, "statement %d", i)
+		}
+		for _, job := range step.Jobs {
+			// The arguments are encoded for IsDependentOn.
+			if _, err := job.Encode(true); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	levels := make([]int, len(plan.Steps))
+	for i, step := range plan.Steps {
+		for j, prev := range plan.Steps[:i] {
+			dep, err := step.dependsOn(prev)
+			if err != nil {
+				return nil, err
+			}
+			if !dep {
+				continue
+			}
+			step.DependsOn = append(step.DependsOn, prev.Index)
+			if levels[j]+1 > levels[i] {
+				levels[i] = levels[j] + 1
+			}
+		}
+		if levels[i] == len(plan.Batches) {
+			plan.Batches = append(plan.Batches, nil)
+		}
+		plan.Batches[levels[i]] = append(plan.Batches[levels[i]], step)
+	}
+	return plan, nil
+}
+
+// dependsOn returns whether a step depends on a step before it.
+func (s *Step) dependsOn(prev *Step) (bool, error) {
+	if s.barrier || prev.barrier {
+		return true, nil
+	}
+	for _, job := range s.Jobs {
+		for _, other := range prev.Jobs {
+			dep, err := job.IsDependentOn(other)
+			if err != nil || dep {
+				return dep, errors.Trace(err)
+			}
+		}
+	}
+	for key := range s.writes {
+		if prev.writes[key] || prev.reads[key] {
+			return true, nil
+		}
+	}
+	for key := range s.reads {
+		if prev.writes[key] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// jobPlanner plans the jobs of the statements.
+type jobPlanner struct {
+	*Catalog
+	lastJobID int64
+}
+
+func (p *jobPlanner) addJob(step *Step, tp model.ActionType, db *model.DBInfo, tbl *model.TableInfo, args ...interface{}) *model.Job {
+	p.lastJobID++
+	job := &model.Job{
+		ID:         p.lastJobID,
+		Type:       tp,
+		SchemaID:   db.ID,
+		SchemaName: db.Name.O,
+		State:      model.JobStateQueueing,
+		Query:      step.Stmt.Text(),
+		Args:       args,
+	}
+	if tbl != nil {
+		job.TableID = tbl.ID
+		job.TableName = tbl.Name.O
+	}
+	step.Jobs = append(step.Jobs, job)
+	return job
+}
+
+// writeTable adds a table to the writes of a step, and the database and the
+// referenced tables of its foreign keys to the reads.
+func writeTable(step *Step, db model.CIStr, tbl *model.TableInfo) {
+	step.writes[objectKey{db.L, tbl.Name.L}] = true
+	step.reads[objectKey{schema: db.L}] = true
+	for _, fk := range tbl.ForeignKeys {
+		schema := fk.RefSchema
+		if schema.L == "" {
+			schema = db
+		}
+		step.reads[objectKey{schema.L, fk.RefTable.L}] = true
+	}
+}
+
+// lookup returns a table by the name without an error.
+func (p *jobPlanner) lookup(tn *ast.TableName) (*model.DBInfo, *model.TableInfo) {
+	db, tbl, err := p.table(tn)
+	if err != nil {
+		return nil, nil
+	}
+	return db, tbl
+}
+
+// plan applies the statement of a step and plans its jobs.
+func (p *jobPlanner) plan(step *Step) error {
+	switch x := step.Stmt.(type) {
+	case *ast.CreateDatabaseStmt:
+		return p.planDatabase(step, x.Name, model.ActionCreateSchema)
+	case *ast.AlterDatabaseStmt:
+		name := x.Name
+		if x.AlterDefaultDatabase {
+			name = model.NewCIStr(p.currentDB)
+		}
+		return p.planDatabase(step, name, model.ActionModifySchemaCharsetAndCollate)
+	case *ast.DropDatabaseStmt:
+		return p.planDatabase(step, x.Name, model.ActionDropSchema)
+	case *ast.CreateTableStmt:
+		return p.planCreateTable(step, x)
+	case *ast.CreateViewStmt:
+		return p.planCreateView(step, x)
+	case *ast.DropTableStmt:
+		return p.planDropTable(step, x)
+	case *ast.TruncateTableStmt:
+		db, tbl := p.lookup(x.Table)
+		if err := p.Apply(x); err != nil {
+			return err
+		}
+		_, nt := p.lookup(x.Table)
+		p.addJob(step, model.ActionTruncateTable, db, tbl, nt.ID)
+		writeTable(step, db.Name, tbl)
+	case *ast.RenameTableStmt:
+		return p.planRenameTables(step, x)
+	case *ast.CreateIndexStmt:
+		db, tbl := p.lookup(x.Table)
+		if err := p.Apply(x); err != nil {
+			return err
+		}
+		_, nt := p.lookup(x.Table)
+		if len(nt.Indices) != len(tbl.Indices) {
+			p.addJob(step, model.ActionAddIndex, db, tbl)
+		}
+		writeTable(step, db.Name, nt)
+	case *ast.DropIndexStmt:
+		db, tbl := p.lookup(x.Table)
+		if err := p.Apply(x); err != nil {
+			return err
+		}
+		_, nt := p.lookup(x.Table)
+		tp := model.ActionDropIndex
+		if strings.EqualFold(x.IndexName, "primary") {
+			tp = model.ActionDropPrimaryKey
+		}
+		if len(nt.Indices) != len(tbl.Indices) || tbl.PKIsHandle != nt.PKIsHandle {
+			p.addJob(step, tp, db, tbl)
+		}
+		writeTable(step, db.Name, tbl)
+	case *ast.AlterTableStmt:
+		return p.planAlterTable(step, x)
+	default:
+		step.barrier = true
+		return p.Apply(x)
+	}
+	return nil
+}
+
+func (p *jobPlanner) planDatabase(step *Step, name model.CIStr, tp model.ActionType) error {
+	before, _ := p.SchemaByName(name)
+	if err := p.Apply(step.Stmt); err != nil {
+		return err
+	}
+	after, _ := p.SchemaByName(name)
+	step.writes[objectKey{schema: name.L}] = true
+	switch {
+	case tp == model.ActionCreateSchema && before == nil:
+		p.addJob(step, tp, after, nil)
+	case tp == model.ActionModifySchemaCharsetAndCollate:
+		p.addJob(step, tp, after, nil)
+	case tp == model.ActionDropSchema && before != nil:
+		p.addJob(step, tp, before, nil)
+		for _, tbl := range before.Tables {
+			step.writes[objectKey{name.L, tbl.Name.L}] = true
+		}
+	}
+	return nil
+}
+
+func (p *jobPlanner) planCreateTable(step *Step, stmt *ast.CreateTableStmt) error {
+	_, old := p.lookup(stmt.Table)
+	if err := p.Apply(stmt); err != nil {
+		return err
+	}
+	db, tbl := p.lookup(stmt.Table)
+	if old == nil {
+		p.addJob(step, model.ActionCreateTable, db, tbl)
+	}
+	writeTable(step, db.Name, tbl)
+	if stmt.ReferTable != nil {
+		if refDB, ref := p.lookup(stmt.ReferTable); ref != nil {
+			step.reads[objectKey{refDB.Name.L, ref.Name.L}] = true
+		}
+	}
+	return nil
+}
+
+func (p *jobPlanner) planCreateView(step *Step, stmt *ast.CreateViewStmt) error {
+	if err := p.Apply(stmt); err != nil {
+		return err
+	}
+	db, tbl := p.lookup(stmt.ViewName)
+	p.addJob(step, model.ActionCreateView, db, tbl)
+	writeTable(step, db.Name, tbl)
+	for _, tn := range ast.FindAll[*ast.TableName](stmt.Select) {
+		schema := tn.Schema.L
+		if schema == "" {
+			schema = model.NewCIStr(p.currentDB).L
+		}
+		step.reads[objectKey{schema, tn.Name.L}] = true
+	}
+	return nil
+}
+
+func (p *jobPlanner) planDropTable(step *Step, stmt *ast.DropTableStmt) error {
+	type dropped struct {
+		db  *model.DBInfo
+		tbl *model.TableInfo
+	}
+	var drops []dropped
+	for _, tn := range stmt.Tables {
+		if db, tbl := p.lookup(tn); tbl != nil {
+			drops = append(drops, dropped{db, tbl})
+		}
+	}
+	if err := p.Apply(stmt); err != nil {
+		return err
+	}
+	for _, d := range drops {
+		tp := model.ActionDropTable
+		switch {
+		case d.tbl.IsView():
+			tp = model.ActionDropView
+		case d.tbl.IsSequence():
+			tp = model.ActionDropSequence
+		}
+		p.addJob(step, tp, d.db, d.tbl)
+		writeTable(step, d.db.Name, d.tbl)
+	}
+	return nil
+}
+
+func (p *jobPlanner) planRenameTables(step *Step, stmt *ast.RenameTableStmt) error {
+	var (
+		oldSchemaIDs, newSchemaIDs, tableIDs []int64
+		oldSchemaNames, newTableNames        []model.CIStr
+		tables                               []*model.TableInfo
+	)
+	// The tables are renamed one by one, so a table may be renamed again.
+	for _, t := range stmt.TableToTables {
+		oldDB, tbl := p.lookup(t.OldTable)
+		if err := p.renameTables(&ast.RenameTableStmt{TableToTables: []*ast.TableToTable{t}}); err != nil {
+			return err
+		}
+		newDB, _ := p.lookup(t.NewTable)
+		oldSchemaIDs = append(oldSchemaIDs, oldDB.ID)
+		newSchemaIDs = append(newSchemaIDs, newDB.ID)
+		tableIDs = append(tableIDs, tbl.ID)
+		oldSchemaNames = append(oldSchemaNames, oldDB.Name)
+		newTableNames = append(newTableNames, t.NewTable.Name)
+		tables = append(tables, tbl)
+		writeTable(step, oldDB.Name, tbl)
+		step.writes[objectKey{newDB.Name.L, t.NewTable.Name.L}] = true
+		step.reads[objectKey{schema: newDB.Name.L}] = true
+	}
+	newDB, _ := p.SchemaByName(p.mustSchemaName(stmt.TableToTables[0].NewTable))
+	if len(tables) == 1 {
+		p.addJob(step, model.ActionRenameTable, newDB, tables[0], oldSchemaIDs[0], newTableNames[0], oldSchemaNames[0])
+		return nil
+	}
+	var oldTableNames []model.CIStr
+	for _, tbl := range tables {
+		oldTableNames = append(oldTableNames, tbl.Name)
+	}
+	p.addJob(step, model.ActionRenameTables, newDB, tables[0], oldSchemaIDs, newSchemaIDs, newTableNames, tableIDs, oldSchemaNames, oldTableNames)
+	return nil
+}
+
+// mustSchemaName returns the database of a table name which is known to be
+// valid.
+func (p *jobPlanner) mustSchemaName(tn *ast.TableName) model.CIStr {
+	schema, _ := p.schemaName(tn)
+	return schema
+}
+
+func (p *jobPlanner) planAlterTable(step *Step, stmt *ast.AlterTableStmt) error {
+	db, tbl := p.lookup(stmt.Table)
+	if err := p.Apply(stmt); err != nil {
+		return err
+	}
+	writeTable(step, db.Name, tbl)
+	newDB, newName := db, tbl.Name
+	var actions []model.ActionType
+	for _, spec := range stmt.Specs {
+		switch spec.Tp {
+		case ast.AlterTableRenameTable:
+			newDB, _ = p.SchemaByName(p.mustSchemaName(spec.NewTable))
+			newName = spec.NewTable.Name
+		case ast.AlterTableExchangePartition:
+			if ntDB, nt := p.lookup(spec.NewTable); nt != nil {
+				writeTable(step, ntDB.Name, nt)
+			}
+		}
+		actions = append(actions, alterActions(spec)...)
+	}
+	nt, _ := p.TableByName(newDB.Name, newName)
+	writeTable(step, newDB.Name, nt)
+	if len(actions) == 0 {
+		return nil
+	}
+	if len(actions) > 1 {
+		job := p.addJob(step, model.ActionMultiSchemaChange, db, tbl)
+		job.MultiSchemaInfo = model.NewMultiSchemaInfo()
+		for _, tp := range actions {
+			job.MultiSchemaInfo.SubJobs = append(job.MultiSchemaInfo.SubJobs, &model.SubJob{Type: tp})
+		}
+		return nil
+	}
+	switch spec := stmt.Specs[len(stmt.Specs)-1]; actions[0] {
+	case model.ActionRenameTable:
+		p.addJob(step, model.ActionRenameTable, newDB, tbl, db.ID, newName, db.Name)
+	case model.ActionExchangeTablePartition:
+		// The job of EXCHANGE PARTITION is on the non-partitioned table.
+		ntDB, nt := p.lookup(spec.NewTable)
+		if nt == nil {
+			return ErrTableNotExists.GenWithStackByArgs(p.mustSchemaName(spec.NewTable).O, spec.NewTable.Name.O)
+		}
+		var defID int64
+		for _, def := range tbl.GetPartitionInfo().Definitions {
+			if def.Name.L == spec.PartitionNames[0].L {
+				defID = def.ID
+			}
+		}
+		p.addJob(step, model.ActionExchangeTablePartition, ntDB, nt, defID, db.ID, tbl.ID, spec.PartitionNames[0].O, spec.WithValidation)
+	default:
+		p.addJob(step, actions[0], db, tbl)
+	}
+	return nil
+}
+
+// alterActions returns the job types of an ALTER TABLE spec, the specs which
+// change nothing, like ENGINE, have no jobs.
+func alterActions(spec *ast.AlterTableSpec) []model.ActionType {
+	switch spec.Tp {
+	case ast.AlterTableOption:
+		var actions []model.ActionType
+		for _, op := range spec.Options {
+			switch op.Tp {
+			case ast.TableOptionComment:
+				actions = append(actions, model.ActionModifyTableComment)
+			case ast.TableOptionCharset, ast.TableOptionCollate:
+				actions = append(actions, model.ActionModifyTableCharsetAndCollate)
+			case ast.TableOptionAutoIncrement:
+				actions = append(actions, model.ActionRebaseAutoID)
+			case ast.TableOptionAutoIdCache:
+				actions = append(actions, model.ActionModifyTableAutoIdCache)
+			case ast.TableOptionAutoRandomBase:
+				actions = append(actions, model.ActionRebaseAutoRandomBase)
+			case ast.TableOptionShardRowID:
+				actions = append(actions, model.ActionShardRowID)
+			}
+		}
+		return actions
+	case ast.AlterTableAddColumns:
+		actions := make([]model.ActionType, 0, len(spec.NewColumns))
+		for range spec.NewColumns {
+			actions = append(actions, model.ActionAddColumn)
+		}
+		return actions
+	case ast.AlterTableAddConstraint:
+		switch spec.Constraint.Tp {
+		case ast.ConstraintPrimaryKey:
+			return []model.ActionType{model.ActionAddPrimaryKey}
+		case ast.ConstraintForeignKey:
+			return []model.ActionType{model.ActionAddForeignKey}
+		case ast.ConstraintCheck:
+			return []model.ActionType{model.ActionAddCheckConstraint}
+		}
+		return []model.ActionType{model.ActionAddIndex}
+	}
+	if tp, ok := alterActionTypes[spec.Tp]; ok {
+		return []model.ActionType{tp}
+	}
+	return nil
+}
+
+var alterActionTypes = map[ast.AlterTableType]model.ActionType{
+	ast.AlterTableDropColumn:          model.ActionDropColumn,
+	ast.AlterTableModifyColumn:        model.ActionModifyColumn,
+	ast.AlterTableChangeColumn:        model.ActionModifyColumn,
+	ast.AlterTableRenameColumn:        model.ActionModifyColumn,
+	ast.AlterTableAlterColumn:         model.ActionSetDefaultValue,
+	ast.AlterTableDropPrimaryKey:      model.ActionDropPrimaryKey,
+	ast.AlterTableDropIndex:           model.ActionDropIndex,
+	ast.AlterTableDropForeignKey:      model.ActionDropForeignKey,
+	ast.AlterTableRenameIndex:         model.ActionRenameIndex,
+	ast.AlterTableIndexInvisible:      model.ActionAlterIndexVisibility,
+	ast.AlterTableAlterCheck:          model.ActionAlterCheckConstraint,
+	ast.AlterTableDropCheck:           model.ActionDropCheckConstraint,
+	ast.AlterTableRenameTable:         model.ActionRenameTable,
+	ast.AlterTableAddPartitions:       model.ActionAddTablePartition,
+	ast.AlterTableDropPartition:       model.ActionDropTablePartition,
+	ast.AlterTableTruncatePartition:   model.ActionTruncateTablePartition,
+	ast.AlterTableExchangePartition:   model.ActionExchangeTablePartition,
+	ast.AlterTableSetTiFlashReplica:   model.ActionSetTiFlashReplica,
+	ast.AlterTableAttributes:          model.ActionAlterTableAttributes,
+	ast.AlterTablePartitionAttributes: model.ActionAlterTablePartitionAttributes,
+	ast.AlterTableCache:               model.ActionAlterCacheTable,
+	ast.AlterTableNoCache:             model.ActionAlterNoCacheTable,
+}
@@ -0,0 +1,237 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	. "github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/terror"
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func planJobs(t *testing.T, c *Catalog, sql string) (*JobPlan, error) {
+	stmts, _, err := parser.New().Parse(sql, "", "")
+	require.NoError(t, err, sql)
+	return c.PlanJobs(stmts)
+}
+
+// batches returns the indexes of the statements of the batches.
+func batches(plan *JobPlan) [][]int {
+	res := make([][]int, 0, len(plan.Batches))
+	for _, batch := range plan.Batches {
+		indexes := make([]int, 0, len(batch))
+		for _, step := range batch {
+			indexes = append(indexes, step.Index)
+		}
+		res = append(res, indexes)
+	}
+	return res
+}
+
+// jobs returns the jobs of the steps as "type schema.table".
+func jobs(plan *JobPlan) []string {
+	var res []string
+	for _, step := range plan.Steps {
+		var descs []string
+		for _, job := range step.Jobs {
+			descs = append(descs, fmt.Sprintf("%s %s.%s", job.Type, job.SchemaName, job.TableName))
+		}
+		res = append(res, fmt.Sprintf("%d: %s", step.Index, strings.Join(descs, ", ")))
+	}
+	return res
+}
+
+func TestPlanJobs(t *testing.T) {
+	c := NewCatalog()
+	plan, err := planJobs(t, c, `
+		create database d1;
+		create database d2;
+		use d1;
+		create table t1 (id int primary key);
+		create table t2 (id int primary key);
+		create table d2.t3 (id int);
+		alter table t1 add column a int;
+		create index i on t2 (id);
+		create table c (id int, pid int, foreign key (pid) references t1 (id));
+		create table if not exists t2 (id int);
+		create view v as select * from t2;
+		alter table t2 add column b int, add index (b), comment 'T2';
+		alter database d2 charset utf8mb4;
+		create table d2.t4 (id int)`)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"0: create schema d1.",
+		"1: create schema d2.",
+		"3: create table d1.t1",
+		"4: create table d1.t2",
+		"5: create table d2.t3",
+		"6: add column d1.t1",
+		"7: add index d1.t2",
+		"8: create table d1.c",
+		"9: ",
+		"10: create view d1.v",
+		"11: alter table multi-schema change d1.t2",
+		"12: modify schema charset and collate d2.",
+		"13: create table d2.t4",
+	}, jobs(plan))
+	// The jobs of the databases have no table IDs, they depend on each other.
+	require.Equal(t, [][]int{{0}, {1, 3, 4}, {5, 6, 7}, {8, 9, 12}, {10, 13}, {11}}, batches(plan))
+
+	steps := plan.Steps
+	require.Equal(t, "d1", steps[2].CurrentDB)
+	require.Equal(t, "", steps[1].CurrentDB)
+	require.Equal(t, []int{0, 3, 6}, steps[7].DependsOn)
+	require.Equal(t, []int{0, 4, 7, 9}, steps[9].DependsOn)
+	require.Equal(t, []int{1, 12}, steps[12].DependsOn)
+	job := steps[10].Jobs[0]
+	require.Equal(t, model.ActionMultiSchemaChange, job.Type)
+	var types []model.ActionType
+	for _, sub := range job.MultiSchemaInfo.SubJobs {
+		types = append(types, sub.Type)
+	}
+	require.Equal(t, []model.ActionType{model.ActionAddColumn, model.ActionAddIndex, model.ActionModifyTableComment}, types)
+	require.Equal(t, "create table t1 (id int primary key);", strings.TrimSpace(steps[2].Jobs[0].Query))
+	tbl, ok := c.TableByName(model.NewCIStr("d1"), model.NewCIStr("t1"))
+	require.True(t, ok)
+	require.Equal(t, tbl.ID, steps[2].Jobs[0].TableID)
+	require.Equal(t, tbl.ID, steps[5].Jobs[0].TableID)
+}
+
+func TestPlanJobsRename(t *testing.T) {
+	c := NewCatalog()
+	mustApply(t, c, `create database d1; create database d2; use d1;
+		create table t1 (id int primary key); create table t2 (id int primary key);
+		create table p (id int primary key, x int) partition by range (id) (partition p0 values less than (10), partition p1 values less than (20));
+		create table n (id int primary key, x int)`)
+	plan, err := planJobs(t, c, `
+		rename table t1 to t9;
+		create table t1 (id int);
+		alter table t9 add column b int;
+		truncate table t2;
+		alter table t2 add column b int;
+		rename table t9 to d2.t9, t1 to t8;
+		alter table t8 rename to t1;
+		create table t8 (id int);
+		alter table p exchange partition p1 with table n;
+		alter table n add column y int;
+		alter table p engine = InnoDB`)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"0: rename table d1.t1",
+		"1: create table d1.t1",
+		"2: add column d1.t9",
+		"3: truncate table d1.t2",
+		"4: add column d1.t2",
+		"5: rename tables d2.t9",
+		"6: rename table d1.t8",
+		"7: create table d1.t8",
+		"8: exchange partition d1.n",
+		"9: add column d1.n",
+		"10: ",
+	}, jobs(plan))
+	require.Equal(t, [][]int{{0, 3, 8}, {1, 2, 4, 9, 10}, {5}, {6}, {7}}, batches(plan))
+	// The truncated table has a new ID, the table is known by its name.
+	require.Equal(t, []int{3}, plan.Steps[4].DependsOn)
+	require.NotEqual(t, plan.Steps[3].Jobs[0].TableID, plan.Steps[4].Jobs[0].TableID)
+	require.Equal(t, []int{8}, plan.Steps[9].DependsOn)
+	require.Equal(t, []int{8}, plan.Steps[10].DependsOn)
+
+	var oldSchemaID int64
+	var newName model.CIStr
+	job := plan.Steps[0].Jobs[0]
+	require.NoError(t, job.DecodeArgs(&oldSchemaID, &newName))
+	require.Equal(t, job.SchemaID, oldSchemaID)
+	require.Equal(t, "t9", newName.O)
+	job = plan.Steps[5].Jobs[0]
+	db2, _ := c.SchemaByName(model.NewCIStr("d2"))
+	require.Equal(t, db2.ID, job.SchemaID)
+}
+
+func TestPlanJobsDatabase(t *testing.T) {
+	c := NewCatalog()
+	mustApply(t, c, `create database d1; create database d2; create table d1.t (id int primary key); create table d2.t (id int primary key)`)
+	plan, err := planJobs(t, c, `
+		alter table d1.t add column a int;
+		alter table d2.t add column a int;
+		drop database d1;
+		create table d2.u (id int, tid int, foreign key (tid) references t (id));
+		drop table d2.t;
+		create sequence d2.s;
+		create table d2.v (id int);
+		drop table if exists d2.none, d2.v`)
+	require.NoError(t, err)
+	require.Equal(t, [][]int{{0, 1}, {2, 3}, {4}, {5}, {6}, {7}}, batches(plan))
+	// The referenced table of the foreign key is altered before.
+	require.Equal(t, []int{1}, plan.Steps[3].DependsOn)
+	require.Equal(t, []int{0}, plan.Steps[2].DependsOn)
+	require.Equal(t, []int{1, 3}, plan.Steps[4].DependsOn)
+	require.Empty(t, plan.Steps[5].Jobs)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, plan.Steps[5].DependsOn)
+	require.Len(t, plan.Steps[7].Jobs, 1)
+	require.Equal(t, model.ActionDropTable, plan.Steps[7].Jobs[0].Type)
+}
+
+func TestPlanJobsCrossSchema(t *testing.T) {
+	c := NewCatalog()
+	plan, err := planJobs(t, c, `
+		create database d1;
+		create database d2;
+		create table d2.p (id int primary key);
+		create table d1.c (a int, foreign key (a) references d2.p (id));
+		create table d2.q (id int primary key);
+		alter table d1.c add column b int, add foreign key (b) references d2.q (id)`)
+	require.NoError(t, err)
+	// The referenced tables in another database are created before.
+	require.Equal(t, [][]int{{0}, {1}, {2, 4}, {3}, {5}}, batches(plan))
+	require.Equal(t, []int{0, 2}, plan.Steps[3].DependsOn)
+	require.Equal(t, []int{0, 2, 3, 4}, plan.Steps[5].DependsOn)
+
+	// The changes of a table referencing a table in another database are
+	// ordered after the changes of the referenced table.
+	c = NewCatalog()
+	plan, err = planJobs(t, c, `
+		create database d1;
+		create database d2;
+		create table d2.p (id int primary key);
+		create table d1.c (a int, foreign key (a) references d2.p (id));
+		alter table d2.p add column x int;
+		create index ia on d1.c (a);
+		create table d1.q (id int primary key);
+		create table d1.r (a int, foreign key (a) references q (id));
+		rename table d1.r to d2.r;
+		alter table d1.q add column x int;
+		alter table d2.r add column b int`)
+	require.NoError(t, err)
+	require.Contains(t, plan.Steps[5].DependsOn, 4)
+	require.Contains(t, plan.Steps[10].DependsOn, 9)
+}
+
+func TestPlanJobsErrors(t *testing.T) {
+	c := NewCatalog()
+	_, err := planJobs(t, c, "create database d; create table d.t (id int); insert into d.t values (1)")
+	require.EqualError(t, err, "statement 2 is not a DDL statement")
+
+	_, err = planJobs(t, c, "create table d.u (id int); create table e.t (id int)")
+	require.True(t, terror.ErrorEqual(ErrDatabaseNotExists, errors.Cause(err)), "%v", err)
+	require.Contains(t, err.Error(), "statement 1")
+	// The statements before the failed one are applied.
+	_, ok := c.TableByName(model.NewCIStr("d"), model.NewCIStr("u"))
+	require.True(t, ok)
+}
@@ -0,0 +1,503 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+)
+
+// Impact is how an ALTER TABLE operation runs online in MySQL 8.0.
+type Impact struct {
+	// Algorithm is the fastest algorithm which supports the operation.
+	Algorithm ast.AlgorithmType
+	// Lock is the weakest lock which permits the operation with the
+	// algorithm: LockTypeNone permits concurrent reads and writes,
+	// LockTypeShared permits concurrent reads only, and LockTypeExclusive
+	// permits neither.
+	Lock ast.LockType
+	// Rebuild is true if the operation rebuilds the table.
+	Rebuild bool
+	// Reason is why a faster algorithm or a weaker lock is not supported.
+	Reason string
+
+	// rebuildInplace is true if the operation rebuilds the table when an
+	// INSTANT operation runs INPLACE, like adding a column.
+	rebuildInplace bool
+}
+
+// SpecImpact is the impact of a spec of ALTER TABLE.
+type SpecImpact struct {
+	Spec *ast.AlterTableSpec
+	Impact
+}
+
+// AlterImpact is the impact of an ALTER TABLE statement.
+type AlterImpact struct {
+	// Impact is the impact of the statement, which is the combination of the
+	// specs with the ALGORITHM and the LOCK clauses applied.
+	Impact
+	// Specs are the impacts of the specs but the ALGORITHM and LOCK clauses.
+	Specs []SpecImpact
+}
+
+var (
+	instant = Impact{Algorithm: ast.AlgorithmTypeInstant, Lock: ast.LockTypeNone}
+	inplace = Impact{Algorithm: ast.AlgorithmTypeInplace, Lock: ast.LockTypeNone}
+	copying = Impact{Algorithm: ast.AlgorithmTypeCopy, Lock: ast.LockTypeShared, Rebuild: true}
+)
+
+// The reasons of the impacts, most of them are the messages of MySQL.
+var (
+	reasonColumnType = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonColumnType].Raw
+	reasonCopy       = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonCopy].Raw
+	reasonPartition  = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonPartition].Raw
+	reasonFkCheck    = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonFkCheck].Raw
+	reasonNopk       = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonNopk].Raw
+	reasonAutoinc    = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonAutoinc].Raw
+	reasonFts        = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonFts].Raw
+	reasonFkRename   = mysql.MySQLErrName[mysql.ErrAlterOperationNotSupportedReasonFkRename].Raw
+	reasonRebuild    = "The operation rebuilds the table"
+	reasonMetadata   = "The operation changes the metadata in the data dictionary"
+	reasonStored     = "Stored generated columns are computed by a table copy"
+	reasonCheck      = "The enforced check constraints are validated by a table copy"
+)
+
+func (i Impact) with(reason string) Impact {
+	i.Reason = reason
+	return i
+}
+
+func (i Impact) rebuild() Impact {
+	i.Rebuild = true
+	return i
+}
+
+func (i Impact) locked(lock ast.LockType) Impact {
+	i.Lock = lock
+	return i
+}
+
+func algorithmRank(a ast.AlgorithmType) int {
+	switch a {
+	case ast.AlgorithmTypeInstant:
+		return 0
+	case ast.AlgorithmTypeInplace:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func lockRank(l ast.LockType) int {
+	switch l {
+	case ast.LockTypeNone:
+		return 0
+	case ast.LockTypeShared:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// merge returns the impact of two operations run by one statement, the
+// reason is the one of the slower algorithm or the stronger lock.
+func (i Impact) merge(o Impact) Impact {
+	res := i
+	if algorithmRank(o.Algorithm) > algorithmRank(i.Algorithm) {
+		res.Algorithm, res.Reason = o.Algorithm, o.Reason
+	}
+	if lockRank(o.Lock) > lockRank(res.Lock) {
+		res.Lock = o.Lock
+		if lockRank(o.Lock) > lockRank(i.Lock) && o.Reason != "" {
+			res.Reason = o.Reason
+		}
+	}
+	res.Rebuild = i.Rebuild || o.Rebuild
+	res.rebuildInplace = i.rebuildInplace || o.rebuildInplace
+	return res
+}
+
+// AnalyzeAlter returns the impact of an ALTER TABLE, CREATE INDEX or DROP INDEX
+// statement on the table in MySQL 8.0.29 or later, or nil for the other
+// statements. It returns an error if the statement cannot be applied to the
+// table, or its ALGORITHM or LOCK clause is not supported like MySQL. The
+// foreign_key_checks variable is assumed to be enabled, so that adding foreign
+// keys copies the table.
+func AnalyzeAlter(tbl *model.TableInfo, stmt ast.StmtNode) (*AlterImpact, error) {
+	var specs []*ast.AlterTableSpec
+	var lockAlg *ast.IndexLockAndAlgorithm
+	switch x := stmt.(type) {
+	case *ast.AlterTableStmt:
+		specs = x.Specs
+	case *ast.CreateIndexStmt:
+		specs, lockAlg = []*ast.AlterTableSpec{createIndexSpec(x)}, x.LockAlg
+	case *ast.DropIndexStmt:
+		specs, lockAlg = []*ast.AlterTableSpec{dropIndexSpec(x)}, x.LockAlg
+	default:
+		return nil, nil
+	}
+
+	algorithm, lock := ast.AlgorithmTypeDefault, ast.LockTypeDefault
+	if lockAlg != nil {
+		algorithm, lock = lockAlg.AlgorithmTp, lockAlg.LockTp
+	}
+	b := NewBuilder()
+	b.Charset, b.Collation = tbl.Charset, tbl.Collate
+	a := &alterAnalyzer{
+		tableBuilder: &tableBuilder{Builder: b, tbl: cloneTable(tbl), hasDefault: make(map[string]bool), nullable: make(map[string]bool)},
+		specs:        specs,
+	}
+	res := &AlterImpact{Impact: instant}
+	for _, spec := range specs {
+		switch spec.Tp {
+		case ast.AlterTableAlgorithm:
+			algorithm = spec.Algorithm
+			continue
+		case ast.AlterTableLock:
+			lock = spec.LockType
+			continue
+		}
+		impact, err := a.analyze(spec)
+		if err != nil {
+			return nil, err
+		}
+		res.Specs = append(res.Specs, SpecImpact{Spec: spec, Impact: impact})
+		res.Impact = res.Impact.merge(impact)
+	}
+	if err := a.checkTable(); err != nil {
+		return nil, err
+	}
+	impact, err := applyLockAndAlgorithm(res.Impact, algorithm, lock)
+	if err != nil {
+		return nil, err
+	}
+	res.Impact = impact
+	return res, nil
+}
+
+// applyLockAndAlgorithm returns the impact with the ALGORITHM and the LOCK
+// clauses, a slower algorithm or a stronger lock is always permitted.
+func applyLockAndAlgorithm(impact Impact, algorithm ast.AlgorithmType, lock ast.LockType) (Impact, error) {
+	explicitLock := lock != ast.LockTypeDefault && lock != 0
+	switch {
+	case algorithm == ast.AlgorithmTypeDefault:
+		// The operations of INSTANT only permit LOCK=DEFAULT, MySQL runs them
+		// INPLACE if a lock is specified.
+		if explicitLock && impact.Algorithm == ast.AlgorithmTypeInstant {
+			algorithm = ast.AlgorithmTypeInplace
+		} else {
+			algorithm = impact.Algorithm
+		}
+	case algorithmRank(algorithm) < algorithmRank(impact.Algorithm):
+		return impact, ErrAlterOperationNotSupportedReason.GenWithStackByArgs(
+			"ALGORITHM="+algorithm.String(), impact.Reason, "ALGORITHM="+impact.Algorithm.String())
+	case algorithm == ast.AlgorithmTypeInstant && explicitLock:
+		return impact, ErrAlterOperationNotSupported.GenWithStackByArgs("LOCK="+lock.String(), "LOCK=DEFAULT")
+	}
+
+	if algorithm != impact.Algorithm {
+		impact.Algorithm = algorithm
+		switch algorithm {
+		case ast.AlgorithmTypeInplace:
+			impact.Rebuild = impact.Rebuild || impact.rebuildInplace
+		case ast.AlgorithmTypeCopy:
+			impact.Rebuild = true
+			if impact.Lock == ast.LockTypeNone {
+				impact.Lock, impact.Reason = ast.LockTypeShared, reasonCopy
+			}
+		}
+	}
+	if explicitLock {
+		if lockRank(lock) < lockRank(impact.Lock) {
+			return impact, ErrAlterOperationNotSupportedReason.GenWithStackByArgs(
+				"LOCK="+lock.String(), impact.Reason, "LOCK="+impact.Lock.String())
+		}
+		impact.Lock = lock
+	}
+	return impact, nil
+}
+
+type alterAnalyzer struct {
+	*tableBuilder
+	specs []*ast.AlterTableSpec
+}
+
+// analyze returns the impact of a spec, and applies the spec to the table.
+func (a *alterAnalyzer) analyze(spec *ast.AlterTableSpec) (Impact, error) {
+	impact, err := a.impactOf(spec)
+	if err != nil {
+		return impact, err
+	}
+	switch spec.Tp {
+	case ast.AlterTableModifyColumn, ast.AlterTableChangeColumn:
+		// The impact of changing a column depends on the new column.
+		return impact, nil
+	}
+	return impact, a.alter(spec)
+}
+
+func (a *alterAnalyzer) impactOf(spec *ast.AlterTableSpec) (Impact, error) {
+	switch spec.Tp {
+	case ast.AlterTableOption:
+		return a.optionsImpact(spec.Options), nil
+	case ast.AlterTableAddColumns:
+		return a.addColumnsImpact(spec), nil
+	case ast.AlterTableDropColumn:
+		return a.dropColumnImpact(spec.OldColumnName.Name), nil
+	case ast.AlterTableModifyColumn, ast.AlterTableChangeColumn:
+		return a.changeColumnImpact(spec)
+	case ast.AlterTableRenameColumn:
+		if a.isForeignKeyColumn(spec.OldColumnName.Name) {
+			return inplace.with(reasonFkRename), nil
+		}
+		return instant, nil
+	case ast.AlterTableAlterColumn, ast.AlterTableRenameTable, ast.AlterTableIndexInvisible:
+		return instant, nil
+	case ast.AlterTableAddConstraint:
+		return a.constraintImpact(spec.Constraint), nil
+	case ast.AlterTableDropPrimaryKey:
+		for _, other := range a.specs {
+			if other.Tp == ast.AlterTableAddConstraint && other.Constraint.Tp == ast.ConstraintPrimaryKey {
+				return inplace.rebuild().with(reasonRebuild), nil
+			}
+		}
+		return copying.with(reasonNopk), nil
+	case ast.AlterTableDropIndex, ast.AlterTableRenameIndex, ast.AlterTableDropForeignKey, ast.AlterTableDropCheck:
+		return inplace.with(reasonMetadata), nil
+	case ast.AlterTableAlterCheck:
+		if spec.Constraint.Enforced {
+			return copying.with(reasonCheck), nil
+		}
+		return inplace.with(reasonMetadata), nil
+	case ast.AlterTableForce:
+		return inplace.rebuild().with(reasonRebuild), nil
+	case ast.AlterTableOrderByColumns:
+		return copying.with(reasonRebuild), nil
+	case ast.AlterTableAddPartitions, ast.AlterTableDropPartition:
+		if pi := a.tbl.Partition; pi != nil && (pi.Type == model.PartitionTypeHash || pi.Type == model.PartitionTypeKey) {
+			return inplace.rebuild().locked(ast.LockTypeShared).with(reasonPartition), nil
+		}
+		return inplace.with(reasonPartition), nil
+	case ast.AlterTableCoalescePartitions, ast.AlterTableReorganizePartition, ast.AlterTableRebuildPartition,
+		ast.AlterTableOptimizePartition:
+		return inplace.rebuild().locked(ast.LockTypeShared).with(reasonPartition), nil
+	case ast.AlterTableCheckPartitions, ast.AlterTableRepairPartition:
+		return inplace.locked(ast.LockTypeShared).with(reasonPartition), nil
+	case ast.AlterTableTruncatePartition, ast.AlterTableExchangePartition,
+		ast.AlterTableImportPartitionTablespace, ast.AlterTableDiscardPartitionTablespace,
+		ast.AlterTableImportTablespace, ast.AlterTableDiscardTablespace:
+		return inplace.locked(ast.LockTypeExclusive).with(reasonPartition), nil
+	case ast.AlterTablePartition, ast.AlterTableRemovePartitioning:
+		return copying.with(reasonPartition), nil
+	}
+	return inplace.with(reasonMetadata), nil
+}
+
+func (a *alterAnalyzer) optionsImpact(options []*ast.TableOption) Impact {
+	impact := instant
+	for _, op := range options {
+		switch op.Tp {
+		case ast.TableOptionComment:
+		case ast.TableOptionCharset, ast.TableOptionCollate:
+			if op.UintValue == ast.TableOptionCharsetWithConvertTo {
+				impact = impact.merge(copying.with(reasonColumnType))
+			} else {
+				impact = impact.merge(inplace.locked(ast.LockTypeShared).with(reasonMetadata))
+			}
+		case ast.TableOptionEngine:
+			if strings.EqualFold(op.StrValue, "InnoDB") {
+				impact = impact.merge(inplace.rebuild().with(reasonRebuild))
+			} else {
+				impact = impact.merge(copying.with(reasonRebuild))
+			}
+		case ast.TableOptionRowFormat, ast.TableOptionKeyBlockSize:
+			impact = impact.merge(inplace.rebuild().with(reasonRebuild))
+		case ast.TableOptionEncryption:
+			impact = impact.merge(copying.with(reasonRebuild))
+		default:
+			impact = impact.merge(inplace.with(reasonMetadata))
+		}
+	}
+	return impact
+}
+
+// addColumnsImpact returns the impact of ADD COLUMN, adding a column is
+// INSTANT at any position but the auto increment and the stored generated
+// columns.
+func (a *alterAnalyzer) addColumnsImpact(spec *ast.AlterTableSpec) Impact {
+	impact := instant
+	impact.rebuildInplace = true
+	for _, def := range spec.NewColumns {
+		for _, op := range def.Options {
+			switch op.Tp {
+			case ast.ColumnOptionAutoIncrement:
+				impact = impact.merge(inplace.rebuild().locked(ast.LockTypeShared).with(reasonAutoinc))
+			case ast.ColumnOptionGenerated:
+				if op.Stored {
+					impact = impact.merge(copying.with(reasonStored))
+				}
+			case ast.ColumnOptionPrimaryKey:
+				impact = impact.merge(a.constraintImpact(&ast.Constraint{Tp: ast.ConstraintPrimaryKey}))
+			case ast.ColumnOptionUniqKey:
+				impact = impact.merge(a.constraintImpact(&ast.Constraint{Tp: ast.ConstraintUniq}))
+			case ast.ColumnOptionCheck:
+				impact = impact.merge(a.constraintImpact(&ast.Constraint{Tp: ast.ConstraintCheck, Enforced: op.Enforced}))
+			case ast.ColumnOptionReference:
+				impact = impact.merge(a.constraintImpact(&ast.Constraint{Tp: ast.ConstraintForeignKey}))
+			}
+		}
+	}
+	for _, c := range spec.NewConstraints {
+		impact = impact.merge(a.constraintImpact(c))
+	}
+	return impact
+}
+
+// dropColumnImpact returns the impact of DROP COLUMN, dropping a column is
+// INSTANT but the stored generated columns and the primary key columns.
+func (a *alterAnalyzer) dropColumnImpact(name model.CIStr) Impact {
+	col := model.FindColumnInfo(a.tbl.Columns, name.L)
+	switch {
+	case col == nil:
+		return instant
+	case col.IsGenerated() && col.GeneratedStored, a.isPrimaryKeyColumn(col):
+		return inplace.rebuild().with(reasonRebuild)
+	}
+	impact := instant
+	impact.rebuildInplace = true
+	return impact
+}
+
+func (a *alterAnalyzer) constraintImpact(c *ast.Constraint) Impact {
+	switch c.Tp {
+	case ast.ConstraintPrimaryKey:
+		return inplace.rebuild().with(reasonRebuild)
+	case ast.ConstraintFulltext:
+		return inplace.rebuild().locked(ast.LockTypeShared).with(reasonFts)
+	case ast.ConstraintForeignKey:
+		return copying.with(reasonFkCheck)
+	case ast.ConstraintCheck:
+		if c.Enforced {
+			return copying.with(reasonCheck)
+		}
+	}
+	return inplace.with(reasonMetadata)
+}
+
+func (a *alterAnalyzer) isForeignKeyColumn(name model.CIStr) bool {
+	for _, fk := range a.tbl.ForeignKeys {
+		for _, col := range fk.Cols {
+			if col.L == name.L {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changeColumnImpact applies MODIFY COLUMN or CHANGE COLUMN, and returns the
+// impact by the old and the new column.
+func (a *alterAnalyzer) changeColumnImpact(spec *ast.AlterTableSpec) (Impact, error) {
+	def := spec.NewColumns[0]
+	oldName := def.Name.Name
+	if spec.Tp == ast.AlterTableChangeColumn {
+		oldName = spec.OldColumnName.Name
+	}
+	var old *model.ColumnInfo
+	if col := model.FindColumnInfo(a.tbl.Columns, oldName.L); col != nil {
+		old = col.Clone()
+	}
+	if err := a.alter(spec); err != nil || old == nil {
+		return instant, err
+	}
+	col := model.FindColumnInfo(a.tbl.Columns, def.Name.Name.L)
+
+	impact := instant
+	if old.Name.L != col.Name.L && a.isForeignKeyColumn(col.Name) {
+		impact = impact.merge(inplace.with(reasonFkRename))
+	}
+	if spec.Position != nil && spec.Position.Tp != ast.ColumnPositionNone && old.Offset != col.Offset {
+		impact = impact.merge(inplace.rebuild().with(reasonRebuild))
+	}
+	oldFlag, flag := old.GetFlag(), col.GetFlag()
+	switch {
+	case old.IsGenerated() != col.IsGenerated() || old.GeneratedStored != col.GeneratedStored ||
+		old.GeneratedExprString != col.GeneratedExprString:
+		impact = impact.merge(copying.with(reasonColumnType))
+	case !old.FieldType.Equal(&col.FieldType) || mysql.HasZerofillFlag(oldFlag) != mysql.HasZerofillFlag(flag):
+		impact = impact.merge(typeChangeImpact(old, col))
+	}
+	if !mysql.HasAutoIncrementFlag(oldFlag) && mysql.HasAutoIncrementFlag(flag) {
+		impact = impact.merge(copying.with(reasonAutoinc))
+	}
+	if mysql.HasNotNullFlag(oldFlag) != mysql.HasNotNullFlag(flag) {
+		impact = impact.merge(inplace.rebuild().with(reasonRebuild))
+	}
+	return impact, nil
+}
+
+// typeChangeImpact returns the impact of changing the type of a column, only
+// extending VARCHAR within the same number of length bytes and appending the
+// members of ENUM and SET without changing the storage size are not COPY.
+func typeChangeImpact(old, col *model.ColumnInfo) Impact {
+	same := old.GetType() == col.GetType() && old.GetCharset() == col.GetCharset() &&
+		old.GetCollate() == col.GetCollate() && old.GetFlag()&mysql.ZerofillFlag == col.GetFlag()&mysql.ZerofillFlag
+	if !same {
+		return copying.with(reasonColumnType)
+	}
+	switch col.GetType() {
+	case mysql.TypeVarchar:
+		oldLen, newLen := old.GetFlen()*maxLenOf(old.GetCharset()), col.GetFlen()*maxLenOf(col.GetCharset())
+		if newLen >= oldLen && (oldLen > 255) == (newLen > 255) {
+			return inplace.with(reasonColumnType)
+		}
+	case mysql.TypeEnum, mysql.TypeSet:
+		oldElems, elems := old.GetElems(), col.GetElems()
+		if len(elems) < len(oldElems) {
+			break
+		}
+		for i, e := range oldElems {
+			if elems[i] != e {
+				return copying.with(reasonColumnType)
+			}
+		}
+		if storageSize(col.GetType(), len(oldElems)) == storageSize(col.GetType(), len(elems)) {
+			return instant
+		}
+	}
+	return copying.with(reasonColumnType)
+}
+
+// storageSize returns the number of bytes of an ENUM or a SET value.
+func storageSize(tp byte, members int) int {
+	if tp == mysql.TypeEnum {
+		if members <= 255 {
+			return 1
+		}
+		return 2
+	}
+	switch n := (members + 7) / 8; {
+	case n <= 4:
+		if n == 0 {
+			return 1
+		}
+		return n
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	. "github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/terror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeAlter(t *testing.T) {
+	b := NewBuilder()
+	tbl := mustBuild(t, b, "create table t (id int primary key, a varchar(10), b int, c enum('x','y'), f int, "+
+		"g int as (b + 1) stored, key kb (b), constraint fk foreign key (f) references p (id), constraint ck check (b > 0))")
+	hash := mustBuild(t, b, "create table h (a int) partition by hash (a) partitions 4")
+	cases := []struct {
+		sql    string
+		impact string
+	}{
+		{"alter table t add column x int", "INSTANT NONE false"},
+		{"alter table t add column x int first, drop column a", "INSTANT NONE false"},
+		{"alter table t add column x int auto_increment, add key (x)", "INPLACE SHARED true"},
+		{"alter table t add column x int as (id + 1) stored", "COPY SHARED true"},
+		{"alter table t drop column g", "INPLACE NONE true"},
+		{"alter table t drop column id", "INPLACE NONE true"},
+		{"alter table t rename column a to x", "INSTANT NONE false"},
+		{"alter table t rename column f to x", "INPLACE NONE false"},
+		{"alter table t modify a varchar(20)", "INPLACE NONE false"},
+		{"alter table t modify a varchar(100)", "COPY SHARED true"},
+		{"alter table t modify a varchar(5)", "COPY SHARED true"},
+		{"alter table t modify a text", "COPY SHARED true"},
+		{"alter table t modify a varchar(10) not null", "INPLACE NONE true"},
+		{"alter table t modify a varchar(10) default 'x' comment 'a'", "INSTANT NONE false"},
+		{"alter table t modify a varchar(10) after f", "INPLACE NONE true"},
+		{"alter table t change a x varchar(10)", "INSTANT NONE false"},
+		{"alter table t modify c enum('x','y','z')", "INSTANT NONE false"},
+		{"alter table t modify c enum('y','x')", "COPY SHARED true"},
+		{"alter table t alter column b set default 1", "INSTANT NONE false"},
+		{"alter table t add index ka (a)", "INPLACE NONE false"},
+		{"alter table t add fulltext index ka (a)", "INPLACE SHARED true"},
+		{"alter table t drop index kb", "INPLACE NONE false"},
+		{"alter table t rename index kb to kx", "INPLACE NONE false"},
+		{"alter table t alter index kb invisible", "INSTANT NONE false"},
+		{"alter table t drop primary key", "COPY SHARED true"},
+		{"alter table t drop primary key, add primary key (id, b)", "INPLACE NONE true"},
+		{"alter table t add constraint fk2 foreign key (b) references p (id)", "COPY SHARED true"},
+		{"alter table t drop foreign key fk", "INPLACE NONE false"},
+		{"alter table t add constraint ck2 check (b < 10)", "COPY SHARED true"},
+		{"alter table t add constraint ck2 check (b < 10) not enforced", "INPLACE NONE false"},
+		{"alter table t alter check ck not enforced", "INPLACE NONE false"},
+		{"alter table t comment 'x'", "INSTANT NONE false"},
+		{"alter table t auto_increment = 100", "INPLACE NONE false"},
+		{"alter table t charset latin1", "INPLACE SHARED false"},
+		{"alter table t convert to character set latin1", "COPY SHARED true"},
+		{"alter table t engine = innodb", "INPLACE NONE true"},
+		{"alter table t force", "INPLACE NONE true"},
+		{"alter table t rename to u", "INSTANT NONE false"},
+		{"alter table t partition by hash (id) partitions 2", "COPY SHARED true"},
+		{"create index ka on t (a)", "INPLACE NONE false"},
+		{"drop index kb on t", "INPLACE NONE false"},
+		{"alter table t add column x int, algorithm = inplace", "INPLACE NONE true"},
+		{"alter table t add column x int, lock = none", "INPLACE NONE true"},
+		{"alter table t add column x int, algorithm = copy", "COPY SHARED true"},
+		{"alter table t add index ka (a), lock = shared", "INPLACE SHARED false"},
+		{"alter table t modify a text, algorithm = copy, lock = exclusive", "COPY EXCLUSIVE true"},
+		{"create index ka on t (a) algorithm = inplace lock = none", "INPLACE NONE false"},
+		{"alter table h add partition partitions 2", "INPLACE SHARED true"},
+		{"alter table h coalesce partition 2", "INPLACE SHARED true"},
+		{"alter table h truncate partition p0", "INPLACE EXCLUSIVE false"},
+		{"alter table h remove partitioning", "COPY SHARED true"},
+	}
+	for _, c := range cases {
+		stmt, err := parser.New().ParseOneStmt(c.sql, "", "")
+		require.NoError(t, err, c.sql)
+		target := tbl
+		if strings.HasPrefix(c.sql, "alter table h") {
+			target = hash
+		}
+		res, err := AnalyzeAlter(target, stmt)
+		require.NoError(t, err, c.sql)
+		require.Equal(t, c.impact, fmt.Sprintf("%s %s %v", res.Algorithm, res.Lock, res.Rebuild), c.sql)
+	}
+
+	stmt, err := parser.New().ParseOneStmt("alter table t add column x int, modify a text, add index kx (x), lock = shared", "", "")
+	require.NoError(t, err)
+	res, err := AnalyzeAlter(tbl, stmt)
+	require.NoError(t, err)
+	require.Len(t, res.Specs, 3)
+	require.Equal(t, ast.AlgorithmTypeInstant, res.Specs[0].Algorithm)
+	require.Equal(t, ast.AlgorithmTypeCopy, res.Specs[1].Algorithm)
+	require.Equal(t, ast.AlgorithmTypeInplace, res.Specs[2].Algorithm)
+	require.Equal(t, "Cannot change column type INPLACE", res.Reason)
+
+	stmt, err = parser.New().ParseOneStmt("select 1", "", "")
+	require.NoError(t, err)
+	res, err = AnalyzeAlter(tbl, stmt)
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
+
+func TestAnalyzeAlterErrors(t *testing.T) {
+	tbl := mustBuild(t, NewBuilder(), "create table t (id int primary key, a varchar(10), b int, key kb (b))")
+	cases := []struct {
+		sql string
+		err *terror.Error
+		msg string
+	}{
+		{"alter table t add index ka (a), algorithm = instant", ErrAlterOperationNotSupportedReason,
+			"ALGORITHM=INSTANT is not supported. Reason: The operation changes the metadata in the data dictionary. Try ALGORITHM=INPLACE."},
+		{"alter table t modify a text, algorithm = inplace", ErrAlterOperationNotSupportedReason,
+			"ALGORITHM=INPLACE is not supported. Reason: Cannot change column type INPLACE. Try ALGORITHM=COPY."},
+		{"alter table t modify a text, lock = none", ErrAlterOperationNotSupportedReason,
+			"LOCK=NONE is not supported. Reason: Cannot change column type INPLACE. Try LOCK=SHARED."},
+		{"alter table t add column x int, algorithm = copy, lock = none", ErrAlterOperationNotSupportedReason,
+			"LOCK=NONE is not supported. Reason: COPY algorithm requires a lock. Try LOCK=SHARED."},
+		{"alter table t add column x int, algorithm = instant, lock = none", ErrAlterOperationNotSupported,
+			"LOCK=NONE is not supported for this operation. Try LOCK=DEFAULT."},
+		{"alter table t drop primary key, algorithm = inplace", ErrAlterOperationNotSupportedReason,
+			"ALGORITHM=INPLACE is not supported. Reason: Dropping a primary key is not allowed without also adding a new primary key. Try ALGORITHM=COPY."},
+		{"create index ka on t (a) algorithm = instant", ErrAlterOperationNotSupportedReason, ""},
+		{"drop index kc on t", ErrCantDropFieldOrKey, ""},
+		{"alter table t drop column x", ErrCantDropFieldOrKey, ""},
+	}
+	for _, c := range cases {
+		stmt, err := parser.New().ParseOneStmt(c.sql, "", "")
+		require.NoError(t, err, c.sql)
+		_, err = AnalyzeAlter(tbl, stmt)
+		require.True(t, terror.ErrorEqual(c.err, err), "%s: %v", c.sql, err)
+		if c.msg != "" {
+			require.Contains(t, err.Error(), c.msg)
+		}
+	}
+}
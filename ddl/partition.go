@@ -0,0 +1,348 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// buildPartitionInfo builds the PartitionInfo of the table.
+func (tb *tableBuilder) buildPartitionInfo(opts *ast.PartitionOptions) error {
+	pi := &model.PartitionInfo{Type: opts.Tp, Enable: true}
+	var partCols []string
+	if opts.Expr != nil {
+		for _, name := range columnNames(opts.Expr) {
+			if model.FindColumnInfo(tb.tbl.Columns, name) == nil {
+				return ErrFieldNotFoundPart.GenWithStackByArgs()
+			}
+			partCols = append(partCols, name)
+		}
+		if c, ok := opts.Expr.(*ast.ColumnNameExpr); ok {
+			col := model.FindColumnInfo(tb.tbl.Columns, c.Name.Name.L)
+			if !mysql.IsIntegerType(col.GetType()) {
+				return ErrFieldTypeNotAllowedAsPartitionField.GenWithStackByArgs(col.Name.O)
+			}
+		}
+		expr, err := restoreExpr(opts.Expr, format.DefaultRestoreFlags)
+		if err != nil {
+			return err
+		}
+		pi.Expr = expr
+	}
+	for _, name := range opts.ColumnNames {
+		col := model.FindColumnInfo(tb.tbl.Columns, name.Name.L)
+		if col == nil {
+			return ErrFieldNotFoundPart.GenWithStackByArgs()
+		}
+		if opts.Tp != model.PartitionTypeKey && !isPartitionColumnType(col.GetType()) {
+			return ErrFieldTypeNotAllowedAsPartitionField.GenWithStackByArgs(col.Name.O)
+		}
+		pi.Columns = append(pi.Columns, col.Name)
+		partCols = append(partCols, col.Name.L)
+	}
+
+	if err := tb.buildPartitionDefinitions(pi, opts); err != nil {
+		return err
+	}
+	switch opts.Tp {
+	case model.PartitionTypeRange:
+		if err := checkRangePartitions(opts.Definitions, len(opts.ColumnNames) > 0); err != nil {
+			return err
+		}
+	case model.PartitionTypeList:
+		if err := checkListPartitions(opts.Definitions); err != nil {
+			return err
+		}
+	}
+	tb.tbl.Partition = pi
+	return tb.checkUniqueKeysInPartition(partCols)
+}
+
+// isPartitionColumnType returns whether a column of the type can be a column
+// of RANGE COLUMNS or LIST COLUMNS partitioning.
+func isPartitionColumnType(tp byte) bool {
+	switch tp {
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeString, mysql.TypeVarchar:
+		return true
+	}
+	return mysql.IsIntegerType(tp)
+}
+
+// buildPartitionDefinitions builds the partitions, the partitions of HASH and
+// KEY partitioning without definitions are named p0, p1 and so on.
+func (tb *tableBuilder) buildPartitionDefinitions(pi *model.PartitionInfo, opts *ast.PartitionOptions) error {
+	if len(opts.Definitions) == 0 {
+		num := opts.Num
+		if num == 0 {
+			num = 1
+		}
+		if num > maxPartitionCount {
+			return ErrTooManyPartitions.GenWithStackByArgs()
+		}
+		for i := uint64(0); i < num; i++ {
+			pi.Definitions = append(pi.Definitions, model.PartitionDefinition{
+				ID:   tb.genID(),
+				Name: model.NewCIStr(fmt.Sprintf("p%d", i)),
+			})
+		}
+		pi.Num = num
+		return nil
+	}
+
+	count := len(opts.Definitions)
+	if opts.Sub != nil && opts.Sub.Num > 0 {
+		count *= int(opts.Sub.Num)
+	}
+	if count > maxPartitionCount {
+		return ErrTooManyPartitions.GenWithStackByArgs()
+	}
+	names := make(map[string]bool, len(opts.Definitions))
+	for _, def := range opts.Definitions {
+		if names[def.Name.L] {
+			return ErrSameNamePartition.GenWithStackByArgs(def.Name.O)
+		}
+		names[def.Name.L] = true
+		pd := model.PartitionDefinition{ID: tb.genID(), Name: def.Name}
+		pd.Comment, _ = def.Comment()
+		for _, opt := range def.Options {
+			if opt.Tp == ast.TableOptionPlacementPolicy {
+				pd.PlacementPolicyRef = &model.PolicyRefInfo{Name: model.NewCIStr(opt.StrValue)}
+			}
+		}
+		switch clause := def.Clause.(type) {
+		case *ast.PartitionDefinitionClauseLessThan:
+			for _, expr := range clause.Exprs {
+				s, err := restoreExpr(expr, format.DefaultRestoreFlags)
+				if err != nil {
+					return err
+				}
+				pd.LessThan = append(pd.LessThan, s)
+			}
+		case *ast.PartitionDefinitionClauseIn:
+			for _, row := range clause.Values {
+				values := make([]string, 0, len(row))
+				for _, expr := range row {
+					s, err := restoreExpr(expr, format.DefaultRestoreFlags)
+					if err != nil {
+						return err
+					}
+					values = append(values, s)
+				}
+				pd.InValues = append(pd.InValues, values)
+			}
+		}
+		pi.Definitions = append(pi.Definitions, pd)
+	}
+	pi.Num = uint64(len(pi.Definitions))
+	return nil
+}
+
+// partitionValue is a value of VALUES LESS THAN, max is MAXVALUE.
+type partitionValue struct {
+	max   bool
+	value interface{}
+	known bool
+}
+
+func newPartitionValue(expr ast.ExprNode) partitionValue {
+	if _, ok := expr.(*ast.MaxValueExpr); ok {
+		return partitionValue{max: true, known: true}
+	}
+	v, ok := literalValue(expr)
+	return partitionValue{value: v, known: ok && v != nil}
+}
+
+// storedPartitionValue returns the value of a restored value of VALUES LESS
+// THAN in the PartitionInfo.
+func storedPartitionValue(s string) partitionValue {
+	switch {
+	case strings.EqualFold(s, "MAXVALUE"):
+		return partitionValue{max: true, known: true}
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return partitionValue{value: strings.ReplaceAll(s[1:len(s)-1], "''", "'"), known: true}
+	}
+	if r, ok := ratOf(s); ok {
+		return partitionValue{value: r, known: true}
+	}
+	return partitionValue{}
+}
+
+// compare compares two values, ok is false if they are not comparable, like
+// the values of the functions which are not evaluated.
+func (v partitionValue) compare(o partitionValue) (cmp int, ok bool) {
+	switch {
+	case !v.known || !o.known:
+		return 0, false
+	case v.max && o.max:
+		return 0, true
+	case v.max:
+		return 1, true
+	case o.max:
+		return -1, true
+	}
+	switch x := v.value.(type) {
+	case *big.Rat:
+		if y, ok := o.value.(*big.Rat); ok {
+			return x.Cmp(y), true
+		}
+	case string:
+		if y, ok := o.value.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+	return 0, false
+}
+
+// checkRangePartitions checks that the values of RANGE partitions increase
+// strictly and MAXVALUE is only in the last partition.
+func checkRangePartitions(defs []*ast.PartitionDefinition, columns bool) error {
+	var prev []partitionValue
+	for i, def := range defs {
+		clause, ok := def.Clause.(*ast.PartitionDefinitionClauseLessThan)
+		if !ok {
+			continue
+		}
+		values := make([]partitionValue, 0, len(clause.Exprs))
+		for _, expr := range clause.Exprs {
+			values = append(values, newPartitionValue(expr))
+		}
+		if !columns && values[0].max && i != len(defs)-1 {
+			return ErrPartitionMaxvalue.GenWithStackByArgs()
+		}
+		if prev != nil && !increasing(prev, values) {
+			return ErrRangeNotIncreasing.GenWithStackByArgs()
+		}
+		prev = values
+	}
+	return nil
+}
+
+// increasing returns whether the tuple b is greater than a, the tuples which
+// are not comparable are regarded as increasing.
+func increasing(a, b []partitionValue) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		cmp, ok := a[i].compare(b[i])
+		if !ok {
+			return true
+		}
+		if cmp != 0 {
+			return cmp < 0
+		}
+	}
+	return false
+}
+
+// checkListPartitions checks that a value is in one LIST partition at most.
+func checkListPartitions(defs []*ast.PartitionDefinition) error {
+	seen := make(map[string]bool)
+	for _, def := range defs {
+		clause, ok := def.Clause.(*ast.PartitionDefinitionClauseIn)
+		if !ok {
+			continue
+		}
+		for _, row := range clause.Values {
+			keys := make([]string, 0, len(row))
+			for _, expr := range row {
+				key, err := listValueKey(expr)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+			key := strings.Join(keys, ",")
+			if seen[key] {
+				return ErrMultipleDefConstInListPart.GenWithStackByArgs()
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// listValueKey returns the normalized text of a value of LIST partitions.
+func listValueKey(expr ast.ExprNode) (string, error) {
+	v, ok := literalValue(expr)
+	if ok {
+		switch x := v.(type) {
+		case nil:
+			return "NULL", nil
+		case *big.Rat:
+			return x.RatString(), nil
+		case string:
+			return fmt.Sprintf("%q", x), nil
+		}
+	}
+	return restoreExpr(expr, format.DefaultRestoreFlags)
+}
+
+// storedValueKey returns the normalized text of a restored value of LIST
+// partitions in the PartitionInfo, like listValueKey.
+func storedValueKey(s string) string {
+	if strings.EqualFold(s, "NULL") {
+		return "NULL"
+	}
+	v := storedPartitionValue(s)
+	switch x := v.value.(type) {
+	case *big.Rat:
+		return x.RatString()
+	case string:
+		return fmt.Sprintf("%q", x)
+	}
+	return s
+}
+
+// checkUniqueKeysInPartition checks that the primary key and the unique
+// indexes include all the partitioning columns.
+func (tb *tableBuilder) checkUniqueKeysInPartition(partCols []string) error {
+	if len(partCols) == 0 {
+		return nil
+	}
+	if tb.tbl.PKIsHandle {
+		pk := tb.tbl.GetPkColInfo()
+		for _, name := range partCols {
+			if name != pk.Name.L {
+				return ErrUniqueKeyNeedAllFieldsInPf.GenWithStackByArgs("PRIMARY KEY")
+			}
+		}
+	}
+	for _, idx := range tb.tbl.Indices {
+		if !idx.Unique {
+			continue
+		}
+		cols := make(map[string]bool, len(idx.Columns))
+		for _, ic := range idx.Columns {
+			if ic.Length == types.UnspecifiedLength {
+				cols[ic.Name.L] = true
+			}
+		}
+		for _, name := range partCols {
+			if cols[name] {
+				continue
+			}
+			if idx.Primary {
+				return ErrUniqueKeyNeedAllFieldsInPf.GenWithStackByArgs("PRIMARY KEY")
+			}
+			return ErrUniqueKeyNeedAllFieldsInPf.GenWithStackByArgs("UNIQUE INDEX")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,843 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/opcode"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// PartitionAccess is the partitions of a table accessed by a statement.
+type PartitionAccess struct {
+	// Partitions are the accessed partitions in the order of the definitions.
+	Partitions []model.CIStr
+	// FullScan is true if the conditions prune no partition, all the
+	// partitions are accessed.
+	FullScan bool
+}
+
+// PrunePartitions returns the partitions of a partitioned table accessed by a
+// SELECT, UPDATE or DELETE statement, it returns nil for the other statements
+// and the tables which are not partitioned.
+//
+// Every reference of the table in the query blocks of the statement is pruned
+// by the WHERE clause and the ON conditions of the inner joins of its block,
+// the explicit partition selection is respected. The schema of the references
+// is not checked. The partitions are pruned by the comparisons, IN lists,
+// BETWEEN and IS NULL of the partitioning columns combined by AND and OR:
+//
+//   - RANGE partitions by the ranges of the column of the expression, YEAR()
+//     and TO_DAYS() of the column are supported.
+//   - RANGE COLUMNS partitions by the ranges of the first column.
+//   - LIST and LIST COLUMNS partitions by the values of every column.
+//   - HASH partitions by the modulo of the values, or of the short ranges.
+//
+// KEY partitions are not pruned, the hash function is not implemented.
+func PrunePartitions(tbl *model.TableInfo, stmt ast.StmtNode) (*PartitionAccess, error) {
+	switch stmt.(type) {
+	case *ast.SelectStmt, *ast.SetOprStmt, *ast.UpdateStmt, *ast.DeleteStmt:
+	default:
+		return nil, nil
+	}
+	p, err := newPruner(tbl)
+	if p == nil || err != nil {
+		return nil, err
+	}
+	ctes := make(map[string]bool)
+	for _, cte := range ast.FindAll[*ast.CommonTableExpression](stmt) {
+		ctes[cte.Name.L] = true
+	}
+	accessed := make([]bool, len(p.pi.Definitions))
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		var refs *ast.TableRefsClause
+		var where ast.ExprNode
+		switch x := n.(type) {
+		case *ast.SelectStmt:
+			refs, where = x.From, x.Where
+		case *ast.UpdateStmt:
+			refs, where = x.TableRefs, x.Where
+		case *ast.DeleteStmt:
+			refs, where = x.TableRefs, x.Where
+		default:
+			return true
+		}
+		if refs == nil {
+			return true
+		}
+		var conds []pruneCond
+		if where != nil {
+			conds = append(conds, pruneCond{expr: where})
+		}
+		p.refs(refs.TableRefs, conds, ctes, accessed)
+		return true
+	})
+	return p.access(accessed), nil
+}
+
+// PruneCondition returns the partitions of a partitioned table which may have
+// rows satisfying a condition, like a WHERE clause, it returns nil if the table
+// is not partitioned. The columns of the condition qualified by another name
+// than the table name or the alias are not of the table.
+func PruneCondition(tbl *model.TableInfo, alias model.CIStr, cond ast.ExprNode) (*PartitionAccess, error) {
+	p, err := newPruner(tbl)
+	if p == nil || err != nil {
+		return nil, err
+	}
+	if alias.L == "" {
+		alias = tbl.Name
+	}
+	set := p.all()
+	if cond != nil {
+		set = p.eval(alias, cond)
+	}
+	return p.access(set), nil
+}
+
+// pruner prunes the partitions of a table.
+type pruner struct {
+	tbl *model.TableInfo
+	pi  *model.PartitionInfo
+	// cols are the partitioning columns, the column of the expression for
+	// RANGE, LIST and HASH partitions.
+	cols []*model.ColumnInfo
+	// fn is the lower case name of the function of the expression applied to
+	// the column, it is empty if the expression is the column.
+	fn string
+	// bounds are the values of VALUES LESS THAN, values are the values of
+	// VALUES IN, converted like the values of the conditions.
+	bounds [][]partitionValue
+	values [][][]prunedValue
+}
+
+// prunedValue is a value of a partition or a condition, a value which is not
+// known is NULL if null is true.
+type prunedValue struct {
+	partitionValue
+	null bool
+}
+
+// valueRange is the range of the values of a condition, a nil bound is
+// unbounded. A range of NULL is only NULL.
+type valueRange struct {
+	null              bool
+	low, high         *partitionValue
+	lowOpen, highOpen bool
+}
+
+// newPruner returns the pruner of a table, or nil if the table is not
+// partitioned.
+func newPruner(tbl *model.TableInfo) (*pruner, error) {
+	pi := tbl.GetPartitionInfo()
+	if pi == nil || len(pi.Definitions) == 0 {
+		return nil, nil
+	}
+	p := &pruner{tbl: tbl, pi: pi}
+	if len(pi.Columns) > 0 {
+		for _, name := range pi.Columns {
+			col := model.FindColumnInfo(tbl.Columns, name.L)
+			if col == nil {
+				return nil, ErrFieldNotFoundPart.GenWithStackByArgs()
+			}
+			p.cols = append(p.cols, col)
+		}
+	} else if pi.Expr != "" {
+		stmt, err := parser.New().ParseOneStmt("SELECT "+pi.Expr, "", "")
+		if err != nil {
+			return nil, err
+		}
+		expr := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr
+		if f, ok := expr.(*ast.FuncCallExpr); ok && len(f.Args) == 1 && (f.FnName.L == ast.Year || f.FnName.L == ast.ToDays) {
+			p.fn, expr = f.FnName.L, f.Args[0]
+		}
+		if c, ok := expr.(*ast.ColumnNameExpr); ok {
+			if col := model.FindColumnInfo(tbl.Columns, c.Name.Name.L); col != nil {
+				p.cols = append(p.cols, col)
+			}
+		}
+	}
+	for _, def := range pi.Definitions {
+		var bounds []partitionValue
+		for i, s := range def.LessThan {
+			bounds = append(bounds, p.storedValue(i, s).partitionValue)
+		}
+		p.bounds = append(p.bounds, bounds)
+		var rows [][]prunedValue
+		for _, row := range def.InValues {
+			values := make([]prunedValue, 0, len(row))
+			for i, s := range row {
+				values = append(values, p.storedValue(i, s))
+			}
+			rows = append(rows, values)
+		}
+		p.values = append(p.values, rows)
+	}
+	return p, nil
+}
+
+// storedValue converts a restored value of the i-th partitioning column in the
+// PartitionInfo.
+func (p *pruner) storedValue(i int, s string) prunedValue {
+	if strings.EqualFold(s, "NULL") {
+		return prunedValue{null: true}
+	}
+	v := storedPartitionValue(s)
+	if !v.known && len(p.pi.Columns) == 0 {
+		v = storedFuncValue(s)
+	}
+	if v.max || !v.known {
+		return prunedValue{partitionValue: v}
+	}
+	if len(p.pi.Columns) == 0 {
+		// The values of the expression are integers.
+		v, _ = integerValue(v.value)
+		return prunedValue{partitionValue: v}
+	}
+	v, _ = convertValue(p.cols[i], v.value)
+	return prunedValue{partitionValue: v}
+}
+
+// storedFuncValue evaluates a value of the partitions which is YEAR() or
+// TO_DAYS() of a date, like TO_DAYS('2007-10-07').
+func storedFuncValue(s string) partitionValue {
+	stmt, err := parser.New().ParseOneStmt("SELECT "+s, "", "")
+	if err != nil {
+		return partitionValue{}
+	}
+	f, ok := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.FuncCallExpr)
+	if !ok || len(f.Args) != 1 || f.FnName.L != ast.Year && f.FnName.L != ast.ToDays {
+		return partitionValue{}
+	}
+	value, ok := literalValue(f.Args[0])
+	if !ok {
+		return partitionValue{}
+	}
+	str, ok := value.(string)
+	if !ok {
+		return partitionValue{}
+	}
+	t, ok := parseDate(str)
+	if !ok {
+		return partitionValue{}
+	}
+	return dateFuncValue(f.FnName.L, t)
+}
+
+// value converts a value of a condition on the i-th partitioning column to a
+// value of the partitions, exact is false if the value is rounded.
+func (p *pruner) value(i int, value interface{}) (v partitionValue, exact bool) {
+	switch {
+	case len(p.pi.Columns) > 0:
+		return convertValue(p.cols[i], value)
+	case p.fn != "":
+		v, _ = convertValue(p.cols[i], value)
+		s, ok := v.value.(string)
+		if !v.known || !ok || len(s) < len(dateLayout) {
+			return partitionValue{}, false
+		}
+		t, err := time.Parse(dateLayout, s[:len(dateLayout)])
+		if err != nil {
+			return partitionValue{}, false
+		}
+		return dateFuncValue(p.fn, t), false
+	}
+	return integerValue(value)
+}
+
+// dateFuncValue returns YEAR() or TO_DAYS() of a date.
+func dateFuncValue(fn string, t time.Time) partitionValue {
+	if fn == ast.Year {
+		return partitionValue{value: new(big.Rat).SetInt64(int64(t.Year())), known: true}
+	}
+	return partitionValue{value: new(big.Rat).SetInt64(toDays(t)), known: true}
+}
+
+// toDays returns TO_DAYS() of a date.
+func toDays(t time.Time) int64 {
+	return (t.Unix() - time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Unix()) / (24 * 60 * 60)
+}
+
+// integerValue converts a value to a number.
+func integerValue(value interface{}) (partitionValue, bool) {
+	switch x := value.(type) {
+	case *big.Rat:
+		return partitionValue{value: x, known: true}, true
+	case string:
+		if r, ok := ratOf(strings.TrimSpace(x)); ok {
+			return partitionValue{value: r, known: true}, true
+		}
+	}
+	return partitionValue{}, false
+}
+
+// The layouts of the normalized values of DATE and DATETIME columns, which are
+// ordered like the strings.
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "2006-01-02 15:04:05.999999"
+)
+
+// dateLayouts are the accepted layouts of the date and time strings.
+var dateLayouts = []string{
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2 15:4:5",
+	"2006-1-2 15:4",
+	"2006-1-2",
+	"20060102150405",
+	"20060102",
+}
+
+// parseDate parses a date or time string in one of the dateLayouts.
+func parseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// convertValue converts a value to the value of a column type, the dates and
+// times are normalized and the strings of case insensitive collations are
+// folded. exact is false if the value is rounded, like a time compared with a
+// DATE column.
+func convertValue(col *model.ColumnInfo, value interface{}) (v partitionValue, exact bool) {
+	switch tp := col.GetType(); {
+	case tp == mysql.TypeDate || tp == mysql.TypeDatetime || tp == mysql.TypeTimestamp:
+		s, ok := value.(string)
+		if !ok {
+			return partitionValue{}, false
+		}
+		t, ok := parseDate(s)
+		if !ok {
+			return partitionValue{}, false
+		}
+		if tp == mysql.TypeDate {
+			d := t.Format(dateLayout)
+			return partitionValue{value: d, known: true}, t.Format(timeLayout) == d+" 00:00:00"
+		}
+		return partitionValue{value: t.Format(timeLayout), known: true}, true
+	case mysql.IsIntegerType(tp) || tp == mysql.TypeNewDecimal || tp == mysql.TypeFloat || tp == mysql.TypeDouble:
+		return integerValue(value)
+	case types.IsTypeChar(tp):
+		s, ok := value.(string)
+		if !ok {
+			return partitionValue{}, false
+		}
+		if collate := col.GetCollate(); collate != "binary" && !strings.HasSuffix(collate, "_bin") {
+			s = strings.ToLower(strings.TrimRight(s, " "))
+		}
+		return partitionValue{value: s, known: true}, true
+	}
+	return partitionValue{}, false
+}
+
+// pruneCond is a condition pruning the references of a table. nullRejecting
+// is true for a condition of the rows of an outer join, like the WHERE clause
+// of a LEFT JOIN, pruning the inner table: the outer rows without matched rows
+// are NULL-extended, so only the conjuncts rejecting NULL prune the inner
+// table. The others, like IS NULL of an anti-join, are satisfied by the
+// NULL-extended rows and all the partitions are read.
+type pruneCond struct {
+	expr          ast.ExprNode
+	nullRejecting bool
+}
+
+// refs prunes the references of the table in a join tree by the conditions
+// and adds the partitions to accessed, the ON conditions of the inner joins
+// and of the inner tables of the outer joins are added to the conditions.
+func (p *pruner) refs(node ast.ResultSetNode, conds []pruneCond, ctes map[string]bool, accessed []bool) {
+	switch x := node.(type) {
+	case *ast.Join:
+		left, right := conds, conds
+		switch x.Tp {
+		case ast.LeftJoin:
+			right = nullRejecting(conds)
+		case ast.RightJoin:
+			left = nullRejecting(conds)
+		}
+		if x.On != nil {
+			on := pruneCond{expr: x.On.Expr}
+			switch x.Tp {
+			case ast.CrossJoin:
+				left, right = append(left[:len(left):len(left)], on), append(right[:len(right):len(right)], on)
+			case ast.LeftJoin:
+				right = append(right[:len(right):len(right)], on)
+			case ast.RightJoin:
+				left = append(left[:len(left):len(left)], on)
+			}
+		}
+		p.refs(x.Left, left, ctes, accessed)
+		if x.Right != nil {
+			p.refs(x.Right, right, ctes, accessed)
+		}
+	case *ast.TableSource:
+		tn, ok := x.Source.(*ast.TableName)
+		if !ok || tn.Name.L != p.tbl.Name.L || tn.Schema.L == "" && ctes[tn.Name.L] {
+			return
+		}
+		alias := x.AsName
+		if alias.L == "" {
+			alias = tn.Name
+		}
+		set := p.all()
+		for _, cond := range conds {
+			if !cond.nullRejecting {
+				set = intersect(set, p.eval(alias, cond.expr))
+				continue
+			}
+			for _, expr := range conjuncts(cond.expr, nil) {
+				if p.rejectsNull(alias, expr) {
+					set = intersect(set, p.eval(alias, expr))
+				}
+			}
+		}
+		if len(tn.PartitionNames) > 0 {
+			for i, def := range p.pi.Definitions {
+				named := false
+				for _, name := range tn.PartitionNames {
+					named = named || name.L == def.Name.L
+				}
+				set[i] = set[i] && named
+			}
+		}
+		union(accessed, set)
+	}
+}
+
+// nullRejecting returns the conditions marked nullRejecting.
+func nullRejecting(conds []pruneCond) []pruneCond {
+	res := make([]pruneCond, 0, len(conds))
+	for _, cond := range conds {
+		res = append(res, pruneCond{expr: cond.expr, nullRejecting: true})
+	}
+	return res
+}
+
+// rejectsNull returns whether a condition is false or unknown if the
+// partitioning columns of the table are NULL, it is the comparisons, IN lists
+// and BETWEEN of the columns but <=>, and their conjunctions and disjunctions.
+func (p *pruner) rejectsNull(alias model.CIStr, expr ast.ExprNode) bool {
+	switch x := expr.(type) {
+	case *ast.ParenthesesExpr:
+		return p.rejectsNull(alias, x.Expr)
+	case *ast.BinaryOperationExpr:
+		switch x.Op {
+		case opcode.LogicAnd:
+			return p.rejectsNull(alias, x.L) || p.rejectsNull(alias, x.R)
+		case opcode.LogicOr:
+			return p.rejectsNull(alias, x.L) && p.rejectsNull(alias, x.R)
+		case opcode.NullEQ:
+			return false
+		}
+		if isComparison(x.Op) {
+			_, l := p.column(alias, x.L)
+			_, r := p.column(alias, x.R)
+			return l || r
+		}
+	case *ast.PatternInExpr:
+		_, ok := p.column(alias, x.Expr)
+		return ok && !x.Not && x.Sel == nil
+	case *ast.BetweenExpr:
+		_, ok := p.column(alias, x.Expr)
+		return ok && !x.Not
+	}
+	return false
+}
+
+// eval returns the partitions which may have rows satisfying a condition, the
+// columns of the table are qualified by alias.
+func (p *pruner) eval(alias model.CIStr, expr ast.ExprNode) []bool {
+	switch x := expr.(type) {
+	case *ast.ParenthesesExpr:
+		return p.eval(alias, x.Expr)
+	case *ast.BinaryOperationExpr:
+		switch x.Op {
+		case opcode.LogicAnd:
+			return p.and(alias, x)
+		case opcode.LogicOr:
+			return union(p.eval(alias, x.L), p.eval(alias, x.R))
+		case opcode.EQ, opcode.NullEQ, opcode.LT, opcode.LE, opcode.GT, opcode.GE:
+			return p.compare(alias, x.Op, x.L, x.R)
+		}
+	case *ast.PatternInExpr:
+		if !x.Not && x.Sel == nil {
+			set := p.none()
+			for _, item := range x.List {
+				union(set, p.compare(alias, opcode.EQ, x.Expr, item))
+			}
+			return set
+		}
+	case *ast.BetweenExpr:
+		if x.Not {
+			break
+		}
+		i, low, lowOK := p.rangeOf(alias, opcode.GE, x.Expr, x.Left)
+		j, high, highOK := p.rangeOf(alias, opcode.LE, x.Expr, x.Right)
+		switch {
+		case lowOK && highOK && i == j:
+			low.high, low.highOpen = high.high, high.highOpen
+			return p.partitions(i, low)
+		case lowOK:
+			return p.partitions(i, low)
+		case highOK:
+			return p.partitions(j, high)
+		}
+	case *ast.IsNullExpr:
+		if i, ok := p.column(alias, x.Expr); ok && !x.Not {
+			return p.partitions(i, valueRange{null: true})
+		}
+	}
+	return p.all()
+}
+
+// and returns the partitions which may have rows satisfying a conjunction, the
+// ranges of the comparisons of a column are intersected before the partitions
+// are pruned, so a short range of two comparisons prunes HASH partitions.
+func (p *pruner) and(alias model.CIStr, expr ast.ExprNode) []bool {
+	set := p.all()
+	ranges := make(map[int]valueRange)
+	for _, cond := range conjuncts(expr, nil) {
+		if b, ok := cond.(*ast.BinaryOperationExpr); ok && isComparison(b.Op) {
+			if i, rng, ok := p.rangeOf(alias, b.Op, b.L, b.R); ok && !rng.null {
+				prev, seen := ranges[i]
+				if !seen {
+					ranges[i] = rng
+					continue
+				}
+				if merged, ok := prev.intersect(rng); ok {
+					ranges[i] = merged
+					continue
+				}
+			}
+		}
+		set = intersect(set, p.eval(alias, cond))
+	}
+	for i, rng := range ranges {
+		set = intersect(set, p.partitions(i, rng))
+	}
+	return set
+}
+
+// conjuncts appends the conjuncts of an expression to res.
+func conjuncts(expr ast.ExprNode, res []ast.ExprNode) []ast.ExprNode {
+	switch x := expr.(type) {
+	case *ast.ParenthesesExpr:
+		return conjuncts(x.Expr, res)
+	case *ast.BinaryOperationExpr:
+		if x.Op == opcode.LogicAnd {
+			return conjuncts(x.R, conjuncts(x.L, res))
+		}
+	}
+	return append(res, expr)
+}
+
+// compare returns the partitions which may have rows satisfying a comparison,
+// the equality of rows is the equalities of their columns.
+func (p *pruner) compare(alias model.CIStr, op opcode.Op, l, r ast.ExprNode) []bool {
+	lrow, lok := l.(*ast.RowExpr)
+	rrow, rok := r.(*ast.RowExpr)
+	if lok && rok && op == opcode.EQ && len(lrow.Values) == len(rrow.Values) {
+		set := p.all()
+		for i := range lrow.Values {
+			set = intersect(set, p.compare(alias, op, lrow.Values[i], rrow.Values[i]))
+		}
+		return set
+	}
+	if i, rng, ok := p.rangeOf(alias, op, l, r); ok {
+		return p.partitions(i, rng)
+	}
+	return p.all()
+}
+
+// flippedOps are the operators of the comparisons with swapped operands.
+var flippedOps = map[opcode.Op]opcode.Op{
+	opcode.EQ:     opcode.EQ,
+	opcode.NullEQ: opcode.NullEQ,
+	opcode.LT:     opcode.GT,
+	opcode.LE:     opcode.GE,
+	opcode.GT:     opcode.LT,
+	opcode.GE:     opcode.LE,
+}
+
+// isComparison returns whether an operator is a comparison of rangeOf.
+func isComparison(op opcode.Op) bool {
+	_, ok := flippedOps[op]
+	return ok
+}
+
+// rangeOf returns the partitioning column and its range of a comparison of
+// the column with a constant, ok is false for the other comparisons.
+func (p *pruner) rangeOf(alias model.CIStr, op opcode.Op, l, r ast.ExprNode) (i int, rng valueRange, ok bool) {
+	if _, ok := p.column(alias, r); ok {
+		l, r, op = r, l, flippedOps[op]
+	}
+	i, ok = p.column(alias, l)
+	if !ok {
+		return 0, rng, false
+	}
+	value, ok := literalValue(r)
+	switch {
+	case !ok:
+		return 0, rng, false
+	case value == nil:
+		// Only <=> matches NULL.
+		return i, valueRange{null: true}, op == opcode.NullEQ
+	}
+	v, exact := p.value(i, value)
+	if !v.known {
+		return 0, rng, false
+	}
+	switch op {
+	case opcode.EQ, opcode.NullEQ:
+		rng.low, rng.high = &v, &v
+	case opcode.LT:
+		rng.high, rng.highOpen = &v, exact
+	case opcode.LE:
+		rng.high = &v
+	case opcode.GT:
+		rng.low, rng.lowOpen = &v, exact
+	case opcode.GE:
+		rng.low = &v
+	}
+	return i, rng, true
+}
+
+// column returns the index of the partitioning column of an expression.
+func (p *pruner) column(alias model.CIStr, expr ast.ExprNode) (int, bool) {
+	for {
+		paren, ok := expr.(*ast.ParenthesesExpr)
+		if !ok {
+			break
+		}
+		expr = paren.Expr
+	}
+	c, ok := expr.(*ast.ColumnNameExpr)
+	if !ok || c.Name.Table.L != "" && c.Name.Table.L != alias.L {
+		return 0, false
+	}
+	for i, col := range p.cols {
+		if col.Name.L == c.Name.Name.L {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// partitions returns the partitions which may have rows whose i-th
+// partitioning column is in a range.
+func (p *pruner) partitions(i int, rng valueRange) []bool {
+	switch p.pi.Type {
+	case model.PartitionTypeRange:
+		// RANGE COLUMNS partitions are pruned by the first column.
+		if i == 0 {
+			return p.rangePartitions(rng)
+		}
+	case model.PartitionTypeList:
+		return p.listPartitions(i, rng)
+	case model.PartitionTypeHash:
+		return p.hashPartitions(rng)
+	}
+	return p.all()
+}
+
+// rangePartitions returns the RANGE partitions of a range, NULL is less than
+// all the values. The first column of a partition of RANGE COLUMNS partitions
+// with more columns may equal the first value of VALUES LESS THAN.
+func (p *pruner) rangePartitions(rng valueRange) []bool {
+	set := p.none()
+	if rng.null {
+		set[0] = true
+		return set
+	}
+	closed := len(p.cols) > 1
+	for k, bounds := range p.bounds {
+		if len(bounds) == 0 || k > 0 && len(p.bounds[k-1]) == 0 {
+			set[k] = true
+			continue
+		}
+		below := true
+		if rng.low != nil {
+			cmp, ok := rng.low.compare(bounds[0])
+			below = !ok || cmp < 0 || cmp == 0 && closed && !rng.lowOpen
+		}
+		above := true
+		if rng.high != nil && k > 0 {
+			cmp, ok := rng.high.compare(p.bounds[k-1][0])
+			above = !ok || cmp > 0 || cmp == 0 && !rng.highOpen
+		}
+		set[k] = below && above
+	}
+	return set
+}
+
+// listPartitions returns the LIST partitions which have a value of the i-th
+// column in a range.
+func (p *pruner) listPartitions(i int, rng valueRange) []bool {
+	set := p.none()
+	for k, rows := range p.values {
+		for _, row := range rows {
+			if i >= len(row) || rng.contains(row[i]) {
+				set[k] = true
+				break
+			}
+		}
+	}
+	return set
+}
+
+// intersect returns the intersection of two ranges of values, ok is false if
+// the bounds are not comparable.
+func (rng valueRange) intersect(o valueRange) (res valueRange, ok bool) {
+	res = rng
+	if o.low != nil {
+		cmp := 1
+		if rng.low != nil {
+			if cmp, ok = o.low.compare(*rng.low); !ok {
+				return rng, false
+			}
+		}
+		if cmp > 0 || cmp == 0 && o.lowOpen {
+			res.low, res.lowOpen = o.low, o.lowOpen
+		}
+	}
+	if o.high != nil {
+		cmp := -1
+		if rng.high != nil {
+			if cmp, ok = o.high.compare(*rng.high); !ok {
+				return rng, false
+			}
+		}
+		if cmp < 0 || cmp == 0 && o.highOpen {
+			res.high, res.highOpen = o.high, o.highOpen
+		}
+	}
+	return res, true
+}
+
+// contains returns whether a value is in the range, the unknown values may be
+// in any range.
+func (rng valueRange) contains(v prunedValue) bool {
+	switch {
+	case v.null || rng.null:
+		return v.null && rng.null
+	case !v.known:
+		return true
+	}
+	if rng.low != nil {
+		if cmp, ok := v.compare(*rng.low); ok && (cmp < 0 || cmp == 0 && rng.lowOpen) {
+			return false
+		}
+	}
+	if rng.high != nil {
+		if cmp, ok := v.compare(*rng.high); ok && (cmp > 0 || cmp == 0 && rng.highOpen) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxHashValues is the maximum number of the values of a range whose HASH
+// partitions are computed.
+const maxHashValues = 256
+
+// hashPartitions returns the HASH partitions of a range, a value is in the
+// partition of the absolute value of the value modulo the number of the
+// partitions and NULL is in the first partition. The partitions of the long
+// ranges are not computed.
+func (p *pruner) hashPartitions(rng valueRange) []bool {
+	set := p.none()
+	if rng.null {
+		set[0] = true
+		return set
+	}
+	if rng.low == nil || rng.high == nil {
+		return p.all()
+	}
+	low, ok1 := rng.low.value.(*big.Rat)
+	high, ok2 := rng.high.value.(*big.Rat)
+	if !ok1 || !ok2 {
+		return p.all()
+	}
+	// The integers in the range.
+	from := new(big.Int).Quo(low.Num(), low.Denom())
+	if low.Sign() > 0 && !low.IsInt() || low.IsInt() && rng.lowOpen {
+		from.Add(from, big.NewInt(1))
+	}
+	to := new(big.Int).Quo(high.Num(), high.Denom())
+	if high.Sign() < 0 && !high.IsInt() || high.IsInt() && rng.highOpen {
+		to.Sub(to, big.NewInt(1))
+	}
+	count := new(big.Int).Sub(to, from)
+	if count.Cmp(big.NewInt(maxHashValues)) >= 0 {
+		return p.all()
+	}
+	n := big.NewInt(int64(len(set)))
+	for v := from; v.Cmp(to) <= 0; v = new(big.Int).Add(v, big.NewInt(1)) {
+		k := new(big.Int).Rem(v, n)
+		set[k.Abs(k).Int64()] = true
+	}
+	return set
+}
+
+// access returns the PartitionAccess of the partitions.
+func (p *pruner) access(set []bool) *PartitionAccess {
+	res := &PartitionAccess{FullScan: true}
+	for i, def := range p.pi.Definitions {
+		if set[i] {
+			res.Partitions = append(res.Partitions, def.Name)
+		} else {
+			res.FullScan = false
+		}
+	}
+	return res
+}
+
+// all returns the set of all the partitions.
+func (p *pruner) all() []bool {
+	set := p.none()
+	for i := range set {
+		set[i] = true
+	}
+	return set
+}
+
+// none returns the empty set of the partitions.
+func (p *pruner) none() []bool {
+	return make([]bool, len(p.pi.Definitions))
+}
+
+// intersect intersects the set a with b and returns a.
+func intersect(a, b []bool) []bool {
+	for i := range a {
+		a[i] = a[i] && b[i]
+	}
+	return a
+}
+
+// union adds the set b to a and returns a.
+func union(a, b []bool) []bool {
+	for i := range a {
+		a[i] = a[i] || b[i]
+	}
+	return a
+}
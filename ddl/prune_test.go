@@ -0,0 +1,189 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	. "github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/stretchr/testify/require"
+)
+
+// prune returns the names of the partitions accessed by a statement, or "all"
+// for a full scan.
+func prune(t *testing.T, tbl *model.TableInfo, sql string) string {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err, sql)
+	access, err := PrunePartitions(tbl, stmt)
+	require.NoError(t, err, sql)
+	if access.FullScan {
+		return "all"
+	}
+	names := make([]string, 0, len(access.Partitions))
+	for _, name := range access.Partitions {
+		names = append(names, name.O)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestPrunePartitions(t *testing.T) {
+	c := NewCatalog()
+	mustApply(t, c, `create database d; use d;
+		create table r (id int, d date) partition by range (id) (
+			partition p0 values less than (10), partition p1 values less than (20),
+			partition p2 values less than (30), partition p3 values less than maxvalue);
+		create table y (id int, d date) partition by range (year(d)) (
+			partition p2019 values less than (2020), partition p2020 values less than (2021),
+			partition pmax values less than maxvalue);
+		create table td (id int, d date) partition by range (to_days(d)) (
+			partition p0 values less than (to_days('2007-10-07')), partition p1 values less than (TO_DAYS('2008-01-01 10:00:00')),
+			partition p2 values less than (733408), partition p3 values less than maxvalue);
+		create table yl (id int, d datetime) partition by range (year(d)) (
+			partition p0 values less than (year('2020-01-01')), partition p1 values less than (2021));
+		create table rc (a int, b varchar(10), c datetime) partition by range columns (c) (
+			partition p0 values less than ('2020-01-01'), partition p1 values less than ('2021-01-01 00:00:00'),
+			partition p2 values less than (maxvalue));
+		create table rc2 (a int, b int) partition by range columns (a, b) (
+			partition p0 values less than (10, 10), partition p1 values less than (20, 20),
+			partition p2 values less than (maxvalue, maxvalue));
+		create table l (id int, x int) partition by list (x) (
+			partition p0 values in (1, 3, 5), partition p1 values in (2, 4, 6), partition pn values in (null));
+		create table lc (a varchar(10) collate utf8mb4_general_ci, b int) partition by list columns (a, b) (
+			partition p0 values in (('a', 1), ('b', 2)), partition p1 values in (('a', 2), ('c', 3)));
+		create table h (id int, x int) partition by hash (id) partitions 4;
+		create table k (id int) partition by key (id) partitions 4;
+		create table n (id int)`)
+	r, y, rc := mustTable(t, c, "d", "r"), mustTable(t, c, "d", "y"), mustTable(t, c, "d", "rc")
+	rc2, l, lc := mustTable(t, c, "d", "rc2"), mustTable(t, c, "d", "l"), mustTable(t, c, "d", "lc")
+	h, k := mustTable(t, c, "d", "h"), mustTable(t, c, "d", "k")
+	td, yl := mustTable(t, c, "d", "td"), mustTable(t, c, "d", "yl")
+
+	cases := []struct {
+		tbl      *model.TableInfo
+		sql      string
+		expected string
+	}{
+		{r, "select * from r", "all"},
+		{r, "select * from r where id = 15", "p1"},
+		{r, "select * from r where 15 = id", "p1"},
+		{r, "select * from r where id < 10", "p0"},
+		{r, "select * from r where id <= 10", "p0,p1"},
+		{r, "select * from r where id > 19 and id < 21", "p1,p2"},
+		{r, "select * from r where id >= 30", "p3"},
+		{r, "select * from r where id between 12 and 25", "p1,p2"},
+		{r, "select * from r where id in (1, 35)", "p0,p3"},
+		{r, "select * from r where id = 1 or id = '25'", "p0,p2"},
+		{r, "select * from r where id is null", "p0"},
+		{r, "select * from r where id = 1 and id = 25", ""},
+		{r, "select * from r where id = 1 or d = '2020-01-01'", "all"},
+		{r, "select * from r where id <> 1", "all"},
+		{r, "select * from r where id not in (1, 2)", "all"},
+		{r, "select * from r where id + 1 = 5", "all"},
+		{r, "select * from r where id > 5 and id < 8 and d is null", "p0"},
+		{r, "select * from r where id <=> null", "p0"},
+		{r, "select * from r t where t.id = 15", "p1"},
+		{r, "select * from r t where r.id = 15", "all"},
+		{r, "select * from r partition (p1, p2) where id > 15", "p1,p2"},
+		{r, "select * from r partition (p0) where id > 15", ""},
+		{r, "select * from r a join r b on a.id = b.id where a.id = 1 and b.id > 25", "p0,p2,p3"},
+		{r, "select * from n left join r on r.id = 1", "p0"},
+		{r, "select * from r left join n on r.id = 1", "all"},
+		{r, "select * from n left join r on n.id = r.id where r.id is null", "all"},
+		{r, "select * from n left join r on n.id = r.id where r.id <=> null", "all"},
+		{r, "select * from n left join r on n.id = r.id where r.id = 5 or r.id is null", "all"},
+		{r, "select * from n left join r on n.id = r.id where r.id = 5 and n.id > 0", "p0"},
+		{r, "select * from n left join r on n.id = r.id and r.id < 10 where r.id is null", "p0"},
+		{r, "select * from r right join n on n.id = r.id where r.id in (1, 25)", "p0,p2"},
+		{r, "select * from n left join (r join n m on r.id = m.id) on n.id = m.id where r.id is null", "all"},
+		{r, "select * from r left join n on r.id = n.id where r.id is null", "p0"},
+		{r, "select * from n where id in (select id from r where id = 5)", "p0"},
+		{r, "select * from r where id = 1 union select * from r where id = 11", "p0,p1"},
+		{r, "with r as (select * from n) select * from r", ""},
+		{r, "update r set d = now() where id = 25", "p2"},
+		{r, "delete from r where id < 0", "p0"},
+		{y, "select * from y where d = '2020-06-01'", "p2020"},
+		{y, "select * from y where d < '2020-01-01'", "p2019,p2020"},
+		{y, "select * from y where d >= '2021-3-4'", "pmax"},
+		{td, "select * from td where d = '2007-10-06'", "p0"},
+		{td, "select * from td where d = '2007-10-07'", "p1"},
+		{td, "select * from td where d between '2007-12-01' and '2007-12-31'", "p1"},
+		{td, "select * from td where d = '2008-01-01'", "p2"},
+		{td, "select * from td where d = '2008-01-02'", "p3"},
+		{td, "select * from td where d > '2009-01-01'", "p3"},
+		{yl, "select * from yl where d = '2019-12-31 23:59:59'", "p0"},
+		{yl, "select * from yl where d >= '2020-01-01'", "p1"},
+		{rc, "select * from rc where c < '2020-01-01'", "p0"},
+		{rc, "select * from rc where c = '2020-06-01 10:00:00'", "p1"},
+		{rc, "select * from rc where c >= '20210101'", "p2"},
+		{rc, "select * from rc where a = 1", "all"},
+		{rc2, "select * from rc2 where a = 10", "p0,p1"},
+		{rc2, "select * from rc2 where a > 10 and a < 20", "p1"},
+		{rc2, "select * from rc2 where b = 5", "all"},
+		{l, "select * from l where x = 3", "p0"},
+		{l, "select * from l where x in (2, 7)", "p1"},
+		{l, "select * from l where x between 4 and 5", "p0,p1"},
+		{l, "select * from l where x is null", "pn"},
+		{lc, "select * from lc where a = 'B '", "p0"},
+		{lc, "select * from lc where b = 3", "p1"},
+		{lc, "select * from lc where (a, b) = ('b', 2)", "p0"},
+		{lc, "select * from lc where (a, b) in (('c', 3), ('d', 1))", "p1"},
+		{h, "select * from h where id = 5", "p1"},
+		{h, "select * from h where id = -6", "p2"},
+		{h, "select * from h where id in (4, 8, 3)", "p0,p3"},
+		{h, "select * from h where id between 6 and 7", "p2,p3"},
+		{h, "select * from h where id > 1 and id < 3", "p2"},
+		{h, "select * from h where id > 1", "all"},
+		{h, "select * from h where id is null", "p0"},
+		{k, "select * from k where id = 1", "all"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expected, prune(t, c.tbl, c.sql), c.sql)
+	}
+
+	stmt, err := parser.New().ParseOneStmt("insert into r values (1, null)", "", "")
+	require.NoError(t, err)
+	access, err := PrunePartitions(r, stmt)
+	require.NoError(t, err)
+	require.Nil(t, access)
+	stmt, err = parser.New().ParseOneStmt("select * from n", "", "")
+	require.NoError(t, err)
+	access, err = PrunePartitions(mustTable(t, c, "d", "n"), stmt)
+	require.NoError(t, err)
+	require.Nil(t, access)
+}
+
+func TestPruneCondition(t *testing.T) {
+	c := NewCatalog()
+	mustApply(t, c, `create database d; create table d.r (id int) partition by range (id) (
+		partition p0 values less than (10), partition p1 values less than (20))`)
+	r := mustTable(t, c, "d", "r")
+	stmt, err := parser.New().ParseOneStmt("select * from x where r.id > 12 and x.id = 1", "", "")
+	require.NoError(t, err)
+	where := stmt.(*ast.SelectStmt).Where
+	access, err := PruneCondition(r, model.CIStr{}, where)
+	require.NoError(t, err)
+	require.False(t, access.FullScan)
+	require.Equal(t, []model.CIStr{model.NewCIStr("p1")}, access.Partitions)
+	access, err = PruneCondition(r, model.NewCIStr("x"), where)
+	require.NoError(t, err)
+	require.Equal(t, []model.CIStr{model.NewCIStr("p0")}, access.Partitions)
+	access, err = PruneCondition(r, model.CIStr{}, nil)
+	require.NoError(t, err)
+	require.True(t, access.FullScan)
+	require.Len(t, access.Partitions, 2)
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/resolver"
+	"github.com/daiguadaidai/parser/typeinfer"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// selectColumns returns the columns of CREATE TABLE ... SELECT, which are the
+// output columns of the query with their inferred types.
+func (tb *tableBuilder) selectColumns(sel ast.ResultSetNode) ([]*model.ColumnInfo, error) {
+	is := tb.InfoSchema
+	if is == nil {
+		is = resolver.NewInfoSchema()
+	}
+	r := resolver.NewResolver(is, tb.CurrentDB)
+	if err := r.Resolve(sel); err != nil {
+		return nil, err
+	}
+	inferrer := typeinfer.NewInferrer()
+	inferrer.Charset, inferrer.Collation = tb.tbl.Charset, tb.tbl.Collate
+	if err := inferrer.Infer(sel); err != nil {
+		return nil, err
+	}
+
+	fields := r.ResultFields(sel)
+	fts := make([]*types.FieldType, len(fields))
+	if setOpr, ok := sel.(*ast.SetOprStmt); ok {
+		for _, leaf := range setOprLeaves(setOpr.SelectList) {
+			for i, f := range r.ResultFields(leaf) {
+				if i < len(fts) {
+					fts[i] = typeinfer.AggregateTypes(fts[i], fieldType(f))
+				}
+			}
+		}
+	} else {
+		for i, f := range fields {
+			fts[i] = fieldType(f)
+		}
+	}
+
+	cols := make([]*model.ColumnInfo, 0, len(fields))
+	for i, f := range fields {
+		if err := checkColumnName(f.ColumnAsName.O); err != nil {
+			return nil, err
+		}
+		col := &model.ColumnInfo{
+			Name:      f.ColumnAsName,
+			FieldType: *tb.selectColumnType(fts[i]),
+			State:     model.StatePublic,
+			Version:   model.CurrLatestColumnInfoVersion,
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// setOprLeaves returns the query blocks of a set operation.
+func setOprLeaves(list *ast.SetOprSelectList) []ast.ResultSetNode {
+	var leaves []ast.ResultSetNode
+	for _, sel := range list.Selects {
+		switch x := sel.(type) {
+		case *ast.SetOprSelectList:
+			leaves = append(leaves, setOprLeaves(x)...)
+		case ast.ResultSetNode:
+			leaves = append(leaves, x)
+		}
+	}
+	return leaves
+}
+
+// fieldType returns the type of an output column.
+func fieldType(f *ast.ResultField) *types.FieldType {
+	if f.Expr != nil && f.Expr.GetType() != nil && f.Expr.GetType().GetType() != mysql.TypeUnspecified {
+		return f.Expr.GetType()
+	}
+	if f.Column != nil {
+		return &f.Column.FieldType
+	}
+	return types.NewFieldType(mysql.TypeNull)
+}
+
+// selectColumnType converts the type of an output column to the type of a
+// column: VAR_STRING becomes VARCHAR or TEXT, NULL becomes BINARY(0), and the
+// flags of the keys and the defaults are dropped.
+func (tb *tableBuilder) selectColumnType(ft *types.FieldType) *types.FieldType {
+	ft = ft.Clone()
+	switch ft.GetType() {
+	case mysql.TypeNull, mysql.TypeUnspecified:
+		ft = types.NewFieldType(mysql.TypeString)
+		ft.SetFlen(0)
+		ft.SetCharset(charset.CharsetBin)
+		ft.SetCollate(charset.CollationBin)
+		ft.AddFlag(mysql.BinaryFlag)
+	case mysql.TypeVarString:
+		ft.SetType(mysql.TypeVarchar)
+		cs := ft.GetCharset()
+		if cs == "" {
+			cs = tb.tbl.Charset
+		}
+		if ft.GetFlen()*maxLenOf(cs) > mysql.MaxFieldVarCharLength {
+			ft.SetType(blobTypeOf(ft.GetFlen() * maxLenOf(cs)))
+		}
+	}
+	if isStringType(ft.GetType()) && ft.GetCharset() == "" {
+		ft.SetCharset(tb.tbl.Charset)
+		ft.SetCollate(tb.tbl.Collate)
+	}
+	ft.SetFlag(ft.GetFlag() & (mysql.NotNullFlag | mysql.UnsignedFlag | mysql.ZerofillFlag | mysql.BinaryFlag))
+	return ft
+}
@@ -0,0 +1,476 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/tidb"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// showCreateFlags are the flags of the text of SHOW CREATE TABLE.
+const showCreateFlags = format.RestoreStringSingleQuotes | format.RestoreStringEscapeBackslash |
+	format.RestoreNameBackQuotes | format.RestoreKeyWordUppercase
+
+// ShowCreateTable returns the CREATE TABLE statement of a table like SHOW
+// CREATE TABLE. Only the RestoreTiDBSpecialComment flag of flags is used: the
+// TiDB extensions like CLUSTERED and SHARD_ROW_ID_BITS are written in TiDB
+// special comments if it is set, otherwise they are written as plain text.
+func ShowCreateTable(tbl *model.TableInfo, flags format.RestoreFlags) (string, error) {
+	var sb strings.Builder
+	ctx := format.NewRestoreCtx(showCreateFlags|flags&format.RestoreTiDBSpecialComment, &sb)
+	w := &showWriter{ctx: ctx, tbl: tbl}
+	if err := w.writeTable(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+type showWriter struct {
+	ctx *format.RestoreCtx
+	tbl *model.TableInfo
+}
+
+func (w *showWriter) writeTable() error {
+	ctx, tbl := w.ctx, w.tbl
+	switch tbl.TempTableType {
+	case model.TempTableGlobal:
+		ctx.WriteKeyWord("CREATE GLOBAL TEMPORARY TABLE ")
+	case model.TempTableLocal:
+		ctx.WriteKeyWord("CREATE TEMPORARY TABLE ")
+	default:
+		ctx.WriteKeyWord("CREATE TABLE ")
+	}
+	ctx.WriteName(tbl.Name.O)
+	ctx.WritePlain(" (\n")
+
+	var lines []func() error
+	for _, col := range tbl.Columns {
+		if col.Hidden {
+			continue
+		}
+		col := col
+		lines = append(lines, func() error { return w.writeColumn(col) })
+	}
+	if tbl.PKIsHandle {
+		if pk := tbl.GetPkColInfo(); pk != nil {
+			lines = append(lines, func() error { return w.writePKHandle(pk) })
+		}
+	}
+	for _, idx := range tbl.Indices {
+		idx := idx
+		lines = append(lines, func() error { return w.writeIndex(idx) })
+	}
+	for _, fk := range tbl.ForeignKeys {
+		fk := fk
+		lines = append(lines, func() error { w.writeForeignKey(fk); return nil })
+	}
+	for _, c := range tbl.Constraints {
+		c := c
+		lines = append(lines, func() error { w.writeCheck(c); return nil })
+	}
+	for i, line := range lines {
+		if i > 0 {
+			ctx.WritePlain(",\n")
+		}
+		ctx.WritePlain("  ")
+		if err := line(); err != nil {
+			return err
+		}
+	}
+	ctx.WritePlain("\n)")
+
+	if err := w.writeTableOptions(); err != nil {
+		return err
+	}
+	if tbl.Partition != nil {
+		return w.writePartition()
+	}
+	return nil
+}
+
+func (w *showWriter) writeColumn(col *model.ColumnInfo) error {
+	ctx, tbl := w.ctx, w.tbl
+	ctx.WriteName(col.Name.O)
+	ctx.WritePlain(" ")
+	ctx.WritePlain(col.GetTypeDesc())
+	if isStringType(col.GetType()) && col.GetCharset() != charset.CharsetBin {
+		if col.GetCharset() != tbl.Charset {
+			ctx.WriteKeyWord(" CHARACTER SET ")
+			ctx.WritePlain(col.GetCharset())
+		}
+		if col.GetCollate() != tbl.Collate {
+			if def, err := charset.GetDefaultCollation(col.GetCharset()); err != nil || col.GetCharset() == tbl.Charset || def != col.GetCollate() {
+				ctx.WriteKeyWord(" COLLATE ")
+				ctx.WritePlain(col.GetCollate())
+			}
+		}
+	}
+	if col.IsGenerated() {
+		ctx.WriteKeyWord(" GENERATED ALWAYS AS ")
+		ctx.WritePlainf("(%s)", col.GeneratedExprString)
+		if col.GeneratedStored {
+			ctx.WriteKeyWord(" STORED")
+		} else {
+			ctx.WriteKeyWord(" VIRTUAL")
+		}
+	}
+
+	flag := col.GetFlag()
+	if mysql.HasNotNullFlag(flag) {
+		ctx.WriteKeyWord(" NOT NULL")
+	} else if col.GetType() == mysql.TypeTimestamp {
+		ctx.WriteKeyWord(" NULL")
+	}
+	if mysql.HasAutoIncrementFlag(flag) {
+		ctx.WriteKeyWord(" AUTO_INCREMENT")
+	} else if tbl.AutoRandomBits > 0 && mysql.HasPriKeyFlag(flag) && tbl.PKIsHandle {
+		ctx.WritePlain(" ")
+		if err := ctx.WriteWithSpecialComments(tidb.FeatureIDAutoRandom, func() error {
+			ctx.WriteKeyWord("AUTO_RANDOM")
+			ctx.WritePlainf("(%d)", tbl.AutoRandomBits)
+			return nil
+		}); err != nil {
+			return err
+		}
+	} else if !col.IsGenerated() {
+		w.writeDefault(col)
+	}
+	if mysql.HasOnUpdateNowFlag(flag) {
+		ctx.WriteKeyWord(" ON UPDATE CURRENT_TIMESTAMP")
+		writeFsp(ctx, col)
+	}
+	if col.Comment != "" {
+		ctx.WriteKeyWord(" COMMENT ")
+		ctx.WriteString(col.Comment)
+	}
+	return nil
+}
+
+// writeDefault writes the DEFAULT clause of a column, the columns of BLOB,
+// TEXT, JSON and GEOMETRY have no DEFAULT NULL like MySQL.
+func (w *showWriter) writeDefault(col *model.ColumnInfo) {
+	ctx := w.ctx
+	value := col.GetDefaultValue()
+	if value == nil {
+		tp := col.GetType()
+		if mysql.HasNotNullFlag(col.GetFlag()) || types.IsTypeBlob(tp) || tp == mysql.TypeJSON || tp == mysql.TypeGeometry {
+			return
+		}
+		ctx.WriteKeyWord(" DEFAULT NULL")
+		return
+	}
+	s := fmt.Sprint(value)
+	ctx.WriteKeyWord(" DEFAULT ")
+	switch {
+	case col.DefaultIsExpr:
+		ctx.WritePlainf("(%s)", s)
+	case strings.EqualFold(s, ast.CurrentTimestamp) && (col.GetType() == mysql.TypeTimestamp || col.GetType() == mysql.TypeDatetime):
+		ctx.WriteKeyWord("CURRENT_TIMESTAMP")
+		writeFsp(ctx, col)
+	case col.GetType() == mysql.TypeBit:
+		ctx.WritePlain(bitLiteral(s))
+	default:
+		ctx.WriteString(s)
+	}
+}
+
+// writeFsp writes the fractional seconds precision of CURRENT_TIMESTAMP.
+func writeFsp(ctx *format.RestoreCtx, col *model.ColumnInfo) {
+	if col.GetDecimal() > 0 {
+		ctx.WritePlainf("(%d)", col.GetDecimal())
+	}
+}
+
+// bitLiteral returns the bit literal of the bytes of a BIT value.
+func bitLiteral(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&sb, "%08b", s[i])
+	}
+	bits := strings.TrimLeft(sb.String(), "0")
+	if bits == "" {
+		bits = "0"
+	}
+	return "b'" + bits + "'"
+}
+
+func (w *showWriter) writeClustered(clustered bool) error {
+	ctx := w.ctx
+	ctx.WritePlain(" ")
+	return ctx.WriteWithSpecialComments(tidb.FeatureIDClusteredIndex, func() error {
+		if clustered {
+			ctx.WriteKeyWord("CLUSTERED")
+		} else {
+			ctx.WriteKeyWord("NONCLUSTERED")
+		}
+		return nil
+	})
+}
+
+func (w *showWriter) writePKHandle(pk *model.ColumnInfo) error {
+	w.ctx.WriteKeyWord("PRIMARY KEY ")
+	w.ctx.WritePlain("(")
+	w.ctx.WriteName(pk.Name.O)
+	w.ctx.WritePlain(")")
+	return w.writeClustered(true)
+}
+
+func (w *showWriter) writeIndex(idx *model.IndexInfo) error {
+	ctx := w.ctx
+	switch {
+	case idx.Primary:
+		ctx.WriteKeyWord("PRIMARY KEY ")
+	case idx.Unique:
+		ctx.WriteKeyWord("UNIQUE KEY ")
+		ctx.WriteName(idx.Name.O)
+		ctx.WritePlain(" ")
+	default:
+		ctx.WriteKeyWord("KEY ")
+		ctx.WriteName(idx.Name.O)
+		ctx.WritePlain(" ")
+	}
+	ctx.WritePlain("(")
+	for i, ic := range idx.Columns {
+		if i > 0 {
+			ctx.WritePlain(",")
+		}
+		if col := model.FindColumnInfo(w.tbl.Columns, ic.Name.L); col != nil && col.Hidden {
+			ctx.WritePlainf("(%s)", col.GeneratedExprString)
+			continue
+		}
+		ctx.WriteName(ic.Name.O)
+		if ic.Length != types.UnspecifiedLength {
+			ctx.WritePlainf("(%d)", ic.Length)
+		}
+	}
+	ctx.WritePlain(")")
+	if idx.Tp == model.IndexTypeHash || idx.Tp == model.IndexTypeRtree {
+		ctx.WriteKeyWord(" USING ")
+		ctx.WriteKeyWord(idx.Tp.String())
+	}
+	if idx.Comment != "" {
+		ctx.WriteKeyWord(" COMMENT ")
+		ctx.WriteString(idx.Comment)
+	}
+	if idx.Invisible {
+		ctx.WritePlain(" /*!80000 INVISIBLE */")
+	}
+	if idx.Primary {
+		return w.writeClustered(w.tbl.IsCommonHandle)
+	}
+	return nil
+}
+
+func (w *showWriter) writeNames(names []model.CIStr) {
+	w.ctx.WritePlain("(")
+	for i, name := range names {
+		if i > 0 {
+			w.ctx.WritePlain(",")
+		}
+		w.ctx.WriteName(name.O)
+	}
+	w.ctx.WritePlain(")")
+}
+
+func (w *showWriter) writeForeignKey(fk *model.FKInfo) {
+	ctx := w.ctx
+	ctx.WriteKeyWord("CONSTRAINT ")
+	ctx.WriteName(fk.Name.O)
+	ctx.WriteKeyWord(" FOREIGN KEY ")
+	w.writeNames(fk.Cols)
+	ctx.WriteKeyWord(" REFERENCES ")
+	if fk.RefSchema.O != "" {
+		ctx.WriteName(fk.RefSchema.O)
+		ctx.WritePlain(".")
+	}
+	ctx.WriteName(fk.RefTable.O)
+	ctx.WritePlain(" ")
+	w.writeNames(fk.RefCols)
+	if opt := ast.ReferOptionType(fk.OnDelete); opt != ast.ReferOptionNoOption {
+		ctx.WriteKeyWord(" ON DELETE ")
+		ctx.WriteKeyWord(opt.String())
+	}
+	if opt := ast.ReferOptionType(fk.OnUpdate); opt != ast.ReferOptionNoOption {
+		ctx.WriteKeyWord(" ON UPDATE ")
+		ctx.WriteKeyWord(opt.String())
+	}
+}
+
+func (w *showWriter) writeCheck(c *model.ConstraintInfo) {
+	ctx := w.ctx
+	ctx.WriteKeyWord("CONSTRAINT ")
+	ctx.WriteName(c.Name.O)
+	ctx.WriteKeyWord(" CHECK ")
+	ctx.WritePlainf("((%s))", c.ExprString)
+	if !c.Enforced {
+		ctx.WritePlain(" /*!80016 NOT ENFORCED */")
+	}
+}
+
+func (w *showWriter) writeTableOptions() error {
+	w.ctx.WriteKeyWord(" ENGINE=")
+	w.ctx.WritePlain("InnoDB")
+	return w.writeOptions()
+}
+
+// writeOptions writes the table options but ENGINE, the options of zero
+// values are omitted.
+func (w *showWriter) writeOptions() error {
+	ctx, tbl := w.ctx, w.tbl
+	if tbl.Charset != "" {
+		ctx.WriteKeyWord(" DEFAULT CHARSET=")
+		ctx.WritePlain(tbl.Charset)
+	}
+	if tbl.Collate != "" {
+		ctx.WriteKeyWord(" COLLATE=")
+		ctx.WritePlain(tbl.Collate)
+	}
+	if tbl.AutoIncID > 1 {
+		ctx.WriteKeyWord(" AUTO_INCREMENT=")
+		ctx.WritePlainf("%d", tbl.AutoIncID)
+	}
+	if tbl.AutoIdCache != 0 {
+		ctx.WritePlain(" ")
+		if err := ctx.WriteWithSpecialComments(tidb.FeatureIDAutoIDCache, func() error {
+			ctx.WriteKeyWord("AUTO_ID_CACHE=")
+			ctx.WritePlainf("%d", tbl.AutoIdCache)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if tbl.AutoRandID != 0 {
+		ctx.WritePlain(" ")
+		if err := ctx.WriteWithSpecialComments(tidb.FeatureIDAutoRandomBase, func() error {
+			ctx.WriteKeyWord("AUTO_RANDOM_BASE=")
+			ctx.WritePlainf("%d", tbl.AutoRandID)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if tbl.ShardRowIDBits > 0 {
+		ctx.WritePlain(" ")
+		if err := ctx.WriteWithSpecialComments(tidb.FeatureIDTiDB, func() error {
+			ctx.WriteKeyWord("SHARD_ROW_ID_BITS=")
+			ctx.WritePlainf("%d", tbl.ShardRowIDBits)
+			if tbl.PreSplitRegions > 0 {
+				ctx.WriteKeyWord(" PRE_SPLIT_REGIONS=")
+				ctx.WritePlainf("%d", tbl.PreSplitRegions)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if tbl.Compression != "" {
+		ctx.WriteKeyWord(" COMPRESSION=")
+		ctx.WriteString(tbl.Compression)
+	}
+	if tbl.Comment != "" {
+		ctx.WriteKeyWord(" COMMENT=")
+		ctx.WriteString(tbl.Comment)
+	}
+	if tbl.TempTableType == model.TempTableGlobal {
+		ctx.WriteKeyWord(" ON COMMIT DELETE ROWS")
+	}
+	if ref := tbl.PlacementPolicyRef; ref != nil {
+		ctx.WritePlain(" ")
+		return w.writePlacement(ref)
+	}
+	return nil
+}
+
+func (w *showWriter) writePlacement(ref *model.PolicyRefInfo) error {
+	ctx := w.ctx
+	return ctx.WriteWithSpecialComments(tidb.FeatureIDPlacement, func() error {
+		ctx.WriteKeyWord("PLACEMENT POLICY=")
+		ctx.WriteName(ref.Name.O)
+		return nil
+	})
+}
+
+func (w *showWriter) writePartition() error {
+	ctx, pi := w.ctx, w.tbl.Partition
+	ctx.WriteKeyWord("\nPARTITION BY ")
+	ctx.WriteKeyWord(pi.Type.String())
+	switch {
+	case pi.Type == model.PartitionTypeKey:
+		ctx.WritePlain(" ")
+		w.writeNames(pi.Columns)
+	case len(pi.Columns) > 0:
+		ctx.WriteKeyWord(" COLUMNS")
+		w.writeNames(pi.Columns)
+	default:
+		ctx.WritePlainf(" (%s)", pi.Expr)
+	}
+	if pi.Type == model.PartitionTypeHash || pi.Type == model.PartitionTypeKey {
+		ctx.WriteKeyWord(" PARTITIONS ")
+		ctx.WritePlainf("%d", len(pi.Definitions))
+		return nil
+	}
+	for i, def := range pi.Definitions {
+		if i == 0 {
+			ctx.WritePlain("\n(")
+		} else {
+			ctx.WritePlain(",\n ")
+		}
+		if err := w.writePartitionDefinition(def); err != nil {
+			return err
+		}
+	}
+	if len(pi.Definitions) > 0 {
+		ctx.WritePlain(")")
+	}
+	return nil
+}
+
+func (w *showWriter) writePartitionDefinition(def model.PartitionDefinition) error {
+	ctx := w.ctx
+	ctx.WriteKeyWord("PARTITION ")
+	ctx.WriteName(def.Name.O)
+	switch w.tbl.Partition.Type {
+	case model.PartitionTypeRange:
+		ctx.WriteKeyWord(" VALUES LESS THAN ")
+		ctx.WritePlainf("(%s)", strings.Join(def.LessThan, ","))
+	case model.PartitionTypeList:
+		ctx.WriteKeyWord(" VALUES IN ")
+		values := make([]string, 0, len(def.InValues))
+		for _, row := range def.InValues {
+			if len(row) == 1 {
+				values = append(values, row[0])
+			} else {
+				values = append(values, "("+strings.Join(row, ",")+")")
+			}
+		}
+		ctx.WritePlainf("(%s)", strings.Join(values, ","))
+	}
+	if def.Comment != "" {
+		ctx.WriteKeyWord(" COMMENT ")
+		ctx.WriteString(def.Comment)
+	}
+	if def.PlacementPolicyRef != nil {
+		ctx.WritePlain(" ")
+		return w.writePlacement(def.PlacementPolicyRef)
+	}
+	return nil
+}
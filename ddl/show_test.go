@@ -0,0 +1,116 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"testing"
+
+	. "github.com/daiguadaidai/parser/ddl"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowCreateTable(t *testing.T) {
+	cases := []struct {
+		sql  string
+		show string
+	}{
+		{
+			"create table t (id int auto_increment primary key, a varchar(10) collate utf8mb4_general_ci not null default 'x' comment 'it''s', b timestamp(3) default current_timestamp(3) on update current_timestamp(3), c int as (length(a)) stored, d text, e bit(4) default b'101', unique key ua (a(5)), key (b, c) invisible, key ((c * 2)))",
+			"CREATE TABLE `t` (\n" +
+				"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+				"  `a` varchar(10) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'x' COMMENT 'it''s',\n" +
+				"  `b` timestamp(3) NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),\n" +
+				"  `c` int(11) GENERATED ALWAYS AS (length(`a`)) STORED,\n" +
+				"  `d` text,\n" +
+				"  `e` bit(4) DEFAULT b'101',\n" +
+				"  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */,\n" +
+				"  UNIQUE KEY `ua` (`a`(5)),\n" +
+				"  KEY `b` (`b`,`c`) /*!80000 INVISIBLE */,\n" +
+				"  KEY `functional_index` ((`c` * 2))\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin",
+		},
+		{
+			"create table t (a varchar(10) charset latin1, b int, primary key (a, b), constraint fk foreign key (b) references p (id) on delete cascade, constraint ck check (b > 0) not enforced) charset utf8 auto_increment 100 shard_row_id_bits 4 pre_split_regions 2 comment 'c'",
+			"CREATE TABLE `t` (\n" +
+				"  `a` varchar(10) CHARACTER SET latin1 NOT NULL,\n" +
+				"  `b` int(11) NOT NULL,\n" +
+				"  PRIMARY KEY (`a`,`b`) /*T![clustered_index] NONCLUSTERED */,\n" +
+				"  KEY `fk` (`b`),\n" +
+				"  CONSTRAINT `fk` FOREIGN KEY (`b`) REFERENCES `p` (`id`) ON DELETE CASCADE,\n" +
+				"  CONSTRAINT `ck` CHECK ((`b` > 0)) /*!80016 NOT ENFORCED */\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_INCREMENT=100 /*T! SHARD_ROW_ID_BITS=4 PRE_SPLIT_REGIONS=2 */ COMMENT='c'",
+		},
+		{
+			"create table t (a int, b date) partition by range columns (b) (partition p0 values less than ('2020-01-01') comment 'x', partition p1 values less than (maxvalue))",
+			"CREATE TABLE `t` (\n" +
+				"  `a` int(11) DEFAULT NULL,\n" +
+				"  `b` date DEFAULT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin\n" +
+				"PARTITION BY RANGE COLUMNS(`b`)\n" +
+				"(PARTITION `p0` VALUES LESS THAN ('2020-01-01') COMMENT 'x',\n" +
+				" PARTITION `p1` VALUES LESS THAN (MAXVALUE))",
+		},
+		{
+			"create table t (a date default 20200101, b datetime(2) default '2020-01-01', c time(2) default '10:00:00.5', d year default 69)",
+			"CREATE TABLE `t` (\n" +
+				"  `a` date DEFAULT '2020-01-01',\n" +
+				"  `b` datetime(2) DEFAULT '2020-01-01 00:00:00.00',\n" +
+				"  `c` time(2) DEFAULT '10:00:00.50',\n" +
+				"  `d` year(4) DEFAULT '2069'\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin",
+		},
+		{
+			"create table t (a int) partition by list (a) (partition p0 values in (1, 2), partition p1 values in (3))",
+			"CREATE TABLE `t` (\n" +
+				"  `a` int(11) DEFAULT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin\n" +
+				"PARTITION BY LIST (`a`)\n" +
+				"(PARTITION `p0` VALUES IN (1,2),\n" +
+				" PARTITION `p1` VALUES IN (3))",
+		},
+		{
+			"create table t (a int) partition by hash (a) partitions 4",
+			"CREATE TABLE `t` (\n" +
+				"  `a` int(11) DEFAULT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin\n" +
+				"PARTITION BY HASH (`a`) PARTITIONS 4",
+		},
+	}
+	for _, c := range cases {
+		tbl := mustBuild(t, NewBuilder(), c.sql)
+		show, err := ShowCreateTable(tbl, format.RestoreTiDBSpecialComment)
+		require.NoError(t, err, c.sql)
+		require.Equal(t, c.show, show, c.sql)
+
+		// The text builds the same table.
+		again, err := ShowCreateTable(mustBuild(t, NewBuilder(), show), format.RestoreTiDBSpecialComment)
+		require.NoError(t, err, show)
+		require.Equal(t, show, again)
+	}
+
+	tbl := mustBuild(t, NewBuilder(), "create table t (a bigint auto_random(3) primary key) auto_random_base 10")
+	show, err := ShowCreateTable(tbl, format.RestoreTiDBSpecialComment)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `t` (\n"+
+		"  `a` bigint(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(3) */,\n"+
+		"  PRIMARY KEY (`a`) /*T![clustered_index] CLUSTERED */\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin /*T![auto_rand_base] AUTO_RANDOM_BASE=10 */", show)
+	show, err = ShowCreateTable(tbl, 0)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `t` (\n"+
+		"  `a` bigint(20) NOT NULL AUTO_RANDOM(3),\n"+
+		"  PRIMARY KEY (`a`) CLUSTERED\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin AUTO_RANDOM_BASE=10", show)
+}
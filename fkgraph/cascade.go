@@ -0,0 +1,153 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fkgraph
+
+import (
+	"fmt"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+)
+
+// Impact is a foreign key through which a DELETE or an UPDATE of a table
+// affects the rows of another table, or fails.
+type Impact struct {
+	// Edge is the foreign key, the affected table is the Edge.Child.
+	Edge *Edge
+	// Delete is true if the event is a deletion of the referenced rows, it is
+	// false for an update of the referenced columns.
+	Delete bool
+	// Action is the referential action of the event: ReferOptionCascade
+	// deletes or updates the referencing rows, ReferOptionSetNull and
+	// ReferOptionSetDefault update them, and ReferOptionRestrict fails the
+	// statement if there are referencing rows. NO ACTION and no action are
+	// reported as RESTRICT, like InnoDB does.
+	Action ast.ReferOptionType
+	// Depth is the number of the foreign keys from the table of the
+	// statement, which is 1 for the tables referencing it.
+	Depth int
+}
+
+// String implements fmt.Stringer interface.
+func (i *Impact) String() string {
+	event := "UPDATE"
+	if i.Delete {
+		event = "DELETE"
+	}
+	return fmt.Sprintf("%s %s ON %s %s", i.Edge.Child, i.Edge.FK.Name.O, event, i.Action)
+}
+
+// Cascades returns whether the rows of the table are deleted or updated.
+func (i *Impact) Cascades() bool {
+	return i.Action != ast.ReferOptionRestrict
+}
+
+// DeleteImpacts returns the impacts of a DELETE from a table in depth-first
+// order: the rows referencing the deleted rows are deleted or updated by
+// the foreign keys, which deletes or updates the rows referencing them in
+// turn. A foreign key is reported once for an event.
+func (g *Graph) DeleteImpacts(t Table) []*Impact {
+	w := &impactWalker{g: g, seen: make(map[impactKey]bool)}
+	w.delete(t.key(), 1)
+	return w.res
+}
+
+// UpdateImpacts returns the impacts of an UPDATE of the columns of a table
+// like DeleteImpacts, all the columns are updated if cols is nil. Only the
+// foreign keys referencing the updated columns are affected.
+func (g *Graph) UpdateImpacts(t Table, cols []model.CIStr) []*Impact {
+	w := &impactWalker{g: g, seen: make(map[impactKey]bool)}
+	w.update(t.key(), cols, 1)
+	return w.res
+}
+
+// ImpactedTables returns the tables whose rows are deleted or updated by the
+// impacts, in the order of the impacts.
+func ImpactedTables(impacts []*Impact) []Table {
+	var res []Table
+	seen := make(map[Table]bool)
+	for _, i := range impacts {
+		if t := i.Edge.Child; i.Cascades() && !seen[t.key()] {
+			seen[t.key()] = true
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+type impactKey struct {
+	edge   *Edge
+	delete bool
+}
+
+type impactWalker struct {
+	g    *Graph
+	seen map[impactKey]bool
+	res  []*Impact
+}
+
+// add adds the impact of an event through a foreign key, it returns false if
+// the impact is added before.
+func (w *impactWalker) add(e *Edge, delete bool, action int, depth int) (*Impact, bool) {
+	key := impactKey{edge: e, delete: delete}
+	if w.seen[key] {
+		return nil, false
+	}
+	w.seen[key] = true
+	i := &Impact{Edge: e, Delete: delete, Action: ast.ReferOptionType(action), Depth: depth}
+	if i.Action == ast.ReferOptionNoOption || i.Action == ast.ReferOptionNoAction {
+		i.Action = ast.ReferOptionRestrict
+	}
+	w.res = append(w.res, i)
+	return i, true
+}
+
+func (w *impactWalker) delete(t Table, depth int) {
+	for _, e := range w.g.children[t] {
+		i, ok := w.add(e, true, e.FK.OnDelete, depth)
+		if !ok {
+			continue
+		}
+		switch i.Action {
+		case ast.ReferOptionCascade:
+			w.delete(e.Child.key(), depth+1)
+		case ast.ReferOptionSetNull, ast.ReferOptionSetDefault:
+			w.update(e.Child.key(), e.FK.Cols, depth+1)
+		}
+	}
+}
+
+func (w *impactWalker) update(t Table, cols []model.CIStr, depth int) {
+	for _, e := range w.g.children[t] {
+		if cols != nil && !overlaps(e.FK.RefCols, cols) {
+			continue
+		}
+		i, ok := w.add(e, false, e.FK.OnUpdate, depth)
+		if ok && i.Cascades() {
+			w.update(e.Child.key(), e.FK.Cols, depth+1)
+		}
+	}
+}
+
+// overlaps returns whether two lists of columns have a common column.
+func overlaps(a, b []model.CIStr) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.L == y.L {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fkgraph builds the graph of the foreign keys of a schema catalog.
+//
+// The graph finds the cycles of the foreign keys, orders the tables so that
+// the referenced tables are created before the tables referencing them, orders
+// the CREATE TABLE, DROP TABLE, INSERT and DELETE statements of a script so
+// they satisfy the foreign keys, and reports the tables a DELETE or an UPDATE
+// cascades into by the referential actions.
+//
+// A referenced table without a database in the FKInfo is in the database of
+// the referencing table.
+package fkgraph
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/model"
+	"github.com/pingcap/errors"
+)
+
+// Table is a table of the graph.
+type Table struct {
+	Schema string
+	Name   string
+}
+
+// String implements fmt.Stringer interface.
+func (t Table) String() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// key returns the case insensitive key of the table.
+func (t Table) key() Table {
+	return Table{Schema: strings.ToLower(t.Schema), Name: strings.ToLower(t.Name)}
+}
+
+// Edge is a foreign key of the Child table referencing the Parent table.
+type Edge struct {
+	Child  Table
+	Parent Table
+	FK     *model.FKInfo
+}
+
+// Graph is the graph of the foreign keys.
+type Graph struct {
+	// tables are the tables of the catalog, then the referenced tables which
+	// are not in the catalog.
+	tables   []Table
+	known    map[Table]bool
+	index    map[Table]int
+	parents  map[Table][]*Edge
+	children map[Table][]*Edge
+}
+
+// New builds the graph of the foreign keys of the tables of the databases,
+// like the Schemas of a ddl.Catalog. The views and the sequences are skipped.
+func New(dbs []*model.DBInfo) *Graph {
+	g := &Graph{
+		known:    make(map[Table]bool),
+		index:    make(map[Table]int),
+		parents:  make(map[Table][]*Edge),
+		children: make(map[Table][]*Edge),
+	}
+	var edges []*Edge
+	for _, db := range dbs {
+		for _, tbl := range db.Tables {
+			if tbl.IsView() || tbl.IsSequence() {
+				continue
+			}
+			t := Table{Schema: db.Name.O, Name: tbl.Name.O}
+			g.add(t)
+			g.known[t.key()] = true
+			for _, fk := range tbl.ForeignKeys {
+				parent := Table{Schema: fk.RefSchema.O, Name: fk.RefTable.O}
+				if parent.Schema == "" {
+					parent.Schema = db.Name.O
+				}
+				edges = append(edges, &Edge{Child: t, Parent: parent, FK: fk})
+			}
+		}
+	}
+	for _, e := range edges {
+		g.addEdge(e)
+	}
+	return g
+}
+
+// add adds a table if it is not in the graph and returns it as it is named in
+// the graph.
+func (g *Graph) add(t Table) Table {
+	if i, ok := g.index[t.key()]; ok {
+		return g.tables[i]
+	}
+	g.index[t.key()] = len(g.tables)
+	g.tables = append(g.tables, t)
+	return t
+}
+
+// addEdge adds a foreign key, the tables are renamed as they are named in the
+// graph.
+func (g *Graph) addEdge(e *Edge) {
+	e.Child, e.Parent = g.add(e.Child), g.add(e.Parent)
+	g.parents[e.Child.key()] = append(g.parents[e.Child.key()], e)
+	g.children[e.Parent.key()] = append(g.children[e.Parent.key()], e)
+}
+
+// Tables returns the tables of the catalog.
+func (g *Graph) Tables() []Table {
+	res := make([]Table, 0, len(g.known))
+	for _, t := range g.tables {
+		if g.known[t.key()] {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Parents returns the foreign keys of a table.
+func (g *Graph) Parents(t Table) []*Edge {
+	return g.parents[t.key()]
+}
+
+// Children returns the foreign keys referencing a table.
+func (g *Graph) Children(t Table) []*Edge {
+	return g.children[t.key()]
+}
+
+// Cycles returns the cycles of the foreign keys, which are the strongly
+// connected components of the graph with more than one table and the tables
+// referencing themselves. The tables of a cycle are in the order of the
+// catalog. A cycle makes the tables impossible to be filled or emptied one
+// after another without disabling foreign_key_checks, unless a foreign key
+// column is nullable.
+func (g *Graph) Cycles() [][]Table {
+	s := &sccFinder{g: g, index: make(map[Table]int), low: make(map[Table]int), onStack: make(map[Table]bool)}
+	for _, t := range g.tables {
+		if _, ok := s.index[t.key()]; !ok {
+			s.visit(t.key())
+		}
+	}
+	var res [][]Table
+	for _, comp := range s.comps {
+		if len(comp) == 1 && !g.references(comp[0], comp[0]) {
+			continue
+		}
+		cycle := make([]Table, 0, len(comp))
+		for _, t := range g.tables {
+			for _, k := range comp {
+				if t.key() == k {
+					cycle = append(cycle, t)
+				}
+			}
+		}
+		res = append(res, cycle)
+	}
+	// The cycles are in the order of their first tables.
+	for i := 1; i < len(res); i++ {
+		for j := i; j > 0 && g.index[res[j][0].key()] < g.index[res[j-1][0].key()]; j-- {
+			res[j], res[j-1] = res[j-1], res[j]
+		}
+	}
+	return res
+}
+
+// references returns whether the child table references the parent table.
+func (g *Graph) references(child, parent Table) bool {
+	for _, e := range g.parents[child.key()] {
+		if e.Parent.key() == parent.key() {
+			return true
+		}
+	}
+	return false
+}
+
+// sccFinder finds the strongly connected components by Tarjan's algorithm.
+type sccFinder struct {
+	g       *Graph
+	next    int
+	index   map[Table]int
+	low     map[Table]int
+	stack   []Table
+	onStack map[Table]bool
+	comps   [][]Table
+}
+
+func (s *sccFinder) visit(t Table) {
+	s.index[t], s.low[t] = s.next, s.next
+	s.next++
+	s.stack = append(s.stack, t)
+	s.onStack[t] = true
+	for _, e := range s.g.parents[t] {
+		p := e.Parent.key()
+		if _, ok := s.index[p]; !ok {
+			s.visit(p)
+			if s.low[p] < s.low[t] {
+				s.low[t] = s.low[p]
+			}
+		} else if s.onStack[p] && s.index[p] < s.low[t] {
+			s.low[t] = s.index[p]
+		}
+	}
+	if s.low[t] != s.index[t] {
+		return
+	}
+	var comp []Table
+	for {
+		top := s.stack[len(s.stack)-1]
+		s.stack = s.stack[:len(s.stack)-1]
+		s.onStack[top] = false
+		comp = append(comp, top)
+		if top == t {
+			break
+		}
+	}
+	s.comps = append(s.comps, comp)
+}
+
+// Order returns the tables of the catalog in the order they can be created or
+// filled, the referenced tables first, the order of the catalog is kept for
+// the tables not depending on each other. The tables referencing themselves
+// are ordered by their other foreign keys. Reversed, it is the order the
+// tables can be dropped or emptied. An error is returned for a cycle.
+func (g *Graph) Order() ([]Table, error) {
+	tables := g.Tables()
+	deps := make(map[Table]map[Table]bool, len(tables))
+	for _, t := range tables {
+		deps[t.key()] = make(map[Table]bool)
+		for _, e := range g.parents[t.key()] {
+			if p := e.Parent.key(); p != t.key() && g.known[p] {
+				deps[t.key()][p] = true
+			}
+		}
+	}
+	res := make([]Table, 0, len(tables))
+	done := make(map[Table]bool, len(tables))
+	for len(res) < len(tables) {
+		progress := false
+		for _, t := range tables {
+			if done[t.key()] || !allDone(deps[t.key()], done) {
+				continue
+			}
+			res = append(res, t)
+			done[t.key()] = true
+			progress = true
+			break
+		}
+		if !progress {
+			return nil, g.cycleError()
+		}
+	}
+	return res, nil
+}
+
+// cycleError returns the error of the first cycle of more than one table.
+func (g *Graph) cycleError() error {
+	for _, cycle := range g.Cycles() {
+		if len(cycle) > 1 {
+			return errors.Errorf("foreign key cycle: %s", cycleString(cycle))
+		}
+	}
+	return errors.New("foreign key cycle")
+}
+
+// allDone returns whether all the tables are done.
+func allDone(tables, done map[Table]bool) bool {
+	for t := range tables {
+		if !done[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// cycleString returns the text of a cycle like "a, b".
+func cycleString(cycle []Table) string {
+	names := make([]string, 0, len(cycle))
+	for _, t := range cycle {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, ", ")
+}
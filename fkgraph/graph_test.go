@@ -0,0 +1,173 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fkgraph_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/ddl"
+	. "github.com/daiguadaidai/parser/fkgraph"
+	"github.com/daiguadaidai/parser/model"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, sql string) []ast.StmtNode {
+	stmts, _, err := parser.New().Parse(sql, "", "")
+	require.NoError(t, err, sql)
+	return stmts
+}
+
+func newGraph(t *testing.T, sql string) *Graph {
+	c := ddl.NewCatalog()
+	for _, stmt := range parse(t, sql) {
+		require.NoError(t, c.Apply(stmt), sql)
+	}
+	return New(c.Schemas())
+}
+
+func tableStrings(tables []Table) []string {
+	res := make([]string, 0, len(tables))
+	for _, t := range tables {
+		res = append(res, t.String())
+	}
+	return res
+}
+
+func impactStrings(impacts []*Impact) []string {
+	res := make([]string, 0, len(impacts))
+	for _, i := range impacts {
+		res = append(res, fmt.Sprintf("%d %s", i.Depth, i))
+	}
+	return res
+}
+
+const schema = `create database d; use d;
+	create table c (id int primary key, oid int, pid int,
+		foreign key fk_c_o (oid) references o (id) on delete cascade,
+		foreign key fk_c_p (pid) references p (id));
+	create table o (id int primary key, uid int, code int, unique key (code),
+		constraint fk_o_u foreign key (uid) references u (id) on delete cascade on update cascade);
+	create table u (id int primary key, mid int,
+		constraint fk_u_m foreign key (mid) references u (id) on delete set null);
+	create table p (id int primary key);
+	create table l (id int primary key, code int,
+		constraint fk_l_o foreign key (code) references o (code) on delete set null on update restrict);
+	create table x (id int primary key, yid int);
+	create table y (id int primary key, xid int, constraint fk_y_x foreign key (xid) references x (id));
+	alter table x add constraint fk_x_y foreign key (yid) references y (id)`
+
+func TestGraph(t *testing.T) {
+	// The referenced tables may be created later with foreign_key_checks = 0,
+	// which the catalog does not check.
+	g := newGraph(t, schema)
+	require.Equal(t, []string{"d.c", "d.o", "d.u", "d.p", "d.l", "d.x", "d.y"}, tableStrings(g.Tables()))
+	c := Table{Schema: "D", Name: "C"}
+	require.Len(t, g.Parents(c), 2)
+	require.Equal(t, "d.o", g.Parents(c)[0].Parent.String())
+	require.Equal(t, "fk_c_o", g.Parents(c)[0].FK.Name.O)
+	require.Len(t, g.Children(Table{Schema: "d", Name: "o"}), 2)
+
+	require.Equal(t, [][]string{{"d.u"}, {"d.x", "d.y"}}, [][]string{
+		tableStrings(g.Cycles()[0]), tableStrings(g.Cycles()[1]),
+	})
+	_, err := g.Order()
+	require.EqualError(t, err, "foreign key cycle: d.x, d.y")
+
+	g = newGraph(t, schema+"; alter table x drop foreign key fk_x_y")
+	require.Len(t, g.Cycles(), 1)
+	order, err := g.Order()
+	require.NoError(t, err)
+	require.Equal(t, []string{"d.u", "d.o", "d.p", "d.c", "d.l", "d.x", "d.y"}, tableStrings(order))
+
+	// The parents in another database.
+	g = newGraph(t, `create database d1; create database d2; create table d2.p (id int primary key);
+		create table d1.c (id int primary key, pid int, foreign key (pid) references d2.p (id));
+		create table d1.p (id int primary key)`)
+	parents := g.Parents(Table{Schema: "d1", Name: "c"})
+	require.Len(t, parents, 1)
+	require.Equal(t, "d2.p", parents[0].Parent.String())
+	require.Empty(t, g.Children(Table{Schema: "d1", Name: "p"}))
+}
+
+func TestImpacts(t *testing.T) {
+	g := newGraph(t, schema)
+	u := Table{Schema: "d", Name: "u"}
+	impacts := g.DeleteImpacts(u)
+	require.Equal(t, []string{
+		"1 d.o fk_o_u ON DELETE CASCADE",
+		"2 d.c fk_c_o ON DELETE CASCADE",
+		"2 d.l fk_l_o ON DELETE SET NULL",
+		"1 d.u fk_u_m ON DELETE SET NULL",
+	}, impactStrings(impacts))
+	require.Equal(t, []string{"d.o", "d.c", "d.l", "d.u"}, tableStrings(ImpactedTables(impacts)))
+
+	impacts = g.DeleteImpacts(Table{Schema: "d", Name: "p"})
+	require.Equal(t, []string{"1 d.c fk_c_p ON DELETE RESTRICT"}, impactStrings(impacts))
+	require.False(t, impacts[0].Cascades())
+	require.Empty(t, ImpactedTables(impacts))
+
+	// Only the foreign keys referencing the updated columns are affected.
+	impacts = g.UpdateImpacts(u, []model.CIStr{model.NewCIStr("ID")})
+	require.Equal(t, []string{
+		"1 d.o fk_o_u ON UPDATE CASCADE",
+		"1 d.u fk_u_m ON UPDATE RESTRICT",
+	}, impactStrings(impacts))
+	impacts = g.UpdateImpacts(Table{Schema: "d", Name: "o"}, nil)
+	require.Equal(t, []string{
+		"1 d.c fk_c_o ON UPDATE RESTRICT",
+		"1 d.l fk_l_o ON UPDATE RESTRICT",
+	}, impactStrings(impacts))
+	require.Empty(t, g.UpdateImpacts(u, []model.CIStr{model.NewCIStr("mid")}))
+	require.Empty(t, g.DeleteImpacts(Table{Schema: "d", Name: "none"}))
+}
+
+func TestOrderStatements(t *testing.T) {
+	g := newGraph(t, `create database d; create table d.p (id int primary key);
+		create table d.c (id int primary key, pid int, foreign key (pid) references p (id))`)
+	stmts := parse(t, `
+		create table g (id int primary key, cid int, foreign key (cid) references c (id));
+		insert into c values (1, 1);
+		create table q (id int primary key);
+		create table r (id int primary key, qid int, foreign key (qid) references q (id));
+		insert into p values (1);
+		insert into g values (1, 1);
+		delete from p;
+		delete from c where id > 0;
+		truncate table g;
+		use e;
+		drop table d.q;
+		drop table d.r, d.g;
+		insert into d.p select * from d.p;
+		delete t from d.p as t`)
+	order, err := g.OrderStatements(stmts, "d")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 2, 3, 4, 1, 5, 8, 7, 6, 9, 11, 10, 12, 13}, order)
+
+	stmts = parse(t, `insert into c values (1, 1); delete from p; insert into p values (1)`)
+	// The first INSERT runs before the DELETE of the referenced table, which
+	// runs before the last INSERT.
+	_, err = g.OrderStatements(stmts, "d")
+	require.EqualError(t, err, "statements [0 1 2] can not be ordered by the foreign keys")
+
+	stmts = parse(t, `create table a (id int primary key, bid int, foreign key (bid) references b (id));
+		create table b (id int primary key, aid int, foreign key (aid) references a (id))`)
+	// The tables referencing each other are created in the order of the script.
+	order, err = g.OrderStatements(stmts, "d")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, order)
+}
@@ -0,0 +1,281 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fkgraph
+
+import (
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/pingcap/errors"
+)
+
+// stmtKind is the kind of a statement ordered by the foreign keys.
+type stmtKind int
+
+const (
+	// kindOther is a statement which is not moved, like USE.
+	kindOther stmtKind = iota
+	kindCreate
+	kindDrop
+	kindInsert
+	kindDelete
+)
+
+// stmtInfo is a statement to be ordered.
+type stmtInfo struct {
+	kind stmtKind
+	// targets are the tables created, dropped, filled or emptied.
+	targets []Table
+	// tables are all the tables the statement refers to, but the tables
+	// referenced by the foreign keys it defines.
+	tables map[Table]bool
+	// segment is the number of the part of the script between the
+	// statements which are not moved, the statements are moved in their
+	// segments.
+	segment int
+}
+
+// OrderStatements returns the indexes of the statements in an order which
+// satisfies the foreign keys of the graph and of the created tables:
+//
+//   - CREATE TABLE and INSERT of a referenced table run before the ones of
+//     the tables referencing it.
+//   - DROP TABLE, DELETE and TRUNCATE TABLE of a referencing table run
+//     before the ones of the tables it references.
+//
+// The other statements, like USE, are not moved and no statement is moved
+// across them. The statements referring to the same table, and the statements
+// of different kinds on the tables related by a foreign key, keep their order.
+// The order of the script is kept otherwise. currentDB is the database of the
+// unqualified table names before the first USE. An error is returned if the
+// statements can not be ordered, like an INSERT of a referencing table, a
+// DELETE and an INSERT of the referenced table.
+func (g *Graph) OrderStatements(stmts []ast.StmtNode, currentDB string) ([]int, error) {
+	deps := make(map[Table]map[Table]bool)
+	addDep := func(child, parent Table) {
+		if deps[child] == nil {
+			deps[child] = make(map[Table]bool)
+		}
+		deps[child][parent] = true
+	}
+	for child, edges := range g.parents {
+		for _, e := range edges {
+			addDep(child, e.Parent.key())
+		}
+	}
+	infos := make([]*stmtInfo, 0, len(stmts))
+	db, segment := currentDB, 0
+	for _, stmt := range stmts {
+		if use, ok := stmt.(*ast.UseStmt); ok {
+			db = use.DBName
+		}
+		info := analyzeStmt(stmt, db)
+		if info.kind == kindOther {
+			segment++
+			info.segment = segment
+			segment++
+		} else {
+			info.segment = segment
+		}
+		infos = append(infos, info)
+		if create, ok := stmt.(*ast.CreateTableStmt); ok {
+			for _, c := range create.Constraints {
+				if c.Tp == ast.ConstraintForeignKey && c.Refer != nil {
+					addDep(info.targets[0].key(), tableOf(c.Refer.Table, info.targets[0].Schema).key())
+				}
+			}
+		}
+	}
+
+	// before[i] are the statements which run before the statement i.
+	before := make([]map[int]bool, len(stmts))
+	for i := range before {
+		before[i] = make(map[int]bool)
+	}
+	for i := range infos {
+		for j := i + 1; j < len(infos); j++ {
+			if infos[i].segment != infos[j].segment {
+				before[j][i] = true
+				continue
+			}
+			switch precedes(infos[i], infos[j], deps) {
+			case 1:
+				before[j][i] = true
+			case -1:
+				before[i][j] = true
+			}
+		}
+	}
+	res := make([]int, 0, len(stmts))
+	done := make([]bool, len(stmts))
+	for len(res) < len(stmts) {
+		next := -1
+		for i := range stmts {
+			if !done[i] && allBefore(before[i], done) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var rest []int
+			for i := range stmts {
+				if !done[i] {
+					rest = append(rest, i)
+				}
+			}
+			return nil, errors.Errorf("statements %v can not be ordered by the foreign keys", rest)
+		}
+		done[next] = true
+		res = append(res, next)
+	}
+	return res, nil
+}
+
+// allBefore returns whether all the statements are done.
+func allBefore(stmts map[int]bool, done []bool) bool {
+	for i := range stmts {
+		if !done[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// precedes returns 1 if the statement a must run before b, which is after a
+// in the script, -1 if b must run before a, and 0 if they can run in any
+// order.
+func precedes(a, b *stmtInfo, deps map[Table]map[Table]bool) int {
+	for t := range a.tables {
+		if b.tables[t] {
+			return 1
+		}
+	}
+	var aParent, aChild bool
+	for _, x := range a.targets {
+		for _, y := range b.targets {
+			aParent = aParent || deps[y.key()][x.key()]
+			aChild = aChild || deps[x.key()][y.key()]
+		}
+	}
+	switch {
+	case !aParent && !aChild:
+		return 0
+	case a.kind != b.kind || aParent && aChild:
+		return 1
+	case a.kind == kindCreate || a.kind == kindInsert:
+		if aParent {
+			return 1
+		}
+		return -1
+	}
+	// DROP TABLE and DELETE.
+	if aChild {
+		return 1
+	}
+	return -1
+}
+
+// analyzeStmt returns the stmtInfo of a statement, db is the current database.
+func analyzeStmt(stmt ast.StmtNode, db string) *stmtInfo {
+	info := &stmtInfo{tables: make(map[Table]bool)}
+	switch x := stmt.(type) {
+	case *ast.CreateTableStmt:
+		info.kind = kindCreate
+		info.targets = []Table{tableOf(x.Table, db)}
+	case *ast.DropTableStmt:
+		info.kind = kindDrop
+		for _, tn := range x.Tables {
+			info.targets = append(info.targets, tableOf(tn, db))
+		}
+	case *ast.InsertStmt:
+		if tn := firstTable(x.Table); tn != nil {
+			info.kind = kindInsert
+			info.targets = []Table{tableOf(tn, db)}
+		}
+	case *ast.DeleteStmt:
+		info.kind = kindDelete
+		info.targets = deleteTargets(x, db)
+	case *ast.TruncateTableStmt:
+		info.kind = kindDelete
+		info.targets = []Table{tableOf(x.Table, db)}
+	}
+	if info.kind != kindOther && len(info.targets) == 0 {
+		info.kind = kindOther
+	}
+	for _, t := range info.targets {
+		info.tables[t.key()] = true
+	}
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.ReferenceDef:
+			return false
+		case *ast.TableName:
+			info.tables[tableOf(x, db).key()] = true
+		}
+		return true
+	})
+	return info
+}
+
+// tableOf returns the table of a name, db is the current database.
+func tableOf(tn *ast.TableName, db string) Table {
+	if tn.Schema.O != "" {
+		db = tn.Schema.O
+	}
+	return Table{Schema: db, Name: tn.Name.O}
+}
+
+// firstTable returns the first table of a join.
+func firstTable(refs *ast.TableRefsClause) *ast.TableName {
+	if refs == nil || refs.TableRefs == nil {
+		return nil
+	}
+	var node ast.ResultSetNode = refs.TableRefs
+	for {
+		switch x := node.(type) {
+		case *ast.Join:
+			node = x.Left
+		case *ast.TableSource:
+			tn, _ := x.Source.(*ast.TableName)
+			return tn
+		default:
+			return nil
+		}
+	}
+}
+
+// deleteTargets returns the tables a DELETE deletes from, the aliases of the
+// multiple-table syntax are resolved.
+func deleteTargets(stmt *ast.DeleteStmt, db string) []Table {
+	if !stmt.IsMultiTable {
+		if tn := firstTable(stmt.TableRefs); tn != nil {
+			return []Table{tableOf(tn, db)}
+		}
+		return nil
+	}
+	var sources []*ast.TableSource
+	if stmt.TableRefs != nil {
+		sources = ast.FindAll[*ast.TableSource](stmt.TableRefs)
+	}
+	var res []Table
+	for _, tn := range stmt.Tables.Tables {
+		t := tableOf(tn, db)
+		for _, src := range sources {
+			if source, ok := src.Source.(*ast.TableName); ok && tn.Schema.L == "" && src.AsName.L == tn.Name.L {
+				t = tableOf(source, db)
+				break
+			}
+		}
+		res = append(res, t)
+	}
+	return res
+}
@@ -225,6 +225,8 @@ const (
 
 	RestoreTiDBSpecialComment
 	SkipPlacementRuleForRestore
+
+	RestorePrettyFormat
 )
 
 const (
@@ -311,12 +313,38 @@ func (rf RestoreFlags) HasSkipPlacementRuleForRestoreFlag() bool {
 	return rf.has(SkipPlacementRuleForRestore)
 }
 
+// HasPrettyFormatFlag returns a boolean indicating whether `rf` has `RestorePrettyFormat` flag.
+func (rf RestoreFlags) HasPrettyFormatFlag() bool {
+	return rf.has(RestorePrettyFormat)
+}
+
+// PrettyConfig configures the output of `RestorePrettyFormat`.
+type PrettyConfig struct {
+	// Indent is the string of one indent level, 2 spaces are used if it's empty.
+	Indent string
+	// MaxWidth is the width a list or a block is written in one line within,
+	// 80 is used if it's 0 and there is no limit if it's negative.
+	MaxWidth int
+	// LeadingComma writes the commas at the beginning of the lines instead of
+	// the end when a list is split into lines.
+	LeadingComma bool
+}
+
 // RestoreCtx is `Restore` context to hold flags and writer.
 type RestoreCtx struct {
 	Flags     RestoreFlags
 	In        io.Writer
 	DefaultDB string
 	CTERestorer
+	// Pretty is used when `RestorePrettyFormat` is set.
+	Pretty PrettyConfig
+
+	indentLevel int
+	// column is the width of the current line in pretty format.
+	column int
+	// oneLine writes the pretty format in one line, it's used to measure the
+	// width of a list or a block.
+	oneLine bool
 }
 
 // NewRestoreCtx returns a new `RestoreCtx`.
@@ -333,7 +361,7 @@ func (ctx *RestoreCtx) WriteKeyWord(keyWord string) {
 	case ctx.Flags.HasKeyWordLowercaseFlag():
 		keyWord = strings.ToLower(keyWord)
 	}
-	fmt.Fprint(ctx.In, keyWord)
+	ctx.write(keyWord)
 }
 
 // WriteWithSpecialComments writes a string with a special comment wrapped.
@@ -368,7 +396,7 @@ func (ctx *RestoreCtx) WriteString(str string) {
 		str = strings.Replace(str, `"`, `""`, -1)
 		quotes = `"`
 	}
-	fmt.Fprint(ctx.In, quotes, str, quotes)
+	ctx.write(quotes + str + quotes)
 }
 
 // WriteName writes the name into writer
@@ -389,17 +417,156 @@ func (ctx *RestoreCtx) WriteName(name string) {
 		name = strings.Replace(name, "`", "``", -1)
 		quotes = "`"
 	}
-	fmt.Fprint(ctx.In, quotes, name, quotes)
+	ctx.write(quotes + name + quotes)
 }
 
 // WritePlain writes the plain text into writer without any handling.
 func (ctx *RestoreCtx) WritePlain(plainText string) {
-	fmt.Fprint(ctx.In, plainText)
+	ctx.write(plainText)
 }
 
 // WritePlainf write the plain text into writer without any handling.
 func (ctx *RestoreCtx) WritePlainf(format string, a ...interface{}) {
-	fmt.Fprintf(ctx.In, format, a...)
+	ctx.write(fmt.Sprintf(format, a...))
+}
+
+func (ctx *RestoreCtx) write(s string) {
+	fmt.Fprint(ctx.In, s)
+	if !ctx.Flags.HasPrettyFormatFlag() {
+		return
+	}
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		ctx.column = len(s) - i - 1
+	} else {
+		ctx.column += len(s)
+	}
+}
+
+func (ctx *RestoreCtx) pretty() bool {
+	return ctx.Flags.HasPrettyFormatFlag() && !ctx.oneLine
+}
+
+// IncIndent increases the indent level of the lines started by WriteBreak.
+func (ctx *RestoreCtx) IncIndent() {
+	ctx.indentLevel++
+}
+
+// DecIndent decreases the indent level of the lines started by WriteBreak.
+func (ctx *RestoreCtx) DecIndent() {
+	ctx.indentLevel--
+}
+
+// WriteBreak starts a new line with the current indent in pretty format, or
+// writes `flat` otherwise.
+func (ctx *RestoreCtx) WriteBreak(flat string) {
+	if !ctx.pretty() {
+		ctx.WritePlain(flat)
+		return
+	}
+	indent := ctx.Pretty.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	ctx.write("\n" + strings.Repeat(indent, ctx.indentLevel))
+}
+
+// WriteLines writes n items restored by `restore`, the items are separated by
+// `sep`. Every item is written in a new line in pretty format.
+func (ctx *RestoreCtx) WriteLines(sep string, n int, restore func(ctx *RestoreCtx, i int) error) error {
+	pretty := ctx.pretty()
+	for i := 0; i < n; i++ {
+		switch {
+		case !pretty:
+			if i > 0 {
+				ctx.WritePlain(sep)
+			}
+		case ctx.Pretty.LeadingComma:
+			// The first item is aligned with the items after the commas.
+			ctx.WriteBreak("")
+			if i > 0 {
+				ctx.WritePlain(", ")
+			} else {
+				ctx.WritePlain("  ")
+			}
+		default:
+			if i > 0 {
+				ctx.WritePlain(",")
+			}
+			ctx.WriteBreak("")
+		}
+		if err := restore(ctx, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteList writes n items restored by `restore` after a keyword. The items are
+// written after `prefix` and separated by `sep`. In pretty format they are
+// separated by ", " if they fit in the line, or written in the following lines
+// with one more indent otherwise.
+func (ctx *RestoreCtx) WriteList(prefix, sep string, n int, restore func(ctx *RestoreCtx, i int) error) error {
+	if !ctx.pretty() {
+		ctx.WritePlain(prefix)
+		return ctx.WriteLines(sep, n, restore)
+	}
+	line, err := ctx.measure(func(lctx *RestoreCtx) error {
+		lctx.WritePlain(prefix)
+		return lctx.WriteLines(", ", n, restore)
+	})
+	if err != nil {
+		return err
+	}
+	if ctx.fits(line, 0) {
+		ctx.WritePlain(line)
+		return nil
+	}
+	ctx.IncIndent()
+	err = ctx.WriteLines(sep, n, restore)
+	ctx.DecIndent()
+	return err
+}
+
+// WriteBlock writes the content restored by `restore`, like a subquery in the
+// parentheses. In pretty format, the content is written in the following lines
+// with one more indent if it doesn't fit in the line, and `closing` is the
+// width of what follows the block in the line.
+func (ctx *RestoreCtx) WriteBlock(closing int, restore func(ctx *RestoreCtx) error) error {
+	if !ctx.pretty() {
+		return restore(ctx)
+	}
+	line, err := ctx.measure(restore)
+	if err != nil {
+		return err
+	}
+	if ctx.fits(line, closing) {
+		ctx.WritePlain(line)
+		return nil
+	}
+	ctx.IncIndent()
+	ctx.WriteBreak("")
+	err = restore(ctx)
+	ctx.DecIndent()
+	ctx.WriteBreak("")
+	return err
+}
+
+// measure restores the content in one line.
+func (ctx *RestoreCtx) measure(restore func(ctx *RestoreCtx) error) (string, error) {
+	var sb strings.Builder
+	lctx := *ctx
+	lctx.In = &sb
+	lctx.oneLine = true
+	err := restore(&lctx)
+	return sb.String(), err
+}
+
+func (ctx *RestoreCtx) fits(line string, closing int) bool {
+	width := ctx.Pretty.MaxWidth
+	if width == 0 {
+		width = 80
+	}
+	return width < 0 || ctx.column+len(line)+closing <= width
 }
 
 // CTERestorer is used by WithClause related nodes restore.
@@ -107,3 +107,61 @@ func TestRestoreSpecialComment(t *testing.T) {
 	})
 	require.Same(t, err, got)
 }
+
+func TestRestorePretty(t *testing.T) {
+	items := []string{"aaaa", "bbbb", "cccc"}
+	restoreItem := func(ctx *RestoreCtx, i int) error {
+		ctx.WriteName(items[i])
+		return nil
+	}
+	restore := func(ctx *RestoreCtx) error {
+		ctx.WriteKeyWord("select")
+		if err := ctx.WriteList(" ", ",", len(items), restoreItem); err != nil {
+			return err
+		}
+		ctx.WriteBreak(" ")
+		ctx.WriteKeyWord("where ")
+		ctx.WritePlain("x in (")
+		if err := ctx.WriteBlock(1, func(ctx *RestoreCtx) error {
+			ctx.WriteKeyWord("select")
+			ctx.WriteBreak(" ")
+			ctx.WritePlain("1")
+			return nil
+		}); err != nil {
+			return err
+		}
+		ctx.WritePlain(")")
+		return nil
+	}
+
+	testCases := []struct {
+		pretty PrettyConfig
+		expect string
+	}{
+		{PrettyConfig{}, "SELECT aaaa, bbbb, cccc\nWHERE x in (SELECT 1)"},
+		{PrettyConfig{MaxWidth: 20}, "SELECT\n  aaaa,\n  bbbb,\n  cccc\nWHERE x in (\n  SELECT\n  1\n)"},
+		{PrettyConfig{MaxWidth: 20, Indent: "\t", LeadingComma: true}, "SELECT\n\t  aaaa\n\t, bbbb\n\t, cccc\nWHERE x in (\n\tSELECT\n\t1\n)"},
+		{PrettyConfig{MaxWidth: -1}, "SELECT aaaa, bbbb, cccc\nWHERE x in (SELECT 1)"},
+	}
+	var sb strings.Builder
+	ctx := NewRestoreCtx(RestoreKeyWordUppercase, &sb)
+	require.NoError(t, restore(ctx))
+	require.Equal(t, "SELECT aaaa,bbbb,cccc WHERE x in (SELECT 1)", sb.String())
+	for _, testCase := range testCases {
+		sb.Reset()
+		ctx := NewRestoreCtx(RestoreKeyWordUppercase|RestorePrettyFormat, &sb)
+		ctx.Pretty = testCase.pretty
+		require.NoError(t, restore(ctx))
+		require.Equalf(t, testCase.expect, sb.String(), "case: %#v", testCase.pretty)
+	}
+
+	sb.Reset()
+	ctx = NewRestoreCtx(RestorePrettyFormat, &sb)
+	ctx.WritePlain("(")
+	ctx.IncIndent()
+	require.NoError(t, ctx.WriteLines(",", len(items), restoreItem))
+	ctx.DecIndent()
+	ctx.WriteBreak("")
+	ctx.WritePlain(")")
+	require.Equal(t, "(\n  aaaa,\n  bbbb,\n  cccc\n)", sb.String())
+}
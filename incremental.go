@@ -0,0 +1,219 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	gocontext "context"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/pingcap/errors"
+)
+
+// ParsedStmt is a statement of a ParseResult with its span in the SQL text.
+type ParsedStmt struct {
+	Stmt ast.StmtNode
+	// Start is the position right after the ';' of the previous statement, or
+	// the start of the text. End is the position right after the ';' of the
+	// statement, or the end of the text.
+	Start Pos
+	End   Pos
+	// Warns are the warnings of parsing the statement.
+	Warns []error
+}
+
+// ParseResult is the result of ParseIncremental and Reparse.
+type ParseResult struct {
+	SQL   string
+	Stmts []ParsedStmt
+}
+
+// StmtNodes returns the parsed statements.
+func (r *ParseResult) StmtNodes() []ast.StmtNode {
+	stmts := make([]ast.StmtNode, 0, len(r.Stmts))
+	for _, s := range r.Stmts {
+		stmts = append(stmts, s.Stmt)
+	}
+	return stmts
+}
+
+// TextEdit is a change of the SQL text: RemovedLen bytes at Offset are
+// replaced by Inserted.
+type TextEdit struct {
+	Offset     int
+	RemovedLen int
+	Inserted   string
+}
+
+// Apply returns the text after the edit.
+func (e TextEdit) Apply(text string) (string, error) {
+	if e.Offset < 0 || e.RemovedLen < 0 || e.Offset+e.RemovedLen > len(text) {
+		return "", errors.Errorf("edit [%d, %d) out of range [0, %d)", e.Offset, e.Offset+e.RemovedLen, len(text))
+	}
+	return text[:e.Offset] + e.Inserted + text[e.Offset+e.RemovedLen:], nil
+}
+
+// ParseIncremental parses sql like ParseSQL, but keeps the span of every
+// statement, so the result can be updated by Reparse after the text is edited.
+func (parser *Parser) ParseIncremental(sql string, params ...ParseParam) (*ParseResult, error) {
+	res := &ParseResult{SQL: sql}
+	stmts, _, _, err := parser.parseSegments(sql, Pos{Line: 1}, nil, params)
+	if err != nil {
+		return nil, err
+	}
+	res.Stmts = stmts
+	return res, nil
+}
+
+// Reparse returns the parse result of the text after edit is applied to prev.
+// Only the statements whose span overlaps the edit are parsed again, the ASTs of
+// the others are moved into the new result with their offsets adjusted, so prev
+// must not be used after a successful call.
+func (parser *Parser) Reparse(prev *ParseResult, edit TextEdit, params ...ParseParam) (*ParseResult, error) {
+	sql, err := edit.Apply(prev.SQL)
+	if err != nil {
+		return nil, err
+	}
+	editEnd := edit.Offset + edit.RemovedLen
+	delta := len(edit.Inserted) - edit.RemovedLen
+
+	// Statements ending before the edit are not changed.
+	keep := 0
+	start := Pos{Line: 1}
+	for keep < len(prev.Stmts) && prev.Stmts[keep].End.Offset < edit.Offset {
+		start = prev.Stmts[keep].End
+		keep++
+	}
+	// Statements starting after the edit are reused once the scanning meets
+	// the start of one of them again.
+	next := keep
+	for next < len(prev.Stmts) && prev.Stmts[next].Start.Offset < editEnd {
+		next++
+	}
+	resync := func(pos Pos) int {
+		for i := next; i < len(prev.Stmts); i++ {
+			if off := prev.Stmts[i].Start.Offset + delta; off == pos.Offset {
+				return i
+			} else if off > pos.Offset {
+				break
+			}
+		}
+		return -1
+	}
+
+	stmts, stop, newStart, err := parser.parseSegments(sql, start, resync, params)
+	if err != nil {
+		return nil, err
+	}
+	res := &ParseResult{SQL: sql}
+	res.Stmts = append(res.Stmts, prev.Stmts[:keep]...)
+	res.Stmts = append(res.Stmts, stmts...)
+	if stop >= 0 {
+		oldStart := prev.Stmts[stop].Start
+		for _, s := range prev.Stmts[stop:] {
+			s.Start = shiftPos(s.Start, oldStart, newStart)
+			s.End = shiftPos(s.End, oldStart, newStart)
+			if delta != 0 {
+				s.Stmt.Accept(&offsetShifter{delta: delta})
+			}
+			res.Stmts = append(res.Stmts, s)
+		}
+	}
+	return res, nil
+}
+
+// shiftPos moves pos, which is after from, by the distance between from and to.
+func shiftPos(pos, from, to Pos) Pos {
+	if pos.Line == from.Line {
+		pos.Col += to.Col - from.Col
+	}
+	pos.Line += to.Line - from.Line
+	pos.Offset += to.Offset - from.Offset
+	return pos
+}
+
+// parseSegments splits sql from start into statements by ';' and parses them
+// one by one. If resync returns a non-negative value for the start of a
+// statement, the parsing stops and the value and the position are returned,
+// otherwise -1 is returned after all the text is parsed.
+func (parser *Parser) parseSegments(sql string, start Pos, resync func(Pos) int, params []ParseParam) ([]ParsedStmt, int, Pos, error) {
+	s := NewScanner(sql)
+	s.SetSQLMode(parser.lexer.GetSQLMode())
+	s.EnableWindowFunc(parser.lexer.supportWindowFunc)
+	s.r.updatePos(start)
+
+	var res []ParsedStmt
+	for !s.r.eof() {
+		if resync != nil {
+			if i := resync(s.r.pos()); i >= 0 {
+				return res, i, s.r.pos(), nil
+			}
+		}
+		segStart := s.r.pos()
+		empty := true
+		for {
+			before := s.r.pos().Offset
+			tok, _, _ := s.scan()
+			if tok == 0 || tok == ';' {
+				break
+			}
+			empty = false
+			if s.r.pos().Offset == before {
+				// An illegal character, skip it and let the parser report it.
+				s.r.inc()
+			}
+		}
+		segEnd := s.r.pos()
+		if empty {
+			continue
+		}
+		stmts, warns, err := parser.parse(gocontext.Background(), sql[:segEnd.Offset], segStart, params)
+		if err != nil {
+			return nil, -1, Pos{}, err
+		}
+		for _, stmt := range stmts {
+			res = append(res, ParsedStmt{Stmt: stmt, Start: segStart, End: segEnd, Warns: warns})
+		}
+	}
+	return res, -1, s.r.pos(), nil
+}
+
+// offsetShifter moves the offsets of the nodes by delta.
+type offsetShifter struct {
+	delta int
+}
+
+// Enter implements ast.Visitor interface.
+func (v *offsetShifter) Enter(n ast.Node) (node ast.Node, skipChildren bool) {
+	// Offset 0 means the position of the node is not recorded, a node of a
+	// statement after the edit can't be at the start of the text.
+	if off := n.OriginTextPosition(); off > 0 {
+		n.SetOriginTextPosition(off + v.delta)
+	}
+	switch x := n.(type) {
+	case *ast.SelectField:
+		if x.Offset > 0 {
+			x.Offset += v.delta
+		}
+	case ast.ParamMarkerOffsetter:
+		if off := x.GetOffset(); off > 0 {
+			x.SetOffset(off + v.delta)
+		}
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (v *offsetShifter) Leave(n ast.Node) (node ast.Node, ok bool) {
+	return n, true
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/stretchr/testify/require"
+)
+
+type offsetCollector struct {
+	offsets []int
+}
+
+func (c *offsetCollector) Enter(n ast.Node) (ast.Node, bool) {
+	c.offsets = append(c.offsets, n.OriginTextPosition())
+	switch x := n.(type) {
+	case *ast.SelectField:
+		c.offsets = append(c.offsets, x.Offset)
+	case ast.ParamMarkerOffsetter:
+		c.offsets = append(c.offsets, x.GetOffset())
+	}
+	return n, false
+}
+
+func (c *offsetCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+func describeStmts(t *testing.T, stmts []ast.StmtNode, withText bool) []string {
+	var res []string
+	for _, stmt := range stmts {
+		var sb strings.Builder
+		require.NoError(t, stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)))
+		c := &offsetCollector{}
+		stmt.Accept(c)
+		res = append(res, sb.String())
+		if withText {
+			res = append(res, stmt.Text())
+		}
+		for _, off := range c.offsets {
+			res = append(res, strconv.Itoa(off))
+		}
+	}
+	return res
+}
+
+func TestReparse(t *testing.T) {
+	p := parser.New()
+	sql := "select a, b from t where c = 1;\nupdate t set a = 2 where b in (1, 2);\n\n  select x.y from x;;delete from t where id = 3"
+	res, err := p.ParseIncremental(sql)
+	require.NoError(t, err)
+	require.Len(t, res.Stmts, 4)
+	require.Equal(t, 0, res.Stmts[0].Start.Offset)
+	require.Equal(t, 31, res.Stmts[0].End.Offset)
+	require.Equal(t, 31, res.Stmts[1].Start.Offset)
+	require.Equal(t, 2, res.Stmts[1].End.Line)
+
+	edits := []parser.TextEdit{
+		{Offset: 7, RemovedLen: 1, Inserted: "aa"},              // edit the first statement
+		{Offset: 0, Inserted: "select 0; "},                     // insert a statement
+		{Offset: 40, RemovedLen: 1, Inserted: "abc\n"},          // edit the second statement
+		{Offset: 31, Inserted: "\nselect 'x;y';"},               // insert between statements
+		{Offset: 12, RemovedLen: 6},                             // remove part of a statement
+		{Offset: len(sql) - 1, RemovedLen: 1, Inserted: "4, 5"}, // edit the last statement
+		{Offset: 30, RemovedLen: 1},                             // join two statements
+		{Offset: 0, Inserted: "-- comment;\n"},                  // comment
+		{Offset: 80, RemovedLen: 0, Inserted: "/* a; b */"},     // comment in a statement
+	}
+	for _, edit := range edits {
+		text, err := edit.Apply(sql)
+		require.NoError(t, err)
+		prev, err := p.ParseIncremental(sql)
+		require.NoError(t, err)
+		expected, err := p.ParseIncremental(text)
+		if err != nil {
+			_, err = p.Reparse(prev, edit)
+			require.Error(t, err, text)
+			continue
+		}
+		res, err := p.Reparse(prev, edit)
+		require.NoError(t, err, text)
+		require.Equal(t, text, res.SQL)
+		require.Equal(t, len(expected.Stmts), len(res.Stmts), text)
+		for i := range expected.Stmts {
+			require.Equal(t, expected.Stmts[i].Start, res.Stmts[i].Start, text)
+			require.Equal(t, expected.Stmts[i].End, res.Stmts[i].End, text)
+		}
+		require.Equal(t, describeStmts(t, expected.StmtNodes(), true), describeStmts(t, res.StmtNodes(), true), text)
+
+		stmts, _, err := p.ParseSQL(text)
+		require.NoError(t, err)
+		// The text of a statement does not include the empty statements before it.
+		require.Equal(t, describeStmts(t, stmts, false), describeStmts(t, res.StmtNodes(), false), text)
+	}
+}
+
+func TestReparseReuse(t *testing.T) {
+	p := parser.New()
+	sql := "select 1; select 2; select 3"
+	prev, err := p.ParseIncremental(sql)
+	require.NoError(t, err)
+	first, last := prev.Stmts[0].Stmt, prev.Stmts[2].Stmt
+
+	res, err := p.Reparse(prev, parser.TextEdit{Offset: 17, RemovedLen: 1, Inserted: "22"})
+	require.NoError(t, err)
+	require.Len(t, res.Stmts, 3)
+	require.Same(t, first, res.Stmts[0].Stmt)
+	require.Same(t, last, res.Stmts[2].Stmt)
+	require.Equal(t, "22", res.Stmts[1].Stmt.(*ast.SelectStmt).Fields.Fields[0].Text())
+
+	// A string which is not closed swallows the following statements.
+	prev = res
+	res, err = p.Reparse(prev, parser.TextEdit{Offset: 17, Inserted: "'"})
+	require.Error(t, err)
+	require.Nil(t, res)
+
+	_, err = p.Reparse(prev, parser.TextEdit{Offset: 100})
+	require.Error(t, err)
+
+	// The offsets of the param markers of the reused statements are moved.
+	edit := parser.TextEdit{Offset: 7, RemovedLen: 1, Inserted: "12345"}
+	sql = "select 1; select * from t where a = ?"
+	prev, err = p.ParseIncremental(sql)
+	require.NoError(t, err)
+	res, err = p.Reparse(prev, edit)
+	require.NoError(t, err)
+	require.Same(t, prev.Stmts[1].Stmt, res.Stmts[1].Stmt)
+	text, err := edit.Apply(sql)
+	require.NoError(t, err)
+	stmts, _, err := p.ParseSQL(text)
+	require.NoError(t, err)
+	require.Equal(t, describeStmts(t, stmts, false), describeStmts(t, res.StmtNodes(), false))
+	marker := res.Stmts[1].Stmt.(*ast.SelectStmt).Where.(*ast.BinaryOperationExpr).R
+	require.Equal(t, 40, marker.(ast.ParamMarkerOffsetter).GetOffset())
+}
@@ -0,0 +1,201 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Keywordgen generates the set of the reserved keywords from the tokens of
+// ReservedKeyword in parser.y and the keyword tables of misc.go.
+//
+// Usage, in the parser directory:
+//
+//	go run ./internal/keywordgen -o keywords_generated.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	reservedKeywordStartMarker   = "\t/* The following tokens belong to ReservedKeyword. Notice: make sure these tokens are contained in ReservedKeyword. */"
+	unreservedKeywordStartMarker = "\t/* The following tokens belong to UnReservedKeyword. Notice: make sure these tokens are contained in UnReservedKeyword. */"
+)
+
+// keywordTables are the maps of misc.go from the keywords to their tokens, the
+// keywords mapped to a reserved token are reserved too, like the aliases.
+var keywordTables = []string{"tokenMap", "windowFuncTokenMap"}
+
+func main() {
+	out := flag.String("o", "keywords_generated.go", "output file")
+	dir := flag.String("dir", ".", "directory of the parser package")
+	flag.Parse()
+
+	grammar, err := os.ReadFile(filepath.Join(*dir, "parser.y"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokens, err := reservedTokens(string(grammar))
+	if err != nil {
+		log.Fatal(err)
+	}
+	keywords := make(map[string]struct{})
+	for _, kw := range tokens {
+		keywords[kw] = struct{}{}
+	}
+	tables, err := readKeywordTables(filepath.Join(*dir, "misc.go"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for kw, tok := range tables {
+		if _, ok := tokens[tok]; ok {
+			keywords[kw] = struct{}{}
+		}
+	}
+	src, err := generate(keywords)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// reservedTokens returns the keywords of the tokens declared in the
+// ReservedKeyword section of the grammar, keyed by the token names.
+func reservedTokens(grammar string) (map[string]string, error) {
+	start := strings.Index(grammar, reservedKeywordStartMarker)
+	if start == -1 {
+		return nil, fmt.Errorf("the start of the reserved keywords is not found")
+	}
+	section := grammar[start+len(reservedKeywordStartMarker):]
+	end := strings.Index(section, unreservedKeywordStartMarker)
+	if end == -1 {
+		return nil, fmt.Errorf("the end of the reserved keywords is not found")
+	}
+	tokens := make(map[string]string)
+	for _, line := range strings.Split(section[:end], "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		kw, err := strconv.Unquote(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid token declaration %q", line)
+		}
+		tokens[fields[0]] = kw
+	}
+	return tokens, nil
+}
+
+// readKeywordTables returns the keywords of keywordTables in the file, mapped
+// to the names of their tokens.
+func readKeywordTables(path string) (map[string]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]string)
+	found := 0
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) != 1 || len(vs.Values) != 1 || !isKeywordTable(vs.Names[0].Name) {
+				continue
+			}
+			lit, ok := vs.Values[0].(*ast.CompositeLit)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a map literal", vs.Names[0].Name)
+			}
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, ok := kv.Key.(*ast.BasicLit)
+				val, ok2 := kv.Value.(*ast.Ident)
+				if !ok || !ok2 || key.Kind != token.STRING {
+					continue
+				}
+				kw, err := strconv.Unquote(key.Value)
+				if err != nil {
+					return nil, err
+				}
+				tables[kw] = val.Name
+			}
+			found++
+		}
+	}
+	if found != len(keywordTables) {
+		return nil, fmt.Errorf("the keyword tables %v are not found in %s", keywordTables, path)
+	}
+	return tables, nil
+}
+
+func isKeywordTable(name string) bool {
+	for _, table := range keywordTables {
+		if table == name {
+			return true
+		}
+	}
+	return false
+}
+
+func generate(keywords map[string]struct{}) ([]byte, error) {
+	sorted := make([]string, 0, len(keywords))
+	for kw := range keywords {
+		sorted = append(sorted, kw)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	buf.WriteString(`// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by keywordgen. DO NOT EDIT.
+
+package parser
+
+// reservedKeywords are the keywords of ReservedKeyword in parser.y and their
+// aliases, including the window function names which are keywords only if the
+// window functions are enabled.
+var reservedKeywords = map[string]struct{}{
+`)
+	for _, kw := range sorted {
+		fmt.Fprintf(&buf, "\t%q: {},\n", kw)
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,239 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by keywordgen. DO NOT EDIT.
+
+package parser
+
+// reservedKeywords are the keywords of ReservedKeyword in parser.y and their
+// aliases, including the window function names which are keywords only if the
+// window functions are enabled.
+var reservedKeywords = map[string]struct{}{
+	"ADD":                 {},
+	"ALL":                 {},
+	"ALTER":               {},
+	"ANALYZE":             {},
+	"AND":                 {},
+	"AS":                  {},
+	"ASC":                 {},
+	"BETWEEN":             {},
+	"BIGINT":              {},
+	"BINARY":              {},
+	"BLOB":                {},
+	"BOTH":                {},
+	"BY":                  {},
+	"CALL":                {},
+	"CASCADE":             {},
+	"CASE":                {},
+	"CHANGE":              {},
+	"CHAR":                {},
+	"CHARACTER":           {},
+	"CHECK":               {},
+	"COLLATE":             {},
+	"COLUMN":              {},
+	"CONSTRAINT":          {},
+	"CONVERT":             {},
+	"CREATE":              {},
+	"CROSS":               {},
+	"CUME_DIST":           {},
+	"CURRENT_DATE":        {},
+	"CURRENT_ROLE":        {},
+	"CURRENT_TIME":        {},
+	"CURRENT_TIMESTAMP":   {},
+	"CURRENT_USER":        {},
+	"DATABASE":            {},
+	"DATABASES":           {},
+	"DAY_HOUR":            {},
+	"DAY_MICROSECOND":     {},
+	"DAY_MINUTE":          {},
+	"DAY_SECOND":          {},
+	"DEC":                 {},
+	"DECIMAL":             {},
+	"DEFAULT":             {},
+	"DELAYED":             {},
+	"DELETE":              {},
+	"DENSE_RANK":          {},
+	"DESC":                {},
+	"DESCRIBE":            {},
+	"DISTINCT":            {},
+	"DISTINCTROW":         {},
+	"DIV":                 {},
+	"DOUBLE":              {},
+	"DROP":                {},
+	"DUAL":                {},
+	"ELSE":                {},
+	"ENCLOSED":            {},
+	"ESCAPED":             {},
+	"EXCEPT":              {},
+	"EXISTS":              {},
+	"EXPLAIN":             {},
+	"FALSE":               {},
+	"FETCH":               {},
+	"FIRST_VALUE":         {},
+	"FLOAT":               {},
+	"FOR":                 {},
+	"FORCE":               {},
+	"FOREIGN":             {},
+	"FROM":                {},
+	"FULLTEXT":            {},
+	"GENERATED":           {},
+	"GRANT":               {},
+	"GROUP":               {},
+	"GROUPS":              {},
+	"HAVING":              {},
+	"HIGH_PRIORITY":       {},
+	"HOUR_MICROSECOND":    {},
+	"HOUR_MINUTE":         {},
+	"HOUR_SECOND":         {},
+	"IF":                  {},
+	"IGNORE":              {},
+	"IN":                  {},
+	"INDEX":               {},
+	"INFILE":              {},
+	"INNER":               {},
+	"INSERT":              {},
+	"INT":                 {},
+	"INT1":                {},
+	"INT2":                {},
+	"INT3":                {},
+	"INT4":                {},
+	"INT8":                {},
+	"INTEGER":             {},
+	"INTERSECT":           {},
+	"INTERVAL":            {},
+	"INTO":                {},
+	"IS":                  {},
+	"JOIN":                {},
+	"KEY":                 {},
+	"KEYS":                {},
+	"KILL":                {},
+	"LAG":                 {},
+	"LAST_VALUE":          {},
+	"LEAD":                {},
+	"LEADING":             {},
+	"LEFT":                {},
+	"LIKE":                {},
+	"LIMIT":               {},
+	"LINEAR":              {},
+	"LINES":               {},
+	"LOAD":                {},
+	"LOCALTIME":           {},
+	"LOCALTIMESTAMP":      {},
+	"LOCK":                {},
+	"LONG":                {},
+	"LONGBLOB":            {},
+	"LONGTEXT":            {},
+	"LOW_PRIORITY":        {},
+	"MATCH":               {},
+	"MAXVALUE":            {},
+	"MEDIUMBLOB":          {},
+	"MEDIUMINT":           {},
+	"MEDIUMTEXT":          {},
+	"MINUTE_MICROSECOND":  {},
+	"MINUTE_SECOND":       {},
+	"MOD":                 {},
+	"NATURAL":             {},
+	"NOT":                 {},
+	"NO_WRITE_TO_BINLOG":  {},
+	"NTH_VALUE":           {},
+	"NTILE":               {},
+	"NULL":                {},
+	"NUMERIC":             {},
+	"OF":                  {},
+	"ON":                  {},
+	"OPTIMIZE":            {},
+	"OPTION":              {},
+	"OPTIONALLY":          {},
+	"OR":                  {},
+	"ORDER":               {},
+	"OUTER":               {},
+	"OUTFILE":             {},
+	"OVER":                {},
+	"PARTITION":           {},
+	"PERCENT_RANK":        {},
+	"PRECISION":           {},
+	"PRIMARY":             {},
+	"PROCEDURE":           {},
+	"RANGE":               {},
+	"RANK":                {},
+	"READ":                {},
+	"REAL":                {},
+	"RECURSIVE":           {},
+	"REFERENCES":          {},
+	"REGEXP":              {},
+	"RELEASE":             {},
+	"RENAME":              {},
+	"REPEAT":              {},
+	"REPLACE":             {},
+	"REQUIRE":             {},
+	"RESTRICT":            {},
+	"REVOKE":              {},
+	"RIGHT":               {},
+	"RLIKE":               {},
+	"ROW":                 {},
+	"ROWS":                {},
+	"ROW_NUMBER":          {},
+	"SCHEMA":              {},
+	"SCHEMAS":             {},
+	"SECOND_MICROSECOND":  {},
+	"SELECT":              {},
+	"SET":                 {},
+	"SHOW":                {},
+	"SMALLINT":            {},
+	"SPATIAL":             {},
+	"SQL":                 {},
+	"SQL_BIG_RESULT":      {},
+	"SQL_CALC_FOUND_ROWS": {},
+	"SQL_SMALL_RESULT":    {},
+	"SSL":                 {},
+	"STARTING":            {},
+	"STATS_EXTENDED":      {},
+	"STORED":              {},
+	"STRAIGHT_JOIN":       {},
+	"TABLE":               {},
+	"TABLESAMPLE":         {},
+	"TERMINATED":          {},
+	"THEN":                {},
+	"TINYBLOB":            {},
+	"TINYINT":             {},
+	"TINYTEXT":            {},
+	"TO":                  {},
+	"TRAILING":            {},
+	"TRIGGER":             {},
+	"TRUE":                {},
+	"UNION":               {},
+	"UNIQUE":              {},
+	"UNLOCK":              {},
+	"UNSIGNED":            {},
+	"UPDATE":              {},
+	"USAGE":               {},
+	"USE":                 {},
+	"USING":               {},
+	"UTC_DATE":            {},
+	"UTC_TIME":            {},
+	"UTC_TIMESTAMP":       {},
+	"VALUES":              {},
+	"VARBINARY":           {},
+	"VARCHAR":             {},
+	"VARCHARACTER":        {},
+	"VARYING":             {},
+	"VIRTUAL":             {},
+	"WHEN":                {},
+	"WHERE":               {},
+	"WINDOW":              {},
+	"WITH":                {},
+	"WRITE":               {},
+	"XOR":                 {},
+	"YEAR_MONTH":          {},
+	"ZEROFILL":            {},
+}
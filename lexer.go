@@ -80,6 +80,17 @@ type Scanner struct {
 
 	// true if a dot follows an identifier
 	identifierDot bool
+
+	// tokenStartPos records the start position of the last token returned by Lex.
+	tokenStartPos Pos
+
+	// maxTokens and maxParenDepth limit the resource used by the parser, 0 means unlimited.
+	maxTokens     int
+	maxParenDepth int
+	tokenCount    int
+	parenDepth    int
+	// done is the Done channel of the context of the parsing.
+	done <-chan struct{}
 }
 
 // Errors returns the errors and warns during a scan.
@@ -98,6 +109,12 @@ func (s *Scanner) reset(sql string) {
 	s.stmtStartPos = 0
 	s.inBangComment = false
 	s.lastKeyword = 0
+	s.lastKeyword2 = 0
+	s.lastKeyword3 = 0
+	s.lastHintPos = Pos{}
+	s.identifierDot = false
+	s.tokenCount = 0
+	s.parenDepth = 0
 }
 
 func (s *Scanner) stmtText() string {
@@ -200,7 +217,11 @@ func (s *Scanner) getNextToken() int {
 // return invalid tells parser that scanner meets illegal character.
 func (s *Scanner) Lex(v *yySymType) int {
 	tok, pos, lit := s.scan()
+	if (s.maxTokens > 0 || s.maxParenDepth > 0 || s.done != nil) && !s.checkLimits(tok) {
+		return 0
+	}
 	s.lastScanOffset = pos.Offset
+	s.tokenStartPos = pos
 	s.lastKeyword3 = s.lastKeyword2
 	s.lastKeyword2 = s.lastKeyword
 	s.lastKeyword = 0
@@ -264,6 +285,44 @@ func (s *Scanner) Lex(v *yySymType) int {
 	return tok
 }
 
+// checkCancelInterval is the number of tokens between two checks of the context.
+const checkCancelInterval = 1024
+
+// checkLimits counts the token and checks the resource limits, it returns false
+// and stops the scanning if any limit is hit or the parsing is canceled.
+func (s *Scanner) checkLimits(tok int) bool {
+	if tok == 0 {
+		return true
+	}
+	s.tokenCount++
+	var err error
+	switch {
+	case s.maxTokens > 0 && s.tokenCount > s.maxTokens:
+		err = ErrParserLimitExceeded.GenWithStackByArgs("max tokens", s.tokenCount, s.maxTokens)
+	case tok == '(':
+		s.parenDepth++
+		if s.maxParenDepth > 0 && s.parenDepth > s.maxParenDepth {
+			err = ErrParserLimitExceeded.GenWithStackByArgs("max expression depth", s.parenDepth, s.maxParenDepth)
+		}
+	case tok == ')':
+		s.parenDepth--
+	}
+	if err == nil && s.done != nil && s.tokenCount%checkCancelInterval == 0 {
+		select {
+		case <-s.done:
+			err = ErrParseInterrupted.GenWithStackByArgs()
+		default:
+		}
+	}
+	if err == nil {
+		return true
+	}
+	s.AppendError(err)
+	// Skip the remaining text, so the parser meets EOF and stops.
+	s.r.p.Offset = s.r.l
+	return false
+}
+
 // LexLiteral returns the value of the converted literal
 func (s *Scanner) LexLiteral() interface{} {
 	symType := &yySymType{}
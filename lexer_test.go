@@ -15,6 +15,8 @@ package parser
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"unicode"
 
@@ -720,3 +722,66 @@ func TestFeatureIDs(t *testing.T) {
 		requires.Equalf(t, test.nextChar, nextChar, "input = %s", test.input)
 	}
 }
+
+func TestKeywords(t *testing.T) {
+	keywords := Keywords()
+	requires.True(t, sort.StringsAreSorted(keywords))
+	requires.Contains(t, keywords, "SELECT")
+	requires.Contains(t, keywords, "ACCOUNT")
+	requires.Contains(t, keywords, "GROUP_CONCAT")
+	for i := 1; i < len(keywords); i++ {
+		requires.NotEqual(t, keywords[i-1], keywords[i])
+	}
+}
+
+func TestIsReservedKeyword(t *testing.T) {
+	requires.True(t, IsReservedKeyword("select"))
+	requires.True(t, IsReservedKeyword("NATURAL"))
+	requires.False(t, IsReservedKeyword("account"))
+	requires.False(t, IsReservedKeyword("count"))
+	requires.False(t, IsReservedKeyword("foo"))
+
+	requires.True(t, IsReservedKeyword("rank"))
+	requires.True(t, IsReservedKeyword("Window"))
+
+	// The window function names are keywords only if the window functions are
+	// enabled.
+	p := New()
+	p.EnableWindowFunc(true)
+	keywords := Keywords()
+	for kw := range windowFuncTokenMap {
+		keywords = append(keywords, kw)
+	}
+	for _, kw := range keywords {
+		_, _, err := p.Parse("do (select 1 as "+kw+")", "", "")
+		requires.Equal(t, err != nil, IsReservedKeyword(kw), kw)
+	}
+	for kw := range reservedKeywords {
+		requires.Contains(t, keywords, kw)
+	}
+}
+
+func TestLookupKeyword(t *testing.T) {
+	cases := []struct {
+		word     string
+		kind     KeywordKind
+		reserved bool
+	}{
+		{"select", KeywordStatement, true},
+		{"Replace", KeywordStatement, true},
+		{"begin", KeywordStatement, false},
+		{"from", KeywordGeneral, true},
+		{"account", KeywordGeneral, false},
+		{"schema", KeywordGeneral, true},
+		{"count", KeywordFunction, false},
+		{"substr", KeywordFunction, false},
+		{"rank", KeywordWindowFunction, true},
+	}
+	for _, c := range cases {
+		info, ok := LookupKeyword(c.word)
+		requires.True(t, ok, c.word)
+		requires.Equal(t, KeywordInfo{Name: strings.ToUpper(c.word), Kind: c.kind, Reserved: c.reserved}, info, c.word)
+	}
+	_, ok := LookupKeyword("foo")
+	requires.False(t, ok)
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"github.com/daiguadaidai/parser/ast"
+)
+
+// astLimitChecker counts the AST nodes and the depth of expressions.
+type astLimitChecker struct {
+	maxDepth int
+	maxNodes int
+	depth    int
+	nodes    int
+	err      error
+}
+
+// Enter implements ast.Visitor interface.
+func (c *astLimitChecker) Enter(n ast.Node) (node ast.Node, skipChildren bool) {
+	c.nodes++
+	if c.maxNodes > 0 && c.nodes > c.maxNodes {
+		c.err = ErrParserLimitExceeded.GenWithStackByArgs("max AST nodes", c.nodes, c.maxNodes)
+		return n, true
+	}
+	if _, ok := n.(ast.ExprNode); ok {
+		c.depth++
+		if c.maxDepth > 0 && c.depth > c.maxDepth {
+			c.err = ErrParserLimitExceeded.GenWithStackByArgs("max expression depth", c.depth, c.maxDepth)
+			return n, true
+		}
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (c *astLimitChecker) Leave(n ast.Node) (node ast.Node, ok bool) {
+	if _, isExpr := n.(ast.ExprNode); isExpr {
+		c.depth--
+	}
+	return n, c.err == nil
+}
+
+// checkASTLimits checks the parsed statements against MaxASTNodes and MaxExprDepth.
+func (parser *Parser) checkASTLimits(stmts []ast.StmtNode) error {
+	if parser.maxASTNodes <= 0 && parser.maxExprDepth <= 0 {
+		return nil
+	}
+	c := &astLimitChecker{maxDepth: parser.maxExprDepth, maxNodes: parser.maxASTNodes}
+	for _, stmt := range stmts {
+		stmt.Accept(c)
+		if c.err != nil {
+			return c.err
+		}
+	}
+	return nil
+}
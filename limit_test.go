@@ -0,0 +1,96 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/terror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserLimits(t *testing.T) {
+	p := parser.New()
+	p.SetParserConfig(parser.ParserConfig{
+		EnableWindowFunction:        true,
+		EnableStrictDoubleTypeCheck: true,
+		MaxInputBytes:               1024,
+		MaxTokens:                   64,
+		MaxExprDepth:                8,
+		MaxASTNodes:                 32,
+	})
+
+	_, _, err := p.ParseSQL("select * from t where a in (1, 2, 3) and b = (select max(c) from t2)")
+	require.NoError(t, err)
+
+	cases := []struct {
+		sql   string
+		limit string
+	}{
+		{"select '" + strings.Repeat("x", 1024) + "'", "max input bytes"},
+		{"select * from t where a in (" + strings.Repeat("1, ", 40) + "1)", "max tokens"},
+		{"select " + strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), "max expression depth"},
+		{"select " + strings.Repeat("- ", 10) + "1", "max expression depth"},
+		{"select a, b, c, d, e, f, g, h, i, j, k, l, m, n, o, p, q, r, s, t, u, v, w, x, y, z from t", "max AST nodes"},
+	}
+	for _, c := range cases {
+		_, _, err = p.ParseSQL(c.sql)
+		require.Error(t, err, c.sql)
+		require.True(t, terror.ErrorEqual(err, parser.ErrParserLimitExceeded), "%v", err)
+		require.Contains(t, err.Error(), c.limit)
+	}
+
+	// The parser can still be used after a limit is hit.
+	stmts, _, err := p.ParseSQL("select 1; select 2")
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+
+	// Limits are disabled by default.
+	p = parser.New()
+	_, _, err = p.ParseSQL("select " + strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100))
+	require.NoError(t, err)
+}
+
+func TestParseSQLContext(t *testing.T) {
+	p := parser.New()
+	sql := "select * from t where a in (" + strings.Repeat("1, ", 5000) + "1)"
+
+	stmts, _, err := p.ParseSQLContext(context.Background(), sql)
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = p.ParseSQLContext(ctx, sql)
+	require.True(t, terror.ErrorEqual(err, parser.ErrParseInterrupted), "%v", err)
+
+	// Cancellation is also observed in the middle of the scanning.
+	done := make(chan struct{})
+	close(done)
+	_, _, err = p.ParseSQLContext(lateCancelCtx{Context: context.Background(), done: done}, sql)
+	require.True(t, terror.ErrorEqual(err, parser.ErrParseInterrupted), "%v", err)
+}
+
+// lateCancelCtx is a context which is only noticed to be done by its Done channel.
+type lateCancelCtx struct {
+	context.Context
+	done chan struct{}
+}
+
+func (c lateCancelCtx) Done() <-chan struct{} {
+	return c.done
+}
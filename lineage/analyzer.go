@@ -0,0 +1,745 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lineage
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+)
+
+// output is a result column of a query block.
+type output struct {
+	name    string
+	sources []Column
+}
+
+// source is a table in a FROM clause.
+type source struct {
+	name string
+	// table is nil for a derived table or a CTE.
+	table *Table
+	// outputs are the columns of the source, known is false if they are
+	// unknown, which is the case of a base table without a catalog.
+	outputs []output
+	known   bool
+}
+
+func (s *source) find(name string) (output, bool) {
+	for _, o := range s.outputs {
+		if o.name == name {
+			return o, true
+		}
+	}
+	return output{}, false
+}
+
+// scope is the name scope of a query block.
+type scope struct {
+	parent  *scope
+	sources []*source
+	ctes    map[string][]output
+	// fields are the result columns, they are seen by the aliases in ORDER
+	// BY, GROUP BY and HAVING.
+	fields []output
+	node   *Scope
+}
+
+func (s *scope) findCTE(name string) ([]output, bool) {
+	for ; s != nil; s = s.parent {
+		if outputs, ok := s.ctes[name]; ok {
+			return outputs, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scope) findField(name string) (output, bool) {
+	for _, f := range s.fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return output{}, false
+}
+
+type analyzer struct {
+	*Analyzer
+	res     *Result
+	tables  map[Table]int
+	columns map[Column]int
+	targets map[targetKey]int
+}
+
+// targetKey identifies a written column, the columns without a name are
+// identified by their positions.
+type targetKey struct {
+	Column
+	pos int
+}
+
+func (a *analyzer) tableOf(tn *ast.TableName) Table {
+	t := Table{Schema: tn.Schema.O, Name: tn.Name.O}
+	if t.Schema == "" {
+		t.Schema = a.CurrentDB
+	}
+	return t
+}
+
+// addTable adds a table to the read or written tables.
+func (a *analyzer) addTable(t Table, write bool) {
+	key := t
+	flag := 1
+	if write {
+		flag = 2
+	}
+	if a.tables[key]&flag != 0 {
+		return
+	}
+	a.tables[key] |= flag
+	if write {
+		a.res.Write = append(a.res.Write, t)
+	} else {
+		a.res.Read = append(a.res.Read, t)
+	}
+}
+
+func (a *analyzer) addRef(c Column, clause Clause) {
+	if idx, ok := a.columns[c]; ok {
+		a.res.Columns[idx].Clauses |= clause
+		return
+	}
+	a.columns[c] = len(a.res.Columns)
+	a.res.Columns = append(a.res.Columns, ColumnRef{Column: c, Clauses: clause})
+}
+
+// addLineage adds the sources of a written column, the sources of the same
+// target are merged.
+func (a *analyzer) addLineage(target Column, pos int, sources []Column) {
+	key := targetKey{Column: target}
+	if target.Name == "" {
+		key.pos = pos
+	}
+	if idx, ok := a.targets[key]; ok {
+		a.res.Lineage[idx].Sources = mergeColumns(a.res.Lineage[idx].Sources, sources)
+		return
+	}
+	a.targets[key] = len(a.res.Lineage)
+	a.res.Lineage = append(a.res.Lineage, ColumnLineage{Target: target, Position: pos, Sources: mergeColumns(nil, sources)})
+}
+
+// mergeColumns appends the columns not in dst.
+func mergeColumns(dst, src []Column) []Column {
+	for _, c := range src {
+		found := false
+		for _, d := range dst {
+			if d == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+func (a *analyzer) stmt(stmt ast.StmtNode) {
+	root := &scope{node: a.res.Scope}
+	switch x := stmt.(type) {
+	case *ast.SelectStmt, *ast.SetOprStmt:
+		a.res.Scope.Columns = names(a.query(root, x.(ast.ResultSetNode), a.res.Scope))
+	case *ast.InsertStmt:
+		a.insert(root, x)
+	case *ast.UpdateStmt:
+		a.update(root, x)
+	case *ast.DeleteStmt:
+		a.delete(root, x)
+	case *ast.CreateTableStmt:
+		t := a.tableOf(x.Table)
+		if x.ReferTable != nil {
+			a.addTable(a.tableOf(x.ReferTable), false)
+		}
+		a.addTable(t, true)
+		if x.Select != nil {
+			outputs := a.query(root, x.Select, a.res.Scope)
+			a.res.Scope.Columns = names(outputs)
+			for i, o := range outputs {
+				a.addLineage(Column{Table: t, Name: o.name}, i, o.sources)
+			}
+		}
+	case *ast.CreateViewStmt:
+		t := a.tableOf(x.ViewName)
+		a.addTable(t, true)
+		sel, ok := x.Select.(ast.ResultSetNode)
+		if !ok {
+			return
+		}
+		outputs := a.query(root, sel, a.res.Scope)
+		a.res.Scope.Columns = names(outputs)
+		for i, o := range outputs {
+			name := o.name
+			if i < len(x.Cols) {
+				name = x.Cols[i].L
+			}
+			a.addLineage(Column{Table: t, Name: name}, i, o.sources)
+		}
+	case *ast.ExplainStmt:
+		a.stmt(x.Stmt)
+	case *ast.DropTableStmt, *ast.TruncateTableStmt, *ast.AlterTableStmt, *ast.RenameTableStmt,
+		*ast.CreateIndexStmt, *ast.DropIndexStmt, *ast.LoadDataStmt:
+		for _, tn := range ast.FindTableNames(x) {
+			a.addTable(a.tableOf(tn), true)
+		}
+	}
+}
+
+func names(outputs []output) []string {
+	res := make([]string, 0, len(outputs))
+	for _, o := range outputs {
+		res = append(res, o.name)
+	}
+	return res
+}
+
+// child creates the Scope of a CTE, a derived table or a subquery.
+func child(parent *Scope, kind ScopeKind, name string) *Scope {
+	s := &Scope{Kind: kind, Name: name}
+	parent.Children = append(parent.Children, s)
+	return s
+}
+
+// query analyzes a query block and returns its result columns.
+func (a *analyzer) query(parent *scope, node ast.Node, ns *Scope) []output {
+	switch x := node.(type) {
+	case *ast.SelectStmt:
+		return a.selectStmt(&scope{parent: parent, node: ns}, x)
+	case *ast.SetOprStmt:
+		s := &scope{parent: parent, node: ns}
+		if x.With != nil {
+			a.with(s, x.With)
+		}
+		outputs := a.setOprList(s, x.SelectList)
+		if x.OrderBy != nil {
+			// The ORDER BY of a set operation sees the result columns only.
+			os := &scope{parent: parent, node: ns, sources: []*source{{outputs: outputs, known: true}}}
+			a.byItems(os, x.OrderBy.Items, ClauseOrderBy)
+		}
+		return outputs
+	case *ast.SetOprSelectList:
+		return a.setOprList(&scope{parent: parent, node: ns}, x)
+	case *ast.SubqueryExpr:
+		return a.query(parent, x.Query, ns)
+	}
+	return nil
+}
+
+// setOprList analyzes the SELECTs of a set operation, the sources of a
+// result column are merged from all the SELECTs.
+func (a *analyzer) setOprList(s *scope, list *ast.SetOprSelectList) []output {
+	if list.With != nil {
+		s = &scope{parent: s, node: s.node}
+		a.with(s, list.With)
+	}
+	var outputs []output
+	for i, sel := range list.Selects {
+		res := a.query(s, sel, s.node)
+		if i == 0 {
+			outputs = res
+			continue
+		}
+		for j := range outputs {
+			if j < len(res) {
+				outputs[j].sources = mergeColumns(outputs[j].sources, res[j].sources)
+			}
+		}
+	}
+	return outputs
+}
+
+func (a *analyzer) with(s *scope, with *ast.WithClause) {
+	if s.ctes == nil {
+		s.ctes = make(map[string][]output, len(with.CTEs))
+	}
+	for _, c := range with.CTEs {
+		ns := child(s.node, ScopeCTE, c.Name.O)
+		var outputs []output
+		if set, ok := c.Query.Query.(*ast.SetOprStmt); ok && with.IsRecursive {
+			// The recursive part sees the columns of the seed part.
+			seed := a.query(s, set.SelectList.Selects[0], &Scope{})
+			s.ctes[c.Name.L] = renameOutputs(seed, c.ColNameList)
+		}
+		outputs = renameOutputs(a.query(s, c.Query, ns), c.ColNameList)
+		ns.Columns = names(outputs)
+		s.ctes[c.Name.L] = outputs
+	}
+}
+
+func renameOutputs(outputs []output, cols []model.CIStr) []output {
+	for i := range outputs {
+		if i < len(cols) {
+			outputs[i].name = cols[i].L
+		}
+	}
+	return outputs
+}
+
+func (a *analyzer) selectStmt(s *scope, sel *ast.SelectStmt) []output {
+	if sel.With != nil {
+		a.with(s, sel.With)
+	}
+	if sel.From != nil {
+		a.tableRefs(s, sel.From.TableRefs)
+	}
+	switch sel.Kind {
+	case ast.SelectStmtKindTable:
+		s.fields = a.wildcard(s, nil)
+	case ast.SelectStmtKindValues:
+		for _, row := range sel.Lists {
+			for i, e := range row.Values {
+				if i >= len(s.fields) {
+					s.fields = append(s.fields, output{name: "column_" + strconv.Itoa(i)})
+				}
+				s.fields[i].sources = mergeColumns(s.fields[i].sources, a.expr(s, e, ClauseSelect))
+			}
+		}
+	default:
+		s.fields = a.fields(s, sel.Fields)
+	}
+	a.expr(s, sel.Where, ClauseWhere)
+	if sel.GroupBy != nil {
+		a.byItems(s, sel.GroupBy.Items, ClauseGroupBy)
+	}
+	if sel.Having != nil {
+		a.expr(s, sel.Having.Expr, ClauseHaving)
+	}
+	for i := range sel.WindowSpecs {
+		a.windowSpec(s, &sel.WindowSpecs[i])
+	}
+	if sel.OrderBy != nil {
+		a.byItems(s, sel.OrderBy.Items, ClauseOrderBy)
+	}
+	return s.fields
+}
+
+func (a *analyzer) windowSpec(s *scope, spec *ast.WindowSpec) {
+	if spec.PartitionBy != nil {
+		a.byItems(s, spec.PartitionBy.Items, ClauseWindow)
+	}
+	if spec.OrderBy != nil {
+		a.byItems(s, spec.OrderBy.Items, ClauseWindow)
+	}
+}
+
+func (a *analyzer) byItems(s *scope, items []*ast.ByItem, clause Clause) {
+	for _, item := range items {
+		a.expr(s, item.Expr, clause)
+	}
+}
+
+// tableRefs adds the tables of a FROM clause to the scope.
+func (a *analyzer) tableRefs(s *scope, node ast.ResultSetNode) []*source {
+	switch x := node.(type) {
+	case *ast.Join:
+		left := a.tableRefs(s, x.Left)
+		if x.Right == nil {
+			return left
+		}
+		right := a.tableRefs(s, x.Right)
+		switch {
+		case x.NaturalJoin:
+			for _, l := range left {
+				for _, o := range l.outputs {
+					if matched := findSource(right, o.name); matched != nil {
+						a.refSources(o.sources, ClauseJoin)
+						o2, _ := matched.find(o.name)
+						a.refSources(o2.sources, ClauseJoin)
+					}
+				}
+			}
+		case len(x.Using) > 0:
+			for _, name := range x.Using {
+				for _, side := range [][]*source{left, right} {
+					a.refSources(a.sourceColumn(side, name.Name.L), ClauseJoin)
+				}
+			}
+		}
+		if x.On != nil {
+			a.expr(s, x.On.Expr, ClauseJoin)
+		}
+		return append(left, right...)
+	case *ast.TableSource:
+		src := a.tableSource(s, x)
+		s.sources = append(s.sources, src)
+		return []*source{src}
+	}
+	return nil
+}
+
+func findSource(sources []*source, name string) *source {
+	for _, src := range sources {
+		if _, ok := src.find(name); ok {
+			return src
+		}
+	}
+	return nil
+}
+
+// sourceColumn binds a column to the sources of one side of a join.
+func (a *analyzer) sourceColumn(sources []*source, name string) []Column {
+	if src := findSource(sources, name); src != nil {
+		o, _ := src.find(name)
+		return o.sources
+	}
+	var unknown []*source
+	for _, src := range sources {
+		if !src.known {
+			unknown = append(unknown, src)
+		}
+	}
+	if len(unknown) == 1 {
+		return []Column{{Table: *unknown[0].table, Name: name}}
+	}
+	return []Column{{Name: name}}
+}
+
+func (a *analyzer) tableSource(s *scope, ts *ast.TableSource) *source {
+	switch x := ts.Source.(type) {
+	case *ast.TableName:
+		name := ts.AsName.L
+		if name == "" {
+			name = x.Name.L
+		}
+		if x.Schema.L == "" {
+			if outputs, ok := s.findCTE(x.Name.L); ok {
+				return &source{name: name, outputs: outputs, known: true}
+			}
+		}
+		t := a.tableOf(x)
+		a.addTable(t, false)
+		s.node.Tables = appendTable(s.node.Tables, t)
+		src := &source{name: name, table: &t}
+		if a.InfoSchema != nil {
+			if tbl, ok := a.InfoSchema.TableByName(model.NewCIStr(t.Schema), x.Name); ok {
+				src.known = true
+				for _, col := range tbl.Cols() {
+					src.outputs = append(src.outputs, output{name: col.Name.L, sources: []Column{{Table: t, Name: col.Name.L}}})
+				}
+			}
+		}
+		return src
+	case *ast.SelectStmt, *ast.SetOprStmt:
+		ns := child(s.node, ScopeDerived, ts.AsName.O)
+		outputs := a.query(s, x, ns)
+		ns.Columns = names(outputs)
+		return &source{name: ts.AsName.L, outputs: outputs, known: true}
+	}
+	return &source{name: ts.AsName.L, known: true}
+}
+
+func appendTable(tables []Table, t Table) []Table {
+	for _, tbl := range tables {
+		if tbl == t {
+			return tables
+		}
+	}
+	return append(tables, t)
+}
+
+func (a *analyzer) refSources(cols []Column, clause Clause) {
+	for _, c := range cols {
+		a.addRef(c, clause)
+	}
+}
+
+// fields analyzes the select fields and returns the result columns.
+func (a *analyzer) fields(s *scope, fields *ast.FieldList) []output {
+	var outputs []output
+	for _, f := range fields.Fields {
+		if f.WildCard != nil {
+			outputs = append(outputs, a.wildcard(s, f.WildCard)...)
+			continue
+		}
+		outputs = append(outputs, output{name: fieldName(f), sources: a.expr(s, f.Expr, ClauseSelect)})
+	}
+	return outputs
+}
+
+// wildcard expands "*" or "t.*" of the scope, the columns of a table without
+// a catalog are the column "*".
+func (a *analyzer) wildcard(s *scope, w *ast.WildCardField) []output {
+	var outputs []output
+	for _, src := range s.sources {
+		if w != nil && w.Table.L != "" && (src.name != w.Table.L || w.Schema.L != "" && (src.table == nil || !strings.EqualFold(src.table.Schema, w.Schema.O))) {
+			continue
+		}
+		if !src.known {
+			c := Column{Table: *src.table, Name: "*"}
+			a.addRef(c, ClauseSelect)
+			outputs = append(outputs, output{name: "*", sources: []Column{c}})
+			continue
+		}
+		for _, o := range src.outputs {
+			a.refSources(o.sources, ClauseSelect)
+			outputs = append(outputs, o)
+		}
+	}
+	return outputs
+}
+
+// fieldName returns the name of a result column like MySQL.
+func fieldName(f *ast.SelectField) string {
+	if f.AsName.L != "" {
+		return f.AsName.L
+	}
+	if c, ok := f.Expr.(*ast.ColumnNameExpr); ok {
+		return c.Name.Name.L
+	}
+	if text := strings.TrimSpace(f.Text()); text != "" {
+		return strings.ToLower(text)
+	}
+	var sb strings.Builder
+	if err := f.Expr.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return ""
+	}
+	return strings.ToLower(sb.String())
+}
+
+// column binds a column name and returns the base table columns it refers to.
+func (a *analyzer) column(s *scope, cn *ast.ColumnName, clause Clause) []Column {
+	name := cn.Name.L
+	if cn.Table.L != "" {
+		for sc := s; sc != nil; sc = sc.parent {
+			for _, src := range sc.sources {
+				if src.name != cn.Table.L || cn.Schema.L != "" && (src.table == nil || !strings.EqualFold(src.table.Schema, cn.Schema.O)) {
+					continue
+				}
+				if o, ok := src.find(name); ok {
+					return o.sources
+				}
+				if src.table != nil {
+					return []Column{{Table: *src.table, Name: name}}
+				}
+				return []Column{{Name: name}}
+			}
+		}
+		return []Column{{Table: Table{Schema: cn.Schema.O, Name: cn.Table.O}, Name: name}}
+	}
+	for sc := s; sc != nil; sc = sc.parent {
+		if sc == s && (clause == ClauseOrderBy || clause == ClauseHaving) {
+			// ORDER BY and HAVING prefer the aliases of the result columns.
+			if f, ok := sc.findField(name); ok {
+				return f.sources
+			}
+		}
+		if src := findSource(sc.sources, name); src != nil {
+			o, _ := src.find(name)
+			return o.sources
+		}
+		var unknown []*source
+		for _, src := range sc.sources {
+			if !src.known {
+				unknown = append(unknown, src)
+			}
+		}
+		switch len(unknown) {
+		case 0:
+		case 1:
+			return []Column{{Table: *unknown[0].table, Name: name}}
+		default:
+			return []Column{{Name: name}}
+		}
+		if sc == s && clause == ClauseGroupBy {
+			if f, ok := sc.findField(name); ok {
+				return f.sources
+			}
+		}
+	}
+	return []Column{{Name: name}}
+}
+
+// expr analyzes an expression, it returns the base table columns the value of
+// the expression is computed from.
+func (a *analyzer) expr(s *scope, e ast.ExprNode, clause Clause) []Column {
+	if e == nil {
+		return nil
+	}
+	v := &exprVisitor{a: a, s: s, clause: clause}
+	e.Accept(v)
+	return v.sources
+}
+
+type exprVisitor struct {
+	a       *analyzer
+	s       *scope
+	clause  Clause
+	sources []Column
+}
+
+// Enter implements ast.Visitor interface.
+func (v *exprVisitor) Enter(n ast.Node) (ast.Node, bool) {
+	switch x := n.(type) {
+	case *ast.ColumnNameExpr:
+		cols := v.a.column(v.s, x.Name, v.clause)
+		v.a.refSources(cols, v.clause)
+		v.sources = mergeColumns(v.sources, cols)
+		return n, true
+	case *ast.SubqueryExpr:
+		outputs := v.a.query(v.s, x.Query, child(v.s.node, ScopeSubquery, ""))
+		if len(outputs) > 0 {
+			v.sources = mergeColumns(v.sources, outputs[0].sources)
+		}
+		return n, true
+	case *ast.WindowFuncExpr:
+		for _, arg := range x.Args {
+			v.sources = mergeColumns(v.sources, v.a.expr(v.s, arg, v.clause))
+		}
+		v.a.windowSpec(v.s, &x.Spec)
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (v *exprVisitor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+func (a *analyzer) targetTable(tn *ast.TableRefsClause) (Table, bool) {
+	if tn == nil || tn.TableRefs == nil {
+		return Table{}, false
+	}
+	ts, ok := tn.TableRefs.Left.(*ast.TableSource)
+	if !ok {
+		return Table{}, false
+	}
+	name, ok := ts.Source.(*ast.TableName)
+	if !ok {
+		return Table{}, false
+	}
+	return a.tableOf(name), true
+}
+
+func (a *analyzer) insert(s *scope, n *ast.InsertStmt) {
+	t, ok := a.targetTable(n.Table)
+	if !ok {
+		return
+	}
+	a.addTable(t, true)
+	targets := make([]string, 0, len(n.Columns))
+	for _, c := range n.Columns {
+		targets = append(targets, c.Name.L)
+	}
+	if len(targets) == 0 && len(n.Setlist) == 0 && a.InfoSchema != nil {
+		if tbl, ok := a.InfoSchema.TableByName(model.NewCIStr(t.Schema), model.NewCIStr(t.Name)); ok {
+			for _, col := range tbl.Cols() {
+				targets = append(targets, col.Name.L)
+			}
+		}
+	}
+	target := func(i int) Column {
+		c := Column{Table: t}
+		if i < len(targets) {
+			c.Name = targets[i]
+			a.addRef(c, ClauseSet)
+		}
+		return c
+	}
+	switch {
+	case n.Select != nil:
+		outputs := a.query(s, n.Select, a.res.Scope)
+		a.res.Scope.Columns = names(outputs)
+		for i, o := range outputs {
+			a.addLineage(target(i), i, o.sources)
+		}
+	case len(n.Setlist) > 0:
+		for i, as := range n.Setlist {
+			c := Column{Table: t, Name: as.Column.Name.L}
+			a.addRef(c, ClauseSet)
+			a.addLineage(c, i, a.expr(s, as.Expr, ClauseSelect))
+		}
+	default:
+		for _, row := range n.Lists {
+			for i, e := range row {
+				a.addLineage(target(i), i, a.expr(s, e, ClauseSelect))
+			}
+		}
+	}
+	if len(n.OnDuplicate) > 0 {
+		// ON DUPLICATE KEY UPDATE sees the inserted table and the columns
+		// of the SELECT.
+		ds := &scope{parent: s, node: a.res.Scope, sources: append([]*source{{name: strings.ToLower(t.Name), table: &t}}, s.sources...)}
+		for _, as := range n.OnDuplicate {
+			c := Column{Table: t, Name: as.Column.Name.L}
+			a.addRef(c, ClauseSet)
+			a.addLineage(c, len(a.res.Lineage), a.expr(ds, as.Expr, ClauseSelect))
+		}
+	}
+}
+
+func (a *analyzer) update(s *scope, n *ast.UpdateStmt) {
+	if n.With != nil {
+		a.with(s, n.With)
+	}
+	if n.TableRefs != nil {
+		a.tableRefs(s, n.TableRefs.TableRefs)
+	}
+	for i, as := range n.List {
+		sources := a.expr(s, as.Expr, ClauseSelect)
+		for _, c := range a.column(s, as.Column, ClauseSet) {
+			a.addRef(c, ClauseSet)
+			if c.Table.Name != "" {
+				a.addTable(c.Table, true)
+			}
+			a.addLineage(c, i, sources)
+		}
+	}
+	a.expr(s, n.Where, ClauseWhere)
+	if n.Order != nil {
+		a.byItems(s, n.Order.Items, ClauseOrderBy)
+	}
+}
+
+func (a *analyzer) delete(s *scope, n *ast.DeleteStmt) {
+	if n.With != nil {
+		a.with(s, n.With)
+	}
+	var sources []*source
+	if n.TableRefs != nil {
+		sources = a.tableRefs(s, n.TableRefs.TableRefs)
+	}
+	a.expr(s, n.Where, ClauseWhere)
+	if n.Order != nil {
+		a.byItems(s, n.Order.Items, ClauseOrderBy)
+	}
+	if !n.IsMultiTable || n.Tables == nil {
+		for _, src := range sources {
+			if src.table != nil {
+				a.addTable(*src.table, true)
+			}
+		}
+		return
+	}
+	for _, tn := range n.Tables.Tables {
+		for _, src := range sources {
+			if src.table != nil && src.name == tn.Name.L && (tn.Schema.L == "" || strings.EqualFold(src.table.Schema, tn.Schema.O)) {
+				a.addTable(*src.table, true)
+			}
+		}
+	}
+}
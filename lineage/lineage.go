@@ -0,0 +1,223 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lineage extracts the tables and the columns a statement reads and
+// writes, and the column-level lineage of the data it writes.
+//
+// The analysis is syntactic: the names are bound by the scopes of the
+// statement, so a column of a derived table or a CTE is traced to the base
+// table columns it is computed from. Without a schema catalog an unqualified
+// column is bound to the only base table of its scope, or to an empty Table
+// if the scope has several tables. With a catalog, the wildcards are expanded
+// and the unqualified columns are bound to the tables having them.
+package lineage
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/resolver"
+)
+
+// Table is a base table, the Schema is empty if the table name is not
+// qualified and the Analyzer has no CurrentDB.
+type Table struct {
+	Schema string
+	Name   string
+}
+
+// String implements fmt.Stringer interface.
+func (t Table) String() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// Column is a column of a base table, the name is in lower case. The Table is
+// empty if the column can not be bound to a table, and the Name is "*" for
+// the wildcard of a table whose columns are unknown.
+type Column struct {
+	Table Table
+	Name  string
+}
+
+// String implements fmt.Stringer interface. A column without a Name is
+// rendered as its table.
+func (c Column) String() string {
+	t := c.Table.String()
+	switch {
+	case t == "":
+		return c.Name
+	case c.Name == "":
+		return t
+	}
+	return t + "." + c.Name
+}
+
+// Clause is a set of the clauses a column is referenced in.
+type Clause uint16
+
+// Clauses.
+const (
+	// ClauseSelect is the field list, or the assigned value of UPDATE and INSERT.
+	ClauseSelect Clause = 1 << iota
+	// ClauseJoin is the ON condition or the USING list of a join.
+	ClauseJoin
+	// ClauseWhere is the WHERE clause.
+	ClauseWhere
+	// ClauseGroupBy is the GROUP BY clause.
+	ClauseGroupBy
+	// ClauseHaving is the HAVING clause.
+	ClauseHaving
+	// ClauseWindow is the PARTITION BY and ORDER BY of a window.
+	ClauseWindow
+	// ClauseOrderBy is the ORDER BY clause.
+	ClauseOrderBy
+	// ClauseSet is a column assigned by INSERT, UPDATE or ON DUPLICATE KEY UPDATE.
+	ClauseSet
+)
+
+var clauseNames = []string{"select", "join", "where", "group by", "having", "window", "order by", "set"}
+
+// String implements fmt.Stringer interface.
+func (c Clause) String() string {
+	var names []string
+	for i, name := range clauseNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// ColumnRef is a column referenced by a statement.
+type ColumnRef struct {
+	Column
+	// Clauses are the clauses the column is referenced in.
+	Clauses Clause
+}
+
+// ScopeKind is the kind of a Scope.
+type ScopeKind int
+
+// Scope kinds.
+const (
+	// ScopeQuery is the statement itself.
+	ScopeQuery ScopeKind = iota
+	// ScopeCTE is a common table expression.
+	ScopeCTE
+	// ScopeDerived is a derived table in a FROM clause.
+	ScopeDerived
+	// ScopeSubquery is a subquery in an expression.
+	ScopeSubquery
+)
+
+var scopeKindNames = []string{"query", "cte", "derived", "subquery"}
+
+// String implements fmt.Stringer interface.
+func (k ScopeKind) String() string {
+	return scopeKindNames[k]
+}
+
+// Scope is a query block of a statement, all the SELECTs of a set operation
+// are one Scope.
+type Scope struct {
+	Kind ScopeKind
+	// Name is the name of the CTE or the alias of the derived table.
+	Name string
+	// Tables are the base tables read in the FROM clauses of the scope.
+	Tables []Table
+	// Columns are the names of the result columns of the scope.
+	Columns []string
+	// Children are the CTEs, derived tables and subqueries in the scope.
+	Children []*Scope
+}
+
+// ColumnLineage is the lineage of a column written by a statement.
+type ColumnLineage struct {
+	// Target is the written column, its Name is empty if the target columns
+	// of INSERT are not listed and there is no catalog.
+	Target Column
+	// Position is the position of the column in the written columns.
+	Position int
+	// Sources are the base table columns the written values are computed from.
+	Sources []Column
+}
+
+// String implements fmt.Stringer interface, it is like "t.a<-s.x,s.y". A
+// Target without a Name is rendered by its position from 1, like "t.#1".
+func (l ColumnLineage) String() string {
+	target := l.Target.String()
+	if l.Target.Name == "" {
+		target += ".#" + strconv.Itoa(l.Position+1)
+	}
+	sources := make([]string, 0, len(l.Sources))
+	for _, c := range l.Sources {
+		sources = append(sources, c.String())
+	}
+	return target + "<-" + strings.Join(sources, ",")
+}
+
+// Result is the lineage of a statement.
+type Result struct {
+	// Read are the base tables read by the statement.
+	Read []Table
+	// Write are the tables written by the statement.
+	Write []Table
+	// Columns are the base table columns referenced by the statement.
+	Columns []ColumnRef
+	// Scope is the outermost query block of the statement.
+	Scope *Scope
+	// Lineage is the column lineage of INSERT, REPLACE, UPDATE, CREATE TABLE
+	// ... SELECT and CREATE VIEW.
+	Lineage []ColumnLineage
+}
+
+// ColumnsOf returns the referenced columns of a table.
+func (r *Result) ColumnsOf(t Table) []ColumnRef {
+	var res []ColumnRef
+	for _, c := range r.Columns {
+		if c.Table == t {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Analyzer analyzes the lineage of statements.
+type Analyzer struct {
+	// InfoSchema is the optional schema catalog.
+	InfoSchema resolver.InfoSchema
+	// CurrentDB is the schema of the unqualified table names.
+	CurrentDB string
+}
+
+// Analyze returns the lineage of a statement without a schema catalog.
+func Analyze(stmt ast.StmtNode) *Result {
+	return (&Analyzer{}).Analyze(stmt)
+}
+
+// Analyze returns the lineage of a statement.
+func (a *Analyzer) Analyze(stmt ast.StmtNode) *Result {
+	an := &analyzer{
+		Analyzer: a,
+		res:      &Result{Scope: &Scope{Kind: ScopeQuery}},
+		tables:   make(map[Table]int),
+		columns:  make(map[Column]int),
+		targets:  make(map[targetKey]int),
+	}
+	an.stmt(stmt)
+	return an.res
+}
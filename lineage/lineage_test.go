@@ -0,0 +1,250 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lineage_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ddl"
+	. "github.com/daiguadaidai/parser/lineage"
+	"github.com/daiguadaidai/parser/resolver"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+func analyze(t *testing.T, a *Analyzer, sql string) *Result {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err, sql)
+	return a.Analyze(stmt)
+}
+
+func tableStrings(tables []Table) string {
+	res := make([]string, 0, len(tables))
+	for _, t := range tables {
+		res = append(res, t.String())
+	}
+	return strings.Join(res, ",")
+}
+
+func columnStrings(refs []ColumnRef) string {
+	res := make([]string, 0, len(refs))
+	for _, c := range refs {
+		res = append(res, fmt.Sprintf("%s[%s]", c.Column, c.Clauses))
+	}
+	return strings.Join(res, " ")
+}
+
+func lineageStrings(lineage []ColumnLineage) string {
+	res := make([]string, 0, len(lineage))
+	for _, l := range lineage {
+		res = append(res, l.String())
+	}
+	return strings.Join(res, " ")
+}
+
+func TestTables(t *testing.T) {
+	cases := []struct {
+		sql   string
+		read  string
+		write string
+	}{
+		{"select * from t", "t", ""},
+		{"select * from db.t, s join u on s.a = u.a", "db.t,s,u", ""},
+		{"select * from t where a in (select a from s)", "t,s", ""},
+		{"with c as (select * from t) select * from c, c as d", "t", ""},
+		{"select * from t union select * from s", "t,s", ""},
+		{"insert into t select * from s", "s", "t"},
+		{"insert into t values (1)", "", "t"},
+		{"replace into t set a = 1", "", "t"},
+		{"update t, s set t.a = s.a where t.b = s.b", "t,s", "t"},
+		{"delete t from t join s on t.a = s.a", "t,s", "t"},
+		{"delete from t where a = 1", "t", "t"},
+		{"create table t2 as select * from t", "t", "t2"},
+		{"create table t2 like t", "t", "t2"},
+		{"create view v as select a from t", "t", "v"},
+		{"drop table t, s", "", "t,s"},
+		{"truncate table t", "", "t"},
+		{"explain select * from t", "t", ""},
+	}
+	for _, c := range cases {
+		res := analyze(t, &Analyzer{}, c.sql)
+		require.Equal(t, c.read, tableStrings(res.Read), c.sql)
+		require.Equal(t, c.write, tableStrings(res.Write), c.sql)
+	}
+
+	res := analyze(t, &Analyzer{CurrentDB: "test"}, "select * from t, db.s")
+	require.Equal(t, "test.t,db.s", tableStrings(res.Read))
+}
+
+func TestColumns(t *testing.T) {
+	cases := []struct {
+		sql     string
+		columns string
+	}{
+		{
+			"select a, b + 1 from t where c > 0 order by d",
+			"t.a[select] t.b[select] t.c[where] t.d[order by]",
+		},
+		{
+			"select t.a, count(*) from t join s on t.id = s.tid where s.x = 1 group by t.a having sum(s.y) > 1",
+			"t.id[join] s.tid[join] t.a[select|group by] s.x[where] s.y[having]",
+		},
+		{
+			"select a as x from t order by x",
+			"t.a[select|order by]",
+		},
+		{
+			"select a, row_number() over (partition by b order by c) from t",
+			"t.a[select] t.b[window] t.c[window]",
+		},
+		{
+			"select a, sum(b) over w from t window w as (partition by c)",
+			"t.a[select] t.b[select] t.c[window]",
+		},
+		{
+			"select x.b from (select a, b from t where c = 1) x where x.a = 2",
+			"t.a[select|where] t.b[select] t.c[where]",
+		},
+		{
+			"with c (k) as (select a from t) select k from c where k > 1",
+			"t.a[select|where]",
+		},
+		{
+			"select * from t join s using (a)",
+			"t.a[join] s.a[join] t.*[select] s.*[select]",
+		},
+		{
+			"select a from t, s",
+			"a[select]",
+		},
+		{
+			"update t set a = b + 1 where c = 2",
+			"t.b[select] t.a[set] t.c[where]",
+		},
+		{
+			"insert into t (a, b) select x, y from s",
+			"s.x[select] s.y[select] t.a[set] t.b[set]",
+		},
+	}
+	for _, c := range cases {
+		res := analyze(t, &Analyzer{}, c.sql)
+		require.Equal(t, c.columns, columnStrings(res.Columns), c.sql)
+	}
+
+	res := analyze(t, &Analyzer{}, "select t.a, s.b from t join s on t.a = s.a")
+	require.Equal(t, "t.a[select|join]", columnStrings(res.ColumnsOf(Table{Name: "t"})))
+	require.Equal(t, "s.a[join] s.b[select]", columnStrings(res.ColumnsOf(Table{Name: "s"})))
+}
+
+func TestScopes(t *testing.T) {
+	res := analyze(t, &Analyzer{}, "with c as (select a from t) select x.a, (select max(b) from s) m from c join (select a from u) x on c.a = x.a where exists (select 1 from v)")
+	root := res.Scope
+	require.Equal(t, ScopeQuery, root.Kind)
+	require.Equal(t, []string{"a", "m"}, root.Columns)
+	require.Empty(t, root.Tables)
+	require.Len(t, root.Children, 4)
+
+	kinds := make([]string, 0, len(root.Children))
+	for _, s := range root.Children {
+		kinds = append(kinds, fmt.Sprintf("%s:%s:%s", s.Kind, s.Name, tableStrings(s.Tables)))
+	}
+	require.Equal(t, []string{"cte:c:t", "derived:x:u", "subquery::s", "subquery::v"}, kinds)
+
+	res = analyze(t, &Analyzer{}, "select a from t union all select b from s")
+	require.Equal(t, "t,s", tableStrings(res.Scope.Tables))
+	require.Equal(t, []string{"a"}, res.Scope.Columns)
+}
+
+func TestLineage(t *testing.T) {
+	cases := []struct {
+		sql     string
+		lineage string
+	}{
+		{
+			"insert into t (a, b) select x + y, 1 from s",
+			"t.a<-s.x,s.y t.b<-",
+		},
+		{
+			"insert into t select x from s",
+			"t.#1<-s.x",
+		},
+		{
+			"insert into t values (1, 2)",
+			"t.#1<- t.#2<-",
+		},
+		{
+			"insert into t (a) values (1), (2)",
+			"t.a<-",
+		},
+		{
+			"insert into t (a) select x from s union select y from u",
+			"t.a<-s.x,u.y",
+		},
+		{
+			"insert into t (a) select s.x from s on duplicate key update b = s.y",
+			"t.a<-s.x t.b<-s.y",
+		},
+		{
+			"insert into t set a = 1, b = 2",
+			"t.a<- t.b<-",
+		},
+		{
+			"update t join s on t.id = s.id set t.a = s.a + s.b, t.c = 1",
+			"t.a<-s.a,s.b t.c<-",
+		},
+		{
+			"create table t2 as select a, b * 2 as c from (select a, b from t) x",
+			"t2.a<-t.a t2.c<-t.b",
+		},
+		{
+			"create view v (x, y) as with c as (select a, b from t) select a, b from c",
+			"v.x<-t.a v.y<-t.b",
+		},
+		{
+			"insert into t (a) with recursive r (n) as (select a from s union all select n + 1 from r) select n from r",
+			"t.a<-s.a",
+		},
+	}
+	for _, c := range cases {
+		res := analyze(t, &Analyzer{}, c.sql)
+		require.Equal(t, c.lineage, lineageStrings(res.Lineage), c.sql)
+	}
+	require.Equal(t, "t", Column{Table: Table{Name: "t"}}.String())
+}
+
+func TestCatalog(t *testing.T) {
+	c := ddl.NewCatalog()
+	stmts, _, err := parser.New().Parse("create database test; use test; create table t (a int, b int); create table s (c int, d int)", "", "")
+	require.NoError(t, err)
+	for _, stmt := range stmts {
+		require.NoError(t, c.Apply(stmt))
+	}
+	a := &Analyzer{InfoSchema: resolver.NewInfoSchema(c.Schemas()...), CurrentDB: "test"}
+
+	res := analyze(t, a, "select * from t, s where c = 1 and a = 2")
+	require.Equal(t, "test.t.a[select|where] test.t.b[select] test.s.c[select|where] test.s.d[select]", columnStrings(res.Columns))
+	require.Equal(t, []string{"a", "b", "c", "d"}, res.Scope.Columns)
+
+	res = analyze(t, a, "select s.* from t join s on b = d")
+	require.Equal(t, "test.t.b[join] test.s.d[select|join] test.s.c[select]", columnStrings(res.Columns))
+
+	res = analyze(t, a, "insert into t select c, d from s")
+	require.Equal(t, "test.t.a<-test.s.c test.t.b<-test.s.d", lineageStrings(res.Lineage))
+
+	res = analyze(t, a, "select a, x from t, u")
+	require.Equal(t, "test.t.a[select] test.u.x[select]", columnStrings(res.Columns))
+}
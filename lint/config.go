@@ -0,0 +1,72 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/pingcap/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Config configures the rules of a Linter, like:
+//
+//	rules:
+//	  select-star:
+//	    disabled: true
+//	  too-many-indexes:
+//	    severity: error
+//	    params:
+//	      max: 8
+type Config struct {
+	// Rules are the configs of the rules keyed by the rule IDs.
+	Rules map[string]RuleConfig `json:"rules" yaml:"rules"`
+}
+
+// RuleConfig configures a rule.
+type RuleConfig struct {
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	// Severity overrides the default severity of the rule if it is not nil.
+	Severity *Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Params are the parameters of the rule.
+	Params map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// ParseConfig parses a config in YAML or JSON, which is a subset of YAML.
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && err != io.EOF {
+		return nil, errors.Trace(err)
+	}
+	return cfg, nil
+}
+
+// decodeParams sets the parameters of the rule, the unknown parameters are
+// errors.
+func (rc RuleConfig) decodeParams(rule Rule) error {
+	if len(rc.Params) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(rc.Params)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return errors.Trace(dec.Decode(rule))
+}
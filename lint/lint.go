@@ -0,0 +1,396 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint checks SQL statements by rules, like the SQL review tools.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/resolver"
+	"github.com/pingcap/errors"
+)
+
+// Severity is the severity of the problems found by a rule.
+type Severity int
+
+// Severity values.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+// String implements fmt.Stringer interface.
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler interface.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if strings.EqualFold(string(text), name) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return errors.Errorf("unknown severity %q", text)
+}
+
+// Category is the category of a rule.
+type Category string
+
+// Category values.
+const (
+	CategoryQuery       Category = "query"
+	CategorySchema      Category = "schema"
+	CategoryNaming      Category = "naming"
+	CategoryPerformance Category = "performance"
+)
+
+// Metadata describes a rule.
+type Metadata struct {
+	// ID is the unique name of the rule, like select-star.
+	ID string
+	// Severity is the default severity of the rule.
+	Severity    Severity
+	Category    Category
+	Description string
+}
+
+// Rule checks statements. A rule with parameters is a pointer to a struct,
+// its exported fields are the parameters decoded from the config as JSON.
+type Rule interface {
+	Metadata() Metadata
+	// Check checks a statement, the problems are reported by ctx.
+	Check(ctx *Context, stmt ast.StmtNode)
+}
+
+// Position is a position in the SQL text.
+type Position struct {
+	// Offset is the byte offset in the text.
+	Offset int `json:"offset"`
+	// Line and Column are 1-based, Column counts bytes.
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Span is a span of the SQL text, End is exclusive.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Result is a problem found by a rule.
+type Result struct {
+	RuleID   string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Category Category `json:"category"`
+	Message  string   `json:"message"`
+	// Stmt is the index of the statement.
+	Stmt int `json:"stmt"`
+	// Span is the span of the reported node, or the statement if the position
+	// of the node is unknown. It is empty if the SQL text is unknown.
+	Span Span `json:"span"`
+}
+
+// Context is the context of a rule checking a statement.
+type Context struct {
+	// InfoSchema is the optional schema catalog.
+	InfoSchema resolver.InfoSchema
+	// CurrentDB is the database of the unqualified table names.
+	CurrentDB string
+
+	sql   string
+	lines []int
+	// start and end are the offsets of the statement.
+	start, end int
+	stmt       int
+	meta       Metadata
+	severity   Severity
+	results    []Result
+}
+
+// Report reports a problem of a node of the statement.
+func (c *Context) Report(node ast.Node, format string, args ...interface{}) {
+	c.results = append(c.results, Result{
+		RuleID:   c.meta.ID,
+		Severity: c.severity,
+		Category: c.meta.Category,
+		Message:  fmt.Sprintf(format, args...),
+		Stmt:     c.stmt,
+		Span:     c.spanOf(node),
+	})
+}
+
+// SchemaName returns the schema of a table name.
+func (c *Context) SchemaName(tn *ast.TableName) string {
+	if tn.Schema.O != "" {
+		return tn.Schema.O
+	}
+	return c.CurrentDB
+}
+
+func (c *Context) position(offset int) Position {
+	line := sort.SearchInts(c.lines, offset+1) - 1
+	return Position{Offset: offset, Line: line + 1, Column: offset - c.lines[line] + 1}
+}
+
+func (c *Context) span(start, end int) Span {
+	return Span{Start: c.position(start), End: c.position(end)}
+}
+
+// spanOf returns the span of a node. The expressions and the select fields
+// have their positions, the names are found by their first occurrences in the
+// statement.
+func (c *Context) spanOf(node ast.Node) Span {
+	if c.sql == "" {
+		return Span{}
+	}
+	if _, ok := node.(ast.StmtNode); !ok {
+		if f, ok := node.(*ast.SelectField); ok && f.Offset > 0 {
+			return c.span(f.Offset, c.termEnd(f.Offset))
+		}
+		if off := node.OriginTextPosition(); off > c.start {
+			return c.span(off, c.termEnd(off))
+		}
+		var name string
+		switch x := node.(type) {
+		case *ast.TableName:
+			name = x.Name.O
+		case *ast.ColumnDef:
+			name = x.Name.Name.O
+		case *ast.ColumnName:
+			name = x.Name.O
+		case *ast.Constraint:
+			name = x.Name
+		}
+		if name != "" {
+			if start, end, ok := c.findName(name); ok {
+				return c.span(start, end)
+			}
+		}
+	}
+	return c.span(c.start, c.end)
+}
+
+// scanner returns a scanner of the text of the statement from offset.
+func (c *Context) scanner(offset int) *parser.Scanner {
+	return parser.NewScanner(c.sql[offset:c.end])
+}
+
+// termEnd returns the end of the term at offset: a token or a qualified name
+// like t.*, and the parenthesized tokens following it like the arguments of a
+// function call.
+func (c *Context) termEnd(offset int) int {
+	s := c.scanner(offset)
+	end := s.NextToken().End.Offset
+	tok := s.NextToken()
+	for tok.Text == "." {
+		if tok = s.NextToken(); tok.Kind == parser.TokenEOF {
+			return offset + end
+		}
+		end = tok.End.Offset
+		tok = s.NextToken()
+	}
+	if tok.Text != "(" {
+		return offset + end
+	}
+	for depth := 1; ; {
+		end = tok.End.Offset
+		tok = s.NextToken()
+		switch {
+		case tok.Kind == parser.TokenEOF:
+			return offset + end
+		case tok.Text == "(":
+			depth++
+		case tok.Text == ")":
+			if depth--; depth == 0 {
+				return offset + tok.End.Offset
+			}
+		}
+	}
+}
+
+// findName returns the span of the first identifier of the name in the
+// statement, the reserved keywords are skipped since they are not identifiers
+// unless quoted.
+func (c *Context) findName(name string) (int, int, bool) {
+	s := c.scanner(c.start)
+	for {
+		tok := s.NextToken()
+		switch tok.Kind {
+		case parser.TokenEOF:
+			return 0, 0, false
+		case parser.TokenKeyword:
+			if v, ok := tok.Value.(string); ok && strings.EqualFold(v, name) && !parser.IsReservedKeyword(v) {
+				return c.start + tok.Start.Offset, c.start + tok.End.Offset, true
+			}
+		case parser.TokenIdentifier, parser.TokenQuotedIdentifier:
+			if v, ok := tok.Value.(string); ok && strings.EqualFold(v, name) {
+				return c.start + tok.Start.Offset, c.start + tok.End.Offset, true
+			}
+		}
+	}
+}
+
+type enabledRule struct {
+	rule     Rule
+	severity Severity
+}
+
+// Linter checks statements by rules.
+type Linter struct {
+	// InfoSchema is the optional schema catalog, like a ddl.Catalog.
+	InfoSchema resolver.InfoSchema
+	// CurrentDB is the database of the unqualified table names, it is changed
+	// by the USE statements.
+	CurrentDB string
+
+	rules []enabledRule
+}
+
+// NewLinter creates a Linter of the rules configured by the config, all the
+// rules are enabled with their default severities if config is nil. It returns
+// an error if the config has an unknown rule or invalid parameters.
+func NewLinter(rules []Rule, config *Config) (*Linter, error) {
+	l := &Linter{}
+	ids := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		meta := rule.Metadata()
+		if ids[meta.ID] {
+			return nil, errors.Errorf("duplicate rule %s", meta.ID)
+		}
+		ids[meta.ID] = true
+		severity := meta.Severity
+		if config != nil {
+			if rc, ok := config.Rules[meta.ID]; ok {
+				if rc.Disabled {
+					continue
+				}
+				if rc.Severity != nil {
+					severity = *rc.Severity
+				}
+				if err := rc.decodeParams(rule); err != nil {
+					return nil, errors.Annotatef(err, "rule %s", meta.ID)
+				}
+			}
+		}
+		l.rules = append(l.rules, enabledRule{rule: rule, severity: severity})
+	}
+	if config != nil {
+		for id := range config.Rules {
+			if !ids[id] {
+				return nil, errors.Errorf("unknown rule %s", id)
+			}
+		}
+	}
+	return l, nil
+}
+
+// Rules returns the metadata of the enabled rules.
+func (l *Linter) Rules() []Metadata {
+	res := make([]Metadata, 0, len(l.rules))
+	for _, r := range l.rules {
+		res = append(res, r.rule.Metadata())
+	}
+	return res
+}
+
+// Lint parses the SQL text and checks the statements.
+func (l *Linter) Lint(sql string) ([]Result, error) {
+	res, err := parser.New().ParseIncremental(sql)
+	if err != nil {
+		return nil, err
+	}
+	return l.LintParsed(res), nil
+}
+
+// LintParsed checks the statements of a parse result, the results are ordered
+// by the statements and their positions.
+func (l *Linter) LintParsed(res *parser.ParseResult) []Result {
+	lines := []int{0}
+	for i := 0; i < len(res.SQL); i++ {
+		if res.SQL[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	var results []Result
+	currentDB := l.CurrentDB
+	for i, s := range res.Stmts {
+		ctx := &Context{InfoSchema: l.InfoSchema, CurrentDB: currentDB, sql: res.SQL, lines: lines, stmt: i}
+		ctx.start, ctx.end = stmtSpan(res.SQL, s)
+		results = append(results, l.check(ctx, s.Stmt)...)
+		if use, ok := s.Stmt.(*ast.UseStmt); ok {
+			currentDB = use.DBName
+		}
+	}
+	return results
+}
+
+// LintStmts checks statements without their text, the spans of the results
+// are empty.
+func (l *Linter) LintStmts(stmts []ast.StmtNode) []Result {
+	var results []Result
+	currentDB := l.CurrentDB
+	for i, stmt := range stmts {
+		ctx := &Context{InfoSchema: l.InfoSchema, CurrentDB: currentDB, stmt: i}
+		results = append(results, l.check(ctx, stmt)...)
+		if use, ok := stmt.(*ast.UseStmt); ok {
+			currentDB = use.DBName
+		}
+	}
+	return results
+}
+
+func (l *Linter) check(ctx *Context, stmt ast.StmtNode) []Result {
+	for _, r := range l.rules {
+		ctx.meta, ctx.severity = r.rule.Metadata(), r.severity
+		r.rule.Check(ctx, stmt)
+	}
+	sort.SliceStable(ctx.results, func(i, j int) bool {
+		return ctx.results[i].Span.Start.Offset < ctx.results[j].Span.Start.Offset
+	})
+	return ctx.results
+}
+
+// stmtSpan returns the span of a statement without the spaces and the ';'
+// around it.
+func stmtSpan(sql string, s parser.ParsedStmt) (int, int) {
+	start, end := s.Start.Offset, s.End.Offset
+	for start < end && isSpace(sql[start]) {
+		start++
+	}
+	for end > start && (isSpace(sql[end-1]) || sql[end-1] == ';') {
+		end--
+	}
+	return start, end
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,279 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/ddl"
+	. "github.com/daiguadaidai/parser/lint"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+// lint returns the results as "rule: text of the span".
+func lint(t *testing.T, l *Linter, sql string) []string {
+	results, err := l.Lint(sql)
+	require.NoError(t, err, sql)
+	res := make([]string, 0, len(results))
+	for _, r := range results {
+		res = append(res, fmt.Sprintf("%s: %s", r.RuleID, sql[r.Span.Start.Offset:r.Span.End.Offset]))
+	}
+	return res
+}
+
+func TestBuiltinRules(t *testing.T) {
+	l, err := NewLinter(BuiltinRules(), nil)
+	require.NoError(t, err)
+	cases := []struct {
+		sql    string
+		expect []string
+	}{
+		{"select a from t where exists (select * from u)", nil},
+		{"select a, t.* from t", []string{"select-star: t.*"}},
+		{"select * from (select * from t) s", []string{"select-star: *", "select-star: *"}},
+		{"update t set a = 1", []string{"dml-without-where: update t set a = 1"}},
+		{"delete from t", []string{"dml-without-where: delete from t"}},
+		{"update t set a = 1 where b = 2; delete from t where a = 1", nil},
+		{"create table t (a int) charset utf8mb4", []string{"table-without-primary-key: t"}},
+		{"create table t (a int primary key) collate utf8mb4_bin", nil},
+		{"create table t (a int, primary key (a)) charset utf8mb4", nil},
+		{"create table t like u", nil},
+		{"create table t (a int primary key)", []string{"implicit-charset: t"}},
+		{"create database d", []string{"implicit-charset: create database d"}},
+		{"create database d charset utf8mb4", nil},
+		{
+			"create table t (id int primary key, unit_price double, price_cents bigint, fee float) charset utf8mb4",
+			[]string{"float-for-money: unit_price", "float-for-money: fee"},
+		},
+		{"alter table t add column total_amount double", []string{"float-for-money: total_amount"}},
+		{
+			"create table t (a int primary key, b int unique, c int, d int, e int, key(c), key(d), index(e), unique(c, d)) charset utf8mb4",
+			[]string{"too-many-indexes: t"},
+		},
+		{"create table t (a int primary key, b int, key(b)) charset utf8mb4", nil},
+		{
+			"create table `order` (id int primary key, `desc` int, constraint `check` unique (`desc`)) charset utf8mb4",
+			[]string{
+				"reserved-word-identifier: `order`",
+				"reserved-word-identifier: `desc`",
+				"reserved-word-identifier: `check`",
+			},
+		},
+		{"create table `status` (id int primary key) charset utf8mb4", nil},
+		{"create table `rank` (`window` int primary key) charset utf8mb4", []string{
+			"reserved-word-identifier: `rank`",
+			"reserved-word-identifier: `window`",
+		}},
+		{"alter table t rename to `table`, rename column a to `select`", []string{
+			"reserved-word-identifier: `table`",
+			"reserved-word-identifier: `select`",
+		}},
+		{"rename table t to `group`", []string{"reserved-word-identifier: `group`"}},
+		{"select a from t order by b, rand()", []string{"order-by-rand: rand()"}},
+		{"select a from t order by rand(1) desc", []string{"order-by-rand: rand(1)"}},
+		{"select rand() from t order by a", nil},
+		{"select count(*) from t group by rand()", nil},
+		{"select row_number() over (order by rand()) from t", []string{"order-by-rand: rand()"}},
+		{"delete from t where a > 0 order by rand() limit 1", []string{"order-by-rand: rand()"}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expect, nilIfEmpty(lint(t, l, c.sql)), c.sql)
+	}
+}
+
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+func TestResults(t *testing.T) {
+	l, err := NewLinter(BuiltinRules(), nil)
+	require.NoError(t, err)
+	require.Len(t, l.Rules(), len(BuiltinRules()))
+
+	sql := "select 1;\n  update t set a = 1;\nselect *\n  from t\n  order by rand();"
+	results, err := l.Lint(sql)
+	require.NoError(t, err)
+	require.Equal(t, []Result{
+		{
+			RuleID: "dml-without-where", Severity: SeverityError, Category: CategoryQuery,
+			Message: "UPDATE without WHERE", Stmt: 1,
+			Span: Span{Start: Position{Offset: 12, Line: 2, Column: 3}, End: Position{Offset: 30, Line: 2, Column: 21}},
+		},
+		{
+			RuleID: "select-star", Severity: SeverityWarning, Category: CategoryQuery,
+			Message: "SELECT * is used", Stmt: 2,
+			Span: Span{Start: Position{Offset: 39, Line: 3, Column: 8}, End: Position{Offset: 40, Line: 3, Column: 9}},
+		},
+		{
+			RuleID: "order-by-rand", Severity: SeverityWarning, Category: CategoryPerformance,
+			Message: "ORDER BY RAND() is used", Stmt: 2,
+			Span: Span{Start: Position{Offset: 61, Line: 5, Column: 12}, End: Position{Offset: 67, Line: 5, Column: 18}},
+		},
+	}, results)
+
+	data, err := json.Marshal(results[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"rule": "dml-without-where", "severity": "error", "category": "query",
+		"message": "UPDATE without WHERE", "stmt": 1,
+		"span": {"start": {"offset": 12, "line": 2, "column": 3}, "end": {"offset": 30, "line": 2, "column": 21}}
+	}`, string(data))
+
+	_, err = l.Lint("select * from")
+	require.Error(t, err)
+
+	// The statements without their text have empty spans.
+	stmts, _, err := parser.New().Parse("select * from t; delete from t", "", "")
+	require.NoError(t, err)
+	results = l.LintStmts(stmts)
+	require.Len(t, results, 2)
+	require.Equal(t, "select-star", results[0].RuleID)
+	require.Equal(t, 0, results[0].Stmt)
+	require.Equal(t, "dml-without-where", results[1].RuleID)
+	require.Equal(t, 1, results[1].Stmt)
+	require.Equal(t, Span{}, results[1].Span)
+}
+
+func TestConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+rules:
+  select-star:
+    disabled: true
+  dml-without-where:
+    severity: warning
+  too-many-indexes:
+    severity: error
+    params:
+      max: 1
+  float-for-money:
+    params:
+      names: [rate]
+`))
+	require.NoError(t, err)
+	l, err := NewLinter(BuiltinRules(), cfg)
+	require.NoError(t, err)
+	require.Len(t, l.Rules(), len(BuiltinRules())-1)
+	for _, meta := range l.Rules() {
+		require.NotEqual(t, "select-star", meta.ID)
+	}
+
+	results, err := l.Lint("select * from t; delete from t; create table t (a int primary key, price double, rate float, key (a)) charset utf8mb4")
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, "dml-without-where", results[0].RuleID)
+	require.Equal(t, SeverityWarning, results[0].Severity)
+	require.Equal(t, "too-many-indexes", results[1].RuleID)
+	require.Equal(t, SeverityError, results[1].Severity)
+	require.Equal(t, "table t has 2 indexes, more than 1", results[1].Message)
+	require.Equal(t, "float-for-money", results[2].RuleID)
+	require.Equal(t, "column rate of money is FLOAT", results[2].Message)
+
+	// JSON is also accepted.
+	cfg, err = ParseConfig([]byte(`{"rules": {"order-by-rand": {"severity": "info"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, SeverityInfo, *cfg.Rules["order-by-rand"].Severity)
+	cfg, err = ParseConfig(nil)
+	require.NoError(t, err)
+	require.Empty(t, cfg.Rules)
+
+	for _, data := range []string{
+		`rules: {select-star: {severity: fatal}}`,
+		`rules: {select-star: {enabled: false}}`,
+		`rule: {}`,
+	} {
+		_, err = ParseConfig([]byte(data))
+		require.Error(t, err, data)
+	}
+	for _, data := range []string{
+		`rules: {no-such-rule: {disabled: true}}`,
+		`rules: {too-many-indexes: {params: {maximum: 3}}}`,
+		`rules: {too-many-indexes: {params: {max: many}}}`,
+	} {
+		cfg, err = ParseConfig([]byte(data))
+		require.NoError(t, err, data)
+		_, err = NewLinter(BuiltinRules(), cfg)
+		require.Error(t, err, data)
+	}
+
+	_, err = NewLinter([]Rule{&SelectStar{}, &SelectStar{}}, nil)
+	require.EqualError(t, err, "duplicate rule select-star")
+}
+
+func TestInfoSchema(t *testing.T) {
+	catalog := ddl.NewCatalog()
+	stmts, _, err := parser.New().Parse(`
+		create database d1; create database d2;
+		create table d1.t (a int primary key, b int, c int, key (b), key (c));
+		create table d2.t (a int, b int, key (b))`, "", "")
+	require.NoError(t, err)
+	for _, stmt := range stmts {
+		require.NoError(t, catalog.Apply(stmt))
+	}
+
+	cfg, err := ParseConfig([]byte(`rules: {too-many-indexes: {params: {max: 3}}}`))
+	require.NoError(t, err)
+	l, err := NewLinter([]Rule{&TooManyIndexes{}}, cfg)
+	require.NoError(t, err)
+	l.InfoSchema = catalog
+	l.CurrentDB = "d1"
+
+	require.Equal(t, []string{"too-many-indexes: t"}, lint(t, l, "create index ia on t (a)"))
+	require.Empty(t, lint(t, l, "create index ia on d2.t (a)"))
+	require.Empty(t, lint(t, l, "alter table t add index ia (a), drop index b"))
+	require.Equal(t, []string{"too-many-indexes: t"}, lint(t, l, "use d2; alter table t add unique (a), add column d int unique, add index (a, b)"))
+	require.Empty(t, lint(t, l, "use d2; alter table t add unique (a), add column d int unique"))
+	require.Empty(t, lint(t, l, "create index ia on no_such_table (a)"))
+
+	// Only the new tables are checked without the schema catalog.
+	l.InfoSchema = nil
+	require.Empty(t, lint(t, l, "create index ia on t (a)"))
+}
+
+// tableComment is a custom rule requiring the table comments.
+type tableComment struct{}
+
+func (tableComment) Metadata() Metadata {
+	return Metadata{ID: "table-comment", Severity: SeverityInfo, Category: CategorySchema}
+}
+
+func (tableComment) Check(ctx *Context, stmt ast.StmtNode) {
+	if x, ok := stmt.(*ast.CreateTableStmt); ok {
+		for _, op := range x.Options {
+			if op.Tp == ast.TableOptionComment {
+				return
+			}
+		}
+		ctx.Report(x.Table, "table %s.%s has no comment", ctx.SchemaName(x.Table), x.Table.Name.O)
+	}
+}
+
+func TestCustomRule(t *testing.T) {
+	l, err := NewLinter([]Rule{tableComment{}}, nil)
+	require.NoError(t, err)
+	results, err := l.Lint("create table t (a int); use d; create table `t 2` (a int) comment 'T2'; create table `t 3` (a int)")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, "table .t has no comment", results[0].Message)
+	require.Equal(t, "table d.t 3 has no comment", results[1].Message)
+	require.Equal(t, SeverityInfo, results[1].Severity)
+	require.Equal(t, 3, results[1].Stmt)
+}
@@ -0,0 +1,398 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+)
+
+// BuiltinRules returns the built-in rules with their default parameters.
+func BuiltinRules() []Rule {
+	return []Rule{
+		&SelectStar{},
+		&DMLWithoutWhere{},
+		&TableWithoutPrimaryKey{},
+		&ImplicitCharset{},
+		&FloatForMoney{Names: []string{"price", "amount", "money", "cost", "balance", "fee", "salary"}},
+		&TooManyIndexes{Max: 5},
+		&ReservedWordIdentifier{},
+		&OrderByRand{},
+	}
+}
+
+// SelectStar reports SELECT *, but not in EXISTS subqueries.
+type SelectStar struct{}
+
+// Metadata implements Rule interface.
+func (*SelectStar) Metadata() Metadata {
+	return Metadata{
+		ID:          "select-star",
+		Severity:    SeverityWarning,
+		Category:    CategoryQuery,
+		Description: "SELECT * depends on the columns of the tables, list the columns explicitly.",
+	}
+}
+
+// Check implements Rule interface.
+func (*SelectStar) Check(ctx *Context, stmt ast.StmtNode) {
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.ExistsSubqueryExpr:
+			return false
+		case *ast.SelectField:
+			if x.WildCard != nil {
+				ctx.Report(x, "SELECT * is used")
+			}
+		}
+		return true
+	})
+}
+
+// DMLWithoutWhere reports UPDATE and DELETE without WHERE.
+type DMLWithoutWhere struct{}
+
+// Metadata implements Rule interface.
+func (*DMLWithoutWhere) Metadata() Metadata {
+	return Metadata{
+		ID:          "dml-without-where",
+		Severity:    SeverityError,
+		Category:    CategoryQuery,
+		Description: "UPDATE and DELETE without WHERE change all the rows.",
+	}
+}
+
+// Check implements Rule interface.
+func (*DMLWithoutWhere) Check(ctx *Context, stmt ast.StmtNode) {
+	switch x := stmt.(type) {
+	case *ast.UpdateStmt:
+		if x.Where == nil {
+			ctx.Report(x, "UPDATE without WHERE")
+		}
+	case *ast.DeleteStmt:
+		if x.Where == nil {
+			ctx.Report(x, "DELETE without WHERE")
+		}
+	}
+}
+
+// TableWithoutPrimaryKey reports the tables created without primary keys.
+type TableWithoutPrimaryKey struct{}
+
+// Metadata implements Rule interface.
+func (*TableWithoutPrimaryKey) Metadata() Metadata {
+	return Metadata{
+		ID:          "table-without-primary-key",
+		Severity:    SeverityWarning,
+		Category:    CategorySchema,
+		Description: "A table without a primary key has an implicit row ID, and is hard to replicate.",
+	}
+}
+
+// Check implements Rule interface.
+func (*TableWithoutPrimaryKey) Check(ctx *Context, stmt ast.StmtNode) {
+	x, ok := stmt.(*ast.CreateTableStmt)
+	if !ok || x.ReferTable != nil || len(x.Cols) == 0 {
+		return
+	}
+	for _, c := range x.Constraints {
+		if c.Tp == ast.ConstraintPrimaryKey {
+			return
+		}
+	}
+	for _, col := range x.Cols {
+		for _, op := range col.Options {
+			if op.Tp == ast.ColumnOptionPrimaryKey {
+				return
+			}
+		}
+	}
+	ctx.Report(x.Table, "table %s has no primary key", x.Table.Name.O)
+}
+
+// ImplicitCharset reports the tables and the databases created without
+// charsets or collations.
+type ImplicitCharset struct{}
+
+// Metadata implements Rule interface.
+func (*ImplicitCharset) Metadata() Metadata {
+	return Metadata{
+		ID:          "implicit-charset",
+		Severity:    SeverityWarning,
+		Category:    CategorySchema,
+		Description: "The implicit charset depends on the database or the server, specify it explicitly.",
+	}
+}
+
+// Check implements Rule interface.
+func (*ImplicitCharset) Check(ctx *Context, stmt ast.StmtNode) {
+	switch x := stmt.(type) {
+	case *ast.CreateTableStmt:
+		if x.ReferTable != nil {
+			return
+		}
+		for _, op := range x.Options {
+			if op.Tp == ast.TableOptionCharset || op.Tp == ast.TableOptionCollate {
+				return
+			}
+		}
+		ctx.Report(x.Table, "table %s has no explicit charset", x.Table.Name.O)
+	case *ast.CreateDatabaseStmt:
+		for _, op := range x.Options {
+			if op.Tp == ast.DatabaseOptionCharset || op.Tp == ast.DatabaseOptionCollate {
+				return
+			}
+		}
+		ctx.Report(x, "database %s has no explicit charset", x.Name.O)
+	}
+}
+
+// FloatForMoney reports the FLOAT and DOUBLE columns whose names contain the
+// words of money, which should be DECIMAL.
+type FloatForMoney struct {
+	// Names are the words of money in the column names.
+	Names []string `json:"names"`
+}
+
+// Metadata implements Rule interface.
+func (*FloatForMoney) Metadata() Metadata {
+	return Metadata{
+		ID:          "float-for-money",
+		Severity:    SeverityWarning,
+		Category:    CategorySchema,
+		Description: "FLOAT and DOUBLE are approximate, use DECIMAL for money.",
+	}
+}
+
+// Check implements Rule interface.
+func (r *FloatForMoney) Check(ctx *Context, stmt ast.StmtNode) {
+	for _, col := range newColumns(stmt) {
+		if tp := col.Tp.GetType(); tp != mysql.TypeFloat && tp != mysql.TypeDouble {
+			continue
+		}
+		name := col.Name.Name.L
+		for _, word := range r.Names {
+			if strings.Contains(name, strings.ToLower(word)) {
+				ctx.Report(col, "column %s of money is %s", col.Name.Name.O, strings.ToUpper(col.Tp.CompactStr()))
+				break
+			}
+		}
+	}
+}
+
+// newColumns returns the columns defined by CREATE TABLE or ALTER TABLE.
+func newColumns(stmt ast.StmtNode) []*ast.ColumnDef {
+	switch x := stmt.(type) {
+	case *ast.CreateTableStmt:
+		return x.Cols
+	case *ast.AlterTableStmt:
+		var cols []*ast.ColumnDef
+		for _, spec := range x.Specs {
+			cols = append(cols, spec.NewColumns...)
+		}
+		return cols
+	}
+	return nil
+}
+
+// TooManyIndexes reports the tables with too many indexes, the indexes added
+// to the existing tables are checked if there is a schema catalog.
+type TooManyIndexes struct {
+	// Max is the maximum number of the indexes of a table.
+	Max int `json:"max"`
+}
+
+// Metadata implements Rule interface.
+func (*TooManyIndexes) Metadata() Metadata {
+	return Metadata{
+		ID:          "too-many-indexes",
+		Severity:    SeverityWarning,
+		Category:    CategorySchema,
+		Description: "Every index slows down the writes and takes space.",
+	}
+}
+
+// Check implements Rule interface.
+func (r *TooManyIndexes) Check(ctx *Context, stmt ast.StmtNode) {
+	var tn *ast.TableName
+	n := 0
+	switch x := stmt.(type) {
+	case *ast.CreateTableStmt:
+		tn, n = x.Table, countIndexes(x.Cols, x.Constraints)
+	case *ast.CreateIndexStmt:
+		tn, n = x.Table, 1+existingIndexes(ctx, x.Table)
+	case *ast.AlterTableStmt:
+		tn = x.Table
+		added := 0
+		for _, spec := range x.Specs {
+			switch spec.Tp {
+			case ast.AlterTableAddColumns:
+				added += countIndexes(spec.NewColumns, spec.NewConstraints)
+			case ast.AlterTableAddConstraint:
+				added += countIndexes(nil, []*ast.Constraint{spec.Constraint})
+			case ast.AlterTableDropIndex, ast.AlterTableDropPrimaryKey:
+				added--
+			}
+		}
+		if added <= 0 {
+			return
+		}
+		n = added + existingIndexes(ctx, x.Table)
+	default:
+		return
+	}
+	if n > r.Max {
+		ctx.Report(tn, "table %s has %d indexes, more than %d", tn.Name.O, n, r.Max)
+	}
+}
+
+func countIndexes(cols []*ast.ColumnDef, constraints []*ast.Constraint) int {
+	n := 0
+	for _, col := range cols {
+		for _, op := range col.Options {
+			if op.Tp == ast.ColumnOptionPrimaryKey || op.Tp == ast.ColumnOptionUniqKey {
+				n++
+			}
+		}
+	}
+	for _, c := range constraints {
+		switch c.Tp {
+		case ast.ConstraintPrimaryKey, ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq,
+			ast.ConstraintUniqKey, ast.ConstraintUniqIndex, ast.ConstraintFulltext:
+			n++
+		}
+	}
+	return n
+}
+
+// existingIndexes returns the number of the indexes of a table in the schema
+// catalog.
+func existingIndexes(ctx *Context, tn *ast.TableName) int {
+	if ctx.InfoSchema == nil {
+		return 0
+	}
+	tbl, ok := ctx.InfoSchema.TableByName(model.NewCIStr(ctx.SchemaName(tn)), tn.Name)
+	if !ok {
+		return 0
+	}
+	n := len(tbl.Indices)
+	if tbl.PKIsHandle {
+		n++
+	}
+	return n
+}
+
+// ReservedWordIdentifier reports the databases, tables, columns, indexes and
+// constraints named by reserved keywords.
+type ReservedWordIdentifier struct{}
+
+// Metadata implements Rule interface.
+func (*ReservedWordIdentifier) Metadata() Metadata {
+	return Metadata{
+		ID:          "reserved-word-identifier",
+		Severity:    SeverityWarning,
+		Category:    CategoryNaming,
+		Description: "The identifiers of reserved keywords must always be quoted.",
+	}
+}
+
+// Check implements Rule interface.
+func (*ReservedWordIdentifier) Check(ctx *Context, stmt ast.StmtNode) {
+	check := func(node ast.Node, kind, name string) {
+		if parser.IsReservedKeyword(name) {
+			ctx.Report(node, "%s name %s is a reserved keyword", kind, name)
+		}
+	}
+	checkTable := func(tn *ast.TableName) {
+		check(tn, "table", tn.Name.O)
+	}
+	checkColumns := func(cols []*ast.ColumnDef) {
+		for _, col := range cols {
+			check(col, "column", col.Name.Name.O)
+		}
+	}
+	checkConstraints := func(constraints []*ast.Constraint) {
+		for _, c := range constraints {
+			if c.Name != "" {
+				check(c, "constraint", c.Name)
+			}
+		}
+	}
+	switch x := stmt.(type) {
+	case *ast.CreateDatabaseStmt:
+		check(x, "database", x.Name.O)
+	case *ast.CreateTableStmt:
+		checkTable(x.Table)
+		checkColumns(x.Cols)
+		checkConstraints(x.Constraints)
+	case *ast.CreateViewStmt:
+		checkTable(x.ViewName)
+		for _, col := range x.Cols {
+			check(x, "column", col.O)
+		}
+	case *ast.CreateIndexStmt:
+		check(x, "index", x.IndexName)
+	case *ast.AlterTableStmt:
+		for _, spec := range x.Specs {
+			checkColumns(spec.NewColumns)
+			checkConstraints(spec.NewConstraints)
+			switch spec.Tp {
+			case ast.AlterTableAddConstraint:
+				checkConstraints([]*ast.Constraint{spec.Constraint})
+			case ast.AlterTableRenameTable:
+				checkTable(spec.NewTable)
+			case ast.AlterTableRenameColumn:
+				check(spec.NewColumnName, "column", spec.NewColumnName.Name.O)
+			case ast.AlterTableRenameIndex:
+				check(x, "index", spec.ToKey.O)
+			}
+		}
+	case *ast.RenameTableStmt:
+		for _, t2t := range x.TableToTables {
+			checkTable(t2t.NewTable)
+		}
+	}
+}
+
+// OrderByRand reports ORDER BY RAND(), including the ORDER BY of the windows.
+// GROUP BY RAND() is not reported.
+type OrderByRand struct{}
+
+// Metadata implements Rule interface.
+func (*OrderByRand) Metadata() Metadata {
+	return Metadata{
+		ID:          "order-by-rand",
+		Severity:    SeverityWarning,
+		Category:    CategoryPerformance,
+		Description: "ORDER BY RAND() sorts all the rows in a temporary table.",
+	}
+}
+
+// Check implements Rule interface.
+func (*OrderByRand) Check(ctx *Context, stmt ast.StmtNode) {
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if order, ok := n.(*ast.OrderByClause); ok {
+			for _, by := range order.Items {
+				if fn, ok := by.Expr.(*ast.FuncCallExpr); ok && fn.FnName.L == ast.Rand {
+					ctx.Report(fn, "ORDER BY RAND() is used")
+				}
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,307 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/mysql"
+)
+
+// document is a SQL document opened by the client.
+type document struct {
+	uri     string
+	version int
+	text    string
+	// lines are the offsets of the line starts.
+	lines []int
+
+	// The result of analyze.
+	analyzed bool
+	stmts    []statement
+	diags    []Diagnostic
+}
+
+// statement is a statement of a document.
+type statement struct {
+	node ast.StmtNode
+	// start and end are the offsets of the statement text, the end includes
+	// the ';' if any.
+	start, end int
+	tokens     []parser.Token
+}
+
+func newDocument(uri string, version int, text string) *document {
+	d := &document{uri: uri, version: version}
+	d.setText(text)
+	return d
+}
+
+func (d *document) setText(text string) {
+	d.text = text
+	d.lines = d.lines[:0]
+	d.lines = append(d.lines, 0)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	d.analyzed = false
+	d.stmts = nil
+	d.diags = nil
+}
+
+// applyChange applies a change sent by the client.
+func (d *document) applyChange(change TextDocumentContentChangeEvent) {
+	if change.Range == nil {
+		d.setText(change.Text)
+		return
+	}
+	start, end := d.offset(change.Range.Start), d.offset(change.Range.End)
+	if end < start {
+		start, end = end, start
+	}
+	d.setText(d.text[:start] + change.Text + d.text[end:])
+}
+
+// position converts a byte offset to a position.
+func (d *document) position(offset int) Position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	line := sort.Search(len(d.lines), func(i int) bool {
+		return d.lines[i] > offset
+	}) - 1
+	char := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		char += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: char}
+}
+
+// offset converts a position to a byte offset, a position beyond the end of
+// the line is clamped to the end of the line.
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset := d.lines[pos.Line]
+	lineEnd := len(d.text)
+	if pos.Line+1 < len(d.lines) {
+		lineEnd = d.lines[pos.Line+1] - 1
+	}
+	for char := 0; char < pos.Character && offset < lineEnd; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		char += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+func (d *document) rangeOf(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// analyze splits the document into statements by ';' and parses them one by
+// one, so all the statements with errors are reported.
+func (d *document) analyze(sqlMode mysql.SQLMode) {
+	if d.analyzed {
+		return
+	}
+	d.analyzed = true
+	it, err := parser.Tokenize(d.text, parser.SQLModeParam(sqlMode))
+	if err != nil {
+		d.diags = append(d.diags, Diagnostic{Range: d.rangeOf(0, 0), Severity: SeverityError, Source: source, Message: err.Error()})
+		return
+	}
+	var tokens []parser.Token
+	start := 0
+	flush := func(end int) {
+		if len(tokens) > 0 {
+			d.parseStatement(sqlMode, start, end, tokens)
+		}
+		tokens, start = nil, end
+	}
+	for it.Next() {
+		tok := it.Token()
+		tokens = append(tokens, tok)
+		if tok.Kind == parser.TokenOperator && tok.Text == ";" {
+			flush(tok.End.Offset)
+		}
+	}
+	flush(len(d.text))
+}
+
+func (d *document) parseStatement(sqlMode mysql.SQLMode, start, end int, tokens []parser.Token) {
+	p := parser.New()
+	p.EnableWindowFunc(true)
+	p.SetSQLMode(sqlMode)
+	text := d.text[start:end]
+	stmts, warns, err := p.Parse(text, "", "")
+	stmtRange := d.rangeOf(tokens[0].Start.Offset, tokens[len(tokens)-1].End.Offset)
+	if err != nil {
+		d.diags = append(d.diags, Diagnostic{
+			Range:    d.errorRange(start, text, tokens, err),
+			Severity: SeverityError,
+			Source:   source,
+			Message:  errorMessage(err),
+		})
+		return
+	}
+	for _, warn := range warns {
+		d.diags = append(d.diags, Diagnostic{Range: stmtRange, Severity: SeverityWarning, Source: source, Message: warn.Error()})
+	}
+	for _, stmt := range stmts {
+		d.stmts = append(d.stmts, statement{node: stmt, start: start, end: end, tokens: tokens})
+	}
+}
+
+var errPosPattern = regexp.MustCompile(`line (\d+) column (\d+)`)
+
+// errorRange returns the range of the token where the parser reports the
+// error. The scanner reports the line and the column after the token, the
+// column of the first line is the byte offset and the columns of the other
+// lines are 1-based.
+func (d *document) errorRange(start int, text string, tokens []parser.Token, err error) Range {
+	m := errPosPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return d.rangeOf(tokens[0].Start.Offset, tokens[len(tokens)-1].End.Offset)
+	}
+	line, _ := strconv.Atoi(m[1])
+	col, _ := strconv.Atoi(m[2])
+	offset := 0
+	for i := 1; i < line && offset < len(text); i++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			offset = len(text)
+			break
+		}
+		offset += next + 1
+	}
+	if line > 1 {
+		col--
+	}
+	end := start + offset + col
+	if end > start+len(text) {
+		end = start + len(text)
+	}
+	for _, tok := range tokens {
+		if tok.End.Offset == end {
+			return d.rangeOf(tok.Start.Offset, end)
+		}
+	}
+	return d.rangeOf(end, end)
+}
+
+// errorMessage removes the position from the message of a syntax error, which
+// is reported by the range of the diagnostic.
+func errorMessage(err error) string {
+	msg := err.Error()
+	if m := errPosPattern.FindStringSubmatch(msg); m != nil {
+		msg = strings.Replace(msg, m[0]+" ", "", 1)
+	}
+	return strings.TrimSpace(msg)
+}
+
+// hasErrors returns whether any statement fails to parse.
+func (d *document) hasErrors() bool {
+	for _, diag := range d.diags {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// hasComments returns whether the document has comments, which are lost when
+// the statements are restored.
+func (d *document) hasComments() bool {
+	text := d.text
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\'', '"', '`':
+			for i++; i < len(text) && text[i] != c; i++ {
+				if text[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+		case '#':
+			return true
+		case '/':
+			if i+1 < len(text) && text[i+1] == '*' {
+				return true
+			}
+		case '-':
+			// A comment starts with "-- ", or "--" followed by a control
+			// character or the end.
+			if i+1 < len(text) && text[i+1] == '-' && (i+2 == len(text) || text[i+2] <= ' ') {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// statementAt returns the statement containing the offset, or nil.
+func (d *document) statementAt(offset int) *statement {
+	for i := range d.stmts {
+		if s := &d.stmts[i]; s.start <= offset && offset <= s.end {
+			return s
+		}
+	}
+	return nil
+}
+
+// tokenAt returns the token containing the offset.
+func (d *document) tokenAt(sqlMode mysql.SQLMode, offset int) (parser.Token, bool) {
+	it, err := parser.Tokenize(d.text, parser.SQLModeParam(sqlMode))
+	if err != nil {
+		return parser.Token{}, false
+	}
+	for it.Next() {
+		tok := it.Token()
+		if tok.Start.Offset > offset {
+			break
+		}
+		if offset < tok.End.Offset || (offset == tok.End.Offset && tok.Kind != parser.TokenOperator) {
+			return tok, true
+		}
+	}
+	return parser.Token{}, false
+}
+
+// nameRange returns the range of the first identifier token matching name in
+// the statement, or the range of the statement.
+func (d *document) nameRange(s *statement, name string) Range {
+	for _, tok := range s.tokens {
+		if v, ok := tok.Value.(string); ok && tok.Kind != parser.TokenString && strings.EqualFold(v, name) {
+			return d.rangeOf(tok.Start.Offset, tok.End.Offset)
+		}
+	}
+	return d.stmtRange(s)
+}
+
+func (d *document) stmtRange(s *statement) Range {
+	return d.rangeOf(s.tokens[0].Start.Offset, s.tokens[len(s.tokens)-1].End.Offset)
+}
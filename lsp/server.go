@@ -0,0 +1,480 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lsp implements a Language Server Protocol server for SQL on top of
+// the parser. It provides the diagnostics of the syntax errors and warnings,
+// the document formatting by Restore, the document symbols of CREATE TABLE,
+// VIEW and INDEX statements, the keyword completion and the hover of keywords
+// and the tables created in the document.
+//
+// Like the parser, a driver of the value expressions such as test_driver must
+// be imported by the program.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/pingcap/errors"
+)
+
+// source is the source of the diagnostics.
+const source = "sql"
+
+// ErrExitWithoutShutdown is returned by Serve if the client sends the exit
+// notification before the shutdown request.
+var ErrExitWithoutShutdown = errors.New("exit notification before shutdown request")
+
+// Server is a Language Server Protocol server. It's not safe for concurrent use,
+// and serves one client by Serve.
+type Server struct {
+	sqlMode mysql.SQLMode
+	docs    map[string]*document
+	w       io.Writer
+
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer returns a server which parses the documents in the SQL mode.
+func NewServer(sqlMode mysql.SQLMode) *Server {
+	return &Server{sqlMode: sqlMode, docs: make(map[string]*document)}
+}
+
+// Serve reads the requests from r and writes the responses and notifications
+// to w until the exit notification or the end of r.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+	for {
+		data, err := readMessage(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if err := s.reply(nil, nil, &ResponseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		result, err := s.handle(&msg)
+		if msg.isNotification() {
+			// The errors of the notifications can't be replied.
+			continue
+		}
+		var respErr *ResponseError
+		if err != nil {
+			var ok bool
+			if respErr, ok = err.(*ResponseError); !ok {
+				respErr = &ResponseError{Code: codeInternalError, Message: err.Error()}
+			}
+			result = nil
+		}
+		if err := s.reply(msg.ID, result, respErr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, respErr *ResponseError) error {
+	msg := &message{ID: id, Error: respErr}
+	if id == nil {
+		null := json.RawMessage("null")
+		msg.ID = &null
+	}
+	if respErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		msg.Result = data
+	}
+	return writeMessage(s.w, msg)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return writeMessage(s.w, &message{Method: method, Params: data})
+}
+
+func (s *Server) handle(msg *message) (interface{}, error) {
+	switch {
+	case msg.Method == "initialize":
+		s.initialized = true
+		return s.initialize(), nil
+	case !s.initialized:
+		return nil, &ResponseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	case msg.Method == "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case s.shutdown:
+		return nil, &ResponseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch msg.Method {
+	case "initialized":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		doc := newDocument(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+		s.docs[doc.uri] = doc
+		return nil, s.publishDiagnostics(doc)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		doc, ok := s.docs[params.TextDocument.URI]
+		if !ok {
+			return nil, &ResponseError{Code: codeInvalidParams, Message: fmt.Sprintf("document %q is not opened", params.TextDocument.URI)}
+		}
+		doc.version = params.TextDocument.Version
+		for _, change := range params.ContentChanges {
+			doc.applyChange(change)
+		}
+		return nil, s.publishDiagnostics(doc)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	case "textDocument/formatting":
+		var params DocumentFormattingParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.formatting(&params)
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.documentSymbol(&params)
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(&params)
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(&params)
+	}
+	if strings.HasPrefix(msg.Method, "$/") {
+		// The optional notifications and requests can be ignored.
+		return nil, nil
+	}
+	return nil, &ResponseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", msg.Method)}
+}
+
+func decodeParams(msg *message, params interface{}) error {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &ResponseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &ResponseError{Code: codeInvalidParams, Message: fmt.Sprintf("document %q is not opened", uri)}
+	}
+	doc.analyze(s.sqlMode)
+	return doc, nil
+}
+
+func (s *Server) initialize() *InitializeResult {
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           TextDocumentSyncIncremental,
+			DocumentFormattingProvider: true,
+			DocumentSymbolProvider:     true,
+			HoverProvider:              true,
+			CompletionProvider:         &CompletionOptions{TriggerCharacters: []string{"."}},
+		},
+		ServerInfo: ServerInfo{Name: "sqllsp"},
+	}
+}
+
+func (s *Server) publishDiagnostics(doc *document) error {
+	doc.analyze(s.sqlMode)
+	diags := doc.diags
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: doc.uri, Version: doc.version, Diagnostics: diags})
+}
+
+// formatting restores the statements in the pretty format. The document is not
+// formatted if it has any syntax error, and the comments are dropped.
+func (s *Server) formatting(params *DocumentFormattingParams) ([]TextEdit, error) {
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	// The comments can not be preserved by Restore.
+	if doc.hasErrors() || doc.hasComments() {
+		return []TextEdit{}, nil
+	}
+	var pretty format.PrettyConfig
+	if !params.Options.InsertSpaces {
+		pretty.Indent = "\t"
+	} else if params.Options.TabSize > 0 {
+		pretty.Indent = strings.Repeat(" ", params.Options.TabSize)
+	}
+	var sb strings.Builder
+	for i, stmt := range doc.stmts {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		ctx := format.NewRestoreCtx(format.DefaultRestoreFlags|format.RestorePrettyFormat, &sb)
+		ctx.Pretty = pretty
+		if err := stmt.node.Restore(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+		sb.WriteString(";\n")
+	}
+	if sb.String() == doc.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range: doc.rangeOf(0, len(doc.text)), NewText: sb.String()}}, nil
+}
+
+// documentSymbol returns the tables, views and indexes created in the
+// document. The columns and the indexes defined in CREATE TABLE are the
+// children of the table.
+func (s *Server) documentSymbol(params *DocumentSymbolParams) ([]DocumentSymbol, error) {
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	symbols := []DocumentSymbol{}
+	for i := range doc.stmts {
+		stmt := &doc.stmts[i]
+		switch n := stmt.node.(type) {
+		case *ast.CreateTableStmt:
+			sym := doc.symbol(stmt, n.Table.Name.O, tableName(n.Table), SymbolKindStruct)
+			for _, col := range n.Cols {
+				detail := ""
+				if col.Tp != nil {
+					detail = col.Tp.String()
+				}
+				sym.Children = append(sym.Children, doc.symbol(stmt, col.Name.Name.O, detail, SymbolKindField))
+			}
+			for _, c := range n.Constraints {
+				if c.Name != "" {
+					sym.Children = append(sym.Children, doc.symbol(stmt, c.Name, "index", SymbolKindKey))
+				}
+			}
+			symbols = append(symbols, sym)
+		case *ast.CreateViewStmt:
+			symbols = append(symbols, doc.symbol(stmt, n.ViewName.Name.O, tableName(n.ViewName), SymbolKindInterface))
+		case *ast.CreateIndexStmt:
+			symbols = append(symbols, doc.symbol(stmt, n.IndexName, "index on "+tableName(n.Table), SymbolKindKey))
+		}
+	}
+	return symbols, nil
+}
+
+func (d *document) symbol(s *statement, name, detail string, kind SymbolKind) DocumentSymbol {
+	return DocumentSymbol{
+		Name:           name,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          d.stmtRange(s),
+		SelectionRange: d.nameRange(s, name),
+	}
+}
+
+func tableName(tn *ast.TableName) string {
+	if tn.Schema.O != "" {
+		return tn.Schema.O + "." + tn.Name.O
+	}
+	return tn.Name.O
+}
+
+// completion returns the keywords and the builtin functions acceptable at the
+// position by the parser, or all the keywords matching the word before the
+// position if the text before it isn't a valid SQL prefix.
+func (s *Server) completion(params *TextDocumentPositionParams) (*CompletionList, error) {
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	offset := doc.offset(params.Position)
+	start := 0
+	if it, err := parser.Tokenize(doc.text[:offset], parser.SQLModeParam(s.sqlMode)); err == nil {
+		for it.Next() {
+			if tok := it.Token(); tok.Kind == parser.TokenOperator && tok.Text == ";" {
+				start = tok.End.Offset
+			}
+		}
+	}
+
+	list := &CompletionList{Items: []CompletionItem{}}
+	p := parser.New()
+	p.SetSQLMode(s.sqlMode)
+	p.EnableWindowFunc(true)
+	if res, err := p.Complete(doc.text[start:offset], offset-start); err == nil {
+		for _, kw := range res.Keywords {
+			list.Items = append(list.Items, CompletionItem{Label: kw, Kind: CompletionItemKindKeyword})
+		}
+		for _, fn := range res.Functions {
+			list.Items = append(list.Items, CompletionItem{Label: fn, Kind: CompletionItemKindFunction})
+		}
+		return list, nil
+	}
+
+	wordStart := offset
+	for wordStart > start && isWordChar(doc.text[wordStart-1]) {
+		wordStart--
+	}
+	prefix := strings.ToUpper(doc.text[wordStart:offset])
+	for _, kw := range parser.Keywords() {
+		if strings.HasPrefix(kw, prefix) {
+			list.Items = append(list.Items, CompletionItem{Label: kw, Kind: CompletionItemKindKeyword})
+		}
+	}
+	return list, nil
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= 0x80 || (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+// hover describes the keyword at the position, or shows the definition of a
+// table created in the document.
+func (s *Server) hover(params *TextDocumentPositionParams) (*Hover, error) {
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := doc.tokenAt(s.sqlMode, doc.offset(params.Position))
+	if !ok {
+		return nil, nil
+	}
+	var content string
+	switch tok.Kind {
+	case parser.TokenKeyword:
+		content = keywordHover(tok.Text)
+	case parser.TokenIdentifier, parser.TokenQuotedIdentifier:
+		name, _ := tok.Value.(string)
+		content, err = doc.tableDefinition(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if content == "" {
+		return nil, nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: content},
+		Range:    doc.rangeOf(tok.Start.Offset, tok.End.Offset),
+	}, nil
+}
+
+// keywordKinds are the descriptions of the kinds of the keywords.
+var keywordKinds = map[parser.KeywordKind]string{
+	parser.KeywordGeneral:        "keyword",
+	parser.KeywordStatement:      "statement keyword",
+	parser.KeywordFunction:       "function name",
+	parser.KeywordWindowFunction: "window function keyword",
+}
+
+// statementSyntax are the syntax lines of the common statement keywords.
+var statementSyntax = map[string]string{
+	"ALTER":    "ALTER TABLE tbl_name alter_option [, alter_option] ...",
+	"BEGIN":    "BEGIN [WORK]",
+	"COMMIT":   "COMMIT [WORK]",
+	"CREATE":   "CREATE {DATABASE | TABLE | INDEX | VIEW | USER | ...} ...",
+	"DELETE":   "DELETE FROM tbl_name [WHERE where_condition] [ORDER BY ...] [LIMIT row_count]",
+	"DROP":     "DROP {DATABASE | TABLE | INDEX | VIEW | USER | ...} ...",
+	"EXPLAIN":  "EXPLAIN [FORMAT = format_name] explainable_stmt",
+	"GRANT":    "GRANT priv_type [, priv_type] ... ON priv_level TO user [, user] ...",
+	"INSERT":   "INSERT [INTO] tbl_name [(col_name, ...)] {VALUES (value_list) [, (value_list)] ... | SELECT ...}",
+	"REPLACE":  "REPLACE [INTO] tbl_name [(col_name, ...)] {VALUES (value_list) [, (value_list)] ... | SELECT ...}",
+	"REVOKE":   "REVOKE priv_type [, priv_type] ... ON priv_level FROM user [, user] ...",
+	"ROLLBACK": "ROLLBACK [WORK]",
+	"SELECT":   "SELECT select_expr [, select_expr] ... [FROM table_references] [WHERE where_condition] [GROUP BY ...] [HAVING ...] [ORDER BY ...] [LIMIT ...]",
+	"SET":      "SET variable = expr [, variable = expr] ...",
+	"SHOW":     "SHOW {DATABASES | TABLES | COLUMNS | CREATE TABLE | ...} ...",
+	"TRUNCATE": "TRUNCATE [TABLE] tbl_name",
+	"UPDATE":   "UPDATE tbl_name SET assignment_list [WHERE where_condition] [ORDER BY ...] [LIMIT row_count]",
+	"USE":      "USE db_name",
+	"WITH":     "WITH cte_name [(col_name, ...)] AS (subquery) [, ...] statement",
+}
+
+// keywordHover describes whether the keyword is reserved and its kind, with
+// the syntax of the common statements.
+func keywordHover(word string) string {
+	info, ok := parser.LookupKeyword(word)
+	if !ok {
+		return fmt.Sprintf("`%s` keyword", strings.ToUpper(word))
+	}
+	reserved := "unreserved"
+	if info.Reserved {
+		reserved = "reserved"
+	}
+	content := fmt.Sprintf("`%s` %s %s", info.Name, reserved, keywordKinds[info.Kind])
+	if syntax, ok := statementSyntax[info.Name]; ok && info.Kind == parser.KeywordStatement {
+		content += "\n\n```sql\n" + syntax + "\n```"
+	}
+	return content
+}
+
+// tableDefinition returns the CREATE TABLE statement of the table in the
+// document as a markdown code block, or "" if the table isn't created.
+func (d *document) tableDefinition(name string) (string, error) {
+	for _, stmt := range d.stmts {
+		n, ok := stmt.node.(*ast.CreateTableStmt)
+		if !ok || !strings.EqualFold(n.Table.Name.O, name) {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString("```sql\n")
+		if err := n.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags|format.RestorePrettyFormat, &sb)); err != nil {
+			return "", errors.Trace(err)
+		}
+		sb.WriteString("\n```")
+		return sb.String(), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,301 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/daiguadaidai/parser/mysql"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	"github.com/stretchr/testify/require"
+)
+
+// client is a client talking to a server over pipes.
+type client struct {
+	t      *testing.T
+	w      *io.PipeWriter
+	r      *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func newClient(t *testing.T) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, w: inW, r: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		err := NewServer(mysql.ModeNone).Serve(inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *client) send(msg *message) {
+	require.NoError(c.t, writeMessage(c.w, msg))
+}
+
+func (c *client) read() *message {
+	data, err := readMessage(c.r)
+	require.NoError(c.t, err)
+	var msg message
+	require.NoError(c.t, json.Unmarshal(data, &msg))
+	return &msg
+}
+
+// call sends a request and decodes the result of the response into result.
+func (c *client) call(method string, params, result interface{}) *ResponseError {
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	data, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	c.send(&message{ID: &id, Method: method, Params: data})
+	resp := c.read()
+	require.Equal(c.t, string(id), string(*resp.ID))
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil {
+		require.NoError(c.t, json.Unmarshal(resp.Result, result))
+	}
+	return nil
+}
+
+// notify sends a notification.
+func (c *client) notify(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	c.send(&message{Method: method, Params: data})
+}
+
+// diagnostics reads the published diagnostics.
+func (c *client) diagnostics() PublishDiagnosticsParams {
+	msg := c.read()
+	require.Equal(c.t, "textDocument/publishDiagnostics", msg.Method)
+	var params PublishDiagnosticsParams
+	require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+	return params
+}
+
+func (c *client) initialize() {
+	var res InitializeResult
+	require.Nil(c.t, c.call("initialize", map[string]interface{}{}, &res))
+	require.Equal(c.t, TextDocumentSyncIncremental, res.Capabilities.TextDocumentSync)
+	c.notify("initialized", map[string]interface{}{})
+}
+
+func (c *client) exit() {
+	require.Nil(c.t, c.call("shutdown", nil, nil))
+	c.notify("exit", nil)
+	require.NoError(c.t, <-c.done)
+}
+
+func (c *client) open(uri, text string) PublishDiagnosticsParams {
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, LanguageID: "sql", Version: 1, Text: text}})
+	return c.diagnostics()
+}
+
+func pos(line, char int) Position {
+	return Position{Line: line, Character: char}
+}
+
+func TestLifecycle(t *testing.T) {
+	c := newClient(t)
+	require.Equal(t, codeServerNotInitialized, c.call("shutdown", nil, nil).Code)
+	c.initialize()
+	require.Equal(t, codeMethodNotFound, c.call("workspace/symbol", map[string]interface{}{}, nil).Code)
+	require.Equal(t, codeInvalidParams, c.call("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: "file:///none.sql"}}, nil).Code)
+	c.exit()
+
+	c = newClient(t)
+	c.initialize()
+	c.notify("exit", nil)
+	require.Equal(t, ErrExitWithoutShutdown, <-c.done)
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	uri := "file:///a.sql"
+	diags := c.open(uri, "select 1;\nselect a\nfrom t wher c = 1;\nselect * form t;\nselect 2")
+	require.Equal(t, uri, diags.URI)
+	require.Len(t, diags.Diagnostics, 2)
+	require.Equal(t, SeverityError, diags.Diagnostics[0].Severity)
+	require.Equal(t, Range{Start: pos(2, 12), End: pos(2, 13)}, diags.Diagnostics[0].Range)
+	require.Contains(t, diags.Diagnostics[0].Message, `near "c = 1;"`)
+	require.Equal(t, Range{Start: pos(3, 9), End: pos(3, 13)}, diags.Diagnostics[1].Range)
+
+	// Fix the first error by an incremental change.
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Range: &Range{Start: pos(2, 7), End: pos(2, 11)}, Text: "where"}},
+	})
+	diags = c.diagnostics()
+	require.Equal(t, 2, diags.Version)
+	require.Len(t, diags.Diagnostics, 1)
+	require.Equal(t, Range{Start: pos(3, 9), End: pos(3, 13)}, diags.Diagnostics[0].Range)
+
+	// Replace the whole text, the warnings are reported on the statements.
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 3},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "select 1;\n  select sql_calc_found_rows * from t"}},
+	})
+	diags = c.diagnostics()
+	require.Len(t, diags.Diagnostics, 0)
+
+	c.notify("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	require.Empty(t, c.diagnostics().Diagnostics)
+	c.exit()
+}
+
+func TestFormatting(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	uri := "file:///a.sql"
+	c.open(uri, "select a, b from t where c = 1; create table t (id int, name varchar(10))")
+
+	var edits []TextEdit
+	require.Nil(t, c.call("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: uri}, Options: FormattingOptions{TabSize: 4, InsertSpaces: true}}, &edits))
+	require.Len(t, edits, 1)
+	require.Equal(t, Range{Start: pos(0, 0), End: pos(0, 73)}, edits[0].Range)
+	require.Equal(t, "SELECT `a`, `b`\nFROM `t`\nWHERE `c`=1;\n\nCREATE TABLE `t` (\n    `id`   INT,\n    `name` VARCHAR(10)\n);\n", edits[0].NewText)
+
+	// A document with errors is not formatted.
+	c.open("file:///b.sql", "select from")
+	require.Nil(t, c.call("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: "file:///b.sql"}}, &edits))
+	require.Empty(t, edits)
+
+	// A document with comments is not formatted, the comments would be lost.
+	for i, text := range []string{
+		"-- the users\nselect a from t",
+		"select a from t # the users",
+		"select /* the users */ a from t",
+		"select a from t --",
+	} {
+		uri := "file:///c" + strconv.Itoa(i) + ".sql"
+		c.open(uri, text)
+		require.Nil(t, c.call("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &edits))
+		require.Empty(t, edits, text)
+	}
+	// The comment marks in strings and identifiers, and the subtraction of a
+	// negative number, are not comments.
+	c.open("file:///d.sql", "select '-- a', \"# b\", `/* c */`, 'it\\'s -- d', 1--1 from t")
+	require.Nil(t, c.call("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: "file:///d.sql"}}, &edits))
+	require.Len(t, edits, 1)
+	c.exit()
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	uri := "file:///a.sql"
+	c.open(uri, "create table db.t (\n  id int,\n  name varchar(10),\n  key idx_name (name)\n);\ncreate view v as select id from t;\ncreate index idx on t (id);\nselect 1;")
+
+	var symbols []DocumentSymbol
+	require.Nil(t, c.call("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols))
+	require.Len(t, symbols, 3)
+	require.Equal(t, "t", symbols[0].Name)
+	require.Equal(t, "db.t", symbols[0].Detail)
+	require.Equal(t, SymbolKindStruct, symbols[0].Kind)
+	require.Equal(t, Range{Start: pos(0, 0), End: pos(4, 2)}, symbols[0].Range)
+	require.Equal(t, Range{Start: pos(0, 16), End: pos(0, 17)}, symbols[0].SelectionRange)
+	require.Len(t, symbols[0].Children, 3)
+	require.Equal(t, "id", symbols[0].Children[0].Name)
+	require.Equal(t, "int(11)", symbols[0].Children[0].Detail)
+	require.Equal(t, Range{Start: pos(1, 2), End: pos(1, 4)}, symbols[0].Children[0].SelectionRange)
+	require.Equal(t, "idx_name", symbols[0].Children[2].Name)
+	require.Equal(t, SymbolKindKey, symbols[0].Children[2].Kind)
+	require.Equal(t, "v", symbols[1].Name)
+	require.Equal(t, SymbolKindInterface, symbols[1].Kind)
+	require.Equal(t, "idx", symbols[2].Name)
+	require.Equal(t, "index on t", symbols[2].Detail)
+	c.exit()
+}
+
+func TestCompletionAndHover(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	uri := "file:///a.sql"
+	c.open(uri, "create table t (id int);\nselect * fr\nselect * from t where id = 1 ord")
+
+	labels := func(list *CompletionList) map[string]CompletionItemKind {
+		res := make(map[string]CompletionItemKind)
+		for _, item := range list.Items {
+			res[item.Label] = item.Kind
+		}
+		return res
+	}
+	var list CompletionList
+	require.Nil(t, c.call("textDocument/completion", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos(1, 11)}, &list))
+	require.Equal(t, map[string]CompletionItemKind{"FROM": CompletionItemKindKeyword}, labels(&list))
+
+	require.Nil(t, c.call("textDocument/completion", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos(2, 33)}, &list))
+	require.Contains(t, labels(&list), "ORDER")
+
+	var hover *Hover
+	require.Nil(t, c.call("textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos(0, 2)}, &hover))
+	require.Equal(t, "`CREATE` reserved statement keyword\n\n```sql\nCREATE {DATABASE | TABLE | INDEX | VIEW | USER | ...} ...\n```", hover.Contents.Value)
+	require.Equal(t, Range{Start: pos(0, 0), End: pos(0, 6)}, hover.Range)
+	require.Nil(t, c.call("textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos(0, 13)}, &hover))
+	require.Equal(t, "```sql\nCREATE TABLE `t` (\n  `id` INT\n)\n```", hover.Contents.Value)
+	hover = nil
+	require.Nil(t, c.call("textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos(0, 23)}, &hover))
+	require.Nil(t, hover)
+	c.exit()
+}
+
+func TestHoverKeyword(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	uri := "file:///a.sql"
+	c.open(uri, "select count(*), rank() over w from t window w as ()")
+
+	hover := func(character int) string {
+		var res *Hover
+		require.Nil(t, c.call("textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos(0, character)}, &res))
+		require.NotNil(t, res)
+		return res.Contents.Value
+	}
+	require.Equal(t, "`SELECT` reserved statement keyword\n\n```sql\n"+statementSyntax["SELECT"]+"\n```", hover(1))
+	require.Equal(t, "`COUNT` unreserved function name", hover(8))
+	require.Equal(t, "`RANK` reserved window function keyword", hover(18))
+	require.Equal(t, "`OVER` reserved window function keyword", hover(25))
+	require.Equal(t, "`FROM` reserved keyword", hover(33))
+	c.exit()
+}
+
+func TestDocumentPosition(t *testing.T) {
+	d := newDocument("file:///a.sql", 1, "select '你好';\nselect '😀', 1;\n")
+	for _, c := range []struct {
+		offset int
+		pos    Position
+	}{
+		{0, pos(0, 0)},
+		{8, pos(0, 8)},
+		{14, pos(0, 10)},
+		{16, pos(0, 12)},
+		{17, pos(1, 0)},
+		{29, pos(1, 10)},
+		{len(d.text), pos(2, 0)},
+	} {
+		require.Equal(t, c.pos, d.position(c.offset), "offset %d", c.offset)
+		require.Equal(t, c.offset, d.offset(c.pos), "offset %d", c.offset)
+	}
+	require.Equal(t, 16, d.offset(pos(0, 100)))
+	require.Equal(t, len(d.text), d.offset(pos(5, 0)))
+}
@@ -13,6 +13,11 @@
 
 package parser
 
+import (
+	"sort"
+	"strings"
+)
+
 func isLetter(ch byte) bool {
 	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
 }
@@ -132,6 +137,78 @@ func isInTokenMap(target string) bool {
 	return ok
 }
 
+// Keywords returns the keywords known by the lexer in upper case and sorted,
+// including the builtin function names scanned as keywords like COUNT.
+func Keywords() []string {
+	keywords := make([]string, 0, len(tokenMap)+len(btFuncTokenMap))
+	for k := range tokenMap {
+		keywords = append(keywords, k)
+	}
+	for k := range btFuncTokenMap {
+		if _, ok := tokenMap[k]; !ok {
+			keywords = append(keywords, k)
+		}
+	}
+	sort.Strings(keywords)
+	return keywords
+}
+
+//go:generate go run ./internal/keywordgen -o keywords_generated.go
+
+// IsReservedKeyword returns whether a word is a reserved keyword, which must be
+// quoted to be used as an identifier.
+func IsReservedKeyword(word string) bool {
+	_, ok := reservedKeywords[strings.ToUpper(word)]
+	return ok
+}
+
+// KeywordKind is the kind of a keyword.
+type KeywordKind int
+
+// The kinds of the keywords.
+const (
+	// KeywordGeneral is a keyword used in the clauses of the statements, like FROM.
+	KeywordGeneral KeywordKind = iota
+	// KeywordStatement is a keyword which can begin a statement, like SELECT.
+	KeywordStatement
+	// KeywordFunction is a builtin function name scanned as a keyword, like COUNT.
+	KeywordFunction
+	// KeywordWindowFunction is a keyword of the window functions, like RANK and
+	// OVER, which is a keyword only if the window functions are enabled.
+	KeywordWindowFunction
+)
+
+// KeywordInfo describes a keyword known by the lexer.
+type KeywordInfo struct {
+	// Name is the keyword in upper case.
+	Name     string
+	Kind     KeywordKind
+	Reserved bool
+}
+
+// LookupKeyword returns the description of word, or false if it isn't a
+// keyword known by the lexer.
+func LookupKeyword(word string) (KeywordInfo, bool) {
+	name := strings.ToUpper(word)
+	info := KeywordInfo{Name: name, Reserved: IsReservedKeyword(name)}
+	tok, ok := tokenMap[name]
+	if _, isFunc := btFuncTokenMap[name]; isFunc {
+		info.Kind = KeywordFunction
+	}
+	if windowTok, isWindowFunc := windowFuncTokenMap[name]; isWindowFunc {
+		tok, ok = windowTok, true
+		info.Kind = KeywordWindowFunction
+	}
+	if !ok && info.Kind != KeywordFunction {
+		return KeywordInfo{}, false
+	}
+	compTables.init()
+	if _, isStmt := compTables.statementKeywords[tok]; ok && isStmt {
+		info.Kind = KeywordStatement
+	}
+	return info, true
+}
+
 // tokenMap is a map of known identifiers to the parser token ID.
 // Please try to keep the map in alphabetical order.
 var tokenMap = map[string]int{
@@ -942,11 +942,15 @@ const (
 	ErrFunctionalIndexOnField                                = 3762
 	ErrFKIncompatibleColumns                                 = 3780
 	ErrFunctionalIndexRowValueIsNotAllowed                   = 3800
+	ErrCheckConstraintDupName                                = 3822
 	ErrDependentByFunctionalIndex                            = 3837
+	ErrDependentByPartitionFunctional                        = 3855
 	ErrInvalidJsonValueForFuncIndex                          = 3903 //nolint: revive
 	ErrJsonValueOutOfRangeForFuncIndex                       = 3904 //nolint: revive
 	ErrFunctionalIndexDataIsTooLong                          = 3907
 	ErrFunctionalIndexNotApplicable                          = 3909
+	ErrCheckConstraintNotFound                               = 3940
+	ErrDependentByCheckConstraint                            = 3959
 
 	// MariaDB errors.
 	ErrOnlyOneDefaultPartionAllowed         = 4030
@@ -5939,6 +5939,7 @@ UnReservedKeyword:
 |	"SESSION"
 |	"SIGNED"
 |	"SHARD_ROW_ID_BITS"
+|	"SHARDKEY"
 |	"SHUTDOWN"
 |	"SNAPSHOT"
 |	"START"
@@ -97,7 +97,7 @@ func TestSimple(t *testing.T) {
 		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "stats_healthy", "tidb_version", "replication", "slave", "client",
 		"max_connections_per_hour", "max_queries_per_hour", "max_updates_per_hour", "max_user_connections", "event", "reload", "routine", "temporary",
 		"following", "preceding", "unbounded", "respect", "nulls", "current", "last", "against", "expansion",
-		"chain", "error", "general", "nvarchar", "pack_keys", "p", "shard_row_id_bits", "pre_split_regions",
+		"chain", "error", "general", "nvarchar", "pack_keys", "p", "shard_row_id_bits", "shardkey", "pre_split_regions",
 		"constraints", "role", "replicas", "policy", "s3", "strict", "running", "stop", "preserve", "placement",
 	}
 	for _, kw := range unreservedKws {
@@ -389,6 +389,7 @@ func RunRestoreTest(t *testing.T, sourceSQLs, expectSQLs string, enableWindowFun
 		require.NoError(t, err, comment)
 		restoreSQL := sb.String()
 		comment = fmt.Sprintf("source %v; restore %v", sourceSQLs, restoreSQL)
+		runJSONRoundTripTest(t, stmt, restoreSQL, comment)
 		restoreStmt, err := p.ParseOneStmt(restoreSQL, "", "")
 		require.NoError(t, err, comment)
 		CleanNodeText(stmt)
@@ -402,6 +403,18 @@ func RunRestoreTest(t *testing.T, sourceSQLs, expectSQLs string, enableWindowFun
 	require.Equalf(t, expectSQLs, restoreSQLs, "restore %v; expect %v", restoreSQLs, expectSQLs)
 }
 
+// runJSONRoundTripTest checks the statement decoded from its JSON encoding is
+// restored to the same SQL.
+func runJSONRoundTripTest(t *testing.T, stmt ast.StmtNode, restoreSQL string, comment string) {
+	data, err := ast.EncodeJSON(stmt)
+	require.NoError(t, err, comment)
+	decoded, err := ast.DecodeJSON(data)
+	require.NoError(t, err, comment)
+	var sb strings.Builder
+	require.NoError(t, decoded.Restore(NewRestoreCtx(DefaultRestoreFlags, &sb)), comment)
+	require.Equal(t, restoreSQL, sb.String(), comment)
+}
+
 func RunTestInRealAsFloatMode(t *testing.T, table []testCase, enableWindowFunc bool) {
 	p := parser.New()
 	p.EnableWindowFunc(enableWindowFunc)
@@ -436,6 +449,7 @@ func RunRestoreTestInRealAsFloatMode(t *testing.T, sourceSQLs, expectSQLs string
 		require.NoError(t, err, comment)
 		restoreSQL := sb.String()
 		comment = fmt.Sprintf("source %v; restore %v", sourceSQLs, restoreSQL)
+		runJSONRoundTripTest(t, stmt, restoreSQL, comment)
 		restoreStmt, err := p.ParseOneStmt(restoreSQL, "", "")
 		require.NoError(t, err, comment)
 		CleanNodeText(stmt)
@@ -2778,6 +2792,7 @@ func TestDDL(t *testing.T) {
 		{"CREATE TABLE t (c TEXT) default CHARACTER SET utf8, default COLLATE utf8_general_ci;", true, "CREATE TABLE `t` (`c` TEXT) DEFAULT CHARACTER SET = UTF8 DEFAULT COLLATE = UTF8_GENERAL_CI"},
 		{"CREATE TABLE t (c TEXT) shard_row_id_bits = 1;", true, "CREATE TABLE `t` (`c` TEXT) SHARD_ROW_ID_BITS = 1"},
 		{"CREATE TABLE t (c TEXT) shard_row_id_bits = 1, PRE_SPLIT_REGIONS = 1;", true, "CREATE TABLE `t` (`c` TEXT) SHARD_ROW_ID_BITS = 1 PRE_SPLIT_REGIONS = 1"},
+		{"CREATE TABLE t (shardkey INT) shardkey = shardkey;", true, "CREATE TABLE `t` (`shardkey` INT) SHARDKEY = shardkey"},
 		// Create table with ON UPDATE CURRENT_TIMESTAMP(6), specify fraction part.
 		{"CREATE TABLE IF NOT EXISTS `general_log` (`event_time` timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),`user_host` mediumtext NOT NULL,`thread_id` bigint(20) unsigned NOT NULL,`server_id` int(10) unsigned NOT NULL,`command_type` varchar(64) NOT NULL,`argument` mediumblob NOT NULL) ENGINE=CSV DEFAULT CHARSET=utf8 COMMENT='General log'", true, "CREATE TABLE IF NOT EXISTS `general_log` (`event_time` TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),`user_host` MEDIUMTEXT NOT NULL,`thread_id` BIGINT(20) UNSIGNED NOT NULL,`server_id` INT(10) UNSIGNED NOT NULL,`command_type` VARCHAR(64) NOT NULL,`argument` MEDIUMBLOB NOT NULL) ENGINE = CSV DEFAULT CHARACTER SET = UTF8 COMMENT = 'General log'"}, // TODO: The number yacc in parentheses has not been implemented yet.
 		// For reference_definition in column_definition.
@@ -0,0 +1,97 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"sync"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/pingcap/errors"
+)
+
+// Reset clears the state left by the previous parse, including the charset and
+// collation parameters, the lexer encodings and the references to the parsed
+// AST, so the parser can be reused by another caller. The SQL mode and the
+// ParserConfig are kept.
+func (parser *Parser) Reset() {
+	resetParams(parser)
+	parser.lexer.reset("")
+	parser.lexer.done = nil
+	parser.explicitCharset = false
+	parser.src = ""
+	// The statements of the previous parse are owned by the caller, the result
+	// slice is not reused.
+	parser.result = nil
+	for i := range parser.cache {
+		parser.cache[i] = yySymType{}
+	}
+	parser.yylval = yySymType{}
+	parser.yyVAL = nil
+}
+
+// Pool is a concurrency-safe pool of parsers sharing the same ParserConfig and SQL mode.
+// A Parser is not goroutine-safe, Pool can be used by many goroutines to avoid
+// creating a Parser for each query.
+type Pool struct {
+	pool    sync.Pool
+	config  ParserConfig
+	sqlMode mysql.SQLMode
+}
+
+// NewPool returns a Pool whose parsers use config and mode.
+func NewPool(config ParserConfig, mode mysql.SQLMode) *Pool {
+	p := &Pool{
+		config:  config,
+		sqlMode: mode,
+	}
+	p.pool.New = func() interface{} {
+		parser := New()
+		parser.SetParserConfig(p.config)
+		parser.SetSQLMode(p.sqlMode)
+		return parser
+	}
+	return p
+}
+
+// Get takes a parser from the pool. It must be returned by Put after use and
+// must not be used after that.
+func (p *Pool) Get() *Parser {
+	return p.pool.Get().(*Parser)
+}
+
+// Put resets the parser and returns it to the pool. The configuration and the
+// SQL mode of the pool are restored if they were changed by the caller.
+func (p *Pool) Put(parser *Parser) {
+	parser.Reset()
+	parser.SetParserConfig(p.config)
+	parser.SetSQLMode(p.sqlMode)
+	p.pool.Put(parser)
+}
+
+// ParseSQL parses sql with a parser of the pool, see Parser.ParseSQL.
+func (p *Pool) ParseSQL(sql string, params ...ParseParam) (stmts []ast.StmtNode, warns []error, err error) {
+	parser := p.Get()
+	stmts, warns, err = parser.ParseSQL(sql, params...)
+	p.Put(parser)
+	return
+}
+
+// ParseOneStmt parses a query with a parser of the pool, see Parser.ParseOneStmt.
+func (p *Pool) ParseOneStmt(sql, charset, collation string) (ast.StmtNode, error) {
+	parser := p.Get()
+	stmt, err := parser.ParseOneStmt(sql, charset, collation)
+	p.Put(parser)
+	return stmt, errors.Trace(err)
+}
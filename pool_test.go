@@ -0,0 +1,98 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/mysql"
+	requires "github.com/stretchr/testify/require"
+)
+
+func TestParserReset(t *testing.T) {
+	p := New()
+	_, _, err := p.ParseSQL("select 'a', 'b'", CharsetConnection("gbk"), CollationConnection("gbk_chinese_ci"), CharsetClient("gbk"))
+	requires.NoError(t, err)
+	requires.Equal(t, "gbk", p.charset)
+	requires.Equal(t, charset.CharsetGBK, p.lexer.client.Name())
+
+	p.Reset()
+	requires.Equal(t, mysql.DefaultCharset, p.charset)
+	requires.Equal(t, mysql.DefaultCollationName, p.collation)
+	requires.Equal(t, mysql.DefaultCharset, p.lexer.client.Name())
+	requires.Equal(t, mysql.DefaultCharset, p.lexer.connection.Name())
+	requires.Equal(t, "", p.src)
+	requires.Len(t, p.result, 0)
+	for _, v := range p.cache {
+		requires.Nil(t, v.item)
+		requires.Nil(t, v.expr)
+		requires.Nil(t, v.statement)
+	}
+}
+
+func TestPool(t *testing.T) {
+	mode, err := mysql.GetSQLMode(mysql.DefaultSQLMode)
+	requires.NoError(t, err)
+	pool := NewPool(ParserConfig{EnableWindowFunction: true, EnableStrictDoubleTypeCheck: true}, mode)
+
+	// The SQL mode changed by a caller does not leak to other callers.
+	p := pool.Get()
+	p.SetSQLMode(mysql.ModeANSIQuotes)
+	stmt, err := p.ParseOneStmt(`select "a"`, "", "")
+	requires.NoError(t, err)
+	requires.IsType(t, &ast.ColumnNameExpr{}, stmt.(*ast.SelectStmt).Fields.Fields[0].Expr)
+	pool.Put(p)
+	requires.Equal(t, mode, p.lexer.GetSQLMode())
+
+	// The statements parsed by a parser are usable after it is returned.
+	p = pool.Get()
+	stmts, _, err := p.ParseSQL("select 1; select 2")
+	requires.NoError(t, err)
+	pool.Put(p)
+	requires.Len(t, stmts, 2)
+	for _, stmt := range stmts {
+		requires.IsType(t, &ast.SelectStmt{}, stmt)
+	}
+	p = pool.Get()
+	_, _, err = p.ParseSQL("select 3; select 4; select 5")
+	requires.NoError(t, err)
+	pool.Put(p)
+	requires.Equal(t, "select 1;", stmts[0].Text())
+	requires.Equal(t, " select 2", stmts[1].Text())
+
+	stmt, err = pool.ParseOneStmt(`select "a"`, "", "")
+	requires.NoError(t, err)
+	_, isColumn := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.ColumnNameExpr)
+	requires.False(t, isColumn)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sql := fmt.Sprintf("select c%d from t%d where id = %d; select %d", i, j, j, i)
+				stmts, _, err := pool.ParseSQL(sql)
+				requires.NoError(t, err)
+				requires.Len(t, stmts, 2)
+				requires.Equal(t, fmt.Sprintf("t%d", j), stmts[0].(*ast.SelectStmt).From.TableRefs.Left.(*ast.TableSource).Source.(*ast.TableName).Name.O)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
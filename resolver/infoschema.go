@@ -0,0 +1,61 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolver
+
+import (
+	"github.com/daiguadaidai/parser/model"
+)
+
+// InfoSchema is the schema catalog the names are resolved against.
+type InfoSchema interface {
+	// SchemaByName returns the database by its name.
+	SchemaByName(schema model.CIStr) (*model.DBInfo, bool)
+	// TableByName returns the table of the database by its name.
+	TableByName(schema, table model.CIStr) (*model.TableInfo, bool)
+}
+
+type infoSchema struct {
+	dbs    map[string]*model.DBInfo
+	tables map[string]map[string]*model.TableInfo
+}
+
+// NewInfoSchema creates an InfoSchema holding the databases, the tables of a
+// database are the DBInfo.Tables.
+func NewInfoSchema(dbs ...*model.DBInfo) InfoSchema {
+	is := &infoSchema{
+		dbs:    make(map[string]*model.DBInfo, len(dbs)),
+		tables: make(map[string]map[string]*model.TableInfo, len(dbs)),
+	}
+	for _, db := range dbs {
+		tables := make(map[string]*model.TableInfo, len(db.Tables))
+		for _, tbl := range db.Tables {
+			tables[tbl.Name.L] = tbl
+		}
+		is.dbs[db.Name.L] = db
+		is.tables[db.Name.L] = tables
+	}
+	return is
+}
+
+// SchemaByName implements InfoSchema interface.
+func (is *infoSchema) SchemaByName(schema model.CIStr) (*model.DBInfo, bool) {
+	db, ok := is.dbs[schema.L]
+	return db, ok
+}
+
+// TableByName implements InfoSchema interface.
+func (is *infoSchema) TableByName(schema, table model.CIStr) (*model.TableInfo, bool) {
+	tbl, ok := is.tables[schema.L][table.L]
+	return tbl, ok
+}
@@ -0,0 +1,1046 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resolver binds the names of the statements to the tables and the
+// columns of a schema catalog.
+//
+// After resolving, every ColumnNameExpr and PositionExpr refers to the
+// ResultField it is evaluated from, and every TableName of a base table has
+// its DBInfo and TableInfo set. A column of a base table refers to a
+// ResultField without Expr, a column of a derived table, a CTE or an alias of
+// the select field list refers to a ResultField whose Expr is the select field
+// expression, so the references can be followed down to the base tables.
+package resolver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/format"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/terror"
+	"github.com/pingcap/errors"
+)
+
+var (
+	// ErrNoDB returns for an unqualified table name without current database.
+	ErrNoDB = terror.ClassOptimizer.NewStd(mysql.ErrNoDB)
+	// ErrNoSuchTable returns for an unknown table.
+	ErrNoSuchTable = terror.ClassOptimizer.NewStd(mysql.ErrNoSuchTable)
+	// ErrBadTable returns for an unknown table of a qualified wildcard.
+	ErrBadTable = terror.ClassOptimizer.NewStd(mysql.ErrBadTable)
+	// ErrUnknownTable returns for an unknown table of a multiple-table DELETE.
+	ErrUnknownTable = terror.ClassOptimizer.NewStd(mysql.ErrUnknownTable)
+	// ErrNonuniqTable returns for a duplicated table name or alias.
+	ErrNonuniqTable = terror.ClassOptimizer.NewStd(mysql.ErrNonuniqTable)
+	// ErrBadField returns for an unknown column.
+	ErrBadField = terror.ClassOptimizer.NewStd(mysql.ErrBadField)
+	// ErrNonUniq returns for an ambiguous column.
+	ErrNonUniq = terror.ClassOptimizer.NewStd(mysql.ErrNonUniq)
+	// ErrDupFieldName returns for a duplicated column name of a derived table.
+	ErrDupFieldName = terror.ClassOptimizer.NewStd(mysql.ErrDupFieldName)
+	// ErrFieldSpecifiedTwice returns for a column assigned twice.
+	ErrFieldSpecifiedTwice = terror.ClassOptimizer.NewStd(mysql.ErrFieldSpecifiedTwice)
+	// ErrNoTablesUsed returns for `SELECT *` without FROM clause.
+	ErrNoTablesUsed = terror.ClassOptimizer.NewStd(mysql.ErrNoTablesUsed)
+	// ErrDerivedMustHaveAlias returns for a derived table without alias.
+	ErrDerivedMustHaveAlias = terror.ClassOptimizer.NewStd(mysql.ErrDerivedMustHaveAlias)
+	// ErrWrongNumberOfColumnsInSelect returns for the set operation of the
+	// selects with different number of columns.
+	ErrWrongNumberOfColumnsInSelect = terror.ClassOptimizer.NewStd(mysql.ErrWrongNumberOfColumnsInSelect)
+	// ErrViewWrongList returns for a column list of a view or a CTE which does
+	// not match the select fields.
+	ErrViewWrongList = terror.ClassOptimizer.NewStd(mysql.ErrViewWrongList)
+	// ErrWrongValueCountOnRow returns for an inserted row which does not match
+	// the columns.
+	ErrWrongValueCountOnRow = terror.ClassOptimizer.NewStd(mysql.ErrWrongValueCountOnRow)
+	// ErrTablenameNotAllowedHere returns for a qualified column in the ORDER BY
+	// clause of a set operation.
+	ErrTablenameNotAllowedHere = terror.ClassOptimizer.NewStd(mysql.ErrTablenameNotAllowedHere)
+	// ErrWrongGroupField returns for grouping on an aggregate function.
+	ErrWrongGroupField = terror.ClassOptimizer.NewStd(mysql.ErrWrongGroupField)
+	// ErrInvalidGroupFuncUse returns for an aggregate function in the WHERE or
+	// ON clause.
+	ErrInvalidGroupFuncUse = terror.ClassOptimizer.NewStd(mysql.ErrInvalidGroupFuncUse)
+)
+
+// clauseCode is the clause where a name is resolved, it decides the search
+// order of the names and the message of the errors.
+type clauseCode int
+
+const (
+	fieldList clauseCode = iota
+	fromClause
+	onClause
+	whereClause
+	groupByClause
+	havingClause
+	windowClause
+	orderByClause
+	globalOrderByClause
+)
+
+var clauseMsg = map[clauseCode]string{
+	fieldList:           "field list",
+	fromClause:          "from clause",
+	onClause:            "on clause",
+	whereClause:         "where clause",
+	groupByClause:       "group statement",
+	havingClause:        "having clause",
+	windowClause:        "window clause",
+	orderByClause:       "order clause",
+	globalOrderByClause: "global ORDER clause",
+}
+
+func (c clauseCode) String() string {
+	return clauseMsg[c]
+}
+
+// column is a column in the FROM clause of a query block.
+type column struct {
+	field *ast.ResultField
+	// db and table match the qualifier of a column name, db is empty for an
+	// aliased table, a derived table or a CTE.
+	db    model.CIStr
+	table model.CIStr
+	// redundant marks the column merged into the column of the other table by
+	// USING or NATURAL join, which is only matched by the qualified name.
+	redundant bool
+}
+
+func (c *column) match(name *ast.ColumnName) bool {
+	if c.field.ColumnAsName.L != name.Name.L {
+		return false
+	}
+	if name.Table.L == "" {
+		return !c.redundant
+	}
+	return c.table.L == name.Table.L && (name.Schema.L == "" || c.db.L == name.Schema.L)
+}
+
+// source is a table in the FROM clause of a query block.
+type source struct {
+	db   model.CIStr
+	name model.CIStr
+	// tableName is set for a base table.
+	tableName *ast.TableName
+	// fields are the columns in the table order.
+	fields []*ast.ResultField
+}
+
+// cte is a common table expression.
+type cte struct {
+	name model.CIStr
+	// fields are nil until the columns are known, a recursive CTE knows its
+	// columns after the first query block is resolved.
+	fields []*ast.ResultField
+}
+
+// scope is the names visible to a query block.
+type scope struct {
+	parent  *scope
+	sources []*source
+	// columns are the columns of the FROM clause in the order of `SELECT *`,
+	// they are set after the whole FROM clause is resolved.
+	columns []*column
+	// fields are the select fields which can be referenced by alias.
+	fields []*ast.ResultField
+	ctes   []*cte
+	// setOpr is set for the scope of the ORDER BY of a set operation.
+	setOpr bool
+}
+
+func (s *scope) findCTE(name model.CIStr) *cte {
+	for cur := s; cur != nil; cur = cur.parent {
+		for i := len(cur.ctes) - 1; i >= 0; i-- {
+			if c := cur.ctes[i]; c.name.L == name.L && c.fields != nil {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+func (s *scope) addSource(src *source) error {
+	for _, other := range s.sources {
+		if other.name.L == src.name.L && other.db.L == src.db.L {
+			return ErrNonuniqTable.GenWithStackByArgs(src.name.O)
+		}
+	}
+	s.sources = append(s.sources, src)
+	return nil
+}
+
+// findColumn finds the column in the FROM clause.
+func (s *scope) findColumn(name *ast.ColumnName, clause clauseCode) (*ast.ResultField, error) {
+	var found *column
+	for _, c := range s.columns {
+		if !c.match(name) {
+			continue
+		}
+		if found != nil {
+			return nil, ErrNonUniq.GenWithStackByArgs(name.OrigColName(), clause.String())
+		}
+		found = c
+	}
+	if found == nil {
+		return nil, nil
+	}
+	found.field.Referenced = true
+	return found.field, nil
+}
+
+// findField finds the select field by its name. A select field of a column
+// resolves to the result field of the column.
+func (s *scope) findField(name *ast.ColumnName, clause clauseCode) (*ast.ResultField, error) {
+	var found *ast.ResultField
+	for _, f := range s.fields {
+		if f.ColumnAsName.L != name.Name.L {
+			continue
+		}
+		rf := f
+		if c, ok := f.Expr.(*ast.ColumnNameExpr); ok && c.Refer != nil {
+			rf = c.Refer
+		}
+		if found != nil && found != rf {
+			return nil, ErrNonUniq.GenWithStackByArgs(name.OrigColName(), clause.String())
+		}
+		found = rf
+	}
+	return found, nil
+}
+
+// Resolver binds the names of the statements against an InfoSchema.
+type Resolver struct {
+	is        InfoSchema
+	currentDB string
+	fields    map[ast.ResultSetNode][]*ast.ResultField
+}
+
+// NewResolver creates a Resolver, currentDB is the database of the unqualified
+// table names.
+func NewResolver(is InfoSchema, currentDB string) *Resolver {
+	return &Resolver{
+		is:        is,
+		currentDB: currentDB,
+		fields:    make(map[ast.ResultSetNode][]*ast.ResultField),
+	}
+}
+
+// ResolveName resolves the names of the node, see Resolver.Resolve.
+func ResolveName(node ast.Node, is InfoSchema, currentDB string) error {
+	return NewResolver(is, currentDB).Resolve(node)
+}
+
+// ResultFields returns the output columns of a resolved SelectStmt or
+// SetOprStmt, the wildcards are expanded. The result fields of a set operation
+// are named by the first query block and have no Expr.
+func (r *Resolver) ResultFields(node ast.ResultSetNode) []*ast.ResultField {
+	return r.fields[node]
+}
+
+// Resolve resolves the names of the statement. The DML statements, EXPLAIN,
+// CREATE VIEW and CREATE TABLE ... SELECT are resolved, the other nodes are
+// ignored.
+func (r *Resolver) Resolve(node ast.Node) error {
+	switch x := node.(type) {
+	case *ast.SelectStmt, *ast.SetOprStmt:
+		_, err := r.resolveResultSet(&scope{}, x.(ast.ResultSetNode))
+		return err
+	case *ast.InsertStmt:
+		return r.resolveInsert(x)
+	case *ast.UpdateStmt:
+		return r.resolveUpdate(x)
+	case *ast.DeleteStmt:
+		return r.resolveDelete(x)
+	case *ast.ExplainStmt:
+		return r.Resolve(x.Stmt)
+	case *ast.CreateViewStmt:
+		sel, ok := x.Select.(ast.ResultSetNode)
+		if !ok {
+			return nil
+		}
+		fields, err := r.resolveResultSet(&scope{}, sel)
+		if err != nil {
+			return err
+		}
+		if len(x.Cols) > 0 && len(x.Cols) != len(fields) {
+			return ErrViewWrongList.GenWithStackByArgs()
+		}
+		return nil
+	case *ast.CreateTableStmt:
+		if x.Select == nil {
+			return nil
+		}
+		_, err := r.resolveResultSet(&scope{}, x.Select)
+		return err
+	}
+	return nil
+}
+
+func (r *Resolver) resolveResultSet(s *scope, node ast.ResultSetNode) ([]*ast.ResultField, error) {
+	switch x := node.(type) {
+	case *ast.SelectStmt:
+		return r.resolveSelect(s, x)
+	case *ast.SetOprStmt:
+		return r.resolveSetOpr(s, x, nil)
+	}
+	return nil, errors.Errorf("unsupported result set %T", node)
+}
+
+func (r *Resolver) resolveSelect(s *scope, sel *ast.SelectStmt) ([]*ast.ResultField, error) {
+	if sel.With != nil {
+		if err := r.resolveWith(s, sel.With); err != nil {
+			return nil, err
+		}
+	}
+	if sel.From != nil {
+		if err := r.resolveFrom(s, sel.From.TableRefs); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.resolveExpr(s, sel.Where, whereClause); err != nil {
+		return nil, err
+	}
+	var err error
+	switch sel.Kind {
+	case ast.SelectStmtKindTable:
+		s.fields = r.expandWildCard(s, nil)
+	case ast.SelectStmtKindValues:
+		s.fields, err = r.resolveValues(s, sel.Lists)
+	default:
+		s.fields, err = r.resolveFields(s, sel.Fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sel.GroupBy != nil {
+		if err := r.resolveByItems(s, sel.GroupBy.Items, groupByClause); err != nil {
+			return nil, err
+		}
+	}
+	if sel.Having != nil {
+		if err := r.resolveExpr(s, sel.Having.Expr, havingClause); err != nil {
+			return nil, err
+		}
+	}
+	for i := range sel.WindowSpecs {
+		if err := r.resolveExpr(s, &sel.WindowSpecs[i], windowClause); err != nil {
+			return nil, err
+		}
+	}
+	if sel.OrderBy != nil {
+		if err := r.resolveByItems(s, sel.OrderBy.Items, orderByClause); err != nil {
+			return nil, err
+		}
+	}
+	r.fields[sel] = s.fields
+	return s.fields, nil
+}
+
+// resolveSetOpr resolves a set operation, seed is called with the result
+// fields of the first query block before the others are resolved.
+func (r *Resolver) resolveSetOpr(s *scope, n *ast.SetOprStmt, seed func([]*ast.ResultField) error) ([]*ast.ResultField, error) {
+	if n.With != nil {
+		if err := r.resolveWith(s, n.With); err != nil {
+			return nil, err
+		}
+	}
+	fields, err := r.resolveSelectList(s, n.SelectList, seed)
+	if err != nil {
+		return nil, err
+	}
+	if n.OrderBy != nil {
+		orderScope := &scope{parent: s, fields: fields, setOpr: true}
+		if err := r.resolveByItems(orderScope, n.OrderBy.Items, orderByClause); err != nil {
+			return nil, err
+		}
+	}
+	r.fields[n] = fields
+	return fields, nil
+}
+
+func (r *Resolver) resolveSelectList(s *scope, list *ast.SetOprSelectList, seed func([]*ast.ResultField) error) ([]*ast.ResultField, error) {
+	if list.With != nil {
+		s = &scope{parent: s}
+		if err := r.resolveWith(s, list.With); err != nil {
+			return nil, err
+		}
+	}
+	var fields []*ast.ResultField
+	for i, sel := range list.Selects {
+		var selFields []*ast.ResultField
+		var err error
+		switch x := sel.(type) {
+		case *ast.SetOprSelectList:
+			selFields, err = r.resolveSelectList(s, x, nil)
+		case ast.ResultSetNode:
+			selFields, err = r.resolveResultSet(&scope{parent: s}, x)
+		default:
+			err = errors.Errorf("unsupported set operation operand %T", sel)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			if len(selFields) != len(fields) {
+				return nil, ErrWrongNumberOfColumnsInSelect.GenWithStackByArgs()
+			}
+			continue
+		}
+		fields = make([]*ast.ResultField, 0, len(selFields))
+		for _, f := range selFields {
+			fields = append(fields, &ast.ResultField{Column: f.Column, ColumnAsName: f.ColumnAsName})
+		}
+		if seed != nil {
+			if err := seed(fields); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fields, nil
+}
+
+func (r *Resolver) resolveWith(s *scope, with *ast.WithClause) error {
+	for _, c := range with.CTEs {
+		e := &cte{name: c.Name}
+		var fields []*ast.ResultField
+		var err error
+		if setOpr, ok := c.Query.Query.(*ast.SetOprStmt); ok && with.IsRecursive {
+			// The CTE is visible to its own query once the first query block
+			// decides its columns.
+			s.ctes = append(s.ctes, e)
+			fields, err = r.resolveSetOpr(&scope{parent: s}, setOpr, func(seed []*ast.ResultField) error {
+				e.fields, err = cteFields(c, seed)
+				return err
+			})
+		} else {
+			fields, err = r.resolveResultSet(&scope{parent: s}, c.Query.Query)
+			s.ctes = append(s.ctes, e)
+		}
+		if err != nil {
+			return err
+		}
+		if e.fields, err = cteFields(c, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cteFields renames the result fields of the CTE by its column list.
+func cteFields(c *ast.CommonTableExpression, fields []*ast.ResultField) ([]*ast.ResultField, error) {
+	if len(c.ColNameList) == 0 {
+		return fields, nil
+	}
+	if len(c.ColNameList) != len(fields) {
+		return nil, ErrViewWrongList.GenWithStackByArgs()
+	}
+	res := make([]*ast.ResultField, 0, len(fields))
+	for i, f := range fields {
+		rf := *f
+		rf.ColumnAsName = c.ColNameList[i]
+		res = append(res, &rf)
+	}
+	return res, nil
+}
+
+func (r *Resolver) resolveFrom(s *scope, join *ast.Join) error {
+	columns, err := r.resolveTableRef(s, join)
+	if err != nil {
+		return err
+	}
+	s.columns = columns
+	return nil
+}
+
+func (r *Resolver) resolveTableRef(s *scope, node ast.ResultSetNode) ([]*column, error) {
+	switch x := node.(type) {
+	case *ast.Join:
+		return r.resolveJoin(s, x)
+	case *ast.TableSource:
+		return r.resolveTableSource(s, x)
+	}
+	return nil, errors.Errorf("unsupported table reference %T", node)
+}
+
+func (r *Resolver) resolveJoin(s *scope, join *ast.Join) ([]*column, error) {
+	left, err := r.resolveTableRef(s, join.Left)
+	if err != nil || join.Right == nil {
+		return left, err
+	}
+	right, err := r.resolveTableRef(s, join.Right)
+	if err != nil {
+		return nil, err
+	}
+	var columns []*column
+	switch {
+	case join.NaturalJoin:
+		var names []*ast.ColumnName
+		for _, c := range left {
+			name := &ast.ColumnName{Name: c.field.ColumnAsName}
+			if !c.redundant && findJoinColumn(right, name) != nil {
+				names = append(names, name)
+			}
+		}
+		columns = mergeJoinColumns(left, right, names, join.Tp)
+	case len(join.Using) > 0:
+		for _, name := range join.Using {
+			for _, side := range [][]*column{left, right} {
+				n := 0
+				for _, c := range side {
+					if c.match(name) {
+						n++
+					}
+				}
+				if n == 0 {
+					return nil, ErrBadField.GenWithStackByArgs(name.Name.O, fromClause.String())
+				}
+				if n > 1 {
+					return nil, ErrNonUniq.GenWithStackByArgs(name.Name.O, fromClause.String())
+				}
+			}
+		}
+		columns = mergeJoinColumns(left, right, join.Using, join.Tp)
+	default:
+		columns = make([]*column, 0, len(left)+len(right))
+		columns = append(columns, left...)
+		columns = append(columns, right...)
+	}
+	if join.On != nil {
+		// The ON condition only sees the tables of the join, the scope itself
+		// has no columns until the FROM clause is resolved.
+		onScope := &scope{parent: s, columns: columns}
+		if err := r.resolveExpr(onScope, join.On.Expr, onClause); err != nil {
+			return nil, err
+		}
+	}
+	return columns, nil
+}
+
+func findJoinColumn(columns []*column, name *ast.ColumnName) *column {
+	for _, c := range columns {
+		if c.match(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// mergeJoinColumns merges the columns of a USING or NATURAL join like MySQL,
+// the common columns come first, then the other columns of the left and the
+// right table. A RIGHT JOIN is handled as a LEFT JOIN with the tables swapped.
+func mergeJoinColumns(left, right []*column, names []*ast.ColumnName, tp ast.JoinType) []*column {
+	if tp == ast.RightJoin {
+		left, right = right, left
+	}
+	merged := make(map[*column]bool, 2*len(names))
+	columns := make([]*column, 0, len(left)+len(right))
+	redundant := make([]*column, 0, len(names))
+	for _, name := range names {
+		l, r := findJoinColumn(left, name), findJoinColumn(right, name)
+		merged[l], merged[r] = true, true
+		columns = append(columns, l)
+		redundant = append(redundant, &column{field: r.field, db: r.db, table: r.table, redundant: true})
+	}
+	for _, side := range [][]*column{left, right} {
+		for _, c := range side {
+			if !merged[c] {
+				columns = append(columns, c)
+			}
+		}
+	}
+	return append(columns, redundant...)
+}
+
+func (r *Resolver) resolveTableSource(s *scope, ts *ast.TableSource) ([]*column, error) {
+	var src *source
+	switch x := ts.Source.(type) {
+	case *ast.TableName:
+		var err error
+		if src, err = r.resolveTableName(s, x, ts.AsName); err != nil {
+			return nil, err
+		}
+	case *ast.SelectStmt, *ast.SetOprStmt:
+		if ts.AsName.L == "" {
+			return nil, ErrDerivedMustHaveAlias.GenWithStackByArgs()
+		}
+		// A derived table can not see the other tables of the FROM clause.
+		fields, err := r.resolveResultSet(&scope{parent: s}, x)
+		if err != nil {
+			return nil, err
+		}
+		src = &source{name: ts.AsName, fields: derivedFields(fields, ts.AsName)}
+	case *ast.Join:
+		return r.resolveJoin(s, x)
+	default:
+		return nil, errors.Errorf("unsupported table source %T", ts.Source)
+	}
+	if err := checkDupFields(src.fields); err != nil {
+		return nil, err
+	}
+	if err := s.addSource(src); err != nil {
+		return nil, err
+	}
+	columns := make([]*column, 0, len(src.fields))
+	for _, f := range src.fields {
+		columns = append(columns, &column{field: f, db: src.db, table: src.name})
+	}
+	return columns, nil
+}
+
+func (r *Resolver) resolveTableName(s *scope, tn *ast.TableName, alias model.CIStr) (*source, error) {
+	if tn.Schema.L == "" {
+		if c := s.findCTE(tn.Name); c != nil {
+			name := tn.Name
+			if alias.L != "" {
+				name = alias
+			}
+			return &source{name: name, fields: derivedFields(c.fields, name)}, nil
+		}
+	}
+	dbName := tn.Schema
+	if dbName.L == "" {
+		if r.currentDB == "" {
+			return nil, ErrNoDB.GenWithStackByArgs()
+		}
+		dbName = model.NewCIStr(r.currentDB)
+	}
+	db, ok := r.is.SchemaByName(dbName)
+	if !ok {
+		return nil, ErrNoSuchTable.GenWithStackByArgs(dbName.O, tn.Name.O)
+	}
+	tbl, ok := r.is.TableByName(dbName, tn.Name)
+	if !ok {
+		return nil, ErrNoSuchTable.GenWithStackByArgs(dbName.O, tn.Name.O)
+	}
+	tn.DBInfo, tn.TableInfo = db, tbl
+	src := &source{db: db.Name, name: tbl.Name, tableName: tn}
+	if alias.L != "" {
+		src.db, src.name = model.CIStr{}, alias
+	}
+	for _, col := range tbl.Cols() {
+		if col.Hidden {
+			continue
+		}
+		src.fields = append(src.fields, &ast.ResultField{
+			Column:       col,
+			ColumnAsName: col.Name,
+			Table:        tbl,
+			TableAsName:  alias,
+			DBName:       db.Name,
+			TableName:    tn,
+		})
+	}
+	return src, nil
+}
+
+// derivedFields returns the columns of a derived table or a CTE, they refer to
+// the select fields by Expr.
+func derivedFields(fields []*ast.ResultField, alias model.CIStr) []*ast.ResultField {
+	res := make([]*ast.ResultField, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, &ast.ResultField{
+			Column:       f.Column,
+			ColumnAsName: f.ColumnAsName,
+			TableAsName:  alias,
+			Expr:         f.Expr,
+		})
+	}
+	return res
+}
+
+func checkDupFields(fields []*ast.ResultField) error {
+	names := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		if _, ok := names[f.ColumnAsName.L]; ok {
+			return ErrDupFieldName.GenWithStackByArgs(f.ColumnAsName.O)
+		}
+		names[f.ColumnAsName.L] = struct{}{}
+	}
+	return nil
+}
+
+func (r *Resolver) resolveFields(s *scope, fields *ast.FieldList) ([]*ast.ResultField, error) {
+	var res []*ast.ResultField
+	for _, f := range fields.Fields {
+		if f.WildCard != nil {
+			if f.WildCard.Table.L == "" && len(s.columns) == 0 {
+				return nil, ErrNoTablesUsed.GenWithStackByArgs()
+			}
+			expanded := r.expandWildCard(s, f.WildCard)
+			if len(expanded) == 0 {
+				return nil, ErrBadTable.GenWithStackByArgs(f.WildCard.Table.O)
+			}
+			res = append(res, expanded...)
+			continue
+		}
+		if err := r.resolveExpr(s, f.Expr, fieldList); err != nil {
+			return nil, err
+		}
+		res = append(res, fieldResult(f.Expr, f.AsName, fieldName(f), len(res)))
+	}
+	return res, nil
+}
+
+// expandWildCard returns the fields of `*` or `t.*`, every field refers to the
+// column by a ColumnNameExpr.
+func (r *Resolver) expandWildCard(s *scope, wildCard *ast.WildCardField) []*ast.ResultField {
+	var fields []*ast.ResultField
+	if wildCard == nil || wildCard.Table.L == "" {
+		for _, c := range s.columns {
+			if !c.redundant {
+				fields = append(fields, c.field)
+			}
+		}
+	} else {
+		for _, src := range s.sources {
+			if src.name.L == wildCard.Table.L && (wildCard.Schema.L == "" || src.db.L == wildCard.Schema.L) {
+				fields = src.fields
+				break
+			}
+		}
+	}
+	res := make([]*ast.ResultField, 0, len(fields))
+	for _, f := range fields {
+		f.Referenced = true
+		expr := &ast.ColumnNameExpr{
+			Name:  &ast.ColumnName{Table: f.TableAsName, Name: f.ColumnAsName},
+			Refer: f,
+		}
+		if expr.Name.Table.L == "" && f.Table != nil {
+			expr.Name.Schema, expr.Name.Table = f.DBName, f.Table.Name
+		}
+		res = append(res, fieldResult(expr, model.CIStr{}, "", len(res)))
+	}
+	return res
+}
+
+func (r *Resolver) resolveValues(s *scope, rows []*ast.RowExpr) ([]*ast.ResultField, error) {
+	var res []*ast.ResultField
+	for i, row := range rows {
+		if i > 0 && len(row.Values) != len(res) {
+			return nil, ErrWrongValueCountOnRow.GenWithStackByArgs(i + 1)
+		}
+		for j, expr := range row.Values {
+			if err := r.resolveExpr(s, expr, fieldList); err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				res = append(res, fieldResult(expr, model.NewCIStr(fmt.Sprintf("column_%d", j)), "", j))
+			}
+		}
+	}
+	return res, nil
+}
+
+// fieldResult returns the result field of a select field. A column keeps the
+// table information of the column it refers to.
+func fieldResult(expr ast.ExprNode, asName model.CIStr, text string, offset int) *ast.ResultField {
+	rf := &ast.ResultField{ColumnAsName: asName, Expr: expr}
+	if c, ok := expr.(*ast.ColumnNameExpr); ok && c.Refer != nil {
+		rf.Column = c.Refer.Column
+		rf.Table = c.Refer.Table
+		rf.TableAsName = c.Refer.TableAsName
+		rf.DBName = c.Refer.DBName
+		rf.TableName = c.Refer.TableName
+		if rf.ColumnAsName.L == "" {
+			rf.ColumnAsName = c.Name.Name
+		}
+		return rf
+	}
+	if rf.ColumnAsName.L == "" {
+		rf.ColumnAsName = model.NewCIStr(text)
+	}
+	rf.Column = &model.ColumnInfo{Name: rf.ColumnAsName, Offset: offset, State: model.StatePublic}
+	if tp := expr.GetType(); tp != nil {
+		rf.Column.FieldType = *tp
+	}
+	return rf
+}
+
+// fieldName returns the name of a select field without alias, which is the
+// text of the field like MySQL.
+func fieldName(f *ast.SelectField) string {
+	if text := strings.TrimSpace(f.Text()); text != "" {
+		return text
+	}
+	var sb strings.Builder
+	if err := f.Expr.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return ""
+	}
+	return sb.String()
+}
+
+func (r *Resolver) resolveByItems(s *scope, items []*ast.ByItem, clause clauseCode) error {
+	for _, item := range items {
+		pos, ok := item.Expr.(*ast.PositionExpr)
+		if !ok {
+			if err := r.resolveExpr(s, item.Expr, clause); err != nil {
+				return err
+			}
+			continue
+		}
+		if pos.P != nil {
+			continue
+		}
+		if pos.N < 1 || pos.N > len(s.fields) {
+			return ErrBadField.GenWithStackByArgs(strconv.Itoa(pos.N), clause.String())
+		}
+		pos.Refer = s.fields[pos.N-1]
+		if clause == groupByClause && hasAggregate(pos.Refer.Expr) {
+			return ErrWrongGroupField.GenWithStackByArgs(pos.Refer.ColumnAsName.O)
+		}
+	}
+	return nil
+}
+
+func hasAggregate(expr ast.ExprNode) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.AggregateFuncExpr:
+			found = true
+		case *ast.SubqueryExpr:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// resolveColumn resolves a column name in the query block and then the outer
+// query blocks. ORDER BY searches the select fields before the FROM clause,
+// GROUP BY and HAVING search the select fields after the FROM clause.
+func (r *Resolver) resolveColumn(s *scope, name *ast.ColumnName, clause clauseCode) (*ast.ResultField, error) {
+	unqualified := name.Table.L == ""
+	for cur := s; cur != nil; cur = cur.parent {
+		inner := cur == s
+		if inner && cur.setOpr && !unqualified {
+			return nil, ErrTablenameNotAllowedHere.GenWithStackByArgs(name.Table.O, "SELECT", globalOrderByClause.String())
+		}
+		if inner && unqualified && clause == orderByClause {
+			if rf, err := cur.findField(name, clause); err != nil || rf != nil {
+				return rf, err
+			}
+		}
+		if rf, err := cur.findColumn(name, clause); err != nil || rf != nil {
+			return rf, err
+		}
+		if inner && unqualified && (clause == groupByClause || clause == havingClause) {
+			rf, err := cur.findField(name, clause)
+			if err != nil {
+				return nil, err
+			}
+			if rf != nil && clause == groupByClause && hasAggregate(rf.Expr) {
+				return nil, ErrWrongGroupField.GenWithStackByArgs(name.Name.O)
+			}
+			if rf != nil {
+				return rf, nil
+			}
+		}
+	}
+	return nil, ErrBadField.GenWithStackByArgs(name.OrigColName(), clause.String())
+}
+
+// resolveTargetColumn resolves an assigned or inserted column, which must be a
+// column of the tables.
+func (r *Resolver) resolveTargetColumn(s *scope, name *ast.ColumnName, assigned map[*ast.ResultField]struct{}) error {
+	rf, err := s.findColumn(name, fieldList)
+	if err != nil {
+		return err
+	}
+	if rf == nil {
+		return ErrBadField.GenWithStackByArgs(name.OrigColName(), fieldList.String())
+	}
+	if _, ok := assigned[rf]; ok {
+		return ErrFieldSpecifiedTwice.GenWithStackByArgs(name.Name.O)
+	}
+	assigned[rf] = struct{}{}
+	return nil
+}
+
+func (r *Resolver) resolveInsert(n *ast.InsertStmt) error {
+	s := &scope{}
+	if err := r.resolveFrom(s, n.Table.TableRefs); err != nil {
+		return err
+	}
+	assigned := make(map[*ast.ResultField]struct{})
+	for _, c := range n.Columns {
+		if err := r.resolveTargetColumn(s, c, assigned); err != nil {
+			return err
+		}
+	}
+	count := len(n.Columns)
+	if count == 0 {
+		count = len(s.columns)
+	}
+	for i, row := range n.Lists {
+		if len(row) != count && (len(row) != 0 || len(n.Columns) != 0) {
+			return ErrWrongValueCountOnRow.GenWithStackByArgs(i + 1)
+		}
+		for _, expr := range row {
+			if err := r.resolveExpr(s, expr, fieldList); err != nil {
+				return err
+			}
+		}
+	}
+	for _, a := range n.Setlist {
+		if err := r.resolveTargetColumn(s, a.Column, assigned); err != nil {
+			return err
+		}
+		if err := r.resolveExpr(s, a.Expr, fieldList); err != nil {
+			return err
+		}
+	}
+	if n.Select != nil {
+		fields, err := r.resolveResultSet(&scope{}, n.Select)
+		if err != nil {
+			return err
+		}
+		if len(fields) != count {
+			return ErrWrongValueCountOnRow.GenWithStackByArgs(1)
+		}
+	}
+	updated := make(map[*ast.ResultField]struct{})
+	for _, a := range n.OnDuplicate {
+		if err := r.resolveTargetColumn(s, a.Column, updated); err != nil {
+			return err
+		}
+		if err := r.resolveExpr(s, a.Expr, fieldList); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) resolveUpdate(n *ast.UpdateStmt) error {
+	s := &scope{}
+	if n.With != nil {
+		if err := r.resolveWith(s, n.With); err != nil {
+			return err
+		}
+	}
+	if err := r.resolveFrom(s, n.TableRefs.TableRefs); err != nil {
+		return err
+	}
+	assigned := make(map[*ast.ResultField]struct{})
+	for _, a := range n.List {
+		if err := r.resolveTargetColumn(s, a.Column, assigned); err != nil {
+			return err
+		}
+		if err := r.resolveExpr(s, a.Expr, fieldList); err != nil {
+			return err
+		}
+	}
+	if err := r.resolveExpr(s, n.Where, whereClause); err != nil {
+		return err
+	}
+	if n.Order != nil {
+		return r.resolveByItems(s, n.Order.Items, orderByClause)
+	}
+	return nil
+}
+
+func (r *Resolver) resolveDelete(n *ast.DeleteStmt) error {
+	s := &scope{}
+	if n.With != nil {
+		if err := r.resolveWith(s, n.With); err != nil {
+			return err
+		}
+	}
+	if err := r.resolveFrom(s, n.TableRefs.TableRefs); err != nil {
+		return err
+	}
+	if n.IsMultiTable && n.Tables != nil {
+		for _, tn := range n.Tables.Tables {
+			if err := resolveDeleteTable(s, tn); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.resolveExpr(s, n.Where, whereClause); err != nil {
+		return err
+	}
+	if n.Order != nil {
+		return r.resolveByItems(s, n.Order.Items, orderByClause)
+	}
+	return nil
+}
+
+// resolveDeleteTable binds a table of the multiple-table DELETE to a base table
+// of the FROM clause.
+func resolveDeleteTable(s *scope, tn *ast.TableName) error {
+	for _, src := range s.sources {
+		if src.name.L != tn.Name.L || (tn.Schema.L != "" && src.db.L != tn.Schema.L) {
+			continue
+		}
+		if src.tableName == nil {
+			break
+		}
+		tn.DBInfo, tn.TableInfo = src.tableName.DBInfo, src.tableName.TableInfo
+		return nil
+	}
+	return ErrUnknownTable.GenWithStackByArgs(tn.Name.O, "MULTI DELETE")
+}
+
+func (r *Resolver) resolveExpr(s *scope, node ast.Node, clause clauseCode) error {
+	if node == nil {
+		return nil
+	}
+	v := &exprResolver{r: r, s: s, clause: clause}
+	node.Accept(v)
+	return v.err
+}
+
+// exprResolver resolves the column names of an expression.
+type exprResolver struct {
+	r      *Resolver
+	s      *scope
+	clause clauseCode
+	err    error
+}
+
+// Enter implements ast.Visitor interface.
+func (v *exprResolver) Enter(n ast.Node) (ast.Node, bool) {
+	if v.err != nil {
+		return n, true
+	}
+	switch x := n.(type) {
+	case *ast.ColumnNameExpr:
+		x.Refer, v.err = v.r.resolveColumn(v.s, x.Name, v.clause)
+		return n, true
+	case *ast.DefaultExpr:
+		if x.Name != nil {
+			_, v.err = v.r.resolveColumn(v.s, x.Name, v.clause)
+		}
+		return n, true
+	case *ast.SubqueryExpr:
+		_, v.err = v.r.resolveResultSet(&scope{parent: v.s}, x.Query)
+		return n, true
+	case *ast.AggregateFuncExpr:
+		if v.clause == whereClause || v.clause == onClause {
+			v.err = ErrInvalidGroupFuncUse.GenWithStackByArgs()
+			return n, true
+		}
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (v *exprResolver) Leave(n ast.Node) (ast.Node, bool) {
+	return n, v.err == nil
+}
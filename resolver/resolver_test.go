@@ -0,0 +1,268 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	. "github.com/daiguadaidai/parser/resolver"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	"github.com/daiguadaidai/parser/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTable(name string, cols ...string) *model.TableInfo {
+	tbl := &model.TableInfo{Name: model.NewCIStr(name), State: model.StatePublic}
+	for i, col := range cols {
+		tbl.Columns = append(tbl.Columns, &model.ColumnInfo{
+			ID:        int64(i + 1),
+			Name:      model.NewCIStr(col),
+			Offset:    i,
+			FieldType: *types.NewFieldType(mysql.TypeLong),
+			State:     model.StatePublic,
+		})
+	}
+	return tbl
+}
+
+func newInfoSchema() InfoSchema {
+	return NewInfoSchema(
+		&model.DBInfo{Name: model.NewCIStr("test"), Tables: []*model.TableInfo{
+			newTable("t", "a", "b", "c"),
+			newTable("s", "a", "d"),
+			newTable("u", "id", "a"),
+		}},
+		&model.DBInfo{Name: model.NewCIStr("other"), Tables: []*model.TableInfo{
+			newTable("t", "x"),
+		}},
+	)
+}
+
+func resolve(t *testing.T, sql string) (ast.StmtNode, *Resolver, error) {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err, sql)
+	r := NewResolver(newInfoSchema(), "test")
+	return stmt, r, r.Resolve(stmt)
+}
+
+// refers returns the table and column each column reference is resolved to,
+// following the select field expressions down to the base tables.
+func refers(t *testing.T, node ast.Node) []string {
+	var res []string
+	for _, c := range ast.FindColumnNames(node) {
+		require.NotNil(t, c.Refer, c.Name.String())
+		res = append(res, referName(c.Refer))
+	}
+	return res
+}
+
+func referName(rf *ast.ResultField) string {
+	for rf.Table == nil {
+		c, ok := rf.Expr.(*ast.ColumnNameExpr)
+		if !ok {
+			return "expr:" + rf.ColumnAsName.O
+		}
+		rf = c.Refer
+	}
+	return rf.DBName.L + "." + rf.Table.Name.L + "." + rf.Column.Name.L
+}
+
+func fieldNames(fields []*ast.ResultField) []string {
+	res := make([]string, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, f.ColumnAsName.O)
+	}
+	return res
+}
+
+func TestResolveSelect(t *testing.T) {
+	stmt, r, err := resolve(t, "select b, t.c as C, x.d, (select max(d) from s where s.a = t.a) from t, other.t y, s x where c > 1 group by b order by C")
+	require.NoError(t, err)
+	sel := stmt.(*ast.SelectStmt)
+	require.Equal(t, []string{"b", "C", "d", "(select max(d) from s where s.a = t.a)"}, fieldNames(r.ResultFields(sel)))
+	require.Equal(t, []string{"test.t.b", "test.t.c", "test.s.d", "test.s.d", "test.s.a", "test.t.a", "test.t.c", "test.t.b", "test.t.c"}, refers(t, stmt))
+
+	tables := ast.FindTableNames(stmt)
+	require.Len(t, tables, 4)
+	require.Equal(t, "s", tables[0].TableInfo.Name.L)
+	require.Equal(t, "t", tables[1].TableInfo.Name.L)
+	require.Equal(t, "test", tables[1].DBInfo.Name.L)
+	require.Equal(t, "other", tables[2].DBInfo.Name.L)
+
+	// The fields of the wildcards and VALUES.
+	stmt, r, err = resolve(t, "select *, s.*, 1 + 1 from t, s")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "a", "d", "a", "d", "1 + 1"}, fieldNames(r.ResultFields(stmt.(*ast.SelectStmt))))
+	stmt, r, err = resolve(t, "table u")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "a"}, fieldNames(r.ResultFields(stmt.(*ast.SelectStmt))))
+	stmt, r, err = resolve(t, "values row(1, 2), row(3, 4)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"column_0", "column_1"}, fieldNames(r.ResultFields(stmt.(*ast.SelectStmt))))
+}
+
+func TestResolveJoin(t *testing.T) {
+	cases := []struct {
+		sql    string
+		fields []string
+		refers []string
+	}{
+		{"select * from t join s using (a)", []string{"a", "b", "c", "d"}, nil},
+		{"select * from t natural join s", []string{"a", "b", "c", "d"}, nil},
+		{"select * from t right join s using (a)", []string{"a", "d", "b", "c"}, nil},
+		{"select a, t.a, s.a from t join s using (a)", []string{"a", "a", "a"}, []string{"test.t.a", "test.t.a", "test.s.a"}},
+		{"select a from t right join s using (a)", []string{"a"}, []string{"test.s.a"}},
+		{"select s.* from t natural join s", []string{"a", "d"}, nil},
+		{"select * from t join (s natural join u) on t.b = u.id", []string{"a", "b", "c", "a", "d", "id"}, []string{"test.t.b", "test.u.id"}},
+		{"select * from t t1 join t t2 on t1.a = t2.b", []string{"a", "b", "c", "a", "b", "c"}, []string{"test.t.a", "test.t.b"}},
+	}
+	for _, c := range cases {
+		stmt, r, err := resolve(t, c.sql)
+		require.NoError(t, err, c.sql)
+		require.Equal(t, c.fields, fieldNames(r.ResultFields(stmt.(*ast.SelectStmt))), c.sql)
+		require.Equal(t, c.refers, refers(t, stmt), c.sql)
+	}
+}
+
+func TestResolveDerivedTableAndCTE(t *testing.T) {
+	stmt, r, err := resolve(t, "select x.a, x.n from (select a, b + 1 as n from t) x where x.n > 1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"test.t.a", "expr:n", "test.t.a", "test.t.b", "expr:n"}, refers(t, stmt))
+	fields := r.ResultFields(stmt.(*ast.SelectStmt))
+	require.Equal(t, "x", fields[1].TableAsName.L)
+	require.IsType(t, &ast.BinaryOperationExpr{}, fields[1].Expr.(*ast.ColumnNameExpr).Refer.Expr)
+
+	stmt, r, err = resolve(t, "with c1 (k) as (select a from t), c2 as (select k from c1) select * from c2 join c1 using (k)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"k"}, fieldNames(r.ResultFields(stmt.(*ast.SelectStmt))))
+	require.Equal(t, []string{"test.t.a", "test.t.a"}, refers(t, stmt))
+
+	// A recursive CTE sees its columns decided by the first query block.
+	stmt, r, err = resolve(t, "with recursive c (n, m) as (select 1, a from t union all select n + 1, m from c where n < 10) select n, m from c")
+	require.NoError(t, err)
+	require.Equal(t, []string{"n", "m"}, fieldNames(r.ResultFields(stmt.(*ast.SelectStmt))))
+	// A CTE shadows the table of the same name.
+	_, _, err = resolve(t, "with t as (select d from s) select d from t")
+	require.NoError(t, err)
+
+	// The correlated columns.
+	stmt, _, err = resolve(t, "select a from t where exists (select 1 from s where s.d = t.b and a = c)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"test.t.a", "test.s.d", "test.t.b", "test.s.a", "test.t.c"}, refers(t, stmt))
+}
+
+func TestResolveOrderBy(t *testing.T) {
+	cases := []struct {
+		sql    string
+		refers []string
+	}{
+		// ORDER BY searches the select fields first.
+		{"select b as a from t order by a", []string{"test.t.b", "test.t.b"}},
+		{"select b as a from t order by t.a", []string{"test.t.b", "test.t.a"}},
+		{"select a + 1 as x from t order by x", []string{"test.t.a", "expr:x"}},
+		{"select a, a from t order by a", []string{"test.t.a", "test.t.a", "test.t.a"}},
+		// GROUP BY and HAVING search the FROM clause first.
+		{"select b as a from t group by a", []string{"test.t.b", "test.t.a"}},
+		{"select count(*) as n, b as x from t group by x having n > 1", []string{"test.t.b", "test.t.b", "expr:n"}},
+		// The ORDER BY of a set operation only sees the result fields.
+		{"select a from t union select d from s order by a", []string{"test.t.a", "test.s.d", "expr:a"}},
+	}
+	for _, c := range cases {
+		stmt, _, err := resolve(t, c.sql)
+		require.NoError(t, err, c.sql)
+		require.Equal(t, c.refers, refers(t, stmt), c.sql)
+	}
+
+	stmt, r, err := resolve(t, "select a, b from t order by 2")
+	require.NoError(t, err)
+	pos := stmt.(*ast.SelectStmt).OrderBy.Items[0].Expr.(*ast.PositionExpr)
+	require.Same(t, r.ResultFields(stmt.(*ast.SelectStmt))[1], pos.Refer)
+}
+
+func TestResolveDML(t *testing.T) {
+	stmt, _, err := resolve(t, "insert into t (a, b) values (1, 2), (3, a) on duplicate key update c = values(a) + b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"test.t.a", "test.t.a", "test.t.b"}, refers(t, stmt))
+	_, _, err = resolve(t, "insert into t select a, d, 1 from s")
+	require.NoError(t, err)
+
+	stmt, _, err = resolve(t, "update t join s using (a) set t.b = d, c = 1 where s.d > 1 order by a")
+	require.NoError(t, err)
+	require.Equal(t, []string{"test.s.d", "test.s.d", "test.t.a"}, refers(t, stmt))
+
+	stmt, _, err = resolve(t, "delete x from t x join s on x.a = s.a where d = 1")
+	require.NoError(t, err)
+	del := stmt.(*ast.DeleteStmt)
+	require.Equal(t, "t", del.Tables.Tables[0].TableInfo.Name.L)
+	require.Equal(t, []string{"test.t.a", "test.s.a", "test.s.d"}, refers(t, stmt))
+
+	_, _, err = resolve(t, "create view v (x, y) as select a, b from t")
+	require.NoError(t, err)
+	_, _, err = resolve(t, "explain select a from t")
+	require.NoError(t, err)
+}
+
+func TestResolveErrors(t *testing.T) {
+	cases := []struct {
+		sql string
+		err string
+	}{
+		{"select x from t", "[planner:1054]Unknown column 'x' in 'field list'"},
+		{"select a from t where t.x = 1", "[planner:1054]Unknown column 't.x' in 'where clause'"},
+		{"select a from t, s", "[planner:1052]Column 'a' in field list is ambiguous"},
+		{"select * from t join s on a = 1", "[planner:1052]Column 'a' in on clause is ambiguous"},
+		{"select * from t join s on t.a = u.a", "[planner:1054]Unknown column 'u.a' in 'on clause'"},
+		{"select b as a from t where a = 1 and x = 1", "[planner:1054]Unknown column 'x' in 'where clause'"},
+		{"select b as x from t where x = 1", "[planner:1054]Unknown column 'x' in 'where clause'"},
+		{"select a as x, b as x from t order by x", "[planner:1052]Column 'x' in order clause is ambiguous"},
+		{"select a from t order by 3", "[planner:1054]Unknown column '3' in 'order clause'"},
+		{"select count(*) as n from t group by n", "[planner:1056]Can't group on 'n'"},
+		{"select count(*) from t group by 1", "[planner:1056]Can't group on 'count(*)'"},
+		{"select a from t where count(*) > 1", "[planner:1111]Invalid use of group function"},
+		{"select * from t join s using (b)", "[planner:1054]Unknown column 'b' in 'from clause'"},
+		{"select * from nope", "[planner:1146]Table 'test.nope' doesn't exist"},
+		{"select * from nodb.t", "[planner:1146]Table 'nodb.t' doesn't exist"},
+		{"select * from t, t", "[planner:1066]Not unique table/alias: 't'"},
+		{"select * from t x, s x", "[planner:1066]Not unique table/alias: 'x'"},
+		{"select *", "[planner:1096]No tables used"},
+		{"select u.* from t", "[planner:1051]Unknown table 'u'"},
+		{"select * from (select a, a from t) x", "[planner:1060]Duplicate column name 'a'"},
+		{"select * from t, (select x.b from t x) y where y.a = 1", "[planner:1054]Unknown column 'y.a' in 'where clause'"},
+		{"select * from t x, (select x.b from s) y", "[planner:1054]Unknown column 'x.b' in 'field list'"},
+		{"select a from t union select a, d from s", "[planner:1222]The used SELECT statements have a different number of columns"},
+		{"select a from t union select d from s order by t.a", "[planner:1250]Table 't' from one of the SELECTs cannot be used in global ORDER clause"},
+		{"with c (x, y) as (select a from t) select * from c", "[planner:1353]View's SELECT and view's field list have different column counts"},
+		{"with c as (select * from c) select * from c", "[planner:1146]Table 'test.c' doesn't exist"},
+		{"insert into t (a, x) values (1, 2)", "[planner:1054]Unknown column 'x' in 'field list'"},
+		{"insert into t (a, a) values (1, 2)", "[planner:1110]Column 'a' specified twice"},
+		{"insert into t (a, b) values (1, 2), (3)", "[planner:1136]Column count doesn't match value count at row 2"},
+		{"insert into t select a from s", "[planner:1136]Column count doesn't match value count at row 1"},
+		{"update t set x = 1", "[planner:1054]Unknown column 'x' in 'field list'"},
+		{"update t, s set a = 1", "[planner:1052]Column 'a' in field list is ambiguous"},
+		{"delete u from t, s", "[planner:1109]Unknown table 'u' in MULTI DELETE"},
+	}
+	for _, c := range cases {
+		_, _, err := resolve(t, c.sql)
+		require.EqualError(t, err, c.err, c.sql)
+	}
+
+	stmt, err := parser.New().ParseOneStmt("select * from t", "", "")
+	require.NoError(t, err)
+	require.EqualError(t, ResolveName(stmt, newInfoSchema(), ""), "[planner:1046]No database selected")
+	require.NoError(t, ResolveName(stmt, newInfoSchema(), "other"))
+}
@@ -187,6 +187,19 @@ func (n *ValueExpr) Accept(v ast.Visitor) (ast.Node, bool) {
 	return v.Leave(n)
 }
 
+// CloneNode implements ast.NodeCloner interface.
+func (n *ValueExpr) CloneNode() ast.Node {
+	c := new(ValueExpr)
+	n.copyTo(c)
+	return c
+}
+
+func (n *ValueExpr) copyTo(c *ValueExpr) {
+	*c = *n
+	ast.CopyTexprNode(&c.TexprNode, &n.TexprNode)
+	n.Datum.Copy(&c.Datum)
+}
+
 // ParamMarkerExpr expression holds a place for another expression.
 // Used in parsing prepare statement.
 type ParamMarkerExpr struct {
@@ -223,7 +236,25 @@ func (n *ParamMarkerExpr) Accept(v ast.Visitor) (ast.Node, bool) {
 	return v.Leave(n)
 }
 
+// CloneNode implements ast.NodeCloner interface.
+func (n *ParamMarkerExpr) CloneNode() ast.Node {
+	c := new(ParamMarkerExpr)
+	*c = *n
+	n.ValueExpr.copyTo(&c.ValueExpr)
+	return c
+}
+
 // SetOrder implements the ParamMarkerExpr interface.
 func (n *ParamMarkerExpr) SetOrder(order int) {
 	n.Order = order
 }
+
+// GetOffset implements the ast.ParamMarkerOffsetter interface.
+func (n *ParamMarkerExpr) GetOffset() int {
+	return n.Offset
+}
+
+// SetOffset implements the ast.ParamMarkerOffsetter interface.
+func (n *ParamMarkerExpr) SetOffset(offset int) {
+	n.Offset = offset
+}
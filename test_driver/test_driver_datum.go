@@ -139,6 +139,25 @@ func (d *Datum) SetBytesAsString(b []byte) {
 	d.b = b
 }
 
+// Copy deep copies the datum into dst.
+func (d *Datum) Copy(dst *Datum) {
+	*dst = *d
+	if d.b != nil {
+		dst.b = append([]byte(nil), d.b...)
+	}
+	switch x := d.x.(type) {
+	case *MyDecimal:
+		dec := *x
+		dst.x = &dec
+	case []Datum:
+		ds := make([]Datum, len(x))
+		for i := range x {
+			x[i].Copy(&ds[i])
+		}
+		dst.x = ds
+	}
+}
+
 // GetInterface gets interface value.
 func (d *Datum) GetInterface() interface{} {
 	return d.x
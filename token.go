@@ -0,0 +1,226 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"github.com/daiguadaidai/parser/mysql"
+)
+
+// TokenKind is the category of a Token.
+type TokenKind int
+
+// TokenKind values.
+const (
+	// TokenEOF is returned once the input is exhausted.
+	TokenEOF TokenKind = iota
+	// TokenInvalid is an illegal character or a malformed literal.
+	TokenInvalid
+	// TokenKeyword is a reserved or unreserved keyword, including builtin function names like COUNT.
+	TokenKeyword
+	// TokenIdentifier is an unquoted identifier.
+	TokenIdentifier
+	// TokenQuotedIdentifier is a back-quoted identifier, or a double-quoted one in ANSI_QUOTES mode.
+	TokenQuotedIdentifier
+	// TokenString is a string literal.
+	TokenString
+	// TokenNumber is an integer, decimal or floating point literal.
+	TokenNumber
+	// TokenHexLiteral is a hexadecimal literal like X'01' or 0x01.
+	TokenHexLiteral
+	// TokenBitLiteral is a bit-value literal like B'01' or 0b01.
+	TokenBitLiteral
+	// TokenCharsetIntroducer is a character set introducer like _utf8mb4 or N.
+	TokenCharsetIntroducer
+	// TokenVariable is a user variable (@a) or a system variable (@@a).
+	TokenVariable
+	// TokenParamMarker is the `?` placeholder of prepared statements.
+	TokenParamMarker
+	// TokenOptimizerHint is an optimizer hint comment like /*+ ... */.
+	TokenOptimizerHint
+	// TokenOperator is an operator or a punctuation character.
+	TokenOperator
+)
+
+var tokenKindNames = []string{
+	TokenEOF:               "EOF",
+	TokenInvalid:           "Invalid",
+	TokenKeyword:           "Keyword",
+	TokenIdentifier:        "Identifier",
+	TokenQuotedIdentifier:  "QuotedIdentifier",
+	TokenString:            "String",
+	TokenNumber:            "Number",
+	TokenHexLiteral:        "HexLiteral",
+	TokenBitLiteral:        "BitLiteral",
+	TokenCharsetIntroducer: "CharsetIntroducer",
+	TokenVariable:          "Variable",
+	TokenParamMarker:       "ParamMarker",
+	TokenOptimizerHint:     "OptimizerHint",
+	TokenOperator:          "Operator",
+}
+
+// String implements fmt.Stringer interface.
+func (k TokenKind) String() string {
+	if k >= 0 && int(k) < len(tokenKindNames) {
+		return tokenKindNames[k]
+	}
+	return "Unknown"
+}
+
+// Token is a lexical token of a SQL text.
+type Token struct {
+	Kind TokenKind
+	// ID is the token id used by the parser. For keywords it is the keyword id,
+	// for single character operators it is the character itself.
+	ID int
+	// Value is the literal value of the token: int64, uint64, float64 or decimal
+	// for numbers, the hex/bit literal for hex and bit values, nil for NULL and the
+	// unquoted, unescaped text for anything else.
+	Value interface{}
+	// Text is the original text of the token in the SQL.
+	Text string
+	// Start and End is the span of the token, End is exclusive.
+	Start Pos
+	End   Pos
+}
+
+// IsKeyword returns whether the token is a keyword.
+func (t *Token) IsKeyword() bool {
+	return t.Kind == TokenKeyword
+}
+
+// NextToken scans and returns the next token. Like Lex, it honors the SQL mode
+// of the scanner, such as ANSI_QUOTES, PIPES_AS_CONCAT and HIGH_NOT_PRECEDENCE.
+// A token with TokenEOF kind is returned at the end of the input.
+func (s *Scanner) NextToken() Token {
+	var v yySymType
+	tok := s.Lex(&v)
+	if tok == 0 {
+		pos := s.r.pos()
+		return Token{Kind: TokenEOF, Start: pos, End: pos}
+	}
+	start, end := s.tokenStartPos, s.r.pos()
+	if end.Offset < start.Offset {
+		end = start
+	}
+	t := Token{
+		ID:    tok,
+		Text:  s.r.s[start.Offset:end.Offset],
+		Start: start,
+		End:   end,
+	}
+	if v.item != nil {
+		t.Value = v.item
+	} else if tok != null {
+		t.Value = v.ident
+	}
+	t.Kind = s.tokenKind(tok, t.Text)
+	return t
+}
+
+func (s *Scanner) tokenKind(tok int, text string) TokenKind {
+	switch tok {
+	case invalid:
+		return TokenInvalid
+	case identifier:
+		if len(text) > 0 && (text[0] == '`' || text[0] == '"') {
+			return TokenQuotedIdentifier
+		}
+		return TokenIdentifier
+	case stringLit:
+		return TokenString
+	case intLit, floatLit, decLit:
+		return TokenNumber
+	case hexLit:
+		return TokenHexLiteral
+	case bitLit:
+		return TokenBitLiteral
+	case underscoreCS:
+		return TokenCharsetIntroducer
+	case singleAtIdentifier, doubleAtIdentifier:
+		return TokenVariable
+	case paramMarker:
+		return TokenParamMarker
+	case hintComment:
+		return TokenOptimizerHint
+	}
+	if s.lastKeyword != 0 || tok == null {
+		return TokenKeyword
+	}
+	return TokenOperator
+}
+
+// TokenIterator iterates over the tokens of a SQL text.
+//
+//	it, err := parser.Tokenize("select * from t")
+//	...
+//	for it.Next() {
+//		tok := it.Token()
+//		...
+//	}
+type TokenIterator struct {
+	lexer *Scanner
+	tok   Token
+	done  bool
+}
+
+// Tokenize returns an iterator over the tokens of sql. The SQL mode defaults to
+// mysql.DefaultSQLMode and can be changed by the SQLModeParam parameter, the
+// client charset can be set by CharsetClient.
+func Tokenize(sql string, params ...ParseParam) (*TokenIterator, error) {
+	p := &Parser{}
+	resetParams(p)
+	p.lexer.reset(sql)
+	p.EnableWindowFunc(true)
+	mode, _ := mysql.GetSQLMode(mysql.DefaultSQLMode)
+	p.SetSQLMode(mode)
+	for _, param := range params {
+		if err := param.ApplyOn(p); err != nil {
+			return nil, err
+		}
+	}
+	return &TokenIterator{lexer: &p.lexer}, nil
+}
+
+// Next advances the iterator to the next token. It returns false at the end of
+// the input; the terminating TokenEOF token is not reported.
+func (it *TokenIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	it.tok = it.lexer.NextToken()
+	if it.tok.Kind == TokenEOF {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Token returns the current token.
+func (it *TokenIterator) Token() Token {
+	return it.tok
+}
+
+// Errors returns the errors and warns during tokenizing.
+func (it *TokenIterator) Errors() (warns []error, errs []error) {
+	return it.lexer.Errors()
+}
+
+// All drains the iterator and returns the remaining tokens.
+func (it *TokenIterator) All() []Token {
+	var tokens []Token
+	for it.Next() {
+		tokens = append(tokens, it.Token())
+	}
+	return tokens
+}
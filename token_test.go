@@ -0,0 +1,100 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/daiguadaidai/parser/mysql"
+	requires "github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	it, err := Tokenize("SELECT /*+ HASH_JOIN(t) */ `a`, b, 'x''y', 1.5, 0x1F, @v, ? FROM t WHERE c >= 10 -- tail")
+	requires.NoError(t, err)
+	tokens := it.All()
+
+	type expect struct {
+		kind TokenKind
+		text string
+	}
+	expects := []expect{
+		{TokenKeyword, "SELECT"},
+		{TokenOptimizerHint, "/*+ HASH_JOIN(t) */"},
+		{TokenQuotedIdentifier, "`a`"},
+		{TokenOperator, ","},
+		{TokenIdentifier, "b"},
+		{TokenOperator, ","},
+		{TokenString, "'x''y'"},
+		{TokenOperator, ","},
+		{TokenNumber, "1.5"},
+		{TokenOperator, ","},
+		{TokenHexLiteral, "0x1F"},
+		{TokenOperator, ","},
+		{TokenVariable, "@v"},
+		{TokenOperator, ","},
+		{TokenParamMarker, "?"},
+		{TokenKeyword, "FROM"},
+		{TokenIdentifier, "t"},
+		{TokenKeyword, "WHERE"},
+		{TokenIdentifier, "c"},
+		{TokenOperator, ">="},
+		{TokenNumber, "10"},
+	}
+	requires.Len(t, tokens, len(expects))
+	for i, e := range expects {
+		requires.Equal(t, e.kind, tokens[i].Kind, "token %d %q", i, tokens[i].Text)
+		requires.Equal(t, e.text, tokens[i].Text, "token %d", i)
+	}
+
+	requires.Equal(t, selectKwd, tokens[0].ID)
+	requires.Equal(t, "a", tokens[2].Value)
+	requires.Equal(t, "x'y", tokens[6].Value)
+	requires.Equal(t, int64(10), tokens[20].Value)
+	requires.Equal(t, 0, tokens[0].Start.Offset)
+	requires.Equal(t, 6, tokens[0].End.Offset)
+	warns, errs := it.Errors()
+	requires.Len(t, warns, 0)
+	requires.Len(t, errs, 0)
+}
+
+func TestTokenizeSQLMode(t *testing.T) {
+	sql := `select "a" || not b`
+	it, err := Tokenize(sql)
+	requires.NoError(t, err)
+	tokens := it.All()
+	requires.Equal(t, TokenString, tokens[1].Kind)
+	requires.Equal(t, pipesAsOr, tokens[2].ID)
+	requires.Equal(t, not, tokens[3].ID)
+
+	mode, err := mysql.GetSQLMode("ANSI_QUOTES,PIPES_AS_CONCAT,HIGH_NOT_PRECEDENCE")
+	requires.NoError(t, err)
+	it, err = Tokenize(sql, SQLModeParam(mode))
+	requires.NoError(t, err)
+	tokens = it.All()
+	requires.Equal(t, TokenQuotedIdentifier, tokens[1].Kind)
+	requires.Equal(t, "a", tokens[1].Value)
+	requires.Equal(t, pipes, tokens[2].ID)
+	requires.Equal(t, not2, tokens[3].ID)
+	requires.Equal(t, TokenKeyword, tokens[3].Kind)
+}
+
+func TestTokenizeAsOf(t *testing.T) {
+	it, err := Tokenize("select * from t as of timestamp now()")
+	requires.NoError(t, err)
+	tokens := it.All()
+	requires.Equal(t, asof, tokens[4].ID)
+	requires.Equal(t, "as of", tokens[4].Text)
+	requires.Equal(t, TokenKeyword, tokens[4].Kind)
+}
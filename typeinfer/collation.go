@@ -0,0 +1,155 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeinfer
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/terror"
+)
+
+// Coercibility is the collation coercibility of an expression, the value
+// is the one returned by the COERCIBILITY() function, a lower value has a
+// higher precedence.
+// See https://dev.mysql.com/doc/refman/8.0/en/charset-collation-coercibility.html
+type Coercibility int
+
+const (
+	// CoercibilityExplicit is an explicit COLLATE clause.
+	CoercibilityExplicit Coercibility = iota
+	// CoercibilityNone is the concatenation of two strings with different collations.
+	CoercibilityNone
+	// CoercibilityImplicit is a column or a stored routine parameter.
+	CoercibilityImplicit
+	// CoercibilitySysconst is a system constant like the result of USER().
+	CoercibilitySysconst
+	// CoercibilityCoercible is a literal string.
+	CoercibilityCoercible
+	// CoercibilityNumeric is a numeric or temporal value.
+	CoercibilityNumeric
+	// CoercibilityIgnorable is NULL or an expression derived from NULL.
+	CoercibilityIgnorable
+)
+
+var coercibilityNames = [...]string{
+	CoercibilityExplicit:  "EXPLICIT",
+	CoercibilityNone:      "NONE",
+	CoercibilityImplicit:  "IMPLICIT",
+	CoercibilitySysconst:  "SYSCONST",
+	CoercibilityCoercible: "COERCIBLE",
+	CoercibilityNumeric:   "NUMERIC",
+	CoercibilityIgnorable: "IGNORABLE",
+}
+
+// String implements fmt.Stringer interface.
+func (c Coercibility) String() string {
+	if c >= 0 && int(c) < len(coercibilityNames) {
+		return coercibilityNames[c]
+	}
+	return "UNKNOWN"
+}
+
+var (
+	// ErrIllegalMix2Collation is returned when two collations can not be aggregated.
+	ErrIllegalMix2Collation = terror.ClassExpression.NewStd(mysql.ErrCantAggregate2collations)
+	// ErrIllegalMix3Collation is returned when three collations can not be aggregated.
+	ErrIllegalMix3Collation = terror.ClassExpression.NewStd(mysql.ErrCantAggregate3collations)
+	// ErrIllegalMixNCollation is returned when more collations can not be aggregated.
+	ErrIllegalMixNCollation = terror.ClassExpression.NewStd(mysql.ErrCantAggregateNcollations)
+)
+
+// collation is the charset, the collation and the coercibility of a string.
+type collation struct {
+	charset      string
+	collation    string
+	coercibility Coercibility
+}
+
+func isBinCollation(c string) bool {
+	return c == charset.CollationBin || strings.HasSuffix(c, "_bin")
+}
+
+func isUnicode(cs string) bool {
+	switch cs {
+	case charset.CharsetUTF8, charset.CharsetUTF8MB4, "ucs2", "utf16", "utf16le", "utf32":
+		return true
+	}
+	return false
+}
+
+// aggregateCollation merges two collations by the rules of MySQL:
+// the lower coercibility wins, on the same coercibility a binary string wins,
+// a _bin collation wins in the same charset and a Unicode charset wins over a
+// non-Unicode one. It returns false if the collations are incompatible.
+func aggregateCollation(a, b collation) (collation, bool) {
+	switch {
+	case b.coercibility == CoercibilityIgnorable:
+		return a, true
+	case a.coercibility == CoercibilityIgnorable:
+		return b, true
+	case a.collation == b.collation:
+		if b.coercibility < a.coercibility {
+			a.coercibility = b.coercibility
+		}
+		return a, true
+	case a.coercibility < b.coercibility:
+		return a, true
+	case b.coercibility < a.coercibility:
+		return b, true
+	case a.coercibility == CoercibilityExplicit:
+		return collation{}, false
+	case a.charset == charset.CharsetBin:
+		return a, true
+	case b.charset == charset.CharsetBin:
+		return b, true
+	case a.charset == b.charset:
+		if isBinCollation(a.collation) {
+			return a, true
+		}
+		if isBinCollation(b.collation) {
+			return b, true
+		}
+	case isUnicode(a.charset) && !isUnicode(b.charset):
+		return a, true
+	case isUnicode(b.charset) && !isUnicode(a.charset):
+		return b, true
+	case a.charset == charset.CharsetUTF8MB4 && b.charset == charset.CharsetUTF8:
+		return a, true
+	case b.charset == charset.CharsetUTF8MB4 && a.charset == charset.CharsetUTF8:
+		return b, true
+	}
+	return collation{}, false
+}
+
+// aggregateCollations merges the collations of the arguments of op, it
+// returns the "Illegal mix of collations" error if they are incompatible.
+func aggregateCollations(op string, colls []collation) (collation, error) {
+	res := collation{coercibility: CoercibilityIgnorable}
+	for _, c := range colls {
+		var ok bool
+		if res, ok = aggregateCollation(res, c); ok {
+			continue
+		}
+		switch len(colls) {
+		case 2:
+			return collation{}, ErrIllegalMix2Collation.GenWithStackByArgs(colls[0].collation, colls[0].coercibility, colls[1].collation, colls[1].coercibility, op)
+		case 3:
+			return collation{}, ErrIllegalMix3Collation.GenWithStackByArgs(colls[0].collation, colls[0].coercibility, colls[1].collation, colls[1].coercibility, colls[2].collation, colls[2].coercibility, op)
+		}
+		return collation{}, ErrIllegalMixNCollation.GenWithStackByArgs(op)
+	}
+	return res, nil
+}
@@ -0,0 +1,811 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeinfer
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// funcInferrer infers the result type of a function from its arguments.
+type funcInferrer func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error)
+
+// fixed returns a funcInferrer of a function whose result type does not
+// depend on the arguments.
+func fixed(newType func() *types.FieldType) funcInferrer {
+	return func(*Inferrer, string, []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		ft := newType()
+		return ft, coercibilityOf(ft), nil
+	}
+}
+
+var (
+	intFunc    = fixed(func() *types.FieldType { return newIntType(false) })
+	uintFunc   = fixed(func() *types.FieldType { return newIntType(true) })
+	boolFunc   = fixed(newBoolType)
+	doubleFunc = fixed(newDoubleType)
+	dateFunc   = fixed(newDateType)
+	jsonFunc   = fixed(newJSONType)
+)
+
+// width computes the length of the result string of a function.
+type width func(args []ast.ExprNode) int
+
+func fixedWidth(n int) width {
+	return func([]ast.ExprNode) int { return n }
+}
+
+// argWidth returns the display width of the k-th argument.
+func argWidth(k int) width {
+	return func(args []ast.ExprNode) int {
+		if k >= len(args) {
+			return 0
+		}
+		return displayWidth(args[k].GetType())
+	}
+}
+
+// scaledWidth returns the display width of the k-th argument scaled by mul/div.
+func scaledWidth(k, mul, div, add int) width {
+	return func(args []ast.ExprNode) int {
+		return (argWidth(k)(args)*mul+div-1)/div + add
+	}
+}
+
+// sumWidth returns the sum of the display widths of the arguments from k.
+func sumWidth(k int) width {
+	return func(args []ast.ExprNode) int {
+		res := 0
+		for _, arg := range args[min(k, len(args)):] {
+			res += displayWidth(arg.GetType())
+		}
+		return res
+	}
+}
+
+// maxWidth returns the max display width of the arguments from k.
+func maxWidth(k int) width {
+	return func(args []ast.ExprNode) int {
+		res := 0
+		for _, arg := range args[min(k, len(args)):] {
+			res = max(res, displayWidth(arg.GetType()))
+		}
+		return res
+	}
+}
+
+// constWidth returns the constant integer value of the k-th argument times
+// the display width of the m-th argument (or 1 if m is negative), or def if
+// the k-th argument is not a constant.
+func constWidth(k, m int, def width) width {
+	return func(args []ast.ExprNode) int {
+		n, ok := constInt(args, k)
+		if !ok {
+			return def(args)
+		}
+		if n < 0 {
+			n = 0
+		}
+		if m >= 0 {
+			n *= int64(argWidth(m)(args))
+		}
+		return int(min64(n, mysql.MaxBlobWidth))
+	}
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// constInt returns the value of the k-th argument if it is an integer literal.
+func constInt(args []ast.ExprNode, k int) (int64, bool) {
+	if k >= len(args) {
+		return 0, false
+	}
+	v, ok := args[k].(ast.ValueExpr)
+	if !ok {
+		return 0, false
+	}
+	switch x := v.GetValue().(type) {
+	case int64:
+		return x, true
+	case uint64:
+		if x > mysql.MaxBlobWidth {
+			return mysql.MaxBlobWidth, true
+		}
+		return int64(x), true
+	}
+	return 0, false
+}
+
+// constString returns the value of the k-th argument if it is a string literal.
+func constString(args []ast.ExprNode, k int) (string, bool) {
+	if k >= len(args) {
+		return "", false
+	}
+	v, ok := args[k].(ast.ValueExpr)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.GetValue().(string)
+	return s, ok
+}
+
+// stringFunc returns a funcInferrer of a function returning a string in
+// the collation aggregated from the arguments.
+func stringFunc(w width) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		return i.stringResult(name, w(args), args)
+	}
+}
+
+// connStringFunc returns a funcInferrer of a function returning a string in
+// the connection collation.
+func connStringFunc(w width) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		return newStringType(w(args), i.Charset, i.Collation), CoercibilityCoercible, nil
+	}
+}
+
+// binaryFunc returns a funcInferrer of a function returning a binary string.
+func binaryFunc(w width) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		return newStringType(w(args), charset.CharsetBin, charset.CollationBin), CoercibilityCoercible, nil
+	}
+}
+
+// sysconstFunc returns a funcInferrer of a function returning a system
+// constant string.
+func sysconstFunc(w width) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		return newStringType(w(args), mysql.DefaultCharset, mysql.DefaultCollationName), CoercibilitySysconst, nil
+	}
+}
+
+// jsonStringFunc returns a funcInferrer of a function returning a string
+// converted from a JSON value.
+func jsonStringFunc(w width) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		return newStringType(w(args), mysql.DefaultCharset, mysql.DefaultCollationName), CoercibilityImplicit, nil
+	}
+}
+
+// argTypeFunc returns a funcInferrer of a function returning the type of
+// its k-th argument.
+func argTypeFunc(k int) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		if k >= len(args) {
+			return nil, CoercibilityNumeric, nil
+		}
+		ft := args[k].GetType().Clone()
+		ft.DelFlag(mysql.NotNullFlag)
+		return ft, i.Coercibility(args[k]), nil
+	}
+}
+
+// mergeFunc returns a funcInferrer of a function returning one of its
+// arguments from k, like COALESCE.
+func mergeFunc(k int) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		return i.mergeResults(name, args[min(k, len(args)):])
+	}
+}
+
+// fspOf returns the fractional seconds precision of a value of the type
+// converted to a temporal value.
+func fspOf(ft *types.FieldType) int {
+	switch ft.EvalType() {
+	case types.ETInt:
+		return 0
+	case types.ETString, types.ETReal, types.ETJson:
+		if !isNull(ft) {
+			return 6
+		}
+	}
+	return min(decimalOf(ft), 6)
+}
+
+// fspArg returns the fsp given by the k-th argument like NOW(3).
+func fspArg(args []ast.ExprNode, k int) int {
+	n, _ := constInt(args, k)
+	return int(n)
+}
+
+// temporalFunc returns a funcInferrer of a function returning a DATETIME,
+// TIMESTAMP or TIME whose fsp is given by fsp.
+func temporalFunc(tp byte, fsp func(args []ast.ExprNode) int) funcInferrer {
+	return func(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+		return newTemporalType(tp, fsp(args)), CoercibilityNumeric, nil
+	}
+}
+
+// fspOfArgs returns the max fsp of the arguments.
+func fspOfArgs(args []ast.ExprNode) int {
+	fsp := 0
+	for _, arg := range args {
+		fsp = max(fsp, fspOf(arg.GetType()))
+	}
+	return fsp
+}
+
+func fspOfArg(k int) func(args []ast.ExprNode) int {
+	return func(args []ast.ExprNode) int {
+		if k >= len(args) {
+			return 0
+		}
+		return fspOf(args[k].GetType())
+	}
+}
+
+func fspFromArg(k int) func(args []ast.ExprNode) int {
+	return func(args []ast.ExprNode) int {
+		return fspArg(args, k)
+	}
+}
+
+// literalFsp returns the fsp of a temporal literal like TIME '10:00:00.123'.
+func literalFsp(args []ast.ExprNode) int {
+	s, _ := constString(args, 0)
+	if idx := strings.LastIndexByte(s, '.'); idx >= 0 {
+		return len(strings.TrimSpace(s[idx+1:]))
+	}
+	return 0
+}
+
+var funcs = map[string]funcInferrer{
+	// control functions
+	ast.If:       mergeFunc(1),
+	ast.Ifnull:   mergeFunc(0),
+	ast.Coalesce: mergeFunc(0),
+	ast.Greatest: mergeFunc(0),
+	ast.Least:    mergeFunc(0),
+	ast.Nullif:   inferNullif,
+	ast.Interval: intFunc,
+	ast.IsNull:   boolFunc,
+
+	// math functions
+	ast.Abs:      inferAbs,
+	ast.Ceil:     inferCeil,
+	ast.Ceiling:  inferCeil,
+	ast.Floor:    inferCeil,
+	ast.Round:    inferRound,
+	ast.Truncate: inferRound,
+	ast.Sign:     intFunc,
+	ast.CRC32:    uintFunc,
+	ast.BitCount: intFunc,
+	ast.Conv:     connStringFunc(fixedWidth(64)),
+	ast.Acos:     doubleFunc,
+	ast.Asin:     doubleFunc,
+	ast.Atan:     doubleFunc,
+	ast.Atan2:    doubleFunc,
+	ast.Cos:      doubleFunc,
+	ast.Cot:      doubleFunc,
+	ast.Degrees:  doubleFunc,
+	ast.Exp:      doubleFunc,
+	ast.Ln:       doubleFunc,
+	ast.Log:      doubleFunc,
+	ast.Log2:     doubleFunc,
+	ast.Log10:    doubleFunc,
+	ast.PI:       doubleFunc,
+	ast.Pow:      doubleFunc,
+	ast.Power:    doubleFunc,
+	ast.Radians:  doubleFunc,
+	ast.Rand:     doubleFunc,
+	ast.Sin:      doubleFunc,
+	ast.Sqrt:     doubleFunc,
+	ast.Tan:      doubleFunc,
+
+	// string functions
+	ast.Concat:          stringFunc(sumWidth(0)),
+	ast.ConcatWS:        inferConcatWS,
+	ast.Lower:           stringFunc(argWidth(0)),
+	ast.Lcase:           stringFunc(argWidth(0)),
+	ast.Upper:           stringFunc(argWidth(0)),
+	ast.Ucase:           stringFunc(argWidth(0)),
+	ast.Reverse:         stringFunc(argWidth(0)),
+	ast.Trim:            stringFunc(argWidth(0)),
+	ast.LTrim:           stringFunc(argWidth(0)),
+	ast.RTrim:           stringFunc(argWidth(0)),
+	ast.Soundex:         stringFunc(argWidth(0)),
+	ast.Substring:       stringFunc(argWidth(0)),
+	ast.Substr:          stringFunc(argWidth(0)),
+	ast.Mid:             stringFunc(argWidth(0)),
+	ast.SubstringIndex:  stringFunc(argWidth(0)),
+	ast.Replace:         stringFunc(argWidth(0)),
+	ast.Translate:       stringFunc(argWidth(0)),
+	ast.Left:            stringFunc(constWidth(1, -1, argWidth(0))),
+	ast.Right:           stringFunc(constWidth(1, -1, argWidth(0))),
+	ast.Lpad:            stringFunc(constWidth(1, -1, argWidth(0))),
+	ast.Rpad:            stringFunc(constWidth(1, -1, argWidth(0))),
+	ast.Repeat:          stringFunc(constWidth(1, 0, fixedWidth(mysql.MaxBlobWidth))),
+	ast.Space:           connStringFunc(constWidth(0, -1, fixedWidth(mysql.MaxBlobWidth))),
+	ast.InsertFunc:      inferInsert,
+	ast.Quote:           stringFunc(scaledWidth(0, 2, 1, 2)),
+	ast.Elt:             stringFunc(maxWidth(1)),
+	ast.MakeSet:         inferMakeSet,
+	ast.ExportSet:       stringFunc(fixedWidth(mysql.MaxBlobWidth)),
+	ast.Format:          connStringFunc(scaledWidth(0, 4, 3, 32)),
+	ast.Hex:             connStringFunc(scaledWidth(0, 2, 1, 0)),
+	ast.Unhex:           binaryFunc(scaledWidth(0, 1, 2, 0)),
+	ast.ToBase64:        connStringFunc(scaledWidth(0, 4, 3, 0)),
+	ast.FromBase64:      binaryFunc(scaledWidth(0, 3, 4, 0)),
+	ast.Bin:             connStringFunc(fixedWidth(64)),
+	ast.Oct:             connStringFunc(fixedWidth(64)),
+	ast.CharFunc:        inferChar,
+	ast.Convert:         inferConvert,
+	ast.LoadFile:        binaryFunc(fixedWidth(mysql.MaxBlobWidth)),
+	ast.WeightString:    binaryFunc(argWidth(0)),
+	ast.ASCII:           intFunc,
+	ast.Ord:             intFunc,
+	ast.Length:          intFunc,
+	ast.OctetLength:     intFunc,
+	ast.BitLength:       intFunc,
+	ast.CharLength:      intFunc,
+	ast.CharacterLength: intFunc,
+	ast.Locate:          intFunc,
+	ast.Instr:           intFunc,
+	ast.Position:        intFunc,
+	ast.Strcmp:          inferStrcmp,
+	ast.Field:           intFunc,
+	ast.FindInSet:       intFunc,
+
+	// temporal functions
+	ast.Curdate:          dateFunc,
+	ast.CurrentDate:      dateFunc,
+	ast.UTCDate:          dateFunc,
+	ast.Date:             dateFunc,
+	ast.DateLiteral:      dateFunc,
+	ast.FromDays:         dateFunc,
+	ast.MakeDate:         dateFunc,
+	ast.LastDay:          dateFunc,
+	ast.Now:              temporalFunc(mysql.TypeDatetime, fspFromArg(0)),
+	ast.CurrentTimestamp: temporalFunc(mysql.TypeDatetime, fspFromArg(0)),
+	ast.LocalTime:        temporalFunc(mysql.TypeDatetime, fspFromArg(0)),
+	ast.LocalTimestamp:   temporalFunc(mysql.TypeDatetime, fspFromArg(0)),
+	ast.Sysdate:          temporalFunc(mysql.TypeDatetime, fspFromArg(0)),
+	ast.UTCTimestamp:     temporalFunc(mysql.TypeDatetime, fspFromArg(0)),
+	ast.Curtime:          temporalFunc(mysql.TypeDuration, fspFromArg(0)),
+	ast.CurrentTime:      temporalFunc(mysql.TypeDuration, fspFromArg(0)),
+	ast.UTCTime:          temporalFunc(mysql.TypeDuration, fspFromArg(0)),
+	ast.Time:             temporalFunc(mysql.TypeDuration, fspOfArg(0)),
+	ast.TimeLiteral:      temporalFunc(mysql.TypeDuration, literalFsp),
+	ast.TimestampLiteral: temporalFunc(mysql.TypeDatetime, literalFsp),
+	ast.Timestamp:        temporalFunc(mysql.TypeDatetime, fspOfArgs),
+	ast.ConvertTz:        temporalFunc(mysql.TypeDatetime, fspOfArg(0)),
+	ast.TimeDiff:         temporalFunc(mysql.TypeDuration, fspOfArgs),
+	ast.MakeTime:         temporalFunc(mysql.TypeDuration, fspOfArg(2)),
+	ast.SecToTime:        temporalFunc(mysql.TypeDuration, fspOfArg(0)),
+	ast.DateAdd:          inferDateArith,
+	ast.DateSub:          inferDateArith,
+	ast.AddDate:          inferDateArith,
+	ast.SubDate:          inferDateArith,
+	ast.TimestampAdd:     inferDateArith,
+	ast.AddTime:          inferAddTime,
+	ast.SubTime:          inferAddTime,
+	ast.StrToDate:        inferStrToDate,
+	ast.FromUnixTime:     inferFromUnixTime,
+	ast.UnixTimestamp:    inferUnixTimestamp,
+	ast.DateFormat:       connStringFunc(fixedWidth(64)),
+	ast.TimeFormat:       connStringFunc(fixedWidth(64)),
+	ast.DayName:          connStringFunc(fixedWidth(9)),
+	ast.MonthName:        connStringFunc(fixedWidth(9)),
+	ast.GetFormat:        connStringFunc(fixedWidth(17)),
+	ast.DateDiff:         intFunc,
+	ast.Day:              intFunc,
+	ast.DayOfMonth:       intFunc,
+	ast.DayOfWeek:        intFunc,
+	ast.DayOfYear:        intFunc,
+	ast.Extract:          intFunc,
+	ast.Hour:             intFunc,
+	ast.MicroSecond:      intFunc,
+	ast.Minute:           intFunc,
+	ast.Month:            intFunc,
+	ast.PeriodAdd:        intFunc,
+	ast.PeriodDiff:       intFunc,
+	ast.Quarter:          intFunc,
+	ast.Second:           intFunc,
+	ast.TimeToSec:        intFunc,
+	ast.TimestampDiff:    intFunc,
+	ast.ToDays:           intFunc,
+	ast.ToSeconds:        intFunc,
+	ast.Week:             intFunc,
+	ast.Weekday:          intFunc,
+	ast.WeekOfYear:       intFunc,
+	ast.Year:             intFunc,
+	ast.YearWeek:         intFunc,
+
+	// information functions
+	ast.Charset:      sysconstFunc(fixedWidth(64)),
+	ast.Collation:    sysconstFunc(fixedWidth(64)),
+	ast.Database:     sysconstFunc(fixedWidth(64)),
+	ast.Schema:       sysconstFunc(fixedWidth(64)),
+	ast.User:         sysconstFunc(fixedWidth(64)),
+	ast.CurrentUser:  sysconstFunc(fixedWidth(64)),
+	ast.SessionUser:  sysconstFunc(fixedWidth(64)),
+	ast.SystemUser:   sysconstFunc(fixedWidth(64)),
+	ast.CurrentRole:  sysconstFunc(fixedWidth(64)),
+	ast.Version:      sysconstFunc(fixedWidth(64)),
+	ast.TiDBVersion:  sysconstFunc(fixedWidth(mysql.MaxBlobWidth)),
+	ast.Coercibility: intFunc,
+	ast.ConnectionID: uintFunc,
+	ast.LastInsertId: uintFunc,
+	ast.FoundRows:    intFunc,
+	ast.RowCount:     intFunc,
+	ast.Benchmark:    intFunc,
+
+	// miscellaneous functions
+	ast.AnyValue:        argTypeFunc(0),
+	ast.NameConst:       argTypeFunc(1),
+	ast.Sleep:           intFunc,
+	ast.GetLock:         intFunc,
+	ast.ReleaseLock:     intFunc,
+	ast.ReleaseAllLocks: intFunc,
+	ast.IsFreeLock:      intFunc,
+	ast.IsUsedLock:      intFunc,
+	ast.UUID:            connStringFunc(fixedWidth(36)),
+	ast.UUIDShort:       uintFunc,
+	ast.UUIDToBin:       binaryFunc(fixedWidth(16)),
+	ast.BinToUUID:       connStringFunc(fixedWidth(36)),
+	ast.IsUUID:          boolFunc,
+	ast.InetAton:        uintFunc,
+	ast.InetNtoa:        connStringFunc(fixedWidth(15)),
+	ast.Inet6Aton:       binaryFunc(fixedWidth(16)),
+	ast.Inet6Ntoa:       connStringFunc(fixedWidth(46)),
+	ast.IsIPv4:          boolFunc,
+	ast.IsIPv4Compat:    boolFunc,
+	ast.IsIPv4Mapped:    boolFunc,
+	ast.IsIPv6:          boolFunc,
+	ast.VitessHash:      uintFunc,
+
+	// encryption functions
+	ast.MD5:                      connStringFunc(fixedWidth(32)),
+	ast.SHA1:                     connStringFunc(fixedWidth(40)),
+	ast.SHA:                      connStringFunc(fixedWidth(40)),
+	ast.SHA2:                     connStringFunc(fixedWidth(128)),
+	ast.PasswordFunc:             connStringFunc(fixedWidth(41)),
+	ast.AesEncrypt:               binaryFunc(scaledWidth(0, 1, 16, 16)),
+	ast.AesDecrypt:               binaryFunc(argWidth(0)),
+	ast.Compress:                 binaryFunc(scaledWidth(0, 1, 1, 13)),
+	ast.Uncompress:               binaryFunc(fixedWidth(mysql.MaxBlobWidth)),
+	ast.RandomBytes:              binaryFunc(constWidth(0, -1, fixedWidth(1024))),
+	ast.UncompressedLength:       intFunc,
+	ast.ValidatePasswordStrength: intFunc,
+
+	// json functions
+	ast.JSONExtract:       jsonFunc,
+	ast.JSONArray:         jsonFunc,
+	ast.JSONObject:        jsonFunc,
+	ast.JSONMerge:         jsonFunc,
+	ast.JSONMergePatch:    jsonFunc,
+	ast.JSONMergePreserve: jsonFunc,
+	ast.JSONSet:           jsonFunc,
+	ast.JSONInsert:        jsonFunc,
+	ast.JSONReplace:       jsonFunc,
+	ast.JSONRemove:        jsonFunc,
+	ast.JSONArrayAppend:   jsonFunc,
+	ast.JSONArrayInsert:   jsonFunc,
+	ast.JSONKeys:          jsonFunc,
+	ast.JSONSearch:        jsonFunc,
+	ast.JSONContains:      boolFunc,
+	ast.JSONContainsPath:  boolFunc,
+	ast.JSONValid:         boolFunc,
+	ast.JSONDepth:         intFunc,
+	ast.JSONLength:        intFunc,
+	ast.JSONStorageSize:   intFunc,
+	ast.JSONType:          jsonStringFunc(fixedWidth(51)),
+	ast.JSONUnquote:       jsonStringFunc(fixedWidth(mysql.MaxBlobWidth)),
+	ast.JSONPretty:        jsonStringFunc(fixedWidth(mysql.MaxBlobWidth)),
+	ast.JSONQuote:         stringFunc(scaledWidth(0, 6, 1, 2)),
+}
+
+// inferFunc infers the type of a function call, the type of an unknown
+// function is kept.
+func (i *Inferrer) inferFunc(x *ast.FuncCallExpr) (*types.FieldType, Coercibility, error) {
+	infer, ok := funcs[x.FnName.L]
+	if !ok {
+		return nil, coercibilityOf(x.GetType()), nil
+	}
+	return infer(i, x.FnName.L, x.Args)
+}
+
+func inferNullif(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if len(args) != 2 {
+		return nil, CoercibilityNumeric, nil
+	}
+	if err := i.checkComparison(name, args...); err != nil {
+		return nil, 0, err
+	}
+	return argTypeFunc(0)(i, name, args)
+}
+
+func inferStrcmp(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if _, err := i.aggregateArgs(name, args); err != nil {
+		return nil, 0, err
+	}
+	return newIntType(false), CoercibilityNumeric, nil
+}
+
+func inferConcatWS(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	flen := sumWidth(1)(args)
+	if len(args) > 2 {
+		flen += argWidth(0)(args) * (len(args) - 2)
+	}
+	return i.stringResult(name, flen, args)
+}
+
+func inferInsert(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	return i.stringResult(name, argWidth(0)(args)+argWidth(3)(args), args)
+}
+
+func inferMakeSet(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	flen := sumWidth(1)(args)
+	if len(args) > 2 {
+		flen += len(args) - 2
+	}
+	return i.stringResult(name, flen, args)
+}
+
+// inferChar infers CHAR(N, ... USING charset_name), whose last argument is
+// the charset name or NULL.
+func inferChar(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	flen := 4 * max(len(args)-1, 0)
+	if cs, ok := constString(args, len(args)-1); ok && cs != "" {
+		coll, err := charset.GetDefaultCollation(cs)
+		if err != nil {
+			return nil, 0, err
+		}
+		return newStringType(flen, cs, coll), CoercibilityImplicit, nil
+	}
+	return newStringType(flen, charset.CharsetBin, charset.CollationBin), CoercibilityCoercible, nil
+}
+
+// inferConvert infers CONVERT(expr USING charset_name).
+func inferConvert(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	cs, ok := constString(args, 1)
+	if !ok {
+		return newStringType(argWidth(0)(args), i.Charset, i.Collation), CoercibilityImplicit, nil
+	}
+	cs = strings.ToLower(cs)
+	coll, err := charset.GetDefaultCollation(cs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return newStringType(argWidth(0)(args), cs, coll), CoercibilityImplicit, nil
+}
+
+func inferAbs(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if len(args) != 1 {
+		return nil, CoercibilityNumeric, nil
+	}
+	ft := args[0].GetType()
+	switch arithmeticKind(ft) {
+	case types.ETInt:
+		return newIntType(isUnsigned(ft)), CoercibilityNumeric, nil
+	case types.ETDecimal:
+		return newDecimalType(flenOf(ft), decimalOf(ft)), CoercibilityNumeric, nil
+	}
+	return newDoubleType(), CoercibilityNumeric, nil
+}
+
+// inferCeil infers CEIL and FLOOR, they return integers for integers and
+// decimals.
+func inferCeil(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if len(args) != 1 {
+		return nil, CoercibilityNumeric, nil
+	}
+	ft := args[0].GetType()
+	switch arithmeticKind(ft) {
+	case types.ETInt:
+		return newIntType(isUnsigned(ft)), CoercibilityNumeric, nil
+	case types.ETDecimal:
+		flen := flenOf(ft) - decimalOf(ft) + 1
+		if flen < mysql.MaxIntWidth-1 {
+			return newIntType(isUnsigned(ft)), CoercibilityNumeric, nil
+		}
+		return newDecimalType(flen, 0), CoercibilityNumeric, nil
+	}
+	return newDoubleType(), CoercibilityNumeric, nil
+}
+
+// inferRound infers ROUND and TRUNCATE, the decimal of a rounded decimal is
+// the constant second argument.
+func inferRound(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if len(args) == 0 {
+		return nil, CoercibilityNumeric, nil
+	}
+	ft := args[0].GetType()
+	switch arithmeticKind(ft) {
+	case types.ETInt:
+		return newIntType(isUnsigned(ft)), CoercibilityNumeric, nil
+	case types.ETDecimal:
+		dec := 0
+		if len(args) > 1 {
+			d, ok := constInt(args, 1)
+			if !ok {
+				d = int64(decimalOf(ft))
+			}
+			dec = int(min64(max64(d, 0), mysql.MaxDecimalScale))
+		}
+		return newDecimalType(flenOf(ft)-decimalOf(ft)+dec+1, dec), CoercibilityNumeric, nil
+	}
+	return newDoubleType(), CoercibilityNumeric, nil
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// inferDateArith infers DATE_ADD and the like, a DATE plus days is a DATE,
+// a temporal value is a DATETIME and a string is a string.
+func inferDateArith(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	date, unit := 0, 2
+	if name == ast.TimestampAdd {
+		date, unit = 2, 0
+	}
+	if len(args) != 3 {
+		return nil, CoercibilityNumeric, nil
+	}
+	ft := args[date].GetType()
+	u := ast.TimeUnitInvalid
+	if x, ok := args[unit].(*ast.TimeUnitExpr); ok {
+		u = x.Unit
+	}
+	fsp := decimalOf(ft)
+	switch u {
+	case ast.TimeUnitMicrosecond, ast.TimeUnitSecondMicrosecond, ast.TimeUnitMinuteMicrosecond,
+		ast.TimeUnitHourMicrosecond, ast.TimeUnitDayMicrosecond:
+		fsp = 6
+	}
+	switch {
+	case ft.GetType() == mysql.TypeDate:
+		switch u {
+		case ast.TimeUnitDay, ast.TimeUnitWeek, ast.TimeUnitMonth, ast.TimeUnitQuarter, ast.TimeUnitYear, ast.TimeUnitYearMonth:
+			return newDateType(), CoercibilityNumeric, nil
+		}
+		return newTemporalType(mysql.TypeDatetime, fsp), CoercibilityNumeric, nil
+	case isTemporal(ft.EvalType()):
+		return newTemporalType(mysql.TypeDatetime, fsp), CoercibilityNumeric, nil
+	}
+	return newStringType(mysql.MaxDatetimeWidthNoFsp+7, i.Charset, i.Collation), CoercibilityCoercible, nil
+}
+
+// inferAddTime infers ADDTIME and SUBTIME, whose result has the type of the
+// first argument.
+func inferAddTime(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if len(args) != 2 {
+		return nil, CoercibilityNumeric, nil
+	}
+	fsp := fspOfArgs(args)
+	switch args[0].GetType().GetType() {
+	case mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDate:
+		return newTemporalType(mysql.TypeDatetime, fsp), CoercibilityNumeric, nil
+	case mysql.TypeDuration:
+		return newTemporalType(mysql.TypeDuration, fsp), CoercibilityNumeric, nil
+	}
+	return newStringType(mysql.MaxDatetimeWidthNoFsp+7, i.Charset, i.Collation), CoercibilityCoercible, nil
+}
+
+// inferStrToDate infers STR_TO_DATE by the specifiers of the constant format.
+func inferStrToDate(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	format, ok := constString(args, 1)
+	if !ok {
+		return newTemporalType(mysql.TypeDatetime, 6), CoercibilityNumeric, nil
+	}
+	hasDate, hasTime, fsp := false, false, 0
+	for k := 0; k+1 < len(format); k++ {
+		if format[k] != '%' {
+			continue
+		}
+		k++
+		switch format[k] {
+		case 'Y', 'y', 'm', 'c', 'd', 'e', 'D', 'M', 'b', 'j', 'a', 'W', 'U', 'u', 'V', 'v', 'X', 'x', 'w':
+			hasDate = true
+		case 'f':
+			hasTime, fsp = true, 6
+		case 'H', 'h', 'I', 'i', 'S', 's', 'p', 'r', 'T', 'k', 'l':
+			hasTime = true
+		}
+	}
+	switch {
+	case hasDate && !hasTime:
+		return newDateType(), CoercibilityNumeric, nil
+	case hasTime && !hasDate:
+		return newTemporalType(mysql.TypeDuration, fsp), CoercibilityNumeric, nil
+	}
+	return newTemporalType(mysql.TypeDatetime, fsp), CoercibilityNumeric, nil
+}
+
+func inferFromUnixTime(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if len(args) > 1 {
+		return newStringType(64, i.Charset, i.Collation), CoercibilityCoercible, nil
+	}
+	return newTemporalType(mysql.TypeDatetime, fspOfArg(0)(args)), CoercibilityNumeric, nil
+}
+
+func inferUnixTimestamp(i *Inferrer, name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if fsp := fspOfArg(0)(args); fsp > 0 {
+		return newDecimalType(12+fsp, fsp), CoercibilityNumeric, nil
+	}
+	return newIntType(false), CoercibilityNumeric, nil
+}
+
+// inferAggregate infers the type of an aggregate function.
+func (i *Inferrer) inferAggregate(name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	name = strings.ToLower(name)
+	switch name {
+	case ast.AggFuncCount, ast.AggFuncApproxCountDistinct:
+		ft := newBinaryType(mysql.TypeLonglong, 21, 0)
+		ft.AddFlag(mysql.NotNullFlag)
+		return ft, CoercibilityNumeric, nil
+	case ast.AggFuncBitAnd, ast.AggFuncBitOr, ast.AggFuncBitXor:
+		return newIntType(true), CoercibilityNumeric, nil
+	case ast.AggFuncVarPop, ast.AggFuncVarSamp, ast.AggFuncStddevPop, ast.AggFuncStddevSamp:
+		return newDoubleType(), CoercibilityNumeric, nil
+	case ast.AggFuncJsonArrayagg, ast.AggFuncJsonObjectAgg:
+		return newJSONType(), CoercibilityImplicit, nil
+	case ast.AggFuncGroupConcat:
+		return i.stringResult(name, 1024, args)
+	case ast.AggFuncMax, ast.AggFuncMin, ast.AggFuncFirstRow, ast.AggFuncApproxPercentile, ast.AnyValue:
+		return argTypeFunc(0)(i, name, args)
+	case ast.AggFuncSum, ast.AggFuncAvg:
+		if len(args) == 0 {
+			return nil, CoercibilityNumeric, nil
+		}
+		ft := args[0].GetType()
+		switch arithmeticKind(ft) {
+		case types.ETInt, types.ETDecimal:
+			if name == ast.AggFuncSum {
+				// The sum of integers is computed in a DECIMAL.
+				return newDecimalType(flenOf(ft)+22, decimalOf(ft)), CoercibilityNumeric, nil
+			}
+			return newDecimalType(flenOf(ft)+divPrecisionIncrement, decimalOf(ft)+divPrecisionIncrement), CoercibilityNumeric, nil
+		}
+		return newDoubleType(), CoercibilityNumeric, nil
+	}
+	return nil, CoercibilityImplicit, nil
+}
+
+// inferWindow infers the type of a window function.
+func (i *Inferrer) inferWindow(name string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	name = strings.ToLower(name)
+	switch name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank, ast.WindowFuncNtile:
+		return newIntType(false), CoercibilityNumeric, nil
+	case ast.WindowFuncCumeDist, ast.WindowFuncPercentRank:
+		return newDoubleType(), CoercibilityNumeric, nil
+	case ast.WindowFuncFirstValue, ast.WindowFuncLastValue, ast.WindowFuncNthValue:
+		return argTypeFunc(0)(i, name, args)
+	case ast.WindowFuncLead, ast.WindowFuncLag:
+		// The default value is the third argument.
+		results := args
+		if len(args) > 1 {
+			results = []ast.ExprNode{args[0]}
+			if len(args) > 2 {
+				results = append(results, args[2])
+			}
+		}
+		return i.mergeResults(name, results)
+	}
+	return i.inferAggregate(name, args)
+}
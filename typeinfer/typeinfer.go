@@ -0,0 +1,479 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package typeinfer infers the types of the expressions of a statement.
+//
+// The types of the columns come from the ResultFields the resolver binds the
+// ColumnNameExprs to, the types of the literals are set by the driver, the
+// inferrer computes the types of the other expressions bottom-up and sets
+// them by ExprNode.SetType. The charset and the collation of a string
+// expression are aggregated from its arguments by the collation coercibility
+// rules of MySQL, an "Illegal mix of collations" error is returned if they
+// are incompatible.
+package typeinfer
+
+import (
+	"strings"
+
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/opcode"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// divPrecisionIncrement is the default value of div_precision_increment.
+const divPrecisionIncrement = 4
+
+// Inferrer infers the types of the expressions, it implements ast.Visitor.
+type Inferrer struct {
+	// Charset and Collation are the connection charset and collation, the
+	// numbers converted to strings are in them.
+	Charset   string
+	Collation string
+
+	coercibility map[ast.ExprNode]Coercibility
+	visiting     map[ast.ExprNode]bool
+	err          error
+}
+
+// NewInferrer creates an Inferrer with the default connection charset and
+// collation.
+func NewInferrer() *Inferrer {
+	return &Inferrer{
+		Charset:      mysql.DefaultCharset,
+		Collation:    mysql.DefaultCollationName,
+		coercibility: make(map[ast.ExprNode]Coercibility),
+		visiting:     make(map[ast.ExprNode]bool),
+	}
+}
+
+// Infer infers the types of the expressions of the node, which is expected
+// to be resolved by the resolver package.
+func Infer(node ast.Node) error {
+	return NewInferrer().Infer(node)
+}
+
+// Infer infers the types of the expressions of the node.
+func (i *Inferrer) Infer(node ast.Node) error {
+	node.Accept(i)
+	return i.err
+}
+
+// Coercibility returns the collation coercibility of an inferred expression.
+func (i *Inferrer) Coercibility(expr ast.ExprNode) Coercibility {
+	if c, ok := i.coercibility[expr]; ok {
+		return c
+	}
+	return coercibilityOf(expr.GetType())
+}
+
+// coercibilityOf returns the coercibility of a value of the type which is
+// not a string with a known coercibility.
+func coercibilityOf(ft *types.FieldType) Coercibility {
+	switch {
+	case isNull(ft):
+		return CoercibilityIgnorable
+	case isStringType(ft) || ft.GetType() == mysql.TypeJSON:
+		return CoercibilityImplicit
+	}
+	return CoercibilityNumeric
+}
+
+// Enter implements ast.Visitor interface.
+func (i *Inferrer) Enter(n ast.Node) (ast.Node, bool) {
+	if i.err != nil {
+		return n, true
+	}
+	if e, ok := n.(ast.ExprNode); ok {
+		if _, done := i.coercibility[e]; done {
+			return n, true
+		}
+		i.visiting[e] = true
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (i *Inferrer) Leave(n ast.Node) (ast.Node, bool) {
+	if i.err != nil {
+		return n, false
+	}
+	e, ok := n.(ast.ExprNode)
+	if !ok {
+		return n, true
+	}
+	if _, done := i.coercibility[e]; done {
+		return n, true
+	}
+	delete(i.visiting, e)
+	ft, c, err := i.inferExpr(e)
+	if err != nil {
+		i.err = err
+		return n, false
+	}
+	if ft != nil {
+		e.SetType(ft)
+	}
+	i.coercibility[e] = c
+	return n, true
+}
+
+// inferRefer infers the type of an expression a column refers to, which may
+// be out of the node being inferred like the field of a derived table.
+func (i *Inferrer) inferRefer(e ast.ExprNode) {
+	if _, done := i.coercibility[e]; done || i.visiting[e] {
+		return
+	}
+	e.Accept(i)
+}
+
+// referType returns the type of the column a ResultField refers to.
+func (i *Inferrer) referType(rf *ast.ResultField) (*types.FieldType, Coercibility) {
+	if rf == nil {
+		return types.NewFieldType(mysql.TypeUnspecified), CoercibilityImplicit
+	}
+	if rf.Table == nil && rf.Expr != nil {
+		i.inferRefer(rf.Expr)
+		if i.err == nil {
+			return rf.Expr.GetType().Clone(), i.Coercibility(rf.Expr)
+		}
+	}
+	if rf.Column == nil {
+		return types.NewFieldType(mysql.TypeUnspecified), CoercibilityImplicit
+	}
+	ft := rf.Column.FieldType.Clone()
+	return ft, coercibilityOf(ft)
+}
+
+// inferExpr returns the type and the coercibility of the expression whose
+// arguments are inferred, a nil type keeps the type of the expression.
+func (i *Inferrer) inferExpr(e ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	switch x := e.(type) {
+	case ast.ParamMarkerExpr:
+		return nil, CoercibilityCoercible, nil
+	case ast.ValueExpr:
+		ft := x.GetType()
+		if isStringType(ft) {
+			return nil, CoercibilityCoercible, nil
+		}
+		return nil, coercibilityOf(ft), nil
+	case *ast.ColumnNameExpr:
+		ft, c := i.referType(x.Refer)
+		return ft, c, nil
+	case *ast.PositionExpr:
+		ft, c := i.referType(x.Refer)
+		return ft, c, nil
+	case *ast.ValuesExpr:
+		if x.Column == nil {
+			return nil, coercibilityOf(x.GetType()), nil
+		}
+		return x.Column.GetType().Clone(), i.Coercibility(x.Column), nil
+	case *ast.ParenthesesExpr:
+		return x.Expr.GetType().Clone(), i.Coercibility(x.Expr), nil
+	case *ast.VariableExpr:
+		if x.Value != nil {
+			return x.Value.GetType().Clone(), CoercibilityImplicit, nil
+		}
+		return nil, coercibilityOf(x.GetType()), nil
+	case *ast.SetCollationExpr:
+		return i.inferSetCollation(x)
+	case *ast.BinaryOperationExpr:
+		return i.inferBinaryOperation(x)
+	case *ast.UnaryOperationExpr:
+		return i.inferUnaryOperation(x)
+	case *ast.BetweenExpr:
+		return i.inferPredicate("between", x.Expr, x.Left, x.Right)
+	case *ast.PatternInExpr:
+		if x.Sel != nil {
+			return newBoolType(), CoercibilityNumeric, nil
+		}
+		return i.inferPredicate("in", append([]ast.ExprNode{x.Expr}, x.List...)...)
+	case *ast.PatternLikeExpr:
+		return i.inferPredicate("like", x.Expr, x.Pattern)
+	case *ast.PatternRegexpExpr:
+		return i.inferPredicate("regexp", x.Expr, x.Pattern)
+	case *ast.IsNullExpr, *ast.IsTruthExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr:
+		return newBoolType(), CoercibilityNumeric, nil
+	case *ast.CaseExpr:
+		return i.inferCase(x)
+	case *ast.SubqueryExpr:
+		if f := firstField(x.Query); f != nil {
+			return f.GetType().Clone(), i.Coercibility(f), nil
+		}
+		return nil, coercibilityOf(x.GetType()), nil
+	case *ast.FuncCastExpr:
+		return i.inferCast(x)
+	case *ast.FuncCallExpr:
+		return i.inferFunc(x)
+	case *ast.AggregateFuncExpr:
+		return i.inferAggregate(x.F, x.Args)
+	case *ast.WindowFuncExpr:
+		return i.inferWindow(x.F, x.Args)
+	}
+	return nil, coercibilityOf(e.GetType()), nil
+}
+
+// firstField returns the expression of the first field of a query.
+func firstField(n ast.Node) ast.ExprNode {
+	switch x := n.(type) {
+	case *ast.SelectStmt:
+		if x.Fields != nil && len(x.Fields.Fields) > 0 && x.Fields.Fields[0].WildCard == nil {
+			return x.Fields.Fields[0].Expr
+		}
+	case *ast.SetOprStmt:
+		return firstField(x.SelectList)
+	case *ast.SetOprSelectList:
+		if x != nil && len(x.Selects) > 0 {
+			return firstField(x.Selects[0])
+		}
+	}
+	return nil
+}
+
+// collationOf returns the collation of an expression as an argument of a
+// string operation, a number is converted to a string of the connection
+// collation.
+func (i *Inferrer) collationOf(e ast.ExprNode) collation {
+	ft := e.GetType()
+	switch {
+	case isNull(ft) || ft.GetType() == mysql.TypeUnspecified:
+		return collation{coercibility: CoercibilityIgnorable}
+	case isStringType(ft) || ft.GetType() == mysql.TypeJSON:
+		return collation{charset: ft.GetCharset(), collation: ft.GetCollate(), coercibility: i.Coercibility(e)}
+	}
+	return collation{charset: i.Charset, collation: i.Collation, coercibility: CoercibilityNumeric}
+}
+
+// aggregateArgs aggregates the collations of the arguments of op, the result
+// is the connection collation if none of the arguments is a string.
+func (i *Inferrer) aggregateArgs(op string, args []ast.ExprNode) (collation, error) {
+	colls := make([]collation, 0, len(args))
+	for _, arg := range args {
+		colls = append(colls, i.collationOf(arg))
+	}
+	res, err := aggregateCollations(op, colls)
+	if err != nil {
+		return collation{}, err
+	}
+	if res.coercibility >= CoercibilityNumeric {
+		res = collation{charset: i.Charset, collation: i.Collation, coercibility: CoercibilityCoercible}
+	}
+	return res, nil
+}
+
+// stringResult returns a string of flen characters in the collation of the
+// arguments of op.
+func (i *Inferrer) stringResult(op string, flen int, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	coll, err := i.aggregateArgs(op, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	return newStringType(flen, coll.charset, coll.collation), coll.coercibility, nil
+}
+
+// mergeResults returns the type aggregated from the arguments of op, which
+// are the possible results like the branches of CASE.
+func (i *Inferrer) mergeResults(op string, args []ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	fts := make([]*types.FieldType, 0, len(args))
+	for _, arg := range args {
+		fts = append(fts, arg.GetType())
+	}
+	ft := AggregateTypes(fts...)
+	if !isStringType(ft) {
+		return ft, coercibilityOf(ft), nil
+	}
+	coll, err := i.aggregateArgs(op, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	setCollation(ft, coll.charset, coll.collation)
+	return ft, coll.coercibility, nil
+}
+
+// checkComparison checks the collations of the arguments of a comparison,
+// they are compared by a collation only if they are all strings.
+func (i *Inferrer) checkComparison(op string, args ...ast.ExprNode) error {
+	for _, arg := range args {
+		if ft := arg.GetType(); !isNull(ft) && !isStringType(ft) {
+			return nil
+		}
+	}
+	_, err := i.aggregateArgs(op, args)
+	return err
+}
+
+func (i *Inferrer) inferPredicate(op string, args ...ast.ExprNode) (*types.FieldType, Coercibility, error) {
+	if err := i.checkComparison(op, args...); err != nil {
+		return nil, 0, err
+	}
+	return newBoolType(), CoercibilityNumeric, nil
+}
+
+func (i *Inferrer) inferSetCollation(x *ast.SetCollationExpr) (*types.FieldType, Coercibility, error) {
+	coll, err := charset.GetCollationByName(x.Collate)
+	if err != nil {
+		return nil, 0, err
+	}
+	ft := x.Expr.GetType().Clone()
+	if !isStringType(ft) {
+		ft = newStringType(displayWidth(ft), "", "")
+	}
+	setCollation(ft, coll.CharsetName, coll.Name)
+	return ft, CoercibilityExplicit, nil
+}
+
+// opName returns the name of an operator in the error messages.
+func opName(op opcode.Op) string {
+	var sb strings.Builder
+	op.Format(&sb)
+	return strings.ToLower(strings.TrimSpace(sb.String()))
+}
+
+func (i *Inferrer) inferBinaryOperation(x *ast.BinaryOperationExpr) (*types.FieldType, Coercibility, error) {
+	switch x.Op {
+	case opcode.LogicAnd, opcode.LogicOr, opcode.LogicXor:
+		return newBoolType(), CoercibilityNumeric, nil
+	case opcode.EQ, opcode.NE, opcode.LT, opcode.LE, opcode.GT, opcode.GE, opcode.NullEQ:
+		return i.inferPredicate(opName(x.Op), x.L, x.R)
+	case opcode.And, opcode.Or, opcode.Xor, opcode.LeftShift, opcode.RightShift:
+		return newIntType(true), CoercibilityNumeric, nil
+	}
+	return arithmeticType(x.Op, x.L.GetType(), x.R.GetType()), CoercibilityNumeric, nil
+}
+
+// arithmeticKind returns the kind a value of the type is computed as in an
+// arithmetic operation, a temporal value is a number like 20220102030405.
+func arithmeticKind(ft *types.FieldType) types.EvalType {
+	switch et := ft.EvalType(); {
+	case isNull(ft) || et == types.ETInt:
+		return types.ETInt
+	case et == types.ETDecimal:
+		return types.ETDecimal
+	case isTemporal(et):
+		if decimalOf(ft) > 0 {
+			return types.ETDecimal
+		}
+		return types.ETInt
+	}
+	return types.ETReal
+}
+
+// arithmeticType returns the result type of an arithmetic operator.
+func arithmeticType(op opcode.Op, l, r *types.FieldType) *types.FieldType {
+	lk, rk := arithmeticKind(l), arithmeticKind(r)
+	unsigned := isUnsigned(l) || isUnsigned(r)
+	switch {
+	case op == opcode.IntDiv:
+		return newIntType(unsigned)
+	case lk == types.ETReal || rk == types.ETReal:
+		return newDoubleType()
+	case op == opcode.Div:
+		dec := decimalOf(l) + divPrecisionIncrement
+		return newDecimalType(flenOf(l)-decimalOf(l)+decimalOf(r)+dec, dec)
+	case lk == types.ETDecimal || rk == types.ETDecimal:
+		ld, rd := decimalOf(l), decimalOf(r)
+		li, ri := flenOf(l)-ld, flenOf(r)-rd
+		var ft *types.FieldType
+		switch op {
+		case opcode.Mul:
+			ft = newDecimalType(li+ri+ld+rd, ld+rd)
+		case opcode.Mod:
+			ft = newDecimalType(max(li, ri)+max(ld, rd), max(ld, rd))
+		default:
+			ft = newDecimalType(max(li, ri)+1+max(ld, rd), max(ld, rd))
+		}
+		if op == opcode.Mod && isUnsigned(l) || op != opcode.Mod && isUnsigned(l) && isUnsigned(r) {
+			ft.AddFlag(mysql.UnsignedFlag)
+		}
+		return ft
+	case op == opcode.Mod:
+		return newIntType(isUnsigned(l))
+	}
+	return newIntType(unsigned)
+}
+
+func (i *Inferrer) inferUnaryOperation(x *ast.UnaryOperationExpr) (*types.FieldType, Coercibility, error) {
+	ft := x.V.GetType()
+	switch x.Op {
+	case opcode.Not, opcode.Not2:
+		return newBoolType(), CoercibilityNumeric, nil
+	case opcode.BitNeg:
+		return newIntType(true), CoercibilityNumeric, nil
+	case opcode.Plus:
+		return ft.Clone(), i.Coercibility(x.V), nil
+	}
+	switch arithmeticKind(ft) {
+	case types.ETInt:
+		if isUnsigned(ft) {
+			return newDecimalType(flenOf(ft), 0), CoercibilityNumeric, nil
+		}
+		return newIntType(false), CoercibilityNumeric, nil
+	case types.ETDecimal:
+		return newDecimalType(flenOf(ft), decimalOf(ft)), CoercibilityNumeric, nil
+	}
+	return newDoubleType(), CoercibilityNumeric, nil
+}
+
+func (i *Inferrer) inferCase(x *ast.CaseExpr) (*types.FieldType, Coercibility, error) {
+	results := make([]ast.ExprNode, 0, len(x.WhenClauses)+1)
+	conds := make([]ast.ExprNode, 0, len(x.WhenClauses)+1)
+	if x.Value != nil {
+		conds = append(conds, x.Value)
+	}
+	for _, w := range x.WhenClauses {
+		conds = append(conds, w.Expr)
+		results = append(results, w.Result)
+	}
+	if x.ElseClause != nil {
+		results = append(results, x.ElseClause)
+	}
+	if x.Value != nil {
+		if err := i.checkComparison("case", conds...); err != nil {
+			return nil, 0, err
+		}
+	}
+	return i.mergeResults("case", results)
+}
+
+func (i *Inferrer) inferCast(x *ast.FuncCastExpr) (*types.FieldType, Coercibility, error) {
+	ft := x.Tp.Clone()
+	arg := x.Expr.GetType()
+	switch ft.EvalType() {
+	case types.ETString:
+		if ft.GetFlen() == types.UnspecifiedLength {
+			ft.SetFlen(displayWidth(arg))
+		}
+		if ft.GetCharset() == "" {
+			setCollation(ft, i.Charset, i.Collation)
+		}
+		return ft, CoercibilityImplicit, nil
+	case types.ETJson:
+		return ft, CoercibilityImplicit, nil
+	case types.ETDecimal:
+		flen, dec := mysql.GetDefaultFieldLengthAndDecimalForCast(ft.GetType())
+		if ft.GetFlen() == types.UnspecifiedLength {
+			ft.SetFlen(flen)
+		}
+		if ft.GetDecimal() == types.UnspecifiedLength {
+			ft.SetDecimal(dec)
+		}
+	case types.ETDatetime, types.ETTimestamp, types.ETDuration:
+		if ft.GetType() != mysql.TypeDate {
+			ft = newTemporalType(ft.GetType(), decimalOf(ft))
+		} else {
+			ft = newDateType()
+		}
+	}
+	return ft, CoercibilityNumeric, nil
+}
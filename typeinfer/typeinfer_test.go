@@ -0,0 +1,271 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeinfer_test
+
+import (
+	"testing"
+
+	"github.com/daiguadaidai/parser"
+	"github.com/daiguadaidai/parser/ast"
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/model"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/resolver"
+	_ "github.com/daiguadaidai/parser/test_driver"
+	. "github.com/daiguadaidai/parser/typeinfer"
+	"github.com/daiguadaidai/parser/types"
+	"github.com/stretchr/testify/require"
+)
+
+type column struct {
+	name          string
+	tp            byte
+	flen, decimal int
+	flag          uint
+	collation     string
+}
+
+func newInfoSchema() resolver.InfoSchema {
+	cols := []column{
+		{"i", mysql.TypeLong, 11, 0, 0, ""},
+		{"u", mysql.TypeLong, 10, 0, mysql.UnsignedFlag, ""},
+		{"ti", mysql.TypeTiny, 4, 0, 0, ""},
+		{"bi", mysql.TypeLonglong, 20, 0, 0, ""},
+		{"d", mysql.TypeNewDecimal, 10, 2, 0, ""},
+		{"f", mysql.TypeDouble, 22, -1, 0, ""},
+		{"s", mysql.TypeVarchar, 20, 0, 0, "utf8mb4_bin"},
+		{"ci", mysql.TypeVarchar, 10, 0, 0, "utf8mb4_general_ci"},
+		{"l", mysql.TypeVarchar, 10, 0, 0, "latin1_swedish_ci"},
+		{"b", mysql.TypeVarchar, 8, 0, mysql.BinaryFlag, "binary"},
+		{"txt", mysql.TypeBlob, 65535, 0, 0, "utf8mb4_bin"},
+		{"dt", mysql.TypeDatetime, 19, 0, 0, ""},
+		{"dt3", mysql.TypeDatetime, 23, 3, 0, ""},
+		{"da", mysql.TypeDate, 10, 0, 0, ""},
+		{"tm", mysql.TypeDuration, 10, 0, 0, ""},
+		{"j", mysql.TypeJSON, 0, 0, 0, ""},
+	}
+	tbl := &model.TableInfo{Name: model.NewCIStr("t"), State: model.StatePublic}
+	for i, c := range cols {
+		ft := types.NewFieldType(c.tp)
+		ft.SetFlen(c.flen)
+		ft.SetDecimal(c.decimal)
+		ft.SetFlag(c.flag)
+		if c.collation != "" {
+			coll, err := charset.GetCollationByName(c.collation)
+			if err != nil {
+				panic(err)
+			}
+			ft.SetCharset(coll.CharsetName)
+			ft.SetCollate(coll.Name)
+		} else {
+			ft.SetCharset("binary")
+			ft.SetCollate("binary")
+			ft.AddFlag(mysql.BinaryFlag)
+		}
+		tbl.Columns = append(tbl.Columns, &model.ColumnInfo{
+			ID:        int64(i + 1),
+			Name:      model.NewCIStr(c.name),
+			Offset:    i,
+			FieldType: *ft,
+			State:     model.StatePublic,
+		})
+	}
+	return resolver.NewInfoSchema(&model.DBInfo{Name: model.NewCIStr("test"), Tables: []*model.TableInfo{tbl}})
+}
+
+func infer(t *testing.T, sql string) (ast.StmtNode, *Inferrer, error) {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err, sql)
+	require.NoError(t, resolver.ResolveName(stmt, newInfoSchema(), "test"), sql)
+	i := NewInferrer()
+	return stmt, i, i.Infer(stmt)
+}
+
+func typeOf(t *testing.T, sql string) string {
+	stmt, i, err := infer(t, sql)
+	require.NoError(t, err, sql)
+	var fields []*ast.SelectField
+	switch x := stmt.(type) {
+	case *ast.SelectStmt:
+		fields = x.Fields.Fields
+	case *ast.SetOprStmt:
+		fields = x.SelectList.Selects[0].(*ast.SelectStmt).Fields.Fields
+	}
+	expr := fields[len(fields)-1].Expr
+	ft := expr.GetType()
+	return ft.String() + "|" + ft.GetCollate() + "|" + i.Coercibility(expr).String()
+}
+
+func TestInferExpressions(t *testing.T) {
+	for _, c := range []struct {
+		expr string
+		tp   string
+	}{
+		// columns and literals
+		{"i", "int(11) BINARY|binary|NUMERIC"},
+		{"s", "varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin|utf8mb4_bin|IMPLICIT"},
+		{"'abc'", "var_string(3)|utf8mb4_bin|COERCIBLE"},
+		{"null", "null BINARY|binary|IGNORABLE"},
+		// operators
+		{"i + 1", "bigint(20) BINARY|binary|NUMERIC"},
+		{"u + i", "bigint(20) UNSIGNED BINARY|binary|NUMERIC"},
+		{"d + 1", "decimal(11,2) BINARY|binary|NUMERIC"},
+		{"d * d", "decimal(20,4) BINARY|binary|NUMERIC"},
+		{"i / 2", "decimal(15,4) BINARY|binary|NUMERIC"},
+		{"i div 2", "bigint(20) BINARY|binary|NUMERIC"},
+		{"s + 1", "double BINARY|binary|NUMERIC"},
+		{"dt + 0", "bigint(20) BINARY|binary|NUMERIC"},
+		{"dt3 + 0", "decimal(24,3) BINARY|binary|NUMERIC"},
+		{"-u", "decimal(10,0) BINARY|binary|NUMERIC"},
+		{"i & 1", "bigint(20) UNSIGNED BINARY|binary|NUMERIC"},
+		{"i = 1", "bigint(1) BINARY|binary|NUMERIC"},
+		{"s like 'a%'", "bigint(1) BINARY|binary|NUMERIC"},
+		{"not i", "bigint(1) BINARY|binary|NUMERIC"},
+		// control flow
+		{"case when i > 0 then i else d end", "decimal(13,2) BINARY|binary|NUMERIC"},
+		{"case i when 1 then 'a' else s end", "var_string(20)|utf8mb4_bin|IMPLICIT"},
+		{"if(i, 1, 'a')", "var_string(1)|utf8mb4_bin|COERCIBLE"},
+		{"ifnull(ti, i)", "int(11) BINARY|binary|NUMERIC"},
+		{"coalesce(null, ti, u)", "bigint(11) BINARY|binary|NUMERIC"},
+		{"coalesce(null, dt, da)", "datetime BINARY|binary|NUMERIC"},
+		{"coalesce(s, txt)", "text CHARACTER SET utf8mb4 COLLATE utf8mb4_bin|utf8mb4_bin|IMPLICIT"},
+		// casts
+		{"cast(i as char)", "var_string(11)|utf8mb4_bin|IMPLICIT"},
+		{"cast(d as decimal(5,1))", "decimal(5,1) BINARY|binary|NUMERIC"},
+		{"cast(dt as datetime(3))", "datetime(3) BINARY|binary|NUMERIC"},
+		{"convert(s using latin1)", "var_string(20)|latin1_bin|IMPLICIT"},
+		{"s collate utf8mb4_general_ci", "varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci|utf8mb4_general_ci|EXPLICIT"},
+		// functions
+		{"concat(s, i)", "var_string(31)|utf8mb4_bin|IMPLICIT"},
+		{"concat(s, b)", "var_string(28) BINARY|binary|IMPLICIT"},
+		{"concat(ci, 'a')", "var_string(11)|utf8mb4_general_ci|IMPLICIT"},
+		{"left(s, 3)", "var_string(3)|utf8mb4_bin|IMPLICIT"},
+		{"hex(i)", "var_string(22)|utf8mb4_bin|COERCIBLE"},
+		{"now(3)", "datetime(3) BINARY|binary|NUMERIC"},
+		{"da + interval 1 day", "date BINARY|binary|NUMERIC"},
+		{"date_add(da, interval 1 hour)", "datetime BINARY|binary|NUMERIC"},
+		{"time '10:00:00.12'", "time(2) BINARY|binary|NUMERIC"},
+		{"str_to_date(s, '%Y-%m-%d %H:%i:%s.%f')", "datetime(6) BINARY|binary|NUMERIC"},
+		{"round(d, 1)", "decimal(10,1) BINARY|binary|NUMERIC"},
+		{"floor(f)", "double BINARY|binary|NUMERIC"},
+		{"j->'$.a'", "json|utf8mb4_bin|IMPLICIT"},
+		{"j->>'$.a'", "longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin|utf8mb4_bin|IMPLICIT"},
+		{"user()", "var_string(64)|utf8mb4_bin|SYSCONST"},
+		{"(select s from t limit 1)", "varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin|utf8mb4_bin|IMPLICIT"},
+		// aggregate and window functions
+		{"count(*)", "bigint(21) BINARY|binary|NUMERIC"},
+		{"sum(i)", "decimal(33,0) BINARY|binary|NUMERIC"},
+		{"avg(d)", "decimal(14,6) BINARY|binary|NUMERIC"},
+		{"sum(f)", "double BINARY|binary|NUMERIC"},
+		{"max(s)", "varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin|utf8mb4_bin|IMPLICIT"},
+		{"bit_or(i)", "bigint(20) UNSIGNED BINARY|binary|NUMERIC"},
+		{"std(i)", "double BINARY|binary|NUMERIC"},
+		{"row_number() over ()", "bigint(20) BINARY|binary|NUMERIC"},
+		{"lag(s, 1, 'x') over ()", "var_string(20)|utf8mb4_bin|IMPLICIT"},
+	} {
+		require.Equal(t, c.tp, typeOf(t, "select "+c.expr+" from t"), c.expr)
+	}
+}
+
+func TestInferReferences(t *testing.T) {
+	for _, c := range []struct {
+		sql string
+		tp  string
+	}{
+		{"select x from (select i + 1 as x from t) d", "bigint(20) BINARY|binary|NUMERIC"},
+		{"with c as (select concat(s, 'a') as x from t) select x from c", "var_string(21)|utf8mb4_bin|IMPLICIT"},
+		{"select x + 1 from (select i / 2 as x from t) d", "decimal(16,4) BINARY|binary|NUMERIC"},
+		{"select x from (select d as x from t union select i from t) u", "decimal(10,2) BINARY|binary|NUMERIC"},
+		{"select unknown_func(i) from t", "unspecified||NUMERIC"},
+	} {
+		require.Equal(t, c.tp, typeOf(t, c.sql), c.sql)
+	}
+}
+
+func TestCollationCoercibility(t *testing.T) {
+	for _, c := range []struct {
+		expr string
+		err  string
+	}{
+		{"s = ci", ""},
+		{"ci = l", ""},
+		{"b = s", ""},
+		{"ci = 'a' collate utf8mb4_unicode_ci", ""},
+		{"i = ci", ""},
+		{"ci collate utf8mb4_bin = s collate utf8mb4_general_ci", "[expression:1267]Illegal mix of collations (utf8mb4_bin,EXPLICIT) and (utf8mb4_general_ci,EXPLICIT) for operation '='"},
+		{"ci collate utf8mb4_bin like s collate utf8mb4_general_ci", "[expression:1267]Illegal mix of collations (utf8mb4_bin,EXPLICIT) and (utf8mb4_general_ci,EXPLICIT) for operation 'like'"},
+		{"concat(ci collate utf8mb4_bin, 'a' collate utf8mb4_general_ci, s)", "[expression:1270]Illegal mix of collations (utf8mb4_bin,EXPLICIT), (utf8mb4_general_ci,EXPLICIT), (utf8mb4_bin,IMPLICIT) for operation 'concat'"},
+		{"case when i then s collate utf8mb4_bin when 1 then ci collate utf8mb4_general_ci else l end", "[expression:1270]Illegal mix of collations (utf8mb4_bin,EXPLICIT), (utf8mb4_general_ci,EXPLICIT), (latin1_swedish_ci,IMPLICIT) for operation 'case'"},
+		{"ci in (s, l, 'a' collate utf8mb4_bin, 'b' collate utf8mb4_general_ci)", "[expression:1271]Illegal mix of collations for operation 'in'"},
+	} {
+		_, _, err := infer(t, "select "+c.expr+" from t")
+		if c.err == "" {
+			require.NoError(t, err, c.expr)
+		} else {
+			require.EqualError(t, err, c.err, c.expr)
+		}
+	}
+	require.Equal(t, "var_string(30)|utf8mb4_bin|IMPLICIT", typeOf(t, "select concat(ci, s) from t"))
+	require.Equal(t, "var_string(20)|utf8mb4_general_ci|IMPLICIT", typeOf(t, "select concat(ci, l) from t"))
+	require.Equal(t, "var_string(30)|utf8mb4_unicode_ci|EXPLICIT", typeOf(t, "select concat(ci, s collate utf8mb4_unicode_ci) from t"))
+}
+
+func newType(tp byte, flen, decimal int, flag uint) *types.FieldType {
+	ft := types.NewFieldType(tp)
+	ft.SetFlen(flen)
+	ft.SetDecimal(decimal)
+	ft.SetFlag(flag)
+	return ft
+}
+
+func TestAggregateTypes(t *testing.T) {
+	tiny := newType(mysql.TypeTiny, 4, 0, 0)
+	u32 := newType(mysql.TypeLong, 10, 0, mysql.UnsignedFlag)
+	ubig := newType(mysql.TypeLonglong, 20, 0, mysql.UnsignedFlag)
+	dec := newType(mysql.TypeNewDecimal, 10, 2, 0)
+	dbl := newType(mysql.TypeDouble, 22, -1, 0)
+	date := newType(mysql.TypeDate, 10, 0, 0)
+	dt := newType(mysql.TypeDatetime, 23, 3, 0)
+	str := newType(mysql.TypeVarchar, 5, 0, 0)
+	null := newType(mysql.TypeNull, 0, 0, 0)
+
+	require.Equal(t, "null BINARY", AggregateTypes(null, null).String())
+	require.Equal(t, "tinyint(4)", AggregateTypes(tiny, null).String())
+	require.Equal(t, "bigint(11) BINARY", AggregateTypes(tiny, u32).String())
+	require.Equal(t, "decimal(21,0) BINARY", AggregateTypes(tiny, ubig).String())
+	require.Equal(t, "decimal(12,2) BINARY", AggregateTypes(tiny, u32, dec).String())
+	require.Equal(t, "double BINARY", AggregateTypes(dec, dbl).String())
+	require.Equal(t, "datetime(3) BINARY", AggregateTypes(date, dt).String())
+	require.Equal(t, "var_string(23)", AggregateTypes(dt, str).String())
+}
+
+func TestCompareType(t *testing.T) {
+	i := newType(mysql.TypeLong, 11, 0, 0)
+	dec := newType(mysql.TypeNewDecimal, 10, 2, 0)
+	str := newType(mysql.TypeVarchar, 5, 0, 0)
+	dt := newType(mysql.TypeDatetime, 19, 0, 0)
+	tm := newType(mysql.TypeDuration, 10, 0, 0)
+	j := newType(mysql.TypeJSON, 0, 0, 0)
+	null := newType(mysql.TypeNull, 0, 0, 0)
+
+	require.Equal(t, types.ETInt, CompareType(i, i))
+	require.Equal(t, types.ETDecimal, CompareType(i, dec))
+	require.Equal(t, types.ETReal, CompareType(i, str))
+	require.Equal(t, types.ETString, CompareType(str, str))
+	require.Equal(t, types.ETDatetime, CompareType(str, dt))
+	require.Equal(t, types.ETDuration, CompareType(tm, str))
+	require.Equal(t, types.ETDatetime, CompareType(tm, dt))
+	require.Equal(t, types.ETJson, CompareType(j, str))
+	require.Equal(t, types.ETString, CompareType(null, str))
+}
@@ -0,0 +1,386 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeinfer
+
+import (
+	"github.com/daiguadaidai/parser/charset"
+	"github.com/daiguadaidai/parser/mysql"
+	"github.com/daiguadaidai/parser/types"
+)
+
+// newBinaryType creates a non-string type with the binary charset.
+func newBinaryType(tp byte, flen, decimal int) *types.FieldType {
+	ft := types.NewFieldType(tp)
+	ft.SetFlen(flen)
+	ft.SetDecimal(decimal)
+	ft.SetCharset(charset.CharsetBin)
+	ft.SetCollate(charset.CollationBin)
+	ft.AddFlag(mysql.BinaryFlag)
+	return ft
+}
+
+func newIntType(unsigned bool) *types.FieldType {
+	ft := newBinaryType(mysql.TypeLonglong, mysql.MaxIntWidth, 0)
+	if unsigned {
+		ft.AddFlag(mysql.UnsignedFlag)
+	}
+	return ft
+}
+
+func newBoolType() *types.FieldType {
+	ft := newBinaryType(mysql.TypeLonglong, 1, 0)
+	ft.AddFlag(mysql.IsBooleanFlag)
+	return ft
+}
+
+func newDoubleType() *types.FieldType {
+	return newBinaryType(mysql.TypeDouble, mysql.MaxRealWidth, types.UnspecifiedLength)
+}
+
+func newDecimalType(flen, decimal int) *types.FieldType {
+	ft := newBinaryType(mysql.TypeNewDecimal, 0, 0)
+	ft.SetDecimalUnderLimit(decimal)
+	ft.SetFlenUnderLimit(flen)
+	return ft
+}
+
+func newDateType() *types.FieldType {
+	return newBinaryType(mysql.TypeDate, mysql.MaxDateWidth, 0)
+}
+
+// newTemporalType creates a DATETIME, TIMESTAMP or TIME type with fsp.
+func newTemporalType(tp byte, fsp int) *types.FieldType {
+	if fsp < 0 {
+		fsp = 0
+	} else if fsp > 6 {
+		fsp = 6
+	}
+	flen := mysql.MaxDatetimeWidthNoFsp
+	if tp == mysql.TypeDuration {
+		flen = mysql.MaxDurationWidthNoFsp
+	}
+	if fsp > 0 {
+		flen += fsp + 1
+	}
+	return newBinaryType(tp, flen, fsp)
+}
+
+func newJSONType() *types.FieldType {
+	ft := types.NewFieldType(mysql.TypeJSON)
+	ft.SetFlen(mysql.MaxBlobWidth)
+	ft.SetDecimal(0)
+	ft.SetCharset(mysql.DefaultCharset)
+	ft.SetCollate(mysql.DefaultCollationName)
+	return ft
+}
+
+func newNullType() *types.FieldType {
+	return newBinaryType(mysql.TypeNull, 0, 0)
+}
+
+// newStringType creates a VARCHAR type, or a TEXT type if flen is too long
+// for a VARCHAR.
+func newStringType(flen int, cs, coll string) *types.FieldType {
+	tp := mysql.TypeVarString
+	switch {
+	case flen > 16777215:
+		tp = mysql.TypeLongBlob
+	case flen > mysql.MaxFieldVarCharLength:
+		tp = mysql.TypeMediumBlob
+	}
+	ft := types.NewFieldType(tp)
+	ft.SetFlen(flen)
+	ft.SetDecimal(types.UnspecifiedLength)
+	setCollation(ft, cs, coll)
+	return ft
+}
+
+// setCollation sets the charset and the collation of a string type.
+func setCollation(ft *types.FieldType, cs, coll string) {
+	ft.SetCharset(cs)
+	ft.SetCollate(coll)
+	if cs == charset.CharsetBin {
+		ft.AddFlag(mysql.BinaryFlag)
+	} else {
+		ft.DelFlag(mysql.BinaryFlag)
+	}
+}
+
+func isNull(ft *types.FieldType) bool {
+	return ft.GetType() == mysql.TypeNull
+}
+
+func isUnsigned(ft *types.FieldType) bool {
+	return mysql.HasUnsignedFlag(ft.GetFlag())
+}
+
+func isTemporal(et types.EvalType) bool {
+	return et == types.ETDatetime || et == types.ETTimestamp || et == types.ETDuration
+}
+
+// isStringType returns whether the values of the type are strings with a
+// collation.
+func isStringType(ft *types.FieldType) bool {
+	return !isNull(ft) && ft.GetType() != mysql.TypeUnspecified && ft.EvalType() == types.ETString
+}
+
+// flenOf returns the flen of the type, or the default one if unspecified.
+func flenOf(ft *types.FieldType) int {
+	if flen := ft.GetFlen(); flen != types.UnspecifiedLength {
+		return flen
+	}
+	flen, _ := mysql.GetDefaultFieldLengthAndDecimal(ft.GetType())
+	if flen < 0 {
+		return 0
+	}
+	return flen
+}
+
+// decimalOf returns the decimal of the type, or 0 if unspecified.
+func decimalOf(ft *types.FieldType) int {
+	if dec := ft.GetDecimal(); dec > 0 && dec != mysql.NotFixedDec {
+		return dec
+	}
+	return 0
+}
+
+// displayWidth returns the number of characters of the values of the type
+// converted to strings.
+func displayWidth(ft *types.FieldType) int {
+	switch ft.GetType() {
+	case mysql.TypeNewDecimal:
+		width := flenOf(ft) + 1
+		if decimalOf(ft) > 0 {
+			width++
+		}
+		return width
+	case mysql.TypeFloat, mysql.TypeDouble:
+		if ft.GetFlen() == types.UnspecifiedLength || ft.GetDecimal() == types.UnspecifiedLength {
+			return mysql.MaxRealWidth
+		}
+	case mysql.TypeDate:
+		return mysql.MaxDateWidth
+	case mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDuration:
+		return newTemporalType(ft.GetType(), decimalOf(ft)).GetFlen()
+	case mysql.TypeEnum, mysql.TypeSet:
+		if ft.GetFlen() != types.UnspecifiedLength {
+			break
+		}
+		width := 0
+		for _, e := range ft.GetElems() {
+			if ft.GetType() == mysql.TypeEnum {
+				width = max(width, len(e))
+			} else {
+				width += len(e) + 1
+			}
+		}
+		return width
+	}
+	return flenOf(ft)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// intRank is the rank of the integer types by their ranges.
+var intRank = map[byte]int{
+	mysql.TypeTiny:     1,
+	mysql.TypeShort:    2,
+	mysql.TypeInt24:    3,
+	mysql.TypeLong:     4,
+	mysql.TypeLonglong: 5,
+}
+
+var rankedInts = [...]byte{0, mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong}
+
+// blobRank is the rank of the blob types by their lengths.
+var blobRank = map[byte]int{
+	mysql.TypeTinyBlob:   1,
+	mysql.TypeBlob:       2,
+	mysql.TypeMediumBlob: 3,
+	mysql.TypeLongBlob:   4,
+}
+
+// AggregateTypes returns the type merged from the types of the results of
+// CASE, IF, IFNULL, COALESCE and the like, following the MySQL type
+// aggregation rules: integers merge into the widest integer, numbers into
+// DECIMAL or DOUBLE, temporal types into DATETIME and anything else into a
+// string long enough for all the values. The NULL types are ignored. The
+// charset of a merged string is the binary one if any string is binary,
+// the caller is expected to aggregate the collations.
+func AggregateTypes(fts ...*types.FieldType) *types.FieldType {
+	args := make([]*types.FieldType, 0, len(fts))
+	for _, ft := range fts {
+		if ft != nil && !isNull(ft) {
+			args = append(args, ft)
+		}
+	}
+	if len(args) == 0 {
+		return newNullType()
+	}
+	allUnsigned, allFloat := true, true
+	allInt, allNumeric, allTemporal, sameType := true, true, true, true
+	maxDecimal, maxIntPart, maxWidth := 0, 0, 0
+	for _, ft := range args {
+		et := ft.EvalType()
+		sameType = sameType && ft.GetType() == args[0].GetType()
+		allUnsigned = allUnsigned && isUnsigned(ft)
+		allFloat = allFloat && ft.GetType() == mysql.TypeFloat
+		allInt = allInt && et == types.ETInt
+		allNumeric = allNumeric && (et == types.ETInt || et == types.ETDecimal || et == types.ETReal)
+		allTemporal = allTemporal && isTemporal(et)
+		maxDecimal = max(maxDecimal, decimalOf(ft))
+		maxIntPart = max(maxIntPart, flenOf(ft)-decimalOf(ft))
+		maxWidth = max(maxWidth, displayWidth(ft))
+	}
+	switch {
+	case sameType && args[0].GetType() != mysql.TypeEnum && args[0].GetType() != mysql.TypeSet && args[0].GetType() != mysql.TypeUnspecified:
+		res := args[0].Clone()
+		res.DelFlag(mysql.NotNullFlag | mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag | mysql.AutoIncrementFlag | mysql.OnUpdateNowFlag | mysql.NoDefaultValueFlag)
+		if !allUnsigned {
+			res.DelFlag(mysql.UnsignedFlag)
+		}
+		switch res.EvalType() {
+		case types.ETString:
+			res.SetFlen(maxWidth)
+			cs, coll := mergedCharset(args)
+			setCollation(res, cs, coll)
+		case types.ETDecimal:
+			res.SetDecimalUnderLimit(maxDecimal)
+			res.SetFlenUnderLimit(maxIntPart + maxDecimal)
+		case types.ETDatetime, types.ETTimestamp, types.ETDuration:
+			if res.GetType() != mysql.TypeDate {
+				res = newTemporalType(res.GetType(), maxDecimal)
+			}
+		case types.ETJson:
+		default:
+			res.SetFlen(maxIntPart + maxDecimal)
+			if res.GetDecimal() != types.UnspecifiedLength {
+				res.SetDecimal(maxDecimal)
+			}
+		}
+		return res
+	case allInt:
+		rank, anyUnsigned := 0, false
+		for _, ft := range args {
+			r, ok := intRank[ft.GetType()]
+			if !ok {
+				// BIT and YEAR values are merged as BIGINT.
+				r = intRank[mysql.TypeLonglong]
+			}
+			rank = max(rank, r)
+			anyUnsigned = anyUnsigned || isUnsigned(ft)
+		}
+		if anyUnsigned && !allUnsigned {
+			// A signed and an unsigned integer need a wider type.
+			rank++
+			maxIntPart++
+		}
+		if rank >= len(rankedInts) {
+			return newDecimalType(maxIntPart, 0)
+		}
+		res := newBinaryType(rankedInts[rank], maxIntPart, 0)
+		if allUnsigned {
+			res.AddFlag(mysql.UnsignedFlag)
+		}
+		return res
+	case allNumeric:
+		for _, ft := range args {
+			if ft.EvalType() == types.ETReal {
+				if allFloat {
+					return newBinaryType(mysql.TypeFloat, mysql.MaxRealWidth, types.UnspecifiedLength)
+				}
+				return newDoubleType()
+			}
+		}
+		res := newDecimalType(maxIntPart+maxDecimal, maxDecimal)
+		if allUnsigned {
+			res.AddFlag(mysql.UnsignedFlag)
+		}
+		return res
+	case allTemporal:
+		return newTemporalType(mysql.TypeDatetime, maxDecimal)
+	}
+	tp := mysql.TypeVarString
+	for _, ft := range args {
+		if blobRank[ft.GetType()] > blobRank[tp] {
+			tp = ft.GetType()
+		}
+	}
+	res := newStringType(maxWidth, "", "")
+	if blobRank[tp] > blobRank[res.GetType()] {
+		res.SetType(tp)
+	}
+	cs, coll := mergedCharset(args)
+	setCollation(res, cs, coll)
+	return res
+}
+
+// mergedCharset returns the binary charset if any of the string types is
+// binary, otherwise the charset of the first string type.
+func mergedCharset(fts []*types.FieldType) (cs, coll string) {
+	for _, ft := range fts {
+		if !isStringType(ft) {
+			continue
+		}
+		if ft.GetCharset() == charset.CharsetBin {
+			return charset.CharsetBin, charset.CollationBin
+		}
+		if cs == "" {
+			cs, coll = ft.GetCharset(), ft.GetCollate()
+		}
+	}
+	if cs == "" {
+		return mysql.DefaultCharset, mysql.DefaultCollationName
+	}
+	return cs, coll
+}
+
+// CompareType returns the evaluation type two values of the types are
+// compared as. A string compared with a number is compared as a DOUBLE and a
+// string compared with a temporal value is compared as the temporal type,
+// both are implicit conversions of the string.
+func CompareType(lhs, rhs *types.FieldType) types.EvalType {
+	l, r := lhs.EvalType(), rhs.EvalType()
+	switch {
+	case isNull(lhs):
+		return r
+	case isNull(rhs):
+		return l
+	case l == r:
+		return l
+	case l == types.ETJson || r == types.ETJson:
+		return types.ETJson
+	case isTemporal(l) && r == types.ETString:
+		return l
+	case isTemporal(r) && l == types.ETString:
+		return r
+	case isTemporal(l) || isTemporal(r):
+		return types.ETDatetime
+	case l == types.ETString || r == types.ETString || l == types.ETReal || r == types.ETReal:
+		return types.ETReal
+	}
+	return types.ETDecimal
+}
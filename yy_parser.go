@@ -14,6 +14,7 @@
 package parser
 
 import (
+	gocontext "context"
 	"fmt"
 	"math"
 	"regexp"
@@ -58,6 +59,10 @@ var (
 	ErrWarnDeprecatedIntegerDisplayWidth = terror.ClassParser.NewStdErr(mysql.ErrWarnDeprecatedSyntaxNoReplacement, mysql.Message("Integer display width is deprecated and will be removed in a future release.", nil))
 	// ErrWrongUsage returns for incorrect usages.
 	ErrWrongUsage = terror.ClassParser.NewStd(mysql.ErrWrongUsage)
+	// ErrParserLimitExceeded returns when the SQL exceeds one of the resource limits in ParserConfig.
+	ErrParserLimitExceeded = terror.ClassParser.NewStdErr(mysql.ErrUserLimitReached, mysql.Message("SQL has exceeded the '%s' parser limit (current value: %d, max value: %d)", nil))
+	// ErrParseInterrupted returns when the context of ParseSQLContext is done.
+	ErrParseInterrupted = terror.ClassParser.NewStd(mysql.ErrQueryInterrupted)
 	// SpecFieldPattern special result field pattern
 	SpecFieldPattern = regexp.MustCompile(`(\/\*!(M?[0-9]{5,6})?|\*\/)`)
 	specCodeStart    = regexp.MustCompile(`^\/\*!(M?[0-9]{5,6})?[ \t]*`)
@@ -77,6 +82,21 @@ type ParserConfig struct {
 	EnableWindowFunction        bool
 	EnableStrictDoubleTypeCheck bool
 	SkipPositionRecording       bool
+
+	// The following limits protect the parser from untrusted SQL,
+	// 0 means unlimited. ErrParserLimitExceeded is returned if any
+	// limit is hit.
+
+	// MaxInputBytes is the max length of the SQL text.
+	MaxInputBytes int
+	// MaxTokens is the max number of tokens of the SQL text.
+	MaxTokens int
+	// MaxExprDepth is the max nesting depth of expressions. Nested
+	// parentheses are counted during scanning, so deeply nested input is
+	// rejected before it is parsed.
+	MaxExprDepth int
+	// MaxASTNodes is the max number of AST nodes of the parsed statements.
+	MaxASTNodes int
 }
 
 //revive:enable:exported
@@ -93,6 +113,10 @@ type Parser struct {
 	explicitCharset       bool
 	strictDoubleFieldType bool
 
+	maxInputBytes int
+	maxExprDepth  int
+	maxASTNodes   int
+
 	// the following fields are used by yyParse to reduce allocation.
 	cache  []yySymType
 	yylval yySymType
@@ -141,12 +165,42 @@ func (parser *Parser) SetParserConfig(config ParserConfig) {
 	parser.EnableWindowFunc(config.EnableWindowFunction)
 	parser.SetStrictDoubleTypeCheck(config.EnableStrictDoubleTypeCheck)
 	parser.lexer.skipPositionRecording = config.SkipPositionRecording
+	parser.maxInputBytes = config.MaxInputBytes
+	parser.maxExprDepth = config.MaxExprDepth
+	parser.maxASTNodes = config.MaxASTNodes
+	parser.lexer.maxTokens = config.MaxTokens
+	parser.lexer.maxParenDepth = config.MaxExprDepth
 }
 
 // ParseSQL parses a query string to raw ast.StmtNode.
 func (parser *Parser) ParseSQL(sql string, params ...ParseParam) (stmt []ast.StmtNode, warns []error, err error) {
+	return parser.ParseSQLContext(gocontext.Background(), sql, params...)
+}
+
+// ParseSQLContext parses a query string to raw ast.StmtNode like ParseSQL.
+// The parsing stops with ErrParseInterrupted once ctx is done.
+func (parser *Parser) ParseSQLContext(ctx gocontext.Context, sql string, params ...ParseParam) (stmt []ast.StmtNode, warns []error, err error) {
+	return parser.parse(ctx, sql, Pos{Line: 1}, params)
+}
+
+// parse parses sql from the start position, the text before start is ignored.
+func (parser *Parser) parse(ctx gocontext.Context, sql string, start Pos, params []ParseParam) (stmt []ast.StmtNode, warns []error, err error) {
+	if parser.maxInputBytes > 0 && len(sql) > parser.maxInputBytes {
+		return nil, nil, ErrParserLimitExceeded.GenWithStackByArgs("max input bytes", len(sql), parser.maxInputBytes)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, ErrParseInterrupted.GenWithStackByArgs()
+	}
 	resetParams(parser)
 	parser.lexer.reset(sql)
+	if start.Offset > 0 {
+		parser.lexer.r.updatePos(start)
+		parser.lexer.stmtStartPos = start.Offset
+	}
+	parser.lexer.done = ctx.Done()
+	defer func() {
+		parser.lexer.done = nil
+	}()
 	for _, p := range params {
 		if err := p.ApplyOn(parser); err != nil {
 			return nil, nil, err
@@ -154,6 +208,11 @@ func (parser *Parser) ParseSQL(sql string, params ...ParseParam) (stmt []ast.Stm
 	}
 	parser.src = sql
 	parser.result = parser.result[:0]
+	// The symbols left by the previous parse refer to its nodes, a reduction
+	// would move their offsets, which breaks the statements kept by the caller.
+	for i := range parser.cache {
+		parser.cache[i] = yySymType{}
+	}
 
 	var l yyLexer = &parser.lexer
 	yyParse(l, parser)
@@ -167,6 +226,9 @@ func (parser *Parser) ParseSQL(sql string, params ...ParseParam) (stmt []ast.Stm
 	if len(errs) != 0 {
 		return nil, warns, errors.Trace(errs[0])
 	}
+	if err := parser.checkASTLimits(parser.result); err != nil {
+		return nil, warns, err
+	}
 	for _, stmt := range parser.result {
 		ast.SetFlag(stmt)
 	}
@@ -397,6 +459,7 @@ var (
 	_ ParseParam = CharsetConnection("")
 	_ ParseParam = CollationConnection("")
 	_ ParseParam = CharsetClient("")
+	_ ParseParam = SQLModeParam(0)
 )
 
 func resetParams(p *Parser) {
@@ -445,3 +508,13 @@ func (c CharsetClient) ApplyOn(p *Parser) error {
 	p.lexer.client = charset.FindEncoding(string(c))
 	return nil
 }
+
+// SQLModeParam specifies the SQL mode used to scan and parse a SQL.
+// Like SetSQLMode, the mode is kept by the parser after the call.
+type SQLModeParam mysql.SQLMode
+
+// ApplyOn implements ParseParam interface.
+func (m SQLModeParam) ApplyOn(p *Parser) error {
+	p.SetSQLMode(mysql.SQLMode(m))
+	return nil
+}